@@ -0,0 +1,506 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/internal/setup"
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/daemon"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+const appsUsage = `Usage: cheat-go apps ACTION [flags]
+
+Actions:
+  list                    List built-in and installed apps (--offset, --limit to page)
+  add FILE|URL            Install an app definition (--enable to display it)
+  remove NAME...          Uninstall apps and drop them from the config
+  validate FILE...        Check app definitions without installing them
+  enable NAME...          Add apps to the displayed apps in the config
+  disable NAME...         Remove apps from the displayed apps in the config
+  update [NAME...]        Re-download apps that were installed from a URL
+`
+
+// appSourceKey is the metadata entry recording where an app was installed from
+const appSourceKey = "source"
+
+var appsActions = map[string]func(env cmdEnv, args []string) int{
+	"list":     runAppsList,
+	"add":      runAppsAdd,
+	"remove":   runAppsRemove,
+	"validate": runAppsValidate,
+	"enable":   runAppsEnable,
+	"disable":  runAppsDisable,
+	"update":   runAppsUpdate,
+}
+
+func runApps(env cmdEnv, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(env.stdout, appsUsage)
+		return 0
+	}
+
+	action, ok := appsActions[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unknown apps action %q\n\n%s", args[0], appsUsage)
+		return 2
+	}
+	return action(env, args[1:])
+}
+
+// appsSession is an app registry and config opened for a single command
+type appsSession struct {
+	registry *apps.Registry
+	cfg      *config.Config
+	loader   *config.Loader
+	store    storage.Storage
+	instance *lock.Instance
+}
+
+// openApps loads the config and registry. Writers also claim the
+// single-instance lock.
+func openApps(env cmdEnv, configFile string, write bool) (*appsSession, bool) {
+	loader := config.NewLoader(configFile)
+	cfg := loadConfigWith(env, loader)
+
+	session := &appsSession{cfg: cfg, loader: loader}
+	if write {
+		instance, ok := claimCLIInstance(env, cfg, "apps")
+		if !ok {
+			return nil, false
+		}
+		session.instance = instance
+	}
+
+	store, err := setup.OpenStorage(cfg)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		session.Close()
+		return nil, false
+	}
+	session.store = store
+
+	session.registry = loadRegistry(env, cfg, store)
+	if j := openJournal(cfg); j != nil {
+		session.registry.SetJournal(j, journal.SourceCLI)
+	}
+	return session, true
+}
+
+// loadRegistry loads every app the TUI shows: built-in apps, cheat sheets,
+// plugin apps and the apps of store
+func loadRegistry(env cmdEnv, cfg *config.Config, store storage.Storage) *apps.Registry {
+	registry, err := setup.NewRegistry(cfg, store)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Warning: %v\n", err)
+	}
+	pluginLoader := newPluginLoader(cfg)
+	pluginLoader.LoadAll()
+	if err := pluginLoader.RegisterApps(registry); err != nil {
+		fmt.Fprintf(env.stderr, "Warning: %v\n", err)
+	}
+	if err := registry.LoadAllAppsFromDirectory(); err != nil {
+		fmt.Fprintf(env.stderr, "Warning: %v\n", err)
+	}
+	registry.SetAliases(cfg.Aliases)
+	registry.SetMergedApps(cfg.Merged)
+	return registry
+}
+
+// Close releases the store and instance lock. After changes, a running
+// daemon is told to load the apps again.
+func (s *appsSession) Close() {
+	if s.store != nil {
+		s.store.Close()
+	}
+	if s.instance != nil {
+		s.instance.Release()
+		daemon.NewClient(s.cfg.DaemonSocket()).Reload()
+	}
+}
+
+// saveConfig writes the config back to the file it was loaded from
+func (s *appsSession) saveConfig(env cmdEnv) bool {
+	if err := s.loader.Save(s.cfg, s.loader.Path()); err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to save config: %v\n", err)
+		return false
+	}
+	return true
+}
+
+func runAppsList(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("apps list", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	profileFlag(fs, &env)
+	enabledOnly := fs.Bool("enabled", false, "Only list apps shown in the TUI")
+	offset := fs.Int("offset", 0, "Skip this many apps")
+	limit := fs.Int("limit", 0, "List at most this many apps (0 for all)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	session, ok := openApps(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	var keep func(apps.AppInfo) bool
+	if *enabledOnly {
+		keep = func(info apps.AppInfo) bool { return slices.Contains(session.cfg.Apps, info.Name) }
+	}
+	page, total := session.registry.Page(*offset, *limit, keep)
+	for _, info := range page {
+		source := "builtin"
+		if session.registry.HasStoredApp(info.Name) {
+			source = "user"
+			if info.Metadata[appSourceKey] != "" {
+				source = "url"
+			}
+		} else if src := info.Metadata[appSourceKey]; src == "cheat" || src == "plugin" {
+			source = src
+		}
+		state := ""
+		if slices.Contains(session.cfg.Apps, info.Name) {
+			state = "enabled"
+		}
+
+		fmt.Fprintf(env.stdout, "%-20s %-8s %-8s %4d  %s\n", info.Name, source, state, info.Shortcuts,
+			runewidth.Truncate(info.Description, 40, "…"))
+	}
+	if len(page) < total {
+		first := min(max(*offset, 0), total)
+		fmt.Fprintf(env.stderr, "Apps %d-%d of %d\n", first+1, first+len(page), total)
+	}
+	return 0
+}
+
+func runAppsAdd(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("apps add", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	profileFlag(fs, &env)
+	enable := fs.Bool("enable", false, "Also display the app in the TUI")
+	force := fs.Bool("force", false, "Replace an installed app with the same name")
+	allowUnverified := fs.Bool("allow-unverified", false, "Install an app from a URL that is unsigned or signed by an unknown key")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go apps add [--enable] [--force] [--allow-unverified] FILE|URL")
+		return 2
+	}
+	source := fs.Arg(0)
+
+	data, err := readSource(source)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	session, ok := openApps(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	// the keys were checked when the config was validated
+	verifier, _ := signature.NewVerifier(session.cfg.Signatures.TrustedKeys)
+	if err := verifySource(verifier, source, data); err != nil {
+		if !signature.Overridable(err) || !*allowUnverified {
+			fmt.Fprintf(env.stderr, "Error: %s: %v\n", source, err)
+			return 1
+		}
+		fmt.Fprintf(env.stderr, "Warning: %s: %v\n", source, err)
+	}
+
+	app, err := session.registry.ParseApp(data)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %s: %v\n", source, err)
+		return 1
+	}
+	if session.registry.HasStoredApp(app.Name) && !*force {
+		fmt.Fprintf(env.stderr, "Error: app %s is already installed (use --force to replace it)\n", app.Name)
+		return 1
+	}
+	if isURL(source) {
+		if app.Metadata == nil {
+			app.Metadata = map[string]string{}
+		}
+		app.Metadata[appSourceKey] = source
+	}
+
+	if err := session.registry.SaveApp(app); err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to save app: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Installed %s (%d shortcuts)\n", app.Name, len(app.Shortcuts))
+
+	if *enable && !slices.Contains(session.cfg.Apps, app.Name) {
+		session.cfg.Apps = append(session.cfg.Apps, app.Name)
+		if !session.saveConfig(env) {
+			return 1
+		}
+		fmt.Fprintf(env.stdout, "Enabled %s\n", app.Name)
+	}
+	return 0
+}
+
+func runAppsRemove(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("apps remove", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go apps remove NAME...")
+		return 2
+	}
+
+	session, ok := openApps(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	code := 0
+	for _, name := range fs.Args() {
+		if err := session.registry.RemoveApp(name); err != nil {
+			fmt.Fprintf(env.stderr, "Error: %s: %v\n", name, err)
+			code = 1
+			continue
+		}
+		session.cfg.Apps = removeString(session.cfg.Apps, name)
+		fmt.Fprintf(env.stdout, "Removed %s\n", name)
+	}
+
+	if !session.saveConfig(env) {
+		return 1
+	}
+	return code
+}
+
+func runAppsValidate(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("apps validate", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go apps validate FILE...")
+		return 2
+	}
+
+	registry := apps.NewRegistryWithStorage(nil)
+	code := 0
+	for _, source := range fs.Args() {
+		data, err := readSource(source)
+		if err == nil {
+			var app *apps.App
+			if app, err = registry.ParseApp(data); err == nil {
+				fmt.Fprintf(env.stdout, "%s: ok (%s, %d shortcuts)\n", source, app.Name, len(app.Shortcuts))
+				continue
+			}
+		}
+		fmt.Fprintf(env.stdout, "%s: %v\n", source, err)
+		code = 1
+	}
+	return code
+}
+
+func runAppsEnable(env cmdEnv, args []string) int {
+	return setAppsEnabled(env, "enable", args, true)
+}
+
+func runAppsDisable(env cmdEnv, args []string) int {
+	return setAppsEnabled(env, "disable", args, false)
+}
+
+// setAppsEnabled adds or removes apps from the displayed apps in the config
+func setAppsEnabled(env cmdEnv, action string, args []string, enable bool) int {
+	fs := flag.NewFlagSet("apps "+action, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	profileFlag(fs, &env)
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintf(env.stderr, "Usage: cheat-go apps %s NAME...\n", action)
+		return 2
+	}
+
+	session, ok := openApps(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	code := 0
+	for _, name := range fs.Args() {
+		switch {
+		case !enable:
+			session.cfg.Apps = removeString(session.cfg.Apps, name)
+		case slices.Contains(session.cfg.Apps, name):
+		default:
+			if _, exists := session.registry.Get(name); !exists {
+				fmt.Fprintf(env.stderr, "Error: %s: %v\n", name, apps.ErrAppNotFound)
+				code = 1
+				continue
+			}
+			session.cfg.Apps = append(session.cfg.Apps, name)
+		}
+		if enable {
+			fmt.Fprintf(env.stdout, "Enabled %s\n", name)
+		} else {
+			fmt.Fprintf(env.stdout, "Disabled %s\n", name)
+		}
+	}
+
+	if !session.saveConfig(env) {
+		return 1
+	}
+	return code
+}
+
+func runAppsUpdate(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("apps update", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	allowUnverified := fs.Bool("allow-unverified", false, "Update apps that are unsigned or signed by an unknown key")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+
+	session, ok := openApps(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	names := fs.Args()
+	if len(names) == 0 {
+		stored, err := session.registry.StoredApps()
+		if err != nil {
+			fmt.Fprintf(env.stderr, "Error: %v\n", err)
+			return 1
+		}
+		for _, name := range stored {
+			if app, ok := session.registry.Get(name); ok && app.Metadata[appSourceKey] != "" {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(env.stdout, "No apps were installed from a URL.")
+			return 0
+		}
+	}
+
+	verifier, _ := signature.NewVerifier(session.cfg.Signatures.TrustedKeys)
+	code := 0
+	for _, name := range names {
+		if err := updateApp(session.registry, verifier, name, *allowUnverified); err != nil {
+			fmt.Fprintf(env.stderr, "Error: %s: %v\n", name, err)
+			code = 1
+			continue
+		}
+		fmt.Fprintf(env.stdout, "Updated %s\n", name)
+	}
+	return code
+}
+
+// updateApp re-downloads an app from the URL it was installed from, which
+// is refused unless its signature is verified or allowUnverified overrides
+// an unsigned or unknown one
+func updateApp(registry *apps.Registry, verifier *signature.Verifier, name string, allowUnverified bool) error {
+	current, ok := registry.Get(name)
+	if !ok || !registry.HasStoredApp(name) {
+		return apps.ErrAppNotFound
+	}
+	source := current.Metadata[appSourceKey]
+	if source == "" {
+		return errors.New("not installed from a URL")
+	}
+
+	data, err := readSource(source)
+	if err != nil {
+		return err
+	}
+	if err := verifySource(verifier, source, data); err != nil && (!signature.Overridable(err) || !allowUnverified) {
+		return err
+	}
+	app, err := registry.ParseApp(data)
+	if err != nil {
+		return err
+	}
+	if app.Name != name {
+		return fmt.Errorf("source now defines app %q", app.Name)
+	}
+	if app.Metadata == nil {
+		app.Metadata = map[string]string{}
+	}
+	app.Metadata[appSourceKey] = source
+	return registry.SaveApp(app)
+}
+
+// readSource reads an app or plugin definition from a file or http(s) URL
+func readSource(source string) ([]byte, error) {
+	if !isURL(source) {
+		return os.ReadFile(source)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: unexpected status code: %d", source, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// verifySource checks data downloaded from source against the signature
+// beside it; files given on the command line and configs without trusted
+// keys are not checked
+func verifySource(verifier *signature.Verifier, source string, data []byte) error {
+	if !isURL(source) || !verifier.Enabled() {
+		return nil
+	}
+	sig, _ := readSource(source + signature.Suffix)
+	_, err := verifier.Verify(data, sig)
+	return err
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// removeString returns list without any occurrence of s
+func removeString(list []string, s string) []string {
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
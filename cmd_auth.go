@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/remuscazacu/cheat-go/pkg/online"
+)
+
+func runLogin(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := loadConfig(env, *configFile)
+	if !cfg.Online.Auth.Enabled() {
+		fmt.Fprintln(env.stderr, "Error: online.auth client_id, device_auth_url and token_url must be configured")
+		return 1
+	}
+
+	oauth := newOAuth(cfg)
+	code, err := oauth.StartDeviceFlow()
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(env.stdout, "Open %s and enter the code %s\n", code.VerificationURI, code.UserCode)
+	if code.VerificationURIComplete != "" {
+		fmt.Fprintf(env.stdout, "or visit %s\n", code.VerificationURIComplete)
+	}
+	fmt.Fprintln(env.stdout, "Waiting for authorization...")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if _, err := oauth.PollDeviceToken(ctx, code); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(env.stdout, "Logged in. Token saved to %s\n", cfg.TokenPath())
+	return 0
+}
+
+func runLogout(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("logout", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := loadConfig(env, *configFile)
+	store := online.NewTokenStore(cfg.TokenPath())
+	if _, err := store.Load(); errors.Is(err, online.ErrNotAuthenticated) {
+		fmt.Fprintln(env.stdout, "Not logged in.")
+		return 0
+	}
+	if err := store.Clear(); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintln(env.stdout, "Logged out.")
+	return 0
+}
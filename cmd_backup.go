@@ -0,0 +1,201 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/maintenance"
+)
+
+const backupUsage = `Usage: cheat-go backup ACTION [flags]
+
+Actions:
+  create                  Bundle config, apps, notes, plugins and cache into
+                          a timestamped tar.gz in the backups directory
+  list                    List backups, newest first
+  restore BACKUP          Replace the current data with a backup, given as a
+                          path or a file name in the backups directory
+`
+
+var backupActions = map[string]func(env cmdEnv, args []string) int{
+	"create":  runBackupCreate,
+	"list":    runBackupList,
+	"restore": runBackupRestore,
+}
+
+func runBackup(env cmdEnv, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(env.stdout, backupUsage)
+		return 0
+	}
+
+	action, ok := backupActions[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unknown backup action %q\n\n%s", args[0], backupUsage)
+		return 2
+	}
+	return action(env, args[1:])
+}
+
+// backupLocations lists the data locations a backup holds: everything
+// but older backups and logs, and the cache only when asked for
+func backupLocations(cfg *config.Config, configPath string, cache bool) []maintenance.Location {
+	var locations []maintenance.Location
+	for _, loc := range storageLocations(cfg, configPath) {
+		switch {
+		case loc.Name == "backups", loc.Name == "logs":
+		case loc.Name == "cache" && !cache:
+		default:
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// newBackupScheduler creates the automatic backups of notes and apps set
+// up by the backup section of cfg
+func newBackupScheduler(cfg *config.Config) *maintenance.BackupScheduler {
+	var locations []maintenance.Location
+	for _, loc := range storageLocations(cfg, "") {
+		switch loc.Name {
+		case "apps", "notes", "database":
+			locations = append(locations, loc)
+		}
+	}
+	return maintenance.NewBackupScheduler(cfg.BackupsDir(), locations, cfg.Backup.Interval, cfg.Backup.Keep)
+}
+
+// backupBeforeChange makes an automatic backup before a command changes
+// notes or apps, when automatic backups are on
+func backupBeforeChange(env cmdEnv, cfg *config.Config) bool {
+	if !cfg.Backup.Auto {
+		return true
+	}
+	if _, err := newBackupScheduler(cfg).Backup(); err != nil {
+		fmt.Fprintf(env.stderr, "Error: backup before changing data failed: %v\n", err)
+		return false
+	}
+	return true
+}
+
+func runBackupCreate(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("backup create", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	skipCache := fs.Bool("skip-cache", false, "Leave the cache out of the backup")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	loader := config.NewLoader(*configFile)
+	cfg := loadConfigWith(env, loader)
+
+	path, manifest, err := maintenance.CreateBackup(cfg.BackupsDir(), backupLocations(cfg, loader.Path(), !*skipCache), time.Now())
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	printBackupManifest(env, manifest)
+	fmt.Fprintf(env.stdout, "\nBackup saved to %s\n", path)
+	return 0
+}
+
+func runBackupList(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("backup list", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := loadConfig(env, *configFile)
+	backups, err := maintenance.ListBackups(cfg.BackupsDir())
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(backups) == 0 {
+		fmt.Fprintln(env.stdout, "No backups yet. Run 'cheat-go backup create' to make one.")
+		return 0
+	}
+	for _, backup := range backups {
+		kind := "manual"
+		if backup.Auto {
+			kind = "auto"
+		}
+		fmt.Fprintf(env.stdout, "%-40s %s %-6s %10s\n", filepath.Base(backup.Path),
+			backup.CreatedAt.Format("2006-01-02 15:04:05"), kind, maintenance.FormatBytes(backup.Size))
+	}
+	return 0
+}
+
+func runBackupRestore(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("backup restore", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	yes := fs.Bool("yes", false, "Restore without asking for confirmation")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go backup restore [--yes] BACKUP")
+		return 2
+	}
+
+	loader := config.NewLoader(*configFile)
+	cfg := loadConfigWith(env, loader)
+
+	archive := fs.Arg(0)
+	if _, err := os.Stat(archive); os.IsNotExist(err) && filepath.Base(archive) == archive {
+		archive = filepath.Join(cfg.BackupsDir(), archive)
+	}
+	manifest, err := maintenance.ReadBackupManifest(archive)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(env.stdout, "Backup from %s:\n", manifest.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	printBackupManifest(env, manifest)
+	fmt.Fprintln(env.stdout, "\nThis replaces the current data in these locations.")
+	if !*yes && !confirm(env, "Continue?") {
+		fmt.Fprintln(env.stdout, "Aborted.")
+		return 0
+	}
+
+	instance, ok := claimCLIInstance(env, cfg, "data")
+	if !ok {
+		return 1
+	}
+	defer instance.Release()
+
+	// the current data is backed up first so the restore can be undone
+	locations := backupLocations(cfg, loader.Path(), true)
+	current, _, err := maintenance.CreateBackup(cfg.BackupsDir(), locations, time.Now())
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to back up the current data: %v\n", err)
+		return 1
+	}
+
+	if _, err := maintenance.RestoreBackup(archive, locations); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Restored %s\n", archive)
+	fmt.Fprintf(env.stdout, "The previous data was saved to %s\n", current)
+	return 0
+}
+
+func printBackupManifest(env cmdEnv, manifest *maintenance.BackupManifest) {
+	for _, loc := range manifest.Locations {
+		size := maintenance.FormatBytes(loc.Size)
+		if !loc.Exists {
+			size = "-"
+		}
+		fmt.Fprintf(env.stdout, "%-8s %10s %6d files  %s\n", loc.Name, size, loc.Files, loc.Path)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+const bundleUsage = `Usage: cheat-go bundle ACTION [flags]
+
+Actions:
+  export [ID...]          Download online sheets into an offline bundle: the
+                          given sheets, those of --repo, or every sheet
+  list BUNDLE             List the sheets of a bundle
+  import BUNDLE           Install the sheets of a bundle without going online
+                          (--enable to display them)
+`
+
+var bundleActions = map[string]func(env cmdEnv, args []string) int{
+	"export": runBundleExport,
+	"list":   runBundleList,
+	"import": runBundleImport,
+}
+
+func runBundle(env cmdEnv, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(env.stdout, bundleUsage)
+		return 0
+	}
+
+	action, ok := bundleActions[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unknown bundle action %q\n\n%s", args[0], bundleUsage)
+		return 2
+	}
+	return action(env, args[1:])
+}
+
+func runBundleExport(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("bundle export", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	repo := fs.String("repo", "", "Export the sheets of this repository URL")
+	output := fs.String("output", "", "Bundle file to write (default cheat-go-sheets-TIME"+online.BundleExt+")")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+
+	cfg := loadConfig(env, *configFile)
+	client := newOnlineClient(cfg, newCache(cfg))
+	sheets, err := client.SearchCheatSheets(online.SearchOptions{Repository: *repo})
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to list online sheets: %v\n", err)
+		return 1
+	}
+	if ids := fs.Args(); len(ids) > 0 {
+		var selected []online.CheatSheet
+		for _, id := range ids {
+			i := slices.IndexFunc(sheets, func(sheet online.CheatSheet) bool { return sheet.ID == id })
+			if i < 0 {
+				fmt.Fprintf(env.stderr, "Error: no online sheet %s\n", id)
+				return 1
+			}
+			selected = append(selected, sheets[i])
+		}
+		sheets = selected
+	}
+	if len(sheets) == 0 {
+		fmt.Fprintln(env.stderr, "Error: no online sheets to export")
+		return 1
+	}
+
+	// sheets download concurrently but keep their order in the bundle
+	bundled := make([]*online.BundledSheet, len(sheets))
+	var mu sync.Mutex
+	index := map[string]int{}
+	for i, sheet := range sheets {
+		index[sheet.ID] = i
+	}
+	_, events := online.NewDownloadManager(cfg.Online.DownloadWorkers).Start(sheets, func(ctx context.Context, sheet online.CheatSheet) (*apps.App, error) {
+		b, err := online.BundleSheet(online.WithContext(ctx, client), sheet)
+		if err == nil {
+			mu.Lock()
+			bundled[index[sheet.ID]] = b
+			mu.Unlock()
+		}
+		return nil, err
+	})
+
+	code := 0
+	for event := range events {
+		switch event.State {
+		case online.DownloadDone:
+			fmt.Fprintf(env.stdout, "Downloaded %s\n", event.Sheet.Name)
+		case online.DownloadFailed:
+			fmt.Fprintf(env.stderr, "Error: %s: %v\n", event.Sheet.Name, event.Err)
+			code = 1
+		}
+	}
+
+	bundle := &online.Bundle{CreatedAt: time.Now().UTC()}
+	for _, b := range bundled {
+		if b != nil {
+			bundle.Sheets = append(bundle.Sheets, *b)
+		}
+	}
+	if len(bundle.Sheets) == 0 {
+		fmt.Fprintln(env.stderr, "Error: no sheet could be downloaded")
+		return 1
+	}
+
+	path := *output
+	if path == "" {
+		path = "cheat-go-sheets-" + bundle.CreatedAt.Local().Format("20060102-150405") + online.BundleExt
+	}
+	var buf bytes.Buffer
+	if err := online.WriteBundle(&buf, bundle); err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to write bundle: %v\n", err)
+		return 1
+	}
+	if err := lock.WriteFileAtomic(path, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to write bundle: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Bundled %d of %d sheets into %s\n", len(bundle.Sheets), len(sheets), path)
+	return code
+}
+
+// readBundleFile reads the bundle at path
+func readBundleFile(path string) (*online.Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return online.ReadBundle(f)
+}
+
+func runBundleList(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("bundle list", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go bundle list BUNDLE")
+		return 2
+	}
+
+	bundle, err := readBundleFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Bundle from %s, %d sheets:\n", bundle.CreatedAt.Local().Format("2006-01-02 15:04:05"), len(bundle.Sheets))
+	for _, b := range bundle.Sheets {
+		signed := "unsigned"
+		if b.Signature != nil {
+			signed = "signed"
+		}
+		fmt.Fprintf(env.stdout, "%-24s %-8s %s\n", b.Sheet.Name, signed, b.Sheet.Repository)
+	}
+	return 0
+}
+
+func runBundleImport(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("bundle import", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	enable := fs.Bool("enable", false, "Also display the imported apps in the TUI")
+	force := fs.Bool("force", false, "Replace installed apps with the same names")
+	allowUnverified := fs.Bool("allow-unverified", false, "Import sheets that are unsigned or signed by an unknown key")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go bundle import [--enable] [--force] [--allow-unverified] BUNDLE")
+		return 2
+	}
+
+	bundle, err := readBundleFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	session, ok := openApps(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+	if !backupBeforeChange(env, session.cfg) {
+		return 1
+	}
+
+	var installed []online.InstalledSheet
+	if err := storage.GetJSON(session.store, storage.CollectionSession, online.InstalledSheetsKey, &installed); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		fmt.Fprintf(env.stderr, "Error: failed to read the installed sheets: %v\n", err)
+		return 1
+	}
+
+	// the keys were checked when the config was validated
+	verifier, _ := signature.NewVerifier(session.cfg.Signatures.TrustedKeys)
+	code, enabled := 0, false
+	for _, b := range bundle.Sheets {
+		app, err := b.Open(verifier)
+		if err != nil {
+			if app == nil || !*allowUnverified {
+				fmt.Fprintf(env.stderr, "Error: %s: %v\n", b.Sheet.Name, err)
+				code = 1
+				continue
+			}
+			fmt.Fprintf(env.stderr, "Warning: %s: %v\n", b.Sheet.Name, err)
+		}
+		if err := session.registry.ValidateApp(app); err != nil {
+			fmt.Fprintf(env.stderr, "Error: invalid cheat sheet %s: %v\n", b.Sheet.Name, err)
+			code = 1
+			continue
+		}
+		if session.registry.HasStoredApp(app.Name) && !*force {
+			fmt.Fprintf(env.stderr, "Error: app %s is already installed (use --force to replace it)\n", app.Name)
+			code = 1
+			continue
+		}
+		if err := session.registry.SaveApp(app); err != nil {
+			fmt.Fprintf(env.stderr, "Error: failed to save app: %v\n", err)
+			code = 1
+			continue
+		}
+		fmt.Fprintf(env.stdout, "Installed %s (%d shortcuts)\n", app.Name, len(app.Shortcuts))
+
+		// updates of the sheet are found once the machine can go online
+		installed = slices.DeleteFunc(installed, func(sheet online.InstalledSheet) bool { return sheet.ID == b.Sheet.ID })
+		installed = append(installed, b.Sheet.Installed(app.Name))
+
+		if *enable && !slices.Contains(session.cfg.Apps, app.Name) {
+			session.cfg.Apps = append(session.cfg.Apps, app.Name)
+			enabled = true
+			fmt.Fprintf(env.stdout, "Enabled %s\n", app.Name)
+		}
+	}
+
+	if err := storage.PutJSON(session.store, storage.CollectionSession, online.InstalledSheetsKey, installed); err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to record the installed sheets: %v\n", err)
+		return 1
+	}
+	if enabled && !session.saveConfig(env) {
+		return 1
+	}
+	return code
+}
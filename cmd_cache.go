@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/internal/setup"
+	"github.com/remuscazacu/cheat-go/pkg/cache"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/maintenance"
+)
+
+const cacheUsage = `Usage: cheat-go cache ACTION [flags]
+
+Actions:
+  stats                   Show the number and size of cached entries
+  clear                   Remove every cached entry, or one --namespace
+                          (online or registry)
+  gc                      Remove entries older than --ttl
+  warm                    Cache the app table and online repository listing
+`
+
+// cacheNamespaces are the namespaces accepted by "cache clear --namespace"
+var cacheNamespaces = []string{cache.NamespaceOnline, cache.NamespaceRegistry}
+
+var cacheActions = map[string]func(env cmdEnv, args []string) int{
+	"stats": runCacheStats,
+	"clear": runCacheClear,
+	"gc":    runCacheGC,
+	"warm":  runCacheWarm,
+}
+
+func runCache(env cmdEnv, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(env.stdout, cacheUsage)
+		return 0
+	}
+
+	action, ok := cacheActions[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unknown cache action %q\n\n%s", args[0], cacheUsage)
+		return 2
+	}
+	return action(env, args[1:])
+}
+
+// openFileCache opens the on-disk cache of the configured data directory
+func openFileCache(env cmdEnv, configFile string, ttl time.Duration) (*cache.FileCache, string, bool) {
+	cfg := loadConfig(env, configFile)
+	dir := cfg.CacheDir()
+	fileCache, err := cache.NewFileCache(dir, ttl)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return nil, "", false
+	}
+	return fileCache, dir, true
+}
+
+func runCacheStats(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("cache stats", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	asJSON := fs.Bool("json", false, "Print statistics as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	fileCache, dir, ok := openFileCache(env, *configFile, defaultCacheTTL)
+	if !ok {
+		return 1
+	}
+
+	stats := fileCache.Stats()
+	if *asJSON {
+		return printJSON(env, struct {
+			Dir   string `json:"dir"`
+			Items int    `json:"items"`
+			Size  int64  `json:"size"`
+		}{dir, stats.Items, stats.Size})
+	}
+
+	fmt.Fprintf(env.stdout, "Directory: %s\n", dir)
+	fmt.Fprintf(env.stdout, "Entries:   %d\n", stats.Items)
+	fmt.Fprintf(env.stdout, "Size:      %s\n", maintenance.FormatBytes(stats.Size))
+	return 0
+}
+
+func runCacheClear(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("cache clear", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	namespace := fs.String("namespace", "", "Only clear entries of this namespace: online, sync or registry")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *namespace != "" && !slices.Contains(cacheNamespaces, *namespace) {
+		fmt.Fprintf(env.stderr, "Error: unknown cache namespace %q (valid: %s)\n", *namespace, strings.Join(cacheNamespaces, ", "))
+		return 2
+	}
+
+	fileCache, _, ok := openFileCache(env, *configFile, defaultCacheTTL)
+	if !ok {
+		return 1
+	}
+
+	before := fileCache.Stats()
+	clear := fileCache.Clear
+	if *namespace != "" {
+		clear = func() error { return fileCache.ClearNamespace(*namespace) }
+	}
+	if err := clear(); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	after := fileCache.Stats()
+	fmt.Fprintf(env.stdout, "Cleared %d entries (%s)\n", before.Items-after.Items, maintenance.FormatBytes(before.Size-after.Size))
+	return 0
+}
+
+func runCacheGC(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("cache gc", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	ttl := fs.Duration("ttl", defaultCacheTTL, "Age after which entries are removed")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	fileCache, _, ok := openFileCache(env, *configFile, *ttl)
+	if !ok {
+		return 1
+	}
+
+	removed, err := fileCache.Prune()
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Removed %d expired entries\n", removed)
+	return 0
+}
+
+func runCacheWarm(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("cache warm", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := loadConfig(env, *configFile)
+	store, err := setup.OpenStorage(cfg)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	responses := newCache(cfg)
+	cfg, registry := setupTable(env.stderr, cfg, config.Overrides{}, store)
+	rows := tableData(responses, registry, cfg.Apps)
+	fmt.Fprintf(env.stdout, "Cached table of %d apps (%d shortcuts)\n", len(cfg.Apps), len(rows)-1)
+
+	// only the http provider keeps its responses in the shared cache
+	if cfg.Online.Provider != "http" {
+		fmt.Fprintf(env.stdout, "Skipped online repositories (%s provider is not cached)\n", cfg.Online.Provider)
+		return 0
+	}
+	repos, err := newOnlineClient(cfg, responses).GetRepositories()
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to cache online repositories: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Cached %d online repositories\n", len(repos))
+	return 0
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/internal/setup"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/maintenance"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+const (
+	defaultCacheTTL     = 24 * time.Hour
+	defaultBackupMaxAge = 30 * 24 * time.Hour
+	defaultBackupKeep   = 5
+)
+
+func runCleanup(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("cleanup", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	dryRun := fs.Bool("dry-run", false, "Only report what would be deleted")
+	yes := fs.Bool("yes", false, "Delete without asking for confirmation")
+	cacheTTL := fs.Duration("cache-ttl", defaultCacheTTL, "Age after which cache files are stale")
+	backupAge := fs.Duration("backup-age", defaultBackupMaxAge, "Age after which backups are removed")
+	backupKeep := fs.Int("backup-keep", defaultBackupKeep, "Number of newest backups always kept")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := loadConfig(env, *configFile)
+	downloaded, err := downloadedApps(cfg)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to read the installed sheets: %v\n", err)
+		return 1
+	}
+	report, err := maintenance.ScanCleanup(maintenance.CleanupOptions{
+		AppsDir:        cfg.AppsDir(),
+		DownloadedApps: downloaded,
+		ReferencedApps: cfg.Apps,
+		CacheDir:       cfg.CacheDir(),
+		CacheTTL:       *cacheTTL,
+		PluginDirs:     []string{cfg.PluginsDir()},
+		BackupsDir:     cfg.BackupsDir(),
+		BackupMaxAge:   *backupAge,
+		BackupKeep:     *backupKeep,
+	})
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if len(report.Items) == 0 {
+		fmt.Fprintln(env.stdout, "Nothing to clean up.")
+		return 0
+	}
+
+	for _, item := range report.Items {
+		fmt.Fprintf(env.stdout, "%-16s %10s  %s (%s)\n",
+			item.Kind, maintenance.FormatBytes(item.Size), item.Path, item.Reason)
+	}
+	fmt.Fprintf(env.stdout, "\n%d items, %s reclaimable\n",
+		len(report.Items), maintenance.FormatBytes(report.Reclaimable()))
+
+	if *dryRun {
+		return 0
+	}
+
+	if !*yes && !confirm(env, "Delete these files?") {
+		fmt.Fprintln(env.stdout, "Aborted.")
+		return 0
+	}
+
+	deleted, freed, err := report.Delete()
+	fmt.Fprintf(env.stdout, "Deleted %d items, freed %s\n", deleted, maintenance.FormatBytes(freed))
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// downloadedApps returns the apps installed from online sheets, the only
+// ones cleanup removes
+func downloadedApps(cfg *config.Config) ([]string, error) {
+	store, err := setup.OpenStorage(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	var installed []online.InstalledSheet
+	if err := storage.GetJSON(store, storage.CollectionSession, online.InstalledSheetsKey, &installed); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	names := make([]string, len(installed))
+	for i, sheet := range installed {
+		names[i] = sheet.App
+	}
+	return names, nil
+}
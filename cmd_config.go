@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/workspace"
+)
+
+const configUsage = `Usage: cheat-go config ACTION [flags]
+
+Actions:
+  get [KEY]               Print a setting such as layout.table_style, or
+                          the whole configuration
+  set KEY VALUE           Change a setting and save the config; lists may be
+                          comma-separated, as in: set apps vim,zsh
+  validate                Check the config file for errors
+  path                    Print the config file location
+`
+
+var configActions = map[string]func(env cmdEnv, args []string) int{
+	"get":      runConfigGet,
+	"set":      runConfigSet,
+	"validate": runConfigValidate,
+	"path":     runConfigPath,
+}
+
+func runConfig(env cmdEnv, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(env.stdout, configUsage)
+		return 0
+	}
+
+	action, ok := configActions[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unknown config action %q\n\n%s", args[0], configUsage)
+		return 2
+	}
+	return action(env, args[1:])
+}
+
+// configFlags parses the flags shared by config actions
+func configFlags(env cmdEnv, name string, args []string) (*flag.FlagSet, *string, bool) {
+	fs := flag.NewFlagSet("config "+name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, false
+	}
+	return fs, configFile, true
+}
+
+func runConfigGet(env cmdEnv, args []string) int {
+	fs, configFile, ok := configFlags(env, "get", args)
+	if !ok {
+		return 2
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go config get [KEY]")
+		return 2
+	}
+
+	cfg := loadConfig(env, *configFile)
+	if fs.NArg() == 0 {
+		data, err := yaml.Marshal(cfg)
+		if err != nil {
+			fmt.Fprintf(env.stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Fprint(env.stdout, string(data))
+		return 0
+	}
+
+	value, err := cfg.Get(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(env.stdout, value)
+	return 0
+}
+
+func runConfigSet(env cmdEnv, args []string) int {
+	fs, configFile, ok := configFlags(env, "set", args)
+	if !ok {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go config set KEY VALUE")
+		return 2
+	}
+
+	loader := config.NewLoader(*configFile)
+	cfg := loadConfigWith(env, loader)
+	path := loader.Path()
+
+	// Load falls back to the defaults for a broken file, which must not
+	// be saved over it
+	if _, err := os.Stat(path); err == nil {
+		if result := config.CheckFile(path); !result.Valid {
+			fmt.Fprintf(env.stderr, "Error: %s has errors, fix them first (see \"cheat-go config validate\")\n", path)
+			return 1
+		}
+	}
+
+	key, value := fs.Arg(0), fs.Arg(1)
+	if err := cfg.Set(key, value); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		if errors.Is(err, config.ErrUnknownKey) {
+			return 2
+		}
+		return 1
+	}
+	if result := cfg.Validate(); !result.Valid {
+		for _, err := range result.Errors {
+			fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		}
+		return 1
+	}
+
+	if err := loader.Save(cfg, path); err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to save config: %v\n", err)
+		return 1
+	}
+	saved, _ := cfg.Display(key)
+	fmt.Fprintf(env.stdout, "Set %s = %s in %s\n", key, saved, path)
+	return 0
+}
+
+func runConfigValidate(env cmdEnv, args []string) int {
+	fs, configFile, ok := configFlags(env, "validate", args)
+	if !ok {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go config validate [--config FILE]")
+		return 2
+	}
+
+	loader := config.NewLoader(*configFile)
+	cfg, _ := loader.Load()
+	path := loader.Path()
+
+	result := config.CheckFile(path)
+	if !result.Valid {
+		fmt.Fprintf(env.stdout, "%s: %d problems\n", path, len(result.Errors))
+		for _, err := range result.Errors {
+			fmt.Fprintf(env.stdout, "  %v\n", err)
+		}
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "%s: ok\n", path)
+
+	// the project configuration must also apply over the file
+	project, ok := config.FindProject(workspace.Current().Dir)
+	if !ok {
+		return 0
+	}
+	loaded, err := config.LoadProject(project)
+	if err == nil {
+		_, err = cfg.WithProject(loaded)
+	}
+	if err != nil {
+		fmt.Fprintln(env.stdout, err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "%s: ok\n", project)
+	return 0
+}
+
+func runConfigPath(env cmdEnv, args []string) int {
+	_, configFile, ok := configFlags(env, "path", args)
+	if !ok {
+		return 2
+	}
+
+	loader := config.NewLoader(*configFile)
+	loader.Load()
+	fmt.Fprintln(env.stdout, loader.Path())
+	return 0
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/internal/setup"
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/daemon"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+	"github.com/remuscazacu/cheat-go/pkg/sync"
+)
+
+const daemonUsage = `Usage: cheat-go daemon ACTION [flags]
+
+Actions:
+  run                     Run in the foreground, keeping apps loaded and
+                          syncing on the sync interval; for systemd or launchd
+  status                  Show whether a daemon runs and its sync state
+  reload                  Make the daemon read apps and plugins again
+  stop                    Stop the running daemon
+
+While a daemon runs, "cheat-go search" asks it instead of loading every app.
+A scheduled sync is skipped while another process, such as the TUI, syncs.
+`
+
+var daemonActions = map[string]func(env cmdEnv, args []string) int{
+	"run":    runDaemonRun,
+	"status": runDaemonStatus,
+	"reload": runDaemonReload,
+	"stop":   runDaemonStop,
+}
+
+func runDaemon(env cmdEnv, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(env.stdout, daemonUsage)
+		return 0
+	}
+
+	action, ok := daemonActions[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unknown daemon action %q\n\n%s", args[0], daemonUsage)
+		return 2
+	}
+	return action(env, args[1:])
+}
+
+// newDaemonService sets up what the daemon keeps warm: the registry of
+// every app and, when sync is enabled, the sync manager. The config at
+// configFile is read again on each reload, for the apps enabled since.
+func newDaemonService(env cmdEnv, configFile string, cfg *config.Config, store storage.Storage) (daemon.Service, error) {
+	svc := daemon.Service{
+		Load: func() (*apps.Registry, []string, error) {
+			current := loadConfig(env, configFile)
+			// searches are served concurrently, so nothing may load lazily
+			registry := loadRegistry(env, current, store)
+			registry.LoadShortcuts()
+			return registry, current.Apps, nil
+		},
+	}
+
+	if cfg.Sync.Enabled {
+		manager, err := newSyncManager(cfg)
+		if err != nil {
+			return svc, err
+		}
+		manager.SetJournal(openJournal(cfg))
+		svc.Sync = manager
+		if cfg.Sync.AutoSync {
+			svc.SyncInterval = cfg.Sync.Interval
+			if svc.SyncInterval <= 0 {
+				svc.SyncInterval = sync.DefaultSyncInterval
+			}
+		}
+	}
+	return svc, nil
+}
+
+func runDaemonRun(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("daemon run", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := loadConfig(env, *configFile)
+	store, err := setup.OpenStorage(cfg)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	svc, err := newDaemonService(env, *configFile, cfg, store)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	server := daemon.NewServer(cfg.DaemonSocket(), svc)
+	if err := server.Listen(); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		if _, ok := <-signals; ok {
+			server.Shutdown()
+		}
+	}()
+
+	fmt.Fprintf(env.stdout, "Daemon listening on %s\n", cfg.DaemonSocket())
+	if err := server.Serve(); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(env.stdout, "Daemon stopped")
+	return 0
+}
+
+// daemonClient connects to the daemon of the configured data directory
+func daemonClient(env cmdEnv, configFile string) *daemon.Client {
+	return daemon.NewClient(loadConfig(env, configFile).DaemonSocket())
+}
+
+// daemonError prints err, explaining a daemon that is not running
+func daemonError(env cmdEnv, err error) int {
+	if errors.Is(err, daemon.ErrNotRunning) {
+		fmt.Fprintln(env.stderr, "Error: the daemon is not running; start it with 'cheat-go daemon run'")
+	} else {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+	}
+	return 1
+}
+
+func runDaemonStatus(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("daemon status", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	asJSON := fs.Bool("json", false, "Print the status as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	status, err := daemonClient(env, *configFile).Status()
+	if err != nil {
+		if errors.Is(err, daemon.ErrNotRunning) && !*asJSON {
+			fmt.Fprintln(env.stdout, "The daemon is not running.")
+			return 1
+		}
+		return daemonError(env, err)
+	}
+	if *asJSON {
+		return printJSON(env, status)
+	}
+
+	fmt.Fprintf(env.stdout, "PID:        %d\n", status.PID)
+	fmt.Fprintf(env.stdout, "Running:    since %s\n", status.StartedAt.Format(time.DateTime))
+	fmt.Fprintf(env.stdout, "Apps:       %d\n", status.Apps)
+	switch {
+	case status.Sync == nil:
+		fmt.Fprintln(env.stdout, "Sync:       not configured")
+	case status.SyncInterval == 0:
+		fmt.Fprintln(env.stdout, "Sync:       on request only (sync.auto_sync is off)")
+	default:
+		fmt.Fprintf(env.stdout, "Sync:       every %s\n", status.SyncInterval)
+	}
+	if status.Sync != nil {
+		lastSync := "never"
+		if !status.Sync.LastSync.IsZero() {
+			lastSync = status.Sync.LastSync.Format(time.DateTime)
+		}
+		fmt.Fprintf(env.stdout, "Last sync:  %s\n", lastSync)
+		if status.Sync.LastError != "" {
+			fmt.Fprintf(env.stdout, "Last error: %s\n", status.Sync.LastError)
+		}
+	}
+	if status.Skipped != "" {
+		fmt.Fprintf(env.stdout, "Skipped:    %s\n", status.Skipped)
+	}
+	return 0
+}
+
+func runDaemonReload(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("daemon reload", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := daemonClient(env, *configFile).Reload(); err != nil {
+		return daemonError(env, err)
+	}
+	fmt.Fprintln(env.stdout, "Daemon reloaded")
+	return 0
+}
+
+func runDaemonStop(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("daemon stop", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := daemonClient(env, *configFile).Stop(); err != nil {
+		return daemonError(env, err)
+	}
+	fmt.Fprintln(env.stdout, "Daemon stopped")
+	return 0
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/remuscazacu/cheat-go/pkg/ui"
+)
+
+func runExport(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	profileFlag(fs, &env)
+	format := fs.String("format", "", "Export format: markdown, html or png (defaults to the output's extension, or markdown)")
+	output := fs.String("output", "", "Write to this file instead of stdout")
+	appList := fs.String("apps", "", "Comma-separated apps to show instead of the configured ones")
+	search := fs.String("search", "", "Only export the shortcuts matching this search, as in the TUI")
+	tags := fs.String("tags", "", "Only export shortcuts with one of these comma-separated tags")
+	theme := fs.String("theme", "", "Theme of HTML and PNG exports instead of the configured one")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go export [--format FORMAT] [--output FILE] [--apps APPS] [--search QUERY] [--tags TAGS]")
+		return 2
+	}
+
+	if *format == "" {
+		*format = "markdown"
+		if detected, ok := ui.ExportFormat(*output); ok {
+			*format = detected
+		}
+	}
+	if _, ok := ui.ExportFormats[*format]; !ok {
+		fmt.Fprintf(env.stderr, "Error: unsupported export format %q\n", *format)
+		return 2
+	}
+
+	session, ok := openApps(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	cfg := session.cfg
+	if *theme != "" {
+		withTheme := *cfg
+		withTheme.Theme = *theme
+		cfg = &withTheme
+	}
+	names := session.cfg.Apps
+	if *appList != "" {
+		names = splitList(*appList)
+	}
+	session.registry.SetColumns(cfg.Layout.Columns)
+	session.registry.SetShowCategories(cfg.Layout.ShowCategories)
+	session.registry.LoadApps(names)
+
+	rows := session.registry.FilterTableData(names, *search, splitList(*tags))
+	data, err := ui.NewConfiguredRenderer(cfg).Export(rows, *format)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		env.stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Exported the table to %s\n", *output)
+	return 0
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/internal/setup"
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/importer"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+)
+
+// importFormats maps a format name to the parser that handles it
+var importFormats = map[string]func(name string, data []byte) (*apps.App, error){
+	"markdown": importer.ParseMarkdown,
+	"cheat":    importer.ParseCheat,
+	"vim":      importer.ParseVimrc,
+	"tmux":     importer.ParseTmux,
+	"kitty":    importer.ParseKitty,
+	"vscode":   importer.ParseVSCode,
+	"i3":       importer.ParseI3,
+	"sway":     importer.ParseSway,
+}
+
+// configFormats are the formats reading the key mappings of a tool's own
+// configuration. Their apps are named after the tool, such as my-vim, so
+// they show next to the tool's cheat sheet rather than replacing it.
+var configFormats = map[string]bool{
+	"vim": true, "tmux": true, "kitty": true, "vscode": true, "i3": true, "sway": true,
+}
+
+// configFiles maps the usual names of configuration files to their
+// format. Files only named config are recognised by their directory.
+var configFiles = map[string]string{
+	".vimrc": "vim", "_vimrc": "vim", "vimrc": "vim", "init.vim": "vim", ".gvimrc": "vim",
+	".tmux.conf": "tmux", "tmux.conf": "tmux",
+	"kitty.conf": "kitty", "keybindings.json": "vscode",
+	"i3/config": "i3", ".i3/config": "i3", "sway/config": "sway",
+}
+
+// detectImportFormat guesses the import format from a file name
+func detectImportFormat(path string) string {
+	if format, ok := configFiles[filepath.Base(path)]; ok {
+		return format
+	}
+	if format, ok := configFiles[filepath.Base(filepath.Dir(path))+"/"+filepath.Base(path)]; ok {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return "markdown"
+	case "":
+		// sheets of the cheat tool are named after their command
+		return "cheat"
+	}
+	return ""
+}
+
+func runImport(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	format := fs.String("format", "", "Input format (markdown, cheat, vim, tmux, kitty, vscode, i3, sway)")
+	name := fs.String("name", "", "App name (defaults to the file name)")
+	dryRun := fs.Bool("dry-run", false, "Parse and print the result without saving")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go import [--format FORMAT] [--name NAME] FILE")
+		return 2
+	}
+	path := fs.Arg(0)
+
+	if *format == "" {
+		*format = detectImportFormat(path)
+	}
+	parse, ok := importFormats[*format]
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unsupported import format %q\n", *format)
+		return 2
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *name == "" && !configFormats[*format] {
+		*name = importer.NameFromPath(path)
+	}
+	app, err := parse(*name, data)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to import %s: %v\n", path, err)
+		return 1
+	}
+
+	if *dryRun {
+		fmt.Fprintf(env.stdout, "Parsed %s: %d shortcuts in %d categories\n",
+			app.Name, len(app.Shortcuts), len(app.Categories))
+		for _, s := range app.Shortcuts {
+			fmt.Fprintf(env.stdout, "  [%s] %-20s %s\n", s.Category, s.Keys, s.Description)
+		}
+		return 0
+	}
+
+	cfg := loadConfig(env, *configFile)
+	store, err := setup.OpenStorage(cfg)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	registry := apps.NewRegistryWithStorage(store)
+	if j := openJournal(cfg); j != nil {
+		registry.SetJournal(j, journal.SourceImport)
+	}
+	if !backupBeforeChange(env, cfg) {
+		return 1
+	}
+	if err := registry.SaveApp(app); err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to save app: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(env.stdout, "Imported %s (%d shortcuts) into %s\n",
+		app.Name, len(app.Shortcuts), appsLocation(cfg))
+	if !slices.Contains(cfg.Apps, app.Name) {
+		fmt.Fprintf(env.stdout, "Add %q to the apps list in your config to display it.\n", app.Name)
+	}
+	return 0
+}
+
+// appsLocation describes where imported apps are stored
+func appsLocation(cfg *config.Config) string {
+	if cfg.Storage.Backend == "sqlite" {
+		return cfg.DatabasePath()
+	}
+	return cfg.AppsDir()
+}
@@ -0,0 +1,811 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/internal/setup"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+)
+
+const notesUsage = `Usage: cheat-go notes ACTION [flags]
+
+Actions:
+  list                    List notes, newest first
+  search QUERY            List notes whose title or content matches QUERY
+  show ID...              Print notes with their fields (--content for the
+                          content alone, --json for scripts)
+  add --title TITLE       Create a note, reading its content from stdin
+  add --template NAME     Create a note from a template (--app fills {{app}})
+  templates               List note templates
+  edit ID                 Change a note's fields (--stdin replaces the content)
+  delete ID...            Move notes to the trash
+  trash                   List deleted notes
+  restore ID...           Move notes back from the trash
+  empty-trash             Permanently remove all deleted notes
+  tag ID... --add T,U     Add or --remove tags on notes
+  export                  Write all notes as json, yaml, markdown, html or pdf
+  import DIR              Import the .md files of a folder, e.g. an Obsidian
+                          vault (--dry-run reports what would be created)
+
+IDs may be given as "-" to read them one per line from stdin, for example:
+  cheat-go notes list --tag old -q | cheat-go notes delete -
+`
+
+// notesActions maps each notes action to its handler
+var notesActions = map[string]func(env cmdEnv, args []string) int{
+	"list":        runNotesList,
+	"search":      runNotesSearch,
+	"show":        runNotesShow,
+	"add":         runNotesAdd,
+	"edit":        runNotesEdit,
+	"delete":      runNotesDelete,
+	"trash":       runNotesTrash,
+	"restore":     runNotesRestore,
+	"empty-trash": runNotesEmptyTrash,
+	"tag":         runNotesTag,
+	"export":      runNotesExport,
+	"import":      runNotesImport,
+	"templates":   runNotesTemplates,
+}
+
+func runNotes(env cmdEnv, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(env.stdout, notesUsage)
+		return 0
+	}
+
+	action, ok := notesActions[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unknown notes action %q\n\n%s", args[0], notesUsage)
+		return 2
+	}
+	return action(env, args[1:])
+}
+
+// notesSession is a notes manager opened for a single command
+type notesSession struct {
+	manager  *notes.FileManager
+	cfg      *config.Config
+	close    func()
+	instance *lock.Instance
+}
+
+// openNotes opens the configured notes store. Writers also claim the
+// single-instance lock so a running TUI cannot overwrite their changes.
+func openNotes(env cmdEnv, configFile string, write bool) (*notesSession, bool) {
+	cfg := loadConfig(env, configFile)
+
+	session := &notesSession{cfg: cfg}
+	if write {
+		instance, ok := claimCLIInstance(env, cfg, "notes")
+		if !ok {
+			return nil, false
+		}
+		session.instance = instance
+	}
+
+	store, err := setup.OpenStorage(cfg)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		session.Close()
+		return nil, false
+	}
+	session.close = func() { store.Close() }
+
+	fm, err := notes.NewStorageManager(store)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		session.Close()
+		return nil, false
+	}
+	if j := openJournal(cfg); j != nil {
+		fm.SetJournal(j, journal.SourceCLI)
+	}
+	if write {
+		fm.PurgeTrash(cfg.Notes.TrashRetention)
+	}
+	session.manager = fm
+	return session, true
+}
+
+// Close releases the store and instance lock
+func (s *notesSession) Close() {
+	if s.close != nil {
+		s.close()
+	}
+	if s.instance != nil {
+		s.instance.Release()
+	}
+}
+
+// noteFilters are the search flags shared by list and search
+type noteFilters struct {
+	app       *string
+	shortcut  *string
+	category  *string
+	tags      *string
+	favorites *bool
+	sort      *string
+	limit     *int
+	asJSON    *bool
+	quiet     *bool
+}
+
+func addNoteFilters(fs *flag.FlagSet) noteFilters {
+	return noteFilters{
+		app:       fs.String("app", "", "Only notes for this app"),
+		shortcut:  fs.String("shortcut", "", "Only notes attached to the shortcut with these keys"),
+		category:  fs.String("category", "", "Only notes in this category"),
+		tags:      fs.String("tag", "", "Only notes with any of these comma-separated tags"),
+		favorites: fs.Bool("favorites", false, "Only favorite notes"),
+		sort:      fs.String("sort", "", "Sort by relevance, title, created_at or updated_at (default relevance for searches, updated_at otherwise)"),
+		limit:     fs.Int("limit", 0, "Maximum number of notes to list"),
+		asJSON:    fs.Bool("json", false, "Print notes as JSON"),
+		quiet:     fs.Bool("q", false, "Print only note IDs"),
+	}
+}
+
+func (f noteFilters) options(query string) notes.SearchOptions {
+	return notes.SearchOptions{
+		Query:         query,
+		AppName:       *f.app,
+		ShortcutKeys:  *f.shortcut,
+		Category:      *f.category,
+		Tags:          splitList(*f.tags),
+		OnlyFavorites: *f.favorites,
+		SortBy:        *f.sort,
+		Limit:         *f.limit,
+	}
+}
+
+func runNotesList(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes list", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	filters := addNoteFilters(fs)
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	return searchNotes(env, *configFile, filters, "")
+}
+
+func runNotesSearch(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes search", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	filters := addNoteFilters(fs)
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go notes search [flags] QUERY")
+		return 2
+	}
+	return searchNotes(env, *configFile, filters, strings.Join(fs.Args(), " "))
+}
+
+func searchNotes(env cmdEnv, configFile string, filters noteFilters, query string) int {
+	session, ok := openNotes(env, configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	results, err := session.manager.SearchNotes(filters.options(query))
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	switch {
+	case *filters.asJSON:
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			fmt.Fprintf(env.stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(env.stdout, string(data))
+	case *filters.quiet:
+		for _, note := range results {
+			fmt.Fprintln(env.stdout, note.ID)
+		}
+	default:
+		for _, note := range results {
+			printNoteLine(env.stdout, note)
+		}
+	}
+	return 0
+}
+
+// printNoteLine writes a one line summary of note
+func printNoteLine(w io.Writer, note *notes.Note) {
+	favorite := " "
+	if note.IsFavorite {
+		favorite = "★"
+	}
+	title := runewidth.FillRight(runewidth.Truncate(note.Title, 32, "…"), 32)
+	fmt.Fprintf(w, "%-24s %s %s %s\n", note.ID, favorite, title, strings.Join(note.Tags, ","))
+}
+
+func runNotesShow(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes show", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	contentOnly := fs.Bool("content", false, "Print only the content")
+	asJSON := fs.Bool("json", false, "Print the notes as JSON")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+
+	ids, err := noteIDs(env, fs.Args())
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	session, ok := openNotes(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	code := 0
+	found := []*notes.Note{}
+	for _, id := range ids {
+		note, err := session.manager.GetNote(id)
+		if err != nil {
+			fmt.Fprintf(env.stderr, "Error: %s: %v\n", id, err)
+			code = 1
+			continue
+		}
+		found = append(found, note)
+	}
+
+	switch {
+	case *asJSON:
+		if printJSON(env, found) != 0 {
+			return 1
+		}
+	case *contentOnly:
+		for _, note := range found {
+			fmt.Fprintln(env.stdout, note.Content)
+		}
+	default:
+		for i, note := range found {
+			if i > 0 {
+				fmt.Fprintln(env.stdout, "\n---")
+			}
+			printNote(env.stdout, note)
+		}
+	}
+	return code
+}
+
+// printNote writes the fields of note followed by its content
+func printNote(w io.Writer, note *notes.Note) {
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%-10s %s\n", name+":", value)
+		}
+	}
+	field("ID", note.ID)
+	field("Title", note.Title)
+	field("App", note.AppName)
+	field("Shortcut", note.ShortcutKeys)
+	field("Category", note.Category)
+	field("Tags", strings.Join(note.Tags, ", "))
+	field("Team", note.Team)
+	if note.IsFavorite {
+		field("Favorite", "yes")
+	}
+	field("Created", note.CreatedAt.Local().Format("2006-01-02 15:04"))
+	field("Updated", note.UpdatedAt.Local().Format("2006-01-02 15:04"))
+
+	fmt.Fprintln(w)
+	if note.Encrypted {
+		fmt.Fprintln(w, "(encrypted; open the note in the TUI to read it)")
+		return
+	}
+	fmt.Fprintln(w, note.Content)
+}
+
+func runNotesAdd(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes add", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	title := fs.String("title", "", "Note title (required without --template)")
+	template := fs.String("template", "", "Create the note from this template instead of stdin")
+	app := fs.String("app", "", "App the note belongs to")
+	shortcut := fs.String("shortcut", "", "Attach the note to the shortcut with these keys in --app")
+	category := fs.String("category", "", "Note category")
+	tags := fs.String("tags", "", "Comma-separated tags")
+	favorite := fs.Bool("favorite", false, "Mark the note as favorite")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if *title == "" && *template == "" {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go notes add --title TITLE [flags] < content")
+		fmt.Fprintln(env.stderr, "       cheat-go notes add --template NAME [flags]")
+		return 2
+	}
+	if *shortcut != "" && *app == "" {
+		fmt.Fprintln(env.stderr, "Error: --shortcut requires --app")
+		return 2
+	}
+
+	note := &notes.Note{}
+	if *template != "" {
+		templates, err := notes.LoadTemplates(loadConfig(env, *configFile).TemplatesDir())
+		if err != nil {
+			fmt.Fprintf(env.stderr, "Error: %v\n", err)
+			return 1
+		}
+		tmpl, err := notes.FindTemplate(templates, *template)
+		if err != nil {
+			fmt.Fprintf(env.stderr, "Error: %v\n", err)
+			return 1
+		}
+		note = tmpl.NewNote(notes.TemplateVars{App: *app})
+	} else {
+		content, err := io.ReadAll(env.stdin)
+		if err != nil {
+			fmt.Fprintf(env.stderr, "Error: failed to read content: %v\n", err)
+			return 1
+		}
+		note.Content = strings.TrimRight(string(content), "\n")
+	}
+
+	session, ok := openNotes(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	if *title != "" {
+		note.Title = *title
+	}
+	note.AppName = *app
+	note.ShortcutKeys = *shortcut
+	if *category != "" {
+		note.Category = *category
+	}
+	if *tags != "" {
+		note.Tags = splitList(*tags)
+	}
+	note.AddTags(session.cfg.Notes.Tags...)
+	note.IsFavorite = *favorite
+	if err := session.manager.CreateNote(note); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintln(env.stdout, note.ID)
+	return 0
+}
+
+func runNotesTemplates(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes templates", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := loadConfig(env, *configFile)
+	templates, err := notes.LoadTemplates(cfg.TemplatesDir())
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	for _, tmpl := range templates {
+		fmt.Fprintf(env.stdout, "%-20s %s\n", tmpl.Name, tmpl.Description)
+	}
+	return 0
+}
+
+func runNotesEdit(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes edit", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	title := fs.String("title", "", "New title")
+	app := fs.String("app", "", "New app")
+	shortcut := fs.String("shortcut", "", "Attach to the shortcut with these keys (\"\" detaches)")
+	category := fs.String("category", "", "New category")
+	tags := fs.String("tags", "", "Replace tags with these comma-separated tags")
+	stdin := fs.Bool("stdin", false, "Replace the content with stdin")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go notes edit ID [--title T] [--app A] [--shortcut K] [--category C] [--tags T,U] [--stdin]")
+		return 2
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var content []byte
+	if *stdin {
+		var err error
+		if content, err = io.ReadAll(env.stdin); err != nil {
+			fmt.Fprintf(env.stderr, "Error: failed to read content: %v\n", err)
+			return 1
+		}
+	}
+
+	session, ok := openNotes(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	id := fs.Arg(0)
+	existing, err := session.manager.GetNote(id)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %s: %v\n", id, err)
+		return 1
+	}
+
+	note := *existing
+	if set["title"] {
+		note.Title = *title
+	}
+	if set["app"] {
+		note.AppName = *app
+	}
+	if set["shortcut"] {
+		note.ShortcutKeys = *shortcut
+	}
+	if note.ShortcutKeys != "" && note.AppName == "" {
+		fmt.Fprintln(env.stderr, "Error: a note attached to a shortcut needs an app")
+		return 2
+	}
+	if set["category"] {
+		note.Category = *category
+	}
+	if set["tags"] {
+		note.Tags = splitList(*tags)
+	}
+	if *stdin {
+		note.Content = strings.TrimRight(string(content), "\n")
+	}
+
+	if err := session.manager.UpdateNote(id, &note); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Updated %s\n", id)
+	return 0
+}
+
+func runNotesDelete(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes delete", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+
+	ids, err := noteIDs(env, fs.Args())
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	session, ok := openNotes(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	return eachNote(env, ids, "Moved to trash", session.manager.DeleteNote)
+}
+
+func runNotesTrash(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes trash", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	asJSON := fs.Bool("json", false, "Print deleted notes as JSON")
+	quiet := fs.Bool("q", false, "Print only note IDs")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+
+	session, ok := openNotes(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	trash, _ := session.manager.ListTrash()
+	switch {
+	case *asJSON:
+		return printJSON(env, trash)
+	case *quiet:
+		for _, trashed := range trash {
+			fmt.Fprintln(env.stdout, trashed.Note.ID)
+		}
+	case len(trash) == 0:
+		fmt.Fprintln(env.stdout, "The trash is empty")
+	default:
+		for _, trashed := range trash {
+			fmt.Fprintf(env.stdout, "%s  %s  %s\n", trashed.Note.ID,
+				trashed.DeletedAt.Format("2006-01-02 15:04"), trashed.Note.Title)
+		}
+	}
+	return 0
+}
+
+func runNotesRestore(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes restore", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+
+	ids, err := noteIDs(env, fs.Args())
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	session, ok := openNotes(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	return eachNote(env, ids, "Restored", session.manager.RestoreNote)
+}
+
+func runNotesEmptyTrash(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes empty-trash", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+
+	session, ok := openNotes(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	removed, err := session.manager.EmptyTrash()
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Removed %d notes from the trash\n", removed)
+	return 0
+}
+
+func runNotesTag(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes tag", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	add := fs.String("add", "", "Comma-separated tags to add")
+	remove := fs.String("remove", "", "Comma-separated tags to remove")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if *add == "" && *remove == "" {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go notes tag ID... [--add T,U] [--remove V]")
+		return 2
+	}
+
+	ids, err := noteIDs(env, fs.Args())
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	session, ok := openNotes(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	return eachNote(env, ids, "Tagged", func(id string) error {
+		existing, err := session.manager.GetNote(id)
+		if err != nil {
+			return err
+		}
+		note := *existing
+		note.Tags = retag(existing.Tags, splitList(*add), splitList(*remove))
+		return session.manager.UpdateNote(id, &note)
+	})
+}
+
+func runNotesExport(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes export", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	format := fs.String("format", "json", "Export format: json, yaml, markdown, html or pdf")
+	output := fs.String("output", "", "Write to this file instead of stdout")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+
+	session, ok := openNotes(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	data, err := session.manager.ExportNotes(*format)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		env.stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Exported notes to %s\n", *output)
+	return 0
+}
+
+func runNotesImport(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("notes import", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	dryRun := fs.Bool("dry-run", false, "Report what would be imported without saving")
+	app := fs.String("app", "", "App of notes whose front matter does not name one")
+	asJSON := fs.Bool("json", false, "Print the import report as JSON")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go notes import [--dry-run] [--app APP] DIR")
+		return 2
+	}
+
+	session, ok := openNotes(env, *configFile, !*dryRun)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+	if !*dryRun && !backupBeforeChange(env, session.cfg) {
+		return 1
+	}
+
+	report, err := session.manager.ImportDirectory(fs.Arg(0), notes.ImportDirectoryOptions{
+		DryRun: *dryRun,
+		App:    *app,
+	})
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *asJSON {
+		return printJSON(env, report)
+	}
+
+	verb := "Imported"
+	if *dryRun {
+		verb = "Would import"
+	}
+	for _, file := range report.Created {
+		fmt.Fprintf(env.stdout, "%s %s as %q\n", verb, file.Path, file.Note.Title)
+	}
+	for _, file := range report.Skipped {
+		fmt.Fprintf(env.stdout, "Skipped %s: %s\n", file.Path, file.Reason)
+	}
+	fmt.Fprintf(env.stdout, "%s %d notes, skipped %d files\n", verb, len(report.Created), len(report.Skipped))
+	return 0
+}
+
+// eachNote applies fn to every id, reporting failures and continuing
+func eachNote(env cmdEnv, ids []string, done string, fn func(id string) error) int {
+	code := 0
+	for _, id := range ids {
+		if err := fn(id); err != nil {
+			fmt.Fprintf(env.stderr, "Error: %s: %v\n", id, err)
+			code = 1
+			continue
+		}
+		fmt.Fprintf(env.stdout, "%s %s\n", done, id)
+	}
+	return code
+}
+
+// noteIDs expands "-" arguments to the IDs listed on stdin
+func noteIDs(env cmdEnv, args []string) ([]string, error) {
+	ids := []string{}
+	for _, arg := range args {
+		if arg != "-" {
+			ids = append(ids, arg)
+			continue
+		}
+		scanner := bufio.NewScanner(env.stdin)
+		for scanner.Scan() {
+			if id := strings.TrimSpace(scanner.Text()); id != "" {
+				ids = append(ids, id)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read IDs: %w", err)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no note IDs given")
+	}
+	return ids, nil
+}
+
+// retag adds and removes tags, keeping the existing order
+func retag(tags, add, remove []string) []string {
+	result := []string{}
+	for _, tag := range tags {
+		if !slices.Contains(remove, tag) {
+			result = append(result, tag)
+		}
+	}
+	for _, tag := range add {
+		if !slices.Contains(result, tag) && !slices.Contains(remove, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// reorderArgs moves flags ahead of positional arguments so IDs may come
+// before flags, as in "notes tag ID --add x"
+func reorderArgs(fs *flag.FlagSet, args []string) []string {
+	var flags, positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positional = append(positional, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := fs.Lookup(name); f != nil && i+1 < len(args) {
+			if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+				continue
+			}
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	return append(flags, positional...)
+}
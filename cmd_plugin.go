@@ -0,0 +1,548 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/internal/setup"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+	"github.com/remuscazacu/cheat-go/pkg/plugins"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+)
+
+const pluginUsage = `Usage: cheat-go plugin ACTION [flags]
+
+Actions:
+  list                    List installed plugins
+  install FILE|URL        Install a plugin definition into the user plugin directory
+  remove NAME...          Uninstall user plugins
+  enable NAME...          Load plugins on startup
+  disable NAME...         Keep plugins installed but do not load them
+  info NAME               Show a plugin's metadata, location and config
+  run NAME [COMMAND]      Run a command of a Lua plugin, or list its commands
+`
+
+var pluginActions = map[string]func(env cmdEnv, args []string) int{
+	"list":    runPluginList,
+	"install": runPluginInstall,
+	"remove":  runPluginRemove,
+	"enable":  runPluginEnable,
+	"disable": runPluginDisable,
+	"info":    runPluginInfo,
+	"run":     runPluginRun,
+}
+
+func runPlugin(env cmdEnv, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(env.stdout, pluginUsage)
+		return 0
+	}
+
+	action, ok := pluginActions[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unknown plugin action %q\n\n%s", args[0], pluginUsage)
+		return 2
+	}
+	return action(env, args[1:])
+}
+
+// pluginSession is the plugin loader and config opened for a single command
+type pluginSession struct {
+	loader    *plugins.Loader
+	cfg       *config.Config
+	cfgLoader *config.Loader
+	instance  *lock.Instance
+	// host is what Lua plugins can reach; commands fill it in as needed
+	host  *plugins.Host
+	close func()
+}
+
+// openPlugins loads the config and every installed plugin. Writers also
+// claim the single-instance lock.
+func openPlugins(env cmdEnv, configFile string, write bool) (*pluginSession, bool) {
+	cfgLoader := config.NewLoader(configFile)
+	cfg := loadConfigWith(env, cfgLoader)
+
+	session := &pluginSession{cfg: cfg, cfgLoader: cfgLoader}
+	if write {
+		instance, ok := claimCLIInstance(env, cfg, "plugins")
+		if !ok {
+			return nil, false
+		}
+		session.instance = instance
+	}
+
+	session.host = &plugins.Host{}
+	session.loader = newPluginLoader(cfg)
+	session.loader.SetHost(session.host)
+	session.loader.LoadAll()
+	return session, true
+}
+
+// Close releases the store opened for the host and the instance lock
+func (s *pluginSession) Close() {
+	if s.close != nil {
+		s.close()
+	}
+	if s.instance != nil {
+		s.instance.Release()
+	}
+}
+
+// saveConfig writes the config back to the file it was loaded from
+func (s *pluginSession) saveConfig(env cmdEnv) bool {
+	if err := s.cfgLoader.Save(s.cfg, s.cfgLoader.Path()); err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to save config: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// isUserPlugin reports whether a plugin lives in the user plugin directory
+// and may therefore be replaced or removed
+func (s *pluginSession) isUserPlugin(loaded *plugins.LoadedPlugin) bool {
+	return filepath.Dir(loaded.Path) == filepath.Clean(s.cfg.PluginsDir())
+}
+
+func runPluginList(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("plugin list", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	asJSON := fs.Bool("json", false, "Print plugins as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	session, ok := openPlugins(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	loaded := session.loader.ListPlugins()
+	if *asJSON {
+		return printJSON(env, pluginInfos(loaded))
+	}
+
+	if len(loaded) == 0 {
+		fmt.Fprintln(env.stdout, "No plugins installed.")
+		return 0
+	}
+	for _, p := range loaded {
+		state := "enabled"
+		switch {
+		case p.Untrusted != nil:
+			state = "untrusted"
+		case p.Disabled:
+			state = "disabled"
+		}
+		fmt.Fprintf(env.stdout, "%-20s %-10s %-9s %s\n", p.Metadata.Name, p.Metadata.Version, state,
+			runewidth.Truncate(p.Metadata.Description, 40, "…"))
+	}
+	return 0
+}
+
+func runPluginInstall(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("plugin install", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	force := fs.Bool("force", false, "Replace an installed plugin with the same name")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go plugin install [--force] FILE|URL")
+		return 2
+	}
+	source := fs.Arg(0)
+
+	data, err := readSource(source)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	// the signature is optional, and only checked with trusted keys
+	sig, _ := readSource(source + signature.Suffix)
+
+	session, ok := openPlugins(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	// verified before the metadata is read, which runs Lua plugins
+	verifier, _ := signature.NewVerifier(session.cfg.Signatures.TrustedKeys)
+	var unverified error
+	if verifier.Enabled() {
+		if _, err := verifier.Verify(data, sig); err != nil {
+			if !signature.Overridable(err) {
+				fmt.Fprintf(env.stderr, "Error: %s: %v\n", source, err)
+				return 1
+			}
+			unverified = err
+		}
+	}
+
+	// Lua plugins are named after their file
+	ext := ".yaml"
+	var metadata *plugins.Metadata
+	switch name := strings.TrimSuffix(filepath.Base(source), ".lua"); {
+	case strings.HasSuffix(source, ".lua") && unverified != nil:
+		ext = ".lua"
+		metadata, err = plugins.LuaFileMetadata(name)
+	case strings.HasSuffix(source, ".lua"):
+		ext = ".lua"
+		metadata, err = plugins.ParseLuaMetadata(name, data)
+	default:
+		metadata, err = plugins.ParseMetadata(data)
+	}
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %s: %v\n", source, err)
+		return 1
+	}
+
+	var replaced string
+	if existing, err := session.loader.LoadedPlugin(metadata.Name); err == nil {
+		switch {
+		case !*force:
+			fmt.Fprintf(env.stderr, "Error: plugin %s is already installed at %s (use --force to replace it)\n",
+				metadata.Name, existing.Path)
+			return 1
+		case session.isUserPlugin(existing):
+			replaced = existing.Path
+		}
+	}
+
+	dir := session.cfg.PluginsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to create plugin directory: %v\n", err)
+		return 1
+	}
+	path := filepath.Join(dir, metadata.Name+ext)
+	if err := lock.WriteFileAtomic(path, data, 0644); err != nil {
+		fmt.Fprintf(env.stderr, "Error: failed to install plugin: %v\n", err)
+		return 1
+	}
+	if replaced != "" && replaced != path {
+		os.Remove(replaced)
+		os.Remove(replaced + signature.Suffix)
+	}
+	if len(sig) > 0 {
+		if err := lock.WriteFileAtomic(path+signature.Suffix, sig, 0644); err != nil {
+			fmt.Fprintf(env.stderr, "Error: failed to install plugin signature: %v\n", err)
+			return 1
+		}
+	} else {
+		os.Remove(path + signature.Suffix)
+	}
+
+	fmt.Fprintf(env.stdout, "Installed %s %s to %s\n", metadata.Name, metadata.Version, path)
+	if unverified != nil {
+		fmt.Fprintf(env.stdout, "%s could not be verified (%v); approve it in the plugin manager to run it\n", metadata.Name, unverified)
+	}
+	if slices.Contains(session.cfg.Plugins.Disabled, metadata.Name) {
+		fmt.Fprintf(env.stdout, "%s is disabled; run 'cheat-go plugin enable %s' to load it\n", metadata.Name, metadata.Name)
+	}
+	return 0
+}
+
+func runPluginRemove(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("plugin remove", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go plugin remove NAME...")
+		return 2
+	}
+
+	session, ok := openPlugins(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	code := 0
+	for _, name := range fs.Args() {
+		loaded, err := session.loader.LoadedPlugin(name)
+		if err != nil {
+			fmt.Fprintf(env.stderr, "Error: %s: %v\n", name, err)
+			code = 1
+			continue
+		}
+		if !session.isUserPlugin(loaded) {
+			fmt.Fprintf(env.stderr, "Error: %s is installed system wide at %s; remove it manually\n", name, loaded.Path)
+			code = 1
+			continue
+		}
+		if err := os.Remove(loaded.Path); err != nil {
+			fmt.Fprintf(env.stderr, "Error: %s: %v\n", name, err)
+			code = 1
+			continue
+		}
+		session.cfg.Plugins.Disabled = removeString(session.cfg.Plugins.Disabled, name)
+		fmt.Fprintf(env.stdout, "Removed %s\n", name)
+	}
+
+	if !session.saveConfig(env) {
+		return 1
+	}
+	return code
+}
+
+func runPluginEnable(env cmdEnv, args []string) int {
+	return setPluginsEnabled(env, "enable", args, true)
+}
+
+func runPluginDisable(env cmdEnv, args []string) int {
+	return setPluginsEnabled(env, "disable", args, false)
+}
+
+// setPluginsEnabled adds or removes plugins from the disabled list in the config
+func setPluginsEnabled(env cmdEnv, action string, args []string, enable bool) int {
+	fs := flag.NewFlagSet("plugin "+action, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintf(env.stderr, "Usage: cheat-go plugin %s NAME...\n", action)
+		return 2
+	}
+
+	session, ok := openPlugins(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	code := 0
+	for _, name := range fs.Args() {
+		if _, err := session.loader.LoadedPlugin(name); err != nil {
+			fmt.Fprintf(env.stderr, "Error: %s: %v\n", name, err)
+			code = 1
+			continue
+		}
+
+		disabled := removeString(session.cfg.Plugins.Disabled, name)
+		if enable {
+			fmt.Fprintf(env.stdout, "Enabled %s\n", name)
+		} else {
+			disabled = append(disabled, name)
+			fmt.Fprintf(env.stdout, "Disabled %s\n", name)
+		}
+		session.cfg.Plugins.Disabled = disabled
+	}
+
+	if !session.saveConfig(env) {
+		return 1
+	}
+	return code
+}
+
+func runPluginInfo(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("plugin info", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	asJSON := fs.Bool("json", false, "Print the plugin as JSON")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go plugin info [--json] NAME")
+		return 2
+	}
+
+	session, ok := openPlugins(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	loaded, err := session.loader.LoadedPlugin(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	info := pluginInfos([]*plugins.LoadedPlugin{loaded})[0]
+	if *asJSON {
+		return printJSON(env, info)
+	}
+
+	fmt.Fprintf(env.stdout, "Name:        %s\n", info.Name)
+	fmt.Fprintf(env.stdout, "Version:     %s\n", info.Version)
+	fmt.Fprintf(env.stdout, "Author:      %s\n", info.Author)
+	fmt.Fprintf(env.stdout, "Type:        %s\n", info.Type)
+	fmt.Fprintf(env.stdout, "Description: %s\n", info.Description)
+	fmt.Fprintf(env.stdout, "Path:        %s\n", info.Path)
+	switch {
+	case info.Untrusted != "":
+		fmt.Fprintf(env.stdout, "State:       not run: %s\n", info.Untrusted)
+		fmt.Fprintf(env.stdout, "SHA-256:     %s\n", loaded.Sum)
+	case info.Enabled:
+		fmt.Fprintln(env.stdout, "State:       enabled")
+	default:
+		fmt.Fprintln(env.stdout, "State:       disabled")
+	}
+	if len(info.Commands) > 0 {
+		fmt.Fprintf(env.stdout, "Commands:    %s\n", strings.Join(info.Commands, ", "))
+	}
+	if len(info.Config) > 0 {
+		fmt.Fprintln(env.stdout, "Config:")
+		keys := make([]string, 0, len(info.Config))
+		for key := range info.Config {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(env.stdout, "  %s: %v\n", key, info.Config[key])
+		}
+	}
+	return 0
+}
+
+// pluginInfo is the scripting view of an installed plugin
+type pluginInfo struct {
+	plugins.Metadata
+	Path     string   `json:"path"`
+	Enabled  bool     `json:"enabled"`
+	Commands []string `json:"commands,omitempty"`
+	// Untrusted is why the plugin failed signature verification
+	Untrusted string `json:"untrusted,omitempty"`
+}
+
+func pluginInfos(loaded []*plugins.LoadedPlugin) []pluginInfo {
+	infos := make([]pluginInfo, 0, len(loaded))
+	for _, p := range loaded {
+		info := pluginInfo{Metadata: *p.Metadata, Path: p.Path, Enabled: !p.Disabled}
+		if luaPlugin, ok := p.Plugin.(*plugins.LuaPlugin); ok {
+			info.Commands = luaPlugin.Commands()
+		}
+		if p.Untrusted != nil {
+			info.Untrusted = p.Untrusted.Error()
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func runPluginRun(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("plugin run", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	// arguments starting with - go to the command after --
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go plugin run NAME [COMMAND [ARG...]] [-- ARG...]")
+		return 2
+	}
+	name := fs.Arg(0)
+
+	session, ok := openPlugins(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	loaded, err := session.loader.LoadedPlugin(name)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %s: %v\n", name, err)
+		return 1
+	}
+	luaPlugin, ok := loaded.Plugin.(*plugins.LuaPlugin)
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: %s is not a Lua plugin and has no commands\n", name)
+		return 1
+	}
+	if loaded.Disabled {
+		fmt.Fprintf(env.stderr, "Error: %s is disabled; run 'cheat-go plugin enable %s' first\n", name, name)
+		return 1
+	}
+
+	if fs.NArg() == 1 {
+		commands := luaPlugin.Commands()
+		if len(commands) == 0 {
+			fmt.Fprintf(env.stdout, "%s has no commands.\n", name)
+		}
+		for _, command := range commands {
+			fmt.Fprintln(env.stdout, command)
+		}
+		return 0
+	}
+
+	if !session.openHost(env) {
+		return 1
+	}
+	output, err := luaPlugin.RunCommand(context.Background(), fs.Arg(1), fs.Args()[2:])
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %s: %v\n", name, err)
+		return 1
+	}
+	if output != "" {
+		fmt.Fprintln(env.stdout, output)
+	}
+	return 0
+}
+
+// openHost gives Lua plugins the apps and notes of the configured storage
+func (s *pluginSession) openHost(env cmdEnv) bool {
+	store, err := setup.OpenStorage(s.cfg)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return false
+	}
+	s.close = func() { store.Close() }
+
+	registry, err := setup.NewRegistry(s.cfg, store)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Warning: %v\n", err)
+	}
+	if err := s.loader.RegisterApps(registry); err != nil {
+		fmt.Fprintf(env.stderr, "Warning: %v\n", err)
+	}
+	if err := registry.LoadAllAppsFromDirectory(); err != nil {
+		fmt.Fprintf(env.stderr, "Warning: %v\n", err)
+	}
+
+	fm, err := notes.NewStorageManager(store)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return false
+	}
+	if j := openJournal(s.cfg); j != nil {
+		registry.SetJournal(j, journal.SourceCLI)
+		fm.SetJournal(j, journal.SourceCLI)
+	}
+
+	s.host.Apps = registry
+	s.host.Notes = fm
+	return true
+}
+
+// printJSON writes v as indented JSON to stdout
+func printJSON(env cmdEnv, v interface{}) int {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(env.stdout, string(data))
+	return 0
+}
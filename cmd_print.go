@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/sheet"
+)
+
+// defaultSheetFile is where print writes the sheet without --output
+const defaultSheetFile = "cheat-sheet.pdf"
+
+func runPrint(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("print", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	profileFlag(fs, &env)
+	output := fs.String("output", defaultSheetFile, "PDF file to write, or - for stdout")
+	appList := fs.String("apps", "", "Comma-separated apps to print instead of the configured ones")
+	search := fs.String("search", "", "Only print the shortcuts matching this search, as in the TUI")
+	tags := fs.String("tags", "", "Only print shortcuts with one of these comma-separated tags")
+	paperName := fs.String("paper", sheet.A4.Name, "Paper size: a4 or letter")
+	landscape := fs.Bool("landscape", false, "Turn the page sideways")
+	columns := fs.Int("columns", 0, "Number of columns (3 by default, 4 in landscape)")
+	fontSize := fs.Float64("font-size", 0, "Largest font size in points; text shrinks to fit one page")
+	title := fs.String("title", "", "Title printed at the top of the sheet (defaults to the app names)")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go print [--output FILE] [--apps APPS] [--paper a4|letter] [--landscape] [--columns N]")
+		return 2
+	}
+	paper, ok := sheet.PaperNamed(*paperName)
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unknown paper size %q (use a4 or letter)\n", *paperName)
+		return 2
+	}
+	if *columns == 0 && *landscape {
+		*columns = 4
+	}
+
+	session, ok := openApps(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	names := session.cfg.Apps
+	if *appList != "" {
+		names = splitList(*appList)
+	}
+	session.registry.LoadApps(names)
+
+	// the standard PDF fonts have no key symbols, so mac notation is
+	// spelled out
+	notation := session.cfg.Layout.KeyNotation
+	if notation == apps.NotationMac {
+		notation = apps.NotationVerbose
+	}
+	printed := sheetApps(session.registry, names, *search, splitList(*tags), notation)
+	if *title == "" {
+		*title = strings.Join(names, " · ")
+	}
+
+	data, err := sheet.Render(printed, sheet.Options{
+		Title:     *title,
+		Paper:     paper,
+		Landscape: *landscape,
+		Columns:   *columns,
+		FontSize:  *fontSize,
+	})
+	if errors.Is(err, sheet.ErrNoShortcuts) {
+		fmt.Fprintln(env.stderr, "No shortcuts to print")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *output == "-" {
+		env.stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Printed the cheat sheet to %s\n", *output)
+	return 0
+}
+
+// sheetApps returns copies of the apps called names holding the shortcuts
+// matching query and tags, with their keys in notation
+func sheetApps(registry *apps.Registry, names []string, query string, tags []string, notation string) []*apps.App {
+	var matches map[string][]apps.Shortcut
+	if query != "" {
+		matches = make(map[string][]apps.Shortcut)
+		for _, result := range registry.SearchShortcutsAdvanced(names, apps.ParseSearchQuery(query)) {
+			matches[result.AppName] = append(matches[result.AppName], result.Shortcut)
+		}
+	}
+
+	var printed []*apps.App
+	for _, name := range names {
+		app, ok := registry.Get(name)
+		if !ok {
+			continue
+		}
+		shortcuts := app.Shortcuts
+		if matches != nil {
+			shortcuts = matches[name]
+		}
+
+		copied := *app
+		copied.Shortcuts = nil
+		for _, shortcut := range shortcuts {
+			if len(tags) > 0 && !shortcut.HasAnyTag(tags) {
+				continue
+			}
+			shortcut.Keys = apps.FormatKeys(shortcut.Keys, notation)
+			copied.Shortcuts = append(copied.Shortcuts, shortcut)
+		}
+		printed = append(printed, &copied)
+	}
+	return printed
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/daemon"
+)
+
+// searchResult is a matching shortcut as printed by search --json
+type searchResult struct {
+	App         string   `json:"app"`
+	Keys        string   `json:"keys"`
+	Description string   `json:"description"`
+	Category    string   `json:"category,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// searchDaemon searches the apps a running daemon keeps loaded, reporting
+// false when no daemon answers. The daemon searches the apps enabled by
+// cfg rather than its own, which may come from another profile or project.
+func searchDaemon(cfg *config.Config, query string, all bool) ([]apps.ShortcutResult, bool) {
+	results, err := daemon.NewClient(cfg.DaemonSocket()).Search(query, all, cfg.Apps...)
+	return results, err == nil
+}
+
+func runSearch(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	profileFlag(fs, &env)
+	all := fs.Bool("all", false, "Search every installed app, not only the enabled ones")
+	asJSON := fs.Bool("json", false, "Print the matches as JSON")
+	limit := fs.Int("limit", 0, "Print at most this many matches (0 for all)")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go search [--all] [--profile NAME] [--json] [--limit N] QUERY")
+		return 2
+	}
+
+	query := strings.Join(fs.Args(), " ")
+	found, ok := searchDaemon(loadConfig(env, *configFile), query, *all)
+	if !ok {
+		session, ok := openApps(env, *configFile, false)
+		if !ok {
+			return 1
+		}
+		defer session.Close()
+
+		names := session.cfg.Apps
+		if *all {
+			names = session.registry.List()
+			sort.Strings(names)
+		}
+		found = session.registry.SearchShortcutsAdvanced(names, apps.ParseSearchQuery(query))
+	}
+	if *limit > 0 && len(found) > *limit {
+		found = found[:*limit]
+	}
+
+	results := make([]searchResult, 0, len(found))
+	for _, r := range found {
+		results = append(results, searchResult{
+			App:         r.AppName,
+			Keys:        r.Shortcut.Keys,
+			Description: r.Shortcut.Description,
+			Category:    r.Shortcut.Category,
+			Tags:        r.Shortcut.Tags,
+		})
+	}
+
+	if *asJSON {
+		if code := printJSON(env, results); code != 0 {
+			return code
+		}
+	} else {
+		for _, r := range results {
+			fmt.Fprintf(env.stdout, "%-12s %-20s %s", r.App, r.Keys, r.Description)
+			if r.Category != "" {
+				fmt.Fprintf(env.stdout, " [%s]", r.Category)
+			}
+			fmt.Fprintln(env.stdout)
+		}
+	}
+
+	// like grep, finding nothing is a failure scripts can test for
+	if len(results) == 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/remuscazacu/cheat-go/internal/setup"
+	"github.com/remuscazacu/cheat-go/pkg/practice"
+)
+
+func runStats(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	asJSON := fs.Bool("json", false, "Print statistics as JSON")
+	weakest := fs.Int("weakest", 10, "Number of weakest shortcuts to list")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := loadConfig(env, *configFile)
+	store, err := setup.OpenStorage(cfg)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	tracker, err := practice.NewTracker(store)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	stats := tracker.Stats(*weakest)
+	if *asJSON {
+		return printJSON(env, stats)
+	}
+
+	if stats.Reviews == 0 {
+		fmt.Fprintln(env.stdout, "No practice yet. Press Q in the TUI to start a quiz.")
+		return 0
+	}
+
+	fmt.Fprintf(env.stdout, "Reviews:  %d (%.0f%% correct)\n", stats.Reviews, stats.Accuracy*100)
+	fmt.Fprintf(env.stdout, "Cards:    %d, %d due\n", stats.Cards, stats.Due)
+	fmt.Fprintf(env.stdout, "Streak:   %d days (longest %d)\n", stats.CurrentStreak, stats.LongestStreak)
+
+	printGroups := func(title string, groups []practice.Group) {
+		fmt.Fprintf(env.stdout, "\n%-20s %6s %8s %9s\n", title, "Cards", "Reviews", "Accuracy")
+		for _, group := range groups {
+			fmt.Fprintf(env.stdout, "%-20s %6d %8d %8.0f%%\n", group.Name, group.Cards, group.Reviews, group.Accuracy*100)
+		}
+	}
+	printGroups("App", stats.Apps)
+	printGroups("Category", stats.Categories)
+
+	if len(stats.Weakest) > 0 {
+		fmt.Fprintln(env.stdout, "\nWeakest shortcuts:")
+		for _, card := range stats.Weakest {
+			fmt.Fprintf(env.stdout, "  %-12s %-24s %3.0f%% of %d\n", card.App, card.Keys, card.Accuracy()*100, card.Reviews)
+		}
+	}
+	return 0
+}
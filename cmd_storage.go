@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/maintenance"
+)
+
+// storageLocations lists every place user data is kept for cfg
+func storageLocations(cfg *config.Config, configPath string) []maintenance.Location {
+	locations := []maintenance.Location{
+		{Name: "config", Path: configPath},
+		{Name: "apps", Path: cfg.AppsDir(), Shallow: true, Extensions: []string{".yaml", ".yml"}},
+		{Name: "notes", Path: cfg.NotesDir()},
+		{Name: "plugins", Path: cfg.PluginsDir()},
+		{Name: "cache", Path: cfg.CacheDir()},
+		{Name: "backups", Path: cfg.BackupsDir()},
+		{Name: "logs", Path: cfg.LogsDir()},
+	}
+	if cfg.Storage.Backend == "sqlite" {
+		locations = append(locations, maintenance.Location{Name: "database", Path: cfg.DatabasePath()})
+	}
+	return locations
+}
+
+func runStorage(env cmdEnv, args []string) int {
+	if len(args) > 0 && args[0] == "move" {
+		return runStorageMove(env, args[1:])
+	}
+
+	fs := flag.NewFlagSet("storage", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	loader := config.NewLoader(*configFile)
+	cfg := loadConfigWith(env, loader)
+
+	report, err := maintenance.DiskUsage(storageLocations(cfg, loader.Path()))
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(env.stdout, "Data directory: %s\n\n", cfg.BaseDir())
+	for _, e := range report.Entries {
+		size := maintenance.FormatBytes(e.Size)
+		if !e.Exists {
+			size = "-"
+		}
+		fmt.Fprintf(env.stdout, "%-8s %10s %6d files  %s\n", e.Name, size, e.Files, e.Path)
+	}
+	fmt.Fprintf(env.stdout, "\nTotal: %s\n", maintenance.FormatBytes(report.Total()))
+	return 0
+}
+
+func runStorageMove(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("storage move", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	yes := fs.Bool("yes", false, "Move without asking for confirmation")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go storage move [--yes] NEW_DIR")
+		return 2
+	}
+
+	loader := config.NewLoader(*configFile)
+	cfg := loadConfigWith(env, loader)
+
+	dst, err := filepath.Abs(config.ExpandPath(fs.Arg(0)))
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	src := cfg.BaseDir()
+
+	fmt.Fprintf(env.stdout, "This will move all cheat-go data:\n  from: %s\n  to:   %s\n", src, dst)
+	fmt.Fprintf(env.stdout, "and update data_dir in %s\n", loader.Path())
+	if !*yes && !confirm(env, "Continue?") {
+		fmt.Fprintln(env.stdout, "Aborted.")
+		return 0
+	}
+
+	if err := maintenance.MoveDataDir(src, dst); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	cfg.DataDir = dst
+	if err := loader.Save(cfg, loader.Path()); err != nil {
+		fmt.Fprintf(env.stderr, "Error: data moved but config update failed: %v\n", err)
+		fmt.Fprintf(env.stderr, "Set data_dir: %s manually.\n", dst)
+		return 1
+	}
+
+	fmt.Fprintf(env.stdout, "Data directory moved to %s\n", dst)
+	return 0
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/sync"
+)
+
+const syncUsage = `Usage: cheat-go sync ACTION [flags]
+
+Actions:
+  now                     Pull, merge and push notes now, printing progress
+                          (--json for a summary, -q for errors only); exits
+                          1 when the sync fails, for cron and systemd timers
+  status                  Show the device ID, last sync and any error
+  conflicts               List conflicts left by the last sync
+  resolve ID STRATEGY     Resolve a conflict: local, remote, merge or skip
+  devices                 List the devices syncing the same data (--json)
+  rename [NAME]           Name this device; without NAME use the host name
+  revoke ID               Make the server forget another device, such as a
+                          lost laptop, and refuse its syncs
+`
+
+var syncActions = map[string]func(env cmdEnv, args []string) int{
+	"now":       runSyncNow,
+	"status":    runSyncStatus,
+	"conflicts": runSyncConflicts,
+	"resolve":   runSyncResolve,
+	"devices":   runSyncDevices,
+	"rename":    runSyncRename,
+	"revoke":    runSyncRevoke,
+}
+
+func runSync(env cmdEnv, args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(env.stdout, syncUsage)
+		return 0
+	}
+
+	action, ok := syncActions[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unknown sync action %q\n\n%s", args[0], syncUsage)
+		return 2
+	}
+	return action(env, args[1:])
+}
+
+// syncSession is a sync manager opened for a single command
+type syncSession struct {
+	manager  *sync.Manager
+	cfg      *config.Config
+	instance *lock.Instance
+}
+
+// openSync creates the sync manager from the config. Commands that change
+// local data also claim the single-instance lock.
+func openSync(env cmdEnv, configFile string, write bool) (*syncSession, bool) {
+	cfg := loadConfig(env, configFile)
+	if cfg.Sync.Endpoint == "" {
+		fmt.Fprintln(env.stderr, "Error: sync is not configured; set sync.endpoint in the config")
+		return nil, false
+	}
+
+	session := &syncSession{cfg: cfg}
+	if write {
+		instance, ok := claimCLIInstance(env, cfg, "synced data")
+		if !ok {
+			return nil, false
+		}
+		session.instance = instance
+	}
+
+	manager, err := newSyncManager(cfg)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		session.Close()
+		return nil, false
+	}
+	manager.SetJournal(openJournal(cfg))
+	session.manager = manager
+	return session, true
+}
+
+// Close releases the instance lock
+func (s *syncSession) Close() {
+	if s.instance != nil {
+		s.instance.Release()
+	}
+}
+
+// syncStageMessages are the progress lines of sync now
+var syncStageMessages = map[sync.SyncStage]string{
+	sync.StageGathering: "Gathering local data...",
+	sync.StagePulling:   "Pulling remote data...",
+	sync.StageSaving:    "Saving local data...",
+}
+
+// syncReport is the outcome of sync now as printed by --json
+type syncReport struct {
+	OK         bool                `json:"ok"`
+	LastSync   *time.Time          `json:"last_sync,omitempty"`
+	Notes      int                 `json:"notes"`
+	Apps       int                 `json:"apps"`
+	Resolved   []syncResolution    `json:"resolved"`
+	Unresolved []string            `json:"unresolved"`
+	Transfer   *sync.TransferStats `json:"transfer,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// syncResolution is a conflict the sync resolved by itself
+type syncResolution struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Resolution string `json:"resolution"`
+}
+
+func runSyncNow(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("sync now", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	asJSON := fs.Bool("json", false, "Print a summary as JSON instead of progress")
+	quiet := fs.Bool("q", false, "Print only errors, for cron jobs")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	session, ok := openSync(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	report := syncReport{Resolved: []syncResolution{}, Unresolved: []string{}}
+	progress := !*asJSON && !*quiet
+	session.manager.SetProgress(func(p sync.Progress) {
+		report.Notes, report.Apps = p.Notes, p.Apps
+		if p.Conflict != nil {
+			report.Resolved = append(report.Resolved, syncResolution{
+				ID: p.Conflict.ID, Type: p.Conflict.Type, Resolution: p.Resolution.String(),
+			})
+		}
+		if !progress {
+			return
+		}
+		switch {
+		case p.Stage == sync.StageResolving && p.Conflict == nil:
+			fmt.Fprintf(env.stdout, "Resolving %d conflicts...\n", p.Total)
+		case p.Stage == sync.StageResolving:
+			fmt.Fprintf(env.stdout, "  %s (%s): kept %s\n", p.Conflict.ID, p.Conflict.Type, p.Resolution)
+		case p.Stage == sync.StagePushing && p.Changes > 0:
+			fmt.Fprintf(env.stdout, "Pushing %d changed notes and apps...\n", p.Changes)
+		case p.Stage == sync.StagePushing:
+			fmt.Fprintf(env.stdout, "Pushing %d notes and %d apps...\n", p.Notes, p.Apps)
+		default:
+			if msg, ok := syncStageMessages[p.Stage]; ok {
+				fmt.Fprintln(env.stdout, msg)
+			}
+		}
+	})
+
+	err := session.manager.Sync()
+	status := session.manager.GetSyncStatus()
+	if err != nil {
+		report.Error = err.Error()
+		for _, conflict := range status.Conflicts {
+			report.Unresolved = append(report.Unresolved, conflict.ID)
+		}
+	} else {
+		report.OK = true
+		report.LastSync = &status.LastSync
+	}
+	report.Transfer = status.Transfer
+
+	if *asJSON {
+		printJSON(env, report)
+	} else if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		if len(report.Unresolved) > 0 {
+			fmt.Fprintf(env.stderr, "%d unresolved conflicts; see 'cheat-go sync conflicts'\n", len(report.Unresolved))
+		}
+	} else if !*quiet {
+		fmt.Fprintf(env.stdout, "Synced at %s: %d notes, %d apps, %d conflicts resolved\n",
+			status.LastSync.Format(time.DateTime), report.Notes, report.Apps, len(report.Resolved))
+		if report.Transfer != nil {
+			fmt.Fprintf(env.stdout, "Transferred %s\n", report.Transfer)
+		}
+	}
+
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+func runSyncStatus(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("sync status", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	asJSON := fs.Bool("json", false, "Print the status as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	session, ok := openSync(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	status := session.manager.GetSyncStatus()
+	if *asJSON {
+		return printJSON(env, status)
+	}
+
+	lastSync := "never"
+	if !status.LastSync.IsZero() {
+		lastSync = status.LastSync.Format(time.DateTime)
+	}
+	fmt.Fprintf(env.stdout, "Endpoint:   %s\n", session.cfg.Sync.Endpoint)
+	fmt.Fprintf(env.stdout, "Device ID:  %s\n", status.DeviceID)
+	fmt.Fprintf(env.stdout, "Device:     %s\n", status.DeviceName)
+	fmt.Fprintf(env.stdout, "Last sync:  %s\n", lastSync)
+	fmt.Fprintf(env.stdout, "Conflicts:  %d\n", len(status.Conflicts))
+	if status.Transfer != nil {
+		fmt.Fprintf(env.stdout, "Transfer:   %s\n", status.Transfer)
+	}
+	if status.Quarantined != "" {
+		fmt.Fprintf(env.stdout, "Integrity:  pulled data failed its checksum; kept in %s\n", status.Quarantined)
+	}
+	if status.LastError != "" {
+		fmt.Fprintf(env.stdout, "Last error: %s\n", status.LastError)
+		return 1
+	}
+	return 0
+}
+
+func runSyncConflicts(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("sync conflicts", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	asJSON := fs.Bool("json", false, "Print conflicts as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	session, ok := openSync(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	conflicts := session.manager.GetSyncStatus().Conflicts
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].ID < conflicts[j].ID })
+	if *asJSON {
+		if conflicts == nil {
+			conflicts = []sync.SyncItem{}
+		}
+		return printJSON(env, conflicts)
+	}
+
+	if len(conflicts) == 0 {
+		fmt.Fprintln(env.stdout, "No conflicts.")
+		return 0
+	}
+	for _, conflict := range conflicts {
+		fmt.Fprintf(env.stdout, "%-24s %-8s %s\n", conflict.ID, conflict.Type, conflict.Timestamp.Format(time.DateTime))
+	}
+	return 0
+}
+
+func runSyncResolve(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("sync resolve", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go sync resolve ID local|remote|merge|skip")
+		return 2
+	}
+
+	resolution, err := sync.ParseResolution(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	session, ok := openSync(env, *configFile, true)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	if err := session.manager.ResolveConflict(fs.Arg(0), resolution); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Resolved %s (%s)\n", fs.Arg(0), resolution)
+	return 0
+}
+
+func runSyncDevices(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("sync devices", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	asJSON := fs.Bool("json", false, "Print devices as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	session, ok := openSync(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	devices, err := session.manager.Devices(context.Background())
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *asJSON {
+		if devices == nil {
+			devices = []sync.Device{}
+		}
+		return printJSON(env, devices)
+	}
+
+	for _, device := range devices {
+		current := ""
+		if device.Current {
+			current = " (this device)"
+		}
+		fmt.Fprintf(env.stdout, "%-36s %-20s %s%s\n", device.ID, device.Name, device.LastSeen.Format(time.DateTime), current)
+	}
+	return 0
+}
+
+func runSyncRename(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("sync rename", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go sync rename [NAME]")
+		return 2
+	}
+
+	session, ok := openSync(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	if err := session.manager.SetDeviceName(fs.Arg(0)); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "This device is now %q; other devices see the name after the next sync\n", session.manager.DeviceName())
+	return 0
+}
+
+func runSyncRevoke(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("sync revoke", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	configFile := fs.String("config", "", "Configuration file path")
+	if err := fs.Parse(reorderArgs(fs, args)); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.stderr, "Usage: cheat-go sync revoke ID")
+		return 2
+	}
+
+	session, ok := openSync(env, *configFile, false)
+	if !ok {
+		return 1
+	}
+	defer session.Close()
+
+	if err := session.manager.RevokeDevice(fs.Arg(0)); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Revoked %s\n", fs.Arg(0))
+	return 0
+}
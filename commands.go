@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/workspace"
+)
+
+// cmdEnv holds the streams a subcommand reads from and writes to
+type cmdEnv struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// profile replaces the profile of the config when set by --profile
+	profile string
+}
+
+func defaultEnv() cmdEnv {
+	return cmdEnv{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+}
+
+// subcommand is a headless command run instead of the TUI
+type subcommand struct {
+	name string
+	// args names the arguments after the command in the help
+	args    string
+	summary string
+	run     func(env cmdEnv, args []string) int
+}
+
+func subcommands() []subcommand {
+	return []subcommand{
+		{name: "apps", args: "ACTION", summary: "List, install, remove, enable and update apps", run: runApps},
+		{name: "backup", args: "ACTION", summary: "Create, list and restore backups of all user data", run: runBackup},
+		{name: "bundle", args: "ACTION", summary: "Export and import offline bundles of online sheets", run: runBundle},
+		{name: "cache", args: "ACTION", summary: "Show, clear, prune and warm the on-disk cache", run: runCache},
+		{name: "cleanup", summary: "Find and remove unused downloaded data", run: runCleanup},
+		{name: "config", args: "ACTION", summary: "Get, set and validate configuration settings", run: runConfig},
+		{name: "daemon", args: "ACTION", summary: "Run in the background to keep apps loaded and sync on a schedule", run: runDaemon},
+		{name: "export", summary: "Write the shortcut table as markdown, HTML or PNG", run: runExport},
+		{name: "help", args: "[COMMAND]", summary: "Show this help or the actions and flags of a command", run: runHelp},
+		{name: "import", args: "FILE", summary: "Import a cheat sheet or key mapping config as an app", run: runImport},
+		{name: "login", summary: "Log in to the online cheat sheet service", run: runLogin},
+		{name: "logout", summary: "Forget the saved online service token", run: runLogout},
+		{name: "man", summary: "Print a man page of all options and commands", run: runMan},
+		{name: "notes", args: "ACTION", summary: "List, search, show, add, edit, tag and export notes", run: runNotes},
+		{name: "plugin", args: "ACTION", summary: "List, install, remove, enable and disable plugins", run: runPlugin},
+		{name: "print", summary: "Lay out the shortcuts as a printable one-page PDF cheat sheet", run: runPrint},
+		{name: "search", args: "QUERY", summary: "Print the shortcuts matching a search, as in the TUI", run: runSearch},
+		{name: "stats", summary: "Show or export quiz practice statistics", run: runStats},
+		{name: "storage", args: "[move DIR]", summary: "Show disk usage and move the data directory", run: runStorage},
+		{name: "sync", args: "ACTION", summary: "Sync notes and inspect or resolve sync conflicts", run: runSync},
+	}
+}
+
+// runSubcommand dispatches args to a subcommand, reporting whether one matched
+func runSubcommand(env cmdEnv, args []string) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+
+	cmd, ok := findSubcommand(args[0])
+	if !ok {
+		return 0, false
+	}
+	return cmd.run(env, args[1:]), true
+}
+
+// loadConfig loads the configuration for headless commands
+func loadConfig(env cmdEnv, path string) *config.Config {
+	return loadConfigWith(env, config.NewLoader(path))
+}
+
+// loadConfigWith loads the configuration using an existing loader so callers
+// can save changes back to the same file. The active profile and the
+// project configuration are applied as the TUI applies them.
+func loadConfigWith(env cmdEnv, loader *config.Loader) *config.Config {
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintf(env.stderr, "Warning: Could not load config (%v), using defaults\n", err)
+		cfg = config.DefaultConfig()
+	}
+	cfg = applyProfile(env.stderr, cfg, env.profile)
+	return applyProject(env.stderr, cfg)
+}
+
+// profileFlag adds --profile, which selects the profile env loads the
+// config with
+func profileFlag(fs *flag.FlagSet, env *cmdEnv) {
+	fs.StringVar(&env.profile, "profile", "", "Configuration profile to apply instead of the configured one")
+}
+
+// applyProfile applies the named profile, or else the profile of the
+// config, to cfg, warning about one that cannot be applied
+func applyProfile(warn io.Writer, cfg *config.Config, name string) *config.Config {
+	if name == "" {
+		name = cfg.Profile
+	}
+	if name == "" {
+		return cfg
+	}
+	withProfile, err := cfg.WithProfile(name)
+	if err != nil {
+		fmt.Fprintf(warn, "Warning: %v, using the base configuration\n", err)
+		return cfg
+	}
+	return withProfile
+}
+
+// applyProject applies the project configuration nearest the working
+// directory over cfg, warning about one that cannot be applied
+func applyProject(warn io.Writer, cfg *config.Config) *config.Config {
+	path, ok := config.FindProject(workspace.Current().Dir)
+	if !ok {
+		return cfg
+	}
+	project, err := config.LoadProject(path)
+	if err == nil {
+		var applied *config.Config
+		if applied, err = cfg.WithProject(project); err == nil {
+			return applied
+		}
+	}
+	fmt.Fprintf(warn, "Warning: %v, ignoring it\n", err)
+	return cfg
+}
+
+// claimCLIInstance takes the single-instance lock for a command that writes
+// user data, so a running TUI cannot overwrite its changes
+func claimCLIInstance(env cmdEnv, cfg *config.Config, what string) (*lock.Instance, bool) {
+	instance, err := lock.AcquireInstance(cfg.BaseDir())
+	if err != nil {
+		var locked *lock.InstanceLockedError
+		if errors.As(err, &locked) {
+			fmt.Fprintf(env.stderr, "Error: %v; close it before changing %s\n", locked, what)
+		} else {
+			fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		}
+		return nil, false
+	}
+	return instance, true
+}
+
+// confirm asks a yes/no question on the command streams
+func confirm(env cmdEnv, question string) bool {
+	fmt.Fprintf(env.stdout, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(env.stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,1487 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/remuscazacu/cheat-go/internal/setup"
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/daemon"
+	"github.com/remuscazacu/cheat-go/pkg/maintenance"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+	"github.com/remuscazacu/cheat-go/pkg/practice"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+	"github.com/remuscazacu/cheat-go/pkg/sync"
+)
+
+// testEnv returns a command environment with captured output and the given input
+func testEnv(input string) (cmdEnv, *bytes.Buffer, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	return cmdEnv{
+		stdin:  strings.NewReader(input),
+		stdout: stdout,
+		stderr: stderr,
+	}, stdout, stderr
+}
+
+// writeTestConfig writes a config using dataDir and returns its path
+func writeTestConfig(t *testing.T, dataDir string, apps ...string) string {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.DataDir = dataDir
+	if len(apps) > 0 {
+		cfg.Apps = apps
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunSubcommand_Unknown(t *testing.T) {
+	env, _, _ := testEnv("")
+
+	if _, ok := runSubcommand(env, []string{"--theme", "dark"}); ok {
+		t.Error("flags should not be treated as a subcommand")
+	}
+	if _, ok := runSubcommand(env, nil); ok {
+		t.Error("empty args should not match a subcommand")
+	}
+}
+
+func TestCleanupCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir, "vim")
+	os.WriteFile(filepath.Join(dataDir, "vim.yaml"), []byte("name: vim"), 0644)
+	os.WriteFile(filepath.Join(dataDir, "unused.yaml"), []byte("name: unused"), 0644)
+	// an app the user added but has not enabled, or disabled, is not unused
+	os.WriteFile(filepath.Join(dataDir, "mytool.yaml"), []byte("name: mytool"), 0644)
+	cfg, _ := config.NewLoader(configPath).Load()
+	store, err := setup.OpenStorage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.PutJSON(store, storage.CollectionSession, online.InstalledSheetsKey, []online.InstalledSheet{{ID: "unused", App: "unused"}, {ID: "vim", App: "vim"}})
+	store.Close()
+
+	env, stdout, _ := testEnv("")
+	code, ok := runSubcommand(env, []string{"cleanup", "--config", configPath, "--dry-run"})
+	if !ok || code != 0 {
+		t.Fatalf("cleanup --dry-run failed: ok=%v code=%d", ok, code)
+	}
+	if !strings.Contains(stdout.String(), "unused.yaml") {
+		t.Errorf("report should list unused app, got: %s", stdout.String())
+	}
+	if strings.Contains(stdout.String(), "mytool.yaml") {
+		t.Errorf("apps that were not downloaded should be kept, got: %s", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "unused.yaml")); err != nil {
+		t.Error("dry run should not delete files")
+	}
+
+	env, stdout, _ = testEnv("n\n")
+	runSubcommand(env, []string{"cleanup", "--config", configPath})
+	if !strings.Contains(stdout.String(), "Aborted") {
+		t.Errorf("declining confirmation should abort, got: %s", stdout.String())
+	}
+
+	env, _, _ = testEnv("y\n")
+	if code, _ := runSubcommand(env, []string{"cleanup", "--config", configPath}); code != 0 {
+		t.Fatalf("cleanup failed with code %d", code)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "unused.yaml")); !os.IsNotExist(err) {
+		t.Error("confirmed cleanup should delete unused app")
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "vim.yaml")); err != nil {
+		t.Error("referenced app should be kept")
+	}
+}
+
+func TestImportCommand_Markdown(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+	mdPath := filepath.Join(t.TempDir(), "tmux.md")
+	os.WriteFile(mdPath, []byte("# Tmux\n\n- `C-b d`: detach\n"), 0644)
+
+	env, stdout, stderr := testEnv("")
+	code, _ := runSubcommand(env, []string{"import", "--config", configPath, mdPath})
+	if code != 0 {
+		t.Fatalf("import failed with code %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Imported tmux") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "tmux.yaml")); err != nil {
+		t.Errorf("app file should be written: %v", err)
+	}
+
+	env, _, _ = testEnv("")
+	if code, _ := runSubcommand(env, []string{"import", "--format", "bogus", mdPath}); code != 2 {
+		t.Errorf("unsupported format should exit 2, got %d", code)
+	}
+}
+
+func TestImportCommand_ConfigFile(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+	confPath := filepath.Join(t.TempDir(), ".tmux.conf")
+	os.WriteFile(confPath, []byte("set -g prefix C-a\nbind | split-window -h\n"), 0644)
+
+	env, stdout, stderr := testEnv("")
+	if code, _ := runSubcommand(env, []string{"import", "--config", configPath, confPath}); code != 0 {
+		t.Fatalf("import failed with code %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Imported my-tmux (1 shortcuts)") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+	data, err := os.ReadFile(filepath.Join(dataDir, "my-tmux.yaml"))
+	if err != nil || !strings.Contains(string(data), "C-a |") {
+		t.Errorf("app file should hold the binding, got %q, %v", data, err)
+	}
+}
+
+func TestImportCommand_VSCode(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+	jsonPath := filepath.Join(t.TempDir(), "keybindings.json")
+	os.WriteFile(jsonPath, []byte(`[{"key": "ctrl+k ctrl+c", "command": "editor.action.addCommentLine"},]`), 0644)
+
+	env, stdout, stderr := testEnv("")
+	if code, _ := runSubcommand(env, []string{"import", "--config", configPath, "--name", "vscode", jsonPath}); code != 0 {
+		t.Fatalf("import failed with code %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Imported vscode (1 shortcuts)") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "vscode.yaml")); err != nil {
+		t.Errorf("app file should be written: %v", err)
+	}
+}
+
+func TestExportCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+	output := filepath.Join(t.TempDir(), "table.html")
+
+	env, stdout, stderr := testEnv("")
+	if code, _ := runSubcommand(env, []string{"export", "--config", configPath, "--output", output}); code != 0 {
+		t.Fatalf("export failed with code %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Exported the table to "+output) {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+	data, err := os.ReadFile(output)
+	if err != nil || !strings.HasPrefix(string(data), "<!DOCTYPE html>") {
+		t.Errorf("the format should follow the extension, got %q (%v)", data, err)
+	}
+
+	env, stdout, _ = testEnv("")
+	if code, _ := runSubcommand(env, []string{"export", "--config", configPath}); code != 0 || !strings.HasPrefix(stdout.String(), "| Shortcut |") {
+		t.Errorf("export should write markdown to stdout, got %d: %s", code, stdout.String())
+	}
+
+	env, _, stderr = testEnv("")
+	if code, _ := runSubcommand(env, []string{"export", "--config", configPath, "--format", "pdf"}); code != 2 {
+		t.Errorf("an unknown format should exit 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), `unsupported export format "pdf"`) {
+		t.Errorf("unexpected error: %s", stderr.String())
+	}
+}
+
+func TestPrintCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+	output := filepath.Join(t.TempDir(), "vim.pdf")
+
+	env, stdout, stderr := testEnv("")
+	if code, _ := runSubcommand(env, []string{"print", "--config", configPath, "--apps", "vim", "--paper", "letter", "--output", output}); code != 0 {
+		t.Fatalf("print failed with code %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Printed the cheat sheet to "+output) {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+	data, err := os.ReadFile(output)
+	if err != nil || !bytes.HasPrefix(data, []byte("%PDF-")) || !bytes.Contains(data, []byte("/MediaBox [0 0 612.00 792.00]")) {
+		t.Errorf("a letter PDF should be written, got %v", err)
+	}
+
+	env, _, stderr = testEnv("")
+	if code, _ := runSubcommand(env, []string{"print", "--config", configPath, "--apps", "vim", "--search", "no such shortcut", "--output", "-"}); code != 1 {
+		t.Errorf("printing no shortcuts should fail, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "No shortcuts to print") {
+		t.Errorf("unexpected error: %s", stderr.String())
+	}
+
+	env, _, _ = testEnv("")
+	if code, _ := runSubcommand(env, []string{"print", "--config", configPath, "--paper", "a3"}); code != 2 {
+		t.Errorf("an unknown paper size should exit 2, got %d", code)
+	}
+}
+
+func TestDetectImportFormat(t *testing.T) {
+	for path, want := range map[string]string{
+		"sheet.md":                     "markdown",
+		"tar":                          "cheat",
+		"/home/me/.vimrc":              "vim",
+		"/home/me/.tmux.conf":          "tmux",
+		"/home/me/.config/i3/config":   "i3",
+		"/home/me/.config/sway/config": "sway",
+		"/home/me/config":              "cheat",
+		"notes.txt":                    "",
+	} {
+		if got := detectImportFormat(path); got != want {
+			t.Errorf("detectImportFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestImportCommand_AutoBackup(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+	data, _ := os.ReadFile(configPath)
+	os.WriteFile(configPath, append(data, "backup:\n  auto: true\n  keep: 1\n"...), 0644)
+	mdPath := filepath.Join(t.TempDir(), "tmux.md")
+	os.WriteFile(mdPath, []byte("# Tmux\n\n- `C-b d`: detach\n"), 0644)
+
+	for i := 0; i < 2; i++ {
+		env, _, stderr := testEnv("")
+		if code, _ := runSubcommand(env, []string{"import", "--config", configPath, mdPath}); code != 0 {
+			t.Fatalf("import failed with code %d: %s", code, stderr.String())
+		}
+	}
+	backups, _ := maintenance.ListBackups(filepath.Join(dataDir, "backups"))
+	if len(backups) != 1 || !backups[0].Auto {
+		t.Errorf("imports should leave the newest automatic backup, got %+v", backups)
+	}
+}
+
+func TestStorageCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+	os.WriteFile(filepath.Join(dataDir, "vim.yaml"), []byte("name: vim"), 0644)
+
+	env, stdout, _ := testEnv("")
+	if code, _ := runSubcommand(env, []string{"storage", "--config", configPath}); code != 0 {
+		t.Fatalf("storage failed with code %d", code)
+	}
+	for _, name := range []string{"apps", "notes", "cache", "backups", "Total"} {
+		if !strings.Contains(stdout.String(), name) {
+			t.Errorf("storage report should mention %s: %s", name, stdout.String())
+		}
+	}
+
+	newDir := filepath.Join(t.TempDir(), "moved")
+	env, _, stderr := testEnv("")
+	if code, _ := runSubcommand(env, []string{"storage", "move", "--config", configPath, "--yes", newDir}); code != 0 {
+		t.Fatalf("storage move failed with code %d: %s", code, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(newDir, "vim.yaml")); err != nil {
+		t.Errorf("data should be moved: %v", err)
+	}
+
+	cfg, err := config.NewLoader(configPath).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DataDir != newDir {
+		t.Errorf("config data_dir = %s, expected %s", cfg.DataDir, newDir)
+	}
+}
+
+func TestBackupCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+	os.WriteFile(filepath.Join(dataDir, "vim.yaml"), []byte("name: vim"), 0644)
+
+	run := func(stdin string, args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv(stdin)
+		code, _ := runSubcommand(env, append([]string{"backup"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	if _, out, _ := run("", "list"); !strings.Contains(out, "No backups yet") {
+		t.Errorf("list without backups:\n%s", out)
+	}
+	code, out, errOut := run("", "create", "--skip-cache")
+	if code != 0 || !strings.Contains(out, "Backup saved to") || strings.Contains(out, "cache") {
+		t.Fatalf("backup create = %d:\n%s %s", code, out, errOut)
+	}
+	backups, _ := maintenance.ListBackups(filepath.Join(dataDir, "backups"))
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %d", len(backups))
+	}
+	name := filepath.Base(backups[0].Path)
+	if _, out, _ := run("", "list"); !strings.Contains(out, name) {
+		t.Errorf("list should show %s:\n%s", name, out)
+	}
+
+	os.WriteFile(filepath.Join(dataDir, "vim.yaml"), []byte("name: changed"), 0644)
+	if code, out, _ := run("n\n", "restore", name); code != 0 || !strings.Contains(out, "Aborted") {
+		t.Errorf("declining should abort the restore = %d:\n%s", code, out)
+	}
+	if code, out, errOut := run("", "restore", "--yes", name); code != 0 || !strings.Contains(out, "previous data was saved") {
+		t.Fatalf("backup restore = %d:\n%s %s", code, out, errOut)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dataDir, "vim.yaml")); string(data) != "name: vim" {
+		t.Errorf("vim.yaml after restore = %q", data)
+	}
+	if backups, _ := maintenance.ListBackups(filepath.Join(dataDir, "backups")); len(backups) != 2 {
+		t.Errorf("restore should back up the replaced data, got %d backups", len(backups))
+	}
+
+	if code, _, _ := run("", "restore", "--yes", "missing.tar.gz"); code != 1 {
+		t.Error("restoring a missing backup should fail")
+	}
+}
+
+func TestBundleCommand(t *testing.T) {
+	writeConfig := func(trustedKeys ...string) string {
+		cfg := config.DefaultConfig()
+		cfg.DataDir = t.TempDir()
+		cfg.Online.Provider = "mock"
+		cfg.Signatures.TrustedKeys = trustedKeys
+		data, _ := yaml.Marshal(cfg)
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		os.WriteFile(path, data, 0644)
+		return path
+	}
+	run := func(configPath string, args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"bundle"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	connected := writeConfig()
+	bundle := filepath.Join(t.TempDir(), "sheets.tar.gz")
+	if code, _, _ := run(connected, "export", "--output", bundle, "missing-sheet"); code != 1 {
+		t.Error("exporting an unknown sheet should fail")
+	}
+	code, out, errOut := run(connected, "export", "--output", bundle, "--repo", "https://github.com/cheat-go/community")
+	if code != 0 || !strings.Contains(out, "Bundled 2 of 2 sheets") {
+		t.Fatalf("bundle export = %d:\n%s %s", code, out, errOut)
+	}
+	env, stdout, _ := testEnv("")
+	if code, _ := runSubcommand(env, []string{"bundle", "list", bundle}); code != 0 ||
+		!strings.Contains(stdout.String(), "Vim Advanced") || !strings.Contains(stdout.String(), "Git Workflow") {
+		t.Errorf("bundle list = %d:\n%s", code, stdout.String())
+	}
+
+	// the air-gapped machine trusts keys, and the mock sheets are unsigned
+	public, _, _ := signature.GenerateKey()
+	airGapped := writeConfig(public.String())
+	if code, _, errOut := run(airGapped, "import", bundle); code != 1 || !strings.Contains(errOut, "not signed") {
+		t.Errorf("importing unsigned sheets should fail = %d:\n%s", code, errOut)
+	}
+	code, out, errOut = run(airGapped, "import", "--allow-unverified", "--enable", bundle)
+	if code != 0 || !strings.Contains(out, "Installed vim-advanced") || !strings.Contains(out, "Enabled git-workflow") {
+		t.Fatalf("bundle import = %d:\n%s %s", code, out, errOut)
+	}
+	if code, _, errOut := run(airGapped, "import", "--allow-unverified", bundle); code != 1 || !strings.Contains(errOut, "already installed") {
+		t.Errorf("importing installed sheets without --force = %d:\n%s", code, errOut)
+	}
+
+	cfg, err := config.NewLoader(airGapped).Load()
+	if err != nil || !slices.Contains(cfg.Apps, "vim-advanced") {
+		t.Errorf("the imported apps should be enabled, got %v (%v)", cfg.Apps, err)
+	}
+	store, err := setup.OpenStorage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	var installed []online.InstalledSheet
+	storage.GetJSON(store, storage.CollectionSession, online.InstalledSheetsKey, &installed)
+	if len(installed) != 2 || installed[0].ID != "vim-advanced" || installed[0].Repository != "https://github.com/cheat-go/community" {
+		t.Errorf("installed sheets = %+v", installed)
+	}
+}
+
+func TestStatsCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+
+	env, stdout, _ := testEnv("")
+	if code, _ := runSubcommand(env, []string{"stats", "--config", configPath}); code != 0 {
+		t.Fatalf("stats failed with code %d", code)
+	}
+	if !strings.Contains(stdout.String(), "No practice yet") {
+		t.Errorf("stats without practice = %q", stdout.String())
+	}
+
+	tracker, err := practice.NewTracker(storage.NewFileStorage(dataDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.Record(practice.Question{App: "vim", Shortcut: apps.Shortcut{Keys: "gg"}}, true)
+
+	env, stdout, _ = testEnv("")
+	if code, _ := runSubcommand(env, []string{"stats", "--config", configPath, "--json"}); code != 0 {
+		t.Fatalf("stats --json failed with code %d", code)
+	}
+	var stats practice.Summary
+	if err := json.Unmarshal(stdout.Bytes(), &stats); err != nil {
+		t.Fatalf("stats --json printed invalid JSON: %v", err)
+	}
+	if stats.Reviews != 1 || len(stats.Apps) != 1 || stats.CurrentStreak != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestConfigCommand(t *testing.T) {
+	configPath := writeTestConfig(t, t.TempDir())
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"config"}, args...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	if code, out, _ := run("get", "--config", configPath, "theme"); code != 0 || out != "default\n" {
+		t.Errorf("config get theme = %d %q", code, out)
+	}
+	if code, _, stderr := run("set", "--config", configPath, "layout.table_style", "rounded"); code != 0 {
+		t.Fatalf("config set failed with code %d: %s", code, stderr)
+	}
+	if _, out, _ := run("set", "--config", configPath, "apps", "vim,zsh"); !strings.HasPrefix(out, "Set apps = vim, zsh in ") {
+		t.Errorf("config set should print a list on one line, got %q", out)
+	}
+	cfg, err := config.NewLoader(configPath).Load()
+	if err != nil || cfg.Layout.TableStyle != "rounded" {
+		t.Errorf("config set should be saved, got %+v, %v", cfg.Layout, err)
+	}
+
+	if code, _, _ := run("set", "--config", configPath, "theme", "neon"); code != 1 {
+		t.Errorf("invalid values should be rejected, got code %d", code)
+	}
+	if code, _, _ := run("set", "--config", configPath, "colour", "red"); code != 2 {
+		t.Errorf("unknown keys should be rejected, got code %d", code)
+	}
+	if cfg, _ := config.NewLoader(configPath).Load(); cfg.Theme != "default" {
+		t.Errorf("rejected changes should not be saved, theme = %s", cfg.Theme)
+	}
+
+	if code, out, _ := run("validate", "--config", configPath); code != 0 || !strings.Contains(out, "ok") {
+		t.Errorf("config validate = %d %q", code, out)
+	}
+	os.WriteFile(configPath, []byte("theme: neon\n"), 0644)
+	if code, out, _ := run("validate", "--config", configPath); code != 1 || !strings.Contains(out, "invalid theme") {
+		t.Errorf("config validate of a broken file = %d %q", code, out)
+	}
+	if code, _, _ := run("set", "--config", configPath, "theme", "dark"); code != 1 {
+		t.Errorf("set should refuse to overwrite a broken file, got code %d", code)
+	}
+	if code, out, _ := run("path", "--config", configPath); code != 0 || strings.TrimSpace(out) != configPath {
+		t.Errorf("config path = %q", out)
+	}
+}
+
+func TestLoginLogoutCommands(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+
+	env, _, stderr := testEnv("")
+	if code, _ := runSubcommand(env, []string{"login", "--config", configPath}); code != 1 {
+		t.Errorf("login without auth config should fail, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "client_id") {
+		t.Errorf("login should explain missing config: %s", stderr.String())
+	}
+
+	tokenPath := filepath.Join(dataDir, "token.json")
+	os.WriteFile(tokenPath, []byte(`{"access_token":"x"}`), 0600)
+
+	env, stdout, _ := testEnv("")
+	if code, _ := runSubcommand(env, []string{"logout", "--config", configPath}); code != 0 {
+		t.Fatalf("logout failed with code %d", code)
+	}
+	if _, err := os.Stat(tokenPath); !os.IsNotExist(err) {
+		t.Errorf("logout should remove the token: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Logged out") {
+		t.Errorf("unexpected logout output: %s", stdout.String())
+	}
+}
+
+func TestNotesCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+
+	run := func(input string, args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv(input)
+		code, _ := runSubcommand(env, append([]string{"notes"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	code, out, errOut := run("first body\n", "add", "--title", "First", "--tags", "old,vim", "--app", "vim")
+	if code != 0 {
+		t.Fatalf("notes add failed: %s", errOut)
+	}
+	firstID := strings.TrimSpace(out)
+	if _, out, _ = run("second body", "add", "--title", "Second", "--tags", "old"); out == "" {
+		t.Fatal("notes add should print the new ID")
+	}
+	secondID := strings.TrimSpace(out)
+	run("", "add", "--title", "Keep")
+
+	if _, out, _ = run("", "search", "body", "--app", "vim"); !strings.Contains(out, "First") || strings.Contains(out, "Second") {
+		t.Errorf("search should filter by query and app: %s", out)
+	}
+
+	if code, _, errOut = run("", "edit", firstID, "--title", "Renamed"); code != 0 {
+		t.Fatalf("notes edit failed: %s", errOut)
+	}
+	if code, _, errOut = run("", "tag", secondID, "--add", "new", "--remove", "old"); code != 0 {
+		t.Fatalf("notes tag failed: %s", errOut)
+	}
+
+	_, out, _ = run("", "list", "--json")
+	var listed []struct {
+		ID      string   `json:"id"`
+		Title   string   `json:"title"`
+		Content string   `json:"content"`
+		Tags    []string `json:"tags"`
+	}
+	if err := yaml.Unmarshal([]byte(out), &listed); err != nil || len(listed) != 3 {
+		t.Fatalf("list --json = %s (%v)", out, err)
+	}
+	for _, n := range listed {
+		switch n.ID {
+		case firstID:
+			if n.Title != "Renamed" || n.Content != "first body" {
+				t.Errorf("edited note = %+v", n)
+			}
+		case secondID:
+			if strings.Join(n.Tags, ",") != "new" {
+				t.Errorf("retagged note tags = %v", n.Tags)
+			}
+		}
+	}
+
+	if _, out, _ = run("", "show", firstID); !strings.Contains(out, "Title:     Renamed") || !strings.Contains(out, "App:       vim") ||
+		!strings.HasSuffix(out, "\nfirst body\n") {
+		t.Errorf("show should print the fields and content:\n%s", out)
+	}
+	if _, out, _ = run(firstID+"\n", "show", "--content", "-"); out != "first body\n" {
+		t.Errorf("show --content = %q", out)
+	}
+	if code, out, _ = run("", "show", "--json", secondID, "missing"); code != 1 || !strings.Contains(out, `"title": "Second"`) {
+		t.Errorf("show should print the notes found and fail for the rest = %d:\n%s", code, out)
+	}
+
+	// pipe the IDs of notes tagged old into delete
+	_, ids, _ := run("", "list", "--tag", "old", "-q")
+	if strings.TrimSpace(ids) != firstID {
+		t.Fatalf("list -q --tag old = %q, want %s", ids, firstID)
+	}
+	if code, _, errOut = run(ids, "delete", "-"); code != 0 {
+		t.Fatalf("notes delete failed: %s", errOut)
+	}
+	if code, _, _ = run("", "delete", firstID); code != 1 {
+		t.Error("deleting a missing note should fail")
+	}
+
+	_, out, _ = run("", "export", "--format", "markdown")
+	if !strings.Contains(out, "Keep") || strings.Contains(out, "Renamed") {
+		t.Errorf("export should contain remaining notes only: %s", out)
+	}
+}
+
+func TestNotesCommand_Templates(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"notes"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	templatesDir := filepath.Join(dataDir, "notes", "templates")
+	if err := os.MkdirAll(templatesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	custom := "description: Daily standup\ntitle: Standup {{date}}\ncontent: Worked on {{app}}\n"
+	if err := os.WriteFile(filepath.Join(templatesDir, "standup.yaml"), []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, out, _ := run("templates"); !strings.Contains(out, "app-cheatsheet") || !strings.Contains(out, "standup") {
+		t.Errorf("templates should list built-in and user templates:\n%s", out)
+	}
+
+	code, out, errOut := run("add", "--template", "standup", "--app", "vim")
+	if code != 0 {
+		t.Fatalf("notes add --template failed: %s", errOut)
+	}
+	id := strings.TrimSpace(out)
+
+	_, out, _ = run("list", "--json")
+	var listed []notes.Note
+	if err := json.Unmarshal([]byte(out), &listed); err != nil || len(listed) != 1 {
+		t.Fatalf("list --json = %s (%v)", out, err)
+	}
+	note := listed[0]
+	if note.ID != id || note.Content != "Worked on vim" || note.AppName != "vim" {
+		t.Errorf("templated note = %+v", note)
+	}
+	if want := "Standup " + time.Now().Format("2006-01-02"); note.Title != want {
+		t.Errorf("title = %q, want %q", note.Title, want)
+	}
+
+	if code, _, _ := run("add", "--template", "missing"); code != 1 {
+		t.Errorf("unknown template should fail, got %d", code)
+	}
+}
+
+func TestNotesCommand_Shortcut(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("body")
+		code, _ := runSubcommand(env, append([]string{"notes"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	if code, _, _ := run("add", "--title", "Top", "--shortcut", "gg"); code != 2 {
+		t.Errorf("--shortcut without --app should be a usage error, got %d", code)
+	}
+
+	code, out, errOut := run("add", "--title", "Top", "--app", "vim", "--shortcut", "gg")
+	if code != 0 {
+		t.Fatalf("notes add --shortcut failed: %s", errOut)
+	}
+	id := strings.TrimSpace(out)
+	run("add", "--title", "Other", "--app", "vim")
+
+	if _, out, _ := run("list", "--shortcut", "gg", "-q"); strings.TrimSpace(out) != id {
+		t.Errorf("list --shortcut gg = %q, want %s", out, id)
+	}
+
+	if code, _, errOut := run("edit", id, "--shortcut", ""); code != 0 {
+		t.Fatalf("detaching failed: %s", errOut)
+	}
+	if _, out, _ := run("list", "--shortcut", "gg", "-q"); strings.TrimSpace(out) != "" {
+		t.Errorf("detached note should not be listed: %q", out)
+	}
+}
+
+func TestNotesCommand_Trash(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("body")
+		code, _ := runSubcommand(env, append([]string{"notes"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	_, out, _ := run("add", "--title", "Temporary")
+	id := strings.TrimSpace(out)
+	_, out, _ = run("add", "--title", "Scratch")
+	scratchID := strings.TrimSpace(out)
+
+	run("delete", id, scratchID)
+	if _, out, _ := run("list", "-q"); strings.TrimSpace(out) != "" {
+		t.Errorf("deleted notes should not be listed: %q", out)
+	}
+	if _, out, _ := run("trash"); !strings.Contains(out, "Temporary") || !strings.Contains(out, "Scratch") {
+		t.Errorf("trash should list deleted notes:\n%s", out)
+	}
+
+	if code, _, errOut := run("restore", id); code != 0 {
+		t.Fatalf("notes restore failed: %s", errOut)
+	}
+	if _, out, _ := run("list", "-q"); strings.TrimSpace(out) != id {
+		t.Errorf("restored note should be listed, got %q", out)
+	}
+	if code, _, _ := run("restore", id); code != 1 {
+		t.Error("restoring a note that is not in the trash should fail")
+	}
+
+	if _, out, _ := run("empty-trash"); !strings.Contains(out, "Removed 1 notes") {
+		t.Errorf("empty-trash = %q", out)
+	}
+	if _, out, _ := run("trash", "-q"); strings.TrimSpace(out) != "" {
+		t.Errorf("trash should be empty, got %q", out)
+	}
+}
+
+func TestNotesCommand_Import(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir)
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"notes"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	vault := t.TempDir()
+	os.WriteFile(filepath.Join(vault, "macros.md"), []byte("---\ntitle: Macros\ntags: [vim]\n---\nqa...q\n"), 0644)
+	os.WriteFile(filepath.Join(vault, "bad.md"), []byte("---\ntitle: [\n"), 0644)
+
+	code, out, errOut := run("import", vault, "--dry-run")
+	if code != 0 || !strings.Contains(out, `Would import macros.md as "Macros"`) || !strings.Contains(out, "Skipped bad.md") {
+		t.Fatalf("import --dry-run = %d %q %q", code, out, errOut)
+	}
+	if _, out, _ := run("list", "-q"); strings.TrimSpace(out) != "" {
+		t.Errorf("a dry run should not create notes: %q", out)
+	}
+
+	if code, out, errOut = run("import", vault, "--app", "vim"); code != 0 || !strings.Contains(out, "Imported 1 notes") {
+		t.Fatalf("import = %d %q %q", code, out, errOut)
+	}
+	if _, out, _ := run("list", "--app", "vim"); !strings.Contains(out, "Macros") {
+		t.Errorf("imported note should be listed: %q", out)
+	}
+}
+
+func TestAppsCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir, "vim")
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"apps"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	version := "1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "name: remote\ndescription: Remote v%s\nshortcuts:\n  - keys: x\n    description: Exit\n", version)
+	}))
+	defer server.Close()
+
+	local := filepath.Join(t.TempDir(), "local.yaml")
+	os.WriteFile(local, []byte("name: local\ndescription: Local app\nshortcuts:\n  - keys: a\n    description: Add\n"), 0644)
+	broken := filepath.Join(t.TempDir(), "broken.yaml")
+	os.WriteFile(broken, []byte("name: broken\n"), 0644)
+
+	if code, out, _ := run("validate", local, broken); code != 1 || !strings.Contains(out, "local.yaml: ok") {
+		t.Errorf("validate = %d: %s", code, out)
+	}
+
+	if code, _, errOut := run("add", local, "--enable"); code != 0 {
+		t.Fatalf("apps add failed: %s", errOut)
+	}
+	if code, _, errOut := run("add", server.URL+"/remote.yaml"); code != 0 {
+		t.Fatalf("apps add URL failed: %s", errOut)
+	}
+	if code, _, _ := run("add", local); code != 1 {
+		t.Error("adding an installed app without --force should fail")
+	}
+
+	_, out, _ := run("list")
+	for _, want := range []string{"local                user     enabled", "remote               url", "vim                  builtin  enabled"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("list should contain %q:\n%s", want, out)
+		}
+	}
+
+	// the second listing describes the installed apps from the index
+	_, out, errOut := run("list", "--offset", "2", "--limit", "2")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 ||
+		!strings.HasPrefix(lines[0], "local ") || !strings.HasPrefix(lines[1], "remote ") || !strings.Contains(lines[1], "   1  Remote v1") {
+		t.Errorf("list should show the page of apps:\n%s", out)
+	}
+	if !strings.Contains(errOut, "Apps 3-4 of 8") {
+		t.Errorf("a page should tell where it is: %q", errOut)
+	}
+
+	version = "2"
+	if code, out, errOut := run("update"); code != 0 || !strings.Contains(out, "Updated remote") {
+		t.Fatalf("apps update = %d: %s %s", code, out, errOut)
+	}
+	data, _ := os.ReadFile(filepath.Join(dataDir, "remote.yaml"))
+	if !strings.Contains(string(data), "Remote v2") {
+		t.Errorf("update should re-download the app: %s", data)
+	}
+
+	run("enable", "remote")
+	run("disable", "vim")
+	if code, _, _ := run("enable", "missing"); code != 1 {
+		t.Error("enabling an unknown app should fail")
+	}
+	run("remove", "local")
+
+	cfg, err := config.NewLoader(configPath).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(cfg.Apps, ",") != "remote" {
+		t.Errorf("config apps = %v, want [remote]", cfg.Apps)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "local.yaml")); !os.IsNotExist(err) {
+		t.Errorf("removed app file should be deleted: %v", err)
+	}
+}
+
+func TestAppsAddSigned(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir, "vim")
+
+	public, secret, err := signature.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgLoader := config.NewLoader(configPath)
+	cfg, err := cfgLoader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Signatures.TrustedKeys = []string{public.String()}
+	if err := cfgLoader.Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"apps"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	app := func(name string) []byte {
+		return []byte("name: " + name + "\ndescription: Remote\nshortcuts:\n  - keys: x\n    description: Exit\n")
+	}
+	files := map[string][]byte{
+		"/signed.yaml":           app("signed"),
+		"/signed.yaml.minisig":   secret.Sign(app("signed"), ""),
+		"/tampered.yaml":         app("tampered"),
+		"/tampered.yaml.minisig": secret.Sign(app("original"), ""),
+		"/unsigned.yaml":         app("unsigned"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	if code, _, errOut := run("add", server.URL+"/signed.yaml"); code != 0 {
+		t.Fatalf("adding a signed app failed: %s", errOut)
+	}
+	if code, _, errOut := run("add", server.URL+"/tampered.yaml", "--allow-unverified"); code != 1 || !strings.Contains(errOut, "invalid signature") {
+		t.Errorf("adding a tampered app = %d: %s", code, errOut)
+	}
+	if code, _, errOut := run("add", server.URL+"/unsigned.yaml"); code != 1 || !strings.Contains(errOut, "content is not signed") {
+		t.Errorf("adding an unsigned app = %d: %s", code, errOut)
+	}
+	for _, name := range []string{"tampered.yaml", "unsigned.yaml"} {
+		if _, err := os.Stat(filepath.Join(dataDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not be installed: %v", name, err)
+		}
+	}
+	if code, _, errOut := run("add", server.URL+"/unsigned.yaml", "--allow-unverified"); code != 0 || !strings.Contains(errOut, "Warning") {
+		t.Errorf("adding an unsigned app with --allow-unverified = %d: %s", code, errOut)
+	}
+
+	// an update signed no more is refused too
+	files["/signed.yaml"] = []byte(strings.Replace(string(app("signed")), "Remote", "Altered", 1))
+	if code, out, errOut := run("update", "signed"); code != 1 || !strings.Contains(errOut, "invalid signature") {
+		t.Errorf("updating an altered app = %d: %s %s", code, out, errOut)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dataDir, "signed.yaml")); strings.Contains(string(data), "Altered") {
+		t.Errorf("an altered app should not be saved: %s", data)
+	}
+}
+
+func TestPluginCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir, "vim")
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"plugin"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	source := filepath.Join(t.TempDir(), "hello.yml")
+	os.WriteFile(source, []byte("name: hello\nversion: 1.0.0\nauthor: me\ndescription: Says hello\nconfig:\n  interpreter: bash\n"), 0644)
+	invalid := filepath.Join(t.TempDir(), "invalid.yaml")
+	os.WriteFile(invalid, []byte("version: 1.0.0\n"), 0644)
+
+	if code, _, _ := run("install", invalid); code != 1 {
+		t.Error("installing a plugin without a name should fail")
+	}
+	if code, _, errOut := run("install", source); code != 0 {
+		t.Fatalf("plugin install failed: %s", errOut)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "plugins", "hello.yaml")); err != nil {
+		t.Fatalf("plugin should be installed in the plugin directory: %v", err)
+	}
+	if code, _, _ := run("install", source); code != 1 {
+		t.Error("installing an installed plugin without --force should fail")
+	}
+	if code, _, errOut := run("install", "--force", source); code != 0 {
+		t.Fatalf("plugin install --force failed: %s", errOut)
+	}
+
+	if _, out, _ := run("list"); !strings.Contains(out, "hello                1.0.0      enabled") {
+		t.Errorf("list should show the enabled plugin:\n%s", out)
+	}
+
+	if code, out, errOut := run("disable", "hello"); code != 0 || !strings.Contains(out, "Disabled hello") {
+		t.Fatalf("plugin disable = %d: %s %s", code, out, errOut)
+	}
+	if code, _, _ := run("disable", "missing"); code != 1 {
+		t.Error("disabling an unknown plugin should fail")
+	}
+	cfg, err := config.NewLoader(configPath).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(cfg.Plugins.Disabled, "hello") {
+		t.Errorf("disabled plugins should be saved in the config: %v", cfg.Plugins.Disabled)
+	}
+
+	loader := newPluginLoader(cfg)
+	loader.LoadAll()
+	if _, err := loader.GetPlugin("hello"); err == nil {
+		t.Error("disabled plugins should not be registered")
+	}
+
+	code, out, _ := run("info", "hello")
+	for _, want := range []string{"Version:     1.0.0", "State:       disabled", "interpreter: bash"} {
+		if code != 0 || !strings.Contains(out, want) {
+			t.Errorf("info should contain %q:\n%s", want, out)
+		}
+	}
+
+	run("enable", "hello")
+	_, out, _ = run("list", "--json")
+	var infos []pluginInfo
+	if err := json.Unmarshal([]byte(out), &infos); err != nil || len(infos) != 1 || !infos[0].Enabled {
+		t.Errorf("list --json = %s (%v)", out, err)
+	}
+
+	if code, out, errOut := run("remove", "hello"); code != 0 || !strings.Contains(out, "Removed hello") {
+		t.Fatalf("plugin remove = %d: %s %s", code, out, errOut)
+	}
+	if _, out, _ := run("list"); !strings.Contains(out, "No plugins installed") {
+		t.Errorf("removed plugin should not be listed:\n%s", out)
+	}
+}
+
+func TestPluginInstallSigned(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir, "vim")
+
+	public, secret, err := signature.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgLoader := config.NewLoader(configPath)
+	cfg, err := cfgLoader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Signatures.TrustedKeys = []string{public.String()}
+	if err := cfgLoader.Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"plugin"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	dir := t.TempDir()
+	signed := []byte("name: signed\nversion: 1.0.0\n")
+	os.WriteFile(filepath.Join(dir, "signed.yaml"), signed, 0644)
+	os.WriteFile(filepath.Join(dir, "signed.yaml.minisig"), secret.Sign(signed, ""), 0644)
+	os.WriteFile(filepath.Join(dir, "unsigned.yaml"), []byte("name: unsigned\nversion: 1.0.0\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "altered.yaml"), []byte("name: altered\nversion: 6.6.6\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "altered.yaml.minisig"), secret.Sign([]byte("name: altered\n"), ""), 0644)
+
+	if code, _, errOut := run("install", filepath.Join(dir, "signed.yaml")); code != 0 {
+		t.Fatalf("installing a signed plugin failed: %s", errOut)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "plugins", "signed.yaml.minisig")); err != nil {
+		t.Errorf("the signature should be installed beside the plugin: %v", err)
+	}
+	code, out, errOut := run("install", filepath.Join(dir, "unsigned.yaml"))
+	if code != 0 || !strings.Contains(out, "could not be verified") {
+		t.Errorf("installing an unsigned plugin = %d: %s %s", code, out, errOut)
+	}
+	if code, _, errOut := run("install", filepath.Join(dir, "altered.yaml")); code != 1 || !strings.Contains(errOut, "invalid signature") {
+		t.Errorf("installing an altered plugin = %d: %s", code, errOut)
+	}
+
+	// the script of a Lua plugin must not run before its signature is checked
+	marker := filepath.Join(t.TempDir(), "ran")
+	script := []byte(fmt.Sprintf("local f = io.open(%q, \"w\")\nf:write(\"ran\")\nf:close()\n", marker))
+	os.WriteFile(filepath.Join(dir, "tampered.lua"), script, 0644)
+	os.WriteFile(filepath.Join(dir, "tampered.lua.minisig"), secret.Sign([]byte("plugin = {}\n"), ""), 0644)
+	os.WriteFile(filepath.Join(dir, "unverified.lua"), script, 0644)
+	if code, _, errOut := run("install", filepath.Join(dir, "tampered.lua")); code != 1 || !strings.Contains(errOut, "invalid signature") {
+		t.Errorf("installing a tampered Lua plugin = %d: %s", code, errOut)
+	}
+	if code, out, errOut := run("install", filepath.Join(dir, "unverified.lua")); code != 0 || !strings.Contains(out, "could not be verified") {
+		t.Errorf("installing an unsigned Lua plugin = %d: %s %s", code, out, errOut)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("the script of an unverified plugin ran")
+	}
+
+	_, out, _ = run("list")
+	for _, want := range []string{"signed               1.0.0      enabled", "unsigned             1.0.0      untrusted"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("list should contain %q:\n%s", want, out)
+		}
+	}
+	if _, out, _ := run("info", "unsigned"); !strings.Contains(out, "State:       not run: content is not signed") {
+		t.Errorf("info should tell why the plugin is not run:\n%s", out)
+	}
+}
+
+func TestPluginRunCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir, "vim")
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"plugin"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	source := filepath.Join(t.TempDir(), "notes.lua")
+	os.WriteFile(source, []byte(`
+plugin = { version = "0.1.0", description = "Takes notes" }
+cheat.command("jot", function(title) return cheat.note{ title = title, app = "vim" } end)
+cheat.command("shortcuts", function(app) return #cheat.app(app).shortcuts end)
+`), 0644)
+
+	if code, _, errOut := run("install", source); code != 0 {
+		t.Fatalf("plugin install failed: %s", errOut)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "plugins", "notes.lua")); err != nil {
+		t.Fatalf("Lua plugin should be installed as notes.lua: %v", err)
+	}
+	if _, out, _ := run("info", "notes"); !strings.Contains(out, "Type:        lua") || !strings.Contains(out, "Commands:    jot, shortcuts") {
+		t.Errorf("info should show the Lua plugin and its commands:\n%s", out)
+	}
+
+	if code, out, _ := run("run", "notes"); code != 0 || out != "jot\nshortcuts\n" {
+		t.Errorf("run without a command should list commands = %d: %q", code, out)
+	}
+	if code, out, errOut := run("run", "notes", "shortcuts", "vim"); code != 0 || strings.TrimSpace(out) == "0" {
+		t.Errorf("run shortcuts = %d: %q %s", code, out, errOut)
+	}
+	code, _, errOut := run("run", "notes", "jot", "From Lua")
+	if code != 0 {
+		t.Fatalf("run jot = %d: %s", code, errOut)
+	}
+	env, stdout, _ := testEnv("")
+	runSubcommand(env, []string{"notes", "list", "--config", configPath})
+	if !strings.Contains(stdout.String(), "From Lua") {
+		t.Errorf("the note created by the plugin should be saved:\n%s", stdout.String())
+	}
+
+	if code, _, _ := run("run", "notes", "missing"); code != 1 {
+		t.Error("running an unknown command should fail")
+	}
+	if code, _, _ := run("run"); code != 2 {
+		t.Error("run without a plugin should be a usage error")
+	}
+}
+
+func TestSyncCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir, "vim")
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"sync"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	if code, _, errOut := run("status"); code != 1 || !strings.Contains(errOut, "not configured") {
+		t.Errorf("status without an endpoint = %d: %s", code, errOut)
+	}
+
+	local := time.Now().Add(-time.Hour).UTC()
+	notesDir := filepath.Join(dataDir, "notes")
+	os.MkdirAll(notesDir, 0755)
+	data, _ := json.Marshal([]*notes.Note{{ID: "note-1", Title: "Local", UpdatedAt: local}})
+	os.WriteFile(filepath.Join(notesDir, "notes.json"), data, 0644)
+
+	resolveStatus := http.StatusInternalServerError
+	var pushes int
+	var revoked bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pull":
+			json.NewEncoder(w).Encode(sync.SyncData{
+				Timestamp: time.Now().Add(-2 * time.Hour),
+				Notes:     []*notes.Note{{ID: "note-1", Title: "Remote", UpdatedAt: local.Add(time.Minute)}},
+			})
+		case "/push":
+			pushes++
+		case "/resolve":
+			w.WriteHeader(resolveStatus)
+		case "/devices":
+			json.NewEncoder(w).Encode([]sync.Device{{ID: "old-laptop", Name: "Old laptop", LastSeen: local}})
+		case "/devices/old-laptop":
+			revoked = r.Method == "DELETE"
+		default:
+			// a server without delta sync
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	loader := config.NewLoader(configPath)
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Sync = config.SyncConfig{Enabled: true, Endpoint: server.URL, APIKey: "key"}
+	if err := loader.Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _, errOut := run("now"); code != 1 || !strings.Contains(errOut, "1 unresolved conflicts") {
+		t.Fatalf("sync now with a failing resolve = %d: %s", code, errOut)
+	}
+	if code, out, _ := run("status"); code != 1 || !strings.Contains(out, "Conflicts:  1") || !strings.Contains(out, "Last error:") {
+		t.Errorf("status should report the failed sync = %d:\n%s", code, out)
+	}
+	if _, out, _ := run("conflicts"); !strings.Contains(out, "note-1") {
+		t.Errorf("conflicts should list note-1:\n%s", out)
+	}
+
+	if code, _, _ := run("resolve", "note-1", "newest"); code != 2 {
+		t.Error("an unknown strategy should be a usage error")
+	}
+	resolveStatus = http.StatusOK
+	if code, out, errOut := run("resolve", "note-1", "remote"); code != 0 || !strings.Contains(out, "Resolved note-1 (remote)") {
+		t.Fatalf("sync resolve = %d: %s %s", code, out, errOut)
+	}
+	if _, out, _ := run("conflicts", "--json"); strings.TrimSpace(out) != "[]" {
+		t.Errorf("resolved conflicts should not be listed: %s", out)
+	}
+
+	if code, out, errOut := run("now"); code != 0 || !strings.Contains(out, "Synced at") || pushes != 1 {
+		t.Fatalf("sync now = %d (%d pushes): %s %s", code, pushes, out, errOut)
+	} else if !strings.Contains(out, "Pulling remote data...") || !strings.Contains(out, "note-1 (note): kept remote") {
+		t.Errorf("sync now should print progress and resolved conflicts:\n%s", out)
+	}
+	if code, out, _ := run("now", "-q"); code != 0 || out != "" {
+		t.Errorf("sync now -q = %d:\n%s", code, out)
+	}
+	code, out, _ := run("now", "--json")
+	var report struct {
+		OK       bool `json:"ok"`
+		Notes    int  `json:"notes"`
+		Resolved []struct {
+			ID         string `json:"id"`
+			Resolution string `json:"resolution"`
+		} `json:"resolved"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil || code != 0 || !report.OK || report.Notes != 1 ||
+		len(report.Resolved) != 1 || report.Resolved[0].Resolution != "remote" {
+		t.Errorf("sync now --json = %d:\n%s", code, out)
+	}
+	_, out, _ = run("status", "--json")
+	var status sync.SyncStatus
+	if err := json.Unmarshal([]byte(out), &status); err != nil || status.LastSync.IsZero() || status.LastError != "" {
+		t.Errorf("status --json = %s (%v)", out, err)
+	}
+
+	if code, out, _ := run("devices"); code != 0 || !strings.Contains(out, "Old laptop") {
+		t.Errorf("sync devices = %d:\n%s", code, out)
+	}
+	if code, out, _ := run("rename", "Work desktop"); code != 0 || !strings.Contains(out, `"Work desktop"`) {
+		t.Errorf("sync rename = %d:\n%s", code, out)
+	}
+	if _, out, _ := run("status"); !strings.Contains(out, "Device:     Work desktop") {
+		t.Errorf("status should show the device name:\n%s", out)
+	}
+	if code, out, errOut := run("revoke", "old-laptop"); code != 0 || !revoked || !strings.Contains(out, "Revoked old-laptop") {
+		t.Errorf("sync revoke = %d: %s %s", code, out, errOut)
+	}
+	if code, _, _ := run("revoke"); code != 2 {
+		t.Error("revoke without an ID should be a usage error")
+	}
+}
+
+func TestCacheCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir, "vim", "zsh")
+
+	run := func(args ...string) (int, string, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"cache"}, append(args, "--config", configPath)...))
+		return code, stdout.String(), stderr.String()
+	}
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `[{"name":"community"}]`)
+	}))
+	defer server.Close()
+
+	loader := config.NewLoader(configPath)
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Online.Provider = "http"
+	cfg.Online.APIURL = server.URL
+	if err := loader.Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, out, errOut := run("warm"); code != 0 || !strings.Contains(out, "Cached 1 online repositories") {
+		t.Fatalf("cache warm = %d: %s %s", code, out, errOut)
+	}
+	if _, out, _ := run("stats"); !strings.Contains(out, "Entries:   2") {
+		t.Errorf("stats should count the table and repositories:\n%s", out)
+	}
+
+	// a later start reuses both entries from disk
+	responses := newCache(cfg)
+	registry := apps.NewRegistryWithStorage(setup.FileStorage(cfg))
+	registry.LoadApps(cfg.Apps)
+	if _, err := responses.Get(tableCacheKey(registry, cfg.Apps)); err != nil {
+		t.Errorf("warmed table should be cached: %v", err)
+	}
+	newOnlineClient(cfg, responses).GetRepositories()
+	if requests != 1 {
+		t.Errorf("warmed repositories should be served from the cache, got %d requests", requests)
+	}
+
+	if _, out, _ := run("gc"); !strings.Contains(out, "Removed 0 expired entries") {
+		t.Errorf("gc should keep fresh entries: %s", out)
+	}
+	if _, out, _ := run("gc", "--ttl", "1ns"); !strings.Contains(out, "Removed 2 expired entries") {
+		t.Errorf("gc --ttl should remove old entries: %s", out)
+	}
+
+	run("warm")
+	if code, out, _ := run("clear", "--namespace", "online"); code != 0 || !strings.Contains(out, "Cleared 1 entries") {
+		t.Errorf("cache clear --namespace online = %d: %s", code, out)
+	}
+	if _, err := newCache(cfg).Get(tableCacheKey(registry, cfg.Apps)); err != nil {
+		t.Errorf("clearing the online namespace should keep the table: %v", err)
+	}
+	if code, _, _ := run("clear", "--namespace", "bogus"); code != 2 {
+		t.Errorf("unknown namespace should be a usage error, got %d", code)
+	}
+
+	run("warm")
+	if code, out, _ := run("clear"); code != 0 || !strings.Contains(out, "Cleared 2 entries") {
+		t.Errorf("cache clear = %d: %s", code, out)
+	}
+}
+
+func TestCacheWarmDetect(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir, "zsh", "vim")
+	loader := config.NewLoader(configPath)
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Detect = true
+	if err := loader.Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", "nvim")
+
+	env, stdout, stderr := testEnv("")
+	if code, _ := runSubcommand(env, []string{"cache", "warm", "--config", configPath}); code != 0 {
+		t.Fatalf("cache warm = %d: %s %s", code, stdout, stderr)
+	}
+
+	// the TUI looks the table up with vim, found in the workspace, first
+	registry := apps.NewRegistryWithStorage(setup.FileStorage(cfg))
+	registry.LoadApps(cfg.Apps)
+	if _, err := newCache(cfg).Get(tableCacheKey(registry, []string{"vim", "zsh"})); err != nil {
+		t.Errorf("the table should be cached in the detected order: %v", err)
+	}
+}
+
+func TestSearchCommand(t *testing.T) {
+	configPath := writeTestConfig(t, t.TempDir(), "vim", "st")
+
+	run := func(args ...string) (int, string) {
+		env, stdout, _ := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"search", "--config", configPath}, args...))
+		return code, stdout.String()
+	}
+
+	code, out := run("move")
+	if code != 0 || !strings.Contains(out, "vim") || !strings.Contains(out, "st ") || strings.Contains(out, "lf ") {
+		t.Errorf("search should only cover the enabled apps = %d:\n%s", code, out)
+	}
+	if _, out := run("--all", "app:lf", "left"); !strings.Contains(out, "lf ") {
+		t.Errorf("--all should search every app:\n%s", out)
+	}
+
+	code, out = run("--json", "--limit", "1", "app:vim", "move")
+	var results []searchResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil || code != 0 {
+		t.Fatalf("search --json = %d, %v:\n%s", code, err, out)
+	}
+	if len(results) != 1 || results[0].App != "vim" || results[0].Keys != "h" {
+		t.Errorf("results = %+v", results)
+	}
+
+	if code, out := run("no-such-shortcut"); code != 1 || out != "" {
+		t.Errorf("no matches should exit 1 quietly, got %d:\n%s", code, out)
+	}
+}
+
+func TestSearchProfile(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.Apps = []string{"vim", "st"}
+	cfg.Profile = "editor"
+	cfg.Profiles = map[string]config.Profile{
+		"editor": {Apps: []string{"vim"}},
+		"files":  {Apps: []string{"lf"}},
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, data, 0644)
+
+	run := func(args ...string) (int, string) {
+		env, stdout, _ := testEnv("")
+		code, _ := runSubcommand(env, append([]string{"search", "--config", configPath}, args...))
+		return code, stdout.String()
+	}
+
+	if code, out := run("move"); code != 0 || !strings.Contains(out, "vim") || strings.Contains(out, "st ") {
+		t.Errorf("search should cover the apps of the configured profile = %d:\n%s", code, out)
+	}
+	if code, out := run("--profile", "files", "left"); code != 0 || !strings.Contains(out, "lf ") || strings.Contains(out, "vim") {
+		t.Errorf("search --profile files should cover the apps of that profile = %d:\n%s", code, out)
+	}
+}
+
+func TestHelpAndManCommands(t *testing.T) {
+	env, stdout, _ := testEnv("")
+	if code, _ := runSubcommand(env, []string{"help"}); code != 0 || !strings.Contains(stdout.String(), "search QUERY") {
+		t.Errorf("help should list the commands:\n%s", stdout.String())
+	}
+
+	env, stdout, _ = testEnv("")
+	if code, _ := runSubcommand(env, []string{"help", "import"}); code != 0 ||
+		!strings.HasPrefix(stdout.String(), "Usage: cheat-go import [flags] FILE") || !strings.Contains(stdout.String(), "-dry-run") {
+		t.Errorf("help import:\n%s", stdout.String())
+	}
+	env, stdout, _ = testEnv("")
+	if code, _ := runSubcommand(env, []string{"help", "notes"}); code != 0 || stdout.String() != notesUsage {
+		t.Errorf("help notes should print the notes usage:\n%s", stdout.String())
+	}
+	env, _, _ = testEnv("")
+	if code, _ := runSubcommand(env, []string{"help", "bogus"}); code != 2 {
+		t.Errorf("help of an unknown command should exit 2, got %d", code)
+	}
+
+	path := filepath.Join(t.TempDir(), "cheat-go.1")
+	env, _, _ = testEnv("")
+	if code, _ := runSubcommand(env, []string{"man", "--output", path}); code != 0 {
+		t.Fatalf("man failed with code %d", code)
+	}
+	data, _ := os.ReadFile(path)
+	page := string(data)
+	for _, want := range []string{".TH CHEAT-GO 1", ".SS search QUERY", `\-\-profile NAME`, "Usage: cheat\\-go sync ACTION"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("man page is missing %q", want)
+		}
+	}
+}
+
+func TestDaemonCommand(t *testing.T) {
+	dataDir := t.TempDir()
+	configPath := writeTestConfig(t, dataDir, "vim")
+
+	run := func(args ...string) (int, string) {
+		env, stdout, stderr := testEnv("")
+		code, _ := runSubcommand(env, append(args, "--config", configPath))
+		return code, stdout.String() + stderr.String()
+	}
+
+	if code, out := run("daemon", "status"); code != 1 || !strings.Contains(out, "not running") {
+		t.Errorf("status without a daemon = %d:\n%s", code, out)
+	}
+
+	stopped := make(chan int)
+	go func() {
+		code, _ := run("daemon", "run")
+		stopped <- code
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for !daemon.NewClient(filepath.Join(dataDir, "daemon.sock")).Running() {
+		if time.Now().After(deadline) {
+			t.Fatal("the daemon did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if code, out := run("daemon", "status"); code != 0 || !strings.Contains(out, "Sync:       not configured") {
+		t.Errorf("daemon status = %d:\n%s", code, out)
+	}
+	os.WriteFile(filepath.Join(dataDir, "tig.yaml"), []byte("name: tig\ndescription: Git browser\nshortcuts:\n  - keys: Q\n    description: close tig\n"), 0644)
+	if _, out := run("search", "--all", "close", "tig"); strings.Contains(out, "tig") {
+		t.Errorf("the daemon should answer from the apps it loaded:\n%s", out)
+	}
+	if code, _ := run("daemon", "reload"); code != 0 {
+		t.Errorf("daemon reload failed with code %d", code)
+	}
+	if _, out := run("search", "--all", "close", "tig"); !strings.Contains(out, "tig") {
+		t.Errorf("a reload should load new apps:\n%s", out)
+	}
+	// enabling an app reloads the daemon, which searches it from then on
+	if code, out := run("apps", "enable", "tig"); code != 0 {
+		t.Fatalf("apps enable = %d:\n%s", code, out)
+	}
+	if _, out := run("search", "close", "tig"); !strings.Contains(out, "tig") {
+		t.Errorf("the daemon should search the apps enabled since it started:\n%s", out)
+	}
+
+	if code, out := run("daemon", "stop"); code != 0 {
+		t.Fatalf("daemon stop = %d:\n%s", code, out)
+	}
+	select {
+	case code := <-stopped:
+		if code != 0 {
+			t.Errorf("daemon run exited with %d", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the daemon did not stop")
+	}
+}
@@ -0,0 +1,41 @@
+// Command embed shows how another Go program can read cheat-go data through
+// the pkg/cheat API: it prints the shortcuts and notes matching a query.
+//
+//	go run ./examples/embed split
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/pkg/cheat"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: embed QUERY")
+		os.Exit(2)
+	}
+	query := strings.Join(os.Args[1:], " ")
+
+	// read-only, so the example can run next to the cheat-go TUI
+	lib, err := cheat.Open(cheat.Options{ReadOnly: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer lib.Close()
+
+	for _, result := range lib.Search(query) {
+		fmt.Printf("%-8s %-16s %s\n", result.AppName, result.Shortcut.Keys, result.Shortcut.Description)
+	}
+
+	found, err := lib.SearchNotes(cheat.NoteQuery{Query: query})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, note := range found {
+		fmt.Printf("note     %s\n", note.Title)
+	}
+}
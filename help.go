@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// helpEntry is a line of a help section: a flag, command or key and what
+// it does. Lines of text after the first are printed below it.
+type helpEntry struct {
+	name string
+	text string
+}
+
+// tuiOptions are the flags of the TUI, as parsed by parseFlags
+var tuiOptions = []helpEntry{
+	{"-h, --help", "Show this help message and exit"},
+	{"-v, --version", "Show version information and exit"},
+	{"-t, --theme THEME", "Set the display theme\nOptions: default, dark, light, minimal, high-contrast\nDefault: default"},
+	{"-s, --style STYLE", "Set the table style\nOptions: simple, rounded, bold, minimal\nDefault: simple"},
+	{"-c, --config FILE", "Use custom configuration file\nDefault: $CHEATGO_CONFIG, then\n$XDG_CONFIG_HOME/cheat-go/config.yaml"},
+	{"--profile NAME", "Apply a profile of the config, such as \"work\"\nDefault: the profile setting of the config"},
+	{"--app APPS", "Only show these comma-separated configured apps"},
+	{"--search QUERY", "Open the table searched for QUERY, in the syntax\nof / such as \"app:vim window\""},
+	{"--view NAME", "Open a saved view of the table: its apps, tags,\nsearch and sort\nDefault: the view setting of the config"},
+}
+
+var navigationKeys = []helpEntry{
+	{"Arrow Keys / hjkl", "Navigate through the table"},
+	{"/", "Search mode (↑/↓ recall recent searches)"},
+	{"Ctrl+H", "Pick from recent searches"},
+	{"f", "Filter apps"},
+	{"t", "Filter by shortcut tags"},
+	{"P", "Switch between config profiles"},
+	{"V", "Open, save and pick the startup saved view"},
+	{"n", "Open notes manager"},
+	{"N", "Open or attach the note of the selected shortcut"},
+	{"Enter", "Pass the selected shortcut to plugin hooks"},
+	{"p", "Open plugin manager"},
+	{"o", "Browse online repositories"},
+	{"s", "Show sync status"},
+	{"H", "Show change history"},
+	{"C", "Show cache statistics"},
+	{"Q", "Quiz yourself on the shown apps"},
+	{"S", "Show practice statistics"},
+	{"Ctrl+S", "Force sync"},
+	{"?", "Show help (t there takes the tour of the features)"},
+	{"q / Ctrl+C", "Quit the application"},
+}
+
+var environmentVars = []helpEntry{
+	{"CHEATGO_CONFIG", "Config file to use when --config is not given"},
+	{"CHEATGO_DATA_DIR", "Data directory, overriding data_dir of the config"},
+	{"XDG_CONFIG_HOME", "Base of the config directory (default ~/.config)"},
+	{"XDG_DATA_HOME", "Base of the default data directory"},
+}
+
+var featureHelp = []helpEntry{
+	{"Notes Manager (n)", "Create and manage personal notes"},
+	{"Plugin Manager (p)", "Load and manage plugins"},
+	{"Online Browser (o)", "Browse community cheat sheets"},
+	{"Sync Status (s)", "View and manage cloud sync"},
+	{"History (H)", "Review and undo changes to notes and apps"},
+}
+
+var themeHelp = []helpEntry{
+	{"default", "Balanced colors for general use"},
+	{"dark", "High-contrast for dark terminals"},
+	{"light", "Clean appearance for light terminals"},
+	{"minimal", "Reduced visual elements"},
+	{"high-contrast", "Bright base colors, bold and underlined highlights"},
+}
+
+var tableStyleHelp = []helpEntry{
+	{"simple", "Clean borders with lines"},
+	{"rounded", "Elegant rounded corners"},
+	{"bold", "Thick borders for visibility"},
+	{"minimal", "Spacing-based separation"},
+}
+
+var helpExamples = []helpEntry{
+	{"", "Start with default settings"},
+	{"--theme dark", "Use dark theme"},
+	{"--style rounded", "Use rounded table borders"},
+	{"-t dark -s bold", "Dark theme with bold borders"},
+	{"--config my.yaml", "Use custom config file"},
+	{"--profile work", "Use the apps and theme of the work profile"},
+	{"--view review", "Open the table as the saved view review"},
+	{"--app tmux --search pane", "Open the pane shortcuts of tmux"},
+	{"search app:vim delete", "Print matching shortcuts without the TUI"},
+}
+
+const helpDescription = `A fast, interactive terminal application for displaying keyboard shortcuts
+and command cheat sheets. Navigate through shortcuts for popular applications
+with plugin support, personal notes, online repositories, and cloud sync.`
+
+// commandEntries lists the subcommands for the help and man page
+func commandEntries() []helpEntry {
+	var entries []helpEntry
+	for _, cmd := range subcommands() {
+		entries = append(entries, helpEntry{strings.TrimSpace(cmd.name + " " + cmd.args), cmd.summary})
+	}
+	return entries
+}
+
+func printHelp() {
+	writeHelp(os.Stdout)
+}
+
+// writeHelp writes the --help text of the TUI and its commands
+func writeHelp(w io.Writer) {
+	fmt.Fprintf(w, "%s %s - Interactive terminal cheat sheet viewer with cloud features\n\n", appName, version)
+	fmt.Fprintf(w, "USAGE:\n    %s [OPTIONS]\n    %s COMMAND [ARGS]\n\n", appName, appName)
+	fmt.Fprintf(w, "DESCRIPTION:\n%s\n\n", indent(helpDescription, 4))
+
+	writeHelpSection(w, "OPTIONS", tuiOptions)
+	writeHelpSection(w, "COMMANDS", commandEntries())
+	fmt.Fprintf(w, "    Run \"%s help COMMAND\" for the actions and flags of a command.\n\n", appName)
+	writeHelpSection(w, "NAVIGATION", navigationKeys)
+	writeHelpSection(w, "ENVIRONMENT", environmentVars)
+	writeHelpSection(w, "PHASE 4 FEATURES", featureHelp)
+	writeHelpSection(w, "THEMES", themeHelp)
+	writeHelpSection(w, "TABLE STYLES", tableStyleHelp)
+
+	fmt.Fprintln(w, "EXAMPLES:")
+	for _, e := range helpExamples {
+		fmt.Fprintf(w, "    %-28s # %s\n", strings.TrimSpace(appName+" "+e.name), e.text)
+	}
+	fmt.Fprintln(w, "\nFor more information, visit: https://github.com/remuscazacu/cheat-go")
+}
+
+func writeHelpSection(w io.Writer, title string, entries []helpEntry) {
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, e := range entries {
+		lines := strings.Split(e.text, "\n")
+		fmt.Fprintf(w, "    %-24s%s\n", e.name, lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(w, "    %-24s%s\n", "", line)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func indent(text string, n int) string {
+	pad := strings.Repeat(" ", n)
+	return pad + strings.ReplaceAll(text, "\n", "\n"+pad)
+}
+
+// commandHelp returns the usage a subcommand prints for --help. Commands
+// made of a single flag set print their flag defaults, which get a usage
+// line like the commands with actions.
+func commandHelp(cmd subcommand) string {
+	var out bytes.Buffer
+	cmd.run(cmdEnv{stdin: strings.NewReader(""), stdout: &out, stderr: &out}, []string{"--help"})
+	help := out.String()
+	if rest, ok := strings.CutPrefix(help, "Usage of "+cmd.name+":\n"); ok {
+		help = fmt.Sprintf("Usage: %s %s [flags] %s\n\nFlags:\n%s", appName, cmd.name, cmd.args, rest)
+		help = strings.Replace(help, " [flags] \n", " [flags]\n", 1)
+	}
+	return help
+}
+
+func findSubcommand(name string) (subcommand, bool) {
+	for _, cmd := range subcommands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return subcommand{}, false
+}
+
+func runHelp(env cmdEnv, args []string) int {
+	if len(args) == 0 {
+		writeHelp(env.stdout)
+		return 0
+	}
+	if args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprintf(env.stdout, "Usage: %s help [COMMAND]\n", appName)
+		return 0
+	}
+
+	cmd, ok := findSubcommand(args[0])
+	if !ok {
+		fmt.Fprintf(env.stderr, "Error: unknown command %q\n", args[0])
+		return 2
+	}
+	fmt.Fprint(env.stdout, commandHelp(cmd))
+	return 0
+}
+
+func runMan(env cmdEnv, args []string) int {
+	fs := flag.NewFlagSet("man", flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	output := fs.String("output", "", "Write the man page to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var page bytes.Buffer
+	writeManPage(&page, time.Now())
+	if *output == "" {
+		env.stdout.Write(page.Bytes())
+		return 0
+	}
+	if err := os.WriteFile(*output, page.Bytes(), 0644); err != nil {
+		fmt.Fprintf(env.stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.stdout, "Man page written to %s\n", *output)
+	return 0
+}
+
+// writeManPage writes the help and the usage of every command as a roff
+// man page for section 1
+func writeManPage(w io.Writer, date time.Time) {
+	fmt.Fprintf(w, ".TH %s 1 %q %q %q\n", strings.ToUpper(appName), date.Format("2006-01-02"), appName+" "+version, "User Commands")
+	fmt.Fprintf(w, ".SH NAME\n%s \\- interactive terminal cheat sheet viewer\n", appName)
+	fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n[\\fIOPTIONS\\fR]\n.br\n.B %s\n\\fICOMMAND\\fR [\\fIARGS\\fR]\n", appName, appName)
+	fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", manEscape(helpDescription))
+
+	writeManSection(w, "OPTIONS", tuiOptions)
+
+	fmt.Fprintln(w, ".SH COMMANDS")
+	for _, cmd := range subcommands() {
+		fmt.Fprintf(w, ".SS %s\n%s\n", manEscape(strings.TrimSpace(cmd.name+" "+cmd.args)), manEscape(cmd.summary))
+		if help := strings.TrimRight(commandHelp(cmd), "\n"); help != "" {
+			fmt.Fprintf(w, ".PP\n.nf\n.RS 4\n%s\n.RE\n.fi\n", manEscape(help))
+		}
+	}
+
+	writeManSection(w, "KEYS", navigationKeys)
+	writeManSection(w, "ENVIRONMENT", environmentVars)
+	writeManSection(w, "THEMES", themeHelp)
+	writeManSection(w, "TABLE STYLES", tableStyleHelp)
+
+	fmt.Fprintln(w, ".SH EXAMPLES")
+	for _, e := range helpExamples {
+		fmt.Fprintf(w, ".TP\n.B %s\n%s\n", manEscape(strings.TrimSpace(appName+" "+e.name)), manEscape(e.text))
+	}
+	fmt.Fprintf(w, ".SH SEE ALSO\nhttps://github.com/remuscazacu/cheat\\-go\n")
+}
+
+func writeManSection(w io.Writer, title string, entries []helpEntry) {
+	fmt.Fprintf(w, ".SH %s\n", title)
+	for _, e := range entries {
+		fmt.Fprintf(w, ".TP\n.B %s\n%s\n", manEscape(e.name), strings.ReplaceAll(manEscape(e.text), "\n", "\n.br\n"))
+	}
+}
+
+// manEscape escapes text for roff: backslashes, hyphens that would become
+// dashes and lines that would be read as requests
+func manEscape(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\e`)
+	text = strings.ReplaceAll(text, "-", `\-`)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
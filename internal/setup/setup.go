@@ -0,0 +1,52 @@
+// Package setup opens the user data of a configuration. The cheat-go
+// binary and the embedding API of pkg/cheat both open it here, so they read
+// the same storage and layer apps the same way.
+package setup
+
+import (
+	"fmt"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/importer"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+	"github.com/remuscazacu/cheat-go/pkg/workspace"
+)
+
+// OpenStorage opens the user data backend selected by the configuration
+func OpenStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.Storage.Backend {
+	case "", "file":
+		return FileStorage(cfg), nil
+	case "sqlite":
+		return storage.NewSQLiteStorage(cfg.DatabasePath())
+	}
+	return nil, fmt.Errorf("%w: %s", storage.ErrUnknownBackend, cfg.Storage.Backend)
+}
+
+// FileStorage keeps apps and notes in their historical file locations
+func FileStorage(cfg *config.Config) *storage.FileStorage {
+	return storage.NewFileStorage(cfg.BaseDir()).
+		Mount(storage.CollectionApps, cfg.AppsDir(), ".yaml").
+		Mount(storage.CollectionNotes, cfg.NotesDir(), ".json").
+		Mount(storage.CollectionNoteFiles, cfg.NoteFilesDir(), ".json")
+}
+
+// LayeredApps reads the apps of store layered over the shared apps
+// directories and beneath the apps of the project cheat-go runs in, for
+// the registry. Other data comes from store alone.
+func LayeredApps(cfg *config.Config, store storage.Storage) storage.Storage {
+	var project []string
+	if dir, ok := workspace.ProjectAppsDir(workspace.Current()); ok {
+		project = append(project, dir)
+	}
+	return storage.NewLayered(store, storage.CollectionApps, ".yaml", cfg.SharedAppsDirs(), project)
+}
+
+// NewRegistry creates the registry of the layered apps of store and of the
+// cheat sheets in the configured cheat directories. The registry is usable
+// even when some cheat sheets could not be read, which the error reports.
+func NewRegistry(cfg *config.Config, store storage.Storage) (*apps.Registry, error) {
+	registry := apps.NewRegistryWithStorage(LayeredApps(cfg, store))
+	return registry, importer.RegisterCheatDirs(registry, cfg.CheatDirs())
+}
@@ -1,20 +1,32 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 
-	"cheat-go/pkg/apps"
-	"cheat-go/pkg/cache"
-	"cheat-go/pkg/config"
-	"cheat-go/pkg/notes"
-	"cheat-go/pkg/online"
-	"cheat-go/pkg/plugins"
-	"cheat-go/pkg/ui"
+	"github.com/remuscazacu/cheat-go/internal/setup"
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/cache"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+	"github.com/remuscazacu/cheat-go/pkg/plugins"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+	"github.com/remuscazacu/cheat-go/pkg/sync"
+	"github.com/remuscazacu/cheat-go/pkg/ui"
+	"github.com/remuscazacu/cheat-go/pkg/workspace"
 )
 
 const (
@@ -28,70 +40,10 @@ type cliOptions struct {
 	theme       string
 	tableStyle  string
 	configFile  string
-}
-
-func printHelp() {
-	fmt.Printf(`%s %s - Interactive terminal cheat sheet viewer with cloud features
-
-USAGE:
-    %s [OPTIONS]
-
-DESCRIPTION:
-    A fast, interactive terminal application for displaying keyboard shortcuts 
-    and command cheat sheets. Navigate through shortcuts for popular applications
-    with plugin support, personal notes, online repositories, and cloud sync.
-
-OPTIONS:
-    -h, --help              Show this help message and exit
-    -v, --version           Show version information and exit
-    -t, --theme THEME       Set the display theme
-                            Options: default, dark, light, minimal
-                            Default: default
-    -s, --style STYLE       Set the table style  
-                            Options: simple, rounded, bold, minimal
-                            Default: simple
-    -c, --config FILE       Use custom configuration file
-                            Default: ~/.config/cheat-go/config.yaml
-
-NAVIGATION:
-    Arrow Keys / hjkl       Navigate through the table
-    /                       Search mode
-    f                       Filter apps
-    n                       Open notes manager
-    p                       Open plugin manager
-    o                       Browse online repositories
-    s                       Show sync status
-    Ctrl+S                  Force sync
-    ?                       Show help
-    q / Ctrl+C              Quit the application
-
-PHASE 4 FEATURES:
-    Notes Manager (n)       Create and manage personal notes
-    Plugin Manager (p)      Load and manage plugins
-    Online Browser (o)      Browse community cheat sheets
-    Sync Status (s)         View and manage cloud sync
-    
-THEMES:
-    default                 Balanced colors for general use
-    dark                    High-contrast for dark terminals
-    light                   Clean appearance for light terminals  
-    minimal                 Reduced visual elements
-
-TABLE STYLES:
-    simple                  Clean borders with lines
-    rounded                 Elegant rounded corners
-    bold                    Thick borders for visibility
-    minimal                 Spacing-based separation
-
-EXAMPLES:
-    %s                      # Start with default settings
-    %s --theme dark         # Use dark theme
-    %s --style rounded      # Use rounded table borders
-    %s -t dark -s bold      # Dark theme with bold borders
-    %s --config my.yaml     # Use custom config file
-
-For more information, visit: https://github.com/remuscazacu/cheat-go
-`, appName, version, appName, appName, appName, appName, appName, appName)
+	profile     string
+	view        string
+	apps        string
+	search      string
 }
 
 func printVersion() {
@@ -111,12 +63,16 @@ func parseFlags() cliOptions {
 	flag.StringVar(&opts.tableStyle, "style", "", "Table style")
 	flag.StringVar(&opts.configFile, "c", "", "Configuration file path")
 	flag.StringVar(&opts.configFile, "config", "", "Configuration file path")
+	flag.StringVar(&opts.profile, "profile", "", "Configuration profile")
+	flag.StringVar(&opts.view, "view", "", "Saved view to open")
+	flag.StringVar(&opts.apps, "app", "", "Comma-separated apps to show")
+	flag.StringVar(&opts.search, "search", "", "Search to open the table with")
 
 	flag.Parse()
 
 	// Validate theme option
 	if opts.theme != "" {
-		validThemes := []string{"default", "dark", "light", "minimal"}
+		validThemes := config.ValidThemes
 		valid := false
 		for _, t := range validThemes {
 			if opts.theme == t {
@@ -160,33 +116,34 @@ func initialModel(opts cliOptions) ui.Model {
 		cfg = config.DefaultConfig()
 	}
 
-	// Override config with CLI options
-	if opts.theme != "" {
-		cfg.Theme = opts.theme
-	}
-	if opts.tableStyle != "" {
-		cfg.Layout.TableStyle = opts.tableStyle
-	}
+	// Apply the selected profile, then the project configuration of the
+	// working directory
+	cfg = applyProfile(os.Stdout, cfg, opts.profile)
+	cfg = applyProject(os.Stdout, cfg)
 
-	// Initialize app registry
-	registry := apps.NewRegistry(cfg.DataDir)
-	if err := registry.LoadApps(cfg.Apps); err != nil {
-		fmt.Printf("Warning: Could not load some apps (%v), using defaults\n", err)
+	// Open user data storage
+	store, err := setup.OpenStorage(cfg)
+	if err != nil {
+		fmt.Printf("Warning: Could not open %s storage (%v), using files\n", cfg.Storage.Backend, err)
+		store = setup.FileStorage(cfg)
 	}
 
+	// Override config with CLI options for this run and initialize the app
+	// registry
+	cfg, registry := setupTable(os.Stdout, cfg, config.Overrides{Theme: opts.theme, TableStyle: opts.tableStyle}, store)
+
 	// Create theme and renderer
-	theme := ui.GetTheme(cfg.Theme)
-	renderer := ui.NewTableRenderer(theme)
-	renderer.SetTableStyle(cfg.Layout.TableStyle)
-	renderer.SetMaxWidth(cfg.Layout.MaxWidth)
+	renderer := ui.NewConfiguredRenderer(cfg)
 
 	// Generate table data
-	rows := registry.GetTableData(cfg.Apps)
+	responses := newCache(cfg)
+	rows := tableData(responses, registry, cfg.Apps)
 
 	// Initialize Phase 4 components
 	m := ui.Model{
 		Registry:     registry,
 		Config:       cfg,
+		ConfigLoader: loader,
 		Renderer:     renderer,
 		Rows:         rows,
 		FilteredRows: rows,
@@ -204,36 +161,257 @@ func initialModel(opts cliOptions) ui.Model {
 	}
 
 	// Initialize cache
-	m.Cache = cache.NewLRUCache(10*1024*1024, 1000) // 10MB, 1000 items
+	m.Cache = responses
 
-	// Initialize notes manager
-	notesDir := os.ExpandEnv("$HOME/.config/cheat-go/notes")
-	if cfg.DataDir != "" {
-		notesDir = cfg.DataDir + "/notes"
+	// Restore recent searches
+	m.Store = store
+	m.LoadSearchHistory()
+
+	// Initialize change journal
+	m.Journal = openJournal(cfg)
+	if m.Journal != nil {
+		registry.SetJournal(m.Journal, journal.SourceUI)
+	}
+
+	// Initialize plugin loader; the plugins, which may add apps of their
+	// own, load in the background with the notes
+	m.PluginLoader = newPluginLoader(cfg)
+
+	// Initialize online client
+	m.OnlineClient = newOnlineClient(cfg, m.Cache)
+
+	// Open the saved view
+	view := cfg.View
+	if opts.view != "" {
+		view = opts.view
+	}
+	if view != "" {
+		if err := m.ApplyView(view); err != nil {
+			fmt.Printf("Warning: %v, showing the whole table\n", err)
+		}
+	}
+
+	// Narrow the table as --app and --search ask, over the view
+	if opts.apps != "" || opts.search != "" {
+		appNames, query := m.FilteredApps, m.LastSearch
+		if opts.apps != "" {
+			appNames = splitList(opts.apps)
+		}
+		if opts.search != "" {
+			query = opts.search
+		}
+		if err := m.FilterTable(appNames, query); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+
+	// Initialize sync manager (disabled by default)
+	if cfg.Sync.Enabled {
+		if manager, err := newSyncManager(cfg); err == nil {
+			manager.SetJournal(m.Journal)
+			if cfg.Sync.AutoSync {
+				manager.StartAutoSync()
+			}
+			m.SyncManager = manager
+			m.SyncStatus = manager.GetSyncStatus()
+		}
 	}
-	m.NotesManager, _ = notes.NewFileManager(notesDir)
 
-	// Initialize plugin loader
+	return m
+}
+
+// newPluginLoader creates a plugin loader searching the user directory
+// before the system wide ones, skipping disabled plugins and verifying
+// signatures with the trusted keys
+func newPluginLoader(cfg *config.Config) *plugins.Loader {
 	pluginDirs := []string{
-		os.ExpandEnv("$HOME/.config/cheat-go/plugins"),
+		filepath.Join(config.ConfigDir(), "plugins"),
 		"/usr/local/share/cheat-go/plugins",
 	}
 	if cfg.DataDir != "" {
-		pluginDirs = append([]string{cfg.DataDir + "/plugins"}, pluginDirs...)
+		pluginDirs = append([]string{cfg.PluginsDir()}, pluginDirs...)
 	}
-	m.PluginLoader = plugins.NewLoader(pluginDirs...)
-	m.PluginLoader.LoadAll()
+	loader := plugins.NewLoader(pluginDirs...)
+	loader.SetDisabled(cfg.Plugins.Disabled)
+	// the keys were checked when the config was validated
+	verifier, _ := signature.NewVerifier(cfg.Signatures.TrustedKeys)
+	loader.SetVerifier(verifier, cfg.Plugins.Approved)
+	return loader
+}
 
-	// Initialize online client (mock for now)
-	m.OnlineClient = online.NewMockClient()
+// transportOptions applies the configured network overrides to the defaults
+func transportOptions(cfg *config.Config) online.TransportOptions {
+	opts := online.DefaultTransportOptions()
+	network := cfg.Network
+	if network.Retries != nil {
+		opts.MaxRetries = *network.Retries
+	}
+	if network.RetryDelay > 0 {
+		opts.RetryDelay = network.RetryDelay
+	}
+	if network.MaxRetryDelay > 0 {
+		opts.MaxRetryDelay = network.MaxRetryDelay
+	}
+	if network.RateLimit > 0 {
+		opts.RateLimit = network.RateLimit
+	}
+	if network.Burst > 0 {
+		opts.Burst = network.Burst
+	}
+	if network.Timeout > 0 {
+		opts.Timeout = network.Timeout
+	}
+	return opts
+}
 
-	// Initialize sync manager (disabled by default)
-	// m.syncManager would be initialized if sync is enabled in config
+// newOAuth creates the OAuth2 helper for the configured online service
+func newOAuth(cfg *config.Config) *online.OAuth {
+	auth := cfg.Online.Auth
+	return online.NewOAuth(online.OAuthConfig{
+		ClientID:      auth.ClientID,
+		DeviceAuthURL: auth.DeviceAuthURL,
+		TokenURL:      auth.TokenURL,
+		Scopes:        auth.Scopes,
+	}, online.NewTokenStore(cfg.TokenPath()))
+}
 
-	return m
+// openJournal opens the change journal, returning nil when it is unavailable
+func openJournal(cfg *config.Config) *journal.Journal {
+	j, err := journal.Open(cfg.JournalPath())
+	if err != nil {
+		return nil
+	}
+	return j
+}
+
+// newSyncManager creates a sync manager for the notes in the data directory
+// using the configured cloud endpoint
+func newSyncManager(cfg *config.Config) (*sync.Manager, error) {
+	if cfg.Sync.Endpoint == "" {
+		return nil, sync.ErrNoSyncService
+	}
+	service := sync.NewCloudSyncService(cfg.Sync.Endpoint, cfg.Sync.APIKey)
+	service.SetTransportOptions(transportOptions(cfg))
+	service.SetIdentity(cfg.Sync.User, cfg.Sync.Team, cfg.Sync.TeamAPIKey)
+
+	manager, err := sync.NewManager(service, cfg.NotesDir())
+	if err != nil {
+		return nil, err
+	}
+	manager.SetInterval(cfg.Sync.Interval)
+	if cfg.Backup.Auto {
+		manager.SetBeforeMerge(func() error {
+			_, err := newBackupScheduler(cfg).Backup()
+			return err
+		})
+	}
+	return manager, nil
+}
+
+// newCache creates the memory and disk cache shared by the online client,
+// falling back to memory only when the cache directory is unusable
+func newCache(cfg *config.Config) cache.Cache {
+	c, err := cache.NewMultiLevelCache(10*1024*1024, 1000, cfg.CacheDir(), defaultCacheTTL) // 10MB, 1000 items
+	if err != nil {
+		return cache.NewLRUCache(10*1024*1024, 1000)
+	}
+	return c
+}
+
+// tableCacheKey identifies the table rows of the given apps; it changes
+// whenever one of their definitions does
+func tableCacheKey(registry *apps.Registry, names []string) string {
+	hash := sha256.New()
+	hash.Write([]byte(strings.Join(registry.Columns(), ",")))
+	if registry.ShowCategories() {
+		hash.Write([]byte("\x00sections"))
+	}
+	for _, name := range names {
+		app, _ := registry.Get(name)
+		data, _ := json.Marshal(app)
+		hash.Write([]byte(name))
+		hash.Write(data)
+	}
+	return cache.Key(cache.NamespaceRegistry, "table:"+hex.EncodeToString(hash.Sum(nil)))
+}
+
+// setupTable applies overrides to cfg for this run, putting the apps of the
+// current workspace first when detection is on, and creates the registry of
+// the table from store with the apps of cfg loaded. The TUI and "cheat-go
+// cache warm" both set the table up with it, so the rows one caches are
+// those the other looks up.
+func setupTable(warn io.Writer, cfg *config.Config, overrides config.Overrides, store storage.Storage) (*config.Config, *apps.Registry) {
+	if cfg.Detect {
+		overrides.Priority = workspace.Detect(workspace.Current())
+	}
+	cfg = cfg.WithOverrides(overrides)
+
+	registry, err := setup.NewRegistry(cfg, store)
+	if err != nil {
+		fmt.Fprintf(warn, "Warning: Could not load some cheat sheets (%v)\n", err)
+	}
+	registry.SetColumns(cfg.Layout.Columns)
+	registry.SetShowCategories(cfg.Layout.ShowCategories)
+	registry.SetAliases(cfg.Aliases)
+	registry.SetMergedApps(cfg.Merged)
+	if err := registry.LoadApps(cfg.Apps); err != nil {
+		fmt.Fprintf(warn, "Warning: Could not load some apps (%v), using defaults\n", err)
+	}
+	return cfg, registry
+}
+
+// tableData returns the table rows of the given apps, reusing rows cached by
+// an earlier start or "cheat-go cache warm"
+func tableData(c cache.Cache, registry *apps.Registry, names []string) [][]string {
+	key := tableCacheKey(registry, names)
+	if value, err := c.Get(key); err == nil {
+		// rows read back from the file cache are generic JSON
+		var rows [][]string
+		if data, err := json.Marshal(value); err == nil && json.Unmarshal(data, &rows) == nil && len(rows) > 0 {
+			return rows
+		}
+	}
+
+	rows := registry.GetTableData(names)
+	c.Set(key, rows, defaultCacheTTL)
+	return rows
+}
+
+// newOnlineClient creates the online client selected by the configuration
+func newOnlineClient(cfg *config.Config, responses cache.Cache) online.Client {
+	return online.NewBlockingClient(newProviderClient(cfg, responses), online.Blocklist(cfg.Online.Blocklist))
+}
+
+// newProviderClient builds the client of the provider of the config
+func newProviderClient(cfg *config.Config, responses cache.Cache) online.Client {
+	switch cfg.Online.Provider {
+	case "mock":
+		return online.NewMockClient()
+	case "http":
+		opts := transportOptions(cfg)
+		opts.Mirrors = cfg.Online.Mirrors
+		client := online.NewHTTPClient(cfg.Online.APIURL)
+		client.SetTransportOptions(opts)
+		client.SetCache(responses)
+		client.SetAPIKey(cfg.Online.APIKey)
+		if cfg.Online.Auth.Enabled() {
+			client.SetOAuth(newOAuth(cfg))
+		}
+		return client
+	default:
+		opts := transportOptions(cfg)
+		opts.Mirrors = online.GitHubMirrors(cfg.Online.APIURL, cfg.Online.Mirrors)
+		client := online.NewGitHubClient(cfg.Online.Repositories, cfg.Online.APIURL)
+		client.SetTransportOptions(opts)
+		return client
+	}
 }
 
 func main() {
+	if code, ok := runSubcommand(defaultEnv(), os.Args[1:]); ok {
+		os.Exit(code)
+	}
+
 	opts := parseFlags()
 
 	if opts.showHelp {
@@ -246,9 +424,54 @@ func main() {
 		os.Exit(0)
 	}
 
-	p := tea.NewProgram(initialModel(opts))
+	m := initialModel(opts)
+	instance := claimInstance(&m)
+	defer instance.Release()
+
+	// Open the notes and load the plugins once the program runs, before
+	// the setup wizard so that leaving it with Esc does not cancel them
+	m.LoadInBackground()
+	if !m.ConfigLoader.Found() && !configFileExists(m.ConfigLoader.Path()) {
+		m = m.StartSetup()
+	}
+
+	// Back up notes and apps in the background; read-only instances leave
+	// it to the owner of the data directory
+	if instance != nil && m.Config.Backup.Auto {
+		backups := newBackupScheduler(m.Config)
+		backups.Start()
+		defer backups.Stop()
+	}
+
+	p := tea.NewProgram(m)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
+		instance.Release()
 		os.Exit(1)
 	}
 }
+
+// configFileExists reports whether a config file is at path; a file that
+// failed to load is left for the user to fix rather than replaced by the
+// setup wizard
+func configFileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// claimInstance marks this process as the owner of the data directory. When
+// another instance already owns it, notes are opened read-only so the two
+// processes cannot overwrite each other's changes.
+func claimInstance(m *ui.Model) *lock.Instance {
+	instance, err := lock.AcquireInstance(m.Config.BaseDir())
+	if err == nil {
+		return instance
+	}
+
+	var locked *lock.InstanceLockedError
+	if errors.As(err, &locked) {
+		m.ReadOnly = true
+		m.StatusMessage = fmt.Sprintf("Read-only: %v", locked)
+	}
+	return nil
+}
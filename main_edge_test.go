@@ -7,8 +7,8 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
-	"cheat-go/pkg/config"
-	"cheat-go/pkg/ui"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/ui"
 )
 
 func TestInitialModel_ConfigLoadErrors(t *testing.T) {
@@ -1,17 +1,35 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"gopkg.in/yaml.v3"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-
-	"cheat-go/pkg/config"
-	"cheat-go/pkg/notes"
-	"cheat-go/pkg/ui"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+	"github.com/remuscazacu/cheat-go/pkg/plugins"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+	"github.com/remuscazacu/cheat-go/pkg/sync"
+	"github.com/remuscazacu/cheat-go/pkg/ui"
 )
 
 func initialModelWithDefaults() ui.Model {
@@ -20,7 +38,12 @@ func initialModelWithDefaults() ui.Model {
 		tableStyle: "",
 		configFile: "",
 	}
-	return initialModel(opts)
+	m := initialModel(opts)
+	// Keep tests off the network
+	m.OnlineClient = online.NewMockClient()
+	// Open the notes and load the plugins as the program would
+	m.LoadInBackground()
+	return settle(m, m.Init())
 }
 
 func containsIgnoreCase(s, substr string) bool {
@@ -304,7 +327,7 @@ func TestModel_View(t *testing.T) {
 	}
 
 	// Should contain instructions
-	if !strings.Contains(view, "hjkl") {
+	if !strings.Contains(view, "↑/↓/←/→: move") {
 		t.Error("View should contain usage instructions")
 	}
 }
@@ -454,8 +477,8 @@ func TestSearchInput(t *testing.T) {
 	// Test adding characters to search query
 	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}}
 	newModel, cmd := m.Update(msg)
-	if cmd != nil {
-		t.Error("adding to search query should not return command")
+	if cmd == nil {
+		t.Error("adding to search query should schedule filtering the table")
 	}
 
 	updatedModel := newModel.(ui.Model)
@@ -466,8 +489,8 @@ func TestSearchInput(t *testing.T) {
 	// Test backspace
 	msg = tea.KeyMsg{Type: tea.KeyBackspace}
 	newModel, cmd = updatedModel.Update(msg)
-	if cmd != nil {
-		t.Error("backspace should not return command")
+	if cmd == nil {
+		t.Error("backspace should schedule filtering the table")
 	}
 
 	updatedModel = newModel.(ui.Model)
@@ -948,10 +971,15 @@ func TestNotesViewInput(t *testing.T) {
 	updatedModel := newModel.(ui.Model)
 	// Navigation should work even with empty notes
 
-	// Test creating note
+	// Test creating note from the first template
 	msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}}
 	newModel, _ = m.Update(msg)
 	updatedModel = newModel.(ui.Model)
+	if !updatedModel.TemplateMode || !strings.Contains(updatedModel.View(), "New Note from Template") {
+		t.Error("n should offer template selection")
+	}
+	newModel, _ = updatedModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updatedModel = newModel.(ui.Model)
 	if updatedModel.StatusMessage == "" {
 		t.Error("Should show status message when creating note")
 	}
@@ -965,6 +993,222 @@ func TestNotesViewInput(t *testing.T) {
 	}
 }
 
+func TestNotesEncryptionInput(t *testing.T) {
+	m := initialModelWithDefaults()
+	fm, err := notes.NewFileManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.NotesManager = fm
+	note := &notes.Note{Title: "Server", Content: "password is swordfish"}
+	fm.CreateNote(note)
+	m.LoadNotes()
+	m.ViewMode = ui.ViewNotes
+
+	press := func(m ui.Model, msg tea.KeyMsg) ui.Model {
+		newModel, _ := m.Update(msg)
+		return newModel.(ui.Model)
+	}
+	enterPassphrase := func(m ui.Model, passphrase string) ui.Model {
+		m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(passphrase)})
+		return press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	// x asks for the new passphrase twice
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if !m.PassphraseMode {
+		t.Fatal("x should prompt for a passphrase")
+	}
+	m = enterPassphrase(m, "hunter2")
+	if strings.Contains(m.View(), "hunter2") {
+		t.Error("the passphrase should be masked")
+	}
+	m = enterPassphrase(m, "hunter2")
+	stored, _ := fm.GetNote(note.ID)
+	if !stored.Encrypted || strings.Contains(stored.Content, "swordfish") {
+		t.Fatalf("note should be encrypted, status %q", m.StatusMessage)
+	}
+	if !strings.Contains(m.View(), "🔒") {
+		t.Error("encrypted notes should be marked in the list")
+	}
+
+	// a wrong passphrase keeps the note closed
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = enterPassphrase(m, "wrong")
+	if m.ViewMode != ui.ViewNotes || !strings.Contains(m.StatusMessage, "wrong passphrase") {
+		t.Errorf("wrong passphrase should not open the note, view %v status %q", m.ViewMode, m.StatusMessage)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = enterPassphrase(m, "hunter2")
+	if m.ViewMode != ui.ViewNotePreview || !strings.Contains(m.View(), "swordfish") {
+		t.Errorf("the right passphrase should preview the note, status %q", m.StatusMessage)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.DecryptedNote != nil {
+		t.Error("leaving the preview should forget the decrypted note")
+	}
+}
+
+func TestNotesTrashInput(t *testing.T) {
+	m := initialModelWithDefaults()
+	fm, err := notes.NewFileManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.NotesManager = fm
+	note := &notes.Note{Title: "Scratch"}
+	fm.CreateNote(note)
+	m.LoadNotes()
+	m.ViewMode = ui.ViewNotes
+
+	press := func(m ui.Model, key rune) ui.Model {
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		return newModel.(ui.Model)
+	}
+
+	m = press(m, 'd')
+	if len(m.NotesList) != 0 {
+		t.Fatalf("d should remove the note from the list, status %q", m.StatusMessage)
+	}
+
+	m = press(m, 't')
+	if !m.TrashMode || !strings.Contains(m.View(), "Scratch") {
+		t.Fatal("t should show the trash with the deleted note")
+	}
+	m = press(m, 'r')
+	if len(m.TrashList) != 0 || len(m.NotesList) != 1 {
+		t.Errorf("r should restore the note, status %q", m.StatusMessage)
+	}
+
+	m = press(m, 't')
+	m = press(m, 'd')
+	m = press(m, 't')
+	m = press(m, 'E')
+	if trash, _ := fm.ListTrash(); len(trash) != 0 {
+		t.Errorf("E should empty the trash, status %q", m.StatusMessage)
+	}
+}
+
+func TestTagFilterInput(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Registry.Register(&apps.App{
+		Name:        "tmux",
+		Description: "Terminal multiplexer",
+		Shortcuts: []apps.Shortcut{
+			{Keys: "C-b c", Description: "new window", Tags: []string{"window"}},
+			{Keys: "C-b d", Description: "detach", Tags: []string{"session"}},
+			{Keys: "C-b %", Description: "split pane", Tags: []string{"pane", "window"}},
+		},
+	})
+	m.Config.Apps = []string{"vim", "tmux"}
+	m.AllApps = m.Config.Apps
+	m.RefreshTable()
+
+	press := func(m ui.Model, key string) ui.Model {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace}
+		}
+		newModel, _ := m.Update(msg)
+		return newModel.(ui.Model)
+	}
+
+	m = press(m, "t")
+	if !m.TagMode || len(m.AllTags) != 3 || !strings.Contains(m.View(), "session") {
+		t.Fatalf("t should list the tags of the displayed apps, got %v", m.AllTags)
+	}
+
+	// tags are sorted: pane, session, window
+	m = press(m, "j")
+	m = press(m, "j")
+	m = press(m, " ")
+	m = press(m, "enter")
+	if m.TagMode || len(m.Rows) != 3 {
+		t.Fatalf("filtering by window should show two shortcuts, got %d rows", len(m.Rows)-1)
+	}
+
+	// the tag filter combines with search
+	m = press(m, "/")
+	for _, r := range "split" {
+		m = press(m, string(r))
+	}
+	m = press(m, "enter")
+	if len(m.Rows) != 2 || m.Rows[1][0] != "C-b %" {
+		t.Errorf("search within the tag filter = %v", m.Rows)
+	}
+
+	m = press(m, "t")
+	m = press(m, "c")
+	m = press(m, "enter")
+	if len(m.SelectedTags) != 0 || len(m.Rows) != 2 {
+		t.Errorf("clearing the tags should keep the search, got %d rows", len(m.Rows)-1)
+	}
+}
+
+func TestSearchHistoryInput(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Store = storage.NewFileStorage(t.TempDir())
+	m.LoadSearchHistory()
+
+	send := func(m ui.Model, msg tea.KeyMsg) ui.Model {
+		newModel, _ := m.Update(msg)
+		return newModel.(ui.Model)
+	}
+	search := func(m ui.Model, query string) ui.Model {
+		m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+		m = send(m, tea.KeyMsg{Type: tea.KeyCtrlU})
+		for _, r := range query {
+			m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+		return send(m, tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	m = search(m, "move")
+	m = search(m, "quit")
+	m = search(m, "move")
+	if len(m.SearchHistory) != 2 || m.SearchHistory[0] != "move" {
+		t.Fatalf("SearchHistory = %v, want [move quit]", m.SearchHistory)
+	}
+
+	// up and down cycle through recent searches and back to the draft
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = send(m, tea.KeyMsg{Type: tea.KeyCtrlU})
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = send(m, tea.KeyMsg{Type: tea.KeyUp})
+	m = send(m, tea.KeyMsg{Type: tea.KeyUp})
+	if m.SearchQuery != "quit" {
+		t.Errorf("two ups should recall quit, got %q", m.SearchQuery)
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyDown})
+	m = send(m, tea.KeyMsg{Type: tea.KeyDown})
+	if m.SearchQuery != "to" {
+		t.Errorf("down past the newest search should restore the draft, got %q", m.SearchQuery)
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+	// history survives a restart
+	m.SearchHistory = nil
+	m.LoadSearchHistory()
+	if len(m.SearchHistory) != 2 {
+		t.Fatalf("reloaded SearchHistory = %v", m.SearchHistory)
+	}
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyCtrlH})
+	if !m.SearchHistoryMode || !strings.Contains(m.View(), "Recent Searches") {
+		t.Fatal("ctrl+h should open the search history")
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyDown})
+	m = send(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.SearchHistoryMode || m.LastSearch != "quit" || m.SearchHistory[0] != "quit" {
+		t.Errorf("enter should run the picked search, LastSearch %q, history %v", m.LastSearch, m.SearchHistory)
+	}
+}
+
 func TestPluginsViewInput(t *testing.T) {
 	m := initialModelWithDefaults()
 	m.ViewMode = ui.ViewPlugins
@@ -976,8 +1220,7 @@ func TestPluginsViewInput(t *testing.T) {
 
 	// Test reload
 	msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}}
-	newModel, _ = m.Update(msg)
-	updatedModel := newModel.(ui.Model)
+	updatedModel := settle(m.Update(msg))
 	if updatedModel.StatusMessage == "" {
 		t.Error("Should show status message when reloading plugins")
 	}
@@ -1092,8 +1335,8 @@ func TestSearchClearShortcut(t *testing.T) {
 	// Test Ctrl+U to clear search
 	msg := tea.KeyMsg{Type: tea.KeyCtrlU}
 	newModel, cmd := m.Update(msg)
-	if cmd != nil {
-		t.Error("ctrl+u should not return command")
+	if cmd == nil {
+		t.Error("ctrl+u should schedule filtering the table")
 	}
 
 	updatedModel := newModel.(ui.Model)
@@ -1102,6 +1345,48 @@ func TestSearchClearShortcut(t *testing.T) {
 	}
 }
 
+func TestSearchAsYouType(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.LastSearch = "quit"
+	m.Rows = m.Registry.SearchTableData(m.Config.Apps, "quit")
+	before := len(m.Rows)
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = newModel.(ui.Model)
+	var ticks []tea.Cmd
+	for _, r := range "move" {
+		newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = newModel.(ui.Model)
+		ticks = append(ticks, cmd)
+	}
+	if m.LastSearch != "quit" {
+		t.Fatalf("typing should wait for a pause before filtering, got %q", m.LastSearch)
+	}
+
+	// only the tick of the last key filters the table
+	newModel, _ = m.Update(ticks[1]())
+	m = newModel.(ui.Model)
+	if m.LastSearch != "quit" {
+		t.Errorf("an outdated tick should be ignored, got %q", m.LastSearch)
+	}
+	newModel, _ = m.Update(ticks[3]())
+	m = newModel.(ui.Model)
+	if !m.SearchMode || m.LastSearch != "move" || len(m.Rows) < 2 {
+		t.Fatalf("the table should be filtered while typing, search %q, %d rows", m.LastSearch, len(m.Rows))
+	}
+	for _, row := range m.Rows[1:] {
+		if !containsIgnoreCase(strings.Join(row, " "), "move") {
+			t.Errorf("row %v does not match the query", row)
+		}
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(ui.Model)
+	if m.SearchMode || m.LastSearch != "quit" || len(m.Rows) != before {
+		t.Errorf("esc should bring back the previous search, got %q with %d rows", m.LastSearch, len(m.Rows))
+	}
+}
+
 func TestSearchHighlighting(t *testing.T) {
 	m := initialModelWithDefaults()
 	m.LastSearch = "move"
@@ -1261,3 +1546,2232 @@ func TestOpenEditorForNote_DefaultEditor(t *testing.T) {
 		t.Errorf("error should mention editor: %v", err)
 	}
 }
+
+// stubOnlineClient serves a fixed app for downloads
+type stubOnlineClient struct {
+	*online.MockClient
+	app *apps.App
+}
+
+func (s *stubOnlineClient) DownloadCheatSheet(id string) (*apps.App, error) {
+	return s.app, nil
+}
+
+func TestOnlineDownload(t *testing.T) {
+	m := initialModelWithDefaults()
+	dataDir := t.TempDir()
+	m.Registry = apps.NewRegistry(dataDir)
+	m.ConfigLoader = config.NewLoader(filepath.Join(dataDir, "config.yaml"))
+	m.OnlineClient = &stubOnlineClient{
+		MockClient: online.NewMockClient(),
+		app: &apps.App{
+			Name:        "git",
+			Description: "Git",
+			Shortcuts:   []apps.Shortcut{{Keys: "git st", Description: "status"}},
+		},
+	}
+	m.ViewMode = ui.ViewOnline
+	m.LoadRepositories()
+
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyEnter}))
+	if !m.SheetFocus || len(m.CheatSheets) == 0 {
+		t.Fatal("enter should load sheets and focus the sheet list")
+	}
+
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}}))
+	if m.SheetCursor != 1 {
+		t.Errorf("j should move the sheet cursor, got %d", m.SheetCursor)
+	}
+
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}}))
+	if toast := lastToast(m); !strings.Contains(toast, "Installed git") {
+		t.Errorf("unexpected toast %q", toast)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "git.yaml")); err != nil {
+		t.Errorf("downloaded sheet should be saved: %v", err)
+	}
+	if !m.IsAppConfigured("git") {
+		t.Error("installed app should be added to the configured apps")
+	}
+	if m.Rows[0][len(m.Rows[0])-1] != "git" {
+		t.Errorf("table should be refreshed with the new column, header = %v", m.Rows[0])
+	}
+
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyTab}))
+	if m.SheetFocus {
+		t.Error("tab should move focus back to repositories")
+	}
+}
+
+func TestOnlineDownload_Errors(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Registry = apps.NewRegistry(t.TempDir())
+	m.ConfigLoader = nil
+	m.OnlineClient = &stubOnlineClient{
+		MockClient: online.NewMockClient(),
+		app:        &apps.App{Name: "broken"},
+	}
+	m.ViewMode = ui.ViewOnline
+	m.LoadRepositories()
+	m.LoadCheatSheets(m.ReposList[0].URL)
+	appCount := len(m.Config.Apps)
+
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}}))
+	if toast := lastToast(m); !strings.Contains(toast, "Invalid cheat sheet") || m.Toasts[0].Level != ui.ToastError {
+		t.Errorf("invalid sheets should be reported as an error, got %q", toast)
+	}
+	if len(m.Config.Apps) != appCount {
+		t.Error("invalid sheets should not be added to config")
+	}
+}
+
+// settle delivers the messages of cmd, and of the commands they lead to,
+// to the model until no task runs in the background
+func settle(model tea.Model, cmd tea.Cmd) ui.Model {
+	m := model.(ui.Model)
+	msgs := make(chan tea.Msg, 64)
+	run := func(cmd tea.Cmd) {
+		if cmd != nil {
+			go func() { msgs <- cmd() }()
+		}
+	}
+	run(cmd)
+	for len(m.Pending()) > 0 {
+		select {
+		case msg := <-msgs:
+			if batch, ok := msg.(tea.BatchMsg); ok {
+				for _, cmd := range batch {
+					run(cmd)
+				}
+				continue
+			}
+			newModel, next := m.Update(msg)
+			m = newModel.(ui.Model)
+			run(next)
+		case <-time.After(5 * time.Second):
+			return m
+		}
+	}
+	return m
+}
+
+func TestAsyncLoads(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Width = 160
+
+	// the view opens at once and fills in when the repositories arrive
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = newModel.(ui.Model)
+	if m.ViewMode != ui.ViewOnline || len(m.ReposList) != 0 {
+		t.Fatalf("o should open the online view before the repositories load, got %d", len(m.ReposList))
+	}
+	if pending := m.Pending(); len(pending) != 1 || !strings.Contains(m.View(), "loading repositories") {
+		t.Errorf("the status bar should show the running load, pending %v:\n%s", pending, m.View())
+	}
+	m = settle(m, cmd)
+	if len(m.ReposList) == 0 || strings.Contains(m.View(), "loading repositories") {
+		t.Fatal("the repositories should be listed once loaded")
+	}
+
+	// esc cancels a load, dropping its result, and only then leaves
+	newModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(ui.Model)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(ui.Model)
+	if len(m.Pending()) != 0 || m.ViewMode != ui.ViewOnline || m.StatusMessage != "Cancelled" {
+		t.Fatalf("esc should cancel the load, pending %v, view %v", m.Pending(), m.ViewMode)
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		// the load comes before the spinner
+		msg = batch[0]()
+	}
+	newModel, _ = m.Update(msg)
+	m = newModel.(ui.Model)
+	if len(m.CheatSheets) != 0 {
+		t.Error("a cancelled load should not fill in the view")
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if newModel.(ui.Model).ViewMode != ui.ViewMain {
+		t.Error("esc without a load should leave the view")
+	}
+}
+
+func TestCancelStopsRequests(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	m := initialModelWithDefaults()
+	m.OnlineClient = online.NewHTTPClient(server.URL)
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = newModel.(ui.Model)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(ui.Model)
+	if len(m.Pending()) != 0 {
+		t.Fatalf("esc should cancel the load, pending %v", m.Pending())
+	}
+
+	// the server only answers a request given up on
+	done := make(chan struct{})
+	go func() {
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			batch[0]()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a cancelled load should stop its request")
+	}
+}
+
+func TestStartupLoads(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := config.DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.Apps = []string{"vim", "lua-app"}
+	if err := config.NewLoader(configPath).Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+	plugin := `cheat.provide{ name = "lua-app", description = "Provided from Lua", shortcuts = { { keys = "j", description = "Down from Lua" } } }`
+	if err := os.MkdirAll(cfg.PluginsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.PluginsDir(), "extras.lua"), []byte(plugin), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first frame does not wait for the notes and plugins
+	m := initialModel(cliOptions{configFile: configPath})
+	m.LoadInBackground()
+	if m.NotesManager != nil || strings.Contains(m.View(), "Down from Lua") {
+		t.Fatal("notes and plugins should not be loaded before the program runs")
+	}
+	if pending := m.Pending(); !slices.Equal(pending, []string{"opening notes", "loading plugins"}) {
+		t.Errorf("pending = %v", pending)
+	}
+
+	m = settle(m, m.Init())
+	if m.NotesManager == nil {
+		t.Error("the notes should be opened in the background")
+	}
+	if _, err := m.PluginLoader.LoadedPlugin("extras"); err != nil || !strings.Contains(m.View(), "Down from Lua") {
+		t.Errorf("the apps of the plugins should join the table once loaded:\n%s", m.View())
+	}
+}
+
+// lastToast returns the text of the newest toast, or "" without toasts
+func lastToast(m ui.Model) string {
+	if len(m.Toasts) == 0 {
+		return ""
+	}
+	return m.Toasts[len(m.Toasts)-1].Text
+}
+
+func TestToasts(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hello.yaml"), []byte("name: hello\nhooks:\n  startup: echo hi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := initialModelWithDefaults()
+	m.PluginLoader = plugins.NewLoader(dir)
+	m.PluginLoader.LoadAll()
+
+	// toasts stack instead of replacing each other
+	var expirations []tea.Cmd
+	for i := 0; i < 4; i++ {
+		newModel, cmd := m.Update(m.Init()())
+		m = newModel.(ui.Model)
+		if cmd == nil {
+			t.Fatal("a toast should come with the command dismissing it")
+		}
+		expirations = append(expirations, cmd)
+	}
+	if len(m.Toasts) != 4 {
+		t.Fatalf("toasts = %+v", m.Toasts)
+	}
+	view := m.View()
+	if strings.Count(view, "hello: hi") != 3 || !strings.Contains(view, "… 1 more") {
+		t.Errorf("the newest three toasts should be shown:\n%s", view)
+	}
+
+	// each toast goes away once its timeout has passed
+	if testing.Short() {
+		return
+	}
+	newModel, _ := m.Update(expirations[1]())
+	m = newModel.(ui.Model)
+	if len(m.Toasts) != 3 || m.Toasts[1].ID == 2 {
+		t.Errorf("the expired toast should be dismissed, got %+v", m.Toasts)
+	}
+}
+
+func TestHistoryUndo(t *testing.T) {
+	m := initialModelWithDefaults()
+	dataDir := t.TempDir()
+
+	j, err := journal.Open(filepath.Join(dataDir, "journal.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Journal = j
+	m.Registry = apps.NewRegistry(dataDir)
+	m.Registry.SetJournal(j, journal.SourceUI)
+	m.ConfigLoader = config.NewLoader(filepath.Join(dataDir, "config.yaml"))
+	fm, err := notes.NewFileManager(filepath.Join(dataDir, "notes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm.SetJournal(j, journal.SourceUI)
+	m.NotesManager = fm
+
+	note := &notes.Note{Title: "Original"}
+	fm.CreateNote(note)
+	fm.UpdateNote(note.ID, &notes.Note{Title: "Edited"})
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'H'}})
+	m = newModel.(ui.Model)
+	if m.ViewMode != ui.ViewHistory || len(m.HistoryList) != 2 {
+		t.Fatalf("H should open history with 2 entries, got view %v and %d entries", m.ViewMode, len(m.HistoryList))
+	}
+	if !strings.Contains(m.View(), "Edited") {
+		t.Error("history view should list the latest change")
+	}
+
+	// Undo the edit
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	m = newModel.(ui.Model)
+	restored, _ := fm.GetNote(note.ID)
+	if restored == nil || restored.Title != "Original" {
+		t.Errorf("undo should restore the previous title, got %+v (status %q)", restored, m.StatusMessage)
+	}
+	if len(m.HistoryList) != 3 {
+		t.Errorf("the undo itself should be journaled, got %d entries", len(m.HistoryList))
+	}
+
+	// Undo the original create
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	newModel, _ = newModel.(ui.Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	newModel, _ = newModel.(ui.Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	m = newModel.(ui.Model)
+	if _, err := fm.GetNote(note.ID); err != notes.ErrNoteNotFound {
+		t.Errorf("undoing the create should delete the note, status %q", m.StatusMessage)
+	}
+
+	// Undo an app install
+	m.Config.Apps = append(m.Config.Apps, "git")
+	m.Registry.SaveApp(&apps.App{
+		Name:        "git",
+		Description: "Git",
+		Shortcuts:   []apps.Shortcut{{Keys: "git st", Description: "status"}},
+	})
+	m.LoadHistory()
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	m = newModel.(ui.Model)
+	if m.IsAppConfigured("git") {
+		t.Errorf("undoing an app install should unconfigure it, status %q", m.StatusMessage)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "git.yaml")); !os.IsNotExist(err) {
+		t.Error("undoing an app install should remove its file")
+	}
+}
+
+// countingOnlineClient counts cheat sheet fetches
+type countingOnlineClient struct {
+	*online.MockClient
+	fetches int
+}
+
+func (c *countingOnlineClient) GetCheatSheet(id string) (*online.CheatSheet, error) {
+	c.fetches++
+	return c.MockClient.GetCheatSheet(id)
+}
+
+func TestOnlinePreview(t *testing.T) {
+	m := initialModelWithDefaults()
+	client := &countingOnlineClient{MockClient: online.NewMockClient()}
+	m.OnlineClient = client
+	m.ViewMode = ui.ViewOnline
+	m.LoadRepositories()
+
+	press := func(keys ...string) {
+		for _, k := range keys {
+			var msg tea.KeyMsg
+			switch k {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "esc":
+				msg = tea.KeyMsg{Type: tea.KeyEsc}
+			default:
+				msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			}
+			m = settle(m.Update(msg))
+		}
+	}
+
+	press("enter")
+	if client.fetches != 0 {
+		t.Fatalf("listing sheets should not fetch them, got %d fetches", client.fetches)
+	}
+
+	press("v")
+	if m.SheetPreview == nil || m.SheetPreview.ID != "vim-advanced" {
+		t.Fatalf("v should preview the selected sheet, got %+v", m.SheetPreview)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Preview") || !strings.Contains(view, "Record macro") {
+		t.Errorf("preview pane should render shortcuts:\n%s", view)
+	}
+
+	press("j")
+	if m.SheetPreview == nil || m.SheetPreview.ID != "git-workflow" {
+		t.Errorf("moving the cursor should update the open preview, got %+v", m.SheetPreview)
+	}
+	if client.fetches != 2 {
+		t.Errorf("expected 2 fetches, got %d", client.fetches)
+	}
+
+	press("esc")
+	if m.SheetPreview != nil || m.ViewMode != ui.ViewOnline {
+		t.Error("esc should close the preview before leaving the view")
+	}
+
+	press("j")
+	if client.fetches != 2 {
+		t.Error("closed preview should not fetch on cursor moves")
+	}
+}
+
+func TestOnlineRatingAndSort(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.ViewMode = ui.ViewOnline
+	m.LoadRepositories()
+
+	press := func(msgs ...tea.KeyMsg) {
+		for _, msg := range msgs {
+			m = settle(m.Update(msg))
+		}
+	}
+	key := func(k string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.CheatSheets) < 2 {
+		t.Fatalf("expected mock sheets, got %d", len(m.CheatSheets))
+	}
+
+	press(key("s"))
+	if m.SheetSort != online.SortByName || m.CheatSheets[0].Name != "Git Workflow" {
+		t.Errorf("first s should sort by name, got %s with %s first", m.SheetSort, m.CheatSheets[0].Name)
+	}
+	press(key("s"))
+	if m.SheetSort != online.SortByRating || m.CheatSheets[0].Name != "Vim Advanced" {
+		t.Errorf("second s should sort by rating, got %s with %s first", m.SheetSort, m.CheatSheets[0].Name)
+	}
+	if m.CheatSheets[m.SheetCursor].Name != "Vim Advanced" {
+		t.Error("cursor should stay on the selected sheet when sorting")
+	}
+	if !strings.Contains(m.View(), "Rating▼") {
+		t.Error("sorted column should be marked in the header")
+	}
+	if !strings.Contains(m.View(), "git,vcs,workflow") {
+		t.Error("sheet list should show tags")
+	}
+
+	press(key("r"))
+	if !m.RatingMode || !strings.Contains(m.View(), "press 1-5") {
+		t.Fatal("r should open the rating prompt")
+	}
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.RatingMode || m.ViewMode != ui.ViewOnline {
+		t.Error("esc should only cancel the rating prompt")
+	}
+
+	before := m.CheatSheets[m.SheetCursor].Rating
+	press(key("r"), key("9"))
+	if !m.RatingMode {
+		t.Error("out of range keys should keep the prompt open")
+	}
+	press(key("5"))
+	if m.RatingMode || !strings.Contains(m.StatusMessage, "Rated Vim Advanced 5/5") {
+		t.Errorf("rating should be submitted, status %q", m.StatusMessage)
+	}
+	if got := m.CheatSheets[m.SheetCursor].Rating; got <= before {
+		t.Errorf("displayed rating should be refreshed, got %.2f (was %.2f)", got, before)
+	}
+}
+
+func TestHistoryTimeTravel(t *testing.T) {
+	m := initialModelWithDefaults()
+	dataDir := t.TempDir()
+
+	j, err := journal.Open(filepath.Join(dataDir, "journal.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Journal = j
+	fm, err := notes.NewFileManager(filepath.Join(dataDir, "notes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm.SetJournal(j, journal.SourceUI)
+	m.NotesManager = fm
+
+	note := &notes.Note{Title: "Draft", Content: "first version"}
+	fm.CreateNote(note)
+	fm.UpdateNote(note.ID, &notes.Note{Title: "Final", Content: "second version"})
+	fm.DeleteNote(note.ID)
+	fm.CreateNote(&notes.Note{Title: "Later"})
+
+	m.ViewMode = ui.ViewHistory
+	m.LoadHistory()
+
+	// Entries are newest first; select the create of "Draft"
+	m.HistoryCursor = len(m.HistoryList) - 1
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	m = newModel.(ui.Model)
+	if m.ViewMode != ui.ViewSnapshot || m.Snapshot == nil {
+		t.Fatal("t should open the snapshot view")
+	}
+	if len(m.Snapshot.Items) != 1 {
+		t.Fatalf("snapshot should only contain the draft, got %+v", m.Snapshot.Items)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Draft") || !strings.Contains(view, "first version") {
+		t.Errorf("snapshot view should show the note as it was:\n%s", view)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	m = newModel.(ui.Model)
+	restored, err := fm.GetNote(note.ID)
+	if err != nil || restored.Title != "Draft" {
+		t.Errorf("r should restore the note from the snapshot, got %+v, %v (status %q)", restored, err, m.StatusMessage)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(ui.Model)
+	if m.ViewMode != ui.ViewHistory {
+		t.Error("esc should return to the history view")
+	}
+}
+
+func TestQuizInput(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Store = storage.NewFileStorage(t.TempDir())
+	m.FilteredApps = []string{"zathura"}
+
+	send := func(m ui.Model, msg tea.KeyMsg) ui.Model {
+		newModel, _ := m.Update(msg)
+		return newModel.(ui.Model)
+	}
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Q")})
+	if m.ViewMode != ui.ViewQuiz || m.QuizQuestion.App != "zathura" {
+		t.Fatalf("Q should start a quiz on the filtered apps, got view %v question %+v", m.ViewMode, m.QuizQuestion)
+	}
+
+	// a typed answer is checked against the keys
+	for _, r := range m.QuizQuestion.Shortcut.Keys {
+		m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.QuizGraded || !m.QuizLastCorrect || m.QuizCorrect != 1 {
+		t.Errorf("the right keys should be graded correct, got %+v", m.QuizQuestion)
+	}
+	if !strings.Contains(m.View(), "✓ Correct") {
+		t.Error("the quiz view should confirm a correct answer")
+	}
+
+	// revealing asks whether the keys were known
+	first := m.QuizQuestion
+	m = send(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.QuizGraded || m.QuizQuestion.Shortcut.Keys == first.Shortcut.Keys {
+		t.Errorf("enter should ask a different question, got %+v", m.QuizQuestion)
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyTab})
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if !m.QuizGraded || m.QuizLastCorrect || m.QuizAsked != 2 {
+		t.Errorf("n after revealing should count as wrong, asked %d", m.QuizAsked)
+	}
+	if card, ok := m.Practice.Card(m.QuizQuestion.App, m.QuizQuestion.Shortcut.Keys); !ok || card.Box != 0 {
+		t.Errorf("the wrong answer should be recorded, got %+v", card)
+	}
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.ViewMode != ui.ViewMain || m.StatusMessage != "Quiz score: 1/2" {
+		t.Errorf("esc should end the quiz with its score, got %q", m.StatusMessage)
+	}
+}
+
+func TestStatsView(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Store = storage.NewFileStorage(t.TempDir())
+	m.Config.DataDir = t.TempDir()
+
+	send := func(m ui.Model, msg tea.KeyMsg) ui.Model {
+		newModel, _ := m.Update(msg)
+		return newModel.(ui.Model)
+	}
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	if m.ViewMode != ui.ViewStats || !strings.Contains(m.View(), "No practice yet") {
+		t.Fatalf("S should open the empty stats view, got %v", m.ViewMode)
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Q")})
+	m = send(m, tea.KeyMsg{Type: tea.KeyTab})
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m = send(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	view := m.View()
+	if !strings.Contains(view, "1 reviews") || !strings.Contains(view, m.QuizQuestion.App) {
+		t.Errorf("stats should count the quiz answer, got:\n%s", view)
+	}
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	data, err := os.ReadFile(filepath.Join(m.Config.DataDir, "practice-stats.json"))
+	if err != nil {
+		t.Fatalf("e should export the stats: %v (%s)", err, m.StatusMessage)
+	}
+	if !strings.Contains(string(data), `"reviews": 1`) {
+		t.Errorf("unexpected export %s", data)
+	}
+}
+
+func TestSetupWizard(t *testing.T) {
+	m := initialModelWithDefaults()
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	dataDir := t.TempDir()
+	m.ConfigLoader = config.NewLoader(configPath)
+	m = m.StartSetup()
+	if m.ViewMode != ui.ViewSetup || !strings.Contains(m.View(), "Welcome to cheat-go") {
+		t.Fatalf("StartSetup should open the wizard, got view %v", m.ViewMode)
+	}
+
+	send := func(m ui.Model, msg tea.KeyMsg) ui.Model {
+		newModel, _ := m.Update(msg)
+		return newModel.(ui.Model)
+	}
+	typeText := func(m ui.Model, text string) ui.Model {
+		for _, r := range text {
+			m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+		return m
+	}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+
+	// keep only the first app listed
+	for i, name := range m.SetupApps {
+		if i > 0 {
+			m = send(m, down)
+		}
+		if slices.Contains(m.SetupSelected, name) != (i == 0) {
+			m = send(m, tea.KeyMsg{Type: tea.KeySpace})
+		}
+	}
+	first := m.SetupApps[0]
+	m = send(m, enter)
+
+	// theme and table style are picked from lists
+	m = send(m, down)
+	m = send(m, enter)
+	m = send(m, down)
+	m = send(m, down)
+	m = send(m, enter)
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyCtrlU})
+	m = typeText(m, dataDir)
+	m = send(m, enter)
+
+	// enabling sync asks for the server
+	m = send(m, down)
+	m = send(m, enter)
+	m = send(m, enter)
+	if m.StatusMessage == "" {
+		t.Error("an empty sync endpoint should be refused")
+	}
+	m = typeText(m, "https://sync.example.com")
+	m = send(m, enter)
+	m = typeText(m, "secret")
+	m = send(m, enter)
+	if !strings.Contains(m.View(), "Save these settings?") || strings.Contains(m.View(), "secret") {
+		t.Fatalf("the review should list the settings without the API key:\n%s", m.View())
+	}
+	m = send(m, enter)
+	if m.ViewMode != ui.ViewMain {
+		t.Fatalf("saving should return to the table, got %v (%s)", m.ViewMode, m.StatusMessage)
+	}
+
+	saved, err := config.NewLoader(configPath).Load()
+	if err != nil {
+		t.Fatalf("failed to load saved config: %v", err)
+	}
+	if len(saved.Apps) != 1 || saved.Apps[0] != first {
+		t.Errorf("saved apps = %v, want [%s]", saved.Apps, first)
+	}
+	if saved.Theme != config.ValidThemes[1] || saved.Layout.TableStyle != config.ValidTableStyles[2] {
+		t.Errorf("saved theme %q and style %q", saved.Theme, saved.Layout.TableStyle)
+	}
+	if saved.DataDir != dataDir {
+		t.Errorf("saved data dir = %q, want %q", saved.DataDir, dataDir)
+	}
+	if !saved.Sync.Enabled || saved.Sync.Endpoint != "https://sync.example.com" || saved.Sync.APIKey != "secret" {
+		t.Errorf("saved sync = %+v", saved.Sync)
+	}
+	if len(m.AllApps) != 1 || m.AllApps[0] != first || m.Config.Theme != saved.Theme {
+		t.Errorf("the wizard should apply the settings, apps %v", m.AllApps)
+	}
+}
+
+func TestSetupWizardSkip(t *testing.T) {
+	m := initialModelWithDefaults()
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	m.ConfigLoader = config.NewLoader(configPath)
+	m = m.StartSetup()
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(ui.Model)
+	if m.ViewMode != ui.ViewMain {
+		t.Errorf("esc should skip the wizard, got %v", m.ViewMode)
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Error("skipping the wizard should not write a config file")
+	}
+}
+
+func TestProfileSelector(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Config.Profiles = map[string]config.Profile{
+		"work": {Apps: []string{"vim"}, Theme: "dark"},
+	}
+
+	send := func(m ui.Model, msg tea.KeyMsg) ui.Model {
+		newModel, _ := m.Update(msg)
+		return newModel.(ui.Model)
+	}
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+	if !m.ProfileMode || !strings.Contains(m.View(), "work") {
+		t.Fatal("P should list the profiles")
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyDown})
+	m = send(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.ProfileMode || m.Config.ActiveProfile() != "work" {
+		t.Fatalf("enter should switch to the work profile, got %q", m.Config.ActiveProfile())
+	}
+	if len(m.AllApps) != 1 || m.AllApps[0] != "vim" || m.Renderer.GetTheme().Name != "dark" {
+		t.Errorf("the profile should change the apps and theme, got %v", m.AllApps)
+	}
+
+	// the base configuration is listed first
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+	m = send(m, tea.KeyMsg{Type: tea.KeyUp})
+	m = send(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Config.ActiveProfile() != "" || len(m.AllApps) == 1 {
+		t.Errorf("the base configuration should be restored, got apps %v", m.AllApps)
+	}
+}
+
+func TestThemePicker(t *testing.T) {
+	m := initialModelWithDefaults()
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	m.ConfigLoader = config.NewLoader(configPath)
+
+	send := func(m ui.Model, msg tea.KeyMsg) ui.Model {
+		newModel, _ := m.Update(msg)
+		return newModel.(ui.Model)
+	}
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	if !m.ThemeMode || !strings.Contains(m.View(), "minimal") {
+		t.Fatal("T should list the themes")
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyDown})
+	if m.Renderer.GetTheme().Name != "dark" || m.Config.Theme != "default" {
+		t.Errorf("moving should preview the dark theme without keeping it, got %q", m.Renderer.GetTheme().Name)
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.ThemeMode || m.Renderer.GetTheme().Name != "default" {
+		t.Errorf("esc should go back to the default theme, got %q", m.Renderer.GetTheme().Name)
+	}
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	m = send(m, tea.KeyMsg{Type: tea.KeyDown})
+	m = send(m, tea.KeyMsg{Type: tea.KeyDown})
+	m = send(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.ThemeMode || m.Renderer.GetTheme().Name != "light" || m.Config.Theme != "light" {
+		t.Fatalf("enter should use the light theme: %q", m.StatusMessage)
+	}
+	saved, err := config.NewLoader(configPath).Load()
+	if err != nil || saved.Theme != "light" {
+		t.Errorf("the theme should be saved to the config, got %+v (%v)", saved, err)
+	}
+}
+
+func TestDetailPane(t *testing.T) {
+	m := initialModelWithDefaults()
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	m.ConfigLoader = config.NewLoader(configPath)
+	m.CursorX, m.CursorY = 1, 1
+
+	send := func(m ui.Model, msg tea.Msg) ui.Model {
+		newModel, _ := m.Update(msg)
+		return newModel.(ui.Model)
+	}
+
+	m = send(m, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	app, keys, ok := m.SelectedShortcut()
+	if !ok {
+		t.Fatal("the cursor should be on a shortcut")
+	}
+	view := m.View()
+	if !strings.Contains(view, "Details") || !strings.Contains(view, keys+" in "+app) {
+		t.Fatalf("D should show the details of %s in %s:\n%s", keys, app, view)
+	}
+	table, _, _ := strings.Cut(view, "Arrow keys")
+	for _, line := range strings.Split(table, "\n") {
+		if width := lipgloss.Width(line); width > 120 {
+			t.Fatalf("the table and the pane should fit the terminal, got a line %d wide:\n%s", width, view)
+		}
+	}
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">")})
+	if m.Config.Layout.DetailWidth != config.DefaultDetailWidth+4 {
+		t.Errorf("> should widen the pane, got %d", m.Config.Layout.DetailWidth)
+	}
+	saved, err := config.NewLoader(configPath).Load()
+	if err != nil || !saved.Layout.DetailPane || saved.Layout.DetailWidth != config.DefaultDetailWidth+4 {
+		t.Errorf("the pane should be remembered in the config, got %+v (%v)", saved.Layout, err)
+	}
+	for i := 0; i < 10; i++ {
+		m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("<")})
+	}
+	if m.Config.Layout.DetailWidth != config.MinDetailWidth {
+		t.Errorf("< should not narrow the pane below %d, got %d", config.MinDetailWidth, m.Config.Layout.DetailWidth)
+	}
+
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	if strings.Contains(m.View(), "Details") {
+		t.Error("D should hide the pane again")
+	}
+}
+
+func TestPluginHooks(t *testing.T) {
+	dir := t.TempDir()
+	hooked := "name: notify\nhooks:\n  startup: echo ready\n  search: echo \"looked up $CHEATGO_QUERY\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "notify.yaml"), []byte(hooked), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModelWithDefaults()
+	m.PluginLoader = plugins.NewLoader(dir)
+	m.PluginLoader.LoadAll()
+
+	// a command's result comes back to Update as a message
+	deliver := func(m ui.Model, cmd tea.Cmd) ui.Model {
+		if cmd == nil {
+			t.Fatal("expected a command running the plugin hooks")
+		}
+		newModel, _ := m.Update(cmd())
+		return newModel.(ui.Model)
+	}
+
+	m = deliver(m, m.Init())
+	if toast := lastToast(m); toast != "notify: ready" {
+		t.Errorf("startup hook message = %q", toast)
+	}
+
+	m.SearchMode = true
+	m.SearchQuery = "undo"
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = deliver(newModel.(ui.Model), cmd)
+	if toast := lastToast(m); toast != "notify: looked up undo" {
+		t.Errorf("search hook message = %q", toast)
+	}
+}
+
+func TestPluginToggle(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fmt.yaml"), []byte("name: fmt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	m := initialModelWithDefaults()
+	m.ConfigLoader = config.NewLoader(configPath)
+	m.PluginLoader = plugins.NewLoader(dir)
+	m.PluginLoader.LoadAll()
+
+	send := func(m ui.Model, key string) ui.Model {
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return newModel.(ui.Model)
+	}
+
+	m = send(m, "p")
+	m = send(m, "e")
+	if !m.PluginsList[0].Disabled || !strings.Contains(m.View(), "(disabled)") {
+		t.Fatalf("e should disable the plugin, status %q", m.StatusMessage)
+	}
+	saved, _ := config.NewLoader(configPath).Load()
+	if len(saved.Plugins.Disabled) != 1 || saved.Plugins.Disabled[0] != "fmt" {
+		t.Errorf("the disabled plugin should be saved, got %v", saved.Plugins.Disabled)
+	}
+
+	m = send(m, "e")
+	if m.PluginsList[0].Disabled {
+		t.Error("e should enable the plugin again")
+	}
+	saved, _ = config.NewLoader(configPath).Load()
+	if len(saved.Plugins.Disabled) != 0 {
+		t.Errorf("the enabled plugin should be removed from the config, got %v", saved.Plugins.Disabled)
+	}
+}
+
+func TestPluginDetail(t *testing.T) {
+	dir := t.TempDir()
+	plugin := `name: fmt
+version: 1.2.0
+description: Formats things
+config:
+  width: 80
+hooks:
+  select: echo selected
+apps:
+  - name: fmt-app
+    rows:
+      - ["Format", "ctrl+f"]
+`
+	if err := os.WriteFile(filepath.Join(dir, "fmt.yaml"), []byte(plugin), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModelWithDefaults()
+	m.PluginLoader = plugins.NewLoader(dir)
+	m.PluginLoader.LoadAll()
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = newModel.(ui.Model)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(ui.Model)
+	if !m.PluginDetail {
+		t.Fatal("enter should open the plugin details")
+	}
+
+	view := m.View()
+	for _, want := range []string{"Plugin Details", "1.2.0", "Formats things", "Path", "width: 80", "select: echo selected", "Last error"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("details should contain %q:\n%s", want, view)
+		}
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(ui.Model)
+	if m.PluginDetail || m.ViewMode != ui.ViewPlugins {
+		t.Error("esc should go back to the plugin list")
+	}
+}
+
+// emptySync is a sync service with nothing to merge
+type emptySync struct{}
+
+func (emptySync) Push(data sync.SyncData) error { return nil }
+func (emptySync) Pull() (*sync.SyncData, error) {
+	return &sync.SyncData{Timestamp: time.Now()}, nil
+}
+func (emptySync) GetLastSync() (time.Time, error) { return time.Time{}, nil }
+func (emptySync) ResolveConflict(sync.SyncItem, sync.ConflictResolution) error {
+	return nil
+}
+
+func TestSyncProgress(t *testing.T) {
+	m := initialModelWithDefaults()
+	manager, err := sync.NewManager(emptySync{}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SyncManager = manager
+	m.ViewMode = ui.ViewSync
+	load := m.LoadSyncStatus()
+	m = settle(m, load)
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = newModel.(ui.Model)
+	if cmd == nil || m.SyncProgress == nil {
+		t.Fatal("s should start a sync and follow its progress")
+	}
+	if view := m.View(); !strings.Contains(view, "Progress:") {
+		t.Errorf("the sync view should show a progress bar while syncing:\n%s", view)
+	}
+
+	var stages []sync.SyncStage
+	for cmd != nil && m.SyncProgress != nil {
+		newModel, cmd = m.Update(cmd())
+		m = newModel.(ui.Model)
+		if m.SyncProgress != nil {
+			stages = append(stages, m.SyncProgress.Stage)
+		}
+	}
+	if len(stages) == 0 || stages[len(stages)-1] != sync.StageDone {
+		t.Errorf("progress should end with the done stage, got %v", stages)
+	}
+	if toast := lastToast(m); m.SyncProgress != nil || toast != "Sync complete" {
+		t.Errorf("after the sync: progress %+v, toast %q", m.SyncProgress, toast)
+	}
+	if m.SyncStatus.LastSync.IsZero() {
+		t.Error("the sync status should be reloaded once the sync finished")
+	}
+}
+
+// deviceSync is a sync service whose server lists two devices
+type deviceSync struct {
+	emptySync
+	devices []sync.Device
+}
+
+func (s *deviceSync) ListDevices(context.Context) ([]sync.Device, error) {
+	return append([]sync.Device(nil), s.devices...), nil
+}
+
+func (s *deviceSync) RevokeDevice(id string) error {
+	for i, device := range s.devices {
+		if device.ID == id {
+			s.devices = append(s.devices[:i], s.devices[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func TestSyncDevices(t *testing.T) {
+	m := initialModelWithDefaults()
+	service := &deviceSync{}
+	manager, err := sync.NewManager(service, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.devices = []sync.Device{
+		{ID: manager.GetSyncStatus().DeviceID, Name: "Desktop", LastSeen: time.Now()},
+		{ID: "lost", Name: "Lost laptop", LastSeen: time.Now().Add(-time.Hour)},
+	}
+	m.SyncManager = manager
+	m.ViewMode = ui.ViewSync
+	load := m.LoadSyncStatus()
+	m = settle(m, load)
+
+	press := func(keys ...tea.KeyMsg) {
+		for _, key := range keys {
+			m = settle(m.Update(key))
+		}
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	press(runes("d"))
+	if !m.DeviceMode || len(m.DevicesList) != 2 {
+		t.Fatalf("d should list the devices, got %+v", m.DevicesList)
+	}
+	if view := m.View(); !strings.Contains(view, "Lost laptop") {
+		t.Errorf("the devices screen should list the devices:\n%s", view)
+	}
+
+	press(runes("x"))
+	if m.StatusMessage != "This device cannot be revoked" {
+		t.Errorf("revoking the current device: %q", m.StatusMessage)
+	}
+	press(tea.KeyMsg{Type: tea.KeyDown}, runes("x"))
+	if len(service.devices) != 2 {
+		t.Fatal("a single x should only ask for confirmation")
+	}
+	press(runes("x"))
+	if len(service.devices) != 1 || len(m.DevicesList) != 1 {
+		t.Errorf("a second x should revoke the device, left %+v", service.devices)
+	}
+
+	press(runes("n"), tea.KeyMsg{Type: tea.KeyCtrlU}, runes("Work desktop"), tea.KeyMsg{Type: tea.KeyEnter})
+	if name := manager.DeviceName(); name != "Work desktop" {
+		t.Errorf("n should name this device, got %q", name)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.DeviceMode || !strings.Contains(m.View(), "Work desktop") {
+		t.Errorf("esc should return to the sync status showing the name:\n%s", m.View())
+	}
+}
+
+func TestSyncAutoSyncToggle(t *testing.T) {
+	m := initialModelWithDefaults()
+	manager, err := sync.NewManager(emptySync{}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.StopAutoSync()
+	m.SyncManager = manager
+	m.ViewMode = ui.ViewSync
+	load := m.LoadSyncStatus()
+	m = settle(m, load)
+
+	for _, want := range []bool{true, false, true} {
+		m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}))
+		if manager.AutoSyncEnabled() != want || m.SyncStatus.AutoSync != want {
+			t.Fatalf("a should turn auto-sync %v: %q", want, m.StatusMessage)
+		}
+	}
+	if view := m.View(); !strings.Contains(view, "Auto-sync:  every") {
+		t.Errorf("the sync view should show the auto-sync interval:\n%s", view)
+	}
+}
+
+func TestSyncStatusStuckDaemon(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Config.DataDir = t.TempDir()
+
+	// a daemon that takes connections but never answers
+	listener, err := net.Listen("unix", m.Config.DaemonSocket())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	start := time.Now()
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = newModel.(ui.Model)
+	if elapsed := time.Since(start); elapsed > time.Second || cmd == nil {
+		t.Fatalf("the daemon should be asked in the background, s took %s", elapsed)
+	}
+	if m.ViewMode != ui.ViewSync || !slices.Contains(m.Pending(), "asking the daemon") {
+		t.Errorf("the sync view should wait for the daemon, pending %v", m.Pending())
+	}
+}
+
+func TestLayoutColumns(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Registry.SetColumns([]string{"shortcut", "description", "category"})
+	m.Config.Apps = []string{"vim"}
+	m.RefreshTable()
+
+	if header := m.Rows[0]; len(header) != 3 || header[1] != "vim" || header[2] != "vim:category" {
+		t.Fatalf("header = %q", header)
+	}
+	m.CursorX, m.CursorY = 2, 1
+	if app, _, ok := m.SelectedShortcut(); !ok || app != "vim" {
+		t.Errorf("the category column should belong to vim, got %q", app)
+	}
+	if view := m.View(); !strings.Contains(view, "vim:category") {
+		t.Errorf("the table should show the category column:\n%s", view)
+	}
+}
+
+func TestStatusBar(t *testing.T) {
+	m := initialModelWithDefaults()
+	send := func(msg tea.Msg) {
+		newModel, _ := m.Update(msg)
+		m = newModel.(ui.Model)
+	}
+
+	send(tea.WindowSizeMsg{Width: 100, Height: 40})
+	if view := m.View(); !strings.Contains(view, " TABLE │") {
+		t.Errorf("the status bar should show the mode:\n%s", view)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	for _, r := range "undo" {
+		send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if view := m.View(); !strings.Contains(view, " SEARCH │") {
+		t.Errorf("the status bar should follow the search mode:\n%s", view)
+	}
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	lines := strings.Split(strings.TrimRight(m.View(), "\n"), "\n")
+	bar := lines[len(lines)-1]
+	if !strings.Contains(bar, `search: "undo"`) || runewidth.StringWidth(bar) != 100 {
+		t.Errorf("the status bar should show the search across the terminal, got %q", bar)
+	}
+
+	// other views keep the bar, with their messages
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if view := m.View(); !strings.Contains(view, " SYNC │") || !strings.Contains(view, `search: "undo"`) {
+		t.Errorf("the sync view should show the status bar:\n%s", view)
+	}
+}
+
+func TestFind(t *testing.T) {
+	m := initialModelWithDefaults()
+	fm, err := notes.NewFileManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.NotesManager = fm
+	note := &notes.Note{Title: "Quit vim without saving", Content: ":q! drops the changes", AppName: "vim"}
+	fm.CreateNote(note)
+
+	find := func(m ui.Model, query string) ui.Model {
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+		m = newModel.(ui.Model)
+		var cmd tea.Cmd
+		for _, r := range query {
+			newModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			m = newModel.(ui.Model)
+		}
+		return settle(m.Update(cmd()))
+	}
+
+	m = find(m, "quit")
+	if m.ViewMode != ui.ViewFind {
+		t.Fatalf("F should open the find view, got %v", m.ViewMode)
+	}
+	if len(m.FindShortcuts) != 3 || m.FindShortcuts[0].AppName != "vim" {
+		t.Errorf("shortcuts should be found in the order of the apps, got %+v", m.FindShortcuts)
+	}
+	if len(m.FindNotes) != 1 || len(m.FindSheets) != 0 {
+		t.Errorf("found %d notes and %d sheets", len(m.FindNotes), len(m.FindSheets))
+	}
+	view := m.View()
+	for _, group := range []string{"Shortcuts (3)", "Notes (1)", "Online (0)"} {
+		if !strings.Contains(view, group) {
+			t.Errorf("the results should be grouped under %q:\n%s", group, view)
+		}
+	}
+
+	// enter on a shortcut moves the table cursor onto it
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(ui.Model)
+	if app, keys, ok := m.SelectedShortcut(); m.ViewMode != ui.ViewMain || !ok || app != "vim" || keys != "q" {
+		t.Errorf("enter should select q of vim in the table, got %q %q in view %v", app, keys, m.ViewMode)
+	}
+
+	// the note comes after the shortcuts, and esc from it goes back to the results
+	m = find(m, "quit")
+	for i := 0; i < 3; i++ {
+		newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = newModel.(ui.Model)
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(ui.Model)
+	if m.ViewMode != ui.ViewNotePreview || !strings.Contains(m.View(), note.Title) {
+		t.Fatalf("enter should open the note, got view %v", m.ViewMode)
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(ui.Model)
+	if m.ViewMode != ui.ViewFind || len(m.FindNotes) != 1 {
+		t.Errorf("esc should go back to the results, got view %v", m.ViewMode)
+	}
+
+	// online sheets are searched in the background
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = find(newModel.(ui.Model), "vim")
+	if len(m.FindSheets) != 1 || m.FindSheets[0].ID != "vim-advanced" {
+		t.Fatalf("the online sheets should be found, got %+v", m.FindSheets)
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = newModel.(ui.Model)
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyEnter}))
+	if m.ViewMode != ui.ViewOnline || m.SheetCursor != 0 || len(m.CheatSheets) != 1 || m.CheatSheets[0].ID != "vim-advanced" {
+		t.Errorf("enter should show the sheet in the online view, got view %v", m.ViewMode)
+	}
+}
+
+func TestCategorySections(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Registry.Register(&apps.App{Name: "ed", Description: "Editor", Shortcuts: []apps.Shortcut{
+		{Keys: "w", Description: "word", Category: "motion"},
+		{Keys: "b", Description: "back", Category: "motion"},
+		{Keys: "u", Description: "undo", Category: "editing"},
+		{Keys: "x", Description: "cut", Category: "editing"},
+	}})
+	press := func(key string) {
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = newModel.(ui.Model)
+	}
+
+	press("}")
+	if !strings.Contains(m.StatusMessage, "show_categories") {
+		t.Errorf("} without sections should tell how to get them, got %q", m.StatusMessage)
+	}
+
+	m.Registry.SetShowCategories(true)
+	m.Rows = m.Registry.GetTableData([]string{"ed"})
+	m.CursorX = 1
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyHome})
+	m = newModel.(ui.Model)
+	// ▸ editing, u, x, ▸ motion, b, w
+	if m.CursorY != 2 {
+		t.Fatalf("home should skip the first heading, cursor on row %d", m.CursorY)
+	}
+	view := m.View()
+	if !strings.Contains(view, "▸ editing") || !strings.Contains(view, "▸ motion") {
+		t.Errorf("the table should show the category headings:\n%s", view)
+	}
+
+	press("j")
+	press("j")
+	if m.CursorY != 5 {
+		t.Errorf("moving down should step over headings, cursor on row %d", m.CursorY)
+	}
+	if app, keys, ok := m.SelectedShortcut(); !ok || app != "ed" || keys != "b" {
+		t.Errorf("selected %q %q", app, keys)
+	}
+	press("k")
+	if m.CursorY != 3 {
+		t.Errorf("moving up should step over headings, cursor on row %d", m.CursorY)
+	}
+
+	press("}")
+	if m.CursorY != 5 {
+		t.Errorf("} should jump to the next category, cursor on row %d", m.CursorY)
+	}
+	press("}")
+	if m.CursorY != 5 {
+		t.Errorf("} in the last category should stay, cursor on row %d", m.CursorY)
+	}
+	press("j")
+	press("{")
+	if m.CursorY != 5 {
+		t.Errorf("{ should go to the start of the category, cursor on row %d", m.CursorY)
+	}
+	press("{")
+	if m.CursorY != 2 {
+		t.Errorf("{ at the start should go to the previous category, cursor on row %d", m.CursorY)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	m := initialModelWithDefaults()
+	dataDir := t.TempDir()
+	os.WriteFile(filepath.Join(dataDir, "broken.yaml"), []byte("name: broken\nshortcuts:\n  - keys: x\n    platform: osx\n"), 0644)
+	m.Registry = apps.NewRegistry(dataDir)
+
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")}))
+	if m.ViewMode != ui.ViewDiagnostics {
+		t.Fatalf("! should open the diagnostics view, got %v", m.ViewMode)
+	}
+	if len(m.Diagnoses) != 1 || m.Diagnoses[0].Key != "broken" || !m.Diagnoses[0].Failing() {
+		t.Fatalf("diagnoses = %+v", m.Diagnoses)
+	}
+	view := m.View()
+	for _, want := range []string{"1 files with problems, 1 not loading", "line 1: description: is required",
+		`line 4: shortcuts[0].platform: unknown value`} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should show %q:\n%s", want, view)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dataDir, "broken.yaml"), []byte("name: broken\ndescription: fixed\nshortcuts:\n  - keys: x\n    description: y\n"), 0644)
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}))
+	if len(m.Diagnoses) != 0 || !strings.Contains(m.View(), "Every app file is valid") {
+		t.Errorf("checking again should find the file fixed, got %+v", m.Diagnoses)
+	}
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if newModel.(ui.Model).ViewMode != ui.ViewMain {
+		t.Error("esc should return to the table")
+	}
+}
+
+func TestExportTable(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Config.DataDir = t.TempDir()
+
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")}))
+	if !m.ExportMode || !strings.Contains(m.View(), "m: markdown") {
+		t.Fatal("E should open the export picker")
+	}
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")}))
+	if m.ExportMode {
+		t.Error("choosing a format should close the picker")
+	}
+
+	path := filepath.Join(m.Config.DataDir, "table.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("table should be exported: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "| Shortcut |") {
+		t.Errorf("unexpected export:\n%s", data)
+	}
+	if !strings.Contains(lastToast(m), "Exported the table to "+path) {
+		t.Errorf("toast = %q", lastToast(m))
+	}
+
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")}))
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyEsc}))
+	if m.ExportMode {
+		t.Error("esc should cancel the export")
+	}
+}
+
+func TestCopyRowAndColumn(t *testing.T) {
+	var copied []string
+	saved := ui.ClipboardWriter
+	ui.ClipboardWriter = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	defer func() { ui.ClipboardWriter = saved }()
+
+	m := initialModelWithDefaults()
+	m.Rows = [][]string{
+		{"Shortcut", "vim", "tmux"},
+		{apps.SectionPrefix + "navigation", "", ""},
+		{"gg", "top", ""},
+		{"C-b d", "", "detach"},
+		{apps.SectionPrefix + "panes", "", ""},
+		{"C-b %", "", "split | vertical"},
+	}
+	m.CursorX, m.CursorY = 2, 3
+
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}))
+	if len(copied) != 1 || copied[0] != "| Shortcut | vim | tmux |\n| --- | --- | --- |\n| C-b d |  | detach |\n" {
+		t.Fatalf("copied row = %q", copied)
+	}
+	if !strings.Contains(lastToast(m), "Copied the row of C-b d") {
+		t.Errorf("toast = %q", lastToast(m))
+	}
+
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Y")}))
+	want := "| Shortcut | tmux |\n| --- | --- |\n| **navigation** |  |\n| C-b d | detach |\n| **panes** |  |\n| C-b % | split \\| vertical |\n"
+	if len(copied) != 2 || copied[1] != want {
+		t.Fatalf("copied column = %q, want %q", copied[len(copied)-1], want)
+	}
+	if !strings.Contains(lastToast(m), "Copied the tmux column to the clipboard (2 shortcuts)") {
+		t.Errorf("toast = %q", lastToast(m))
+	}
+
+	m.CursorX = 0
+	m = settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Y")}))
+	if len(copied) != 2 || !strings.Contains(lastToast(m), "Move the cursor to an app column") {
+		t.Errorf("the shortcut column has no app to copy, toast = %q", lastToast(m))
+	}
+}
+
+func TestAccessibility(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Config.Accessibility = config.AccessibilityConfig{Linear: true, ASCIIBorders: true, ReducedMotion: true, HighContrast: true}
+	m.Config.Layout.DetailPane = true
+	m.Renderer = ui.NewConfiguredRenderer(m.Config)
+	if name := m.Renderer.GetTheme().Name; name != "high-contrast" {
+		t.Errorf("high_contrast should pick the high-contrast theme, got %s", name)
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "> "+m.Rows[1][0]+" — ") {
+		t.Errorf("the table should be listed line by line with the cursor marked:\n%s", view)
+	}
+	if strings.ContainsAny(view, "│─┼╭╮╰╯▸") {
+		t.Errorf("box drawing characters should be replaced:\n%s", view)
+	}
+
+	// tasks show a still marker and no spinner ticks
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = newModel.(ui.Model)
+	if !strings.Contains(m.View(), "… loading repositories") {
+		t.Errorf("the status bar should show a still marker:\n%s", m.View())
+	}
+	if _, batched := cmd().(tea.BatchMsg); batched {
+		t.Error("no spinner should be started with reduced motion")
+	}
+}
+
+func TestSavedViews(t *testing.T) {
+	m := initialModelWithDefaults()
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	m.ConfigLoader = config.NewLoader(configPath)
+	m.Config.Views = map[string]config.View{
+		"vim": {Apps: []string{"vim", "emacs"}, Sort: config.SortKeys},
+	}
+
+	send := func(m ui.Model, keys string) ui.Model {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(keys)}
+		switch keys {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		}
+		return settle(m.Update(msg))
+	}
+
+	m = send(m, "V")
+	if !m.SavedViewMode || !strings.Contains(m.View(), "vim, emacs") {
+		t.Fatalf("V should list the saved views:\n%s", m.View())
+	}
+	m = send(m, "down")
+	m = send(m, "enter")
+	if m.SavedViewMode || len(m.FilteredApps) != 1 || m.FilteredApps[0] != "vim" || m.TableSort != config.SortKeys {
+		t.Fatalf("enter should open the view with the apps shown, got %v sorted by %q", m.FilteredApps, m.TableSort)
+	}
+	if len(m.Rows[0]) != 2 {
+		t.Errorf("the table should only show vim, got %v", m.Rows[0])
+	}
+	for y := 2; y < len(m.Rows); y++ {
+		if strings.ToLower(m.Rows[y-1][0]) > strings.ToLower(m.Rows[y][0]) {
+			t.Fatalf("rows should be sorted by keys, got %q before %q", m.Rows[y-1][0], m.Rows[y][0])
+		}
+	}
+	if !strings.Contains(m.View(), "view: vim") {
+		t.Error("the status bar should name the view")
+	}
+
+	// the current table is saved under a typed name and picked for startup
+	m = send(m, "t")
+	m = send(m, "enter")
+	m = send(m, "V")
+	m = send(m, "a")
+	m = send(m, "motions")
+	m = send(m, "enter")
+	saved, ok := m.Config.Views["motions"]
+	if !ok || len(saved.Apps) != 1 || saved.Sort != config.SortKeys {
+		t.Fatalf("a should save the table as a view, got %+v", m.Config.Views)
+	}
+	m = send(m, "*")
+	if m.Config.View != "motions" {
+		t.Errorf("* should set the startup view, got %q", m.Config.View)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil || !strings.Contains(string(data), "view: motions") {
+		t.Errorf("the views should be saved to the config, got %s (%v)", data, err)
+	}
+
+	// the whole table is listed first
+	if !m.SavedViewMode {
+		t.Fatal("the picker should stay open after picking the startup view")
+	}
+	for m.SavedViewCursor > 0 {
+		m = settle(m.Update(tea.KeyMsg{Type: tea.KeyUp}))
+	}
+	m = send(m, "enter")
+	if len(m.FilteredApps) != 0 || m.TableSort != "" || len(m.Rows) != len(m.AllRows) {
+		t.Errorf("the whole table should be shown, got apps %v", m.FilteredApps)
+	}
+}
+
+func TestViewFlag(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := config.DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.View = "zsh"
+	cfg.Views = map[string]config.View{
+		"zsh":   {Apps: []string{"zsh"}},
+		"vim-d": {Apps: []string{"vim"}, Search: "d", Sort: config.SortDescription},
+	}
+	if err := config.NewLoader(configPath).Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModel(cliOptions{configFile: configPath})
+	if len(m.FilteredApps) != 1 || m.FilteredApps[0] != "zsh" {
+		t.Errorf("the view of the config should open at startup, got %v", m.FilteredApps)
+	}
+
+	m = initialModel(cliOptions{configFile: configPath, view: "vim-d"})
+	if len(m.FilteredApps) != 1 || m.FilteredApps[0] != "vim" || m.LastSearch != "d" {
+		t.Errorf("--view should open the named view, got %v %q", m.FilteredApps, m.LastSearch)
+	}
+	for y := 2; y < len(m.Rows); y++ {
+		if strings.ToLower(m.Rows[y-1][1]) > strings.ToLower(m.Rows[y][1]) {
+			t.Fatalf("rows should be sorted by description, got %q before %q", m.Rows[y-1][1], m.Rows[y][1])
+		}
+	}
+}
+
+func TestStartupFilterFlags(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := config.DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.Views = map[string]config.View{"zsh": {Apps: []string{"zsh"}, Search: "history"}}
+	if err := config.NewLoader(configPath).Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModel(cliOptions{configFile: configPath, apps: "vim, nano", search: "move"})
+	if len(m.FilteredApps) != 1 || m.FilteredApps[0] != "vim" || m.LastSearch != "move" {
+		t.Fatalf("--app and --search should filter the table, got %v %q", m.FilteredApps, m.LastSearch)
+	}
+	if len(m.Rows) < 2 || len(m.Rows[0]) != 2 {
+		t.Fatalf("the table should list the matching vim shortcuts, got %v", m.Rows)
+	}
+	for _, row := range m.Rows[1:] {
+		if !containsIgnoreCase(strings.Join(row, " "), "move") {
+			t.Errorf("row %v should match the search", row)
+		}
+	}
+
+	// each flag replaces its part of the view and keeps the other
+	m = initialModel(cliOptions{configFile: configPath, view: "zsh", search: "clear"})
+	if len(m.FilteredApps) != 1 || m.FilteredApps[0] != "zsh" || m.LastSearch != "clear" {
+		t.Errorf("--search should replace the search of the view, got %v %q", m.FilteredApps, m.LastSearch)
+	}
+}
+
+func TestKeybinds(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Config.Keybinds = map[string]string{"quit": "x", "down": "n", "notes.new": "a"}
+
+	update := func(m ui.Model, msg tea.KeyMsg) (ui.Model, tea.Cmd) {
+		newModel, cmd := m.Update(msg)
+		return newModel.(ui.Model), cmd
+	}
+	runes := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	// keys bound away from an action no longer trigger it
+	if _, cmd := update(m, runes("q")); cmd != nil {
+		t.Error("q should no longer quit")
+	}
+	if _, cmd := update(m, runes("x")); cmd == nil {
+		t.Error("x should quit")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("x should quit")
+	}
+	moved, _ := update(m, runes("n"))
+	if moved.CursorY != m.CursorY+1 || moved.ViewMode != ui.ViewMain {
+		t.Errorf("n should move down instead of opening the notes, got row %d", moved.CursorY)
+	}
+	moved, _ = update(m, tea.KeyMsg{Type: tea.KeyDown})
+	if moved.CursorY != m.CursorY+1 {
+		t.Error("the arrow keys should keep working")
+	}
+
+	// tab moves between apps
+	moved, _ = update(m, tea.KeyMsg{Type: tea.KeyTab})
+	moved, _ = update(moved, tea.KeyMsg{Type: tea.KeyTab})
+	if app := moved.SelectedApp(); app != m.AllApps[1] {
+		t.Errorf("tab twice should move to the second app, got %q", app)
+	}
+	moved, _ = update(moved, tea.KeyMsg{Type: tea.KeyShiftTab})
+	if app := moved.SelectedApp(); app != m.AllApps[0] {
+		t.Errorf("shift+tab should move back to the first app, got %q", app)
+	}
+
+	// bindings of other views are named after the view
+	m.ViewMode = ui.ViewNotes
+	notes, _ := update(m, runes("a"))
+	if !notes.TemplateMode {
+		t.Error("a should start a new note in the notes view")
+	}
+	m.ViewMode = ui.ViewMain
+}
+
+func TestHelpFromKeymap(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Config.Keybinds = map[string]string{"quit": "x", "notes.new": "a"}
+
+	send := func(m ui.Model, msg tea.KeyMsg) ui.Model {
+		newModel, _ := m.Update(msg)
+		return newModel.(ui.Model)
+	}
+	runes := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	m = send(m, runes("?"))
+	view := m.View()
+	for _, want := range []string{"NAVIGATION", "Next app", "next_app"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("help should list %q:\n%s", want, view)
+		}
+	}
+
+	// the help is searched by group, key, description or name
+	m = send(m, runes("/"))
+	m = send(m, runes("quit"))
+	if view = m.View(); !regexp.MustCompile(`x / Ctrl\+C +Quit`).MatchString(view) {
+		t.Errorf("help should show the rebound quit key:\n%s", view)
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyCtrlU})
+	m = send(m, runes("new note"))
+	m = send(m, tea.KeyMsg{Type: tea.KeyEnter})
+	view = m.View()
+	if !strings.Contains(view, "NOTES MANAGER") || !regexp.MustCompile(`a +New note from a template +notes\.new`).MatchString(view) {
+		t.Errorf("the search should list the rebound new note key:\n%s", view)
+	}
+	if strings.Contains(view, "NAVIGATION") {
+		t.Errorf("groups without matches should be hidden:\n%s", view)
+	}
+
+	// esc clears the search, then closes the help
+	m = send(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.ViewMode != ui.ViewHelp || m.HelpQuery != "" {
+		t.Fatal("esc should clear the search first")
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.ViewMode != ui.ViewMain || m.HelpMode {
+		t.Error("esc should close the help")
+	}
+}
+
+func TestHintBar(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Config.Keybinds = map[string]string{"quit": "x", "online.download": "g"}
+
+	// the hint bar is the last line of hints, above the status bar
+	lastLine := func(view string) string {
+		lines := strings.Split(view, "\n")
+		for i := len(lines) - 1; i >= 0; i-- {
+			if strings.Contains(lines[i], " • ") {
+				return lines[i]
+			}
+		}
+		return ""
+	}
+
+	// the hints follow the keybinds
+	hints := lastLine(m.View())
+	if !strings.Contains(hints, "x: quit") || strings.Contains(hints, "q: quit") {
+		t.Errorf("the hints should show the rebound quit key, got %q", hints)
+	}
+	if strings.Contains(hints, "download") {
+		t.Errorf("the table should not hint at keys of other views, got %q", hints)
+	}
+
+	// and the state of the view
+	m.ViewMode = ui.ViewOnline
+	if hints = lastLine(m.View()); !strings.Contains(hints, "Enter: browse") || strings.Contains(hints, "download") {
+		t.Errorf("the repository list should hint at browsing only, got %q", hints)
+	}
+	m.CheatSheets = []online.CheatSheet{{Name: "vim"}}
+	m.SheetFocus = true
+	if hints = lastLine(m.View()); !strings.Contains(hints, "g: download") || !strings.Contains(hints, "v: preview") {
+		t.Errorf("the sheet list should hint at downloading and previewing, got %q", hints)
+	}
+
+	// a narrow terminal keeps the way out
+	m.ViewMode = ui.ViewMain
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 40, Height: 30})
+	m = newModel.(ui.Model)
+	hints = lastLine(m.View())
+	if runewidth.StringWidth(hints) > 40 || !strings.Contains(hints, "…") ||
+		!strings.Contains(hints, "?: help") || !strings.Contains(hints, "x: quit") {
+		t.Errorf("the hints should be cut to the width keeping help and quit, got %q", hints)
+	}
+}
+
+func TestTour(t *testing.T) {
+	m := initialModelWithDefaults()
+	m.Config.Keybinds = map[string]string{"search": "s", "sync": "Z"}
+	m.ConfigLoader = config.NewLoader(filepath.Join(t.TempDir(), "config.yaml"))
+
+	send := func(m ui.Model, msg tea.KeyMsg) ui.Model {
+		newModel, _ := m.Update(msg)
+		return newModel.(ui.Model)
+	}
+
+	// the first run goes from the wizard into the tour
+	m = send(m.StartSetup(), tea.KeyMsg{Type: tea.KeyEsc})
+	if m.ViewMode != ui.ViewMain || !m.TourMode || m.TourStep != 0 {
+		t.Fatalf("skipping the wizard should start the tour, got view %v", m.ViewMode)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Tour 1/7: Navigation") || !strings.Contains(view, "TOUR") {
+		t.Errorf("the tour should open at navigation:\n%s", view)
+	}
+
+	// the steps name the keys in effect
+	m = send(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if view = m.View(); !strings.Contains(view, "Search") || !strings.Contains(view, "Press s to search") {
+		t.Errorf("the search step should name the rebound key:\n%s", view)
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyLeft})
+	if m.TourStep != 0 {
+		t.Errorf("left should go back a step, got step %d", m.TourStep)
+	}
+	for range 5 {
+		m = send(m, tea.KeyMsg{Type: tea.KeyRight})
+	}
+	if view = m.View(); !strings.Contains(view, "Sync") || !strings.Contains(view, "Press Z for the sync status") {
+		t.Errorf("the sync step should name the rebound key:\n%s", view)
+	}
+
+	// the table keys wait until the tour ends
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	if m.ViewMode != ui.ViewMain || !m.TourMode {
+		t.Error("the tour should keep the keys of the table")
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.TourMode {
+		t.Fatal("esc should end the tour")
+	}
+
+	// the help takes the tour again
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	if view = m.View(); !strings.Contains(view, "t: take the tour") {
+		t.Errorf("the help should hint at the tour:\n%s", view)
+	}
+	m = send(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if m.ViewMode != ui.ViewMain || !m.TourMode || m.TourStep != 0 {
+		t.Error("t should take the tour again from the help")
+	}
+	for range 7 {
+		m = send(m, tea.KeyMsg{Type: tea.KeyEnter})
+	}
+	if m.TourMode {
+		t.Error("enter on the last step should end the tour")
+	}
+}
+
+// revisedOnlineClient serves the mock sheets at a version, and a fixed app
+// for downloads
+type revisedOnlineClient struct {
+	*online.MockClient
+	version string
+}
+
+func (c *revisedOnlineClient) SearchCheatSheets(opts online.SearchOptions) ([]online.CheatSheet, error) {
+	sheets, err := c.MockClient.SearchCheatSheets(opts)
+	for i := range sheets {
+		sheets[i].Version = c.version
+	}
+	return sheets, err
+}
+
+func (c *revisedOnlineClient) DownloadCheatSheet(id string) (*apps.App, error) {
+	return &apps.App{
+		Name:        "git",
+		Description: "Git",
+		Shortcuts:   []apps.Shortcut{{Keys: "git st", Description: "status " + c.version}},
+	}, nil
+}
+
+func TestOnlineSubscriptions(t *testing.T) {
+	m := initialModelWithDefaults()
+	dataDir := t.TempDir()
+	client := &revisedOnlineClient{MockClient: online.NewMockClient(), version: "v1"}
+	m.OnlineClient = client
+	m.Registry = apps.NewRegistry(dataDir)
+	m.Store = storage.NewFileStorage(dataDir)
+	m.ConfigLoader = config.NewLoader(filepath.Join(dataDir, "config.yaml"))
+	m.ViewMode = ui.ViewOnline
+	m.LoadRepositories()
+	repo := m.ReposList[0]
+
+	press := func(m ui.Model, key string) ui.Model {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if key == "enter" {
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		return settle(m.Update(msg))
+	}
+
+	// subscribing is saved to the config
+	m = press(m, "S")
+	if !slices.Contains(m.Config.Online.Subscriptions, repo.URL) || !strings.Contains(m.View(), "● subscribed") {
+		t.Fatalf("S should subscribe to the repository, got %v", m.Config.Online.Subscriptions)
+	}
+	saved, err := config.NewLoader(filepath.Join(dataDir, "config.yaml")).Load()
+	if err != nil || !slices.Contains(saved.Online.Subscriptions, repo.URL) {
+		t.Errorf("the subscription should be saved, got %v (%v)", saved, err)
+	}
+	if m.Init() == nil {
+		t.Error("Init should start checking the subscriptions")
+	}
+
+	// nothing downloaded, nothing to update
+	m = press(m, "c")
+	if toast := lastToast(m); !strings.Contains(toast, "up to date") {
+		t.Errorf("a check without downloads should find nothing, got %q", toast)
+	}
+
+	m = press(m, "enter")
+	if m = press(m, "d"); !strings.Contains(lastToast(m), "Installed git") {
+		t.Fatalf("d should install the sheet, got %q", lastToast(m))
+	}
+	m = press(m, "c")
+	if len(m.SheetUpdates) != 0 {
+		t.Errorf("a sheet just downloaded should be up to date, got %+v", m.SheetUpdates)
+	}
+
+	// a new revision online is badged and downloaded again by one key
+	client.version = "v2"
+	m = press(m, "c")
+	if len(m.SheetUpdates) != 1 || !strings.Contains(lastToast(m), "changed online") {
+		t.Fatalf("the changed sheet should be found, got %+v (%q)", m.SheetUpdates, lastToast(m))
+	}
+	if view := m.View(); !strings.Contains(view, "⬆1 updates") || !strings.Contains(view, "⬆ ") {
+		t.Errorf("the online view should badge the update:\n%s", view)
+	}
+	m = press(m, "U")
+	if len(m.SheetUpdates) != 0 || !strings.Contains(lastToast(m), "Updated git") {
+		t.Errorf("U should download the changed sheet again, got %+v (%q)", m.SheetUpdates, lastToast(m))
+	}
+	if app, ok := m.Registry.Get("git"); !ok || app.Shortcuts[0].Description != "status v2" {
+		t.Errorf("the app should hold the new revision, got %+v", app)
+	}
+	if m = press(m, "c"); len(m.SheetUpdates) != 0 {
+		t.Errorf("the new revision should be recorded, got %+v", m.SheetUpdates)
+	}
+
+	// unsubscribing stops the checks
+	m.SheetFocus = false
+	m = press(m, "S")
+	if len(m.Config.Online.Subscriptions) != 0 {
+		t.Errorf("S again should unsubscribe, got %v", m.Config.Online.Subscriptions)
+	}
+}
+
+// signedOnlineClient serves the mock sheets as YAML files with the
+// signatures of signatures, where they have one
+type signedOnlineClient struct {
+	*online.MockClient
+	signatures map[string][]byte
+}
+
+func (c *signedOnlineClient) DownloadSigned(id string) (*online.SignedSheet, error) {
+	app, err := c.DownloadCheatSheet(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(app)
+	if err != nil {
+		return nil, err
+	}
+	return &online.SignedSheet{App: app, Data: data, Signature: c.signatures[id]}, nil
+}
+
+func TestSignedDownloads(t *testing.T) {
+	public, secret, err := signature.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &signedOnlineClient{MockClient: online.NewMockClient(), signatures: map[string][]byte{}}
+	sheets, _ := client.SearchCheatSheets(online.SearchOptions{})
+	for _, sheet := range sheets {
+		data, _ := yaml.Marshal(sheet.App)
+		client.signatures[sheet.ID] = secret.Sign(data, "")
+	}
+
+	m := initialModelWithDefaults()
+	dataDir := t.TempDir()
+	m.OnlineClient = client
+	m.Registry = apps.NewRegistry(dataDir)
+	m.ConfigLoader = config.NewLoader(filepath.Join(dataDir, "config.yaml"))
+	m.Config.Signatures.TrustedKeys = []string{public.String()}
+	m.ViewMode = ui.ViewOnline
+	m.LoadRepositories()
+	m.LoadCheatSheets(m.ReposList[0].URL)
+	m.SheetFocus = true
+	sheet := m.CheatSheets[m.SheetCursor]
+
+	press := func(m ui.Model, key string) ui.Model {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if key == "esc" {
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		return settle(m.Update(msg))
+	}
+
+	// signed by a trusted key
+	if m = press(m, "d"); !strings.Contains(lastToast(m), "Installed "+sheet.App.Name) {
+		t.Fatalf("a signed sheet should install, got %q", lastToast(m))
+	}
+
+	// altered since it was signed
+	client.signatures[sheet.ID] = secret.Sign([]byte("something else"), "")
+	m = press(m, "d")
+	if toast := lastToast(m); !strings.Contains(toast, "invalid signature") || len(m.UnverifiedSheets) != 0 {
+		t.Errorf("an altered sheet should be refused, got %q", toast)
+	}
+
+	// unsigned sheets wait for the user
+	delete(client.signatures, sheet.ID)
+	m = press(m, "d")
+	view := m.View()
+	for _, want := range []string{"could not be verified: content is not signed", "Install it anyway?", "y: use anyway", "Esc: refuse"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("the online view should contain %q:\n%s", want, view)
+		}
+	}
+	if m = press(m, "esc"); len(m.UnverifiedSheets) != 0 || !strings.Contains(lastToast(m), "Did not install") {
+		t.Errorf("Esc should refuse the sheet, got %q", lastToast(m))
+	}
+
+	m = press(m, "d")
+	if m = press(m, "y"); !strings.Contains(lastToast(m), "Updated "+sheet.App.Name) {
+		t.Errorf("y should install the unsigned sheet, got %q", lastToast(m))
+	}
+}
+
+func TestPluginApproval(t *testing.T) {
+	public, _, err := signature.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fmt.yaml"), []byte("name: fmt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	m := initialModelWithDefaults()
+	m.ConfigLoader = config.NewLoader(configPath)
+	m.Config.Signatures.TrustedKeys = []string{public.String()}
+	verifier, _ := signature.NewVerifier(m.Config.Signatures.TrustedKeys)
+	m.PluginLoader = plugins.NewLoader(dir)
+	m.PluginLoader.SetVerifier(verifier, nil)
+	m.PluginLoader.LoadAll()
+	m.ViewMode = ui.ViewPlugins
+	m.LoadPlugins()
+
+	press := func(m ui.Model, key string) ui.Model {
+		return settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}))
+	}
+
+	if view := m.View(); !strings.Contains(view, "(unverified)") || !strings.Contains(view, "a: approve") {
+		t.Errorf("the unsigned plugin should be listed as unverified:\n%s", view)
+	}
+	if m = press(m, "e"); !strings.Contains(m.StatusMessage, "could not be verified") {
+		t.Errorf("an unverified plugin should not be enabled, got %q", m.StatusMessage)
+	}
+
+	m = press(m, "a")
+	if view := m.View(); !strings.Contains(view, "Run it anyway?") || !strings.Contains(view, "y: use anyway") {
+		t.Fatalf("a should ask to run the plugin:\n%s", view)
+	}
+	m = press(m, "n")
+	if m.PluginApproveMode || len(m.Config.Plugins.Approved) != 0 {
+		t.Fatal("n should leave the plugin unapproved")
+	}
+
+	m = press(m, "a")
+	m = press(m, "y")
+	if len(m.Config.Plugins.Approved) != 1 || !strings.Contains(lastToast(m), "Approved fmt") {
+		t.Fatalf("y should approve the plugin, got %v (%q)", m.Config.Plugins.Approved, lastToast(m))
+	}
+	if _, err := m.PluginLoader.GetPlugin("fmt"); err != nil {
+		t.Errorf("the approved plugin should run after the reload, got %v", err)
+	}
+	saved, err := config.NewLoader(configPath).Load()
+	if err != nil || len(saved.Plugins.Approved) != 1 {
+		t.Errorf("the approval should be saved, got %+v (%v)", saved, err)
+	}
+}
+
+func TestOnlineBlocklistAndReports(t *testing.T) {
+	mock := online.NewMockClient()
+	m := initialModelWithDefaults()
+	dataDir := t.TempDir()
+	m.OnlineClient = online.NewBlockingClient(mock, online.Blocklist{})
+	m.ConfigLoader = config.NewLoader(filepath.Join(dataDir, "config.yaml"))
+	m.ViewMode = ui.ViewOnline
+	m.LoadRepositories()
+	m.LoadCheatSheets(m.ReposList[0].URL)
+	m.SheetFocus = true
+	sheet := m.CheatSheets[m.SheetCursor]
+
+	press := func(m ui.Model, key string) ui.Model {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if key == "esc" {
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		return settle(m.Update(msg))
+	}
+
+	m = press(m, "R")
+	view := m.View()
+	for _, want := range []string{"Report " + sheet.Name + ": 1 spam", "1-4: reason", "Esc: cancel"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("the report prompt should contain %q:\n%s", want, view)
+		}
+	}
+	if m = press(m, "esc"); m.ReportMode || len(mock.Reports()) != 0 {
+		t.Error("Esc should cancel the report")
+	}
+
+	m = press(m, "R")
+	m = press(m, "4")
+	if reports := mock.Reports(); len(reports) != 1 || reports[0] != (online.Report{SheetID: sheet.ID, Reason: "malicious"}) {
+		t.Errorf("Reports() = %+v, want %s reported as malicious", reports, sheet.ID)
+	}
+	if toast := lastToast(m); toast != "Reported "+sheet.Name+" as malicious" {
+		t.Errorf("toast = %q", toast)
+	}
+
+	// blocking the author of the sheet hides their sheets and repositories
+	m = press(m, "b")
+	if len(m.CheatSheets) != 0 || m.SheetFocus || len(m.ReposList) != 1 || lastToast(m) != "Blocked cheat-go" {
+		t.Errorf("blocking cheat-go left sheets %+v, repositories %+v, toast %q", m.CheatSheets, m.ReposList, lastToast(m))
+	}
+
+	// then the repository under the cursor, which the client leaves out too
+	m = press(m, "b")
+	if len(m.ReposList) != 0 {
+		t.Errorf("the blocked repository should be hidden, got %+v", m.ReposList)
+	}
+	if m.LoadRepositories(); len(m.ReposList) != 0 {
+		t.Errorf("the client should leave out blocked repositories, got %+v", m.ReposList)
+	}
+
+	saved, err := config.NewLoader(filepath.Join(dataDir, "config.yaml")).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocklist := saved.Online.Blocklist
+	if !slices.Equal(blocklist.Authors, []string{"cheat-go"}) || !slices.Equal(blocklist.Repositories, []string{"https://github.com/awesome/cheatsheets"}) {
+		t.Errorf("saved blocklist = %+v, want the author and the repository", blocklist)
+	}
+}
+
+// flakyOnlineClient fails the first download of the sheets in failures,
+// and holds every download until gate is closed when it is set
+type flakyOnlineClient struct {
+	*online.MockClient
+	failures map[string]*atomic.Bool
+	gate     chan struct{}
+}
+
+func (c *flakyOnlineClient) DownloadCheatSheet(id string) (*apps.App, error) {
+	if c.gate != nil {
+		<-c.gate
+	}
+	if failure, ok := c.failures[id]; ok && failure.CompareAndSwap(true, false) {
+		return nil, fmt.Errorf("connection reset")
+	}
+	return c.MockClient.DownloadCheatSheet(id)
+}
+
+func TestCompareView(t *testing.T) {
+	m := initialModelWithDefaults()
+	dataDir := t.TempDir()
+	m.OnlineClient = online.NewMockClient()
+	m.Registry = apps.NewRegistry(dataDir)
+	m.ViewMode = ui.ViewOnline
+	m.LoadRepositories()
+	m.LoadCheatSheets(m.ReposList[0].URL)
+	m.SheetFocus = true
+
+	press := func(m ui.Model, key string) ui.Model {
+		return settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}))
+	}
+
+	if m = press(m, "C"); m.ViewMode != ui.ViewOnline || !strings.Contains(lastToast(m), "Vim Advanced is not installed") {
+		t.Errorf("comparing a sheet without local app should say so, got %q", lastToast(m))
+	}
+
+	local := &apps.App{Name: "vim-advanced", Description: "My vim", Shortcuts: []apps.Shortcut{
+		{Keys: "ci\"", Description: "Change inside quotes"},
+		{Keys: "qa", Description: "Record a macro"},
+		{Keys: "dd", Description: "Delete the line"},
+	}}
+	if err := m.Registry.SaveApp(local); err != nil {
+		t.Fatal(err)
+	}
+	m = press(m, "C")
+	if m.ViewMode != ui.ViewCompare {
+		t.Fatalf("C should open the compare view, got %v (%q)", m.ViewMode, lastToast(m))
+	}
+	view := m.View()
+	for _, want := range []string{"Vim Advanced compared with vim-advanced", "Coverage: 2 of 3 shortcuts (67%)",
+		"Missing from vim-advanced (1)", "@a", "Only in vim-advanced (1)", "dd", "m: merge missing"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("the compare view should contain %q:\n%s", want, view)
+		}
+	}
+
+	m = press(m, "m")
+	if view := m.View(); !strings.Contains(view, "Coverage: 3 of 3 shortcuts (100%)") || strings.Contains(view, "m: merge missing") {
+		t.Errorf("m should merge the missing shortcut:\n%s", view)
+	}
+	data, err := os.ReadFile(filepath.Join(dataDir, "vim-advanced.local.yaml"))
+	if err != nil || !strings.Contains(string(data), "Replay macro a") {
+		t.Errorf("the missing shortcut should be added to the overlay, got %q (%v)", data, err)
+	}
+	if stored, _ := os.ReadFile(filepath.Join(dataDir, "vim-advanced.yaml")); strings.Contains(string(stored), "@a") {
+		t.Error("the app definition itself should be left alone")
+	}
+
+	if m = press(m, "q"); m.ViewMode != ui.ViewOnline {
+		t.Errorf("q should return to the online browser, got %v", m.ViewMode)
+	}
+
+	// with trusted keys, the unsigned mock sheets are not compared
+	public, _, _ := signature.GenerateKey()
+	m.Config.Signatures.TrustedKeys = []string{public.String()}
+	m.Comparison = nil
+	if m = press(m, "C"); m.ViewMode != ui.ViewOnline || m.Comparison != nil ||
+		!strings.Contains(lastToast(m), "Error comparing Vim Advanced: content is not signed") {
+		t.Errorf("an unverified sheet should not be compared, got %v (%q)", m.ViewMode, lastToast(m))
+	}
+}
+
+func TestDownloadsView(t *testing.T) {
+	failure := &atomic.Bool{}
+	failure.Store(true)
+	client := &flakyOnlineClient{MockClient: online.NewMockClient(), failures: map[string]*atomic.Bool{"git-workflow": failure}}
+
+	m := initialModelWithDefaults()
+	dataDir := t.TempDir()
+	m.OnlineClient = client
+	m.Registry = apps.NewRegistry(dataDir)
+	m.ConfigLoader = config.NewLoader(filepath.Join(dataDir, "config.yaml"))
+	m.ViewMode = ui.ViewOnline
+	m.LoadRepositories()
+
+	press := func(m ui.Model, key string) ui.Model {
+		return settle(m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}))
+	}
+
+	// every sheet of the repository under the cursor
+	m = press(m, "a")
+	if len(m.Downloads) != 2 || len(m.Pending()) != 0 {
+		t.Fatalf("downloads = %d, pending %v; want both sheets of the repository downloaded", len(m.Downloads), m.Pending())
+	}
+	if !m.IsAppConfigured("vim-advanced") || m.IsAppConfigured("git-workflow") {
+		t.Errorf("only the sheet that downloaded should be installed, apps %v", m.Config.Apps)
+	}
+
+	m = press(m, "D")
+	if m.ViewMode != ui.ViewDownloads {
+		t.Fatalf("D should open the downloads view, got %v", m.ViewMode)
+	}
+	view := m.View()
+	for _, want := range []string{"Downloads", "1/2 done, 1 failed", "✓ done", "✗ connection reset", "R: retry all"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("the downloads view should contain %q:\n%s", want, view)
+		}
+	}
+
+	if m = press(m, "R"); !m.IsAppConfigured("git-workflow") || !strings.Contains(m.View(), "2/2 done, 0 failed") {
+		t.Errorf("R should retry the failed download:\n%s", m.View())
+	}
+	if m = press(m, "c"); len(m.Downloads) != 0 {
+		t.Errorf("c should clear the finished downloads, got %d", len(m.Downloads))
+	}
+
+	// a download waiting for the network is cancelled
+	client.gate = make(chan struct{})
+	defer close(client.gate)
+	m = press(m, "q")
+	m.LoadCheatSheets(m.ReposList[0].URL)
+	m.SheetFocus = true
+	// keys are sent without settling, which would wait for the held download
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	m = model.(ui.Model)
+	if view := m.View(); !strings.Contains(view, "· queued") || !strings.Contains(view, "downloading "+m.CheatSheets[0].Name) {
+		t.Errorf("the download should be listed and pending:\n%s", view)
+	}
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if m = settle(model, cmd); !strings.Contains(m.View(), "– cancelled") || len(m.Pending()) != 0 {
+		t.Errorf("x should cancel the download:\n%s", m.View())
+	}
+}
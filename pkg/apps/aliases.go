@@ -0,0 +1,103 @@
+package apps
+
+import (
+	"slices"
+	"strings"
+)
+
+// SetAliases makes each alias name the app it points to, so that an app
+// listed under another name, such as nvim for vim, shows the shortcuts of
+// its target. Registered apps take precedence over aliases.
+func (r *Registry) SetAliases(aliases map[string]string) {
+	r.aliases = aliases
+}
+
+// SetMergedApps defines virtual apps combining the shortcuts of their
+// members, in order, such as shells for zsh and bash. Members may be
+// aliases. Registered apps take precedence over merged apps.
+func (r *Registry) SetMergedApps(merged map[string][]string) {
+	r.merged = merged
+}
+
+// members returns the apps name stands for: the target of an alias, the
+// members of a merged app, or name itself
+func (r *Registry) members(name string) []string {
+	if target, ok := r.aliases[name]; ok {
+		if members, ok := r.merged[target]; ok {
+			return members
+		}
+		return []string{target}
+	}
+	if members, ok := r.merged[name]; ok {
+		return members
+	}
+	return []string{name}
+}
+
+// virtualApp returns the app an alias or merged app not shadowed by a
+// registered app stands for. An alias returns its target as registered; a
+// merged app is built anew from its members on every call.
+func (r *Registry) virtualApp(name string) (*App, bool) {
+	if target, ok := r.aliases[name]; ok && target != name {
+		return r.Get(target)
+	}
+	members, ok := r.merged[name]
+	if !ok {
+		return nil, false
+	}
+
+	var apps []*App
+	for _, member := range members {
+		if app, ok := r.Get(member); ok {
+			apps = append(apps, app)
+		}
+	}
+	if len(apps) == 0 {
+		return nil, false
+	}
+	return mergeApps(name, apps), true
+}
+
+// mergeApps combines the shortcuts of apps into one app. Shortcuts with the
+// same keys and platform become one, joining their differing descriptions
+// with " / " and their tags, and keeping the category of the first.
+func mergeApps(name string, apps []*App) *App {
+	names := make([]string, len(apps))
+	merged := &App{Name: name, Metadata: map[string]string{"source": "merged"}}
+	categories := make(map[string]bool)
+	at := make(map[string]int)
+
+	for i, app := range apps {
+		names[i] = app.Name
+		for _, category := range app.Categories {
+			if !categories[category] {
+				categories[category] = true
+				merged.Categories = append(merged.Categories, category)
+			}
+		}
+
+		for _, shortcut := range app.Shortcuts {
+			key := shortcut.Keys + "\x00" + shortcut.Platform
+			j, seen := at[key]
+			if !seen {
+				at[key] = len(merged.Shortcuts)
+				shortcut.Tags = append([]string(nil), shortcut.Tags...)
+				merged.Shortcuts = append(merged.Shortcuts, shortcut)
+				continue
+			}
+
+			existing := &merged.Shortcuts[j]
+			if !slices.Contains(strings.Split(existing.Description, " / "), shortcut.Description) {
+				existing.Description += " / " + shortcut.Description
+			}
+			for _, tag := range shortcut.Tags {
+				if !slices.Contains(existing.Tags, tag) {
+					existing.Tags = append(existing.Tags, tag)
+				}
+			}
+		}
+	}
+
+	merged.Description = strings.Join(names, " + ")
+	return merged
+}
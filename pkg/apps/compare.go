@@ -0,0 +1,63 @@
+package apps
+
+import "strings"
+
+// Comparison tells how the shortcuts of a local app cover those of another
+// definition of the same app, such as its community sheet
+type Comparison struct {
+	// Missing are the shortcuts of the other definition the local app lacks
+	Missing []Shortcut
+	// Extra are the shortcuts only the local app has
+	Extra []Shortcut
+	// Shared counts the shortcuts both have
+	Shared int
+}
+
+// Compare compares the shortcuts of local with those of other. Shortcuts
+// match by keys and platform, whatever the notation and case of the keys.
+func Compare(local, other *App) Comparison {
+	var comparison Comparison
+	localKeys := map[string]bool{}
+	for _, shortcut := range local.Shortcuts {
+		localKeys[shortcutKey(shortcut)] = true
+	}
+	otherKeys := map[string]bool{}
+	for _, shortcut := range other.Shortcuts {
+		key := shortcutKey(shortcut)
+		if otherKeys[key] {
+			continue
+		}
+		otherKeys[key] = true
+		if localKeys[key] {
+			comparison.Shared++
+		} else {
+			comparison.Missing = append(comparison.Missing, shortcut)
+		}
+	}
+	for _, shortcut := range local.Shortcuts {
+		key := shortcutKey(shortcut)
+		if !otherKeys[key] {
+			comparison.Extra = append(comparison.Extra, shortcut)
+			// a duplicate is only listed once
+			otherKeys[key] = true
+		}
+	}
+	return comparison
+}
+
+// Coverage is the share of the shortcuts of the other definition the local
+// app has, from 0 to 1
+func (c Comparison) Coverage() float64 {
+	total := c.Shared + len(c.Missing)
+	if total == 0 {
+		return 1
+	}
+	return float64(c.Shared) / float64(total)
+}
+
+// shortcutKey identifies a shortcut by its keys in verbose notation and its
+// platform
+func shortcutKey(shortcut Shortcut) string {
+	keys := FormatKeys(strings.Join(strings.Fields(shortcut.Keys), " "), NotationVerbose)
+	return strings.ToLower(keys) + "\x00" + shortcut.Platform
+}
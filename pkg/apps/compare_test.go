@@ -0,0 +1,37 @@
+package apps
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	local := &App{Name: "vim", Shortcuts: []Shortcut{
+		{Keys: "gg", Description: "top"},
+		{Keys: "Ctrl+S", Description: "save"},
+		{Keys: "zz", Description: "center"},
+		{Keys: "zz", Description: "center again"},
+	}}
+	community := &App{Name: "vim", Shortcuts: []Shortcut{
+		{Keys: "gg", Description: "go to the top"},
+		{Keys: "<C-s>", Description: "save the file"},
+		{Keys: "G", Description: "bottom"},
+		{Keys: "G", Description: "bottom again"},
+		{Keys: "gg", Description: "top", Platform: "macos"},
+	}}
+
+	got := Compare(local, community)
+	if got.Shared != 2 {
+		t.Errorf("Shared = %d, want 2 (gg and the save chord in another notation)", got.Shared)
+	}
+	if len(got.Missing) != 2 || got.Missing[0].Keys != "G" || got.Missing[1].Platform != "macos" {
+		t.Errorf("Missing = %+v, want G once and gg on macos", got.Missing)
+	}
+	if len(got.Extra) != 1 || got.Extra[0].Keys != "zz" {
+		t.Errorf("Extra = %+v, want zz once", got.Extra)
+	}
+	if coverage := got.Coverage(); coverage != 0.5 {
+		t.Errorf("Coverage() = %v, want 0.5", coverage)
+	}
+
+	if coverage := Compare(local, &App{}).Coverage(); coverage != 1 {
+		t.Errorf("Coverage() of an empty sheet = %v, want 1", coverage)
+	}
+}
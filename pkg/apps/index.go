@@ -0,0 +1,216 @@
+package apps
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+// appIndexKey is the document of the session collection describing the
+// stored apps, so they can be registered without decoding them
+const appIndexKey = "app_index"
+
+// AppInfo describes an app without its shortcuts
+type AppInfo struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Categories  []string          `json:"categories,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	// Shortcuts is how many shortcuts the app defines
+	Shortcuts int `json:"shortcuts"`
+}
+
+// Info returns the description of the app
+func (a *App) Info() AppInfo {
+	return AppInfo{
+		Name:        a.Name,
+		Description: a.Description,
+		Categories:  a.Categories,
+		Metadata:    a.Metadata,
+		Version:     a.Version,
+		Shortcuts:   len(a.Shortcuts),
+	}
+}
+
+// indexEntry describes a stored app as it was when its file had checksum
+type indexEntry struct {
+	Checksum string  `json:"checksum"`
+	Info     AppInfo `json:"info"`
+}
+
+// LoadStats tells how the registry came by its apps
+type LoadStats struct {
+	// Apps is how many apps are registered
+	Apps int
+	// Loaded is how many of them have their shortcuts in memory
+	Loaded int
+	// Indexed is how many stored apps were registered from the index
+	Indexed int
+	// Decoded is how many stored apps were read in full
+	Decoded int
+	// Duration is the time spent in LoadApps and LoadAllAppsFromDirectory
+	Duration time.Duration
+}
+
+// LoadStats returns how the apps of the registry were loaded
+func (r *Registry) LoadStats() LoadStats {
+	stats := r.stats
+	stats.Apps = len(r.AppRegistry.apps)
+	stats.Loaded = stats.Apps - len(r.lazy)
+	return stats
+}
+
+// checksum identifies the content of an app file
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readIndex returns the index of the stored apps, reading it from storage
+// the first time. A missing or unreadable index is rebuilt as apps load.
+func (r *Registry) readIndex() map[string]indexEntry {
+	if r.index == nil {
+		r.index = make(map[string]indexEntry)
+		if err := storage.GetJSON(r.store, storage.CollectionSession, appIndexKey, &r.index); err != nil {
+			r.index = make(map[string]indexEntry)
+		}
+	}
+	return r.index
+}
+
+// saveIndex writes the index back to storage when apps were decoded since
+// it was read. Failing to is not an error: the index only speeds up the
+// next start.
+func (r *Registry) saveIndex() {
+	if !r.indexDirty {
+		return
+	}
+	if err := storage.PutJSON(r.store, storage.CollectionSession, appIndexKey, r.index); err == nil {
+		r.indexDirty = false
+	}
+}
+
+// registerApp registers a stored app with its metadata only when the index
+// describes its file as it is, and loads it in full otherwise. Apps that
+// are not stored are loaded as by LoadApp.
+func (r *Registry) registerApp(name string) error {
+	if r.store == nil {
+		return r.LoadApp(name)
+	}
+	data, err := r.store.Get(storage.CollectionApps, name)
+	if err != nil {
+		return r.LoadApp(name)
+	}
+
+	sum := checksum(data)
+	if entry, ok := r.readIndex()[name]; ok && entry.Checksum == sum {
+		r.AppRegistry.Register(&App{
+			Name:        entry.Info.Name,
+			Description: entry.Info.Description,
+			Categories:  entry.Info.Categories,
+			Metadata:    entry.Info.Metadata,
+			Version:     entry.Info.Version,
+		})
+		r.lazy[name] = true
+		r.stats.Indexed++
+		return nil
+	}
+
+	app, err := r.decodeApp(data)
+	if err == nil && app.Name == name {
+		r.stats.Decoded++
+		r.index[name] = indexEntry{Checksum: sum, Info: app.Info()}
+		r.indexDirty = true
+	}
+	return r.registerWithOverlay(name, app, err)
+}
+
+// pruneIndex forgets the index entries of apps no longer stored
+func (r *Registry) pruneIndex(stored []string) {
+	keep := make(map[string]bool, len(stored))
+	for _, name := range stored {
+		keep[name] = true
+	}
+	for name := range r.readIndex() {
+		if !keep[name] {
+			delete(r.index, name)
+			r.indexDirty = true
+		}
+	}
+}
+
+// Get retrieves an app by name, first reading the shortcuts of an app
+// registered from the index. Names not registered resolve as aliases or
+// merged apps.
+func (r *Registry) Get(name string) (*App, bool) {
+	if r.lazy[name] {
+		delete(r.lazy, name)
+		if r.LoadApp(name) == nil {
+			r.stats.Decoded++
+		}
+	}
+	if app, ok := r.AppRegistry.Get(name); ok {
+		return app, true
+	}
+	return r.virtualApp(name)
+}
+
+// GetAll returns all registered apps, with their shortcuts
+func (r *Registry) GetAll() map[string]*App {
+	r.LoadShortcuts()
+	return r.AppRegistry.GetAll()
+}
+
+// Register adds an app to the registry, replacing an app of that name
+// registered from the index
+func (r *Registry) Register(app *App) {
+	delete(r.lazy, app.Name)
+	r.searchIndexes[app.Name] = newSearchIndex(app)
+	r.AppRegistry.Register(app)
+}
+
+// Unregister removes an app from the registry
+func (r *Registry) Unregister(name string) {
+	delete(r.lazy, name)
+	delete(r.searchIndexes, name)
+	r.AppRegistry.Unregister(name)
+}
+
+// LoadShortcuts reads the shortcuts of every app registered from the index.
+// Registries searched from several goroutines must load them up front, as
+// Get is not safe for concurrent use while any remain.
+func (r *Registry) LoadShortcuts() {
+	for name := range r.lazy {
+		r.Get(name)
+	}
+}
+
+// Page returns the apps sorted by name, skipping offset of them and
+// returning at most limit, or all when limit is 0, along with how many
+// apps there are to page through. keep, when not nil, selects the apps.
+// The shortcuts of apps registered from the index are not read.
+func (r *Registry) Page(offset, limit int, keep func(AppInfo) bool) ([]AppInfo, int) {
+	infos := make([]AppInfo, 0, len(r.AppRegistry.apps))
+	for name, app := range r.AppRegistry.apps {
+		info := app.Info()
+		if r.lazy[name] {
+			info.Shortcuts = r.index[name].Info.Shortcuts
+		}
+		if keep == nil || keep(info) {
+			infos = append(infos, info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	total := len(infos)
+	offset = min(max(offset, 0), total)
+	end := total
+	if limit > 0 {
+		end = min(offset+limit, total)
+	}
+	return infos[offset:end], total
+}
@@ -0,0 +1,338 @@
+package apps
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Key notations the keys of shortcuts can be displayed in. Keys are shown
+// as written when no notation is set.
+const (
+	// NotationEmacs writes keys like C-x and M-RET
+	NotationEmacs = "emacs"
+	// NotationVim writes keys like <C-x> and <M-CR>
+	NotationVim = "vim"
+	// NotationMac writes keys like ⌃X and ⌥↩
+	NotationMac = "mac-symbols"
+	// NotationVerbose writes keys like Ctrl+X and Alt+Enter
+	NotationVerbose = "verbose"
+)
+
+// Modifier is a set of modifier keys held down in a chord
+type Modifier uint8
+
+const (
+	ModCtrl Modifier = 1 << iota
+	ModAlt
+	ModShift
+	ModSuper
+)
+
+// KeyChord is a key pressed together with modifiers. Named keys use their
+// canonical names such as "Enter" and "PageUp"; other keys are kept as
+// written, with letters lowercased.
+type KeyChord struct {
+	Mods Modifier
+	Key  string
+}
+
+// namedKey spells a named key in each notation
+type namedKey struct {
+	emacs, vim, mac string
+}
+
+// namedKeys maps canonical key names to their spelling in each notation
+var namedKeys = map[string]namedKey{
+	"Enter":     {"RET", "CR", "↩"},
+	"Tab":       {"TAB", "Tab", "⇥"},
+	"Esc":       {"ESC", "Esc", "⎋"},
+	"Space":     {"SPC", "Space", "␣"},
+	"Backspace": {"DEL", "BS", "⌫"},
+	"Delete":    {"<delete>", "Del", "⌦"},
+	"Insert":    {"<insert>", "Insert", "Ins"},
+	"Up":        {"<up>", "Up", "↑"},
+	"Down":      {"<down>", "Down", "↓"},
+	"Left":      {"<left>", "Left", "←"},
+	"Right":     {"<right>", "Right", "→"},
+	"Home":      {"<home>", "Home", "↖"},
+	"End":       {"<end>", "End", "↘"},
+	"PageUp":    {"<prior>", "PageUp", "⇞"},
+	"PageDown":  {"<next>", "PageDown", "⇟"},
+}
+
+// keyAliases maps lowercased spellings of named keys to canonical names.
+// They are only recognised inside chords or vim brackets, so a command like
+// "docker compose up" keeps its words.
+var keyAliases = map[string]string{
+	"enter": "Enter", "return": "Enter", "ret": "Enter", "cr": "Enter",
+	"tab": "Tab", "esc": "Esc", "escape": "Esc", "space": "Space", "spc": "Space",
+	"backspace": "Backspace", "bs": "Backspace", "bksp": "Backspace",
+	"delete": "Delete", "del": "Delete", "insert": "Insert", "ins": "Insert",
+	"up": "Up", "down": "Down", "left": "Left", "right": "Right",
+	"home": "Home", "end": "End",
+	"pageup": "PageUp", "pgup": "PageUp", "prior": "PageUp",
+	"pagedown": "PageDown", "pgdn": "PageDown", "next": "PageDown",
+	"↩": "Enter", "⏎": "Enter", "⇥": "Tab", "⎋": "Esc", "␣": "Space",
+	"⌫": "Backspace", "⌦": "Delete", "↑": "Up", "↓": "Down", "←": "Left", "→": "Right",
+}
+
+// standaloneKeys are the spellings recognised as named keys outside chords
+var standaloneKeys = map[string]string{
+	"RET": "Enter", "TAB": "Tab", "ESC": "Esc", "SPC": "Space",
+}
+
+// modifierWords maps lowercased modifier names to modifiers
+var modifierWords = map[string]Modifier{
+	"ctrl": ModCtrl, "control": ModCtrl, "ctl": ModCtrl,
+	"alt": ModAlt, "meta": ModAlt, "opt": ModAlt, "option": ModAlt,
+	"shift": ModShift,
+	"super": ModSuper, "mod": ModSuper, "mod4": ModSuper, "win": ModSuper,
+	"cmd": ModSuper, "command": ModSuper,
+}
+
+// emacsModifiers are the single-letter modifiers of emacs notation
+var emacsModifiers = map[string]Modifier{
+	"C": ModCtrl, "M": ModAlt, "S": ModShift, "s": ModSuper,
+}
+
+// vimModifiers are the single-letter modifiers of vim notation
+var vimModifiers = map[string]Modifier{
+	"C": ModCtrl, "M": ModAlt, "A": ModAlt, "S": ModShift, "D": ModSuper,
+}
+
+// macModifiers are the modifier symbols of mac notation in display order
+var macModifiers = []struct {
+	symbol rune
+	mod    Modifier
+}{
+	{'⌃', ModCtrl}, {'⌥', ModAlt}, {'⇧', ModShift}, {'⌘', ModSuper},
+}
+
+// ValidKeyNotation reports whether notation is a supported key notation
+func ValidKeyNotation(notation string) bool {
+	switch notation {
+	case NotationEmacs, NotationVim, NotationMac, NotationVerbose:
+		return true
+	}
+	return false
+}
+
+// FormatKeys rewrites the space-separated chords of keys, such as
+// "C-x C-s", "Ctrl+X" or "<C-x>", in notation. Words that are not chords,
+// like "gg" or the arguments of a command, are kept as written, as are all
+// keys when notation is empty or unknown.
+func FormatKeys(keys, notation string) string {
+	if !ValidKeyNotation(notation) {
+		return keys
+	}
+
+	words := strings.Split(keys, " ")
+	for i, word := range words {
+		if chord, ok := ParseKeyChord(word); ok {
+			words[i] = chord.Format(notation)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// ParseKeyChord parses a single chord written in emacs (C-x), vim (<C-x>),
+// mac (⌃X) or verbose (Ctrl+X) notation, or a standalone named key such as
+// Enter. It reports false for anything else.
+func ParseKeyChord(word string) (KeyChord, bool) {
+	if len(word) > 2 && strings.HasPrefix(word, "<") && strings.HasSuffix(word, ">") {
+		return parseSeparated(word[1:len(word)-1], "-", vimModifiers, true)
+	}
+	if chord, ok := parseMac(word); ok {
+		return chord, true
+	}
+	if strings.Contains(word[min(1, len(word)):], "+") {
+		if chord, ok := parseSeparated(word, "+", nil, false); ok {
+			return chord, true
+		}
+	}
+	if strings.Contains(word[min(1, len(word)):], "-") {
+		if chord, ok := parseSeparated(word, "-", emacsModifiers, false); ok {
+			return chord, true
+		}
+	}
+
+	if name, ok := standaloneKeys[word]; ok {
+		return KeyChord{Key: name}, true
+	}
+	if _, ok := namedKeys[word]; ok {
+		return KeyChord{Key: word}, true
+	}
+	if isFunctionKey(word) && word[0] == 'F' {
+		return KeyChord{Key: word}, true
+	}
+	return KeyChord{}, false
+}
+
+// parseSeparated parses modifiers and a key joined by sep. Modifiers are
+// spelled out or one of letters. A bracketed vim key may have no modifiers,
+// as in <CR>.
+func parseSeparated(word, sep string, letters map[string]Modifier, bracketed bool) (KeyChord, bool) {
+	var parts []string
+	if strings.HasSuffix(word, sep+sep) {
+		parts = append(strings.Split(strings.TrimSuffix(word, sep+sep), sep), sep)
+	} else {
+		parts = strings.Split(word, sep)
+	}
+	if len(parts) < 2 && !bracketed {
+		return KeyChord{}, false
+	}
+
+	var chord KeyChord
+	for _, part := range parts[:len(parts)-1] {
+		mod, ok := letters[part]
+		if !ok {
+			mod, ok = modifierWords[strings.ToLower(part)]
+		}
+		if !ok {
+			return KeyChord{}, false
+		}
+		chord.Mods |= mod
+	}
+
+	key := parts[len(parts)-1]
+	if len(key) > 2 && strings.HasPrefix(key, "<") && strings.HasSuffix(key, ">") {
+		// emacs writes named keys in brackets, as in C-<return>
+		key = key[1 : len(key)-1]
+	}
+	if key == "" {
+		return KeyChord{}, false
+	}
+	chord.Key = canonicalKey(key, sep == "+")
+	if bracketed && chord.Mods == 0 && utf8.RuneCountInString(chord.Key) > 1 && !isNamedKey(chord.Key) {
+		// <leader>, <Plug> and the like are not keys
+		return KeyChord{}, false
+	}
+	return chord, true
+}
+
+// parseMac parses a chord with leading mac modifier symbols, as in ⌃⇧T
+func parseMac(word string) (KeyChord, bool) {
+	var chord KeyChord
+	rest := word
+	for rest != "" {
+		r, size := utf8.DecodeRuneInString(rest)
+		found := false
+		for _, m := range macModifiers {
+			if r == m.symbol {
+				chord.Mods |= m.mod
+				found = true
+			}
+		}
+		if !found {
+			break
+		}
+		rest = rest[size:]
+	}
+	if chord.Mods == 0 || rest == "" {
+		return KeyChord{}, false
+	}
+	chord.Key = canonicalKey(rest, true)
+	return chord, true
+}
+
+// canonicalKey returns the canonical name of a named key or function key,
+// or key itself; single letters are lowercased when upper is true, as
+// notations like Ctrl+X capitalise them
+func canonicalKey(key string, upper bool) string {
+	if name, ok := keyAliases[strings.ToLower(key)]; ok {
+		return name
+	}
+	if isFunctionKey(key) {
+		return "F" + key[1:]
+	}
+	if upper && utf8.RuneCountInString(key) == 1 {
+		return strings.ToLower(key)
+	}
+	return key
+}
+
+// isNamedKey reports whether key is a canonical named or function key
+func isNamedKey(key string) bool {
+	_, ok := namedKeys[key]
+	return ok || isFunctionKey(key)
+}
+
+// isFunctionKey reports whether key is F1 to F24
+func isFunctionKey(key string) bool {
+	if len(key) < 2 || (key[0] != 'F' && key[0] != 'f') {
+		return false
+	}
+	n, err := strconv.Atoi(key[1:])
+	return err == nil && n >= 1 && n <= 24 && key[1] != '0'
+}
+
+// Format writes the chord in notation
+func (c KeyChord) Format(notation string) string {
+	named, isNamed := namedKeys[c.Key]
+
+	switch notation {
+	case NotationEmacs:
+		key := c.Key
+		switch {
+		case isNamed:
+			key = named.emacs
+		case isFunctionKey(key):
+			key = "<" + strings.ToLower(key) + ">"
+		}
+		return c.modifierPrefix([]string{"C-", "M-", "S-", "s-"}) + key
+
+	case NotationVim:
+		key := c.Key
+		if isNamed {
+			key = named.vim
+		}
+		if c.Mods == 0 && !isNamed && !isFunctionKey(key) {
+			return key
+		}
+		return "<" + c.modifierPrefix([]string{"C-", "M-", "S-", "D-"}) + key + ">"
+
+	case NotationMac:
+		key := upperKey(c.Key)
+		if isNamed {
+			key = named.mac
+		}
+		var prefix strings.Builder
+		for _, m := range macModifiers {
+			if c.Mods&m.mod != 0 {
+				prefix.WriteRune(m.symbol)
+			}
+		}
+		return prefix.String() + key
+
+	case NotationVerbose:
+		key := c.Key
+		if c.Mods != 0 {
+			key = upperKey(key)
+		}
+		return c.modifierPrefix([]string{"Ctrl+", "Alt+", "Shift+", "Super+"}) + key
+	}
+	return c.Key
+}
+
+// modifierPrefix joins the names of the chord's modifiers, given in the
+// order ctrl, alt, shift, super
+func (c KeyChord) modifierPrefix(names []string) string {
+	var b strings.Builder
+	for i, mod := range []Modifier{ModCtrl, ModAlt, ModShift, ModSuper} {
+		if c.Mods&mod != 0 {
+			b.WriteString(names[i])
+		}
+	}
+	return b.String()
+}
+
+// upperKey capitalises a single-letter key
+func upperKey(key string) string {
+	r, size := utf8.DecodeRuneInString(key)
+	if size == len(key) && unicode.IsLetter(r) {
+		return string(unicode.ToUpper(r))
+	}
+	return key
+}
@@ -0,0 +1,36 @@
+package apps
+
+import "testing"
+
+func TestFormatKeys(t *testing.T) {
+	tests := []struct {
+		keys                     string
+		emacs, vim, mac, verbose string
+	}{
+		{"C-x C-s", "C-x C-s", "<C-x> <C-s>", "⌃X ⌃S", "Ctrl+X Ctrl+S"},
+		{"Ctrl+Shift+T", "C-S-t", "<C-S-t>", "⌃⇧T", "Ctrl+Shift+T"},
+		{"<M-CR>", "M-RET", "<M-CR>", "⌥↩", "Alt+Enter"},
+		{"⌘⌥Left", "M-s-<left>", "<M-D-Left>", "⌥⌘←", "Alt+Super+Left"},
+		{"Mod+Shift+Enter", "S-s-RET", "<S-D-CR>", "⇧⌘↩", "Shift+Super+Enter"},
+		{"C-b c", "C-b c", "<C-b> c", "⌃B c", "Ctrl+B c"},
+		{"Ctrl++", "C-+", "<C-+>", "⌃+", "Ctrl++"},
+		{"<F5>", "<f5>", "<F5>", "F5", "F5"},
+		{"gg", "gg", "gg", "gg", "gg"},
+		{"<leader>w", "<leader>w", "<leader>w", "<leader>w", "<leader>w"},
+		{"git add --all", "git add --all", "git add --all", "git add --all", "git add --all"},
+		{"docker compose up", "docker compose up", "docker compose up", "docker compose up", "docker compose up"},
+	}
+
+	for _, tt := range tests {
+		for notation, want := range map[string]string{
+			NotationEmacs: tt.emacs, NotationVim: tt.vim, NotationMac: tt.mac, NotationVerbose: tt.verbose,
+		} {
+			if got := FormatKeys(tt.keys, notation); got != want {
+				t.Errorf("FormatKeys(%q, %s) = %q, want %q", tt.keys, notation, got, want)
+			}
+		}
+		if got := FormatKeys(tt.keys, ""); got != tt.keys {
+			t.Errorf("FormatKeys(%q) without a notation = %q", tt.keys, got)
+		}
+	}
+}
@@ -5,7 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,17 +22,71 @@ var (
 	ErrDirectoryRead  = errors.New("failed to read app directory")
 )
 
+// OverlaySuffix ends the storage key of an overlay, so the overlay of vim
+// is kept in vim.local.yaml
+const OverlaySuffix = ".local"
+
+// SectionPrefix starts the first cell of the rows heading each category of
+// the table when categories are shown; the other cells of a section row
+// are empty
+const SectionPrefix = "▸ "
+
+// otherCategory heads the shortcuts without a category
+const otherCategory = "Other"
+
 // Registry manages application loading and registration
 type Registry struct {
 	*AppRegistry
 	dataDir string
+	store   storage.Storage
+	journal *journal.Journal
+	source  journal.Source
+	// builtin keeps the hardcoded apps as defined, before overlays
+	builtin map[string]*App
+	// columns are the fields of each shortcut shown per app in the table
+	columns []string
+	// showCategories groups the rows of the table by category
+	showCategories bool
+	// aliases and merged name apps by other names and combine apps into
+	// one, as set by SetAliases and SetMergedApps
+	aliases map[string]string
+	merged  map[string][]string
+	// lazy names the apps registered from the index with their metadata
+	// only; Get reads their shortcuts
+	lazy map[string]bool
+	// index describes the stored apps; it is read from storage on first
+	// use and written back when apps had to be decoded
+	index      map[string]indexEntry
+	indexDirty bool
+	stats      LoadStats
+	// searchIndexes index the shortcuts of the registered apps for search
+	searchIndexes map[string]*searchIndex
 }
 
-// NewRegistry creates a new registry with default hardcoded apps
+// NewRegistry creates a new registry with default hardcoded apps, reading
+// user app files from dataDir
 func NewRegistry(dataDir string) *Registry {
+	var store storage.Storage
+	if dataDir != "" {
+		store = storage.NewFileStorage(expandPath(dataDir)).
+			Mount(storage.CollectionApps, expandPath(dataDir), ".yaml")
+	}
+
+	registry := NewRegistryWithStorage(store)
+	registry.dataDir = dataDir
+	return registry
+}
+
+// NewRegistryWithStorage creates a new registry with default hardcoded apps,
+// reading user apps from the apps collection of store. A nil store disables
+// user apps.
+func NewRegistryWithStorage(store storage.Storage) *Registry {
 	registry := &Registry{
-		AppRegistry: NewAppRegistry(),
-		dataDir:     dataDir,
+		AppRegistry:   NewAppRegistry(),
+		store:         store,
+		builtin:       make(map[string]*App),
+		lazy:          make(map[string]bool),
+		searchIndexes: make(map[string]*searchIndex),
 	}
 
 	// Load hardcoded apps as fallback
@@ -36,67 +95,291 @@ func NewRegistry(dataDir string) *Registry {
 	return registry
 }
 
-// LoadApps loads applications from configuration
+// SetColumns chooses the columns of the table, in order, from "shortcut",
+// "description", "category", "tags" and "platform". The shortcut column
+// always comes first since it identifies the rows; each app gets one
+// column for every other field, and the description alone when none is
+// given.
+func (r *Registry) SetColumns(columns []string) {
+	r.columns = nil
+	for _, column := range columns {
+		if column != "shortcut" {
+			r.columns = append(r.columns, column)
+		}
+	}
+}
+
+// Columns returns the fields shown per app, as set by SetColumns
+func (r *Registry) Columns() []string {
+	if len(r.columns) == 0 {
+		return []string{"description"}
+	}
+	return r.columns
+}
+
+// SetShowCategories groups the rows of the table by the category of their
+// shortcuts, each group under a section row, sorted by category and then
+// keys
+func (r *Registry) SetShowCategories(on bool) {
+	r.showCategories = on
+}
+
+// ShowCategories reports whether the table is grouped by category, as set
+// by SetShowCategories
+func (r *Registry) ShowCategories() bool {
+	return r.showCategories
+}
+
+// IsSectionRow reports whether a table row heads a category rather than
+// holding a shortcut
+func IsSectionRow(row []string) bool {
+	if len(row) == 0 || !strings.HasPrefix(row[0], SectionPrefix) {
+		return false
+	}
+	for _, cell := range row[1:] {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ColumnHeader names the table column of app showing field: the app name
+// for descriptions, "app:field" otherwise
+func ColumnHeader(app, field string) string {
+	if field == "description" {
+		return app
+	}
+	return app + ":" + field
+}
+
+// ColumnApp returns the app and field of a table column named by
+// ColumnHeader
+func ColumnApp(header string) (string, string) {
+	if i := strings.LastIndex(header, ":"); i > 0 {
+		return header[:i], header[i+1:]
+	}
+	return header, "description"
+}
+
+// shortcutField returns the field of a shortcut shown in a table column
+func shortcutField(shortcut Shortcut, field string) string {
+	switch field {
+	case "category":
+		return shortcut.Category
+	case "tags":
+		return strings.Join(shortcut.Tags, ", ")
+	case "platform":
+		return shortcut.Platform
+	default:
+		return shortcut.Description
+	}
+}
+
+// LoadApps loads applications from configuration. Stored apps unchanged
+// since they were last indexed are registered with their metadata only,
+// and their shortcuts are read on first use. Aliases and merged apps load
+// the apps they stand for.
 func (r *Registry) LoadApps(appNames []string) error {
+	start := time.Now()
+	defer func() { r.stats.Duration += time.Since(start) }()
+
 	for _, name := range appNames {
-		if err := r.LoadApp(name); err != nil {
-			// Log error but continue with next app (backward compatibility)
-			continue
+		for _, member := range r.members(name) {
+			if err := r.registerApp(member); err != nil {
+				// Log error but continue with next app (backward compatibility)
+				continue
+			}
 		}
 	}
+	r.saveIndex()
 	return nil
 }
 
-// LoadAllAppsFromDirectory scans the data directory and loads all available apps
+// LoadAllAppsFromDirectory loads every user app kept in storage and applies
+// the overlays of built-in apps, registering apps from the index as
+// LoadApps does
 func (r *Registry) LoadAllAppsFromDirectory() error {
-	if r.dataDir == "" {
+	if r.store == nil {
 		return nil
 	}
+	start := time.Now()
+	defer func() { r.stats.Duration += time.Since(start) }()
 
-	expandedDir := expandPath(r.dataDir)
-	entries, err := os.ReadDir(expandedDir)
-	if err != nil {
-		return fmt.Errorf("%w: %s", ErrDirectoryRead, expandedDir)
+	if r.dataDir != "" {
+		expandedDir := expandPath(r.dataDir)
+		if _, err := os.Stat(expandedDir); err != nil {
+			return fmt.Errorf("%w: %s", ErrDirectoryRead, expandedDir)
+		}
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
+	names, err := r.store.List(storage.CollectionApps)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDirectoryRead, err)
+	}
 
-		name := entry.Name()
-		if !strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml") {
-			continue
+	var stored []string
+	for _, name := range names {
+		if !strings.HasSuffix(name, OverlaySuffix) {
+			stored = append(stored, name)
 		}
-
-		// Extract app name from filename
-		appName := strings.TrimSuffix(strings.TrimSuffix(name, ".yaml"), ".yml")
-		if err := r.LoadApp(appName); err != nil {
+		if err := r.registerApp(strings.TrimSuffix(name, OverlaySuffix)); err != nil {
 			// Log but don't fail for individual app loading errors
 			continue
 		}
 	}
+	r.pruneIndex(stored)
+	r.saveIndex()
 
 	return nil
 }
 
-// LoadApp loads a single application from file or hardcoded data
+// LoadApp loads a single application from storage or hardcoded data and
+// merges its overlay, if any
 func (r *Registry) LoadApp(name string) error {
-	// Try to load from file first
-	if r.dataDir != "" {
-		appPath := filepath.Join(r.dataDir, name+".yaml")
-		if app, err := r.loadAppFromFile(appPath); err == nil {
-			r.Register(app)
-			return nil
+	// Try to load from storage first
+	app, err := r.loadStoredApp(name)
+	return r.registerWithOverlay(name, app, err)
+}
+
+// registerWithOverlay registers the stored app read with err, or when it
+// could not be read the built-in or already registered app of that name,
+// merging its overlay
+func (r *Registry) registerWithOverlay(name string, app *App, err error) error {
+	if err != nil {
+		// If loading fails, app should already be loaded from hardcoded data
+		var exists bool
+		if app, exists = r.builtin[name]; !exists {
+			if app, exists = r.AppRegistry.Get(name); !exists {
+				return ErrAppNotFound
+			}
 		}
 	}
 
-	// If file loading fails, app should already be loaded from hardcoded data
-	if _, exists := r.Get(name); exists {
-		return nil
+	merged, err := r.applyOverlay(app)
+	if err != nil {
+		r.Register(app)
+		return err
+	}
+	r.Register(merged)
+	return nil
+}
+
+// applyOverlay merges the overlay of app from storage into a copy of app
+func (r *Registry) applyOverlay(app *App) (*App, error) {
+	overlay, err := r.LoadOverlay(app.Name)
+	if err != nil || overlay == nil {
+		return app, err
+	}
+	return app.WithOverlay(overlay), nil
+}
+
+// LoadOverlay reads the overlay of an app from storage. It returns nil when
+// the app has no overlay.
+func (r *Registry) LoadOverlay(name string) (*Overlay, error) {
+	if r.store == nil {
+		return nil, nil
+	}
+
+	data, err := r.store.Get(storage.CollectionApps, name+OverlaySuffix)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := schemaError(CheckOverlay(data)); err != nil {
+		return nil, fmt.Errorf("%s%s: %w", name, OverlaySuffix, err)
+	}
+	var overlay Overlay
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("%w: %s%s: %v", ErrInvalidAppFile, name, OverlaySuffix, err)
+	}
+	return &overlay, nil
+}
+
+// AddToOverlay appends shortcuts to the overlay of the app called name,
+// creating it when the app has none, and registers the app again with them
+func (r *Registry) AddToOverlay(name string, shortcuts []Shortcut) error {
+	if r.store == nil {
+		return fmt.Errorf("data directory not configured")
+	}
+	for i, shortcut := range shortcuts {
+		if shortcut.Keys == "" || shortcut.Description == "" {
+			return fmt.Errorf("%w: shortcut %d needs keys and a description", ErrAppValidation, i)
+		}
+	}
+
+	overlay, err := r.LoadOverlay(name)
+	if err != nil {
+		return err
+	}
+	if overlay == nil {
+		overlay = &Overlay{}
+	}
+	overlay.Shortcuts = append(overlay.Shortcuts, shortcuts...)
+
+	data, err := yaml.Marshal(overlay)
+	if err != nil {
+		return fmt.Errorf("failed to marshal overlay: %w", err)
+	}
+	if err := r.store.Put(storage.CollectionApps, name+OverlaySuffix, data); err != nil {
+		return fmt.Errorf("failed to write overlay file: %w", err)
+	}
+	// apps not loaded yet get the overlay when they are
+	if _, loaded := r.AppRegistry.Get(name); loaded && !r.lazy[name] {
+		return r.LoadApp(name)
+	}
+	return nil
+}
+
+// loadStoredApp reads a user app from storage
+func (r *Registry) loadStoredApp(name string) (*App, error) {
+	if r.store == nil {
+		return nil, ErrAppNotFound
 	}
 
-	return ErrAppNotFound
+	data, err := r.store.Get(storage.CollectionApps, name)
+	if err != nil {
+		return nil, err
+	}
+	return r.decodeApp(data)
+}
+
+// HasStoredApp reports whether name is a user app kept in storage rather
+// than only a built-in
+func (r *Registry) HasStoredApp(name string) bool {
+	if r.store == nil {
+		return false
+	}
+	_, err := r.store.Get(storage.CollectionApps, name)
+	return err == nil
+}
+
+// StoredApps returns the names of the user apps kept in storage, without
+// overlays
+func (r *Registry) StoredApps() ([]string, error) {
+	if r.store == nil {
+		return []string{}, nil
+	}
+	keys, err := r.store.List(storage.CollectionApps)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, key := range keys {
+		if !strings.HasSuffix(key, OverlaySuffix) {
+			names = append(names, key)
+		}
+	}
+	return names, nil
+}
+
+// ParseApp decodes and validates a YAML app definition without saving it
+func (r *Registry) ParseApp(data []byte) (*App, error) {
+	return r.decodeApp(data)
 }
 
 // loadAppFromFile loads an app definition from a YAML file
@@ -105,6 +388,15 @@ func (r *Registry) loadAppFromFile(path string) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
+	return r.decodeApp(data)
+}
+
+// decodeApp parses and validates a YAML app definition. Files breaking
+// the app schema fail with a SchemaError telling what is wrong where.
+func (r *Registry) decodeApp(data []byte) (*App, error) {
+	if err := schemaError(CheckApp(data)); err != nil {
+		return nil, err
+	}
 
 	var app App
 	if err := yaml.Unmarshal(data, &app); err != nil {
@@ -119,6 +411,11 @@ func (r *Registry) loadAppFromFile(path string) (*App, error) {
 	return &app, nil
 }
 
+// ValidateApp checks that an app definition is complete enough to be saved
+func (r *Registry) ValidateApp(app *App) error {
+	return r.validateApp(app)
+}
+
 // validateApp validates an app definition
 func (r *Registry) validateApp(app *App) error {
 	var errors []error
@@ -127,6 +424,9 @@ func (r *Registry) validateApp(app *App) error {
 	if app.Name == "" {
 		errors = append(errors, fmt.Errorf("app name is required"))
 	}
+	if strings.HasSuffix(app.Name, OverlaySuffix) {
+		errors = append(errors, fmt.Errorf("app name must not end in %s", OverlaySuffix))
+	}
 
 	if app.Description == "" {
 		errors = append(errors, fmt.Errorf("app description is required"))
@@ -149,9 +449,9 @@ func (r *Registry) validateApp(app *App) error {
 	return nil
 }
 
-// SaveApp saves an app definition to a YAML file
+// SaveApp saves an app definition as YAML to storage
 func (r *Registry) SaveApp(app *App) error {
-	if r.dataDir == "" {
+	if r.store == nil {
 		return fmt.Errorf("data directory not configured")
 	}
 
@@ -160,26 +460,61 @@ func (r *Registry) SaveApp(app *App) error {
 		return err
 	}
 
-	expandedDir := expandPath(r.dataDir)
+	before, _ := r.loadStoredApp(app.Name)
 
-	// Ensure directory exists
-	if err := os.MkdirAll(expandedDir, 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %w", err)
-	}
-
-	appPath := filepath.Join(expandedDir, app.Name+".yaml")
 	data, err := yaml.Marshal(app)
 	if err != nil {
 		return fmt.Errorf("failed to marshal app data: %w", err)
 	}
 
-	if err := os.WriteFile(appPath, data, 0644); err != nil {
+	if err := r.store.Put(storage.CollectionApps, app.Name, data); err != nil {
 		return fmt.Errorf("failed to write app file: %w", err)
 	}
 
-	// Register the app in memory
-	r.Register(app)
+	// Register the app in memory, keeping the user's overlay
+	if merged, err := r.applyOverlay(app); err == nil {
+		r.Register(merged)
+	} else {
+		r.Register(app)
+	}
+
+	if r.journal != nil {
+		action := journal.ActionCreate
+		if before != nil {
+			action = journal.ActionUpdate
+		}
+		r.journal.Record(action, journal.KindApp, app.Name, app.Name, r.source, before, app)
+	}
+
+	return nil
+}
+
+// SetJournal records every app file written or removed through the registry
+// in j, attributed to source
+func (r *Registry) SetJournal(j *journal.Journal, source journal.Source) {
+	r.journal = j
+	r.source = source
+}
+
+// RemoveApp deletes a user app from storage and unregisters the app
+func (r *Registry) RemoveApp(name string) error {
+	if r.store == nil {
+		return fmt.Errorf("data directory not configured")
+	}
+
+	before, err := r.loadStoredApp(name)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAppNotFound, name)
+	}
+
+	if err := r.store.Delete(storage.CollectionApps, name); err != nil {
+		return fmt.Errorf("failed to remove app file: %w", err)
+	}
+	r.Unregister(name)
 
+	if r.journal != nil {
+		r.journal.Record(journal.ActionDelete, journal.KindApp, name, name, r.source, before, nil)
+	}
 	return nil
 }
 
@@ -270,86 +605,170 @@ func (r *Registry) loadHardcodedApps() {
 
 	// Register all apps
 	for _, app := range apps {
+		r.builtin[app.Name] = app
 		r.Register(app)
 	}
 }
 
 // GetTableData returns data in the original table format for backward compatibility
 func (r *Registry) GetTableData(appNames []string) [][]string {
+	return r.tableData(appNames, nil)
+}
+
+// SearchTableData returns filtered table data based on search query
+func (r *Registry) SearchTableData(appNames []string, query string) [][]string {
+	// If no query, return all data
+	if query == "" {
+		return r.GetTableData(appNames)
+	}
+
+	// Search in keys, description, and category
+	terms := []string{strings.ToLower(query)}
+	return r.tableData(appNames, func(app *App) []bool {
+		return r.searchIndexOf(app).matching(terms)
+	})
+}
+
+// FilterTableData returns the table of appNames limited to the shortcuts
+// that match query, in the syntax of ParseSearchQuery, and carry at least
+// one of tags, when any
+func (r *Registry) FilterTableData(appNames []string, query string, tags []string) [][]string {
+	q := ParseSearchQuery(query)
+	return r.tableData(scopeApps(appNames, q.Apps), func(app *App) []bool {
+		matched := r.matchQuery(app, q)
+		for i := range matched {
+			matched[i] = matched[i] && (len(tags) == 0 || app.Shortcuts[i].HasAnyTag(tags))
+		}
+		return matched
+	})
+}
+
+// Tags returns the sorted, distinct tags of the shortcuts of appNames
+func (r *Registry) Tags(appNames []string) []string {
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, appName := range appNames {
+		app, exists := r.Get(appName)
+		if !exists {
+			continue
+		}
+		for _, shortcut := range app.Shortcuts {
+			for _, tag := range shortcut.Tags {
+				if tag != "" && !seen[tag] {
+					seen[tag] = true
+					tags = append(tags, tag)
+				}
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// tableData builds the table of appNames from the shortcuts match selects,
+// by position, in each app, or from all of them when match is nil: a
+// header row, then one row per distinct keys with the columns of each app,
+// or "-" where an app lacks the keys. When categories are shown, a row
+// belongs to the category of the shortcut of the first app having its keys.
+func (r *Registry) tableData(appNames []string, match func(app *App) []bool) [][]string {
+	columns := r.Columns()
+	width := len(appNames) * len(columns)
+
 	// Header row
-	header := make([]string, len(appNames)+1)
-	header[0] = "Shortcut"
-	copy(header[1:], appNames)
+	header := []string{"Shortcut"}
+	for _, appName := range appNames {
+		for _, column := range columns {
+			header = append(header, ColumnHeader(appName, column))
+		}
+	}
 
 	// Collect all unique shortcuts
 	shortcutMap := make(map[string][]string)
+	categories := make(map[string]string)
 	for i, appName := range appNames {
 		if app, exists := r.Get(appName); exists {
-			for _, shortcut := range app.Shortcuts {
+			var matched []bool
+			if match != nil {
+				matched = match(app)
+			}
+			for s, shortcut := range app.Shortcuts {
+				if matched != nil && !matched[s] {
+					continue
+				}
 				if _, exists := shortcutMap[shortcut.Keys]; !exists {
-					shortcutMap[shortcut.Keys] = make([]string, len(appNames))
+					categories[shortcut.Keys] = shortcut.Category
+					shortcutMap[shortcut.Keys] = make([]string, width)
 					for j := range shortcutMap[shortcut.Keys] {
 						shortcutMap[shortcut.Keys][j] = "-"
 					}
 				}
-				shortcutMap[shortcut.Keys][i] = shortcut.Description
+				for j, column := range columns {
+					shortcutMap[shortcut.Keys][i*len(columns)+j] = shortcutField(shortcut, column)
+				}
 			}
 		}
 	}
 
 	// Convert to table format
 	rows := [][]string{header}
-	for keys, descriptions := range shortcutMap {
-		row := make([]string, len(appNames)+1)
+	for keys, cells := range shortcutMap {
+		row := make([]string, width+1)
 		row[0] = keys
-		copy(row[1:], descriptions)
+		copy(row[1:], cells)
 		rows = append(rows, row)
 	}
 
+	if r.showCategories {
+		return sections(rows, categories, width)
+	}
 	return rows
 }
 
-// SearchTableData returns filtered table data based on search query
-func (r *Registry) SearchTableData(appNames []string, query string) [][]string {
-	// If no query, return all data
-	if query == "" {
-		return r.GetTableData(appNames)
-	}
-
-	// Header row
-	header := make([]string, len(appNames)+1)
-	header[0] = "Shortcut"
-	copy(header[1:], appNames)
-
-	// Collect shortcuts that match the search query
-	shortcutMap := make(map[string][]string)
-	for i, appName := range appNames {
-		if app, exists := r.Get(appName); exists {
-			for _, shortcut := range app.Shortcuts {
-				// Search in keys, description, and category
-				if r.shortcutMatches(shortcut, query) {
-					if _, exists := shortcutMap[shortcut.Keys]; !exists {
-						shortcutMap[shortcut.Keys] = make([]string, len(appNames))
-						for j := range shortcutMap[shortcut.Keys] {
-							shortcutMap[shortcut.Keys][j] = "-"
-						}
-					}
-					shortcutMap[shortcut.Keys][i] = shortcut.Description
-				}
+// sections sorts the shortcut rows by category and keys, putting a section
+// row before the rows of each category. Shortcuts without a category come
+// last.
+func sections(rows [][]string, categories map[string]string, width int) [][]string {
+	shortcuts := rows[1:]
+	sort.Slice(shortcuts, func(i, j int) bool {
+		a, b := categories[shortcuts[i][0]], categories[shortcuts[j][0]]
+		switch {
+		case a == b:
+			return shortcuts[i][0] < shortcuts[j][0]
+		case a == "" || b == "":
+			return b == ""
+		case !strings.EqualFold(a, b):
+			return strings.ToLower(a) < strings.ToLower(b)
+		}
+		return a < b
+	})
+
+	grouped := [][]string{rows[0]}
+	for i, row := range shortcuts {
+		category := categories[row[0]]
+		if i == 0 || category != categories[shortcuts[i-1][0]] {
+			title := category
+			if title == "" {
+				title = otherCategory
 			}
+			section := make([]string, width+1)
+			section[0] = SectionPrefix + title
+			grouped = append(grouped, section)
 		}
+		grouped = append(grouped, row)
 	}
+	return grouped
+}
 
-	// Convert to table format
-	rows := [][]string{header}
-	for keys, descriptions := range shortcutMap {
-		row := make([]string, len(appNames)+1)
-		row[0] = keys
-		copy(row[1:], descriptions)
-		rows = append(rows, row)
+// HasAnyTag reports whether the shortcut carries one of tags
+func (s Shortcut) HasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		for _, own := range s.Tags {
+			if own == tag {
+				return true
+			}
+		}
 	}
-
-	return rows
+	return false
 }
 
 // shortcutMatches checks if a shortcut matches the search query
@@ -379,9 +798,12 @@ func (r *Registry) SearchShortcuts(query string) []ShortcutResult {
 	var results []ShortcutResult
 	queryLower := strings.ToLower(query)
 
+	r.LoadShortcuts()
+	terms := []string{queryLower}
 	for appName, app := range r.AppRegistry.apps {
-		for _, shortcut := range app.Shortcuts {
-			if r.shortcutMatches(shortcut, query) {
+		matched := r.searchIndexOf(app).matching(terms)
+		for i, shortcut := range app.Shortcuts {
+			if matched[i] {
 				results = append(results, ShortcutResult{
 					AppName:  appName,
 					Shortcut: shortcut,
@@ -119,3 +119,31 @@ func TestRegistry_loadAppFromFile_InvalidPath(t *testing.T) {
 		t.Error("should return error for invalid path")
 	}
 }
+
+func TestRegistry_LoadApp_ExpandsHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	appsDir := filepath.Join(home, "apps")
+	os.MkdirAll(appsDir, 0755)
+	os.WriteFile(filepath.Join(appsDir, "tool.yaml"),
+		[]byte("name: tool\ndescription: A tool\nshortcuts:\n  - keys: t\n    description: run\n"), 0644)
+
+	registry := NewRegistry("~/apps")
+	if err := registry.LoadApp("tool"); err != nil {
+		t.Fatalf("LoadApp() with ~ data dir error = %v", err)
+	}
+	if _, exists := registry.Get("tool"); !exists {
+		t.Error("app should be registered")
+	}
+}
+
+func TestRegistry_ValidateApp(t *testing.T) {
+	registry := NewRegistry("")
+	if err := registry.ValidateApp(&App{Name: "x"}); err == nil {
+		t.Error("app without description should fail validation")
+	}
+	if err := registry.ValidateApp(&App{Name: "x", Description: "y"}); err != nil {
+		t.Errorf("valid app failed validation: %v", err)
+	}
+}
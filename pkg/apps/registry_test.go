@@ -1,11 +1,17 @@
 package apps
 
 import (
+	"encoding/json"
 	"errors"
 	"gopkg.in/yaml.v3"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
 )
 
 func TestNewRegistry(t *testing.T) {
@@ -177,6 +183,41 @@ func TestRegistry_GetTableData(t *testing.T) {
 	}
 }
 
+func TestRegistry_SetColumns(t *testing.T) {
+	registry := NewRegistry("")
+	registry.Register(&App{Name: "demo", Shortcuts: []Shortcut{
+		{Keys: "gg", Description: "Top", Category: "Motion", Tags: []string{"nav", "jump"}},
+	}})
+	registry.SetColumns([]string{"category", "shortcut", "description", "tags"})
+
+	table := registry.GetTableData([]string{"demo"})
+	want := [][]string{
+		{"Shortcut", "demo:category", "demo", "demo:tags"},
+		{"gg", "Motion", "Top", "nav, jump"},
+	}
+	if len(table) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(table), len(want))
+	}
+	for i := range want {
+		if strings.Join(table[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, table[i], want[i])
+		}
+	}
+
+	if app, field := ColumnApp("demo:tags"); app != "demo" || field != "tags" {
+		t.Errorf("ColumnApp(demo:tags) = %s, %s", app, field)
+	}
+	if app, field := ColumnApp("demo"); app != "demo" || field != "description" {
+		t.Errorf("ColumnApp(demo) = %s, %s", app, field)
+	}
+
+	// only the shortcut column still shows the descriptions
+	registry.SetColumns([]string{"shortcut"})
+	if header := registry.GetTableData([]string{"demo"})[0]; len(header) != 2 || header[1] != "demo" {
+		t.Errorf("header = %q", header)
+	}
+}
+
 func TestRegistry_GetTableData_EmptyApps(t *testing.T) {
 	registry := NewRegistry("")
 	tableData := registry.GetTableData([]string{})
@@ -314,6 +355,33 @@ func TestRegistry_SearchTableData(t *testing.T) {
 	}
 }
 
+func TestRegistry_FilterTableData(t *testing.T) {
+	registry := NewRegistry("")
+	registry.Register(&App{
+		Name: "tmux",
+		Shortcuts: []Shortcut{
+			{Keys: "C-b c", Description: "new window", Tags: []string{"window"}},
+			{Keys: "C-b d", Description: "detach", Tags: []string{"session"}},
+			{Keys: "C-b %", Description: "split pane", Tags: []string{"pane", "window"}},
+		},
+	})
+
+	if tags := registry.Tags([]string{"vim", "tmux"}); !reflect.DeepEqual(tags, []string{"pane", "session", "window"}) {
+		t.Errorf("Tags() = %v", tags)
+	}
+
+	if rows := registry.FilterTableData([]string{"vim", "tmux"}, "", []string{"window", "session"}); len(rows) != 4 {
+		t.Errorf("FilterTableData() by tags returned %d rows, want 3 shortcuts", len(rows)-1)
+	}
+	rows := registry.FilterTableData([]string{"tmux"}, "pane", []string{"window"})
+	if len(rows) != 2 || rows[1][0] != "C-b %" {
+		t.Errorf("FilterTableData() by query and tag = %v", rows)
+	}
+	if rows := registry.FilterTableData([]string{"vim"}, "", nil); len(rows) != len(registry.GetTableData([]string{"vim"})) {
+		t.Error("FilterTableData() without filters should match GetTableData()")
+	}
+}
+
 func TestRegistry_ShortcutMatches(t *testing.T) {
 	registry := NewRegistry("")
 
@@ -428,6 +496,96 @@ func TestRegistry_LoadAllAppsFromDirectory(t *testing.T) {
 	}
 }
 
+func TestRegistry_Overlay(t *testing.T) {
+	tmpDir := t.TempDir()
+	overlay := `shortcuts:
+  - keys: q
+    description: close window
+  - keys: gx
+    description: open URL under cursor
+    category: navigation
+`
+	os.WriteFile(filepath.Join(tmpDir, "vim.local.yaml"), []byte(overlay), 0644)
+
+	registry := NewRegistry(tmpDir)
+	if err := registry.LoadAllAppsFromDirectory(); err != nil {
+		t.Fatalf("LoadAllAppsFromDirectory() error = %v", err)
+	}
+
+	vim, _ := registry.Get("vim")
+	shortcuts := map[string]Shortcut{}
+	for _, shortcut := range vim.Shortcuts {
+		shortcuts[shortcut.Keys] = shortcut
+	}
+	if got := shortcuts["q"]; got.Description != "close window" || got.Category != "general" {
+		t.Errorf("overridden shortcut = %+v, want the overlay description and the built-in category", got)
+	}
+	if got := shortcuts["gx"]; got.Description != "open URL under cursor" {
+		t.Errorf("added shortcut = %+v", got)
+	}
+	if got := shortcuts["gg"]; got.Description != "top" {
+		t.Errorf("other shortcuts should be kept, gg = %+v", got)
+	}
+	if len(vim.Shortcuts) != len(registry.builtin["vim"].Shortcuts)+1 {
+		t.Errorf("vim has %d shortcuts, want one more than built in", len(vim.Shortcuts))
+	}
+
+	// loading again does not apply the overlay twice
+	registry.LoadApp("vim")
+	if again, _ := registry.Get("vim"); len(again.Shortcuts) != len(vim.Shortcuts) {
+		t.Errorf("reloading vim gave %d shortcuts, want %d", len(again.Shortcuts), len(vim.Shortcuts))
+	}
+
+	// overlays also apply to saved apps, and are not listed as apps
+	app := &App{Name: "tmux", Description: "Terminal multiplexer", Shortcuts: []Shortcut{{Keys: "C-b c", Description: "new window"}}}
+	os.WriteFile(filepath.Join(tmpDir, "tmux.local.yaml"), []byte("shortcuts:\n  - keys: C-b c\n    description: create window\n"), 0644)
+	if err := registry.SaveApp(app); err != nil {
+		t.Fatalf("SaveApp() error = %v", err)
+	}
+	if tmux, _ := registry.Get("tmux"); tmux.Shortcuts[0].Description != "create window" {
+		t.Errorf("saved app shortcut = %+v, want the overlay", tmux.Shortcuts[0])
+	}
+	if stored, _ := registry.StoredApps(); len(stored) != 1 || stored[0] != "tmux" {
+		t.Errorf("StoredApps() = %v, want [tmux]", stored)
+	}
+	if _, exists := registry.Get("vim" + OverlaySuffix); exists {
+		t.Error("overlays should not be registered as apps")
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, "vim.local.yaml"), []byte("shortcuts:\n  - keys: q\n"), 0644)
+	if err := registry.LoadApp("vim"); !errors.Is(err, ErrAppValidation) {
+		t.Errorf("LoadApp() with an invalid overlay error = %v, want %v", err, ErrAppValidation)
+	}
+}
+
+func TestRegistry_AddToOverlay(t *testing.T) {
+	tmpDir := t.TempDir()
+	registry := NewRegistry(tmpDir)
+	registry.LoadApps([]string{"vim"})
+	before, _ := registry.Get("vim")
+
+	if err := registry.AddToOverlay("vim", []Shortcut{{Keys: "gx"}}); !errors.Is(err, ErrAppValidation) {
+		t.Errorf("AddToOverlay() of a shortcut without description error = %v, want %v", err, ErrAppValidation)
+	}
+	if err := registry.AddToOverlay("vim", []Shortcut{{Keys: "gx", Description: "open URL"}}); err != nil {
+		t.Fatalf("AddToOverlay() error = %v", err)
+	}
+	if err := registry.AddToOverlay("vim", []Shortcut{{Keys: "gf", Description: "open file"}}); err != nil {
+		t.Fatalf("AddToOverlay() error = %v", err)
+	}
+
+	overlay, err := registry.LoadOverlay("vim")
+	if err != nil || overlay == nil || len(overlay.Shortcuts) != 2 || overlay.Shortcuts[0].Keys != "gx" {
+		t.Fatalf("LoadOverlay() = %+v, %v; want both shortcuts", overlay, err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "vim.local.yaml")); err != nil {
+		t.Errorf("the overlay should be written next to the apps: %v", err)
+	}
+	if vim, _ := registry.Get("vim"); len(vim.Shortcuts) != len(before.Shortcuts)+2 {
+		t.Errorf("vim has %d shortcuts after AddToOverlay(), want %d", len(vim.Shortcuts), len(before.Shortcuts)+2)
+	}
+}
+
 func TestRegistry_LoadAllAppsFromDirectoryNonExistent(t *testing.T) {
 	registry := NewRegistry("/non/existent/directory")
 
@@ -479,6 +637,59 @@ func TestRegistry_SaveApp(t *testing.T) {
 	}
 }
 
+func TestRegistry_JournalAndRemoveApp(t *testing.T) {
+	tmpDir := t.TempDir()
+	registry := NewRegistry(tmpDir)
+	j, err := journal.Open(filepath.Join(tmpDir, "journal.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.SetJournal(j, journal.SourceImport)
+
+	app := &App{
+		Name:        "journaled",
+		Description: "Journaled app",
+		Shortcuts:   []Shortcut{{Keys: "a", Description: "first"}},
+	}
+	if err := registry.SaveApp(app); err != nil {
+		t.Fatalf("SaveApp() error = %v", err)
+	}
+	app.Shortcuts[0].Description = "second"
+	if err := registry.SaveApp(app); err != nil {
+		t.Fatalf("SaveApp() update error = %v", err)
+	}
+	if err := registry.RemoveApp("journaled"); err != nil {
+		t.Fatalf("RemoveApp() error = %v", err)
+	}
+
+	if _, exists := registry.Get("journaled"); exists {
+		t.Error("removed app should be unregistered")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "journaled.yaml")); !os.IsNotExist(err) {
+		t.Error("removed app file should be deleted")
+	}
+	if err := registry.RemoveApp("journaled"); !errors.Is(err, ErrAppNotFound) {
+		t.Errorf("RemoveApp() of missing app error = %v, want ErrAppNotFound", err)
+	}
+
+	entries, _ := j.Entries()
+	want := []journal.Action{journal.ActionCreate, journal.ActionUpdate, journal.ActionDelete}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d journal entries, got %d", len(want), len(entries))
+	}
+	for i, action := range want {
+		if entries[i].Action != action || entries[i].Kind != journal.KindApp || entries[i].Source != journal.SourceImport {
+			t.Errorf("entry %d = %+v", i, entries[i])
+		}
+	}
+
+	var before App
+	json.Unmarshal(entries[1].Before, &before)
+	if len(before.Shortcuts) != 1 || before.Shortcuts[0].Description != "first" {
+		t.Errorf("update should record previous file state, got %+v", before)
+	}
+}
+
 func TestRegistry_SaveAppInvalidPath(t *testing.T) {
 	registry := NewRegistry("/invalid/path/that/cannot/be/created")
 
@@ -653,3 +864,205 @@ func TestRegistry_GetSearchMatchesEdgeCases(t *testing.T) {
 		t.Error("Should find matches in description")
 	}
 }
+
+func TestRegistry_SQLiteStorage(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "cheat-go.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage() error = %v", err)
+	}
+	defer store.Close()
+
+	registry := NewRegistryWithStorage(store)
+	app := &App{Name: "tool", Description: "A tool", Shortcuts: []Shortcut{{Keys: "x", Description: "Exit"}}}
+	if err := registry.SaveApp(app); err != nil {
+		t.Fatalf("SaveApp() error = %v", err)
+	}
+	if !registry.HasStoredApp("tool") || registry.HasStoredApp("vim") {
+		t.Error("HasStoredApp should only report apps kept in storage")
+	}
+
+	reloaded := NewRegistryWithStorage(store)
+	if err := reloaded.LoadAllAppsFromDirectory(); err != nil {
+		t.Fatalf("LoadAllAppsFromDirectory() error = %v", err)
+	}
+	if got, ok := reloaded.Get("tool"); !ok || got.Description != "A tool" {
+		t.Errorf("reloaded app = %v, %v", got, ok)
+	}
+
+	if err := reloaded.RemoveApp("tool"); err != nil {
+		t.Fatalf("RemoveApp() error = %v", err)
+	}
+	if reloaded.HasStoredApp("tool") {
+		t.Error("removed app still stored")
+	}
+}
+
+func TestRegistry_LazyLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(description string) {
+		data := "name: big\ndescription: " + description + "\nshortcuts:\n  - keys: a\n    description: first\n  - keys: b\n    description: second\n"
+		if err := os.WriteFile(filepath.Join(dir, "big.yaml"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Big app")
+
+	// the first start decodes the app and indexes it
+	registry := NewRegistry(dir)
+	registry.LoadApps([]string{"vim", "big"})
+	if stats := registry.LoadStats(); stats.Decoded != 1 || stats.Indexed != 0 || stats.Loaded != stats.Apps {
+		t.Fatalf("first load stats = %+v", stats)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "session", appIndexKey+".json")); err != nil {
+		t.Fatalf("the index should be saved: %v", err)
+	}
+
+	// the next one registers it from the index, reading shortcuts on demand
+	registry = NewRegistry(dir)
+	registry.LoadApps([]string{"vim", "big"})
+	stats := registry.LoadStats()
+	if stats.Decoded != 0 || stats.Indexed != 1 || stats.Loaded != stats.Apps-1 {
+		t.Fatalf("indexed load stats = %+v", stats)
+	}
+	page, _ := registry.Page(0, 0, func(info AppInfo) bool { return info.Name == "big" })
+	if len(page) != 1 || page[0].Description != "Big app" || page[0].Shortcuts != 2 {
+		t.Errorf("an indexed app should be described without loading it: %+v", page)
+	}
+	if registry.LoadStats().Loaded != stats.Loaded {
+		t.Error("paging should not load shortcuts")
+	}
+	app, ok := registry.Get("big")
+	if !ok || len(app.Shortcuts) != 2 {
+		t.Fatalf("Get should load the shortcuts, got %+v", app)
+	}
+	if stats := registry.LoadStats(); stats.Loaded != stats.Apps || stats.Decoded != 1 {
+		t.Errorf("stats after Get = %+v", stats)
+	}
+
+	// a changed file is decoded again
+	write("Bigger app")
+	registry = NewRegistry(dir)
+	registry.LoadApps([]string{"big"})
+	if app, _ := registry.Get("big"); registry.LoadStats().Indexed != 0 || app.Description != "Bigger app" {
+		t.Errorf("a changed app should not come from the index: %+v", registry.LoadStats())
+	}
+
+	// searches cover apps registered from the index
+	registry = NewRegistry(dir)
+	registry.LoadAllAppsFromDirectory()
+	if results := registry.SearchShortcuts("second"); len(results) != 1 || results[0].AppName != "big" {
+		t.Errorf("search should load indexed apps, got %+v", results)
+	}
+}
+
+func TestRegistry_Page(t *testing.T) {
+	registry := NewRegistryWithStorage(nil)
+
+	page, total := registry.Page(1, 2, nil)
+	if total != 6 || len(page) != 2 || page[0].Name != "lf" || page[1].Name != "st" {
+		t.Errorf("Page(1, 2) = %+v of %d", page, total)
+	}
+	if page, _ := registry.Page(5, 10, nil); len(page) != 1 || page[0].Name != "zsh" {
+		t.Errorf("the last page should hold the rest, got %+v", page)
+	}
+	if page, _ := registry.Page(10, 2, nil); len(page) != 0 {
+		t.Errorf("a page past the end should be empty, got %+v", page)
+	}
+	page, total = registry.Page(0, 0, func(info AppInfo) bool { return strings.HasPrefix(info.Name, "z") })
+	if total != 2 || len(page) != 2 || page[0].Name != "zathura" {
+		t.Errorf("the filter should apply before paging, got %+v of %d", page, total)
+	}
+}
+
+func TestRegistry_ShowCategories(t *testing.T) {
+	registry := NewRegistryWithStorage(nil)
+	registry.Register(&App{Name: "ed", Description: "Editor", Shortcuts: []Shortcut{
+		{Keys: "w", Description: "word", Category: "Motion"},
+		{Keys: "u", Description: "undo", Category: "editing"},
+		{Keys: "b", Description: "back", Category: "Motion"},
+		{Keys: "ZZ", Description: "save and quit"},
+	}})
+	registry.Register(&App{Name: "sh", Description: "Shell", Shortcuts: []Shortcut{
+		{Keys: "u", Description: "kill line", Category: "history"},
+		{Keys: "r", Description: "reverse search", Category: "history"},
+	}})
+
+	plain := registry.GetTableData([]string{"ed", "sh"})
+	for _, row := range plain {
+		if IsSectionRow(row) {
+			t.Fatalf("sections should be off by default, got %v", row)
+		}
+	}
+
+	registry.SetShowCategories(true)
+	rows := registry.GetTableData([]string{"ed", "sh"})
+	var firsts []string
+	for _, row := range rows[1:] {
+		firsts = append(firsts, row[0])
+	}
+	// u belongs to the category it has in the first app
+	want := []string{"▸ editing", "u", "▸ history", "r", "▸ Motion", "b", "w", "▸ Other", "ZZ"}
+	if !reflect.DeepEqual(firsts, want) {
+		t.Errorf("rows = %v, want %v", firsts, want)
+	}
+	if !IsSectionRow(rows[1]) || len(rows[1]) != len(rows[0]) || IsSectionRow(rows[2]) {
+		t.Errorf("section rows should span the table with empty cells: %q", rows[1])
+	}
+
+	// searches keep the sections of the rows they find
+	found := registry.SearchTableData([]string{"ed", "sh"}, "word")
+	if len(found) != 3 || found[1][0] != "▸ Motion" || found[2][0] != "w" {
+		t.Errorf("search rows = %v", found)
+	}
+}
+
+func TestRegistry_AliasesAndMerged(t *testing.T) {
+	registry := NewRegistryWithStorage(nil)
+	registry.Register(&App{Name: "zsh", Categories: []string{"shell"}, Shortcuts: []Shortcut{
+		{Keys: "ctrl+r", Description: "history search", Tags: []string{"history"}},
+		{Keys: "ctrl+a", Description: "line start"},
+	}})
+	registry.Register(&App{Name: "bash", Categories: []string{"shell"}, Shortcuts: []Shortcut{
+		{Keys: "ctrl+r", Description: "reverse search", Tags: []string{"search"}},
+		{Keys: "ctrl+a", Description: "line start"},
+		{Keys: "ctrl+x ctrl+e", Description: "edit in editor"},
+	}})
+	registry.SetAliases(map[string]string{"z": "zsh", "sh": "shells"})
+	registry.SetMergedApps(map[string][]string{"shells": {"z", "bash"}, "none": {"missing"}})
+
+	if app, ok := registry.Get("z"); !ok || app.Name != "zsh" {
+		t.Errorf("alias z should resolve to zsh, got %v", app)
+	}
+	if _, ok := registry.Get("none"); ok {
+		t.Error("merged app without any loaded member should not resolve")
+	}
+
+	merged, ok := registry.Get("sh")
+	if !ok {
+		t.Fatal("alias of a merged app should resolve")
+	}
+	if merged.Name != "shells" || merged.Description != "zsh + bash" || len(merged.Shortcuts) != 3 {
+		t.Fatalf("merged app = %+v", merged)
+	}
+	first := merged.Shortcuts[0]
+	if first.Description != "history search / reverse search" || !reflect.DeepEqual(first.Tags, []string{"history", "search"}) {
+		t.Errorf("shared shortcut = %+v", first)
+	}
+	if merged.Shortcuts[1].Description != "line start" {
+		t.Errorf("equal descriptions should not repeat, got %q", merged.Shortcuts[1].Description)
+	}
+	if app, _ := registry.Get("zsh"); len(app.Shortcuts[0].Tags) != 1 {
+		t.Errorf("merging should not change the members, got %v", app.Shortcuts[0].Tags)
+	}
+
+	rows := registry.GetTableData([]string{"z", "shells"})
+	if !reflect.DeepEqual(rows[0], []string{"Shortcut", "z", "shells"}) || len(rows) != 4 {
+		t.Errorf("table rows = %v", rows)
+	}
+
+	// registered apps take precedence
+	registry.Register(&App{Name: "z", Description: "jump"})
+	if app, _ := registry.Get("z"); app.Description != "jump" {
+		t.Errorf("registered app should shadow the alias, got %v", app)
+	}
+}
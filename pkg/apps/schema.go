@@ -0,0 +1,311 @@
+package apps
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidPlatforms are the platforms a shortcut may be limited to; shortcuts
+// without a platform apply everywhere
+var ValidPlatforms = []string{"linux", "macos", "windows", "bsd"}
+
+// Problem is something wrong with an app file, found by CheckApp
+type Problem struct {
+	// Line and Column locate the problem in the file, from 1; 0 when the
+	// problem concerns the whole file
+	Line   int
+	Column int
+	// Field is the path of the field at fault, such as
+	// shortcuts[2].platform, or "" for the file
+	Field   string
+	Message string
+	// Warning is set for problems that do not keep the app from loading,
+	// such as unknown keys
+	Warning bool
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", p.Line)
+	}
+	if p.Field != "" {
+		b.WriteString(p.Field + ": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// SchemaError reports the problems keeping an app file from loading
+type SchemaError struct {
+	// Problems are every problem of the file, warnings included
+	Problems []Problem
+	// err is ErrInvalidAppFile for files that are not YAML, and
+	// ErrAppValidation otherwise
+	err error
+}
+
+func (e *SchemaError) Error() string {
+	var errs []string
+	for _, problem := range e.Problems {
+		if !problem.Warning {
+			errs = append(errs, problem.String())
+		}
+	}
+	return fmt.Sprintf("%v: %s", e.err, strings.Join(errs, "; "))
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.err
+}
+
+// schema describes the YAML node expected for a field, in the manner of a
+// JSON schema
+type schema struct {
+	kind     yaml.Kind
+	required bool
+	// nonEmpty rejects empty strings, and warns about empty lists
+	nonEmpty bool
+	// enum lists the values a string may take
+	enum []string
+	// items is the schema of the elements of a sequence
+	items *schema
+	// fields are the keys of a mapping; values, when set, is the schema of
+	// every value of a mapping with keys of any name
+	fields map[string]*schema
+	values *schema
+}
+
+var (
+	stringSchema = &schema{kind: yaml.ScalarNode}
+	stringsList  = &schema{kind: yaml.SequenceNode, items: stringSchema}
+
+	shortcutSchema = &schema{kind: yaml.MappingNode, fields: map[string]*schema{
+		"keys":        {kind: yaml.ScalarNode, required: true, nonEmpty: true},
+		"description": {kind: yaml.ScalarNode, required: true, nonEmpty: true},
+		"category":    stringSchema,
+		"tags":        stringsList,
+		"platform":    {kind: yaml.ScalarNode, enum: ValidPlatforms},
+	}}
+
+	appSchema = &schema{kind: yaml.MappingNode, required: true, fields: map[string]*schema{
+		"name":        {kind: yaml.ScalarNode, required: true, nonEmpty: true},
+		"description": {kind: yaml.ScalarNode, required: true, nonEmpty: true},
+		"categories":  stringsList,
+		"shortcuts":   {kind: yaml.SequenceNode, nonEmpty: true, items: shortcutSchema},
+		"metadata":    {kind: yaml.MappingNode, values: stringSchema},
+		"version":     stringSchema,
+	}}
+
+	overlaySchema = &schema{kind: yaml.MappingNode, fields: map[string]*schema{
+		"shortcuts": {kind: yaml.SequenceNode, items: shortcutSchema},
+	}}
+)
+
+// kindNames name the kinds of YAML nodes in problems
+var kindNames = map[yaml.Kind]string{
+	yaml.ScalarNode:   "a value",
+	yaml.SequenceNode: "a list",
+	yaml.MappingNode:  "a mapping",
+}
+
+// CheckApp reports every problem of the YAML app definition data, sorted
+// by line. Problems that are not warnings keep the app from loading.
+func CheckApp(data []byte) []Problem {
+	return check(data, appSchema)
+}
+
+// CheckOverlay reports every problem of the YAML overlay data, as CheckApp
+// does for apps
+func CheckOverlay(data []byte) []Problem {
+	return check(data, overlaySchema)
+}
+
+// yamlLine finds the line in the errors of the YAML parser
+var yamlLine = regexp.MustCompile(`line (\d+): `)
+
+// check validates data against root, which may only be empty when it is
+// not required
+func check(data []byte, root *schema) []Problem {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			if !root.required {
+				return nil
+			}
+			return []Problem{{Message: "the file is empty"}}
+		}
+		message := strings.TrimPrefix(err.Error(), "yaml: ")
+		problem := Problem{Message: message}
+		if match := yamlLine.FindStringSubmatch(message); match != nil {
+			problem.Line, _ = strconv.Atoi(match[1])
+			problem.Message = strings.Replace(message, match[0], "", 1)
+		}
+		return []Problem{problem}
+	}
+
+	var problems []Problem
+	checkNode(doc.Content[0], root, "", &problems)
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return problems
+}
+
+// checkNode validates node against s, adding what is wrong to problems
+func checkNode(node *yaml.Node, s *schema, field string, problems *[]Problem) {
+	report := func(n *yaml.Node, field string, warning bool, format string, args ...interface{}) {
+		*problems = append(*problems, Problem{
+			Line: n.Line, Column: n.Column, Field: field,
+			Message: fmt.Sprintf(format, args...), Warning: warning,
+		})
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		// a key without a value is as good as an empty one
+		switch {
+		case s.required || s.nonEmpty && s.kind == yaml.ScalarNode:
+			report(node, field, false, "must not be empty")
+		case s.nonEmpty:
+			report(node, field, true, "is empty")
+		}
+		return
+	}
+	if node.Kind != s.kind {
+		report(node, field, false, "must be %s", kindNames[s.kind])
+		return
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if s.nonEmpty && strings.TrimSpace(node.Value) == "" {
+			report(node, field, false, "must not be empty")
+		}
+		if len(s.enum) > 0 && node.Value != "" && !slices.Contains(s.enum, node.Value) {
+			report(node, field, true, "unknown value %q (valid: %s)", node.Value, strings.Join(s.enum, ", "))
+		}
+
+	case yaml.SequenceNode:
+		if s.nonEmpty && len(node.Content) == 0 {
+			report(node, field, true, "is empty")
+		}
+		for i, item := range node.Content {
+			checkNode(item, s.items, fmt.Sprintf("%s[%d]", field, i), problems)
+		}
+
+	case yaml.MappingNode:
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			path := key.Value
+			if field != "" {
+				path = field + "." + key.Value
+			}
+			if seen[key.Value] {
+				report(key, path, false, "is defined twice")
+				continue
+			}
+			seen[key.Value] = true
+
+			child := s.values
+			if s.fields != nil {
+				child = s.fields[key.Value]
+			}
+			if child == nil {
+				report(key, path, true, "unknown key")
+				continue
+			}
+			checkNode(value, child, path, problems)
+		}
+
+		names := make([]string, 0, len(s.fields))
+		for name := range s.fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			path := name
+			if field != "" {
+				path = field + "." + name
+			}
+			switch child := s.fields[name]; {
+			case child.required:
+				report(node, path, false, "is required")
+			case child.nonEmpty:
+				report(node, path, true, "is missing")
+			}
+		}
+	}
+}
+
+// schemaError returns the error of an app file with problems, or nil when
+// none of them keeps it from loading
+func schemaError(problems []Problem) error {
+	for _, problem := range problems {
+		if problem.Warning {
+			continue
+		}
+		err := ErrAppValidation
+		if problem.Field == "" {
+			err = ErrInvalidAppFile
+		}
+		return &SchemaError{Problems: problems, err: err}
+	}
+	return nil
+}
+
+// Diagnosis holds the problems of a stored app or overlay file
+type Diagnosis struct {
+	// Key is the storage key of the file, such as vim or vim.local
+	Key      string
+	Problems []Problem
+}
+
+// Failing reports whether the problems keep the file from loading
+func (d Diagnosis) Failing() bool {
+	return schemaError(d.Problems) != nil
+}
+
+// Diagnose checks every stored app and overlay against the app schema,
+// returning the files with problems sorted by key
+func (r *Registry) Diagnose() ([]Diagnosis, error) {
+	if r.store == nil {
+		return nil, nil
+	}
+	keys, err := r.store.List(storage.CollectionApps)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDirectoryRead, err)
+	}
+
+	var diagnoses []Diagnosis
+	for _, key := range keys {
+		data, err := r.store.Get(storage.CollectionApps, key)
+		if err != nil {
+			diagnoses = append(diagnoses, Diagnosis{Key: key, Problems: []Problem{{Message: err.Error()}}})
+			continue
+		}
+		problems := CheckApp(data)
+		if strings.HasSuffix(key, OverlaySuffix) {
+			problems = CheckOverlay(data)
+		}
+		if len(problems) > 0 {
+			diagnoses = append(diagnoses, Diagnosis{Key: key, Problems: problems})
+		}
+	}
+	return diagnoses, nil
+}
@@ -0,0 +1,118 @@
+package apps
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+func TestCheckApp(t *testing.T) {
+	valid := `name: demo
+description: Demo app
+metadata:
+  source: test
+shortcuts:
+  - keys: gg
+    description: top
+    tags: [motion]
+    platform: linux
+`
+	if problems := CheckApp([]byte(valid)); len(problems) != 0 {
+		t.Errorf("valid app should have no problems, got %v", problems)
+	}
+
+	data := `name: demo
+description:
+author: me
+shortcuts:
+  - keys: gg
+    description: top
+    platform: osx
+  - keys: ""
+    description: [not, a, value]
+  - description: no keys
+    category: x
+    category: y
+`
+	var got []string
+	for _, problem := range CheckApp([]byte(data)) {
+		got = append(got, problem.String())
+	}
+	want := []string{
+		`line 2: description: must not be empty`,
+		`line 3: author: unknown key`,
+		`line 7: shortcuts[0].platform: unknown value "osx" (valid: linux, macos, windows, bsd)`,
+		`line 8: shortcuts[1].keys: must not be empty`,
+		`line 9: shortcuts[1].description: must be a value`,
+		`line 10: shortcuts[2].keys: is required`,
+		`line 12: shortcuts[2].category: is defined twice`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	problems := CheckApp([]byte("name: demo\ndescription: d\n"))
+	if len(problems) != 1 || problems[0].Field != "shortcuts" || !problems[0].Warning {
+		t.Errorf("an app without shortcuts should be warned about, got %v", problems)
+	}
+
+	problems = CheckApp([]byte("name: demo\n  description: [\n"))
+	if len(problems) != 1 || problems[0].Line == 0 || problems[0].Field != "" {
+		t.Errorf("syntax errors should report their line, got %v", problems)
+	}
+
+	if problems := CheckOverlay(nil); len(problems) != 0 {
+		t.Errorf("an empty overlay is valid, got %v", problems)
+	}
+	if problems := CheckApp(nil); len(problems) != 1 {
+		t.Errorf("an empty app file is not, got %v", problems)
+	}
+}
+
+func TestRegistry_SchemaErrors(t *testing.T) {
+	registry := NewRegistryWithStorage(nil)
+
+	_, err := registry.ParseApp([]byte("name: demo\ndescription: d\nshortcuts:\n  - keys: x\n"))
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || !errors.Is(err, ErrAppValidation) {
+		t.Fatalf("expected a SchemaError wrapping ErrAppValidation, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "line 4: shortcuts[0].description: is required") {
+		t.Errorf("error should tell where the problem is, got %v", err)
+	}
+
+	if _, err := registry.ParseApp([]byte("name: [")); !errors.Is(err, ErrInvalidAppFile) {
+		t.Errorf("expected ErrInvalidAppFile for broken YAML, got %v", err)
+	}
+
+	// warnings do not keep an app from loading
+	if _, err := registry.ParseApp([]byte("name: demo\ndescription: d\nauthor: me\n")); err != nil {
+		t.Errorf("unknown keys should only be warned about, got %v", err)
+	}
+}
+
+func TestRegistry_Diagnose(t *testing.T) {
+	store := storage.NewFileStorage(t.TempDir())
+	store.Put(storage.CollectionApps, "good", []byte("name: good\ndescription: d\nshortcuts:\n  - keys: x\n    description: y\n"))
+	store.Put(storage.CollectionApps, "odd", []byte("name: odd\ndescription: d\nshortcuts: []\n"))
+	store.Put(storage.CollectionApps, "bad", []byte("name: bad\n"))
+	store.Put(storage.CollectionApps, "good"+OverlaySuffix, []byte("shortcuts:\n  - keys: z\n"))
+	registry := NewRegistryWithStorage(store)
+
+	diagnoses, err := registry.Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	var keys []string
+	var failing []bool
+	for _, diagnosis := range diagnoses {
+		keys = append(keys, diagnosis.Key)
+		failing = append(failing, diagnosis.Failing())
+	}
+	if !reflect.DeepEqual(keys, []string{"bad", "good.local", "odd"}) || !reflect.DeepEqual(failing, []bool{true, true, false}) {
+		t.Errorf("diagnoses = %v %v", keys, failing)
+	}
+}
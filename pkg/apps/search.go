@@ -0,0 +1,192 @@
+package apps
+
+import (
+	"strings"
+)
+
+// SearchQuery is a search split into column-scoped filters. A shortcut
+// matches when it matches every term and, for each field with values, at
+// least one of them.
+type SearchQuery struct {
+	// Terms must all appear in the keys, description or category
+	Terms []string
+	// Apps limits the table to these app columns
+	Apps []string
+	// Categories match shortcut categories starting with them
+	Categories []string
+	// Keys match shortcut keys containing them, case-sensitively
+	Keys []string
+	// Tags match shortcuts with one of these tags
+	Tags []string
+}
+
+// searchFields maps the prefixes of scoped search words to their field
+var searchFields = map[string]func(q *SearchQuery) *[]string{
+	"app":      func(q *SearchQuery) *[]string { return &q.Apps },
+	"cat":      func(q *SearchQuery) *[]string { return &q.Categories },
+	"category": func(q *SearchQuery) *[]string { return &q.Categories },
+	"key":      func(q *SearchQuery) *[]string { return &q.Keys },
+	"keys":     func(q *SearchQuery) *[]string { return &q.Keys },
+	"tag":      func(q *SearchQuery) *[]string { return &q.Tags },
+	"tags":     func(q *SearchQuery) *[]string { return &q.Tags },
+}
+
+// ParseSearchQuery parses queries like `app:vim cat:navigation move`.
+// Words prefixed with app:, cat:, key: or tag: filter that field and may
+// list several values separated by commas; other words are search terms.
+// Double quotes keep spaces in a term or value, as in `"new window"`.
+func ParseSearchQuery(query string) SearchQuery {
+	var q SearchQuery
+	for _, word := range splitSearchWords(query) {
+		if prefix, value, ok := strings.Cut(word, ":"); ok && value != "" {
+			if field, known := searchFields[strings.ToLower(prefix)]; known {
+				for _, v := range strings.Split(value, ",") {
+					if v = strings.TrimSpace(v); v != "" {
+						*field(&q) = append(*field(&q), v)
+					}
+				}
+				continue
+			}
+		}
+		q.Terms = append(q.Terms, word)
+	}
+	return q
+}
+
+// splitSearchWords splits a query at spaces outside double quotes
+func splitSearchWords(query string) []string {
+	var words []string
+	var word strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ' ' && !quoted:
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// SearchTableDataAdvanced returns the table of the apps among appNames that
+// q allows, limited to the shortcuts matching q
+func (r *Registry) SearchTableDataAdvanced(appNames []string, q SearchQuery) [][]string {
+	return r.tableData(scopeApps(appNames, q.Apps), func(app *App) []bool {
+		return r.matchQuery(app, q)
+	})
+}
+
+// SearchShortcutsAdvanced returns the shortcuts of the apps among appNames
+// that q allows and that match q, in the order of appNames
+func (r *Registry) SearchShortcutsAdvanced(appNames []string, q SearchQuery) []ShortcutResult {
+	var results []ShortcutResult
+	for _, name := range scopeApps(appNames, q.Apps) {
+		app, ok := r.Get(name)
+		if !ok {
+			continue
+		}
+		matched := r.matchQuery(app, q)
+		for i, shortcut := range app.Shortcuts {
+			if !matched[i] {
+				continue
+			}
+			var matches []string
+			for _, term := range q.Terms {
+				matches = appendUnique(matches, r.getSearchMatches(shortcut, strings.ToLower(term))...)
+			}
+			results = append(results, ShortcutResult{AppName: name, Shortcut: shortcut, Matches: matches})
+		}
+	}
+	return results
+}
+
+// appendUnique appends the values not yet in list
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !containsFold(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// scopeApps returns the names among appNames that are in apps, or all of
+// appNames when apps is empty
+func scopeApps(appNames, apps []string) []string {
+	if len(apps) == 0 {
+		return appNames
+	}
+	scoped := []string{}
+	for _, name := range appNames {
+		if containsFold(apps, name) {
+			scoped = append(scoped, name)
+		}
+	}
+	return scoped
+}
+
+// matchQuery returns which shortcuts of app, by position, match the
+// filters of q other than its apps
+func (r *Registry) matchQuery(app *App, q SearchQuery) []bool {
+	ix := r.searchIndexOf(app)
+	matched := ix.matching(lowerAll(q.Terms))
+	categories := lowerAll(q.Categories)
+	for i := range matched {
+		matched[i] = matched[i] && ix.matchesFilters(i, categories, q)
+	}
+	return matched
+}
+
+// matchesFilters reports whether shortcut i matches the categories, keys
+// and tags of q, categories being those of q in lower case
+func (ix *searchIndex) matchesFilters(i int, categories []string, q SearchQuery) bool {
+	shortcut := ix.app.Shortcuts[i]
+	if len(categories) > 0 && !matchesAny(categories, func(prefix string) bool {
+		return strings.HasPrefix(ix.categories[i], prefix)
+	}) {
+		return false
+	}
+
+	if len(q.Keys) > 0 && !matchesAny(q.Keys, func(keys string) bool {
+		return strings.Contains(shortcut.Keys, keys)
+	}) {
+		return false
+	}
+
+	if len(q.Tags) > 0 && !matchesAny(q.Tags, func(tag string) bool {
+		return containsFold(shortcut.Tags, tag)
+	}) {
+		return false
+	}
+
+	return true
+}
+
+// matchesAny reports whether match accepts one of values
+func matchesAny(values []string, match func(string) bool) bool {
+	for _, value := range values {
+		if match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether list holds s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package apps
+
+import (
+	"sort"
+	"strings"
+)
+
+// trigramLen is the length of the substrings the search index is keyed by
+const trigramLen = 3
+
+// searchIndex holds the lower case keys, descriptions and categories of
+// the shortcuts of an app, and which shortcuts contain each trigram of
+// them, so searching neither lower cases every field again nor looks at
+// shortcuts that cannot match
+type searchIndex struct {
+	// app and count tell which shortcuts were indexed, so that shortcuts
+	// changed since are not searched with a stale index
+	app   *App
+	count int
+
+	keys         []string
+	descriptions []string
+	categories   []string
+	// trigrams maps every trigram of the fields to the positions of the
+	// shortcuts containing it, in increasing order
+	trigrams map[string][]int
+}
+
+// newSearchIndex indexes the shortcuts of app
+func newSearchIndex(app *App) *searchIndex {
+	count := len(app.Shortcuts)
+	ix := &searchIndex{
+		app:          app,
+		count:        count,
+		keys:         make([]string, count),
+		descriptions: make([]string, count),
+		categories:   make([]string, count),
+		trigrams:     make(map[string][]int),
+	}
+	for i, shortcut := range app.Shortcuts {
+		ix.keys[i] = strings.ToLower(shortcut.Keys)
+		ix.descriptions[i] = strings.ToLower(shortcut.Description)
+		ix.categories[i] = strings.ToLower(shortcut.Category)
+
+		seen := make(map[string]bool)
+		for _, field := range []string{ix.keys[i], ix.descriptions[i], ix.categories[i]} {
+			for j := 0; j+trigramLen <= len(field); j++ {
+				trigram := field[j : j+trigramLen]
+				if !seen[trigram] {
+					seen[trigram] = true
+					ix.trigrams[trigram] = append(ix.trigrams[trigram], i)
+				}
+			}
+		}
+	}
+	return ix
+}
+
+// fresh reports whether the index still describes the shortcuts of app
+func (ix *searchIndex) fresh(app *App) bool {
+	return ix.app == app && ix.count == len(app.Shortcuts)
+}
+
+// contains reports whether shortcut i holds term, in lower case, in its
+// keys, description or category
+func (ix *searchIndex) contains(i int, term string) bool {
+	return strings.Contains(ix.keys[i], term) ||
+		strings.Contains(ix.descriptions[i], term) ||
+		strings.Contains(ix.categories[i], term)
+}
+
+// matching returns which shortcuts hold every one of terms, in lower case
+func (ix *searchIndex) matching(terms []string) []bool {
+	matched := make([]bool, ix.count)
+	check := func(i int) {
+		for _, term := range terms {
+			if !ix.contains(i, term) {
+				return
+			}
+		}
+		matched[i] = true
+	}
+
+	if candidates, narrowed := ix.candidates(terms); narrowed {
+		for _, i := range candidates {
+			check(i)
+		}
+	} else {
+		for i := range matched {
+			check(i)
+		}
+	}
+	return matched
+}
+
+// candidates returns the shortcuts holding every trigram of terms. Only
+// they can match, but they still need checking as the trigrams may come
+// from different fields. It reports false when no term is long enough to
+// narrow down the shortcuts.
+func (ix *searchIndex) candidates(terms []string) ([]int, bool) {
+	var lists [][]int
+	for _, term := range terms {
+		for j := 0; j+trigramLen <= len(term); j++ {
+			postings := ix.trigrams[term[j:j+trigramLen]]
+			if len(postings) == 0 {
+				return nil, true
+			}
+			lists = append(lists, postings)
+		}
+	}
+	if len(lists) == 0 {
+		return nil, false
+	}
+
+	// the rarest trigrams first, so the candidates shrink quickly
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+	candidates := lists[0]
+	for _, postings := range lists[1:] {
+		if candidates = intersect(candidates, postings); len(candidates) == 0 {
+			break
+		}
+	}
+	return candidates, true
+}
+
+// intersect returns the positions in both increasing lists
+func intersect(a, b []int) []int {
+	both := make([]int, 0, min(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			both = append(both, a[i])
+			i++
+			j++
+		}
+	}
+	return both
+}
+
+// lowerAll returns the terms in lower case
+func lowerAll(terms []string) []string {
+	lower := make([]string, len(terms))
+	for i, term := range terms {
+		lower[i] = strings.ToLower(term)
+	}
+	return lower
+}
+
+// searchIndexOf returns the search index of app, built when it was
+// registered. An app changed since is indexed again for this search only,
+// as searches may run concurrently.
+func (r *Registry) searchIndexOf(app *App) *searchIndex {
+	if ix := r.searchIndexes[app.Name]; ix != nil && ix.fresh(app) {
+		return ix
+	}
+	return newSearchIndex(app)
+}
@@ -0,0 +1,184 @@
+package apps
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  SearchQuery
+	}{
+		{"", SearchQuery{}},
+		{"move", SearchQuery{Terms: []string{"move"}}},
+		{"app:vim cat:navigation move", SearchQuery{Terms: []string{"move"}, Apps: []string{"vim"}, Categories: []string{"navigation"}}},
+		{"APP:vim,zsh tag:window key:gg", SearchQuery{Apps: []string{"vim", "zsh"}, Keys: []string{"gg"}, Tags: []string{"window"}}},
+		{`"new window" category:"window ops"`, SearchQuery{Terms: []string{"new window"}, Categories: []string{"window ops"}}},
+		{"key:: foo:bar app:", SearchQuery{Terms: []string{"foo:bar", "app:"}, Keys: []string{":"}}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseSearchQuery(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSearchQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_SearchTableDataAdvanced(t *testing.T) {
+	registry := NewRegistry("")
+	registry.Register(&App{
+		Name: "tmux",
+		Shortcuts: []Shortcut{
+			{Keys: "C-b c", Description: "new window", Category: "window", Tags: []string{"basics"}},
+			{Keys: "C-b n", Description: "next window", Category: "window"},
+			{Keys: "C-b d", Description: "detach", Category: "session", Tags: []string{"basics"}},
+		},
+	})
+	appNames := []string{"vim", "zsh", "tmux"}
+
+	search := func(query string) [][]string {
+		return registry.SearchTableDataAdvanced(appNames, ParseSearchQuery(query))
+	}
+
+	rows := search("app:vim move")
+	if len(rows[0]) != 2 || rows[0][1] != "vim" {
+		t.Errorf("app:vim should only show the vim column, header = %v", rows[0])
+	}
+	if len(rows) != 5 {
+		t.Errorf("app:vim move returned %d rows, want 4 moves", len(rows)-1)
+	}
+
+	if rows := search("cat:win window"); len(rows) != 3 {
+		t.Errorf("cat:win window returned %d rows, want 2", len(rows)-1)
+	}
+	if rows := search("next window"); len(rows) != 2 || rows[1][0] != "C-b n" {
+		t.Errorf("every term should match, got %v", rows)
+	}
+	if rows := search("tag:basics cat:session"); len(rows) != 2 || rows[1][0] != "C-b d" {
+		t.Errorf("tag:basics cat:session = %v", rows)
+	}
+	if rows := search("key:G"); len(rows) != 2 || rows[1][0] != "G" {
+		t.Errorf("key: should match keys case-sensitively, got %v", rows)
+	}
+	if rows := search("app:nano"); len(rows[0]) != 1 || len(rows) != 1 {
+		t.Errorf("an unknown app should leave no columns, got %v", rows)
+	}
+}
+
+func TestRegistry_SearchShortcutsAdvanced(t *testing.T) {
+	registry := NewRegistry("")
+	registry.Register(&App{
+		Name: "tmux",
+		Shortcuts: []Shortcut{
+			{Keys: "C-b c", Description: "new window", Category: "window"},
+			{Keys: "C-b d", Description: "detach", Category: "session"},
+		},
+	})
+
+	results := registry.SearchShortcutsAdvanced([]string{"tmux", "vim", "missing"}, ParseSearchQuery("window"))
+	if len(results) == 0 || results[0].AppName != "tmux" || results[0].Shortcut.Keys != "C-b c" {
+		t.Fatalf("results should follow the app order, got %+v", results)
+	}
+	if got := strings.Join(results[0].Matches, ","); got != "description,category" {
+		t.Errorf("matches = %s", got)
+	}
+
+	results = registry.SearchShortcutsAdvanced([]string{"tmux", "vim"}, ParseSearchQuery("app:tmux cat:session"))
+	if len(results) != 1 || results[0].Shortcut.Keys != "C-b d" {
+		t.Errorf("scoped search = %+v", results)
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	app := &App{Name: "idx", Shortcuts: []Shortcut{
+		{Keys: "Ctrl+W", Description: "Close Window", Category: "windows"},
+		{Keys: "gg", Description: "Go to top", Category: "navigation"},
+		{Keys: "Ä", Description: "Über alles", Category: "umlauts"},
+		{Keys: "dd", Description: "delete line", Category: "editing"},
+	}}
+	ix := newSearchIndex(app)
+
+	// the index finds what scanning every shortcut finds
+	r := NewRegistryWithStorage(nil)
+	for _, query := range []string{"", "w", "wi", "win", "WINDOW", "close win", "ctrl+", "gg", "top",
+		"go to", "ü", "über", "ÜBER", "navigation", "nav", "line", "xyz", "window windows", "e"} {
+		var want []bool
+		for _, shortcut := range app.Shortcuts {
+			want = append(want, r.shortcutMatches(shortcut, query))
+		}
+		if got := ix.matching([]string{strings.ToLower(query)}); !reflect.DeepEqual(got, want) {
+			t.Errorf("matching(%q) = %v, want %v", query, got, want)
+		}
+	}
+
+	// every term must match, possibly in different fields
+	if got := ix.matching([]string{"close", "windows"}); !reflect.DeepEqual(got, []bool{true, false, false, false}) {
+		t.Errorf("matching(close, windows) = %v", got)
+	}
+
+	// a registered app is searched with the index built when registering it
+	r.Register(app)
+	if r.searchIndexOf(app) != r.searchIndexes["idx"] {
+		t.Error("the index built at registration should be used")
+	}
+	app.Shortcuts = append(app.Shortcuts, Shortcut{Keys: "x", Description: "added later"})
+	if rows := r.SearchTableData([]string{"idx"}, "later"); len(rows) != 2 {
+		t.Errorf("shortcuts added after registration should be found, got %v", rows)
+	}
+}
+
+// benchmarkRegistry registers apps holding 12000 shortcuts in all
+func benchmarkRegistry() (*Registry, []string) {
+	r := NewRegistryWithStorage(nil)
+	words := []string{"move", "window", "split", "buffer", "search", "delete", "yank", "scroll", "tab", "pane"}
+	var names []string
+	for a := 0; a < 12; a++ {
+		app := &App{Name: fmt.Sprintf("app%d", a), Description: "Benchmark app"}
+		for s := 0; s < 1000; s++ {
+			app.Shortcuts = append(app.Shortcuts, Shortcut{
+				Keys: fmt.Sprintf("Ctrl+%d", s),
+				Description: fmt.Sprintf("%s the %s to the %s number %d", strings.ToUpper(words[s%10][:1])+words[s%10][1:],
+					words[(s/10)%10], words[(s/100)%10], s),
+				Category: words[(s+a)%10],
+			})
+		}
+		r.Register(app)
+		names = append(names, app.Name)
+	}
+	return r, names
+}
+
+func BenchmarkSearchTableData(b *testing.B) {
+	r, names := benchmarkRegistry()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.SearchTableData(names, "Number 42")
+	}
+}
+
+// BenchmarkSearchTableData_Scan searches as before the index, lower casing
+// every field of every shortcut, for comparison
+func BenchmarkSearchTableData_Scan(b *testing.B) {
+	r, names := benchmarkRegistry()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.tableData(names, func(app *App) []bool {
+			matched := make([]bool, len(app.Shortcuts))
+			for s, shortcut := range app.Shortcuts {
+				matched[s] = r.shortcutMatches(shortcut, "Number 42")
+			}
+			return matched
+		})
+	}
+}
+
+func BenchmarkFilterTableData(b *testing.B) {
+	r, names := benchmarkRegistry()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.FilterTableData(names, "cat:win split", nil)
+	}
+}
@@ -19,6 +19,43 @@ type Shortcut struct {
 	Platform    string   `yaml:"platform,omitempty" json:"platform,omitempty"`
 }
 
+// Overlay adds or overrides individual shortcuts of an app without
+// replacing its definition. Overlays are kept as <app>.local.yaml.
+type Overlay struct {
+	Shortcuts []Shortcut `yaml:"shortcuts" json:"shortcuts"`
+}
+
+// WithOverlay returns a copy of the app with the shortcuts of overlay merged
+// in. An overlay shortcut replaces the shortcut with the same keys and
+// platform, inheriting its category and tags when it sets none; other
+// overlay shortcuts are appended.
+func (a *App) WithOverlay(overlay *Overlay) *App {
+	merged := *a
+	merged.Shortcuts = append([]Shortcut{}, a.Shortcuts...)
+
+	for _, shortcut := range overlay.Shortcuts {
+		replaced := false
+		for i, existing := range merged.Shortcuts {
+			if existing.Keys != shortcut.Keys || existing.Platform != shortcut.Platform {
+				continue
+			}
+			if shortcut.Category == "" {
+				shortcut.Category = existing.Category
+			}
+			if len(shortcut.Tags) == 0 {
+				shortcut.Tags = existing.Tags
+			}
+			merged.Shortcuts[i] = shortcut
+			replaced = true
+			break
+		}
+		if !replaced {
+			merged.Shortcuts = append(merged.Shortcuts, shortcut)
+		}
+	}
+	return &merged
+}
+
 // AppRegistry holds all registered applications
 type AppRegistry struct {
 	apps map[string]*App
@@ -36,6 +73,11 @@ func (r *AppRegistry) Register(app *App) {
 	r.apps[app.Name] = app
 }
 
+// Unregister removes an app from the registry
+func (r *AppRegistry) Unregister(name string) {
+	delete(r.apps, name)
+}
+
 // Get retrieves an app by name
 func (r *AppRegistry) Get(name string) (*App, bool) {
 	app, exists := r.apps[name]
@@ -2,13 +2,18 @@ package cache
 
 import (
 	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/lock"
 )
 
 var (
@@ -16,6 +21,33 @@ var (
 	ErrExpired   = errors.New("cache entry expired")
 )
 
+// Namespaces group the keys of one subsystem so they can be cleared together
+const (
+	NamespaceOnline   = "online"
+	NamespaceRegistry = "registry"
+)
+
+// Key returns key within namespace
+func Key(namespace, key string) string {
+	return namespace + ":" + key
+}
+
+// namespaceOf returns the namespace of key, or "" when it has none. Only
+// lowercase letters, digits, - and _ are accepted so the namespace is safe to
+// use in file names.
+func namespaceOf(key string) string {
+	namespace, _, found := strings.Cut(key, ":")
+	if !found || namespace == "" {
+		return ""
+	}
+	for _, r := range namespace {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return ""
+		}
+	}
+	return namespace
+}
+
 type Entry struct {
 	Key        string      `json:"key"`
 	Value      interface{} `json:"value"`
@@ -29,6 +61,7 @@ type Cache interface {
 	Set(key string, value interface{}, ttl time.Duration) error
 	Delete(key string) error
 	Clear() error
+	ClearNamespace(namespace string) error
 	Stats() CacheStats
 }
 
@@ -164,6 +197,20 @@ func (c *LRUCache) Clear() error {
 	return nil
 }
 
+// ClearNamespace removes every entry within namespace
+func (c *LRUCache) ClearNamespace(namespace string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, elem := range c.items {
+		if namespaceOf(key) == namespace {
+			c.removeElement(elem)
+		}
+	}
+
+	return nil
+}
+
 func (c *LRUCache) Stats() CacheStats {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -304,7 +351,7 @@ func (f *FileCache) Set(key string, value interface{}, ttl time.Duration) error
 	}
 
 	filePath := f.getFilePath(key)
-	return os.WriteFile(filePath, data, 0644)
+	return lock.ReplaceFile(filePath, data, 0644)
 }
 
 func (f *FileCache) Delete(key string) error {
@@ -341,6 +388,25 @@ func (f *FileCache) Clear() error {
 	return nil
 }
 
+// ClearNamespace removes every entry within namespace
+func (f *FileCache) ClearNamespace(namespace string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := os.ReadDir(f.cacheDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if ns, ok := fileNamespace(entry.Name()); ok && ns != "" && ns == namespace && !entry.IsDir() {
+			os.Remove(filepath.Join(f.cacheDir, entry.Name()))
+		}
+	}
+
+	return nil
+}
+
 func (f *FileCache) Stats() CacheStats {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
@@ -361,9 +427,49 @@ func (f *FileCache) Stats() CacheStats {
 	return f.stats
 }
 
+// TTL returns the age after which entries expire
+func (f *FileCache) TTL() time.Duration {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.ttl
+}
+
+// SetTTL changes the age after which entries expire
+func (f *FileCache) SetTTL(ttl time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ttl = ttl
+}
+
+// fileNamespace returns the namespace of the entry file name, reporting
+// false for a file getFilePath does not name. As namespaces may contain -,
+// the namespace is all that comes before the hash rather than before the
+// first -.
+func fileNamespace(name string) (string, bool) {
+	rest, ok := strings.CutSuffix(name, ".cache")
+	if !ok || len(rest) < sha256.Size*2 {
+		return "", false
+	}
+	namespace, hash := rest[:len(rest)-sha256.Size*2], rest[len(rest)-sha256.Size*2:]
+	if _, err := hex.DecodeString(hash); err != nil || strings.ToLower(hash) != hash {
+		return "", false
+	}
+	if namespace == "" {
+		return "", true
+	}
+	namespace, ok = strings.CutSuffix(namespace, "-")
+	return namespace, ok && namespaceOf(namespace+":") == namespace
+}
+
+// getFilePath names the file of key after its SHA-256 hash, prefixed with
+// the key's namespace so a namespace can be cleared without reading entries
 func (f *FileCache) getFilePath(key string) string {
-	// Use a simple hash for the filename
-	return filepath.Join(f.cacheDir, fmt.Sprintf("%x.cache", key))
+	hash := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(hash[:]) + ".cache"
+	if namespace := namespaceOf(key); namespace != "" {
+		name = namespace + "-" + name
+	}
+	return filepath.Join(f.cacheDir, name)
 }
 
 func (f *FileCache) cleanupLoop() {
@@ -376,36 +482,47 @@ func (f *FileCache) cleanupLoop() {
 }
 
 func (f *FileCache) cleanup() {
+	f.Prune()
+}
+
+// Prune removes entries older than the cache TTL and returns how many were
+// removed
+func (f *FileCache) Prune() (int, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
 	entries, err := os.ReadDir(f.cacheDir)
 	if err != nil {
-		return
+		return 0, err
 	}
 
 	now := time.Now()
+	removed := 0
 
 	for _, entry := range entries {
 		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".cache" {
 			filePath := filepath.Join(f.cacheDir, entry.Name())
 			if info, err := entry.Info(); err == nil {
 				if now.Sub(info.ModTime()) > f.ttl {
-					os.Remove(filePath)
-					f.stats.Evictions++
+					if os.Remove(filePath) == nil {
+						removed++
+						f.stats.Evictions++
+					}
 				}
 			}
 		}
 	}
 
 	f.stats.LastClean = now
+	return removed, nil
 }
 
 // MultiLevelCache combines memory and file caches for optimal performance
 type MultiLevelCache struct {
-	memory *LRUCache
-	file   *FileCache
-	mu     sync.RWMutex
+	memory    *LRUCache
+	file      *FileCache
+	memoryTTL time.Duration
+	mu        sync.RWMutex
 }
 
 func NewMultiLevelCache(memSize int64, memItems int, cacheDir string, ttl time.Duration) (*MultiLevelCache, error) {
@@ -415,8 +532,9 @@ func NewMultiLevelCache(memSize int64, memItems int, cacheDir string, ttl time.D
 	}
 
 	return &MultiLevelCache{
-		memory: NewLRUCache(memSize, memItems),
-		file:   fileCache,
+		memory:    NewLRUCache(memSize, memItems),
+		file:      fileCache,
+		memoryTTL: 5 * time.Minute,
 	}, nil
 }
 
@@ -433,13 +551,16 @@ func (m *MultiLevelCache) Get(key string) (interface{}, error) {
 	}
 
 	// Promote to memory cache
-	m.memory.Set(key, value, 5*time.Minute)
+	m.memory.Set(key, value, m.MemoryTTL())
 
 	return value, nil
 }
 
 func (m *MultiLevelCache) Set(key string, value interface{}, ttl time.Duration) error {
 	// Write to both caches
+	if memoryTTL := m.MemoryTTL(); ttl > memoryTTL {
+		ttl = memoryTTL
+	}
 	if err := m.memory.Set(key, value, ttl); err != nil {
 		// Memory cache failure is not critical
 		fmt.Printf("Warning: failed to set memory cache: %v\n", err)
@@ -458,6 +579,12 @@ func (m *MultiLevelCache) Clear() error {
 	return m.file.Clear()
 }
 
+// ClearNamespace removes every entry within namespace from both layers
+func (m *MultiLevelCache) ClearNamespace(namespace string) error {
+	m.memory.ClearNamespace(namespace)
+	return m.file.ClearNamespace(namespace)
+}
+
 func (m *MultiLevelCache) Stats() CacheStats {
 	memStats := m.memory.Stats()
 	fileStats := m.file.Stats()
@@ -471,3 +598,47 @@ func (m *MultiLevelCache) Stats() CacheStats {
 		LastClean: memStats.LastClean,
 	}
 }
+
+// MemoryStats returns the statistics of the memory layer
+func (m *MultiLevelCache) MemoryStats() CacheStats {
+	return m.memory.Stats()
+}
+
+// FileStats returns the statistics of the file layer
+func (m *MultiLevelCache) FileStats() CacheStats {
+	return m.file.Stats()
+}
+
+// ClearMemory empties the memory layer only
+func (m *MultiLevelCache) ClearMemory() error {
+	return m.memory.Clear()
+}
+
+// ClearFile empties the file layer only
+func (m *MultiLevelCache) ClearFile() error {
+	return m.file.Clear()
+}
+
+// MemoryTTL returns how long entries are kept in memory
+func (m *MultiLevelCache) MemoryTTL() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.memoryTTL
+}
+
+// SetMemoryTTL changes how long entries are kept in memory
+func (m *MultiLevelCache) SetMemoryTTL(ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.memoryTTL = ttl
+}
+
+// FileTTL returns the age after which file entries expire
+func (m *MultiLevelCache) FileTTL() time.Duration {
+	return m.file.TTL()
+}
+
+// SetFileTTL changes the age after which file entries expire
+func (m *MultiLevelCache) SetFileTTL(ttl time.Duration) {
+	m.file.SetTTL(ttl)
+}
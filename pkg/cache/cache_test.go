@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -626,10 +627,101 @@ func TestFileCache_GetFilePath(t *testing.T) {
 	cache, _ := NewFileCache(tempDir, 1*time.Hour)
 
 	path := cache.getFilePath("test")
-	expectedPath := filepath.Join(tempDir, "74657374.cache")
+	expectedPath := filepath.Join(tempDir, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08.cache")
 	if path != expectedPath {
 		t.Errorf("Expected %s, got %s", expectedPath, path)
 	}
+
+	path = cache.getFilePath(Key(NamespaceOnline, "test"))
+	if filepath.Dir(path) != tempDir || !strings.HasPrefix(filepath.Base(path), "online-") {
+		t.Errorf("Expected a namespaced file in %s, got %s", tempDir, path)
+	}
+	if path = cache.getFilePath("Bad/NS:test"); strings.Contains(filepath.Base(path), "-") {
+		t.Errorf("Invalid namespaces should not be used in file names, got %s", path)
+	}
+}
+
+func TestFileCache_LongKeys(t *testing.T) {
+	cache, _ := NewFileCache(t.TempDir(), time.Hour)
+
+	long := strings.Repeat("k", 1000)
+	if err := cache.Set(long, "long", time.Hour); err != nil {
+		t.Fatalf("Set of a long key failed: %v", err)
+	}
+	if value, err := cache.Get(long); err != nil || value != "long" {
+		t.Errorf("Get of a long key = %v, %v", value, err)
+	}
+	if err := cache.Set(long+"2", "other", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := cache.Get(long); value != "long" {
+		t.Errorf("Keys with a common prefix should not collide, got %v", value)
+	}
+}
+
+func TestCache_ClearNamespace(t *testing.T) {
+	multi, err := NewMultiLevelCache(1024*1024, 100, t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caches := map[string]Cache{
+		"lru":   NewLRUCache(1024*1024, 100),
+		"multi": multi,
+	}
+	for name, c := range caches {
+		c.Set(Key(NamespaceOnline, "a"), "1", time.Hour)
+		c.Set(Key(NamespaceRegistry, "a"), "2", time.Hour)
+		c.Set("plain", "3", time.Hour)
+
+		if err := c.ClearNamespace(NamespaceOnline); err != nil {
+			t.Fatalf("%s: ClearNamespace failed: %v", name, err)
+		}
+		if _, err := c.Get(Key(NamespaceOnline, "a")); err == nil {
+			t.Errorf("%s: online entry should be cleared", name)
+		}
+		if _, err := c.Get(Key(NamespaceRegistry, "a")); err != nil {
+			t.Errorf("%s: registry entry should be kept: %v", name, err)
+		}
+		if _, err := c.Get("plain"); err != nil {
+			t.Errorf("%s: entry without namespace should be kept: %v", name, err)
+		}
+	}
+
+	// the file layer alone must also be cleared
+	multi.Set(Key(NamespaceOnline, "b"), "4", time.Hour)
+	multi.ClearMemory()
+	multi.ClearNamespace(NamespaceOnline)
+	if _, err := multi.Get(Key(NamespaceOnline, "b")); err == nil {
+		t.Error("online entry should be cleared from the file layer")
+	}
+}
+
+func TestFileCache_ClearNamespacePrefix(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set(Key("a", "x"), "1", time.Hour)
+	cache.Set(Key("a-b", "x"), "2", time.Hour)
+
+	// clearing a must keep a-b, whose files start with a- as well
+	if err := cache.ClearNamespace("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Get(Key("a", "x")); err == nil {
+		t.Error("the entry of a should be cleared")
+	}
+	if value, err := cache.Get(Key("a-b", "x")); err != nil || value != "2" {
+		t.Errorf("the entry of a-b should be kept, got %v, %v", value, err)
+	}
+
+	if err := cache.ClearNamespace("a-b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Get(Key("a-b", "x")); err == nil {
+		t.Error("the entry of a-b should be cleared")
+	}
 }
 
 func TestFileCache_Cleanup(t *testing.T) {
@@ -659,6 +751,27 @@ func TestFileCache_Cleanup(t *testing.T) {
 	}
 }
 
+func TestFileCache_Prune(t *testing.T) {
+	tempDir := t.TempDir()
+	cache, _ := NewFileCache(tempDir, time.Hour)
+
+	cache.Set("fresh", "value", time.Hour)
+	cache.Set("stale", "value", time.Hour)
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(cache.getFilePath("stale"), old, old)
+
+	removed, err := cache.Prune()
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Prune() removed %d entries, want 1", removed)
+	}
+	if _, err := cache.Get("fresh"); err != nil {
+		t.Errorf("Fresh entry should be kept: %v", err)
+	}
+}
+
 func TestFileCache_CorruptedFile(t *testing.T) {
 	tempDir := t.TempDir()
 	cache, _ := NewFileCache(tempDir, 1*time.Hour)
@@ -929,3 +1042,34 @@ func BenchmarkFileCache_Get(b *testing.B) {
 		cache.Get(string(rune(i % 100)))
 	}
 }
+
+func TestMultiLevelCache_Layers(t *testing.T) {
+	cache, err := NewMultiLevelCache(1024*1024, 100, t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewMultiLevelCache() error = %v", err)
+	}
+
+	cache.Set("key", "value", time.Hour)
+	if cache.MemoryStats().Items != 1 || cache.FileStats().Items != 1 {
+		t.Fatalf("Expected the entry in both layers, got %+v and %+v", cache.MemoryStats(), cache.FileStats())
+	}
+
+	cache.ClearMemory()
+	if cache.MemoryStats().Items != 0 || cache.FileStats().Items != 1 {
+		t.Error("ClearMemory should only empty the memory layer")
+	}
+	if _, err := cache.Get("key"); err != nil {
+		t.Errorf("Entry should be read back from the file layer: %v", err)
+	}
+
+	cache.SetMemoryTTL(time.Minute)
+	cache.SetFileTTL(2 * time.Hour)
+	if cache.MemoryTTL() != time.Minute || cache.FileTTL() != 2*time.Hour {
+		t.Errorf("TTLs = %v, %v", cache.MemoryTTL(), cache.FileTTL())
+	}
+
+	cache.ClearFile()
+	if cache.FileStats().Items != 0 {
+		t.Error("ClearFile should empty the file layer")
+	}
+}
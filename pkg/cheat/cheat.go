@@ -0,0 +1,169 @@
+package cheat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/remuscazacu/cheat-go/internal/setup"
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+// APIVersion is the semantic version of the embedding API
+const APIVersion = "1.0.0"
+
+var (
+	// ErrAppNotFound is returned for apps that are not configured
+	ErrAppNotFound = apps.ErrAppNotFound
+	// ErrReadOnly is returned when changing notes of a read-only library
+	ErrReadOnly = notes.ErrReadOnly
+)
+
+type (
+	// App is an application with its shortcuts
+	App = apps.App
+	// Shortcut is a single keyboard shortcut of an app
+	Shortcut = apps.Shortcut
+	// Note is a personal note
+	Note = notes.Note
+	// NoteQuery filters and sorts notes
+	NoteQuery = notes.SearchOptions
+	// NoteManager creates, edits and searches notes
+	NoteManager = notes.Manager
+)
+
+// Options controls how a Library is opened. The zero value loads the
+// configuration from the default locations.
+type Options struct {
+	// ConfigFile is the configuration file to load
+	ConfigFile string
+	// DataDir overrides the configured data directory
+	DataDir string
+	// Apps overrides the configured list of apps
+	Apps []string
+	// ReadOnly rejects changes to notes
+	ReadOnly bool
+}
+
+// Result is a shortcut matching a search
+type Result struct {
+	AppName  string
+	Shortcut Shortcut
+	// Matches lists the fields that matched: keys, description or category
+	Matches []string
+}
+
+// Library gives access to the apps and notes of a cheat-go data directory
+type Library struct {
+	registry *apps.Registry
+	notes    *notes.FileManager
+	store    storage.Storage
+	apps     []string
+}
+
+// Open loads the configuration and opens the user data it points to
+func Open(opts Options) (*Library, error) {
+	cfg, err := config.NewLoader(opts.ConfigFile).Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if opts.DataDir != "" {
+		cfg.DataDir = opts.DataDir
+	}
+	if opts.Apps != nil {
+		cfg.Apps = opts.Apps
+	}
+
+	store, err := setup.OpenStorage(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	manager, err := notes.NewStorageManager(store)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	manager.SetReadOnly(opts.ReadOnly)
+
+	registry, err := setup.NewRegistry(cfg, store)
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to load cheat sheets: %w", err)
+	}
+	registry.SetAliases(cfg.Aliases)
+	registry.SetMergedApps(cfg.Merged)
+	if err := registry.LoadApps(cfg.Apps); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to load apps: %w", err)
+	}
+
+	lib := &Library{registry: registry, notes: manager, store: store}
+	for _, name := range cfg.Apps {
+		if _, ok := registry.Get(name); ok {
+			lib.apps = append(lib.apps, name)
+		}
+	}
+	return lib, nil
+}
+
+// Close releases the underlying storage
+func (l *Library) Close() error {
+	return l.store.Close()
+}
+
+// Apps returns the names of the configured apps in configuration order
+func (l *Library) Apps() []string {
+	return append([]string(nil), l.apps...)
+}
+
+// App returns a configured app by name
+func (l *Library) App(name string) (*App, error) {
+	for _, configured := range l.apps {
+		if configured == name {
+			app, _ := l.registry.Get(name)
+			return app, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrAppNotFound, name)
+}
+
+// Search returns the shortcuts of the configured apps whose keys, description
+// or category contain query, ignoring case. Results follow configuration
+// order, then the order of shortcuts within each app.
+func (l *Library) Search(query string) []Result {
+	order := make(map[string]int, len(l.apps))
+	for i, name := range l.apps {
+		order[name] = i
+	}
+
+	var results []Result
+	for _, found := range l.registry.SearchShortcuts(query) {
+		if _, ok := order[found.AppName]; !ok {
+			continue
+		}
+		results = append(results, Result{
+			AppName:  found.AppName,
+			Shortcut: found.Shortcut,
+			Matches:  found.Matches,
+		})
+	}
+
+	// shortcuts of one app are already in order and contiguous
+	sort.SliceStable(results, func(i, j int) bool {
+		return order[results[i].AppName] < order[results[j].AppName]
+	})
+	return results
+}
+
+// Notes returns the notes manager
+func (l *Library) Notes() NoteManager {
+	return l.notes
+}
+
+// SearchNotes returns the notes matching query
+func (l *Library) SearchNotes(query NoteQuery) ([]*Note, error) {
+	return l.notes.SearchNotes(query)
+}
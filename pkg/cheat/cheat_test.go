@@ -0,0 +1,135 @@
+package cheat
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/remuscazacu/cheat-go/pkg/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openTestLibrary opens a library on a temporary data directory
+func openTestLibrary(t *testing.T, opts Options) *Library {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.Apps = []string{"vim", "zsh"}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(opts.ConfigFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lib, err := Open(opts)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { lib.Close() })
+	return lib
+}
+
+func TestLibrary_Apps(t *testing.T) {
+	lib := openTestLibrary(t, Options{Apps: []string{"zsh", "missing", "vim"}})
+
+	names := lib.Apps()
+	if len(names) != 2 || names[0] != "zsh" || names[1] != "vim" {
+		t.Errorf("expected [zsh vim], got %v", names)
+	}
+
+	app, err := lib.App("vim")
+	if err != nil {
+		t.Fatalf("App failed: %v", err)
+	}
+	if len(app.Shortcuts) == 0 {
+		t.Error("expected vim shortcuts")
+	}
+	if _, err := lib.App("missing"); !errors.Is(err, ErrAppNotFound) {
+		t.Errorf("expected ErrAppNotFound, got %v", err)
+	}
+	if _, err := lib.App("dwm"); !errors.Is(err, ErrAppNotFound) {
+		t.Errorf("unconfigured apps should not be returned, got %v", err)
+	}
+}
+
+func TestLibrary_Search(t *testing.T) {
+	lib := openTestLibrary(t, Options{})
+
+	results := lib.Search("search")
+	if len(results) == 0 {
+		t.Fatal("expected results for 'search'")
+	}
+	seenZsh := false
+	for _, result := range results {
+		switch result.AppName {
+		case "vim":
+			if seenZsh {
+				t.Error("results should follow configuration order")
+			}
+		case "zsh":
+			seenZsh = true
+		default:
+			t.Errorf("unexpected app %q", result.AppName)
+		}
+		if len(result.Matches) == 0 {
+			t.Errorf("expected matched fields for %q", result.Shortcut.Keys)
+		}
+	}
+
+	if results := lib.Search("no shortcut matches this"); len(results) != 0 {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+}
+
+func TestLibrary_Notes(t *testing.T) {
+	lib := openTestLibrary(t, Options{})
+
+	note := &Note{Title: "Window splits", Content: "Ctrl+w s", AppName: "vim"}
+	if err := lib.Notes().CreateNote(note); err != nil {
+		t.Fatalf("CreateNote failed: %v", err)
+	}
+
+	found, err := lib.SearchNotes(NoteQuery{Query: "splits"})
+	if err != nil {
+		t.Fatalf("SearchNotes failed: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != note.ID {
+		t.Errorf("expected the created note, got %v", found)
+	}
+}
+
+func TestLibrary_ReadOnly(t *testing.T) {
+	lib := openTestLibrary(t, Options{ReadOnly: true})
+
+	err := lib.Notes().CreateNote(&Note{Title: "denied"})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestOpen_BrokenCheatSheet(t *testing.T) {
+	cheatDir := t.TempDir()
+	os.WriteFile(filepath.Join(cheatDir, "tar"), []byte("---\ntags: [archive\n"), 0644)
+
+	cfg := config.DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.CheatPaths = []string{cheatDir}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if lib, err := Open(Options{ConfigFile: configFile}); err == nil {
+		lib.Close()
+		t.Error("Open should report a cheat sheet it cannot read")
+	}
+}
@@ -0,0 +1,31 @@
+// Package cheat is the supported API for embedding cheat-go data access in
+// other Go programs, such as prompt frameworks and editor plugins. It is
+// imported as github.com/remuscazacu/cheat-go/pkg/cheat.
+//
+// A Library opens the user's data directory with the same storage backend
+// and app layering as the cheat-go binary, and exposes the configured apps,
+// shortcut search and personal notes. Unlike the binary, it does not apply
+// profiles or project configurations, and fails rather than warn when data
+// cannot be read:
+//
+//	lib, err := cheat.Open(cheat.Options{ReadOnly: true})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer lib.Close()
+//
+//	for _, result := range lib.Search("split") {
+//		fmt.Println(result.AppName, result.Shortcut.Keys)
+//	}
+//
+// # Stability
+//
+// This package follows semantic versioning, tracked by APIVersion. Within a
+// major version, exported identifiers of this package are not removed or
+// changed incompatibly; new fields, methods and functions may be added in
+// minor versions. App, Shortcut, Note, NoteQuery and NoteManager are covered
+// by the same guarantee even though they are defined in other packages.
+//
+// Every other package under pkg/ is an implementation detail of the cheat-go
+// binary and may change in any release.
+package cheat
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownKey   = errors.New("unknown configuration key")
+	ErrInvalidValue = errors.New("invalid configuration value")
+)
+
+// Get returns the setting at a dotted key such as "layout.table_style", as
+// written in the config file. Lists and sections are returned as YAML and
+// unset values as an empty string.
+func (c *Config) Get(key string) (string, error) {
+	s, err := c.setting(key)
+	if err != nil {
+		return "", err
+	}
+	if !s.value.IsValid() {
+		return "", nil
+	}
+
+	data, err := yaml.Marshal(s.value.Interface())
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSuffix(string(data), "\n")
+	if value == `""` || value == "null" || value == "[]" || value == "{}" {
+		return "", nil
+	}
+	return value, nil
+}
+
+// Display returns the setting at a dotted key for a message on one line:
+// lists of strings are joined by commas, as Set accepts them, and other
+// values are returned as by Get.
+func (c *Config) Display(key string) (string, error) {
+	s, err := c.setting(key)
+	if err != nil {
+		return "", err
+	}
+	if s.value.IsValid() && s.typ.Kind() == reflect.Slice && s.typ.Elem().Kind() == reflect.String {
+		items := make([]string, s.value.Len())
+		for i := range items {
+			items[i] = s.value.Index(i).String()
+		}
+		return strings.Join(items, ", "), nil
+	}
+	return c.Get(key)
+}
+
+// Set changes the setting at a dotted key, parsing value as YAML. Lists
+// may also be given comma-separated, as in "vim,zsh". The result is not
+// validated; call Validate before saving it.
+func (c *Config) Set(key, value string) error {
+	s, err := c.setting(key)
+	if err != nil {
+		return err
+	}
+
+	parsed := reflect.New(s.typ)
+	switch {
+	case s.typ.Kind() == reflect.String:
+		parsed.Elem().SetString(value)
+	case s.typ.Kind() == reflect.Slice && s.typ.Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(value), "["):
+		items := reflect.MakeSlice(s.typ, 0, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = reflect.Append(items, reflect.ValueOf(item).Convert(s.typ.Elem()))
+			}
+		}
+		parsed.Elem().Set(items)
+	default:
+		if err := yaml.Unmarshal([]byte(value), parsed.Interface()); err != nil {
+			return fmt.Errorf("%w for %s: %v", ErrInvalidValue, key, err)
+		}
+	}
+
+	s.store(parsed.Elem())
+	return nil
+}
+
+// setting is a configuration value found by its dotted key
+type setting struct {
+	// value is the current value, or the zero Value for a missing map entry
+	value reflect.Value
+	typ   reflect.Type
+	store func(reflect.Value)
+}
+
+// setting finds the value of a dotted key by the yaml names of the fields;
+// the last part of a key may name an entry of a map such as keybinds
+func (c *Config) setting(key string) (setting, error) {
+	if key == "" {
+		return setting{}, fmt.Errorf("%w: empty key", ErrUnknownKey)
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		switch {
+		case v.Kind() == reflect.Struct:
+			next, ok := structField(v, part)
+			if !ok {
+				return setting{}, fmt.Errorf("%w: %s", ErrUnknownKey, key)
+			}
+			v = next
+		case v.Kind() == reflect.Map && i == len(parts)-1:
+			m := v
+			k := reflect.ValueOf(part).Convert(m.Type().Key())
+			return setting{
+				value: m.MapIndex(k),
+				typ:   m.Type().Elem(),
+				store: func(value reflect.Value) {
+					if m.IsNil() {
+						m.Set(reflect.MakeMap(m.Type()))
+					}
+					m.SetMapIndex(k, value)
+				},
+			}, nil
+		default:
+			return setting{}, fmt.Errorf("%w: %s", ErrUnknownKey, key)
+		}
+	}
+	return setting{value: v, typ: v.Type(), store: v.Set}, nil
+}
+
+// structField returns the field of v whose yaml name is name
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if tag == name && t.Field(i).IsExported() {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
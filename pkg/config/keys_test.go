@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfig_GetSet(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if v, err := cfg.Get("layout.table_style"); err != nil || v != "simple" {
+		t.Errorf("Get(layout.table_style) = %q, %v", v, err)
+	}
+	if v, _ := cfg.Get("sync.endpoint"); v != "" {
+		t.Errorf("unset values should be empty, got %q", v)
+	}
+	if v, _ := cfg.Get("apps"); v != "- vim\n- zsh\n- dwm\n- st\n- lf\n- zathura" {
+		t.Errorf("lists should be printed as YAML, got %q", v)
+	}
+
+	sets := []struct{ key, value string }{
+		{"theme", "dark"},
+		{"apps", "vim, tmux"},
+		{"layout.max_width", "80"},
+		{"sync.enabled", "true"},
+		{"sync.interval", "15m"},
+		{"network.retries", "5"},
+		{"keybinds.search", "ctrl+f"},
+		{"plugins.disabled", "[a, b]"},
+	}
+	for _, s := range sets {
+		if err := cfg.Set(s.key, s.value); err != nil {
+			t.Fatalf("Set(%s, %s) error = %v", s.key, s.value, err)
+		}
+	}
+	if cfg.Theme != "dark" || len(cfg.Apps) != 2 || cfg.Apps[1] != "tmux" || cfg.Layout.MaxWidth != 80 {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if !cfg.Sync.Enabled || cfg.Sync.Interval != 15*time.Minute || *cfg.Network.Retries != 5 {
+		t.Errorf("unexpected sync or network settings %+v %+v", cfg.Sync, cfg.Network)
+	}
+	if cfg.Keybinds["search"] != "ctrl+f" || len(cfg.Plugins.Disabled) != 2 {
+		t.Errorf("unexpected keybinds %v or plugins %v", cfg.Keybinds, cfg.Plugins)
+	}
+	if v, _ := cfg.Get("keybinds.search"); v != "ctrl+f" {
+		t.Errorf("Get(keybinds.search) = %q", v)
+	}
+	if v, _ := cfg.Display("apps"); v != "vim, tmux" {
+		t.Errorf("Display(apps) = %q, want the list on one line", v)
+	}
+	if v, _ := cfg.Display("layout.max_width"); v != "80" {
+		t.Errorf("Display(layout.max_width) = %q", v)
+	}
+
+	if err := cfg.Set("layout.max_width", "wide"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+	for _, key := range []string{"colour", "layout.colour", "theme.name", "keybinds.a.b", ""} {
+		if _, err := cfg.Get(key); !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("Get(%q) should fail with ErrUnknownKey, got %v", key, err)
+		}
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	if result := CheckFile(write("ok.yaml", "theme: dark\n")); !result.Valid {
+		t.Errorf("valid file reported %v", result.Errors)
+	}
+	if result := CheckFile(write("empty.yaml", "")); !result.Valid {
+		t.Errorf("empty file should use the defaults, got %v", result.Errors)
+	}
+
+	result := CheckFile(write("bad.yaml", "theme: neon\ncolour: red\n"))
+	if result.Valid || len(result.Errors) != 2 {
+		t.Fatalf("expected an unknown key and an invalid theme, got %v", result.Errors)
+	}
+	if !errors.Is(result.Errors[0], ErrInvalidConfig) || !errors.Is(result.Errors[1], ErrInvalidTheme) {
+		t.Errorf("unexpected errors %v", result.Errors)
+	}
+
+	if result := CheckFile(write("broken.yaml", "theme: [\n")); result.Valid {
+		t.Error("YAML syntax errors should be reported")
+	}
+	if result := CheckFile(filepath.Join(dir, "missing.yaml")); result.Valid {
+		t.Error("a missing file should be reported")
+	}
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -14,9 +16,26 @@ var (
 	ErrInvalidConfig  = errors.New("invalid configuration format")
 )
 
+// DefaultConfigPath is where the configuration is saved when no file exists
+// yet and XDG_CONFIG_HOME is not set
+const DefaultConfigPath = "~/.config/cheat-go/config.yaml"
+
+// Environment variables overriding the configuration locations
+const (
+	// EnvConfig names the config file, after an explicit --config path
+	EnvConfig = "CHEATGO_CONFIG"
+	// EnvDataDir replaces the data_dir setting of the config file
+	EnvDataDir = "CHEATGO_DATA_DIR"
+)
+
 // Loader handles configuration loading and validation
 type Loader struct {
 	configPath string
+	loadedPath string
+	// fileDataDir is the data_dir of the loaded config, kept while
+	// CHEATGO_DATA_DIR overrides it so Save does not store the override
+	fileDataDir string
+	envDataDir  string
 }
 
 // NewLoader creates a new configuration loader
@@ -26,31 +45,85 @@ func NewLoader(configPath string) *Loader {
 	}
 }
 
-// Load reads and parses the configuration file
+// Load reads and parses the first configuration file found among the
+// explicitly requested path, $CHEATGO_CONFIG, config.yaml in the config
+// directory, ~/.cheat-go.yaml and ./config.yaml. CHEATGO_DATA_DIR then
+// overrides the data directory.
 func (l *Loader) Load() (*Config, error) {
-	// Try to load from file first
-	if l.configPath != "" {
-		if config, err := l.loadFromFile(l.configPath); err == nil {
-			return config, nil
+	config := l.load()
+
+	l.fileDataDir, l.envDataDir = config.DataDir, ""
+	if dir := os.Getenv(EnvDataDir); dir != "" {
+		config.DataDir = dir
+		l.envDataDir = dir
+	}
+	return config, nil
+}
+
+// load returns the first configuration file that loads, or the defaults
+func (l *Loader) load() *Config {
+	l.loadedPath = ""
+	for _, path := range l.searchPaths() {
+		if config, err := l.loadFromFile(path); err == nil {
+			l.loadedPath = path
+			return config
 		}
 	}
 
-	// Try default config locations
-	defaultPaths := []string{
-		"~/.config/cheat-go/config.yaml",
-		"~/.cheat-go.yaml",
+	// Fall back to default configuration
+	return DefaultConfig()
+}
+
+// searchPaths lists the config files Load tries, in order
+func (l *Loader) searchPaths() []string {
+	var paths []string
+	if l.configPath != "" {
+		paths = append(paths, l.configPath)
+	}
+	if path := os.Getenv(EnvConfig); path != "" {
+		paths = append(paths, expandPath(path))
+	}
+	return append(paths,
+		DefaultPath(),
+		expandPath("~/.cheat-go.yaml"),
 		"./config.yaml",
+	)
+}
+
+// Path returns the file the configuration was loaded from, falling back to
+// the explicitly requested path, $CHEATGO_CONFIG and then the default location
+func (l *Loader) Path() string {
+	if l.loadedPath != "" {
+		return l.loadedPath
+	}
+	if l.configPath != "" {
+		return l.configPath
 	}
+	if path := os.Getenv(EnvConfig); path != "" {
+		return expandPath(path)
+	}
+	return DefaultPath()
+}
 
-	for _, path := range defaultPaths {
-		expandedPath := expandPath(path)
-		if config, err := l.loadFromFile(expandedPath); err == nil {
-			return config, nil
-		}
+// DefaultPath returns config.yaml in the config directory, where the
+// configuration is saved when no file exists yet
+func DefaultPath() string {
+	return filepath.Join(ConfigDir(), "config.yaml")
+}
+
+// ConfigDir returns the cheat-go directory of $XDG_CONFIG_HOME, or
+// ~/.config/cheat-go
+func ConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "cheat-go")
 	}
+	return filepath.Dir(expandPath(DefaultConfigPath))
+}
 
-	// Fall back to default configuration
-	return DefaultConfig(), nil
+// Found reports whether Load read a configuration file rather than falling
+// back to the defaults
+func (l *Loader) Found() bool {
+	return l.loadedPath != ""
 }
 
 // loadFromFile loads configuration from a specific file
@@ -70,6 +143,52 @@ func (l *Loader) loadFromFile(path string) (*Config, error) {
 
 // validateAndSetDefaults validates config and sets defaults for missing values
 func (l *Loader) validateAndSetDefaults(config *Config) (*Config, error) {
+	setDefaults(config)
+
+	// Validate the configuration
+	validation := config.Validate()
+	if !validation.Valid {
+		return nil, fmt.Errorf("configuration validation failed: %v", validation.Errors)
+	}
+
+	return config, nil
+}
+
+// CheckFile reports every problem of the configuration file at path that
+// makes Load fall back to the defaults: a missing or unreadable file, YAML
+// errors, unknown keys and invalid settings
+func CheckFile(path string) ValidationResult {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ValidationResult{Errors: []error{err}}
+	}
+
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return ValidationResult{Errors: []error{fmt.Errorf("%w: %v", ErrInvalidConfig, err)}}
+		}
+		// unknown keys are reported, the remaining settings still checked
+		result := ValidationResult{}
+		for _, msg := range typeErr.Errors {
+			result.Errors = append(result.Errors, fmt.Errorf("%w: %s", ErrInvalidConfig, msg))
+		}
+		config = Config{}
+		yaml.Unmarshal(data, &config)
+		setDefaults(&config)
+		result.Errors = append(result.Errors, config.Validate().Errors...)
+		return result
+	}
+
+	setDefaults(&config)
+	return config.Validate()
+}
+
+// setDefaults fills in missing values of config from DefaultConfig
+func setDefaults(config *Config) {
 	defaults := DefaultConfig()
 
 	// Set defaults for missing values
@@ -108,17 +227,31 @@ func (l *Loader) validateAndSetDefaults(config *Config) (*Config, error) {
 		config.DataDir = defaults.DataDir
 	}
 
-	// Validate the configuration
-	validation := config.Validate()
-	if !validation.Valid {
-		return nil, fmt.Errorf("configuration validation failed: %v", validation.Errors)
+	if config.Online.Provider == "" {
+		config.Online.Provider = defaults.Online.Provider
+	}
+	if config.Online.Provider == "github" && len(config.Online.Repositories) == 0 {
+		config.Online.Repositories = defaults.Online.Repositories
 	}
 
-	return config, nil
+	if config.Storage.Backend == "" {
+		config.Storage.Backend = defaults.Storage.Backend
+	}
 }
 
-// Save writes the configuration to file
+// Save writes the configuration to file. The file holds API keys, so it is
+// readable by its owner alone, even when it was created with a wider mode.
 func (l *Loader) Save(config *Config, path string) error {
+	config = config.Base()
+
+	// Keep the data directory of the file rather than the one the
+	// environment set for this run
+	if l.envDataDir != "" && config.DataDir == l.envDataDir {
+		saved := *config
+		saved.DataDir = l.fileDataDir
+		config = &saved
+	}
+
 	data, err := yaml.Marshal(config)
 	if err != nil {
 		return err
@@ -130,7 +263,10 @@ func (l *Loader) Save(config *Config, path string) error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
 }
 
 // expandPath expands ~ to home directory
@@ -1,11 +1,13 @@
 package config
 
 import (
+	"errors"
 	"gopkg.in/yaml.v3"
 	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewLoader(t *testing.T) {
@@ -68,6 +70,10 @@ func TestLoader_Load_FromFile(t *testing.T) {
 	if config.Theme != testConfig.Theme {
 		t.Errorf("loaded Theme = %s, expected %s", config.Theme, testConfig.Theme)
 	}
+
+	if !loader.Found() {
+		t.Error("Found() should report the loaded file")
+	}
 }
 
 func TestLoader_Load_DefaultFallback(t *testing.T) {
@@ -269,6 +275,26 @@ func TestLoader_Save(t *testing.T) {
 	}
 }
 
+func TestLoader_SaveMode(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("theme: dark\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Online.APIKey = "secret"
+	if err := NewLoader(configPath).Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("config file mode = %o, want 600", mode)
+	}
+}
+
 func TestLoader_Save_CreateDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 	nestedPath := filepath.Join(tmpDir, "nested", "dir", "config.yaml")
@@ -494,3 +520,45 @@ func TestValidationHelpers(t *testing.T) {
 		t.Error("invalid-column should not be valid")
 	}
 }
+
+func TestLoader_NetworkConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("network:\n  retries: 0\n  retry_delay: 250ms\n  rate_limit: 2.5\n  timeout: 1m\n"), 0644)
+
+	config, err := NewLoader(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	network := config.Network
+	if network.Retries == nil || *network.Retries != 0 {
+		t.Errorf("retries = %v, want explicit 0", network.Retries)
+	}
+	if network.RetryDelay != 250*time.Millisecond || network.Timeout != time.Minute || network.RateLimit != 2.5 {
+		t.Errorf("network = %+v", network)
+	}
+
+	negative := -1
+	config.Network.Retries = &negative
+	if result := config.Validate(); result.Valid {
+		t.Error("negative retries should fail validation")
+	}
+}
+
+func TestLoader_SyncConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("sync:\n  enabled: true\n  endpoint: https://sync.example.com\n  auto_sync: true\n  interval: 15m\n"), 0644)
+
+	config, err := NewLoader(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !config.Sync.Enabled || !config.Sync.AutoSync || config.Sync.Interval != 15*time.Minute {
+		t.Errorf("sync = %+v", config.Sync)
+	}
+
+	config.Sync.Endpoint = ""
+	result := config.Validate()
+	if result.Valid || !errors.Is(result.Errors[0], ErrInvalidSync) {
+		t.Errorf("enabled sync without an endpoint should fail validation: %v", result.Errors)
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import "slices"
+
+// Overrides are settings that hold for the running process alone, such as
+// the apps detected where cheat-go starts or the theme given on the command
+// line. Base takes them back out, so they are never saved.
+type Overrides struct {
+	// Priority are apps moved to the front of Apps, in this order
+	Priority []string
+	// Theme and TableStyle replace those configured unless empty
+	Theme      string
+	TableStyle string
+}
+
+// overridesBase holds the applied overrides and what they replaced
+type overridesBase struct {
+	applied *Overrides
+	// apps are the apps in their order before Priority moved them
+	apps []string
+	// theme and tableStyle are the settings replaced
+	theme      string
+	tableStyle string
+}
+
+// WithOverrides returns a copy of the configuration with overrides applied
+// in place of any applied before
+func (c *Config) WithOverrides(overrides Overrides) *Config {
+	config := c.withoutOverrides()
+	applied := *config
+	applied.overrides = overridesBase{applied: &overrides, apps: config.Apps, theme: config.Theme, tableStyle: config.Layout.TableStyle}
+	applied.Apps = prioritize(config.Apps, overrides.Priority)
+	if overrides.Theme != "" {
+		applied.Theme = overrides.Theme
+	}
+	if overrides.TableStyle != "" {
+		applied.Layout.TableStyle = overrides.TableStyle
+	}
+	return &applied
+}
+
+// reapplyOverrides applies overrides, when not nil, over the configuration
+func (c *Config) reapplyOverrides(overrides *Overrides) *Config {
+	if overrides == nil {
+		return c
+	}
+	return c.WithOverrides(*overrides)
+}
+
+// withoutOverrides returns the configuration without the applied
+// overrides: the apps kept from before in their order, then those added
+// since, and the replaced settings unless they were changed since
+func (c *Config) withoutOverrides() *Config {
+	if c.overrides.applied == nil {
+		return c
+	}
+
+	base := *c
+	base.overrides = overridesBase{}
+	base.Apps = nil
+	for _, app := range c.overrides.apps {
+		if slices.Contains(c.Apps, app) {
+			base.Apps = append(base.Apps, app)
+		}
+	}
+	for _, app := range c.Apps {
+		if !slices.Contains(c.overrides.apps, app) {
+			base.Apps = append(base.Apps, app)
+		}
+	}
+
+	applied := c.overrides.applied
+	if applied.Theme != "" && c.Theme == applied.Theme {
+		base.Theme = c.overrides.theme
+	}
+	if applied.TableStyle != "" && c.Layout.TableStyle == applied.TableStyle {
+		base.Layout.TableStyle = c.overrides.tableStyle
+	}
+	return &base
+}
+
+// prioritize moves the apps of priority among apps to the front, in the
+// order of priority, keeping the order of the others
+func prioritize(apps, priority []string) []string {
+	ordered := make([]string, 0, len(apps))
+	for _, app := range priority {
+		if slices.Contains(apps, app) && !slices.Contains(ordered, app) {
+			ordered = append(ordered, app)
+		}
+	}
+	for _, app := range apps {
+		if !slices.Contains(ordered, app) {
+			ordered = append(ordered, app)
+		}
+	}
+	return ordered
+}
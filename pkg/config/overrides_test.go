@@ -0,0 +1,70 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_WithOverridesPriority(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Apps = []string{"vim", "zsh", "dwm", "st", "lf", "zathura"}
+
+	detected := cfg.WithOverrides(Overrides{Priority: []string{"tmux", "st", "zsh"}})
+	if want := []string{"st", "zsh", "vim", "dwm", "lf", "zathura"}; !reflect.DeepEqual(detected.Apps, want) {
+		t.Errorf("Apps = %v, want %v", detected.Apps, want)
+	}
+	if !reflect.DeepEqual(cfg.Apps, []string{"vim", "zsh", "dwm", "st", "lf", "zathura"}) {
+		t.Error("WithOverrides should not change the configuration")
+	}
+
+	// apps changed since keep the order of the file
+	detected.Apps = append(detected.Apps[1:], "tmux")
+	if want := []string{"vim", "zsh", "dwm", "lf", "zathura", "tmux"}; !reflect.DeepEqual(detected.Base().Apps, want) {
+		t.Errorf("Base().Apps = %v, want %v", detected.Base().Apps, want)
+	}
+
+	// the order holds over another profile
+	cfg.Profiles = map[string]Profile{"work": {Apps: []string{"vim", "st"}}}
+	work, err := cfg.WithOverrides(Overrides{Priority: []string{"st"}}).WithProfile("work")
+	if err != nil || !reflect.DeepEqual(work.Apps, []string{"st", "vim"}) {
+		t.Errorf("WithProfile() = %v (%v), want the overrides kept", work.Apps, err)
+	}
+}
+
+func TestLoader_SaveWithOverrides(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := DefaultConfig()
+	cfg.Apps = []string{"vim", "zsh", "lf"}
+
+	loader := NewLoader(configPath)
+	if err := loader.Save(cfg.WithOverrides(Overrides{Priority: []string{"lf"}}), configPath); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(saved.Apps, []string{"vim", "zsh", "lf"}) {
+		t.Errorf("the order of the file should be saved, got %v", saved.Apps)
+	}
+}
+
+func TestConfig_WithOverridesSettings(t *testing.T) {
+	cfg := DefaultConfig()
+	flags := cfg.WithOverrides(Overrides{Theme: "dark", TableStyle: "minimal"})
+	if flags.Theme != "dark" || flags.Layout.TableStyle != "minimal" {
+		t.Fatalf("overrides should apply, got %s and %s", flags.Theme, flags.Layout.TableStyle)
+	}
+
+	base := flags.Base()
+	if base.Theme != cfg.Theme || base.Layout.TableStyle != cfg.Layout.TableStyle {
+		t.Errorf("Base() should restore the configured settings, got %s and %s", base.Theme, base.Layout.TableStyle)
+	}
+
+	// a theme picked since is the user's
+	flags.Theme = "light"
+	if base := flags.Base(); base.Theme != "light" || base.Layout.TableStyle != cfg.Layout.TableStyle {
+		t.Errorf("Base() should keep settings changed since, got %s and %s", base.Theme, base.Layout.TableStyle)
+	}
+}
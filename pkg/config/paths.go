@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// defaultBaseDir is used for user data when no data directory is configured
+// and XDG_DATA_HOME is not set
+const defaultBaseDir = "~/.config/cheat-go"
+
+// defaultDataDir is the data_dir of the default configuration when
+// XDG_DATA_HOME is not set
+const defaultDataDir = "~/.config/cheat-go/apps"
+
+// DefaultDataDir returns the data directory of the default configuration:
+// the cheat-go directory of $XDG_DATA_HOME, or ~/.config/cheat-go/apps
+func DefaultDataDir() string {
+	if dir, ok := xdgDataDir(); ok {
+		return dir
+	}
+	return defaultDataDir
+}
+
+// xdgDataDir returns the cheat-go directory of $XDG_DATA_HOME, if set
+func xdgDataDir() (string, bool) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		return "", false
+	}
+	return filepath.Join(dir, "cheat-go"), true
+}
+
+// BaseDir returns the expanded root directory for user data
+func (c *Config) BaseDir() string {
+	if c.DataDir == "" {
+		if dir, ok := xdgDataDir(); ok {
+			return dir
+		}
+		return expandPath(defaultBaseDir)
+	}
+	return expandPath(c.DataDir)
+}
+
+// AppsDir returns the expanded directory holding app definition files
+func (c *Config) AppsDir() string {
+	return c.BaseDir()
+}
+
+// NotesDir returns the directory holding personal notes
+func (c *Config) NotesDir() string {
+	return filepath.Join(c.BaseDir(), "notes")
+}
+
+// NoteFilesDir returns the directory holding one file per note
+func (c *Config) NoteFilesDir() string {
+	return filepath.Join(c.NotesDir(), "files")
+}
+
+// TemplatesDir returns the directory holding user note templates
+func (c *Config) TemplatesDir() string {
+	return filepath.Join(c.NotesDir(), "templates")
+}
+
+// PluginsDir returns the user plugin directory
+func (c *Config) PluginsDir() string {
+	return filepath.Join(c.BaseDir(), "plugins")
+}
+
+// CacheDir returns the directory used by the persistent file cache
+func (c *Config) CacheDir() string {
+	return filepath.Join(c.BaseDir(), "cache")
+}
+
+// BackupsDir returns the directory holding local backups
+func (c *Config) BackupsDir() string {
+	return filepath.Join(c.BaseDir(), "backups")
+}
+
+// LogsDir returns the directory holding log files
+func (c *Config) LogsDir() string {
+	return filepath.Join(c.BaseDir(), "logs")
+}
+
+// DaemonSocket returns the unix socket of the background daemon
+func (c *Config) DaemonSocket() string {
+	return filepath.Join(c.BaseDir(), "daemon.sock")
+}
+
+// JournalPath returns the append-only change journal file
+func (c *Config) JournalPath() string {
+	return filepath.Join(c.LogsDir(), "journal.jsonl")
+}
+
+// TokenPath returns the file holding the online service OAuth2 token
+func (c *Config) TokenPath() string {
+	return filepath.Join(c.BaseDir(), "token.json")
+}
+
+// DatabasePath returns the database file used by the sqlite storage backend
+func (c *Config) DatabasePath() string {
+	if c.Storage.Path != "" {
+		return expandPath(c.Storage.Path)
+	}
+	return filepath.Join(c.BaseDir(), "cheat-go.db")
+}
+
+// SystemAppsDirs hold apps installed system wide, searched beneath the
+// data_dirs
+var SystemAppsDirs = []string{"/usr/share/cheat-go/apps", "/usr/local/share/cheat-go/apps"}
+
+// SharedAppsDirs returns the expanded directories of apps layered beneath
+// the apps of DataDir, in increasing precedence: the system wide ones,
+// then DataDirs
+func (c *Config) SharedAppsDirs() []string {
+	dirs := append([]string(nil), SystemAppsDirs...)
+	for _, dir := range c.DataDirs {
+		dirs = append(dirs, expandPath(dir))
+	}
+	return dirs
+}
+
+// CheatDirs returns the expanded directories of cheat tool sheets
+func (c *Config) CheatDirs() []string {
+	dirs := make([]string, 0, len(c.CheatPaths))
+	for _, dir := range c.CheatPaths {
+		dirs = append(dirs, expandPath(dir))
+	}
+	return dirs
+}
+
+// ExpandPath expands a leading ~ to the user's home directory
+func ExpandPath(path string) string {
+	return expandPath(path)
+}
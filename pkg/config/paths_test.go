@@ -0,0 +1,172 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_Paths(t *testing.T) {
+	cfg := &Config{DataDir: "/data/cheat"}
+
+	tests := map[string]string{
+		"base":    cfg.BaseDir(),
+		"apps":    cfg.AppsDir(),
+		"notes":   cfg.NotesDir(),
+		"plugins": cfg.PluginsDir(),
+		"cache":   cfg.CacheDir(),
+		"backups": cfg.BackupsDir(),
+		"logs":    cfg.LogsDir(),
+		"journal": cfg.JournalPath(),
+		"db":      cfg.DatabasePath(),
+		"token":   cfg.TokenPath(),
+	}
+	expected := map[string]string{
+		"base":    "/data/cheat",
+		"apps":    "/data/cheat",
+		"notes":   "/data/cheat/notes",
+		"plugins": "/data/cheat/plugins",
+		"cache":   "/data/cheat/cache",
+		"backups": "/data/cheat/backups",
+		"db":      "/data/cheat/cheat-go.db",
+		"token":   "/data/cheat/token.json",
+		"logs":    "/data/cheat/logs",
+		"journal": "/data/cheat/logs/journal.jsonl",
+	}
+	for name, got := range tests {
+		if got != expected[name] {
+			t.Errorf("%s dir = %s, expected %s", name, got, expected[name])
+		}
+	}
+}
+
+func TestConfig_PathsExpandHome(t *testing.T) {
+	cfg := &Config{}
+	if !filepath.IsAbs(cfg.BaseDir()) {
+		t.Errorf("default base dir should be expanded, got %s", cfg.BaseDir())
+	}
+
+	cfg.DataDir = "~/cheat"
+	if cfg.NotesDir() == "~/cheat/notes" {
+		t.Error("~ should be expanded in derived paths")
+	}
+}
+
+func TestLoader_Path(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	loader := NewLoader(configPath)
+	if loader.Path() != configPath {
+		t.Errorf("Path() before load = %s, expected %s", loader.Path(), configPath)
+	}
+
+	if err := loader.Save(DefaultConfig(), configPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if loader.Path() != configPath {
+		t.Errorf("Path() after load = %s, expected %s", loader.Path(), configPath)
+	}
+
+	if NewLoader("").Path() == "" {
+		t.Error("Path() should fall back to the default location")
+	}
+}
+
+func TestLoader_EnvConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	envPath := filepath.Join(tmpDir, "env.yaml")
+	cfg := DefaultConfig()
+	cfg.Theme = "dark"
+	if err := NewLoader("").Save(cfg, envPath); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvConfig, envPath)
+
+	loader := NewLoader("")
+	loaded, _ := loader.Load()
+	if loaded.Theme != "dark" || loader.Path() != envPath {
+		t.Errorf("CHEATGO_CONFIG should be loaded, got theme %s from %s", loaded.Theme, loader.Path())
+	}
+
+	// an explicit path comes first
+	explicitPath := filepath.Join(tmpDir, "explicit.yaml")
+	cfg.Theme = "light"
+	if err := NewLoader("").Save(cfg, explicitPath); err != nil {
+		t.Fatal(err)
+	}
+	loader = NewLoader(explicitPath)
+	loaded, _ = loader.Load()
+	if loaded.Theme != "light" || loader.Path() != explicitPath {
+		t.Errorf("--config should win over CHEATGO_CONFIG, got theme %s from %s", loaded.Theme, loader.Path())
+	}
+}
+
+func TestLoader_XDGConfigHome(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv(EnvConfig, "")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	want := filepath.Join(xdg, "cheat-go", "config.yaml")
+	if DefaultPath() != want || NewLoader("").Path() != want {
+		t.Errorf("default path = %s, expected %s", DefaultPath(), want)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Theme = "minimal"
+	if err := NewLoader("").Save(cfg, want); err != nil {
+		t.Fatal(err)
+	}
+	loader := NewLoader("")
+	loaded, _ := loader.Load()
+	if loaded.Theme != "minimal" || !loader.Found() {
+		t.Errorf("the config of XDG_CONFIG_HOME should be loaded, got theme %s", loaded.Theme)
+	}
+}
+
+func TestLoader_EnvDataDir(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := DefaultConfig()
+	cfg.DataDir = "/file/data"
+	if err := NewLoader("").Save(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvDataDir, "/env/data")
+
+	loader := NewLoader(configPath)
+	loaded, _ := loader.Load()
+	if loaded.DataDir != "/env/data" || loaded.BaseDir() != "/env/data" {
+		t.Errorf("CHEATGO_DATA_DIR should override data_dir, got %s", loaded.DataDir)
+	}
+
+	// saving keeps the data directory of the file
+	loaded.Theme = "dark"
+	if err := loader.Save(loaded, configPath); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvDataDir, "")
+	saved, _ := NewLoader(configPath).Load()
+	if saved.DataDir != "/file/data" || saved.Theme != "dark" {
+		t.Errorf("saved data_dir = %s, theme %s", saved.DataDir, saved.Theme)
+	}
+}
+
+func TestXDGDataHome(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", xdg)
+
+	want := filepath.Join(xdg, "cheat-go")
+	if DefaultConfig().DataDir != want {
+		t.Errorf("default data dir = %s, expected %s", DefaultConfig().DataDir, want)
+	}
+	if cfg := (&Config{}); cfg.BaseDir() != want {
+		t.Errorf("base dir without data_dir = %s, expected %s", cfg.BaseDir(), want)
+	}
+
+	// a configured data_dir wins
+	if cfg := (&Config{DataDir: "/data"}); cfg.BaseDir() != "/data" {
+		t.Errorf("configured data dir should win, got %s", cfg.BaseDir())
+	}
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	ErrUnknownProfile = errors.New("unknown profile")
+	ErrInvalidProfile = errors.New("invalid profile")
+)
+
+// Profile is a named set of settings that replace those of the
+// configuration while it is active, such as the apps used at work. Empty
+// settings keep the value of the configuration.
+type Profile struct {
+	Apps        []string `yaml:"apps,omitempty" json:"apps,omitempty"`
+	Theme       string   `yaml:"theme,omitempty" json:"theme,omitempty"`
+	TableStyle  string   `yaml:"table_style,omitempty" json:"table_style,omitempty"`
+	KeyNotation string   `yaml:"key_notation,omitempty" json:"key_notation,omitempty"`
+}
+
+// ProfileNames returns the names of the configured profiles, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveProfile returns the name of the applied profile, or "" for none
+func (c *Config) ActiveProfile() string {
+	return c.active
+}
+
+// WithProfile returns a copy of the configuration with the named profile
+// applied in place of any active one; "" applies none. An applied project
+// stays applied over the profile.
+func (c *Config) WithProfile(name string) (*Config, error) {
+	config := c.Base()
+	if name == "" {
+		return config.reapply(c)
+	}
+	profile, ok := config.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (configured: %v)", ErrUnknownProfile, name, config.ProfileNames())
+	}
+
+	applied := *config
+	applied.active = name
+	if profile.Apps != nil {
+		applied.base.Apps, applied.Apps = config.Apps, profile.Apps
+	}
+	if profile.Theme != "" {
+		applied.base.Theme, applied.Theme = config.Theme, profile.Theme
+	}
+	if profile.TableStyle != "" {
+		applied.base.TableStyle, applied.Layout.TableStyle = config.Layout.TableStyle, profile.TableStyle
+	}
+	if profile.KeyNotation != "" {
+		applied.base.KeyNotation, applied.Layout.KeyNotation = config.Layout.KeyNotation, profile.KeyNotation
+	}
+	return applied.reapply(c)
+}
+
+// reapply applies the project and overrides applied to from, if any, over
+// the configuration
+func (c *Config) reapply(from *Config) (*Config, error) {
+	config := c
+	if from.project != nil {
+		var err error
+		if config, err = config.WithProject(from.project); err != nil {
+			return nil, err
+		}
+	}
+	return config.reapplyOverrides(from.overrides.applied), nil
+}
+
+// Base returns the configuration as written in the file: settings changed
+// while a profile is active go back into the profile they came from, and
+// the settings of an applied project and the overrides are taken out
+func (c *Config) Base() *Config {
+	c = c.withoutOverrides().withoutProject()
+	if c.active == "" {
+		return c
+	}
+
+	base := *c
+	base.active, base.base = "", Profile{}
+	profile := c.Profiles[c.active]
+	if profile.Apps != nil {
+		profile.Apps, base.Apps = c.Apps, c.base.Apps
+	}
+	if profile.Theme != "" {
+		profile.Theme, base.Theme = c.Theme, c.base.Theme
+	}
+	if profile.TableStyle != "" {
+		profile.TableStyle, base.Layout.TableStyle = c.Layout.TableStyle, c.base.TableStyle
+	}
+	if profile.KeyNotation != "" {
+		profile.KeyNotation, base.Layout.KeyNotation = c.Layout.KeyNotation, c.base.KeyNotation
+	}
+
+	base.Profiles = make(map[string]Profile, len(c.Profiles))
+	for name, p := range c.Profiles {
+		base.Profiles[name] = p
+	}
+	base.Profiles[c.active] = profile
+	return &base
+}
+
+// validateProfiles checks the settings of every profile and that the
+// default profile exists
+func (c *Config) validateProfiles() []error {
+	var errors []error
+
+	if c.Profile != "" {
+		if _, ok := c.Profiles[c.Profile]; !ok {
+			errors = append(errors, fmt.Errorf("%w: %s (configured: %v)", ErrUnknownProfile, c.Profile, c.ProfileNames()))
+		}
+	}
+
+	for _, name := range c.ProfileNames() {
+		profile := c.Profiles[name]
+		if name == "" {
+			errors = append(errors, fmt.Errorf("%w: empty name", ErrInvalidProfile))
+		}
+		if profile.Theme != "" && !isValidTheme(profile.Theme) {
+			errors = append(errors, fmt.Errorf("%w: %s: theme %s (valid: %v)", ErrInvalidProfile, name, profile.Theme, ValidThemes))
+		}
+		if profile.TableStyle != "" && !isValidTableStyle(profile.TableStyle) {
+			errors = append(errors, fmt.Errorf("%w: %s: table style %s (valid: %v)", ErrInvalidProfile, name, profile.TableStyle, ValidTableStyles))
+		}
+		if profile.KeyNotation != "" && !isValidKeyNotation(profile.KeyNotation) {
+			errors = append(errors, fmt.Errorf("%w: %s: key notation %s (valid: %v)", ErrInvalidProfile, name, profile.KeyNotation, ValidKeyNotations))
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func profileConfig() *Config {
+	cfg := DefaultConfig()
+	cfg.Profiles = map[string]Profile{
+		"work":   {Apps: []string{"vim", "tmux"}, Theme: "dark"},
+		"server": {TableStyle: "minimal"},
+	}
+	return cfg
+}
+
+func TestConfig_WithProfile(t *testing.T) {
+	cfg := profileConfig()
+
+	work, err := cfg.WithProfile("work")
+	if err != nil {
+		t.Fatalf("WithProfile failed: %v", err)
+	}
+	if work.ActiveProfile() != "work" || work.Theme != "dark" || !reflect.DeepEqual(work.Apps, []string{"vim", "tmux"}) {
+		t.Errorf("work profile not applied: %s %s %v", work.ActiveProfile(), work.Theme, work.Apps)
+	}
+	if work.Layout.TableStyle != cfg.Layout.TableStyle {
+		t.Error("settings the profile leaves empty should be kept")
+	}
+	if cfg.Theme != "default" || cfg.ActiveProfile() != "" {
+		t.Error("WithProfile should not change the original configuration")
+	}
+
+	// switching replaces the active profile rather than stacking
+	server, err := work.WithProfile("server")
+	if err != nil {
+		t.Fatalf("WithProfile failed: %v", err)
+	}
+	if server.Theme != "default" || server.Layout.TableStyle != "minimal" || !reflect.DeepEqual(server.Apps, cfg.Apps) {
+		t.Errorf("server profile should apply on the base settings, got %s %s %v", server.Theme, server.Layout.TableStyle, server.Apps)
+	}
+
+	base, _ := server.WithProfile("")
+	if base.ActiveProfile() != "" || base.Layout.TableStyle != "simple" {
+		t.Errorf("an empty name should return the base settings, got %s", base.Layout.TableStyle)
+	}
+
+	if _, err := cfg.WithProfile("missing"); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("expected ErrUnknownProfile, got %v", err)
+	}
+}
+
+func TestConfig_BaseKeepsProfileChanges(t *testing.T) {
+	work, _ := profileConfig().WithProfile("work")
+	work.Apps = append(work.Apps, "zsh")
+	work.DataDir = "/data"
+
+	base := work.Base()
+	if !reflect.DeepEqual(base.Apps, DefaultConfig().Apps) {
+		t.Errorf("base apps should be restored, got %v", base.Apps)
+	}
+	if !reflect.DeepEqual(base.Profiles["work"].Apps, []string{"vim", "tmux", "zsh"}) {
+		t.Errorf("apps added while the profile is active belong to it, got %v", base.Profiles["work"].Apps)
+	}
+	if base.Theme != "default" || base.Profiles["work"].Theme != "dark" {
+		t.Errorf("theme should be split back, got %s and %s", base.Theme, base.Profiles["work"].Theme)
+	}
+	if base.DataDir != "/data" {
+		t.Error("settings outside the profile should be kept")
+	}
+}
+
+func TestLoader_SaveWithProfile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := profileConfig()
+	cfg.Profile = "work"
+
+	work, _ := cfg.WithProfile("work")
+	loader := NewLoader(configPath)
+	if err := loader.Save(work, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Theme != "default" || saved.Profile != "work" || saved.Profiles["work"].Theme != "dark" {
+		t.Errorf("the base settings should be saved, got theme %s, profiles %v", saved.Theme, saved.Profiles)
+	}
+}
+
+func TestConfig_ValidateProfiles(t *testing.T) {
+	cfg := profileConfig()
+	if result := cfg.Validate(); !result.Valid {
+		t.Fatalf("valid profiles rejected: %v", result.Errors)
+	}
+
+	cfg.Profile = "missing"
+	if result := cfg.Validate(); result.Valid || !errors.Is(result.Errors[0], ErrUnknownProfile) {
+		t.Errorf("an unknown default profile should be rejected, got %v", result.Errors)
+	}
+
+	cfg.Profile = ""
+	cfg.Profiles["bad"] = Profile{Theme: "neon"}
+	if result := cfg.Validate(); result.Valid || !errors.Is(result.Errors[0], ErrInvalidProfile) {
+		t.Errorf("an invalid profile theme should be rejected, got %v", result.Errors)
+	}
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile names the project configuration, looked for in the working
+// directory and its parents
+const ProjectFile = ".cheatgo.yaml"
+
+var ErrInvalidProject = errors.New("invalid project configuration")
+
+// ProjectConfig holds the settings a project adds to the configuration. It
+// applies over the configuration and any profile, but settings given on the
+// command line still win over it.
+type ProjectConfig struct {
+	// Apps come first in the table, added to the configured apps when
+	// missing
+	Apps []string `yaml:"apps,omitempty" json:"apps,omitempty"`
+	// Keybinds replace the keys of the actions they name
+	Keybinds map[string]string `yaml:"keybinds,omitempty" json:"keybinds,omitempty"`
+	// NoteTags are added to the notes created in the project, and its
+	// notes are listed first
+	NoteTags []string `yaml:"note_tags,omitempty" json:"note_tags,omitempty"`
+
+	// Path is the file the settings were read from
+	Path string `yaml:"-" json:"-"`
+}
+
+// projectBase holds what applying a project changed, so that Base can
+// take it back out
+type projectBase struct {
+	apps      []string
+	addedApps []string
+	// keybinds holds the replaced keys, "" for actions that had none
+	keybinds  map[string]string
+	addedTags []string
+}
+
+// FindProject returns the project configuration in dir or the nearest of
+// its parents holding one
+func FindProject(dir string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// LoadProject reads the project configuration at path. Unknown keys are
+// errors, so that settings only the main configuration knows are not
+// silently ignored.
+func LoadProject(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &ProjectConfig{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(project); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidProject, path, err)
+	}
+	project.Path = path
+	return project, nil
+}
+
+// Project returns the applied project configuration, or nil for none
+func (c *Config) Project() *ProjectConfig {
+	return c.project
+}
+
+// WithProject returns a copy of the configuration with project applied in
+// place of any applied before. The result must validate, so that a project
+// cannot bind one key to two actions.
+func (c *Config) WithProject(project *ProjectConfig) (*Config, error) {
+	config := c.withoutOverrides().withoutProject()
+	applied := *config
+	applied.project = project
+	base := projectBase{apps: config.Apps, keybinds: make(map[string]string)}
+
+	applied.Apps = append([]string(nil), project.Apps...)
+	for _, app := range config.Apps {
+		if !slices.Contains(project.Apps, app) {
+			applied.Apps = append(applied.Apps, app)
+		}
+	}
+	for _, app := range project.Apps {
+		if !slices.Contains(config.Apps, app) {
+			base.addedApps = append(base.addedApps, app)
+		}
+	}
+
+	applied.Keybinds = make(map[string]string, len(config.Keybinds)+len(project.Keybinds))
+	for action, key := range config.Keybinds {
+		applied.Keybinds[action] = key
+	}
+	for action, key := range project.Keybinds {
+		base.keybinds[action] = config.Keybinds[action]
+		applied.Keybinds[action] = key
+	}
+
+	applied.Notes.Tags = append([]string(nil), config.Notes.Tags...)
+	for _, tag := range project.NoteTags {
+		if !slices.Contains(applied.Notes.Tags, tag) {
+			applied.Notes.Tags = append(applied.Notes.Tags, tag)
+			base.addedTags = append(base.addedTags, tag)
+		}
+	}
+
+	applied.projectBase = base
+	if result := applied.Validate(); !result.Valid {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidProject, project.Path, errors.Join(result.Errors...))
+	}
+	return applied.reapplyOverrides(c.overrides.applied), nil
+}
+
+// withoutProject returns the configuration without the applied project.
+// Apps and tags added by the project are removed and replaced keybinds
+// restored, unless they were changed since.
+func (c *Config) withoutProject() *Config {
+	if c.project == nil {
+		return c
+	}
+
+	base := *c
+	base.project, base.projectBase = nil, projectBase{}
+	added := c.projectBase
+
+	// the apps kept from before the project in their order, then those
+	// added since
+	base.Apps = nil
+	for _, app := range added.apps {
+		if slices.Contains(c.Apps, app) {
+			base.Apps = append(base.Apps, app)
+		}
+	}
+	for _, app := range c.Apps {
+		if !slices.Contains(added.apps, app) && !slices.Contains(added.addedApps, app) {
+			base.Apps = append(base.Apps, app)
+		}
+	}
+
+	base.Keybinds = make(map[string]string, len(c.Keybinds))
+	for action, key := range c.Keybinds {
+		base.Keybinds[action] = key
+	}
+	for action, key := range added.keybinds {
+		if base.Keybinds[action] != c.project.Keybinds[action] {
+			continue
+		}
+		if key == "" {
+			delete(base.Keybinds, action)
+		} else {
+			base.Keybinds[action] = key
+		}
+	}
+
+	base.Notes.Tags = nil
+	for _, tag := range c.Notes.Tags {
+		if !slices.Contains(added.addedTags, tag) {
+			base.Notes.Tags = append(base.Notes.Tags, tag)
+		}
+	}
+	return &base
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindProject(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "src", "pkg")
+	os.MkdirAll(sub, 0755)
+
+	if path, ok := FindProject(sub); ok {
+		t.Errorf("no project file should be found, got %s", path)
+	}
+
+	want := filepath.Join(root, ProjectFile)
+	os.WriteFile(want, []byte("apps: [make]\n"), 0644)
+	if path, ok := FindProject(sub); !ok || path != want {
+		t.Errorf("FindProject() = %s, %v, want %s", path, ok, want)
+	}
+
+	// the nearest file wins
+	nearer := filepath.Join(root, "src", ProjectFile)
+	os.WriteFile(nearer, []byte("apps: [go]\n"), 0644)
+	if path, _ := FindProject(sub); path != nearer {
+		t.Errorf("FindProject() = %s, want %s", path, nearer)
+	}
+}
+
+func TestLoadProject(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ProjectFile)
+	os.WriteFile(path, []byte("apps: [make, git]\nkeybinds:\n  search: ctrl+f\nnote_tags: [acme]\n"), 0644)
+
+	project, err := LoadProject(path)
+	if err != nil {
+		t.Fatalf("LoadProject failed: %v", err)
+	}
+	if !reflect.DeepEqual(project.Apps, []string{"make", "git"}) || project.Keybinds["search"] != "ctrl+f" ||
+		!reflect.DeepEqual(project.NoteTags, []string{"acme"}) || project.Path != path {
+		t.Errorf("project = %+v", project)
+	}
+
+	os.WriteFile(path, []byte("theme: dark\n"), 0644)
+	if _, err := LoadProject(path); !errors.Is(err, ErrInvalidProject) {
+		t.Errorf("unknown keys should be reported, got %v", err)
+	}
+
+	os.WriteFile(path, nil, 0644)
+	if project, err := LoadProject(path); err != nil || project.Apps != nil {
+		t.Errorf("an empty file should load empty, got %+v, %v", project, err)
+	}
+}
+
+func TestConfig_WithProject(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Apps = []string{"vim", "git", "zsh"}
+	cfg.Notes.Tags = []string{"mine"}
+	project := &ProjectConfig{
+		Apps:     []string{"make", "git"},
+		Keybinds: map[string]string{"search": "ctrl+f", "open": "o"},
+		NoteTags: []string{"acme", "mine"},
+		Path:     "/src/acme/.cheatgo.yaml",
+	}
+
+	applied, err := cfg.WithProject(project)
+	if err != nil {
+		t.Fatalf("WithProject failed: %v", err)
+	}
+	if !reflect.DeepEqual(applied.Apps, []string{"make", "git", "vim", "zsh"}) {
+		t.Errorf("project apps should come first, got %v", applied.Apps)
+	}
+	if applied.Keybinds["search"] != "ctrl+f" || applied.Keybinds["open"] != "o" || applied.Keybinds["quit"] != "q" {
+		t.Errorf("keybinds = %v", applied.Keybinds)
+	}
+	if !reflect.DeepEqual(applied.Notes.Tags, []string{"mine", "acme"}) {
+		t.Errorf("note tags = %v", applied.Notes.Tags)
+	}
+	if applied.Project() != project || cfg.Project() != nil || cfg.Keybinds["search"] != "/" {
+		t.Error("WithProject should not change the original configuration")
+	}
+
+	// what the project changed stays out of the saved configuration, the
+	// changes made since stay in
+	applied.Apps = append(applied.Apps, "tmux")
+	applied.Keybinds["open"] = "enter"
+	base := applied.Base()
+	if !reflect.DeepEqual(base.Apps, []string{"vim", "git", "zsh", "tmux"}) {
+		t.Errorf("base apps = %v", base.Apps)
+	}
+	if base.Keybinds["search"] != "/" || base.Keybinds["open"] != "enter" {
+		t.Errorf("base keybinds = %v", base.Keybinds)
+	}
+	if !reflect.DeepEqual(base.Notes.Tags, []string{"mine"}) || base.Project() != nil {
+		t.Errorf("base note tags = %v", base.Notes.Tags)
+	}
+
+	// a key bound to two actions does not apply
+	project.Keybinds = map[string]string{"search": "q"}
+	if _, err := cfg.WithProject(project); !errors.Is(err, ErrInvalidProject) {
+		t.Errorf("expected ErrInvalidProject, got %v", err)
+	}
+}
+
+func TestConfig_WithProfileKeepsProject(t *testing.T) {
+	applied, err := profileConfig().WithProject(&ProjectConfig{Apps: []string{"make"}})
+	if err != nil {
+		t.Fatalf("WithProject failed: %v", err)
+	}
+
+	work, err := applied.WithProfile("work")
+	if err != nil {
+		t.Fatalf("WithProfile failed: %v", err)
+	}
+	if !reflect.DeepEqual(work.Apps, []string{"make", "vim", "tmux"}) || work.Project() == nil {
+		t.Errorf("the project should apply over the profile, got %v", work.Apps)
+	}
+
+	base := work.Base()
+	if base.ActiveProfile() != "" || base.Project() != nil || !reflect.DeepEqual(base.Apps, DefaultConfig().Apps) {
+		t.Errorf("base = %s %v", base.ActiveProfile(), base.Apps)
+	}
+	if !reflect.DeepEqual(base.Profiles["work"].Apps, []string{"vim", "tmux"}) {
+		t.Errorf("the profile should not keep the project apps, got %v", base.Profiles["work"].Apps)
+	}
+}
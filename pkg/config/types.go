@@ -3,6 +3,11 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/signature"
 )
 
 var (
@@ -11,6 +16,17 @@ var (
 	ErrInvalidColumn     = errors.New("invalid column")
 	ErrInvalidKeybind    = errors.New("invalid keybind")
 	ErrInvalidMaxWidth   = errors.New("invalid max width")
+	ErrInvalidNotation   = errors.New("invalid key notation")
+	ErrInvalidDetail     = errors.New("invalid detail pane width")
+	ErrInvalidProvider   = errors.New("invalid online provider")
+	ErrInvalidStorage    = errors.New("invalid storage backend")
+	ErrInvalidNetwork    = errors.New("invalid network setting")
+	ErrInvalidSync       = errors.New("invalid sync setting")
+	ErrInvalidNotes      = errors.New("invalid notes setting")
+	ErrInvalidBackup     = errors.New("invalid backup setting")
+	ErrInvalidAlias      = errors.New("invalid app alias")
+	ErrInvalidTrustedKey = errors.New("invalid trusted key")
+	ErrInvalidMirror     = errors.New("invalid mirror")
 )
 
 // Config represents the main application configuration
@@ -20,6 +36,177 @@ type Config struct {
 	Layout   LayoutConfig      `yaml:"layout" json:"layout"`
 	Keybinds map[string]string `yaml:"keybinds" json:"keybinds"`
 	DataDir  string            `yaml:"data_dir" json:"data_dir"`
+	Online   OnlineConfig      `yaml:"online" json:"online"`
+	Storage  StorageConfig     `yaml:"storage" json:"storage"`
+	Network  NetworkConfig     `yaml:"network,omitempty" json:"network,omitempty"`
+	Plugins  PluginsConfig     `yaml:"plugins,omitempty" json:"plugins,omitempty"`
+	Sync     SyncConfig        `yaml:"sync,omitempty" json:"sync,omitempty"`
+	Notes    NotesConfig       `yaml:"notes,omitempty" json:"notes,omitempty"`
+	Backup   BackupConfig      `yaml:"backup,omitempty" json:"backup,omitempty"`
+	// Signatures verifies downloaded cheat sheets and plugins
+	Signatures SignaturesConfig `yaml:"signatures,omitempty" json:"signatures,omitempty"`
+	// Accessibility adapts the TUI to screen readers and low vision
+	Accessibility AccessibilityConfig `yaml:"accessibility,omitempty" json:"accessibility,omitempty"`
+	// DataDirs are directories of shared apps read beneath those of
+	// DataDir, later ones overriding earlier ones. Apps are never written
+	// there.
+	DataDirs []string `yaml:"data_dirs,omitempty" json:"data_dirs,omitempty"`
+	// CheatPaths are directories of sheets in the format of the cheat tool,
+	// such as ~/.config/cheat/cheatsheets, loaded as extra apps
+	CheatPaths []string `yaml:"cheatpaths,omitempty" json:"cheatpaths,omitempty"`
+	// Aliases show an app under another name as well, such as nvim for vim
+	Aliases map[string]string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	// Merged are virtual apps combining the shortcuts of several apps, such
+	// as shells for zsh and bash, in the order listed
+	Merged map[string][]string `yaml:"merged,omitempty" json:"merged,omitempty"`
+	// Detect moves the apps in use where cheat-go starts, such as the
+	// editor, tmux or git inside a repository, to the front of the table
+	Detect bool `yaml:"detect,omitempty" json:"detect,omitempty"`
+	// Profile names the entry of Profiles applied at launch, unless
+	// --profile selects another
+	Profile  string             `yaml:"profile,omitempty" json:"profile,omitempty"`
+	Profiles map[string]Profile `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+	// View names the entry of Views the table opens in, unless --view
+	// selects another
+	View  string          `yaml:"view,omitempty" json:"view,omitempty"`
+	Views map[string]View `yaml:"views,omitempty" json:"views,omitempty"`
+
+	// active is the applied profile and base holds the settings it replaced
+	active string
+	base   Profile
+	// project is the applied project configuration and projectBase holds
+	// what it changed
+	project     *ProjectConfig
+	projectBase projectBase
+	// overrides holds the settings of this run alone, applied over the
+	// project
+	overrides overridesBase
+}
+
+// AccessibilityConfig adapts the TUI to screen readers, low vision and
+// sensitivity to motion
+type AccessibilityConfig struct {
+	// Linear lists the shortcuts one per line, each followed by what it
+	// does in every app, instead of drawing a table, so screen readers read
+	// them in order
+	Linear bool `yaml:"linear,omitempty" json:"linear,omitempty"`
+	// ASCIIBorders draws tables and boxes with - | and + rather than box
+	// drawing characters
+	ASCIIBorders bool `yaml:"ascii_borders,omitempty" json:"ascii_borders,omitempty"`
+	// HighContrast uses the high-contrast theme whatever Theme is set to
+	HighContrast bool `yaml:"high_contrast,omitempty" json:"high_contrast,omitempty"`
+	// ReducedMotion shows a still marker instead of a spinner while tasks
+	// run
+	ReducedMotion bool `yaml:"reduced_motion,omitempty" json:"reduced_motion,omitempty"`
+}
+
+// NotesConfig configures personal notes
+type NotesConfig struct {
+	// TrashRetention is how long deleted notes stay in the trash; zero keeps
+	// them for notes.DefaultTrashRetention
+	TrashRetention time.Duration `yaml:"trash_retention,omitempty" json:"trash_retention,omitempty"`
+	// Tags are added to every note created
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+}
+
+// BackupConfig schedules automatic backups of notes and apps
+type BackupConfig struct {
+	// Auto backs up every Interval while cheat-go runs and before imports
+	// and syncs change data
+	Auto bool `yaml:"auto,omitempty" json:"auto,omitempty"`
+	// Interval between automatic backups; zero backs up daily
+	Interval time.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	// Keep is the number of automatic backups kept; zero keeps 10
+	Keep int `yaml:"keep,omitempty" json:"keep,omitempty"`
+}
+
+// SyncConfig configures cloud sync of notes and apps. On a server shared by
+// several users, User selects whose data the API key syncs and Team names
+// the team whose shared notes are pulled, read-only, with TeamAPIKey.
+type SyncConfig struct {
+	Enabled    bool          `yaml:"enabled" json:"enabled"`
+	Endpoint   string        `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	APIKey     string        `yaml:"api_key,omitempty" json:"api_key,omitempty"`
+	AutoSync   bool          `yaml:"auto_sync,omitempty" json:"auto_sync,omitempty"`
+	Interval   time.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	User       string        `yaml:"user,omitempty" json:"user,omitempty"`
+	Team       string        `yaml:"team,omitempty" json:"team,omitempty"`
+	TeamAPIKey string        `yaml:"team_api_key,omitempty" json:"team_api_key,omitempty"`
+}
+
+// PluginsConfig controls which installed plugins are loaded
+type PluginsConfig struct {
+	Disabled []string `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	// Approved are the SHA-256 sums of the plugins that could not be
+	// verified but that the user chose to run anyway
+	Approved []string `yaml:"approved,omitempty" json:"approved,omitempty"`
+}
+
+// SignaturesConfig lists the minisign public keys trusted to sign cheat
+// sheets and plugins. Nothing is verified while there are none.
+type SignaturesConfig struct {
+	TrustedKeys []string `yaml:"trusted_keys,omitempty" json:"trusted_keys,omitempty"`
+}
+
+// NetworkConfig overrides retry, rate limit and timeout settings of the
+// online and sync clients. Zero values keep the built-in defaults.
+type NetworkConfig struct {
+	// Retries is the number of retries of a failed request; 0 disables them
+	Retries       *int          `yaml:"retries,omitempty" json:"retries,omitempty"`
+	RetryDelay    time.Duration `yaml:"retry_delay,omitempty" json:"retry_delay,omitempty"`
+	MaxRetryDelay time.Duration `yaml:"max_retry_delay,omitempty" json:"max_retry_delay,omitempty"`
+	RateLimit     float64       `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
+	Burst         int           `yaml:"burst,omitempty" json:"burst,omitempty"`
+	Timeout       time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// StorageConfig selects the backend holding apps, notes and other user data
+type StorageConfig struct {
+	Backend string `yaml:"backend" json:"backend"`
+	Path    string `yaml:"path,omitempty" json:"path,omitempty"`
+}
+
+// OnlineConfig controls where community cheat sheets are browsed from
+type OnlineConfig struct {
+	Provider     string     `yaml:"provider" json:"provider"`
+	Repositories []string   `yaml:"repositories" json:"repositories"`
+	APIURL       string     `yaml:"api_url,omitempty" json:"api_url,omitempty"`
+	APIKey       string     `yaml:"api_key,omitempty" json:"api_key,omitempty"`
+	Auth         AuthConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
+	// Subscriptions are the URLs of the repositories whose downloaded
+	// sheets are checked for updates, at startup and every CheckInterval
+	// while cheat-go runs; zero checks every six hours
+	Subscriptions []string      `yaml:"subscriptions,omitempty" json:"subscriptions,omitempty"`
+	CheckInterval time.Duration `yaml:"check_interval,omitempty" json:"check_interval,omitempty"`
+	// Blocklist hides authors, repositories and tags from the online browser
+	Blocklist BlocklistConfig `yaml:"blocklist,omitempty" json:"blocklist,omitempty"`
+	// DownloadWorkers is how many sheets download at once; zero downloads four
+	DownloadWorkers int `yaml:"download_workers,omitempty" json:"download_workers,omitempty"`
+	// Mirrors lists base URLs serving the same content, tried in turn when
+	// the one used last fails, per repository of the github provider or
+	// per API URL
+	Mirrors map[string][]string `yaml:"mirrors,omitempty" json:"mirrors,omitempty"`
+}
+
+// BlocklistConfig lists online content to hide, matched regardless of case:
+// authors by name, repositories by URL or name and sheets by tag
+type BlocklistConfig struct {
+	Authors      []string `yaml:"authors,omitempty" json:"authors,omitempty"`
+	Repositories []string `yaml:"repositories,omitempty" json:"repositories,omitempty"`
+	Tags         []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+}
+
+// AuthConfig configures OAuth2 device-flow login for the http provider
+type AuthConfig struct {
+	ClientID      string   `yaml:"client_id,omitempty" json:"client_id,omitempty"`
+	DeviceAuthURL string   `yaml:"device_auth_url,omitempty" json:"device_auth_url,omitempty"`
+	TokenURL      string   `yaml:"token_url,omitempty" json:"token_url,omitempty"`
+	Scopes        []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+}
+
+// Enabled reports whether OAuth2 login is configured
+func (a AuthConfig) Enabled() bool {
+	return a.ClientID != "" && a.DeviceAuthURL != "" && a.TokenURL != ""
 }
 
 // LayoutConfig controls the display layout
@@ -28,8 +215,30 @@ type LayoutConfig struct {
 	ShowCategories bool     `yaml:"show_categories" json:"show_categories"`
 	TableStyle     string   `yaml:"table_style" json:"table_style"`
 	MaxWidth       int      `yaml:"max_width" json:"max_width"`
+	// Wrap breaks cells wider than their column onto more lines instead
+	// of cutting them short
+	Wrap bool `yaml:"wrap,omitempty" json:"wrap,omitempty"`
+	// Zebra stripes every other row and RowHighlight highlights the whole
+	// row under the cursor; unset keeps the choice of the theme
+	Zebra        *bool `yaml:"zebra,omitempty" json:"zebra,omitempty"`
+	RowHighlight *bool `yaml:"row_highlight,omitempty" json:"row_highlight,omitempty"`
+	// KeyNotation rewrites the Shortcut column in one of ValidKeyNotations;
+	// empty shows keys as written
+	KeyNotation string `yaml:"key_notation,omitempty" json:"key_notation,omitempty"`
+	// DetailPane shows the details of the shortcut under the cursor next
+	// to the table, in a pane DetailWidth columns wide; 0 is
+	// DefaultDetailWidth
+	DetailPane  bool `yaml:"detail_pane,omitempty" json:"detail_pane,omitempty"`
+	DetailWidth int  `yaml:"detail_width,omitempty" json:"detail_width,omitempty"`
 }
 
+// Bounds and default of the width of the detail pane
+const (
+	MinDetailWidth     = 24
+	MaxDetailWidth     = 100
+	DefaultDetailWidth = 40
+)
+
 // ValidationResult contains validation information
 type ValidationResult struct {
 	Valid  bool
@@ -37,14 +246,23 @@ type ValidationResult struct {
 }
 
 // ValidThemes contains all supported themes
-var ValidThemes = []string{"default", "dark", "light", "minimal"}
+var ValidThemes = []string{"default", "dark", "light", "minimal", "high-contrast"}
 
 // ValidTableStyles contains all supported table styles
 var ValidTableStyles = []string{"simple", "rounded", "bold", "minimal"}
 
+// ValidKeyNotations contains all supported key notations
+var ValidKeyNotations = []string{"emacs", "vim", "mac-symbols", "verbose"}
+
 // ValidColumns contains all supported columns
 var ValidColumns = []string{"shortcut", "description", "category", "tags", "platform"}
 
+// ValidOnlineProviders contains all supported online providers
+var ValidOnlineProviders = []string{"github", "http", "mock"}
+
+// ValidStorageBackends contains all supported storage backends
+var ValidStorageBackends = []string{"file", "sqlite"}
+
 // RequiredKeybinds contains all required keybind actions
 var RequiredKeybinds = []string{"quit", "up", "down", "left", "right"}
 
@@ -62,11 +280,77 @@ func (c *Config) Validate() ValidationResult {
 		errors = append(errors, validationErrors...)
 	}
 
+	// Validate online provider
+	if c.Online.Provider != "" && !isValidProvider(c.Online.Provider) {
+		errors = append(errors, fmt.Errorf("%w: %s (valid: %v)", ErrInvalidProvider, c.Online.Provider, ValidOnlineProviders))
+	}
+
+	// Validate mirrors
+	for origin, mirrors := range c.Online.Mirrors {
+		for _, mirror := range mirrors {
+			if u, err := url.Parse(mirror); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errors = append(errors, fmt.Errorf("%w of %s: %q is not an http(s) URL", ErrInvalidMirror, origin, mirror))
+			}
+		}
+	}
+
+	// Validate storage backend
+	if c.Storage.Backend != "" && !isValidStorageBackend(c.Storage.Backend) {
+		errors = append(errors, fmt.Errorf("%w: %s (valid: %v)", ErrInvalidStorage, c.Storage.Backend, ValidStorageBackends))
+	}
+
+	// Validate network settings
+	if validationErrors := c.Network.validate(); len(validationErrors) > 0 {
+		errors = append(errors, validationErrors...)
+	}
+
+	// Validate trusted keys
+	for _, key := range c.Signatures.TrustedKeys {
+		if _, err := signature.ParsePublicKey(key); err != nil {
+			errors = append(errors, fmt.Errorf("%w: %v", ErrInvalidTrustedKey, err))
+		}
+	}
+
+	// Validate sync settings
+	if c.Sync.Enabled && c.Sync.Endpoint == "" {
+		errors = append(errors, fmt.Errorf("%w: endpoint is required when sync is enabled", ErrInvalidSync))
+	}
+	if c.Sync.Interval < 0 {
+		errors = append(errors, fmt.Errorf("%w: interval must not be negative", ErrInvalidSync))
+	}
+
+	// Validate notes settings
+	if c.Notes.TrashRetention < 0 {
+		errors = append(errors, fmt.Errorf("%w: trash_retention must not be negative", ErrInvalidNotes))
+	}
+
+	// Validate backup settings
+	if c.Backup.Interval < 0 {
+		errors = append(errors, fmt.Errorf("%w: interval must not be negative", ErrInvalidBackup))
+	}
+	if c.Backup.Keep < 0 {
+		errors = append(errors, fmt.Errorf("%w: keep must not be negative", ErrInvalidBackup))
+	}
+
 	// Validate keybinds
 	if validationErrors := c.validateKeybinds(); len(validationErrors) > 0 {
 		errors = append(errors, validationErrors...)
 	}
 
+	// Validate aliases and merged apps
+	if validationErrors := c.validateAliases(); len(validationErrors) > 0 {
+		errors = append(errors, validationErrors...)
+	}
+
+	// Validate profiles
+	if validationErrors := c.validateProfiles(); len(validationErrors) > 0 {
+		errors = append(errors, validationErrors...)
+	}
+
+	if validationErrors := c.validateViews(); len(validationErrors) > 0 {
+		errors = append(errors, validationErrors...)
+	}
+
 	return ValidationResult{
 		Valid:  len(errors) == 0,
 		Errors: errors,
@@ -94,6 +378,33 @@ func (l *LayoutConfig) validate() []error {
 		errors = append(errors, fmt.Errorf("%w: %d (must be between 40 and 200)", ErrInvalidMaxWidth, l.MaxWidth))
 	}
 
+	// Validate key notation
+	if l.KeyNotation != "" && !isValidKeyNotation(l.KeyNotation) {
+		errors = append(errors, fmt.Errorf("%w: %s (valid: %v)", ErrInvalidNotation, l.KeyNotation, ValidKeyNotations))
+	}
+
+	// Validate detail pane width
+	if l.DetailWidth != 0 && (l.DetailWidth < MinDetailWidth || l.DetailWidth > MaxDetailWidth) {
+		errors = append(errors, fmt.Errorf("%w: %d (must be between %d and %d)", ErrInvalidDetail, l.DetailWidth, MinDetailWidth, MaxDetailWidth))
+	}
+
+	return errors
+}
+
+// validate rejects negative network settings
+func (n *NetworkConfig) validate() []error {
+	var errors []error
+
+	if n.Retries != nil && *n.Retries < 0 {
+		errors = append(errors, fmt.Errorf("%w: retries %d must not be negative", ErrInvalidNetwork, *n.Retries))
+	}
+	if n.RetryDelay < 0 || n.MaxRetryDelay < 0 || n.Timeout < 0 {
+		errors = append(errors, fmt.Errorf("%w: durations must not be negative", ErrInvalidNetwork))
+	}
+	if n.RateLimit < 0 || n.Burst < 0 {
+		errors = append(errors, fmt.Errorf("%w: rate_limit and burst must not be negative", ErrInvalidNetwork))
+	}
+
 	return errors
 }
 
@@ -108,18 +419,64 @@ func (c *Config) validateKeybinds() []error {
 		}
 	}
 
-	// Check for duplicate keybind values
+	// Check for duplicate keybind values among the actions of a view,
+	// named with the view as in "notes.new", or of the table
 	usedKeys := make(map[string]string)
 	for action, key := range c.Keybinds {
-		if existingAction, exists := usedKeys[key]; exists {
+		view := ""
+		if before, _, ok := strings.Cut(action, "."); ok {
+			view = before
+		}
+		if existingAction, exists := usedKeys[view+"\x00"+key]; exists {
 			errors = append(errors, fmt.Errorf("%w: key '%s' used by both '%s' and '%s'", ErrInvalidKeybind, key, existingAction, action))
 		}
-		usedKeys[key] = action
+		usedKeys[view+"\x00"+key] = action
 	}
 
 	return errors
 }
 
+// validateAliases checks that aliases and merged apps name real apps, so
+// that resolving them never loops
+func (c *Config) validateAliases() []error {
+	var errors []error
+
+	for alias, target := range c.Aliases {
+		switch {
+		case !isValidAppName(alias) || !isValidAppName(target):
+			errors = append(errors, fmt.Errorf("%w: %q -> %q (names must not be empty or contain ':')", ErrInvalidAlias, alias, target))
+		case alias == target:
+			errors = append(errors, fmt.Errorf("%w: %q points to itself", ErrInvalidAlias, alias))
+		case c.Aliases[target] != "":
+			errors = append(errors, fmt.Errorf("%w: %q points to the alias %q", ErrInvalidAlias, alias, target))
+		case c.Merged[alias] != nil:
+			errors = append(errors, fmt.Errorf("%w: %q is both an alias and a merged app", ErrInvalidAlias, alias))
+		}
+	}
+
+	for name, members := range c.Merged {
+		if !isValidAppName(name) {
+			errors = append(errors, fmt.Errorf("%w: merged app %q (names must not be empty or contain ':')", ErrInvalidAlias, name))
+			continue
+		}
+		if len(members) == 0 {
+			errors = append(errors, fmt.Errorf("%w: merged app %q has no apps", ErrInvalidAlias, name))
+		}
+		for _, member := range members {
+			if !isValidAppName(member) || c.Merged[member] != nil || c.Merged[c.Aliases[member]] != nil {
+				errors = append(errors, fmt.Errorf("%w: merged app %q cannot include %q", ErrInvalidAlias, name, member))
+			}
+		}
+	}
+
+	return errors
+}
+
+// isValidAppName checks that name can name an app and its table column
+func isValidAppName(name string) bool {
+	return name != "" && !strings.Contains(name, ":")
+}
+
 // isValidTheme checks if the theme is valid
 func isValidTheme(theme string) bool {
 	for _, valid := range ValidThemes {
@@ -140,6 +497,16 @@ func isValidTableStyle(style string) bool {
 	return false
 }
 
+// isValidKeyNotation checks if the key notation is valid
+func isValidKeyNotation(notation string) bool {
+	for _, valid := range ValidKeyNotations {
+		if notation == valid {
+			return true
+		}
+	}
+	return false
+}
+
 // isValidColumn checks if the column is valid
 func isValidColumn(column string) bool {
 	for _, valid := range ValidColumns {
@@ -150,6 +517,26 @@ func isValidColumn(column string) bool {
 	return false
 }
 
+// isValidProvider checks if the online provider is valid
+func isValidProvider(provider string) bool {
+	for _, valid := range ValidOnlineProviders {
+		if provider == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidStorageBackend checks if the storage backend is valid
+func isValidStorageBackend(backend string) bool {
+	for _, valid := range ValidStorageBackends {
+		if backend == valid {
+			return true
+		}
+	}
+	return false
+}
+
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -171,6 +558,13 @@ func DefaultConfig() *Config {
 			"next_app": "tab",
 			"prev_app": "shift+tab",
 		},
-		DataDir: "~/.config/cheat-go/apps",
+		DataDir: DefaultDataDir(),
+		Online: OnlineConfig{
+			Provider:     "github",
+			Repositories: []string{"remuscazacu/cheat-go/examples/apps"},
+		},
+		Storage: StorageConfig{
+			Backend: "file",
+		},
 	}
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -158,3 +159,161 @@ func TestLayoutConfig_EmptyValues(t *testing.T) {
 		t.Error("empty layout MaxWidth should be 0")
 	}
 }
+
+func TestConfig_ValidateOnlineProvider(t *testing.T) {
+	config := DefaultConfig()
+	if config.Online.Provider != "github" {
+		t.Errorf("default provider = %s, expected github", config.Online.Provider)
+	}
+
+	config.Online.Provider = "ftp"
+	result := config.Validate()
+	if result.Valid {
+		t.Error("unknown provider should fail validation")
+	}
+	found := false
+	for _, err := range result.Errors {
+		if errors.Is(err, ErrInvalidProvider) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected ErrInvalidProvider, got %v", result.Errors)
+	}
+}
+
+func TestConfig_ValidateStorageBackend(t *testing.T) {
+	config := DefaultConfig()
+	if config.Storage.Backend != "file" {
+		t.Errorf("default storage backend = %s, expected file", config.Storage.Backend)
+	}
+
+	config.Storage.Backend = "sqlite"
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("sqlite backend should validate, got %v", result.Errors)
+	}
+
+	config.Storage.Backend = "redis"
+	result := config.Validate()
+	found := false
+	for _, err := range result.Errors {
+		if errors.Is(err, ErrInvalidStorage) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected ErrInvalidStorage, got %v", result.Errors)
+	}
+}
+
+func TestConfig_ValidateTrustedKeys(t *testing.T) {
+	config := DefaultConfig()
+	config.Signatures.TrustedKeys = []string{"RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"}
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("minisign public key should validate, got %v", result.Errors)
+	}
+
+	config.Signatures.TrustedKeys = append(config.Signatures.TrustedKeys, "ssh-ed25519 AAAA")
+	result := config.Validate()
+	found := false
+	for _, err := range result.Errors {
+		if errors.Is(err, ErrInvalidTrustedKey) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected ErrInvalidTrustedKey, got %v", result.Errors)
+	}
+}
+
+func TestConfig_ValidateMirrors(t *testing.T) {
+	config := DefaultConfig()
+	config.Online.Mirrors = map[string][]string{"github.com/remuscazacu/cheat-go/community": {"https://gitea.example.com/api/v1"}}
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("an https mirror should validate, got %v", result.Errors)
+	}
+
+	config.Online.Mirrors["github.com/remuscazacu/cheat-go/community"] = append(config.Online.Mirrors["github.com/remuscazacu/cheat-go/community"], "gitea.example.com")
+	result := config.Validate()
+	if result.Valid || len(result.Errors) != 1 || !errors.Is(result.Errors[0], ErrInvalidMirror) {
+		t.Errorf("expected ErrInvalidMirror, got %v", result.Errors)
+	}
+}
+
+func TestConfig_ValidateKeyNotation(t *testing.T) {
+	config := DefaultConfig()
+	for _, notation := range append([]string{""}, ValidKeyNotations...) {
+		config.Layout.KeyNotation = notation
+		if result := config.Validate(); !result.Valid {
+			t.Errorf("key notation %q should validate, got %v", notation, result.Errors)
+		}
+	}
+
+	config.Layout.KeyNotation = "windows"
+	result := config.Validate()
+	found := false
+	for _, err := range result.Errors {
+		if errors.Is(err, ErrInvalidNotation) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected ErrInvalidNotation, got %v", result.Errors)
+	}
+}
+
+func TestConfig_ValidateAliases(t *testing.T) {
+	config := DefaultConfig()
+	config.Aliases = map[string]string{"nvim": "vim", "sh": "shells"}
+	config.Merged = map[string][]string{"shells": {"zsh", "bash"}, "editors": {"nvim", "emacs"}}
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("aliases should validate, got %v", result.Errors)
+	}
+
+	invalid := []struct {
+		aliases map[string]string
+		merged  map[string][]string
+	}{
+		{aliases: map[string]string{"vim": "vim"}},
+		{aliases: map[string]string{"nvim": ""}},
+		{aliases: map[string]string{"a:b": "vim"}},
+		{aliases: map[string]string{"vi": "nvim", "nvim": "vim"}},
+		{aliases: map[string]string{"shells": "zsh"}, merged: map[string][]string{"shells": {"zsh"}}},
+		{merged: map[string][]string{"shells": {}}},
+		{merged: map[string][]string{"all": {"shells"}, "shells": {"zsh"}}},
+		{aliases: map[string]string{"sh": "shells"}, merged: map[string][]string{"shells": {"sh"}}},
+	}
+	for _, tc := range invalid {
+		config.Aliases, config.Merged = tc.aliases, tc.merged
+		found := false
+		for _, err := range config.Validate().Errors {
+			if errors.Is(err, ErrInvalidAlias) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("aliases %v and merged %v should not validate", tc.aliases, tc.merged)
+		}
+	}
+}
+
+func TestConfig_ValidateKeybindsPerView(t *testing.T) {
+	config := DefaultConfig()
+	config.Keybinds["notes.new"] = "a"
+	config.Keybinds["views.save"] = "a"
+	config.Keybinds["notes.delete"] = "j"
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("keys of different views should validate, got %v", result.Errors)
+	}
+
+	config.Keybinds["notes.delete"] = "a"
+	found := false
+	for _, err := range config.Validate().Errors {
+		if errors.Is(err, ErrInvalidKeybind) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected ErrInvalidKeybind for a key used twice in a view")
+	}
+}
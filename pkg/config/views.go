@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	ErrUnknownView = errors.New("unknown view")
+	ErrInvalidView = errors.New("invalid view")
+)
+
+// Orders of the rows of the table a view can sort by: SortKeys sorts them
+// by shortcut and SortDescription by what the shortcut does in the first
+// app having it. SortNone keeps the order of the table.
+const (
+	SortNone        = ""
+	SortKeys        = "keys"
+	SortDescription = "description"
+)
+
+// ValidSorts are the orders a view can sort the table by
+var ValidSorts = []string{SortNone, SortKeys, SortDescription}
+
+// View is a named state of the table to come back to: the apps shown, the
+// tags and search the shortcuts are filtered by, and their order. Empty
+// settings leave the table unfiltered.
+type View struct {
+	Apps   []string `yaml:"apps,omitempty" json:"apps,omitempty"`
+	Tags   []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Search string   `yaml:"search,omitempty" json:"search,omitempty"`
+	Sort   string   `yaml:"sort,omitempty" json:"sort,omitempty"`
+}
+
+// ViewNames returns the names of the saved views, sorted
+func (c *Config) ViewNames() []string {
+	names := make([]string, 0, len(c.Views))
+	for name := range c.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupView returns the saved view called name
+func (c *Config) LookupView(name string) (View, error) {
+	view, ok := c.Views[name]
+	if !ok {
+		return View{}, fmt.Errorf("%w: %s (saved: %v)", ErrUnknownView, name, c.ViewNames())
+	}
+	return view, nil
+}
+
+// validateViews checks the sort of every view and that the startup view
+// exists
+func (c *Config) validateViews() []error {
+	var errors []error
+
+	if c.View != "" {
+		if _, err := c.LookupView(c.View); err != nil {
+			errors = append(errors, err)
+		}
+	}
+
+	for _, name := range c.ViewNames() {
+		if name == "" {
+			errors = append(errors, fmt.Errorf("%w: empty name", ErrInvalidView))
+		}
+		if sort := c.Views[name].Sort; !isValidSort(sort) {
+			errors = append(errors, fmt.Errorf("%w: %s: sort %s (valid: %v)", ErrInvalidView, name, sort, ValidSorts[1:]))
+		}
+	}
+
+	return errors
+}
+
+func isValidSort(sort string) bool {
+	for _, valid := range ValidSorts {
+		if sort == valid {
+			return true
+		}
+	}
+	return false
+}
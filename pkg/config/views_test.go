@@ -0,0 +1,35 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConfig_Views(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Views = map[string]View{
+		"review": {Apps: []string{"git"}, Tags: []string{"diff"}, Sort: SortKeys},
+		"nav":    {Search: "move"},
+	}
+	cfg.View = "review"
+
+	if names := cfg.ViewNames(); len(names) != 2 || names[0] != "nav" || names[1] != "review" {
+		t.Errorf("view names should be sorted, got %v", names)
+	}
+	if view, err := cfg.LookupView("review"); err != nil || view.Tags[0] != "diff" {
+		t.Errorf("LookupView failed: %v %v", view, err)
+	}
+	if _, err := cfg.LookupView("missing"); !errors.Is(err, ErrUnknownView) {
+		t.Errorf("expected ErrUnknownView, got %v", err)
+	}
+	if result := cfg.Validate(); !result.Valid {
+		t.Errorf("views should be valid, got %v", result.Errors)
+	}
+
+	cfg.View = "missing"
+	cfg.Views["nav"] = View{Sort: "random"}
+	result := cfg.Validate()
+	if len(result.Errors) != 2 || !errors.Is(result.Errors[0], ErrUnknownView) || !errors.Is(result.Errors[1], ErrInvalidView) {
+		t.Errorf("expected an unknown startup view and an invalid sort, got %v", result.Errors)
+	}
+}
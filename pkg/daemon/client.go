@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// Client talks to the daemon serving a socket
+type Client struct {
+	socket string
+	http   *http.Client
+}
+
+// statusTimeout is how long a status request may take
+const statusTimeout = 3 * time.Second
+
+// NewClient creates a client for the daemon on socket. Syncs may take as
+// long as the network does, so requests only time out after a few minutes,
+// except status requests.
+func NewClient(socket string) *Client {
+	return &Client{
+		socket: socket,
+		http: &http.Client{
+			Timeout: 5 * time.Minute,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+// Status returns the state of the daemon, or ErrNotRunning
+func (c *Client) Status() (*Status, error) {
+	// the daemon answers at once, so a daemon that does not is stuck
+	ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+	var status Status
+	if err := c.do(ctx, http.MethodGet, "/status", &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Running reports whether a daemon answers on the socket
+func (c *Client) Running() bool {
+	_, err := c.Status()
+	return err == nil
+}
+
+// Search returns the shortcuts matching query in names, or else in the
+// apps enabled in the daemon, or in every app when all is set
+func (c *Client) Search(query string, all bool, names ...string) ([]apps.ShortcutResult, error) {
+	params := url.Values{"q": {query}, "app": names}
+	if all {
+		params.Set("all", "1")
+	}
+	var results []apps.ShortcutResult
+	if err := c.do(context.Background(), http.MethodGet, "/search?"+params.Encode(), &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Sync makes the daemon sync now and returns its state afterwards
+func (c *Client) Sync() (*Status, error) {
+	var status Status
+	if err := c.do(context.Background(), http.MethodPost, "/sync", &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Reload makes the daemon read the apps again, such as after installing one
+func (c *Client) Reload() error {
+	return c.do(context.Background(), http.MethodPost, "/reload", nil)
+}
+
+// Stop shuts the daemon down
+func (c *Client) Stop() error {
+	return c.do(context.Background(), http.MethodPost, "/stop", nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, out interface{}) error {
+	if _, err := os.Stat(c.socket); err != nil {
+		return ErrNotRunning
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, "http://daemon"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return ErrNotRunning
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failed errorResponse
+		if json.NewDecoder(resp.Body).Decode(&failed) == nil && failed.Error != "" {
+			return errors.New(failed.Error)
+		}
+		return fmt.Errorf("daemon returned status %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,262 @@
+// Package daemon keeps the app registry and sync manager of cheat-go
+// running in the background and serves them to the TUI and CLI over a
+// local unix socket.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	gosync "sync"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/sync"
+)
+
+var (
+	ErrNotRunning = errors.New("daemon is not running")
+	ErrRunning    = errors.New("daemon is already running")
+)
+
+// Status is what a running daemon reports about itself
+type Status struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	Apps      int       `json:"apps"`
+	// Sync is nil when sync is not configured
+	Sync *sync.SyncStatus `json:"sync,omitempty"`
+	// SyncInterval is how often the daemon syncs; zero when it does not
+	SyncInterval time.Duration `json:"sync_interval,omitempty"`
+	// Skipped tells why the last scheduled sync did not run
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// Service is the state a daemon keeps warm
+type Service struct {
+	// Load builds the app registry and lists the enabled apps, searched
+	// unless all apps are asked for; it runs at start and on reload
+	Load func() (registry *apps.Registry, enabled []string, err error)
+	// Sync is nil when sync is not configured
+	Sync *sync.Manager
+	// SyncInterval is how often to sync; zero turns scheduled syncs off
+	SyncInterval time.Duration
+}
+
+// Server serves a Service on a unix socket
+type Server struct {
+	socket  string
+	svc     Service
+	started time.Time
+
+	mu       gosync.RWMutex
+	registry *apps.Registry
+	enabled  []string
+	skipped  string
+
+	lock     *lock.Lock
+	listener net.Listener
+	http     *http.Server
+	stop     chan struct{}
+	done     chan struct{}
+	once     gosync.Once
+}
+
+// NewServer creates a server for svc listening on socket
+func NewServer(socket string, svc Service) *Server {
+	return &Server{socket: socket, svc: svc}
+}
+
+// Listen loads the registry and opens the socket. It returns ErrRunning
+// when another daemon serves the same socket.
+func (s *Server) Listen() error {
+	l, err := lock.TryAcquire(s.socket + ".lock")
+	if err != nil {
+		if errors.Is(err, lock.ErrLocked) {
+			return ErrRunning
+		}
+		return err
+	}
+
+	if err := s.reload(); err != nil {
+		l.Release()
+		return err
+	}
+
+	// a socket left by a daemon that did not shut down cleanly
+	os.Remove(s.socket)
+	listener, err := net.Listen("unix", s.socket)
+	if err != nil {
+		l.Release()
+		return fmt.Errorf("failed to listen on %s: %w", s.socket, err)
+	}
+
+	s.lock = l
+	s.listener = listener
+	s.started = time.Now()
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /search", s.handleSearch)
+	mux.HandleFunc("POST /sync", s.handleSync)
+	mux.HandleFunc("POST /reload", s.handleReload)
+	mux.HandleFunc("POST /stop", s.handleStop)
+	s.http = &http.Server{Handler: mux}
+	return nil
+}
+
+// Serve answers requests and runs scheduled syncs until Shutdown
+func (s *Server) Serve() error {
+	go s.scheduleSyncs()
+
+	err := s.http.Serve(s.listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		<-s.done
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops serving, waiting for requests and a sync in progress
+func (s *Server) Shutdown() {
+	s.once.Do(func() {
+		close(s.stop)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		s.http.Shutdown(ctx)
+		os.Remove(s.socket)
+		s.lock.Release()
+	})
+}
+
+func (s *Server) scheduleSyncs() {
+	defer close(s.done)
+	if s.svc.Sync == nil || s.svc.SyncInterval <= 0 {
+		<-s.stop
+		return
+	}
+
+	ticker := time.NewTicker(s.svc.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.sync()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// sync syncs, remembering why a sync that could not run while another
+// process, such as the TUI, was syncing the same data was skipped
+func (s *Server) sync() error {
+	if s.svc.Sync == nil {
+		return sync.ErrNoSyncService
+	}
+
+	err := s.svc.Sync.Sync()
+	s.mu.Lock()
+	s.skipped = ""
+	if errors.Is(err, sync.ErrSyncInProgress) {
+		s.skipped = err.Error()
+	}
+	s.mu.Unlock()
+	return err
+}
+
+func (s *Server) reload() error {
+	registry, enabled, err := s.svc.Load()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.registry, s.enabled = registry, enabled
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Server) status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := Status{
+		PID:       os.Getpid(),
+		StartedAt: s.started,
+		Apps:      len(s.registry.List()),
+		Skipped:   s.skipped,
+	}
+	if s.svc.Sync != nil {
+		syncStatus := s.svc.Sync.GetSyncStatus()
+		status.Sync = &syncStatus
+		status.SyncInterval = s.svc.SyncInterval
+	}
+	return status
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.status())
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	registry, names := s.registry, s.enabled
+	s.mu.RUnlock()
+
+	if asked := r.URL.Query()["app"]; len(asked) > 0 {
+		names = asked
+	}
+	if r.URL.Query().Get("all") != "" {
+		names = registry.List()
+		sort.Strings(names)
+	}
+	results := registry.SearchShortcutsAdvanced(names, apps.ParseSearchQuery(r.URL.Query().Get("q")))
+	if results == nil {
+		results = []apps.ShortcutResult{}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	if err := s.sync(); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.status())
+}
+
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if err := s.reload(); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.status())
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.status())
+	go s.Shutdown()
+}
+
+// errorResponse is the body of failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+}
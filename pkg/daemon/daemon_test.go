@@ -0,0 +1,127 @@
+package daemon
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/sync"
+)
+
+// staticSync is a sync service with nothing to merge
+type staticSync struct{}
+
+func (staticSync) Push(data sync.SyncData) error { return nil }
+func (staticSync) Pull() (*sync.SyncData, error) {
+	return &sync.SyncData{Timestamp: time.Now()}, nil
+}
+func (staticSync) GetLastSync() (time.Time, error) { return time.Time{}, nil }
+func (staticSync) ResolveConflict(sync.SyncItem, sync.ConflictResolution) error {
+	return nil
+}
+
+func startServer(t *testing.T, svc Service) (*Server, *Client) {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "daemon.sock")
+	server := NewServer(socket, svc)
+	if err := server.Listen(); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- server.Serve() }()
+	t.Cleanup(func() {
+		server.Shutdown()
+		if err := <-served; err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	})
+	return server, NewClient(socket)
+}
+
+func TestDaemon(t *testing.T) {
+	loads, enabled := 0, []string{"vim"}
+	dir := t.TempDir()
+	manager, err := sync.NewManager(staticSync{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := Service{
+		Load: func() (*apps.Registry, []string, error) {
+			loads++
+			registry := apps.NewRegistry("")
+			registry.Register(&apps.App{Name: "tmux", Shortcuts: []apps.Shortcut{{Keys: "C-b d", Description: "detach"}}})
+			return registry, enabled, nil
+		},
+		Sync: manager,
+	}
+	server, client := startServer(t, svc)
+
+	status, err := client.Status()
+	if err != nil || status.Apps == 0 || status.Sync == nil {
+		t.Fatalf("Status() = %+v, %v", status, err)
+	}
+
+	results, err := client.Search("detach", false)
+	if err != nil || len(results) != 0 {
+		t.Errorf("only the enabled apps should be searched, got %+v, %v", results, err)
+	}
+	results, err = client.Search("detach", true)
+	if err != nil || len(results) != 1 || results[0].AppName != "tmux" {
+		t.Errorf("Search(all) = %+v, %v", results, err)
+	}
+
+	// another process syncing the same data
+	syncing, err := lock.Acquire(filepath.Join(dir, sync.LockFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Sync(); err == nil || !strings.Contains(err.Error(), sync.ErrSyncInProgress.Error()) {
+		t.Errorf("a sync should fail while another runs, got %v", err)
+	}
+	if status, _ := client.Status(); status.Skipped == "" {
+		t.Error("the status should tell why the sync did not run")
+	}
+	syncing.Release()
+	status, err = client.Sync()
+	if err != nil || status.Sync.LastSync.IsZero() || status.Skipped != "" {
+		t.Errorf("Sync() = %+v, %v", status, err)
+	}
+
+	enabled = []string{"tmux"}
+	if err := client.Reload(); err != nil || loads != 2 {
+		t.Errorf("Reload() = %v after %d loads", err, loads)
+	}
+	if results, err := client.Search("detach", false); err != nil || len(results) != 1 {
+		t.Errorf("a reload should search the apps enabled since, got %+v, %v", results, err)
+	}
+
+	second := NewServer(server.socket, svc)
+	if err := second.Listen(); !errors.Is(err, ErrRunning) {
+		t.Errorf("a second daemon should not start, got %v", err)
+	}
+
+	if err := client.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for client.Running() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := client.Status(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("a stopped daemon should not answer, got %v", err)
+	}
+}
+
+func TestClient_NotRunning(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "daemon.sock"))
+	if _, err := client.Status(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Status() without a daemon = %v", err)
+	}
+	if client.Running() {
+		t.Error("Running() should be false without a daemon")
+	}
+}
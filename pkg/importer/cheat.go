@@ -0,0 +1,229 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// cheatFrontMatter is the optional YAML header of a cheat sheet
+type cheatFrontMatter struct {
+	Syntax string   `yaml:"syntax"`
+	Tags   []string `yaml:"tags"`
+}
+
+// ParseCheat converts a sheet in the plain-text format of the cheat tool
+// into an app definition. Each command line becomes a shortcut described by
+// the comment lines above it; commands without a comment are skipped. Tags
+// from the YAML front matter are added to every shortcut.
+func ParseCheat(name string, data []byte) (*apps.App, error) {
+	if name == "" {
+		return nil, fmt.Errorf("app name is required")
+	}
+
+	front, body, err := splitFrontMatter(data)
+	if err != nil {
+		return nil, err
+	}
+
+	app := &apps.App{
+		Name:        name,
+		Description: name + " cheat sheet",
+		Version:     "1.0",
+		Metadata:    map[string]string{"source": "cheat"},
+	}
+	if front.Syntax != "" {
+		app.Metadata["syntax"] = front.Syntax
+	}
+
+	var comment []string
+	var command strings.Builder
+	addCommand := func() {
+		keys := strings.TrimSpace(command.String())
+		command.Reset()
+		desc := cheatDescription(comment)
+		if keys == "" || desc == "" {
+			return
+		}
+		app.Shortcuts = append(app.Shortcuts, apps.Shortcut{
+			Keys:        keys,
+			Description: desc,
+			Category:    "general",
+			Tags:        front.Tags,
+		})
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	commentDone := false
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			if command.Len() > 0 {
+				addCommand()
+			}
+			comment = nil
+			commentDone = false
+		case strings.HasPrefix(trimmed, "#") && command.Len() == 0:
+			if commentDone {
+				comment = nil
+				commentDone = false
+			}
+			comment = append(comment, strings.TrimSpace(strings.TrimLeft(trimmed, "#")))
+		default:
+			// A trailing backslash continues the command on the next line
+			if strings.HasSuffix(trimmed, "\\") {
+				command.WriteString(strings.TrimSpace(strings.TrimSuffix(trimmed, "\\")))
+				command.WriteString(" ")
+				continue
+			}
+			command.WriteString(trimmed)
+			addCommand()
+			commentDone = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cheat sheet: %w", err)
+	}
+	if command.Len() > 0 {
+		addCommand()
+	}
+
+	if len(app.Shortcuts) == 0 {
+		return nil, ErrNoShortcuts
+	}
+	app.Categories = []string{"general"}
+	return app, nil
+}
+
+// splitFrontMatter separates the YAML front matter of a cheat sheet from
+// its body
+func splitFrontMatter(data []byte) (cheatFrontMatter, []byte, error) {
+	var front cheatFrontMatter
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return front, []byte(text), nil
+	}
+
+	header, body, ok := strings.Cut(text[len("---\n"):], "\n---")
+	if !ok {
+		return front, nil, fmt.Errorf("unterminated front matter")
+	}
+	if err := yaml.Unmarshal([]byte(header), &front); err != nil {
+		return front, nil, fmt.Errorf("invalid front matter: %w", err)
+	}
+	return front, []byte(body), nil
+}
+
+// cheatDescription turns comment lines like "To list files:" into a
+// shortcut description
+func cheatDescription(comment []string) string {
+	desc := strings.TrimSpace(strings.Join(comment, " "))
+	desc = strings.TrimSuffix(desc, ":")
+	if rest, ok := strings.CutPrefix(desc, "To "); ok {
+		desc = rest
+	}
+	desc = strings.TrimSpace(desc)
+
+	r, size := utf8.DecodeRuneInString(desc)
+	if size == 0 {
+		return ""
+	}
+	return string(unicode.ToUpper(r)) + desc[size:]
+}
+
+// LoadCheatDir parses every sheet below dir, which is laid out like the
+// cheatsheets directory of the cheat tool. Sheets are named after their
+// file; hidden files and directories and files with an extension (README.md,
+// LICENSE.txt) are skipped, as cheat does. When several sheets share a name
+// the one found last in lexical order wins, so personal/ overrides
+// community/. A missing dir yields no apps.
+func LoadCheatDir(dir string) ([]*apps.App, error) {
+	var loaded []*apps.App
+	index := map[string]int{}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() || filepath.Ext(d.Name()) != "" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		app, err := ParseCheat(NameFromPath(path), data)
+		if errors.Is(err, ErrNoShortcuts) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		if i, ok := index[app.Name]; ok {
+			loaded[i] = app
+		} else {
+			index[app.Name] = len(loaded)
+			loaded = append(loaded, app)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].Name < loaded[j].Name })
+	return loaded, nil
+}
+
+// RegisterCheatDirs registers the sheets of the cheat directories dirs with
+// registry, later directories overriding earlier ones. Built-in apps and
+// apps kept in storage take precedence over sheets of the same name. Load
+// the registry's apps afterwards so user overlays are merged.
+func RegisterCheatDirs(registry *apps.Registry, dirs []string) error {
+	sheets := map[string]*apps.App{}
+	var errs []error
+	for _, dir := range dirs {
+		loaded, err := LoadCheatDir(dir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, app := range loaded {
+			sheets[app.Name] = app
+		}
+	}
+
+	for name, app := range sheets {
+		if _, exists := registry.Get(name); exists || registry.HasStoredApp(name) {
+			continue
+		}
+		registry.Register(app)
+	}
+	return errors.Join(errs...)
+}
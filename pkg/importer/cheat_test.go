@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+const sampleCheat = "---\nsyntax: bash\ntags: [ vcs, development ]\n---\n" +
+	"# To stage all changes in the current directory:\n" +
+	"git add --all\n\n" +
+	"# To commit staged changes\n" +
+	"# with a message:\n" +
+	"git commit -m \"message\"\n" +
+	"git commit --amend\n\n" +
+	"git status\n\n" +
+	"# To push to a remote:\n" +
+	"git push \\\n  origin main\n"
+
+func TestParseCheat(t *testing.T) {
+	app, err := ParseCheat("git", []byte(sampleCheat))
+	if err != nil {
+		t.Fatalf("ParseCheat() error = %v", err)
+	}
+
+	if app.Name != "git" || app.Metadata["source"] != "cheat" || app.Metadata["syntax"] != "bash" {
+		t.Errorf("unexpected app %q with metadata %v", app.Name, app.Metadata)
+	}
+
+	expected := []struct{ keys, desc string }{
+		{"git add --all", "Stage all changes in the current directory"},
+		{`git commit -m "message"`, "Commit staged changes with a message"},
+		{"git commit --amend", "Commit staged changes with a message"},
+		{"git push origin main", "Push to a remote"},
+	}
+	if len(app.Shortcuts) != len(expected) {
+		t.Fatalf("expected %d shortcuts, got %d: %+v", len(expected), len(app.Shortcuts), app.Shortcuts)
+	}
+	for i, e := range expected {
+		s := app.Shortcuts[i]
+		if s.Keys != e.keys || s.Description != e.desc {
+			t.Errorf("shortcut %d = %q %q, expected %q %q", i, s.Keys, s.Description, e.keys, e.desc)
+		}
+		if len(s.Tags) != 2 || s.Tags[0] != "vcs" {
+			t.Errorf("shortcut %d should carry the front matter tags, got %v", i, s.Tags)
+		}
+	}
+
+	if err := apps.NewRegistry("").ValidateApp(app); err != nil {
+		t.Errorf("parsed sheet should be a valid app: %v", err)
+	}
+}
+
+func TestParseCheat_Errors(t *testing.T) {
+	if _, err := ParseCheat("empty", []byte("ls -la\n")); !errors.Is(err, ErrNoShortcuts) {
+		t.Errorf("sheet without comments should give ErrNoShortcuts, got %v", err)
+	}
+	if _, err := ParseCheat("bad", []byte("---\ntags: [\n# To list:\nls\n")); err == nil {
+		t.Error("expected error for unterminated front matter")
+	}
+}
+
+func TestLoadCheatDir(t *testing.T) {
+	dir := t.TempDir()
+	writeSheet := func(path, content string) {
+		t.Helper()
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeSheet("community/tar", "# To extract an archive:\ntar xf archive.tar\n")
+	writeSheet("community/ls", "# To list all files:\nls -a\n")
+	writeSheet("community/README.md", "# To ignore:\nnot a sheet\n")
+	writeSheet("community/.git/HEAD", "# To ignore:\nref\n")
+	writeSheet("personal/tar", "# To create an archive:\ntar cf archive.tar dir\n")
+	writeSheet("personal/notes", "just text\n")
+
+	loaded, err := LoadCheatDir(dir)
+	if err != nil {
+		t.Fatalf("LoadCheatDir() error = %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].Name != "ls" || loaded[1].Name != "tar" {
+		t.Fatalf("expected ls and tar, got %+v", loaded)
+	}
+	if loaded[1].Shortcuts[0].Keys != "tar cf archive.tar dir" {
+		t.Errorf("personal sheet should override community one, got %+v", loaded[1].Shortcuts)
+	}
+
+	if loaded, err := LoadCheatDir(filepath.Join(dir, "missing")); err != nil || len(loaded) != 0 {
+		t.Errorf("missing dir should load nothing, got %v, %v", loaded, err)
+	}
+
+	registry := apps.NewRegistry("")
+	writeSheet("personal/vim", "# To quit:\n:q\n")
+	if err := RegisterCheatDirs(registry, []string{dir}); err != nil {
+		t.Fatalf("RegisterCheatDirs() error = %v", err)
+	}
+	if _, ok := registry.Get("tar"); !ok {
+		t.Error("tar sheet should be registered")
+	}
+	if vim, _ := registry.Get("vim"); vim.Metadata["source"] == "cheat" {
+		t.Error("built-in vim should not be replaced by a sheet")
+	}
+}
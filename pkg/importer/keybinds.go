@@ -0,0 +1,481 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// vimModes maps the map commands of vim to the mode they map keys in,
+// which becomes the category of the shortcut
+var vimModes = map[string]string{
+	"map": "normal", "noremap": "normal", "nmap": "normal", "nnoremap": "normal",
+	"vmap": "visual", "vnoremap": "visual", "xmap": "visual", "xnoremap": "visual",
+	"smap": "select", "snoremap": "select",
+	"omap": "operator", "onoremap": "operator",
+	"imap": "insert", "inoremap": "insert",
+	"cmap": "command", "cnoremap": "command",
+	"tmap": "terminal", "tnoremap": "terminal",
+}
+
+// vimMapArgs are the special arguments of a map command preceding its keys
+var vimMapArgs = map[string]bool{
+	"<buffer>": true, "<nowait>": true, "<silent>": true, "<special>": true,
+	"<script>": true, "<expr>": true, "<unique>": true,
+}
+
+// mapping is a key mapping read from a configuration file
+type mapping struct {
+	keys, description, category string
+}
+
+// mappings collects the mappings of a configuration file in the order they
+// are defined. Mapping keys again replaces the earlier mapping in place.
+type mappings struct {
+	list []mapping
+}
+
+func (m *mappings) find(keys, category string) int {
+	for i, existing := range m.list {
+		if existing.keys == keys && existing.category == category {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *mappings) add(keys, description, category string) {
+	if i := m.find(keys, category); i >= 0 {
+		m.list[i].description = description
+		return
+	}
+	m.list = append(m.list, mapping{keys, description, category})
+}
+
+func (m *mappings) remove(keys, category string) {
+	if i := m.find(keys, category); i >= 0 {
+		m.list = append(m.list[:i], m.list[i+1:]...)
+	}
+}
+
+// app turns the collected mappings into the app name, described as the
+// mappings of tool and with source as its metadata source
+func (m *mappings) app(name, tool, source string) (*apps.App, error) {
+	if len(m.list) == 0 {
+		return nil, ErrNoShortcuts
+	}
+	app := &apps.App{
+		Name:        name,
+		Description: "My " + tool + " key mappings",
+		Version:     "1.0",
+		Metadata:    map[string]string{"source": source},
+	}
+	seen := map[string]bool{}
+	for _, mapping := range m.list {
+		app.Shortcuts = append(app.Shortcuts, apps.Shortcut{
+			Keys:        mapping.keys,
+			Description: mapping.description,
+			Category:    mapping.category,
+		})
+		if !seen[mapping.category] {
+			seen[mapping.category] = true
+			app.Categories = append(app.Categories, mapping.category)
+		}
+	}
+	return app, nil
+}
+
+// configLines calls fn with every trimmed line of a configuration file
+// that is not a comment, together with the comment lines directly above
+// it. Lines starting with comment are comments; comments followed by an
+// empty line are passed with an empty line.
+func configLines(data []byte, comment string, fn func(line string, above []string)) error {
+	var above []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			if len(above) > 0 {
+				fn("", above)
+			}
+			above = nil
+		case strings.HasPrefix(line, comment):
+			above = append(above, strings.TrimSpace(strings.TrimPrefix(line, comment)))
+		default:
+			fn(line, above)
+			above = nil
+		}
+	}
+	return scanner.Err()
+}
+
+// mappingDescription describes a mapping by the comment above it, falling
+// back to what it runs
+func mappingDescription(above []string, action string) string {
+	if desc := cheatDescription(above); desc != "" {
+		return desc
+	}
+	return action
+}
+
+// nextWord splits the first word off s, unquoting it, and returns it with
+// the rest of s
+func nextWord(s string) (string, string) {
+	s = strings.TrimLeft(s, " \t")
+	if s == "" {
+		return "", ""
+	}
+	if quote := s[0]; quote == '"' || quote == '\'' {
+		if end := strings.IndexByte(s[1:], quote); end >= 0 {
+			return s[1 : end+1], strings.TrimLeft(s[end+2:], " \t")
+		}
+	}
+	if end := strings.IndexAny(s, " \t"); end >= 0 {
+		return s[:end], strings.TrimLeft(s[end:], " \t")
+	}
+	return s, ""
+}
+
+// ParseVimrc converts the key mappings of a vimrc into an app definition.
+// Every map command becomes a shortcut in the category of its mode,
+// described by the comment lines above it or else by what it maps to.
+// <Leader> is replaced by the mapleader set before the mapping, and unmap
+// commands and mappings to <Nop> drop the keys again.
+func ParseVimrc(name string, data []byte) (*apps.App, error) {
+	if name == "" {
+		name = "my-vim"
+	}
+
+	var found mappings
+	leader := `\`
+	err := configLines(data, `"`, func(line string, above []string) {
+		if value, ok := vimLet(line, "mapleader"); ok {
+			leader = value
+			return
+		}
+
+		command, rest := nextWord(line)
+		command = strings.TrimPrefix(command, ":")
+		unmap := strings.HasSuffix(command, "unmap")
+		if unmap {
+			command = strings.TrimSuffix(command, "unmap") + "map"
+		}
+		mode, ok := vimModes[command]
+		if !ok {
+			return
+		}
+
+		keys, rest := nextWord(rest)
+		for vimMapArgs[strings.ToLower(keys)] {
+			keys, rest = nextWord(rest)
+		}
+		if keys == "" {
+			return
+		}
+		keys = vimLeader(keys, leader)
+		switch {
+		case unmap || strings.EqualFold(rest, "<Nop>"):
+			found.remove(keys, mode)
+		case rest != "":
+			// a map command without what to map to only lists mappings
+			found.add(keys, mappingDescription(above, rest), mode)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vimrc: %w", err)
+	}
+	return found.app(name, "vim", "vimrc")
+}
+
+// vimLet returns the value a let command gives variable
+func vimLet(line, variable string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, "let ")
+	if !ok {
+		return "", false
+	}
+	name, value, ok := strings.Cut(rest, "=")
+	if !ok || strings.TrimPrefix(strings.TrimSpace(name), "g:") != variable {
+		return "", false
+	}
+	value, _ = nextWord(value)
+	value = strings.ReplaceAll(value, `\\`, `\`)
+	if value == " " || value == "" {
+		value = "<Space>"
+	}
+	return value, true
+}
+
+// vimLeader replaces <Leader> in keys, which vim accepts in any case
+func vimLeader(keys, leader string) string {
+	for {
+		i := strings.Index(strings.ToLower(keys), "<leader>")
+		if i < 0 {
+			return keys
+		}
+		keys = keys[:i] + leader + keys[i+len("<leader>"):]
+	}
+}
+
+// ParseTmux converts the key bindings of a tmux.conf into an app
+// definition. Keys of the prefix table are written after the prefix the
+// file sets last, C-b by default, as tmux reads them when they are pressed,
+// and keys of other tables are put in a category
+// named after the table. A binding is described by its note (bind -N), the
+// comment lines above it or else by its command.
+func ParseTmux(name string, data []byte) (*apps.App, error) {
+	if name == "" {
+		name = "my-tmux"
+	}
+
+	var found mappings
+	prefix := "C-b"
+	err := configLines(data, "#", func(line string, above []string) {
+		command, rest := nextWord(line)
+		switch command {
+		case "set", "set-option":
+			if value, ok := tmuxOption(rest, "prefix"); ok {
+				prefix = value
+			}
+			return
+		case "bind", "bind-key", "unbind", "unbind-key":
+		default:
+			return
+		}
+
+		table, note := "prefix", ""
+		keys, rest := nextWord(rest)
+		for strings.HasPrefix(keys, "-") && len(keys) > 1 {
+			flags := keys[1:]
+			if strings.Contains(flags, "n") {
+				table = "root"
+			}
+			if strings.Contains(flags, "T") {
+				table, rest = nextWord(rest)
+			}
+			if strings.Contains(flags, "N") {
+				note, rest = nextWord(rest)
+			}
+			keys, rest = nextWord(rest)
+		}
+		if keys == "" {
+			return
+		}
+
+		if strings.HasPrefix(command, "unbind") {
+			found.remove(keys, table)
+			return
+		}
+		if rest == "" {
+			return
+		}
+		description := note
+		if description == "" {
+			description = mappingDescription(above, rest)
+		}
+		found.add(keys, description, table)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tmux.conf: %w", err)
+	}
+
+	for i, mapping := range found.list {
+		if mapping.category == "prefix" {
+			found.list[i].keys = prefix + " " + mapping.keys
+		}
+	}
+	return found.app(name, "tmux", "tmux.conf")
+}
+
+// tmuxOption returns the value the arguments of a set command give option
+func tmuxOption(args, option string) (string, bool) {
+	word, rest := nextWord(args)
+	for strings.HasPrefix(word, "-") {
+		word, rest = nextWord(rest)
+	}
+	if word != option {
+		return "", false
+	}
+	value, _ := nextWord(rest)
+	return value, value != ""
+}
+
+// ParseKitty converts the keyboard shortcuts of a kitty.conf into an app
+// definition. kitty_mod is replaced by the modifiers set in the file,
+// ctrl+shift by default, and the keys of multi-key shortcuts are separated
+// by spaces. Shortcuts are put in the category of the "#: Section {{{"
+// fold above them, as in the default kitty.conf, and mapping keys to no_op
+// drops them again.
+func ParseKitty(name string, data []byte) (*apps.App, error) {
+	if name == "" {
+		name = "my-kitty"
+	}
+
+	var found mappings
+	mod := "ctrl+shift"
+	category := "general"
+	err := configLines(data, "#", func(line string, above []string) {
+		var comments []string
+		for _, comment := range above {
+			comment = strings.TrimSpace(strings.TrimPrefix(comment, ":"))
+			if heading, ok := strings.CutSuffix(comment, "{{{"); ok {
+				if slug := slugify(heading); slug != "" {
+					category = slug
+				}
+				comments = nil
+				continue
+			}
+			if comment != "}}}" {
+				comments = append(comments, comment)
+			}
+		}
+
+		command, rest := nextWord(line)
+		switch command {
+		case "kitty_mod":
+			mod, _ = nextWord(rest)
+			return
+		case "map":
+		default:
+			return
+		}
+
+		keys, rest := nextWord(rest)
+		for strings.HasPrefix(keys, "--") {
+			if !strings.Contains(keys, "=") {
+				// the option's value
+				_, rest = nextWord(rest)
+			}
+			keys, rest = nextWord(rest)
+		}
+		if keys == "" {
+			return
+		}
+		keys = strings.ReplaceAll(keys, "kitty_mod", mod)
+		keys = strings.ReplaceAll(keys, ">", " ")
+
+		if rest == "" || rest == "no_op" {
+			found.remove(keys, category)
+			return
+		}
+		found.add(keys, mappingDescription(comments, rest), category)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kitty.conf: %w", err)
+	}
+	return found.app(name, "kitty", "kitty.conf")
+}
+
+// i3Modifiers names the modifiers i3 and sway know by number
+var i3Modifiers = map[string]string{"Mod1": "Alt", "Mod4": "Super"}
+
+// ParseI3 converts the bindsym lines of an i3 config into an app
+// definition. Bindings of the default mode are in the general category and
+// those of other modes in a category named after the mode. Variables set
+// with "set $name value" are replaced, and Mod1 and Mod4 are written as
+// Alt and Super.
+func ParseI3(name string, data []byte) (*apps.App, error) {
+	if name == "" {
+		name = "my-i3"
+	}
+	return parseI3(name, "i3", data)
+}
+
+// ParseSway converts the bindsym lines of a sway config into an app
+// definition, as ParseI3 does. Blocks of bindings (bindsym { ... }) are
+// understood as well.
+func ParseSway(name string, data []byte) (*apps.App, error) {
+	if name == "" {
+		name = "my-sway"
+	}
+	return parseI3(name, "sway", data)
+}
+
+func parseI3(name, tool string, data []byte) (*apps.App, error) {
+	var found mappings
+	variables := map[string]string{}
+	var names []string
+	category, inBlock := "general", false
+
+	expand := func(s string) string {
+		for _, variable := range names {
+			s = strings.ReplaceAll(s, variable, variables[variable])
+		}
+		return s
+	}
+	bind := func(line string, above []string) {
+		keys, rest := nextWord(line)
+		for strings.HasPrefix(keys, "--") {
+			keys, rest = nextWord(rest)
+		}
+		if keys == "" || rest == "" {
+			return
+		}
+		chord := strings.Split(expand(keys), "+")
+		for i, key := range chord {
+			if modifier, ok := i3Modifiers[key]; ok {
+				chord[i] = modifier
+			}
+		}
+		action := strings.TrimPrefix(rest, "exec ")
+		action = strings.TrimPrefix(action, "--no-startup-id ")
+		found.add(strings.Join(chord, "+"), mappingDescription(above, expand(action)), category)
+	}
+
+	err := configLines(data, "#", func(line string, above []string) {
+		if line == "" {
+			return
+		}
+		if line == "}" {
+			if inBlock {
+				inBlock = false
+			} else {
+				category = "general"
+			}
+			return
+		}
+		if inBlock {
+			bind(line, above)
+			return
+		}
+
+		command, rest := nextWord(line)
+		switch command {
+		case "set":
+			variable, value := nextWord(rest)
+			if strings.HasPrefix(variable, "$") {
+				if _, ok := variables[variable]; !ok {
+					names = append(names, variable)
+					// longer names first, so $mode_resize is not read as $mode
+					sort.SliceStable(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+				}
+				variables[variable] = expand(value)
+			}
+		case "bindsym":
+			if rest == "{" {
+				inBlock = true
+				return
+			}
+			bind(rest, above)
+		case "mode":
+			mode, open := nextWord(rest)
+			for strings.HasPrefix(mode, "--") {
+				mode, open = nextWord(open)
+			}
+			if open == "{" {
+				category = slugify(expand(mode))
+				if category == "" || category == "default" {
+					category = "general"
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s config: %w", tool, err)
+	}
+	return found.app(name, tool, tool+" config")
+}
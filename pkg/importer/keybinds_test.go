@@ -0,0 +1,192 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// shortcutRows lists keys, description and category of each shortcut
+func shortcutRows(app *apps.App) [][3]string {
+	var rows [][3]string
+	for _, s := range app.Shortcuts {
+		rows = append(rows, [3]string{s.Keys, s.Description, s.Category})
+	}
+	return rows
+}
+
+func TestParseVimrc(t *testing.T) {
+	vimrc := `set number
+let mapleader = ","
+
+" Save the file
+nnoremap <silent> <leader>w :w<CR>
+inoremap jk <Esc>
+vmap <Leader>y "+y
+nmap Q gq
+nunmap Q
+nnoremap <Space> <Nop>
+nnoremap
+`
+	app, err := ParseVimrc("", []byte(vimrc))
+	if err != nil {
+		t.Fatalf("ParseVimrc() error = %v", err)
+	}
+	if app.Name != "my-vim" || app.Metadata["source"] != "vimrc" {
+		t.Errorf("app = %s %v", app.Name, app.Metadata)
+	}
+	want := [][3]string{
+		{",w", "Save the file", "normal"},
+		{"jk", "<Esc>", "insert"},
+		{",y", `"+y`, "visual"},
+	}
+	if got := shortcutRows(app); !reflect.DeepEqual(got, want) {
+		t.Errorf("shortcuts = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(app.Categories, []string{"normal", "insert", "visual"}) {
+		t.Errorf("categories = %v", app.Categories)
+	}
+
+	app, _ = ParseVimrc("vim-keys", []byte("let g:mapleader = \" \"\nnnoremap <leader>f :Files<CR>\n"))
+	if app.Name != "vim-keys" || app.Shortcuts[0].Keys != "<Space>f" {
+		t.Errorf("got %s %+v", app.Name, app.Shortcuts)
+	}
+
+	if _, err := ParseVimrc("", []byte("set number\n")); err != ErrNoShortcuts {
+		t.Errorf("expected ErrNoShortcuts, got %v", err)
+	}
+}
+
+func TestParseTmux(t *testing.T) {
+	conf := `set -g prefix C-a
+unbind C-b
+
+# Split panes side by side
+bind | split-window -h
+bind-key -r -N "Resize the pane left" H resize-pane -L 5
+bind -n M-Left select-pane -L
+bind-key -T copy-mode-vi v send -X begin-selection
+bind r source-file ~/.tmux.conf \; display "Reloaded"
+bind x kill-pane
+unbind x
+`
+	app, err := ParseTmux("", []byte(conf))
+	if err != nil {
+		t.Fatalf("ParseTmux() error = %v", err)
+	}
+	want := [][3]string{
+		{"C-a |", "Split panes side by side", "prefix"},
+		{"C-a H", "Resize the pane left", "prefix"},
+		{"M-Left", "select-pane -L", "root"},
+		{"v", "send -X begin-selection", "copy-mode-vi"},
+		{"C-a r", `source-file ~/.tmux.conf \; display "Reloaded"`, "prefix"},
+	}
+	if got := shortcutRows(app); !reflect.DeepEqual(got, want) {
+		t.Errorf("shortcuts =\n%v\nwant\n%v", got, want)
+	}
+	if app.Name != "my-tmux" {
+		t.Errorf("name = %s", app.Name)
+	}
+}
+
+func TestParseTmux_PrefixSetLast(t *testing.T) {
+	conf := `bind | split-window -h
+bind -n M-Left select-pane -L
+set -g prefix C-b
+set -g prefix C-a
+`
+	app, err := ParseTmux("tmux", []byte(conf))
+	if err != nil {
+		t.Fatalf("ParseTmux() error = %v", err)
+	}
+	want := [][3]string{
+		{"C-a |", "split-window -h", "prefix"},
+		{"M-Left", "select-pane -L", "root"},
+	}
+	if got := shortcutRows(app); !reflect.DeepEqual(got, want) {
+		t.Errorf("shortcuts =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestParseKitty(t *testing.T) {
+	conf := `font_size 12
+kitty_mod ctrl+alt
+
+#: Window management {{{
+
+#: Open a new window
+map kitty_mod+enter new_window
+map --when-focus-on title:vim ctrl+w no_op
+map ctrl+x>ctrl+y close_window
+
+#: }}}
+
+#: Tab management {{{
+map kitty_mod+t new_tab
+map kitty_mod+q close_tab
+map kitty_mod+q no_op
+`
+	app, err := ParseKitty("", []byte(conf))
+	if err != nil {
+		t.Fatalf("ParseKitty() error = %v", err)
+	}
+	want := [][3]string{
+		{"ctrl+alt+enter", "Open a new window", "window-management"},
+		{"ctrl+x ctrl+y", "close_window", "window-management"},
+		{"ctrl+alt+t", "new_tab", "tab-management"},
+	}
+	if got := shortcutRows(app); !reflect.DeepEqual(got, want) {
+		t.Errorf("shortcuts =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestParseI3(t *testing.T) {
+	conf := `set $mod Mod4
+set $mode_resize Resize mode
+set $term alacritty
+
+# Start a terminal
+bindsym $mod+Return exec $term
+bindsym --release $mod+Shift+q kill
+bindcode 38 focus left
+bindsym Mod1+Tab exec --no-startup-id rofi -show window
+
+mode "$mode_resize" {
+    bindsym h resize shrink width 10 px
+    bindsym Escape mode "default"
+}
+bindsym $mod+r mode "$mode_resize"
+`
+	app, err := ParseI3("", []byte(conf))
+	if err != nil {
+		t.Fatalf("ParseI3() error = %v", err)
+	}
+	want := [][3]string{
+		{"Super+Return", "Start a terminal", "general"},
+		{"Super+Shift+q", "kill", "general"},
+		{"Alt+Tab", "rofi -show window", "general"},
+		{"h", "resize shrink width 10 px", "resize-mode"},
+		{"Escape", `mode "default"`, "resize-mode"},
+		{"Super+r", `mode "Resize mode"`, "general"},
+	}
+	if got := shortcutRows(app); !reflect.DeepEqual(got, want) {
+		t.Errorf("shortcuts =\n%v\nwant\n%v", got, want)
+	}
+	if app.Name != "my-i3" || !reflect.DeepEqual(app.Categories, []string{"general", "resize-mode"}) {
+		t.Errorf("app = %s %v", app.Name, app.Categories)
+	}
+
+	app, err = ParseSway("", []byte("set $mod Mod4\nbindsym {\n    $mod+1 workspace number 1\n    $mod+2 workspace number 2\n}\nbindsym $mod+d exec wofi\n"))
+	if err != nil {
+		t.Fatalf("ParseSway() error = %v", err)
+	}
+	want = [][3]string{
+		{"Super+1", "workspace number 1", "general"},
+		{"Super+2", "workspace number 2", "general"},
+		{"Super+d", "wofi", "general"},
+	}
+	if got := shortcutRows(app); !reflect.DeepEqual(got, want) || app.Name != "my-sway" {
+		t.Errorf("shortcuts of %s = %v, want %v", app.Name, got, want)
+	}
+}
@@ -0,0 +1,220 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+var (
+	ErrNoShortcuts = errors.New("no shortcuts found")
+)
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*$`)
+	listItemPattern = regexp.MustCompile("^\\s*[-*+]\\s+(.+)$")
+	separatorRow    = regexp.MustCompile(`^\|?\s*:?-{2,}:?\s*(\|\s*:?-{2,}:?\s*)*\|?$`)
+)
+
+// headerWords are table header cells that mark a header row rather than data
+var headerWords = map[string]bool{
+	"key": true, "keys": true, "shortcut": true, "shortcuts": true,
+	"binding": true, "command": true, "description": true, "action": true,
+}
+
+// ParseMarkdown converts a markdown cheat sheet into an app definition.
+// Tables use the first column as keys and the second as description, and
+// list items of the form "key: description" are recognised as shortcuts.
+// Headings become categories; the first level-one heading names the app
+// when name is empty.
+func ParseMarkdown(name string, data []byte) (*apps.App, error) {
+	app := &apps.App{
+		Name:     name,
+		Version:  "1.0",
+		Metadata: map[string]string{"source": "markdown"},
+	}
+
+	category := "general"
+	categories := []string{}
+	seenCategory := map[string]bool{}
+	var title string
+	inCode := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode || trimmed == "" {
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(trimmed); m != nil {
+			text := cleanInline(m[2])
+			if len(m[1]) == 1 && title == "" {
+				title = text
+				continue
+			}
+			category = slugify(text)
+			continue
+		}
+
+		var keys, desc string
+		switch {
+		case strings.HasPrefix(trimmed, "|"):
+			if separatorRow.MatchString(trimmed) {
+				continue
+			}
+			keys, desc = parseTableRow(trimmed)
+		default:
+			if m := listItemPattern.FindStringSubmatch(line); m != nil {
+				keys, desc = parseListItem(m[1])
+			} else if app.Description == "" && title != "" && category == "general" {
+				app.Description = cleanInline(trimmed)
+			}
+		}
+
+		if keys == "" || desc == "" {
+			continue
+		}
+
+		app.Shortcuts = append(app.Shortcuts, apps.Shortcut{
+			Keys:        keys,
+			Description: desc,
+			Category:    category,
+		})
+		if !seenCategory[category] {
+			seenCategory[category] = true
+			categories = append(categories, category)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read markdown: %w", err)
+	}
+
+	if app.Name == "" {
+		app.Name = slugify(title)
+	}
+	if app.Name == "" {
+		return nil, fmt.Errorf("app name is required")
+	}
+	if app.Description == "" {
+		app.Description = title
+	}
+	if app.Description == "" {
+		app.Description = app.Name + " cheat sheet"
+	}
+	if len(app.Shortcuts) == 0 {
+		return nil, ErrNoShortcuts
+	}
+	app.Categories = categories
+
+	return app, nil
+}
+
+// NameFromPath derives an app name from a file path
+func NameFromPath(path string) string {
+	base := filepath.Base(path)
+	return slugify(strings.TrimSuffix(base, filepath.Ext(base)))
+}
+
+// parseTableRow extracts keys and description from a markdown table row
+func parseTableRow(row string) (string, string) {
+	row = strings.TrimPrefix(strings.TrimSuffix(row, "|"), "|")
+	cells := splitTableCells(row)
+	if len(cells) < 2 {
+		return "", ""
+	}
+
+	keys := cleanInline(cells[0])
+	desc := cleanInline(cells[1])
+	if headerWords[strings.ToLower(keys)] && headerWords[strings.ToLower(desc)] {
+		return "", ""
+	}
+	return keys, desc
+}
+
+// splitTableCells splits a row on pipes that are not escaped or inside code spans
+func splitTableCells(row string) []string {
+	var cells []string
+	var current strings.Builder
+	inCode := false
+
+	for i := 0; i < len(row); i++ {
+		c := row[i]
+		switch {
+		case c == '\\' && i+1 < len(row) && row[i+1] == '|':
+			current.WriteByte('|')
+			i++
+		case c == '`':
+			inCode = !inCode
+			current.WriteByte(c)
+		case c == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	cells = append(cells, strings.TrimSpace(current.String()))
+	return cells
+}
+
+// parseListItem extracts keys and description from "key: description" items
+func parseListItem(item string) (string, string) {
+	item = strings.TrimSpace(item)
+
+	// Prefer a leading code span as the key: `ctrl+a` - description
+	if strings.HasPrefix(item, "`") {
+		if end := strings.Index(item[1:], "`"); end >= 0 {
+			keys := item[1 : end+1]
+			rest := strings.TrimSpace(item[end+2:])
+			rest = strings.TrimLeft(rest, ":-–— ")
+			return strings.TrimSpace(keys), cleanInline(rest)
+		}
+	}
+
+	idx := strings.Index(item, ": ")
+	if idx <= 0 {
+		return "", ""
+	}
+	return cleanInline(item[:idx]), cleanInline(item[idx+2:])
+}
+
+// cleanInline strips common inline markdown formatting
+func cleanInline(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, "`", "")
+	s = strings.ReplaceAll(s, "**", "")
+	s = strings.ReplaceAll(s, "__", "")
+	s = strings.ReplaceAll(s, "<kbd>", "")
+	s = strings.ReplaceAll(s, "</kbd>", "")
+	return strings.TrimSpace(s)
+}
+
+// slugify turns a heading or file name into a lowercase identifier
+func slugify(s string) string {
+	var b strings.Builder
+	lastDash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			lastDash = false
+		case !lastDash && b.Len() > 0:
+			b.WriteByte('-')
+			lastDash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
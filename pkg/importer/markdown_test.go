@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"testing"
+)
+
+const sampleMarkdown = "# Tmux\n\nTerminal multiplexer shortcuts.\n\n" +
+	"## Sessions\n\n" +
+	"| Shortcut | Description |\n" +
+	"|----------|-------------|\n" +
+	"| `C-b d` | Detach session |\n" +
+	"| `C-b s` | List sessions |\n\n" +
+	"## Panes\n\n" +
+	"- `C-b %`: Split vertically\n" +
+	"- C-b o: Next pane\n" +
+	"- just some text without a separator\n\n" +
+	"```\n| ignored | inside code |\n```\n"
+
+func TestParseMarkdown(t *testing.T) {
+	app, err := ParseMarkdown("", []byte(sampleMarkdown))
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+
+	if app.Name != "tmux" {
+		t.Errorf("expected name derived from title, got %q", app.Name)
+	}
+	if app.Description != "Terminal multiplexer shortcuts." {
+		t.Errorf("unexpected description %q", app.Description)
+	}
+	if len(app.Shortcuts) != 4 {
+		t.Fatalf("expected 4 shortcuts, got %d: %+v", len(app.Shortcuts), app.Shortcuts)
+	}
+
+	expected := []struct{ keys, desc, category string }{
+		{"C-b d", "Detach session", "sessions"},
+		{"C-b s", "List sessions", "sessions"},
+		{"C-b %", "Split vertically", "panes"},
+		{"C-b o", "Next pane", "panes"},
+	}
+	for i, e := range expected {
+		s := app.Shortcuts[i]
+		if s.Keys != e.keys || s.Description != e.desc || s.Category != e.category {
+			t.Errorf("shortcut %d = %+v, expected %+v", i, s, e)
+		}
+	}
+
+	if len(app.Categories) != 2 || app.Categories[0] != "sessions" {
+		t.Errorf("unexpected categories %v", app.Categories)
+	}
+}
+
+func TestParseMarkdown_ExplicitName(t *testing.T) {
+	app, err := ParseMarkdown("mux", []byte("| `a` | first |\n"))
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	if app.Name != "mux" {
+		t.Errorf("explicit name should win, got %q", app.Name)
+	}
+	if app.Description == "" {
+		t.Error("description should fall back to a default")
+	}
+}
+
+func TestParseMarkdown_Errors(t *testing.T) {
+	if _, err := ParseMarkdown("", []byte("| a | b |\n")); err == nil {
+		t.Error("expected error when no name can be derived")
+	}
+	if _, err := ParseMarkdown("empty", []byte("# Empty\n\nNothing here.\n")); err != ErrNoShortcuts {
+		t.Errorf("expected ErrNoShortcuts, got %v", err)
+	}
+}
+
+func TestParseTableRow_EscapedPipe(t *testing.T) {
+	keys, desc := parseTableRow("| `a \\| b` | pipe |")
+	if keys != "a | b" || desc != "pipe" {
+		t.Errorf("got %q / %q", keys, desc)
+	}
+
+	keys, desc = parseTableRow("| `x|y` | code pipe |")
+	if keys != "x|y" || desc != "code pipe" {
+		t.Errorf("got %q / %q", keys, desc)
+	}
+}
+
+func TestNameFromPath(t *testing.T) {
+	if got := NameFromPath("/tmp/My Tmux.md"); got != "my-tmux" {
+		t.Errorf("NameFromPath() = %q", got)
+	}
+}
@@ -0,0 +1,172 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// vscodeBinding is an entry of VS Code's keybindings.json
+type vscodeBinding struct {
+	Key     string `json:"key"`
+	Command string `json:"command"`
+	When    string `json:"when"`
+}
+
+// ParseVSCode converts VS Code's keybindings.json, or a dump of its default
+// keymap, into an app definition. Commands are described by their last
+// part, so editor.action.commentLine becomes "Comment line", and put in a
+// category derived from their prefix. Entries with a command starting with
+// "-" remove the binding of that key to the command, as in VS Code. Keys
+// using cmd are limited to macOS.
+func ParseVSCode(name string, data []byte) (*apps.App, error) {
+	if name == "" {
+		name = "my-vscode"
+	}
+
+	var entries []vscodeBinding
+	if err := json.Unmarshal(stripJSONComments(data), &entries); err != nil {
+		return nil, fmt.Errorf("failed to read keybindings: %w", err)
+	}
+
+	type binding struct{ key, command string }
+	var bindings []binding
+	seen := map[binding]bool{}
+	for _, entry := range entries {
+		key := strings.Join(strings.Fields(strings.ToLower(entry.Key)), " ")
+		if key == "" || entry.Command == "" {
+			continue
+		}
+		if command, ok := strings.CutPrefix(entry.Command, "-"); ok {
+			removed := binding{key, command}
+			delete(seen, removed)
+			for i := 0; i < len(bindings); i++ {
+				if bindings[i] == removed {
+					bindings = append(bindings[:i], bindings[i+1:]...)
+					i--
+				}
+			}
+			continue
+		}
+		// the same binding under different when clauses is listed once
+		if b := (binding{key, entry.Command}); !seen[b] {
+			seen[b] = true
+			bindings = append(bindings, b)
+		}
+	}
+	if len(bindings) == 0 {
+		return nil, ErrNoShortcuts
+	}
+
+	app := &apps.App{
+		Name:        name,
+		Description: "VS Code key bindings",
+		Version:     "1.0",
+		Metadata:    map[string]string{"source": "vscode"},
+	}
+	categories := map[string]bool{}
+	for _, b := range bindings {
+		shortcut := apps.Shortcut{
+			Keys:        b.key,
+			Description: vscodeDescription(b.command),
+			Category:    vscodeCategory(b.command),
+		}
+		if strings.Contains(b.key, "cmd+") {
+			shortcut.Platform = "macos"
+		}
+		app.Shortcuts = append(app.Shortcuts, shortcut)
+		if !categories[shortcut.Category] {
+			categories[shortcut.Category] = true
+			app.Categories = append(app.Categories, shortcut.Category)
+		}
+	}
+	return app, nil
+}
+
+// vscodeCategory derives a category from the prefix of a command:
+// workbench.action.files.save is in files, other workbench and editor
+// actions in workbench and editor, and extension commands such as
+// git.commit in the extension's name
+func vscodeCategory(command string) string {
+	parts := strings.Split(command, ".")
+	switch {
+	case len(parts) == 1:
+		return "general"
+	case len(parts) > 3 && parts[0] == "workbench" && parts[1] == "action":
+		return slugify(parts[2])
+	}
+	return slugify(parts[0])
+}
+
+// vscodeDescription turns the last part of a command, such as
+// commentLine or toggle_sidebar, into words
+func vscodeDescription(command string) string {
+	last := command[strings.LastIndex(command, ".")+1:]
+	var words strings.Builder
+	prev := ' '
+	for _, r := range last {
+		switch {
+		case r == '_' || r == '-':
+			r = ' '
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			words.WriteByte(' ')
+		}
+		words.WriteRune(unicode.ToLower(r))
+		prev = r
+	}
+	desc := strings.Join(strings.Fields(words.String()), " ")
+	if desc == "" {
+		return command
+	}
+	return strings.ToUpper(desc[:1]) + desc[1:]
+}
+
+// stripJSONComments removes the comments and trailing commas VS Code
+// allows in its JSON files
+func stripJSONComments(data []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			out = append(out, '\n')
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := strings.Index(string(data[i+2:]), "*/")
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case c == ']' || c == '}':
+			// drop a comma before the closing bracket
+			j := len(out) - 1
+			for j >= 0 && unicode.IsSpace(rune(out[j])) {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
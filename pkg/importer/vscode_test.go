@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVSCode(t *testing.T) {
+	keybindings := `// Place your key bindings in this file to override the defaults
+[
+	{
+		"key": "ctrl+shift+/",
+		"command": "editor.action.commentLine",
+		"when": "editorTextFocus && !editorReadonly"
+	},
+	/* saving */
+	{ "key": "cmd+s", "command": "workbench.action.files.save" },
+	{ "key": "ctrl+k  ctrl+w", "command": "workbench.action.closeAllEditors" },
+	{ "key": "ctrl+k ctrl+w", "command": "workbench.action.closeAllEditors", "when": "other" },
+	{ "key": "ctrl+alt+g", "command": "git.commit" },
+	{ "key": "ctrl+alt+g", "command": "-git.commit" },
+	{ "key": "f5", "command": "workbench.action.debug.start" },
+	{ "key": "ctrl+q", "command": "quit", },
+]
+`
+	app, err := ParseVSCode("", []byte(keybindings))
+	if err != nil {
+		t.Fatalf("ParseVSCode() error = %v", err)
+	}
+	if app.Name != "my-vscode" || app.Metadata["source"] != "vscode" {
+		t.Errorf("app = %s %v", app.Name, app.Metadata)
+	}
+	want := [][3]string{
+		{"ctrl+shift+/", "Comment line", "editor"},
+		{"cmd+s", "Save", "files"},
+		{"ctrl+k ctrl+w", "Close all editors", "workbench"},
+		{"f5", "Start", "debug"},
+		{"ctrl+q", "Quit", "general"},
+	}
+	if got := shortcutRows(app); !reflect.DeepEqual(got, want) {
+		t.Errorf("shortcuts =\n%v\nwant\n%v", got, want)
+	}
+	if app.Shortcuts[1].Platform != "macos" || app.Shortcuts[0].Platform != "" {
+		t.Errorf("cmd keys should be limited to macOS, got %+v", app.Shortcuts[:2])
+	}
+	if !reflect.DeepEqual(app.Categories, []string{"editor", "files", "workbench", "debug", "general"}) {
+		t.Errorf("categories = %v", app.Categories)
+	}
+
+	if _, err := ParseVSCode("", []byte(`{"key": "x"}`)); err == nil {
+		t.Error("expected an error for a file that is not a list")
+	}
+	if _, err := ParseVSCode("", []byte("[]")); err != ErrNoShortcuts {
+		t.Errorf("expected ErrNoShortcuts, got %v", err)
+	}
+}
+
+func TestStripJSONComments(t *testing.T) {
+	got := string(stripJSONComments([]byte(`{"a": "//not a comment", /* c */ "b": [1, 2,],}`)))
+	if want := `{"a": "//not a comment",  "b": [1, 2]}`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
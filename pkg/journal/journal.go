@@ -0,0 +1,226 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+)
+
+var (
+	ErrEntryNotFound = errors.New("journal entry not found")
+)
+
+// Action is the kind of change recorded by an entry
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Kind identifies what type of object changed
+type Kind string
+
+const (
+	KindNote Kind = "note"
+	KindApp  Kind = "app"
+)
+
+// Source identifies which part of the program made a change
+type Source string
+
+const (
+	SourceUI     Source = "ui"
+	SourceSync   Source = "sync"
+	SourceImport Source = "import"
+	SourceCLI    Source = "cli"
+)
+
+// Entry is a single recorded change. Before and After hold the JSON encoded
+// object state around the change and are empty for creates and deletes
+// respectively.
+type Entry struct {
+	Seq    int64           `json:"seq"`
+	Time   time.Time       `json:"time"`
+	Action Action          `json:"action"`
+	Kind   Kind            `json:"kind"`
+	ID     string          `json:"id"`
+	Name   string          `json:"name,omitempty"`
+	Source Source          `json:"source"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Journal is an append-only log of changes stored as JSON lines
+type Journal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Open returns the journal stored at path, creating its directory if needed
+func Open(path string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	return &Journal{path: path}, nil
+}
+
+// Path returns the journal file path
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Record appends a change to the journal. before and after are encoded as
+// JSON; pass nil for a missing side.
+func (j *Journal) Record(action Action, kind Kind, id, name string, source Source, before, after interface{}) error {
+	entry := Entry{
+		Time:   time.Now(),
+		Action: action,
+		Kind:   kind,
+		ID:     id,
+		Name:   name,
+		Source: source,
+	}
+
+	var err error
+	if entry.Before, err = encode(before); err != nil {
+		return err
+	}
+	if entry.After, err = encode(after); err != nil {
+		return err
+	}
+
+	return j.Append(&entry)
+}
+
+// Append writes entry to the end of the journal, assigning its sequence number
+func (j *Journal) Append(entry *Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// Other processes may be appending to the same journal
+	l, err := lock.Acquire(j.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	last, err := j.lastSeq()
+	if err != nil {
+		return err
+	}
+	entry.Seq = last + 1
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Entries returns all recorded entries, oldest first. Malformed lines are skipped.
+func (j *Journal) Entries() ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.readAll()
+}
+
+// Recent returns up to n entries, newest first. n <= 0 returns all entries.
+func (j *Journal) Recent(n int) ([]Entry, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	recent := []Entry{}
+	for i := len(entries) - 1; i >= 0 && (n <= 0 || len(recent) < n); i-- {
+		recent = append(recent, entries[i])
+	}
+	return recent, nil
+}
+
+// Get returns the entry with the given sequence number
+func (j *Journal) Get(seq int64) (*Entry, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].Seq == seq {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d", ErrEntryNotFound, seq)
+}
+
+func (j *Journal) readAll() ([]Entry, error) {
+	f, err := os.Open(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Entry{}, nil
+		}
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+func (j *Journal) lastSeq() (int64, error) {
+	entries, err := j.readAll()
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	return entries[len(entries)-1].Seq, nil
+}
+
+func encode(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode journal state: %w", err)
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return data, nil
+}
@@ -0,0 +1,115 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testObject struct {
+	Title string `json:"title"`
+}
+
+func TestJournal_RecordAndRead(t *testing.T) {
+	j, err := Open(filepath.Join(t.TempDir(), "logs", "journal.jsonl"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if err := j.Record(ActionCreate, KindNote, "n1", "first", SourceUI, nil, &testObject{Title: "first"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := j.Record(ActionUpdate, KindNote, "n1", "second", SourceSync, &testObject{Title: "first"}, &testObject{Title: "second"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	var missing *testObject
+	if err := j.Record(ActionDelete, KindApp, "vim", "vim", SourceImport, &testObject{Title: "vim"}, missing); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	entries, err := j.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	for i, e := range entries {
+		if e.Seq != int64(i+1) {
+			t.Errorf("entry %d has seq %d", i, e.Seq)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("entry %d has no timestamp", i)
+		}
+	}
+
+	if entries[0].Before != nil {
+		t.Error("create entry should have no before state")
+	}
+	if entries[2].After != nil {
+		t.Error("typed nil after state should be omitted")
+	}
+
+	var before testObject
+	if err := json.Unmarshal(entries[1].Before, &before); err != nil || before.Title != "first" {
+		t.Errorf("before state = %+v, %v", before, err)
+	}
+	if entries[1].Source != SourceSync {
+		t.Errorf("source = %s, want sync", entries[1].Source)
+	}
+
+	recent, _ := j.Recent(2)
+	if len(recent) != 2 || recent[0].Seq != 3 || recent[1].Seq != 2 {
+		t.Errorf("Recent(2) = %+v", recent)
+	}
+	all, _ := j.Recent(0)
+	if len(all) != 3 {
+		t.Errorf("Recent(0) returned %d entries", len(all))
+	}
+
+	entry, err := j.Get(2)
+	if err != nil || entry.Name != "second" {
+		t.Errorf("Get(2) = %+v, %v", entry, err)
+	}
+	if _, err := j.Get(42); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("Get(42) error = %v, want ErrEntryNotFound", err)
+	}
+}
+
+func TestJournal_SkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	content := `{"seq":1,"action":"create","kind":"note","id":"a"}
+not json
+{"seq":2,"action":"delete","kind":"note","id":"a"}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	j, _ := Open(path)
+	entries, err := j.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 valid entries, got %d", len(entries))
+	}
+
+	if err := j.Record(ActionCreate, KindNote, "b", "b", SourceCLI, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = j.Entries()
+	if last := entries[len(entries)-1]; last.Seq != 3 {
+		t.Errorf("new entry seq = %d, want 3", last.Seq)
+	}
+}
+
+func TestJournal_EmptyEntries(t *testing.T) {
+	j, _ := Open(filepath.Join(t.TempDir(), "journal.jsonl"))
+	entries, err := j.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Entries() on missing file = %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,93 @@
+package journal
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Item is the state of a single journaled object
+type Item struct {
+	Kind  Kind            `json:"kind"`
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	State json.RawMessage `json:"state"`
+}
+
+// SnapshotItem is an object as it existed in a snapshot, compared to now
+type SnapshotItem struct {
+	Item
+	// Changed reports that the object was modified after the snapshot
+	Changed bool `json:"changed"`
+	// Deleted reports that the object no longer exists
+	Deleted bool `json:"deleted"`
+}
+
+// Snapshot is the reconstructed state of journaled objects right after the
+// entry with sequence number Seq was applied
+type Snapshot struct {
+	Seq   int64          `json:"seq"`
+	Items []SnapshotItem `json:"items"`
+}
+
+type itemKey struct {
+	kind Kind
+	id   string
+}
+
+// Rewind reconstructs the state as of the entry numbered seq by undoing,
+// newest first, every later entry on top of the current state
+func Rewind(entries []Entry, current []Item, seq int64) *Snapshot {
+	state := make(map[itemKey]*SnapshotItem, len(current))
+	for _, item := range current {
+		state[itemKey{item.Kind, item.ID}] = &SnapshotItem{Item: item}
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Seq <= seq {
+			continue
+		}
+		key := itemKey{e.Kind, e.ID}
+
+		if e.Before == nil {
+			// Created after the snapshot
+			delete(state, key)
+			continue
+		}
+
+		item, exists := state[key]
+		if !exists {
+			item = &SnapshotItem{Item: Item{Kind: e.Kind, ID: e.ID}}
+			state[key] = item
+		}
+		item.Name = e.Name
+		item.State = e.Before
+		item.Changed = true
+	}
+
+	live := make(map[itemKey]bool, len(current))
+	for _, item := range current {
+		live[itemKey{item.Kind, item.ID}] = true
+	}
+
+	snapshot := &Snapshot{Seq: seq, Items: make([]SnapshotItem, 0, len(state))}
+	for key, item := range state {
+		if item.Changed && !live[key] {
+			item.Deleted = true
+		}
+		snapshot.Items = append(snapshot.Items, *item)
+	}
+
+	sort.Slice(snapshot.Items, func(i, j int) bool {
+		a, b := snapshot.Items[i], snapshot.Items[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+
+	return snapshot
+}
@@ -0,0 +1,65 @@
+package journal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func state(title string) json.RawMessage {
+	data, _ := json.Marshal(map[string]string{"title": title})
+	return data
+}
+
+func TestRewind(t *testing.T) {
+	entries := []Entry{
+		{Seq: 1, Action: ActionCreate, Kind: KindNote, ID: "a", Name: "A1", After: state("A1")},
+		{Seq: 2, Action: ActionUpdate, Kind: KindNote, ID: "a", Name: "A2", Before: state("A1"), After: state("A2")},
+		{Seq: 3, Action: ActionCreate, Kind: KindNote, ID: "b", Name: "B", After: state("B")},
+		{Seq: 4, Action: ActionUpdate, Kind: KindNote, ID: "a", Name: "A3", Before: state("A2"), After: state("A3")},
+		{Seq: 5, Action: ActionDelete, Kind: KindNote, ID: "a", Name: "A3", Before: state("A3")},
+		{Seq: 6, Action: ActionCreate, Kind: KindApp, ID: "vim", Name: "vim", After: state("vim")},
+	}
+	current := []Item{
+		{Kind: KindNote, ID: "b", Name: "B", State: state("B")},
+		{Kind: KindNote, ID: "c", Name: "C", State: state("C")},
+		{Kind: KindApp, ID: "vim", Name: "vim", State: state("vim")},
+	}
+
+	snapshot := Rewind(entries, current, 2)
+	if snapshot.Seq != 2 {
+		t.Errorf("Seq = %d, want 2", snapshot.Seq)
+	}
+
+	got := map[string]SnapshotItem{}
+	for _, item := range snapshot.Items {
+		got[item.ID] = item
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected notes a and c in the snapshot, got %+v", snapshot.Items)
+	}
+	a := got["a"]
+	if string(a.State) != string(state("A2")) || !a.Changed || !a.Deleted {
+		t.Errorf("note a = %+v, want deleted A2 state", a)
+	}
+	c := got["c"]
+	if c.Changed || c.Deleted {
+		t.Errorf("untouched note c should be unchanged, got %+v", c)
+	}
+	if _, exists := got["b"]; exists {
+		t.Error("note b was created after the snapshot")
+	}
+	if _, exists := got["vim"]; exists {
+		t.Error("app vim was created after the snapshot")
+	}
+
+	latest := Rewind(entries, current, 6)
+	if len(latest.Items) != len(current) {
+		t.Errorf("snapshot at the latest entry should match the current state, got %d items", len(latest.Items))
+	}
+	for _, item := range latest.Items {
+		if item.Changed {
+			t.Errorf("item %s should be unchanged at the latest entry", item.ID)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrLocked = errors.New("lock held by another process")
+)
+
+// Lock is an advisory lock on a file. The lock is released automatically by
+// the operating system when the holding process exits.
+type Lock struct {
+	file *os.File
+	path string
+}
+
+// Acquire blocks until an exclusive lock on path is obtained
+func Acquire(path string) (*Lock, error) {
+	return acquire(path, true)
+}
+
+// TryAcquire obtains an exclusive lock on path or returns ErrLocked
+func TryAcquire(path string) (*Lock, error) {
+	return acquire(path, false)
+}
+
+func acquire(path string, block bool) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFile(file, block); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &Lock{file: file, path: path}, nil
+}
+
+// Path returns the lock file path
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// Release unlocks and closes the lock file
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	unlockFile(l.file)
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// WriteFileAtomic writes data to path while holding path's companion lock so
+// concurrent writers from other processes are serialized
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	l, err := Acquire(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	return ReplaceFile(path, data, perm)
+}
+
+// ReplaceFile writes data to a temporary file and renames it into place so
+// readers never observe a partially written file
+func ReplaceFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	return os.Rename(tmpName, path)
+}
+
+// Instance marks the running process as the primary instance for a data directory
+type Instance struct {
+	lock *Lock
+}
+
+// InstanceLockedError reports another live instance holding the data directory
+type InstanceLockedError struct {
+	PID int
+}
+
+func (e *InstanceLockedError) Error() string {
+	if e.PID > 0 {
+		return fmt.Sprintf("another instance is running (pid %d)", e.PID)
+	}
+	return "another instance is running"
+}
+
+func (e *InstanceLockedError) Unwrap() error {
+	return ErrLocked
+}
+
+// AcquireInstance claims dir for this process. When another live process
+// already holds it an *InstanceLockedError is returned.
+func AcquireInstance(dir string) (*Instance, error) {
+	path := filepath.Join(dir, "instance.lock")
+
+	l, err := TryAcquire(path)
+	if err != nil {
+		if errors.Is(err, ErrLocked) {
+			return nil, &InstanceLockedError{PID: readPID(path)}
+		}
+		return nil, err
+	}
+
+	l.file.Truncate(0)
+	l.file.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+
+	return &Instance{lock: l}, nil
+}
+
+// Release gives up the instance claim
+func (i *Instance) Release() error {
+	if i == nil {
+		return nil
+	}
+	return i.lock.Release()
+}
+
+func readPID(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
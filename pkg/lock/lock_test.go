@@ -0,0 +1,93 @@
+package lock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTryAcquire_Contention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+
+	first, err := TryAcquire(path)
+	if err != nil {
+		t.Fatalf("TryAcquire() error = %v", err)
+	}
+
+	if _, err := TryAcquire(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("second TryAcquire() error = %v, want ErrLocked", err)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	second, err := TryAcquire(path)
+	if err != nil {
+		t.Fatalf("TryAcquire() after release error = %v", err)
+	}
+	second.Release()
+
+	// Releasing twice is harmless
+	if err := second.Release(); err != nil {
+		t.Errorf("second Release() error = %v", err)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.json")
+
+	if err := WriteFileAtomic(path, []byte("first"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("second"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic() overwrite error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "second" {
+		t.Errorf("file content = %q, %v", data, err)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() != "notes.json" && e.Name() != "notes.json.lock" {
+			t.Errorf("unexpected leftover file %s", e.Name())
+		}
+	}
+}
+
+func TestAcquireInstance(t *testing.T) {
+	dir := t.TempDir()
+
+	instance, err := AcquireInstance(dir)
+	if err != nil {
+		t.Fatalf("AcquireInstance() error = %v", err)
+	}
+
+	_, err = AcquireInstance(dir)
+	var locked *InstanceLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("second AcquireInstance() error = %v, want InstanceLockedError", err)
+	}
+	if locked.PID != os.Getpid() {
+		t.Errorf("PID = %d, want %d", locked.PID, os.Getpid())
+	}
+	if !errors.Is(err, ErrLocked) {
+		t.Error("InstanceLockedError should unwrap to ErrLocked")
+	}
+
+	instance.Release()
+	again, err := AcquireInstance(dir)
+	if err != nil {
+		t.Fatalf("AcquireInstance() after release error = %v", err)
+	}
+	again.Release()
+}
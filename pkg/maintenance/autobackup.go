@@ -0,0 +1,127 @@
+package maintenance
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBackupInterval is how often the scheduler backs up when no
+	// interval is configured
+	DefaultBackupInterval = 24 * time.Hour
+	// DefaultBackupKeep is how many automatic backups are kept when no
+	// retention count is configured
+	DefaultBackupKeep = 10
+)
+
+// BackupScheduler makes automatic backups of a few locations, such as
+// notes and apps, on an interval and before destructive operations. Only
+// the newest automatic backups are kept; backups made with CreateBackup
+// are never removed by it.
+type BackupScheduler struct {
+	dir       string
+	locations []Location
+	interval  time.Duration
+	keep      int
+
+	// mu serializes backups of the ticker and of Backup callers
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewBackupScheduler creates a scheduler writing to dir. A zero interval
+// or keep count uses the default.
+func NewBackupScheduler(dir string, locations []Location, interval time.Duration, keep int) *BackupScheduler {
+	if interval <= 0 {
+		interval = DefaultBackupInterval
+	}
+	if keep <= 0 {
+		keep = DefaultBackupKeep
+	}
+	return &BackupScheduler{dir: dir, locations: locations, interval: interval, keep: keep}
+}
+
+// Backup makes an automatic backup now and removes the automatic backups
+// beyond the retention count
+func (s *BackupScheduler) Backup() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, _, err := createBackup(s.dir, autoBackupPrefix, s.locations, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return path, s.prune()
+}
+
+// prune removes the oldest automatic backups beyond the retention count
+func (s *BackupScheduler) prune() error {
+	backups, err := ListBackups(s.dir)
+	if err != nil {
+		return err
+	}
+	kept := 0
+	for _, backup := range backups {
+		if !backup.Auto {
+			continue
+		}
+		if kept++; kept > s.keep {
+			if err := os.Remove(backup.Path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// due reports whether the newest automatic backup is older than the interval
+func (s *BackupScheduler) due(now time.Time) bool {
+	backups, err := ListBackups(s.dir)
+	if err != nil {
+		return true
+	}
+	for _, backup := range backups {
+		if backup.Auto {
+			return now.Sub(backup.CreatedAt) >= s.interval
+		}
+	}
+	return true
+}
+
+// Start backs up in the background whenever the interval has passed since
+// the last automatic backup, including backups of earlier runs
+func (s *BackupScheduler) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		// checking more often than the interval catches up on backups
+		// missed while the computer slept
+		ticker := time.NewTicker(min(s.interval, time.Minute))
+		defer ticker.Stop()
+
+		for {
+			if s.due(time.Now()) {
+				s.Backup()
+			}
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends background backups and waits for one in progress to finish
+func (s *BackupScheduler) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
@@ -0,0 +1,475 @@
+package maintenance
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidBackup = errors.New("invalid backup")
+)
+
+const (
+	// BackupExt ends the file name of every backup archive
+	BackupExt = ".tar.gz"
+
+	backupPrefix     = "cheat-go-"
+	autoBackupPrefix = backupPrefix + "auto-"
+	backupTimeFormat = "20060102-150405"
+	backupManifest   = "manifest.json"
+	backupVersion    = 1
+)
+
+// BackupManifest describes what a backup archive holds. It is the first
+// entry of the archive.
+type BackupManifest struct {
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"created_at"`
+	Locations []BackupLocation `json:"locations"`
+}
+
+// BackupLocation is a location as it was backed up. Files are stored under
+// the location name, so restoring puts them in the location of that name
+// wherever it is configured now.
+type BackupLocation struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// File locations hold a single file rather than a directory
+	File   bool  `json:"file,omitempty"`
+	Exists bool  `json:"exists"`
+	Files  int   `json:"files"`
+	Size   int64 `json:"size"`
+}
+
+// Backup is an archive found in a backups directory
+type Backup struct {
+	Path      string
+	CreatedAt time.Time
+	Size      int64
+	// Auto backups were made by the backup scheduler
+	Auto bool
+}
+
+// backupFile is a file to archive with its name in the archive
+type backupFile struct {
+	path string
+	name string
+	info fs.FileInfo
+}
+
+// CreateBackup archives every location into a timestamped tar.gz in dir and
+// returns its path. The archive is written under a temporary name first, so
+// an interrupted backup never looks complete.
+func CreateBackup(dir string, locations []Location, now time.Time) (string, *BackupManifest, error) {
+	return createBackup(dir, backupPrefix, locations, now)
+}
+
+func createBackup(dir, prefix string, locations []Location, now time.Time) (string, *BackupManifest, error) {
+	manifest := &BackupManifest{Version: backupVersion, CreatedAt: now.UTC()}
+	var files []backupFile
+	for _, loc := range locations {
+		entry, found, err := collectBackupFiles(loc)
+		if err != nil {
+			return "", nil, err
+		}
+		manifest.Locations = append(manifest.Locations, entry)
+		files = append(files, found...)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".backup-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeBackup(tmp, manifest, files); err != nil {
+		tmp.Close()
+		return "", nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	target := filepath.Join(dir, prefix+now.Format(backupTimeFormat)+BackupExt)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			break
+		}
+		target = filepath.Join(dir, fmt.Sprintf("%s%s-%d%s", prefix, now.Format(backupTimeFormat), i, BackupExt))
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", nil, fmt.Errorf("failed to save backup: %w", err)
+	}
+	return target, manifest, nil
+}
+
+// collectBackupFiles lists the files of a location, named in the archive
+// by the location name and their path relative to it
+func collectBackupFiles(loc Location) (BackupLocation, []backupFile, error) {
+	entry := BackupLocation{Name: loc.Name, Path: loc.Path}
+
+	info, err := os.Stat(loc.Path)
+	if os.IsNotExist(err) {
+		return entry, nil, nil
+	}
+	if err != nil {
+		return entry, nil, fmt.Errorf("failed to stat %s: %w", loc.Path, err)
+	}
+	entry.Exists = true
+
+	if !info.IsDir() {
+		entry.File, entry.Files, entry.Size = true, 1, info.Size()
+		return entry, []backupFile{{path: loc.Path, name: loc.Name + "/" + filepath.Base(loc.Path), info: info}}, nil
+	}
+
+	var files []backupFile
+	err = filepath.WalkDir(loc.Path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if loc.Shallow && p != loc.Path {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !matchesExtension(d.Name(), loc.Extensions) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(loc.Path, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, backupFile{path: p, name: loc.Name + "/" + filepath.ToSlash(rel), info: info})
+		entry.Files++
+		entry.Size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return entry, nil, fmt.Errorf("failed to read %s: %w", loc.Path, err)
+	}
+	return entry, files, nil
+}
+
+func writeBackup(w io.Writer, manifest *BackupManifest, files []backupFile) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: backupManifest, Mode: 0644, Size: int64(len(data)), ModTime: manifest.CreatedAt}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		header, err := tar.FileInfoHeader(file.info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = file.name
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if err := copyInto(tw, file.path); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func copyInto(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// ListBackups returns the backups in dir, newest first
+func ListBackups(dir string) ([]Backup, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, BackupExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		auto := strings.HasPrefix(name, autoBackupPrefix)
+		stamp := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(name, autoBackupPrefix), backupPrefix), BackupExt)
+		created, err := time.ParseInLocation(backupTimeFormat, stamp[:min(len(stamp), len(backupTimeFormat))], time.Local)
+		if err != nil {
+			created = info.ModTime()
+		}
+		backups = append(backups, Backup{Path: filepath.Join(dir, name), CreatedAt: created, Size: info.Size(), Auto: auto})
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		if !backups[i].CreatedAt.Equal(backups[j].CreatedAt) {
+			return backups[i].CreatedAt.After(backups[j].CreatedAt)
+		}
+		// backups made in the same second are numbered -1, -2, ...
+		if len(backups[i].Path) != len(backups[j].Path) {
+			return len(backups[i].Path) > len(backups[j].Path)
+		}
+		return backups[i].Path > backups[j].Path
+	})
+	return backups, nil
+}
+
+// ReadBackupManifest returns the manifest of a backup archive
+func ReadBackupManifest(archive string) (*BackupManifest, error) {
+	var manifest *BackupManifest
+	err := readBackup(archive, func(m *BackupManifest) error {
+		manifest = m
+		return errStopReading
+	}, nil)
+	if err != nil && !errors.Is(err, errStopReading) {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// errStopReading ends readBackup early without an error
+var errStopReading = errors.New("stop reading")
+
+// readBackup calls onManifest with the manifest and then onFile for every
+// file of the archive with its location and path relative to it
+func readBackup(archive string, onManifest func(*BackupManifest) error, onFile func(location, rel string, header *tar.Header, r io.Reader) error) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != backupManifest {
+		return fmt.Errorf("%w: missing manifest", ErrInvalidBackup)
+	}
+	var manifest BackupManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return fmt.Errorf("%w: manifest: %v", ErrInvalidBackup, err)
+	}
+	if manifest.Version > backupVersion {
+		return fmt.Errorf("%w: version %d is newer than this cheat-go supports", ErrInvalidBackup, manifest.Version)
+	}
+	if err := onManifest(&manifest); err != nil {
+		return err
+	}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		location, rel, ok := strings.Cut(header.Name, "/")
+		if !ok || rel == "" || path.IsAbs(rel) || path.Clean(rel) != rel || rel == ".." || strings.HasPrefix(rel, "../") {
+			return fmt.Errorf("%w: unsafe entry %q", ErrInvalidBackup, header.Name)
+		}
+		if err := onFile(location, rel, header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// RestoreBackup replaces the locations recorded in a backup with their
+// backed up contents. Locations are matched by name, and those the backup
+// does not record are left alone. Everything is extracted next to its
+// destination first and then renamed into place, rolling back if any rename
+// fails, so a failed restore leaves the current data as it was.
+func RestoreBackup(archive string, locations []Location) (*BackupManifest, error) {
+	byName := make(map[string]Location, len(locations))
+	for _, loc := range locations {
+		byName[loc.Name] = loc
+	}
+
+	var manifest *BackupManifest
+	recorded := make(map[string]BackupLocation)
+	staged := make(map[string]string)
+	defer func() {
+		for _, dir := range staged {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	err := readBackup(archive, func(m *BackupManifest) error {
+		manifest = m
+		for _, entry := range m.Locations {
+			loc, ok := byName[entry.Name]
+			if !ok {
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(loc.Path), 0755); err != nil {
+				return err
+			}
+			dir, err := os.MkdirTemp(filepath.Dir(loc.Path), "."+filepath.Base(loc.Path)+".restore-*")
+			if err != nil {
+				return fmt.Errorf("failed to stage %s: %w", entry.Name, err)
+			}
+			staged[entry.Name] = dir
+			recorded[entry.Name] = entry
+		}
+		return nil
+	}, func(location, rel string, header *tar.Header, r io.Reader) error {
+		dir, ok := staged[location]
+		if !ok {
+			return nil
+		}
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, r); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var plan renamePlan
+	for _, loc := range locations {
+		entry, ok := recorded[loc.Name]
+		if !ok {
+			continue
+		}
+		dir := staged[loc.Name]
+		switch {
+		case entry.File:
+			plan.replace(loc.Path, filepath.Join(dir, filepath.Base(entry.Path)), entry.Exists)
+		case loc.Shallow:
+			if err := plan.replaceShallow(loc, dir); err != nil {
+				return nil, err
+			}
+		default:
+			plan.replace(loc.Path, dir, entry.Exists)
+		}
+	}
+	if err := plan.apply(); err != nil {
+		return nil, fmt.Errorf("restore failed, nothing was changed: %w", err)
+	}
+	plan.cleanup()
+	return manifest, nil
+}
+
+// renamePlan is a list of renames applied in order and undone in reverse
+// if one fails. Replaced files are renamed aside and removed on cleanup.
+type renamePlan struct {
+	renames [][2]string
+	aside   []string
+}
+
+// replace puts staged at target, moving target aside first. A staged path
+// that was not backed up only removes target.
+func (p *renamePlan) replace(target, staged string, exists bool) {
+	if _, err := os.Lstat(target); err == nil {
+		aside := fmt.Sprintf("%s.old-%d", target, time.Now().UnixNano())
+		p.renames = append(p.renames, [2]string{target, aside})
+		p.aside = append(p.aside, aside)
+	}
+	if exists {
+		p.renames = append(p.renames, [2]string{staged, target})
+	}
+}
+
+// replaceShallow replaces the matching files directly in a shallow location
+// with the staged ones
+func (p *renamePlan) replaceShallow(loc Location, staged string) error {
+	current, err := os.ReadDir(loc.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, entry := range current {
+		if entry.Type().IsRegular() && matchesExtension(entry.Name(), loc.Extensions) {
+			p.replace(filepath.Join(loc.Path, entry.Name()), "", false)
+		}
+	}
+	if err := os.MkdirAll(loc.Path, 0755); err != nil {
+		return err
+	}
+	restored, err := os.ReadDir(staged)
+	if err != nil {
+		return err
+	}
+	for _, entry := range restored {
+		if !entry.IsDir() {
+			p.renames = append(p.renames, [2]string{filepath.Join(staged, entry.Name()), filepath.Join(loc.Path, entry.Name())})
+		}
+	}
+	return nil
+}
+
+func (p *renamePlan) apply() error {
+	for i, rename := range p.renames {
+		if err := os.Rename(rename[0], rename[1]); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				os.Rename(p.renames[j][1], p.renames[j][0])
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *renamePlan) cleanup() {
+	for _, aside := range p.aside {
+		os.RemoveAll(aside)
+	}
+}
@@ -0,0 +1,197 @@
+package maintenance
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func backupTestLocations(dir, configPath string) []Location {
+	return []Location{
+		{Name: "config", Path: configPath},
+		{Name: "apps", Path: dir, Shallow: true, Extensions: []string{".yaml"}},
+		{Name: "notes", Path: filepath.Join(dir, "notes")},
+		{Name: "plugins", Path: filepath.Join(dir, "plugins")},
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, configPath, "theme: dark", time.Time{})
+	writeFile(t, filepath.Join(dir, "vim.yaml"), "name: vim", time.Time{})
+	writeFile(t, filepath.Join(dir, "token.json"), "secret", time.Time{})
+	writeFile(t, filepath.Join(dir, "notes", "notes.json"), "[1]", time.Time{})
+	writeFile(t, filepath.Join(dir, "notes", "files", "a.md"), "a", time.Time{})
+	locations := backupTestLocations(dir, configPath)
+
+	backupsDir := filepath.Join(dir, "backups")
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.Local)
+	path, manifest, err := CreateBackup(backupsDir, locations, now)
+	if err != nil {
+		t.Fatalf("CreateBackup() error = %v", err)
+	}
+	if filepath.Base(path) != "cheat-go-20261016-093000.tar.gz" {
+		t.Errorf("backup name = %s", filepath.Base(path))
+	}
+	if len(manifest.Locations) != 4 || manifest.Locations[2].Files != 2 || manifest.Locations[3].Exists {
+		t.Errorf("manifest = %+v", manifest.Locations)
+	}
+
+	second, _, err := CreateBackup(backupsDir, locations, now)
+	if err != nil || second == path {
+		t.Errorf("a second backup in the same second should get its own name, got %s (%v)", second, err)
+	}
+	backups, err := ListBackups(backupsDir)
+	if err != nil || len(backups) != 2 || backups[0].Path != second {
+		t.Errorf("ListBackups() = %+v, %v", backups, err)
+	}
+
+	// change everything after the backup
+	writeFile(t, configPath, "theme: light", time.Time{})
+	writeFile(t, filepath.Join(dir, "vim.yaml"), "name: changed", time.Time{})
+	writeFile(t, filepath.Join(dir, "zsh.yaml"), "name: zsh", time.Time{})
+	writeFile(t, filepath.Join(dir, "notes", "notes.json"), "[2]", time.Time{})
+	writeFile(t, filepath.Join(dir, "plugins", "new.yaml"), "name: new", time.Time{})
+
+	read, err := ReadBackupManifest(path)
+	if err != nil || !read.CreatedAt.Equal(manifest.CreatedAt) {
+		t.Errorf("ReadBackupManifest() = %+v, %v", read, err)
+	}
+	if _, err := RestoreBackup(path, locations); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+
+	if got := readTestFile(t, configPath); got != "theme: dark" {
+		t.Errorf("config = %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "vim.yaml")); got != "name: vim" {
+		t.Errorf("vim.yaml = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "zsh.yaml")); !os.IsNotExist(err) {
+		t.Error("apps added after the backup should be removed")
+	}
+	if got := readTestFile(t, filepath.Join(dir, "token.json")); got != "secret" {
+		t.Error("files of no location should be left alone")
+	}
+	if got := readTestFile(t, filepath.Join(dir, "notes", "files", "a.md")); got != "a" {
+		t.Errorf("nested note file = %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "notes", "notes.json")); got != "[1]" {
+		t.Errorf("notes.json = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "plugins")); !os.IsNotExist(err) {
+		t.Error("a location missing at backup time should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(backupsDir, filepath.Base(second))); err != nil {
+		t.Error("backups should survive a restore")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".old-") || strings.Contains(entry.Name(), ".restore-") {
+			t.Errorf("restore left %s behind", entry.Name())
+		}
+	}
+}
+
+func TestRestoreBackup_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	locations := backupTestLocations(dir, filepath.Join(dir, "config.yaml"))
+
+	notArchive := filepath.Join(dir, "plain.tar.gz")
+	writeFile(t, notArchive, "not gzip", time.Time{})
+	if _, err := RestoreBackup(notArchive, locations); !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("a non-archive should be invalid, got %v", err)
+	}
+
+	unsafe := filepath.Join(dir, "unsafe.tar.gz")
+	f, err := os.Create(unsafe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	manifest := `{"version":1,"locations":[{"name":"notes","exists":true}]}`
+	tw.WriteHeader(&tar.Header{Name: backupManifest, Mode: 0644, Size: int64(len(manifest))})
+	tw.Write([]byte(manifest))
+	tw.WriteHeader(&tar.Header{Name: "notes/../../escape", Mode: 0644, Size: 1})
+	tw.Write([]byte("x"))
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	writeFile(t, filepath.Join(dir, "notes", "notes.json"), "keep", time.Time{})
+	if _, err := RestoreBackup(unsafe, locations); !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("entries outside their location should be rejected, got %v", err)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "notes", "notes.json")); got != "keep" {
+		t.Errorf("a failed restore should not change data, notes.json = %q", got)
+	}
+}
+
+func TestBackupScheduler(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "notes", "notes.json"), "[]", time.Time{})
+	backupsDir := filepath.Join(dir, "backups")
+	locations := []Location{{Name: "notes", Path: filepath.Join(dir, "notes")}}
+
+	manual, _, err := CreateBackup(backupsDir, locations, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheduler := NewBackupScheduler(backupsDir, locations, time.Hour, 2)
+	if !scheduler.due(time.Now()) {
+		t.Error("a backup should be due without automatic backups")
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := scheduler.Backup(); err != nil {
+			t.Fatalf("Backup() error = %v", err)
+		}
+	}
+	if scheduler.due(time.Now()) {
+		t.Error("no backup should be due right after one")
+	}
+	if !scheduler.due(time.Now().Add(2 * time.Hour)) {
+		t.Error("a backup should be due after the interval")
+	}
+
+	backups, err := ListBackups(backupsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auto := 0
+	for _, backup := range backups {
+		if backup.Auto {
+			auto++
+		}
+	}
+	if auto != 2 || len(backups) != 3 {
+		t.Errorf("expected 2 automatic and the manual backup, got %+v", backups)
+	}
+	if _, err := os.Stat(manual); err != nil {
+		t.Error("manual backups should never be pruned")
+	}
+
+	started := NewBackupScheduler(t.TempDir(), locations, time.Hour, 2)
+	started.Start()
+	started.Stop()
+	if backups, _ := ListBackups(started.dir); len(backups) != 1 {
+		t.Errorf("Start should back up right away when due, got %d backups", len(backups))
+	}
+}
@@ -0,0 +1,310 @@
+package maintenance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// ItemKind identifies why a file is considered reclaimable
+type ItemKind string
+
+const (
+	KindUnusedApp      ItemKind = "unused-app"
+	KindStaleCache     ItemKind = "stale-cache"
+	KindOrphanedPlugin ItemKind = "orphaned-plugin"
+	KindOldBackup      ItemKind = "old-backup"
+)
+
+// CleanupItem is a single file that can be removed
+type CleanupItem struct {
+	Kind   ItemKind
+	Path   string
+	Size   int64
+	Reason string
+}
+
+// CleanupOptions controls which locations are scanned
+type CleanupOptions struct {
+	AppsDir string
+	// DownloadedApps are the apps installed from online sheets; those
+	// missing from ReferencedApps are unused. Apps the user added or
+	// disabled are never removed.
+	DownloadedApps []string
+	ReferencedApps []string
+	CacheDir       string
+	CacheTTL       time.Duration
+	PluginDirs     []string
+	BackupsDir     string
+	BackupMaxAge   time.Duration
+	BackupKeep     int
+}
+
+// CleanupReport lists everything a cleanup run would delete
+type CleanupReport struct {
+	Items []CleanupItem
+}
+
+// Reclaimable returns the total size of all items in the report
+func (r *CleanupReport) Reclaimable() int64 {
+	var total int64
+	for _, item := range r.Items {
+		total += item.Size
+	}
+	return total
+}
+
+// Delete removes every item in the report and returns how many were removed
+func (r *CleanupReport) Delete() (int, int64, error) {
+	var deleted int
+	var freed int64
+	var errs []string
+
+	for _, item := range r.Items {
+		if err := os.Remove(item.Path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Sprintf("%s: %v", item.Path, err))
+			continue
+		}
+		deleted++
+		freed += item.Size
+	}
+
+	if len(errs) > 0 {
+		return deleted, freed, fmt.Errorf("failed to delete %d items: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return deleted, freed, nil
+}
+
+// ScanCleanup walks the configured directories and collects reclaimable files
+func ScanCleanup(opts CleanupOptions) (*CleanupReport, error) {
+	report := &CleanupReport{}
+
+	if opts.AppsDir != "" {
+		items, err := scanUnusedApps(opts.AppsDir, opts.DownloadedApps, opts.ReferencedApps)
+		if err != nil {
+			return nil, err
+		}
+		report.Items = append(report.Items, items...)
+	}
+
+	if opts.CacheDir != "" {
+		items, err := scanStaleCache(opts.CacheDir, opts.CacheTTL)
+		if err != nil {
+			return nil, err
+		}
+		report.Items = append(report.Items, items...)
+	}
+
+	for _, dir := range opts.PluginDirs {
+		items, err := scanOrphanedPlugins(dir)
+		if err != nil {
+			return nil, err
+		}
+		report.Items = append(report.Items, items...)
+	}
+
+	if opts.BackupsDir != "" {
+		items, err := scanOldBackups(opts.BackupsDir, opts.BackupMaxAge, opts.BackupKeep)
+		if err != nil {
+			return nil, err
+		}
+		report.Items = append(report.Items, items...)
+	}
+
+	return report, nil
+}
+
+// scanUnusedApps finds the definition files of downloaded apps not listed
+// in the config
+func scanUnusedApps(dir string, downloaded, referenced []string) ([]CleanupItem, error) {
+	entries, err := readDirIfExists(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool, len(referenced))
+	for _, name := range referenced {
+		used[name] = true
+	}
+	removable := make(map[string]bool, len(downloaded))
+	for _, name := range downloaded {
+		removable[name] = true
+	}
+
+	var items []CleanupItem
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		appName := strings.TrimSuffix(name, ext)
+		// overlays hold the user's own changes to an app, never downloads
+		if used[appName] || !removable[appName] || strings.HasSuffix(appName, apps.OverlaySuffix) {
+			continue
+		}
+		items = append(items, newItem(KindUnusedApp, filepath.Join(dir, name), entry, "downloaded but not referenced in config apps"))
+	}
+
+	return items, nil
+}
+
+// scanStaleCache finds cache files older than the TTL
+func scanStaleCache(dir string, ttl time.Duration) ([]CleanupItem, error) {
+	entries, err := readDirIfExists(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []CleanupItem
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".cache" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if age := now.Sub(info.ModTime()); age > ttl {
+			items = append(items, newItem(KindStaleCache, filepath.Join(dir, entry.Name()), entry,
+				fmt.Sprintf("expired %s ago", (age-ttl).Round(time.Minute))))
+		}
+	}
+
+	return items, nil
+}
+
+// scanOrphanedPlugins finds files in a plugin directory that the loader cannot use
+func scanOrphanedPlugins(dir string) ([]CleanupItem, error) {
+	entries, err := readDirIfExists(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []CleanupItem
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+
+		switch filepath.Ext(name) {
+		case ".so":
+			continue
+		case ".yaml", ".yml":
+			if reason := invalidPluginReason(path); reason != "" {
+				items = append(items, newItem(KindOrphanedPlugin, path, entry, reason))
+			}
+		case ".tmp", ".part", ".bak":
+			items = append(items, newItem(KindOrphanedPlugin, path, entry, "leftover temporary file"))
+		}
+	}
+
+	return items, nil
+}
+
+// invalidPluginReason explains why a plugin metadata file cannot be loaded
+func invalidPluginReason(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	var metadata struct {
+		Name string `yaml:"name"`
+	}
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		return "unparsable plugin metadata"
+	}
+	if metadata.Name == "" {
+		return "plugin metadata has no name"
+	}
+	return ""
+}
+
+// scanOldBackups finds backups older than maxAge, always keeping the newest keep entries
+func scanOldBackups(dir string, maxAge time.Duration, keep int) ([]CleanupItem, error) {
+	entries, err := readDirIfExists(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type backup struct {
+		entry os.DirEntry
+		info  os.FileInfo
+	}
+	var backups []backup
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{entry: entry, info: info})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].info.ModTime().After(backups[j].info.ModTime())
+	})
+
+	var items []CleanupItem
+	now := time.Now()
+	for i, b := range backups {
+		if i < keep {
+			continue
+		}
+		if age := now.Sub(b.info.ModTime()); maxAge > 0 && age > maxAge {
+			items = append(items, newItem(KindOldBackup, filepath.Join(dir, b.entry.Name()), b.entry,
+				fmt.Sprintf("older than %s", maxAge)))
+		}
+	}
+
+	return items, nil
+}
+
+func newItem(kind ItemKind, path string, entry os.DirEntry, reason string) CleanupItem {
+	item := CleanupItem{Kind: kind, Path: path, Reason: reason}
+	if info, err := entry.Info(); err == nil {
+		item.Size = info.Size()
+	}
+	return item
+}
+
+func readDirIfExists(dir string) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	return entries, nil
+}
+
+// FormatBytes renders a byte count in a human readable form
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
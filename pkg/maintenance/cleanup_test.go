@@ -0,0 +1,131 @@
+package maintenance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !modTime.IsZero() {
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScanCleanup(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-72 * time.Hour)
+
+	writeFile(t, filepath.Join(dir, "vim.yaml"), "name: vim", time.Time{})
+	writeFile(t, filepath.Join(dir, "emacs.yaml"), "name: emacs", time.Time{})
+	writeFile(t, filepath.Join(dir, "mytool.yaml"), "name: mytool", time.Time{})
+	writeFile(t, filepath.Join(dir, "vim.local.yaml"), "name: vim", time.Time{})
+	writeFile(t, filepath.Join(dir, "nano.local.yaml"), "name: nano", time.Time{})
+	writeFile(t, filepath.Join(dir, "cache", "fresh.cache"), "{}", time.Time{})
+	writeFile(t, filepath.Join(dir, "cache", "stale.cache"), "{}", old)
+	writeFile(t, filepath.Join(dir, "plugins", "good.yaml"), "name: good", time.Time{})
+	writeFile(t, filepath.Join(dir, "plugins", "nameless.yaml"), "version: 1.0", time.Time{})
+	writeFile(t, filepath.Join(dir, "plugins", "download.part"), "xx", time.Time{})
+	writeFile(t, filepath.Join(dir, "backups", "new.tar.gz"), "b", time.Time{})
+	writeFile(t, filepath.Join(dir, "backups", "old.tar.gz"), "b", old)
+
+	report, err := ScanCleanup(CleanupOptions{
+		AppsDir:        dir,
+		DownloadedApps: []string{"vim", "emacs", "nano"},
+		ReferencedApps: []string{"vim"},
+		CacheDir:       filepath.Join(dir, "cache"),
+		CacheTTL:       24 * time.Hour,
+		PluginDirs:     []string{filepath.Join(dir, "plugins")},
+		BackupsDir:     filepath.Join(dir, "backups"),
+		BackupMaxAge:   48 * time.Hour,
+		BackupKeep:     1,
+	})
+	if err != nil {
+		t.Fatalf("ScanCleanup() error = %v", err)
+	}
+
+	expected := map[string]ItemKind{
+		"emacs.yaml":    KindUnusedApp,
+		"stale.cache":   KindStaleCache,
+		"nameless.yaml": KindOrphanedPlugin,
+		"download.part": KindOrphanedPlugin,
+		"old.tar.gz":    KindOldBackup,
+	}
+	if len(report.Items) != len(expected) {
+		t.Fatalf("expected %d items, got %d: %+v", len(expected), len(report.Items), report.Items)
+	}
+	for _, item := range report.Items {
+		kind, ok := expected[filepath.Base(item.Path)]
+		if !ok {
+			t.Errorf("unexpected item %s", item.Path)
+			continue
+		}
+		if kind != item.Kind {
+			t.Errorf("%s: expected kind %s, got %s", item.Path, kind, item.Kind)
+		}
+	}
+
+	if report.Reclaimable() == 0 {
+		t.Error("reclaimable size should be positive")
+	}
+
+	deleted, _, err := report.Delete()
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if deleted != len(expected) {
+		t.Errorf("expected %d deleted, got %d", len(expected), deleted)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "vim.yaml")); err != nil {
+		t.Error("referenced app should be kept")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "emacs.yaml")); !os.IsNotExist(err) {
+		t.Error("unused app should be deleted")
+	}
+}
+
+func TestScanCleanup_MissingDirectories(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+
+	report, err := ScanCleanup(CleanupOptions{
+		AppsDir:    dir,
+		CacheDir:   dir,
+		PluginDirs: []string{dir},
+		BackupsDir: dir,
+	})
+	if err != nil {
+		t.Fatalf("missing directories should not error: %v", err)
+	}
+	if len(report.Items) != 0 {
+		t.Errorf("expected empty report, got %d items", len(report.Items))
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBytes(tt.input); got != tt.expected {
+			t.Errorf("FormatBytes(%d) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package maintenance
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Location is a named place where cheat-go stores user data
+type Location struct {
+	Name string
+	Path string
+	// Shallow restricts the size calculation to regular files directly in
+	// Path, for directories that also contain other locations
+	Shallow bool
+	// Extensions limits counted files to these extensions when non-empty
+	Extensions []string
+}
+
+// UsageEntry is the measured size of a single location
+type UsageEntry struct {
+	Location
+	Exists bool
+	Size   int64
+	Files  int
+}
+
+// UsageReport summarises disk usage across all data locations
+type UsageReport struct {
+	Entries []UsageEntry
+}
+
+// Total returns the combined size of all locations
+func (r *UsageReport) Total() int64 {
+	var total int64
+	for _, e := range r.Entries {
+		total += e.Size
+	}
+	return total
+}
+
+// DiskUsage measures every location in order
+func DiskUsage(locations []Location) (*UsageReport, error) {
+	report := &UsageReport{}
+
+	for _, loc := range locations {
+		entry := UsageEntry{Location: loc}
+
+		info, err := os.Stat(loc.Path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to stat %s: %w", loc.Path, err)
+			}
+			report.Entries = append(report.Entries, entry)
+			continue
+		}
+		entry.Exists = true
+
+		if !info.IsDir() {
+			entry.Size = info.Size()
+			entry.Files = 1
+			report.Entries = append(report.Entries, entry)
+			continue
+		}
+
+		size, files, err := dirUsage(loc)
+		if err != nil {
+			return nil, err
+		}
+		entry.Size = size
+		entry.Files = files
+		report.Entries = append(report.Entries, entry)
+	}
+
+	return report, nil
+}
+
+func dirUsage(loc Location) (int64, int, error) {
+	var size int64
+	var files int
+
+	err := filepath.WalkDir(loc.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if loc.Shallow && path != loc.Path {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !matchesExtension(d.Name(), loc.Extensions) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size += info.Size()
+		files++
+		return nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to measure %s: %w", loc.Path, err)
+	}
+
+	return size, files, nil
+}
+
+func matchesExtension(name string, extensions []string) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// MoveDataDir relocates the data directory from src to dst. The destination
+// must not exist or be empty. A rename is attempted first and a recursive copy
+// is used when src and dst are on different filesystems.
+func MoveDataDir(src, dst string) error {
+	src = filepath.Clean(src)
+	dst = filepath.Clean(dst)
+
+	if src == dst {
+		return fmt.Errorf("source and destination are the same")
+	}
+	if strings.HasPrefix(dst, src+string(filepath.Separator)) {
+		return fmt.Errorf("destination %s is inside the data directory", dst)
+	}
+
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("data directory not accessible: %w", err)
+	}
+
+	if entries, err := os.ReadDir(dst); err == nil && len(entries) > 0 {
+		return fmt.Errorf("destination %s is not empty", dst)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create destination parent: %w", err)
+	}
+	os.Remove(dst)
+
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	if err := copyTree(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return fmt.Errorf("failed to copy data directory: %w", err)
+	}
+
+	return os.RemoveAll(src)
+}
+
+// copyTree recursively copies a directory, preserving file modes
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
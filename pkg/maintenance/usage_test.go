@@ -0,0 +1,96 @@
+package maintenance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "vim.yaml"), "12345", time.Time{})
+	writeFile(t, filepath.Join(dir, "readme.txt"), "ignored", time.Time{})
+	writeFile(t, filepath.Join(dir, "notes", "notes.json"), "123", time.Time{})
+
+	report, err := DiskUsage([]Location{
+		{Name: "apps", Path: dir, Shallow: true, Extensions: []string{".yaml"}},
+		{Name: "notes", Path: filepath.Join(dir, "notes")},
+		{Name: "logs", Path: filepath.Join(dir, "logs")},
+	})
+	if err != nil {
+		t.Fatalf("DiskUsage() error = %v", err)
+	}
+
+	if len(report.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(report.Entries))
+	}
+	if apps := report.Entries[0]; apps.Size != 5 || apps.Files != 1 {
+		t.Errorf("apps usage = %d bytes / %d files, expected 5 / 1", apps.Size, apps.Files)
+	}
+	if notes := report.Entries[1]; notes.Size != 3 {
+		t.Errorf("notes usage = %d, expected 3", notes.Size)
+	}
+	if report.Entries[2].Exists {
+		t.Error("missing location should not exist")
+	}
+	if report.Total() != 8 {
+		t.Errorf("Total() = %d, expected 8", report.Total())
+	}
+}
+
+func TestMoveDataDir(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	dst := filepath.Join(root, "nested", "dst")
+	writeFile(t, filepath.Join(src, "vim.yaml"), "vim", time.Time{})
+	writeFile(t, filepath.Join(src, "notes", "notes.json"), "[]", time.Time{})
+
+	if err := MoveDataDir(src, dst); err != nil {
+		t.Fatalf("MoveDataDir() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dst, "notes", "notes.json")); err != nil {
+		t.Errorf("notes should be moved: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("source should no longer exist")
+	}
+}
+
+func TestMoveDataDir_Errors(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	writeFile(t, filepath.Join(src, "a.yaml"), "a", time.Time{})
+
+	if err := MoveDataDir(src, src); err == nil {
+		t.Error("moving onto itself should fail")
+	}
+	if err := MoveDataDir(src, filepath.Join(src, "inner")); err == nil {
+		t.Error("moving into itself should fail")
+	}
+
+	occupied := filepath.Join(root, "occupied")
+	writeFile(t, filepath.Join(occupied, "x"), "x", time.Time{})
+	if err := MoveDataDir(src, occupied); err == nil {
+		t.Error("moving into a non-empty directory should fail")
+	}
+	if err := MoveDataDir(filepath.Join(root, "missing"), filepath.Join(root, "new")); err == nil {
+		t.Error("moving a missing directory should fail")
+	}
+}
+
+func TestCopyTree(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	dst := filepath.Join(root, "dst")
+	writeFile(t, filepath.Join(src, "a", "b.txt"), "hello", time.Time{})
+
+	if err := copyTree(src, dst); err != nil {
+		t.Fatalf("copyTree() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "a", "b.txt"))
+	if err != nil || string(data) != "hello" {
+		t.Errorf("copied content = %q, err = %v", data, err)
+	}
+}
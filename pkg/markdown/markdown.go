@@ -0,0 +1,62 @@
+// Package markdown renders the Markdown of notes. The terminal preview is
+// rendered by glamour and the HTML export by goldmark, the parser glamour is
+// built on, with the extensions glamour enables, so a note reads the same in
+// both.
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Terminal renders content in style as lines of at most width columns
+func Terminal(content string, style ansi.StyleConfig, width int) (string, error) {
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithStyles(style),
+		glamour.WithWordWrap(width),
+	)
+	if err != nil {
+		return "", err
+	}
+	return renderer.Render(content)
+}
+
+// HTML renders content as HTML with its headings moved down by shift
+// levels. Raw HTML in content is left out.
+func HTML(content string, shift int) (string, error) {
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			extension.DefinitionList,
+		),
+		goldmark.WithParserOptions(
+			parser.WithASTTransformers(util.Prioritized(headingShift(shift), 100)),
+		),
+	)
+
+	var out bytes.Buffer
+	if err := md.Convert([]byte(content), &out); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// headingShift moves headings down by its number of levels, up to h6
+type headingShift int
+
+func (s headingShift) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if heading, ok := node.(*ast.Heading); ok && entering {
+			heading.Level = min(heading.Level+int(s), 6)
+		}
+		return ast.WalkContinue, nil
+	})
+}
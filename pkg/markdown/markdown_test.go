@@ -0,0 +1,38 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/glamour/styles"
+)
+
+func TestHTML(t *testing.T) {
+	got, err := HTML("# Title\n###### Deep\n\n- [x] done\n\n<script>alert(1)</script>", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"<h3>Title</h3>",
+		"<h6>Deep</h6>",
+		`<input checked="" disabled="" type="checkbox"> done`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML() missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "<script>") {
+		t.Errorf("HTML() should leave out raw HTML, got:\n%s", got)
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	got, err := Terminal("# Title\n\n- [x] done", styles.ASCIIStyleConfig, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "Title") || !strings.Contains(got, "[x] done") {
+		t.Errorf("Terminal() = %q", got)
+	}
+}
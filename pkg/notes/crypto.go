@@ -0,0 +1,222 @@
+package notes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+)
+
+var (
+	ErrWrongPassphrase    = errors.New("wrong passphrase or corrupted note")
+	ErrNotEncrypted       = errors.New("note is not encrypted")
+	ErrAlreadyEncrypted   = errors.New("note is already encrypted")
+	ErrEncryptionRequired = errors.New("encrypted notes must be saved with a passphrase")
+	ErrEmptyPassphrase    = errors.New("passphrase is empty")
+)
+
+const (
+	// encryptedPrefix marks content encrypted by EncryptContent
+	encryptedPrefix = "cheat-go:enc:v1:"
+
+	// scrypt parameters recommended for interactive logins
+	scryptN     = 1 << 15
+	scryptR     = 8
+	scryptP     = 1
+	keyLength   = 32
+	saltLength  = 16
+	nonceLength = 12
+	minEnvelope = saltLength + nonceLength
+)
+
+// IsEncryptedContent reports whether content was produced by EncryptContent
+func IsEncryptedContent(content string) bool {
+	return strings.HasPrefix(content, encryptedPrefix)
+}
+
+// EncryptContent encrypts plaintext with AES-256-GCM under a key derived
+// from passphrase with scrypt. The random salt and nonce are stored with the
+// ciphertext.
+func EncryptContent(plaintext, passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", ErrEmptyPassphrase
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, nonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	envelope := append(salt, nonce...)
+	envelope = gcm.Seal(envelope, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(envelope), nil
+}
+
+// DecryptContent reverses EncryptContent
+func DecryptContent(content, passphrase string) (string, error) {
+	if !IsEncryptedContent(content) {
+		return "", ErrNotEncrypted
+	}
+
+	envelope, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(content, encryptedPrefix))
+	if err != nil || len(envelope) < minEnvelope {
+		return "", ErrWrongPassphrase
+	}
+	salt, nonce, ciphertext := envelope[:saltLength], envelope[saltLength:minEnvelope], envelope[minEnvelope:]
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(plaintext), nil
+}
+
+// newGCM derives the note key from passphrase and salt
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptNote encrypts the content of a note at rest. The plain text is not
+// written to the journal, but journal entries of earlier changes keep theirs.
+func (fm *FileManager) EncryptNote(id, passphrase string) error {
+	return fm.updateEncryption(id, true, func(note *Note) error {
+		if note.Encrypted {
+			return ErrAlreadyEncrypted
+		}
+		content, err := EncryptContent(note.Content, passphrase)
+		if err != nil {
+			return err
+		}
+		note.Content = content
+		note.Encrypted = true
+		return nil
+	})
+}
+
+// RemoveEncryption stores the content of an encrypted note as plain text
+func (fm *FileManager) RemoveEncryption(id, passphrase string) error {
+	return fm.updateEncryption(id, false, func(note *Note) error {
+		if !note.Encrypted {
+			return ErrNotEncrypted
+		}
+		content, err := DecryptContent(note.Content, passphrase)
+		if err != nil {
+			return err
+		}
+		note.Content = content
+		note.Encrypted = false
+		return nil
+	})
+}
+
+// DecryptNote returns a copy of an encrypted note with its content in plain
+// text; the stored note stays encrypted
+func (fm *FileManager) DecryptNote(id, passphrase string) (*Note, error) {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+
+	note, exists := fm.lookup(id)
+	if !exists {
+		return nil, ErrNoteNotFound
+	}
+	if err := fm.loadBody(id); err != nil {
+		return nil, err
+	}
+	if !note.Encrypted {
+		return nil, ErrNotEncrypted
+	}
+
+	content, err := DecryptContent(note.Content, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	decrypted := note.clone()
+	decrypted.Content = content
+	return decrypted, nil
+}
+
+// UpdateEncryptedNote replaces an encrypted note, encrypting the plain text
+// content of updatedNote with passphrase. The passphrase must match the one
+// the note is encrypted with.
+func (fm *FileManager) UpdateEncryptedNote(id string, updatedNote *Note, passphrase string) error {
+	if _, err := fm.DecryptNote(id, passphrase); err != nil {
+		return err
+	}
+
+	content, err := EncryptContent(updatedNote.Content, passphrase)
+	if err != nil {
+		return err
+	}
+	encrypted := updatedNote.clone()
+	encrypted.Content = content
+	encrypted.Encrypted = true
+	return fm.UpdateNote(id, encrypted)
+}
+
+// updateEncryption applies change to a copy of a note and saves it. With
+// hideBefore the journal records the changed note as its own previous state.
+func (fm *FileManager) updateEncryption(id string, hideBefore bool, change func(note *Note) error) error {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.readOnly {
+		return ErrReadOnly
+	}
+
+	note, exists := fm.notes[id]
+	if !exists {
+		return fm.missing(id)
+	}
+	if err := fm.loadBody(id); err != nil {
+		return err
+	}
+
+	updated := note.clone()
+	if err := change(updated); err != nil {
+		return err
+	}
+	updated.UpdatedAt = time.Now()
+
+	fm.notes[id] = updated
+	fm.index.add(updated)
+	if err := fm.saveNotes(id); err != nil {
+		fm.notes[id] = note
+		fm.index.add(note)
+		return err
+	}
+
+	before := note
+	if hideBefore {
+		before = updated
+	}
+	fm.record(journal.ActionUpdate, fm.source, id, before, updated)
+	return nil
+}
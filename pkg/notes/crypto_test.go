@@ -0,0 +1,135 @@
+package notes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptContent(t *testing.T) {
+	encrypted, err := EncryptContent("secret text", "hunter2")
+	if err != nil {
+		t.Fatalf("EncryptContent() error = %v", err)
+	}
+	if !IsEncryptedContent(encrypted) {
+		t.Errorf("content %q should be marked as encrypted", encrypted)
+	}
+	if strings.Contains(encrypted, "secret") {
+		t.Error("encrypted content should not contain the plain text")
+	}
+
+	again, _ := EncryptContent("secret text", "hunter2")
+	if again == encrypted {
+		t.Error("encrypting twice should use a new salt and nonce")
+	}
+
+	plain, err := DecryptContent(encrypted, "hunter2")
+	if err != nil {
+		t.Fatalf("DecryptContent() error = %v", err)
+	}
+	if plain != "secret text" {
+		t.Errorf("DecryptContent() = %q, want %q", plain, "secret text")
+	}
+
+	if _, err := DecryptContent(encrypted, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("wrong passphrase error = %v, want %v", err, ErrWrongPassphrase)
+	}
+	if _, err := DecryptContent(encryptedPrefix+"bm90IGEgbm90ZQ==", "hunter2"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("corrupted content error = %v, want %v", err, ErrWrongPassphrase)
+	}
+	if _, err := DecryptContent("plain", "hunter2"); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("plain content error = %v, want %v", err, ErrNotEncrypted)
+	}
+	if _, err := EncryptContent("text", ""); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("empty passphrase error = %v, want %v", err, ErrEmptyPassphrase)
+	}
+}
+
+func TestFileManager_EncryptNote(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewFileManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	note := &Note{Title: "Server access", Content: "root password is swordfish", AppName: "zsh"}
+	if err := manager.CreateNote(note); err != nil {
+		t.Fatalf("CreateNote() error = %v", err)
+	}
+
+	if err := manager.EncryptNote(note.ID, "hunter2"); err != nil {
+		t.Fatalf("EncryptNote() error = %v", err)
+	}
+	if err := manager.EncryptNote(note.ID, "hunter2"); !errors.Is(err, ErrAlreadyEncrypted) {
+		t.Errorf("second EncryptNote() error = %v, want %v", err, ErrAlreadyEncrypted)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, "files", note.ID+".json"))
+	if err != nil {
+		t.Fatalf("Failed to read note file: %v", err)
+	}
+	if strings.Contains(string(data), "swordfish") {
+		t.Error("note file should not contain the plain text of an encrypted note")
+	}
+
+	stored, _ := manager.GetNote(note.ID)
+	if !stored.Encrypted || !IsEncryptedContent(stored.Content) {
+		t.Errorf("stored note = %+v, want encrypted content", stored)
+	}
+
+	// search only matches the title of encrypted notes
+	results, _ := manager.SearchNotes(SearchOptions{Query: "swordfish"})
+	if len(results) != 0 {
+		t.Errorf("search matched encrypted content: %v", results)
+	}
+	results, _ = manager.SearchNotes(SearchOptions{Query: "server"})
+	if len(results) != 1 {
+		t.Errorf("search by title returned %d notes, want 1", len(results))
+	}
+
+	if _, err := manager.DecryptNote(note.ID, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("DecryptNote() with wrong passphrase error = %v, want %v", err, ErrWrongPassphrase)
+	}
+	decrypted, err := manager.DecryptNote(note.ID, "hunter2")
+	if err != nil {
+		t.Fatalf("DecryptNote() error = %v", err)
+	}
+	if decrypted.Content != note.Content {
+		t.Errorf("DecryptNote() content = %q, want %q", decrypted.Content, note.Content)
+	}
+	if stored, _ := manager.GetNote(note.ID); !IsEncryptedContent(stored.Content) {
+		t.Error("DecryptNote() should leave the stored note encrypted")
+	}
+
+	// plain text content cannot be saved into an encrypted note
+	decrypted.Content = "root password is marlin"
+	if err := manager.UpdateNote(note.ID, decrypted); !errors.Is(err, ErrEncryptionRequired) {
+		t.Errorf("UpdateNote() error = %v, want %v", err, ErrEncryptionRequired)
+	}
+	if err := manager.UpdateEncryptedNote(note.ID, decrypted, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("UpdateEncryptedNote() with wrong passphrase error = %v, want %v", err, ErrWrongPassphrase)
+	}
+	if err := manager.UpdateEncryptedNote(note.ID, decrypted, "hunter2"); err != nil {
+		t.Fatalf("UpdateEncryptedNote() error = %v", err)
+	}
+	if updated, _ := manager.DecryptNote(note.ID, "hunter2"); updated.Content != "root password is marlin" {
+		t.Errorf("updated content = %q", updated.Content)
+	}
+
+	if err := manager.CreateNote(&Note{Title: "Plain", Content: "text", Encrypted: true}); !errors.Is(err, ErrEncryptionRequired) {
+		t.Errorf("CreateNote() error = %v, want %v", err, ErrEncryptionRequired)
+	}
+
+	if err := manager.RemoveEncryption(note.ID, "hunter2"); err != nil {
+		t.Fatalf("RemoveEncryption() error = %v", err)
+	}
+	plain, _ := manager.GetNote(note.ID)
+	if plain.Encrypted || plain.Content != "root password is marlin" {
+		t.Errorf("note after RemoveEncryption() = %+v", plain)
+	}
+	if err := manager.RemoveEncryption(note.ID, "hunter2"); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("second RemoveEncryption() error = %v, want %v", err, ErrNotEncrypted)
+	}
+}
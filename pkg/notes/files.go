@@ -0,0 +1,211 @@
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+// Each note is stored as its own document in the note files collection, so
+// sync tools and git see one changed file per edited note. The index
+// document in the notes collection lists every note without its content;
+// notes are listed from it and their content is read on first use.
+const (
+	// indexKey is the document listing all notes without their content
+	indexKey = "index"
+	// notesKey is the document that held all notes before they were split
+	// into one file each
+	notesKey = "notes"
+	// migratedKey keeps the single-file notes after they were migrated
+	migratedKey = "notes.migrated"
+)
+
+// loadNotes reads the index and reconciles it with the note files: files
+// added by a sync tool or git are picked up, and notes whose file is gone
+// are dropped
+func (fm *FileManager) loadNotes() error {
+	var summaries []*Note
+	err := storage.GetJSON(fm.store, storage.CollectionNotes, indexKey, &summaries)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fm.migrateNotes()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read notes index: %w", err)
+	}
+
+	keys, err := fm.store.List(storage.CollectionNoteFiles)
+	if err != nil {
+		return fmt.Errorf("failed to list note files: %w", err)
+	}
+	files := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		files[key] = true
+	}
+
+	for _, summary := range summaries {
+		if files[summary.ID] {
+			fm.notes[summary.ID] = summary
+			fm.unloaded[summary.ID] = true
+		}
+	}
+	for _, key := range keys {
+		if _, listed := fm.notes[key]; listed {
+			continue
+		}
+		note, err := fm.readNoteFile(key)
+		if err != nil {
+			return err
+		}
+		fm.notes[key] = note
+		fm.index.add(note)
+	}
+	return nil
+}
+
+// migrateNotes splits the single notes document of earlier versions into
+// one file per note. The old document is kept as notes.migrated.
+func (fm *FileManager) migrateNotes() error {
+	var notes []*Note
+	if err := storage.GetJSON(fm.store, storage.CollectionNotes, notesKey, &notes); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to read notes: %w", err)
+	}
+
+	ids := make([]string, 0, len(notes))
+	for _, note := range notes {
+		fm.notes[note.ID] = note
+		fm.index.add(note)
+		ids = append(ids, note.ID)
+	}
+	if err := fm.saveNotes(ids...); err != nil {
+		return fmt.Errorf("failed to migrate notes: %w", err)
+	}
+
+	data, err := fm.store.Get(storage.CollectionNotes, notesKey)
+	if err != nil {
+		return fmt.Errorf("failed to migrate notes: %w", err)
+	}
+	if err := fm.store.Put(storage.CollectionNotes, migratedKey, data); err != nil {
+		return fmt.Errorf("failed to migrate notes: %w", err)
+	}
+	if err := fm.store.Delete(storage.CollectionNotes, notesKey); err != nil {
+		return fmt.Errorf("failed to migrate notes: %w", err)
+	}
+	return nil
+}
+
+// readNoteFile reads the document of a note
+func (fm *FileManager) readNoteFile(id string) (*Note, error) {
+	var note Note
+	if err := storage.GetJSON(fm.store, storage.CollectionNoteFiles, id, &note); err != nil {
+		return nil, fmt.Errorf("failed to read note %s: %w", id, err)
+	}
+	note.ID = id
+	return &note, nil
+}
+
+// loadBody reads the content of a note that is only known from the index.
+// Callers hold fm.mu, for reading or writing.
+func (fm *FileManager) loadBody(id string) error {
+	fm.bodyMu.Lock()
+	defer fm.bodyMu.Unlock()
+	return fm.loadBodyLocked(id)
+}
+
+// ensureLoaded reads the content of all notes not read yet
+func (fm *FileManager) ensureLoaded() error {
+	fm.bodyMu.Lock()
+	defer fm.bodyMu.Unlock()
+
+	for id := range fm.unloaded {
+		if err := fm.loadBodyLocked(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fm *FileManager) loadBodyLocked(id string) error {
+	note, exists := fm.notes[id]
+	if !exists || !fm.unloaded[id] {
+		return nil
+	}
+
+	stored, err := fm.readNoteFile(id)
+	if err != nil {
+		return err
+	}
+	// the note file is authoritative should the index be out of date
+	*note = *stored
+	delete(fm.unloaded, id)
+	fm.index.add(note)
+	return nil
+}
+
+// saveNotes writes the files of the notes with the given ids, removing the
+// files of notes that no longer exist, and then the index
+func (fm *FileManager) saveNotes(ids ...string) error {
+	for _, id := range ids {
+		note, exists := fm.notes[id]
+		if !exists {
+			if err := fm.store.Delete(storage.CollectionNoteFiles, id); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("failed to remove note file: %w", err)
+			}
+			continue
+		}
+		if fm.unloaded[id] {
+			return fmt.Errorf("failed to write note %s: content not loaded", id)
+		}
+		if err := storage.PutJSON(fm.store, storage.CollectionNoteFiles, id, note); err != nil {
+			return fmt.Errorf("failed to write note file: %w", err)
+		}
+	}
+	return fm.saveIndex()
+}
+
+// saveIndex writes the index, sorted by ID so it diffs cleanly
+func (fm *FileManager) saveIndex() error {
+	summaries := make([]*Note, 0, len(fm.notes))
+	for _, note := range fm.notes {
+		summary := *note
+		summary.Content = ""
+		summaries = append(summaries, &summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].ID < summaries[j].ID
+	})
+
+	if err := storage.PutJSON(fm.store, storage.CollectionNotes, indexKey, summaries); err != nil {
+		return fmt.Errorf("failed to write notes index: %w", err)
+	}
+	return nil
+}
+
+// ReplaceNotes replaces all notes with notes, without journaling the
+// changes. Sync uses it to store merged notes.
+func (fm *FileManager) ReplaceNotes(notes []*Note) error {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.readOnly {
+		return ErrReadOnly
+	}
+
+	ids := make([]string, 0, len(fm.notes)+len(notes))
+	for id := range fm.notes {
+		ids = append(ids, id)
+		fm.index.remove(id)
+	}
+	fm.notes = make(map[string]*Note, len(notes))
+	fm.unloaded = make(map[string]bool)
+	for _, note := range notes {
+		fm.notes[note.ID] = note
+		fm.index.add(note)
+		ids = append(ids, note.ID)
+	}
+	return fm.saveNotes(ids...)
+}
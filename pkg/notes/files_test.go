@@ -0,0 +1,134 @@
+package notes
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileManager_NoteFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewFileManager(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := &Note{Title: "First", Content: "first body"}
+	second := &Note{Title: "Second", Content: "second body"}
+	manager.CreateNote(first)
+	manager.CreateNote(second)
+
+	data, err := os.ReadFile(filepath.Join(tempDir, "files", first.ID+".json"))
+	if err != nil {
+		t.Fatalf("note file not written: %v", err)
+	}
+	if !strings.Contains(string(data), "first body") || strings.Contains(string(data), "second body") {
+		t.Errorf("note file should hold exactly its own note, got %s", data)
+	}
+
+	index, err := os.ReadFile(filepath.Join(tempDir, "index.json"))
+	if err != nil {
+		t.Fatalf("index not written: %v", err)
+	}
+	if strings.Contains(string(index), "body") || !strings.Contains(string(index), "Second") {
+		t.Errorf("index should list titles without content, got %s", index)
+	}
+
+	manager.DeleteNote(second.ID)
+	if _, err := os.Stat(filepath.Join(tempDir, "files", second.ID+".json")); !os.IsNotExist(err) {
+		t.Errorf("deleted note file should be removed, error = %v", err)
+	}
+
+	// content is read on first use after reopening
+	manager, err = NewFileManager(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !manager.unloaded[first.ID] {
+		t.Error("note content should not be read when opening")
+	}
+	if err := manager.ToggleFavorite(first.ID); err != nil {
+		t.Fatalf("ToggleFavorite() error = %v", err)
+	}
+	manager, _ = NewFileManager(tempDir)
+	note, err := manager.GetNote(first.ID)
+	if err != nil || note.Content != "first body" || !note.IsFavorite {
+		t.Errorf("GetNote() = %+v, %v", note, err)
+	}
+	if results, _ := manager.SearchNotes(SearchOptions{Query: "body"}); len(results) != 1 {
+		t.Errorf("lazily loaded notes should be searchable, got %d", len(results))
+	}
+}
+
+func TestFileManager_ReconcileNoteFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewFileManager(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := &Note{Title: "Kept", Content: "stays"}
+	removed := &Note{Title: "Removed", Content: "goes"}
+	manager.CreateNote(kept)
+	manager.CreateNote(removed)
+
+	// a sync tool adds one note file and removes another
+	os.Remove(filepath.Join(tempDir, "files", removed.ID+".json"))
+	added, _ := json.Marshal(&Note{Title: "Synced", Content: "from another machine"})
+	os.WriteFile(filepath.Join(tempDir, "files", "note-synced.json"), added, 0644)
+
+	manager, err = NewFileManager(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, _ := manager.ListNotes()
+	titles := map[string]bool{}
+	for _, note := range list {
+		titles[note.Title] = true
+	}
+	if len(list) != 2 || !titles["Kept"] || !titles["Synced"] {
+		t.Errorf("ListNotes() titles = %v, want Kept and Synced", titles)
+	}
+	if note, err := manager.GetNote("note-synced"); err != nil || note.Content != "from another machine" {
+		t.Errorf("GetNote() = %+v, %v", note, err)
+	}
+}
+
+func TestFileManager_MigrateNotes(t *testing.T) {
+	tempDir := t.TempDir()
+	legacy := []*Note{
+		{ID: "note-1", Title: "Splits", Content: "ctrl-w s"},
+		{ID: "note-2", Title: "Buffers", Content: ":ls"},
+	}
+	data, _ := json.Marshal(legacy)
+	os.WriteFile(filepath.Join(tempDir, "notes.json"), data, 0644)
+
+	manager, err := NewFileManager(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileManager() error = %v", err)
+	}
+	if note, err := manager.GetNote("note-2"); err != nil || note.Content != ":ls" {
+		t.Errorf("GetNote() = %+v, %v", note, err)
+	}
+
+	for _, id := range []string{"note-1", "note-2"} {
+		if _, err := os.Stat(filepath.Join(tempDir, "files", id+".json")); err != nil {
+			t.Errorf("note file of %s not written: %v", id, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "notes.json")); !os.IsNotExist(err) {
+		t.Errorf("notes.json should be moved aside, error = %v", err)
+	}
+	if backup, err := os.ReadFile(filepath.Join(tempDir, "notes.migrated.json")); err != nil || string(backup) != string(data) {
+		t.Errorf("notes.migrated.json = %s, %v", backup, err)
+	}
+
+	manager, err = NewFileManager(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := manager.ListNotes(); len(list) != 2 {
+		t.Errorf("ListNotes() after migration returned %d notes, want 2", len(list))
+	}
+}
@@ -0,0 +1,137 @@
+package notes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/pkg/markdown"
+)
+
+var ErrPDFUnavailable = errors.New("PDF export needs wkhtmltopdf or a Chromium-based browser in PATH")
+
+// PDFConverter turns an HTML document into a PDF. It is a variable so other
+// backends can be plugged in.
+var PDFConverter = convertPDF
+
+var htmlExport = template.Must(template.New("notes").Funcs(template.FuncMap{
+	"markdown": markdownToHTML,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Personal Notes</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 48em; margin: 2em auto; padding: 0 1em; color: #24292f; line-height: 1.5; }
+article { border-bottom: 1px solid #d0d7de; padding-bottom: 1.5em; margin-bottom: 1.5em; }
+.meta { color: #57606a; font-size: 0.9em; }
+.tag { background: #ddf4ff; border-radius: 1em; padding: 0 0.6em; margin-right: 0.3em; }
+code, pre { font-family: ui-monospace, Menlo, Consolas, monospace; background: #f6f8fa; border-radius: 4px; }
+code { padding: 0.1em 0.3em; }
+pre { padding: 0.8em; overflow-x: auto; }
+blockquote { color: #57606a; border-left: 0.25em solid #d0d7de; margin: 0; padding: 0 1em; }
+table { border-collapse: collapse; }
+td { border: 1px solid #d0d7de; padding: 0.2em 0.6em; }
+</style>
+</head>
+<body>
+<h1>Personal Notes</h1>
+{{range .}}<article>
+<h2>{{if .IsFavorite}}⭐ {{end}}{{.Title}}</h2>
+<p class="meta">{{if .AppName}}{{.AppName}} · {{end}}{{if .Category}}{{.Category}} · {{end}}updated {{.UpdatedAt.Format "2006-01-02"}}</p>
+{{if .Tags}}<p>{{range .Tags}}<span class="tag">{{.}}</span>{{end}}</p>
+{{end}}{{if .Encrypted}}<p><em>🔒 This note is encrypted.</em></p>
+{{else}}{{markdown .Content}}{{end}}{{if .Shortcuts}}<h3>Shortcuts</h3>
+<table>
+{{range .Shortcuts}}<tr><td><code>{{.Keys}}</code></td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}</article>
+{{end}}</body>
+</html>
+`))
+
+// exportToHTML renders notes, sorted by title, as a standalone HTML page
+func exportToHTML(notes []*Note) ([]byte, error) {
+	sorted := append([]*Note(nil), notes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].Title) < strings.ToLower(sorted[j].Title)
+	})
+
+	var buf bytes.Buffer
+	if err := htmlExport.Execute(&buf, sorted); err != nil {
+		return nil, fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// exportToPDF renders notes as HTML and converts the page with PDFConverter
+func exportToPDF(notes []*Note) ([]byte, error) {
+	page, err := exportToHTML(notes)
+	if err != nil {
+		return nil, err
+	}
+	return PDFConverter(page)
+}
+
+// markdownToHTML converts the Markdown of a note to HTML. Note titles are
+// h2, so the headings of a note start at h3.
+func markdownToHTML(content string) (template.HTML, error) {
+	rendered, err := markdown.HTML(content, 2)
+	if err != nil {
+		return "", fmt.Errorf("failed to render Markdown: %w", err)
+	}
+	return template.HTML(rendered), nil
+}
+
+// convertPDF prints an HTML page to PDF with wkhtmltopdf, or with a headless
+// Chromium-based browser when wkhtmltopdf is not installed
+func convertPDF(page []byte) ([]byte, error) {
+	if path, err := exec.LookPath("wkhtmltopdf"); err == nil {
+		cmd := exec.Command(path, "--quiet", "--encoding", "utf-8", "-", "-")
+		cmd.Stdin = bytes.NewReader(page)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("wkhtmltopdf failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return out, nil
+	}
+
+	for _, browser := range []string{"chromium", "chromium-browser", "google-chrome", "chrome"} {
+		path, err := exec.LookPath(browser)
+		if err != nil {
+			continue
+		}
+		return printWithBrowser(path, page)
+	}
+	return nil, ErrPDFUnavailable
+}
+
+// printWithBrowser prints page to PDF with a headless Chromium-based browser
+func printWithBrowser(browser string, page []byte) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "cheat-go-pdf-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "notes.html")
+	output := filepath.Join(dir, "notes.pdf")
+	if err := os.WriteFile(input, page, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	cmd := exec.Command(browser, "--headless", "--disable-gpu", "--no-pdf-header-footer",
+		"--print-to-pdf="+output, "file://"+input)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s failed: %v: %s", filepath.Base(browser), err, strings.TrimSpace(string(out)))
+	}
+	return os.ReadFile(output)
+}
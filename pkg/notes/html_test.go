@@ -0,0 +1,72 @@
+package notes
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	rendered, err := markdownToHTML("# Motions\n\nUse **w** and `<C-w>`\nto *move*.\n\n- one\n- two\n\n1. first\n\n> quoted\n\n```\nif a < b {}\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(rendered)
+
+	for _, want := range []string{
+		"<h3>Motions</h3>",
+		"<p>Use <strong>w</strong> and <code>&lt;C-w&gt;</code>\nto <em>move</em>.</p>",
+		"<ul>\n<li>one</li>\n<li>two</li>\n</ul>",
+		"<ol>\n<li>first</li>\n</ol>",
+		"<blockquote>\n<p>quoted</p>\n</blockquote>",
+		"<pre><code>if a &lt; b {}\n</code></pre>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdownToHTML() missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestFileManager_ExportHTMLAndPDF(t *testing.T) {
+	manager, err := NewFileManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.CreateNote(&Note{Title: "Zsh <tips>", Content: "**bold**", AppName: "zsh", Tags: []string{"shell"}})
+	secret := &Note{Title: "Accounts", Content: "password"}
+	manager.CreateNote(secret)
+	manager.EncryptNote(secret.ID, "hunter2")
+
+	page, err := manager.ExportNotes("html")
+	if err != nil {
+		t.Fatalf("ExportNotes(html) error = %v", err)
+	}
+	html := string(page)
+	for _, want := range []string{"<!DOCTYPE html>", "Zsh &lt;tips&gt;", "<strong>bold</strong>", `<span class="tag">shell</span>`, "This note is encrypted"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML export missing %q", want)
+		}
+	}
+	if strings.Index(html, "Accounts") > strings.Index(html, "Zsh") {
+		t.Error("HTML export should sort notes by title")
+	}
+	if strings.Contains(html, encryptedPrefix) {
+		t.Error("HTML export should not include encrypted content")
+	}
+
+	original := PDFConverter
+	defer func() { PDFConverter = original }()
+	var converted []byte
+	PDFConverter = func(page []byte) ([]byte, error) {
+		converted = page
+		return []byte("%PDF-1.4"), nil
+	}
+
+	pdf, err := manager.ExportNotes("pdf")
+	if err != nil {
+		t.Fatalf("ExportNotes(pdf) error = %v", err)
+	}
+	if string(pdf) != "%PDF-1.4" || !bytes.Equal(converted, page) {
+		t.Error("PDF export should convert the HTML export")
+	}
+}
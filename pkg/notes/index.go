@@ -0,0 +1,157 @@
+package notes
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// titleWeight is how much more a term in the title counts than one in
+	// the content
+	titleWeight = 3
+	// prefixWeight scales the score of terms that only start with a query
+	// term
+	prefixWeight = 0.5
+)
+
+// index is an inverted index of the terms in note titles and content. The
+// content of encrypted notes is not indexed.
+type index struct {
+	// postings maps a term to the weighted number of times it occurs in
+	// each note
+	postings map[string]map[string]int
+	// noteTerms lists the terms of each note so it can be removed again
+	noteTerms map[string][]string
+	// terms is the sorted vocabulary, rebuilt when sorted is false. It has
+	// its own lock because searches rebuild it while holding only the read
+	// lock of the manager.
+	termsMu sync.Mutex
+	terms   []string
+	sorted  bool
+}
+
+func newIndex() *index {
+	return &index{
+		postings:  make(map[string]map[string]int),
+		noteTerms: make(map[string][]string),
+	}
+}
+
+// tokenize splits text into lower case words of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// add indexes note, replacing what was indexed for it before
+func (ix *index) add(note *Note) {
+	ix.remove(note.ID)
+
+	counts := make(map[string]int)
+	for _, term := range tokenize(note.Title) {
+		counts[term] += titleWeight
+	}
+	if !note.Encrypted {
+		for _, term := range tokenize(note.Content) {
+			counts[term]++
+		}
+	}
+
+	terms := make([]string, 0, len(counts))
+	for term, count := range counts {
+		postings, ok := ix.postings[term]
+		if !ok {
+			postings = make(map[string]int)
+			ix.postings[term] = postings
+			ix.setUnsorted()
+		}
+		postings[note.ID] = count
+		terms = append(terms, term)
+	}
+	ix.noteTerms[note.ID] = terms
+}
+
+// remove drops a note from the index
+func (ix *index) remove(id string) {
+	for _, term := range ix.noteTerms[id] {
+		postings := ix.postings[term]
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(ix.postings, term)
+			ix.setUnsorted()
+		}
+	}
+	delete(ix.noteTerms, id)
+}
+
+// search returns the relevance of every note containing all terms of query,
+// either as whole words or as the start of words
+func (ix *index) search(query string) map[string]float64 {
+	queryTerms := tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+	terms := ix.sortedTerms()
+
+	total := float64(len(ix.noteTerms))
+	var scores map[string]float64
+	for _, queryTerm := range queryTerms {
+		termScores := make(map[string]float64)
+		start := sort.SearchStrings(terms, queryTerm)
+		for _, term := range terms[start:] {
+			if !strings.HasPrefix(term, queryTerm) {
+				break
+			}
+			postings := ix.postings[term]
+			weight := math.Log(1 + total/float64(len(postings)))
+			if term != queryTerm {
+				weight *= prefixWeight
+			}
+			for id, count := range postings {
+				termScores[id] += float64(count) * weight
+			}
+		}
+
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id := range scores {
+			if score, ok := termScores[id]; ok {
+				scores[id] += score
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+	return scores
+}
+
+// setUnsorted marks the vocabulary as changed
+func (ix *index) setUnsorted() {
+	ix.termsMu.Lock()
+	ix.sorted = false
+	ix.termsMu.Unlock()
+}
+
+// sortedTerms returns the sorted vocabulary, rebuilding it after terms were
+// added or removed
+func (ix *index) sortedTerms() []string {
+	ix.termsMu.Lock()
+	defer ix.termsMu.Unlock()
+
+	if !ix.sorted {
+		terms := make([]string, 0, len(ix.postings))
+		for term := range ix.postings {
+			terms = append(terms, term)
+		}
+		sort.Strings(terms)
+		ix.terms = terms
+		ix.sorted = true
+	}
+	return ix.terms
+}
@@ -0,0 +1,106 @@
+package notes
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("Split-window: Ctrl+W s, 2 panes!")
+	want := []string{"split", "window", "ctrl", "w", "s", "2", "panes"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestFileManager_SearchRanking(t *testing.T) {
+	manager, err := NewFileManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inContent := &Note{Title: "Editing", Content: "open a split with ctrl-w s"}
+	inTitle := &Note{Title: "Window split", Content: "ctrl-w s and ctrl-w v"}
+	prefix := &Note{Title: "Splitting panes", Content: "prefix %"}
+	other := &Note{Title: "Git", Content: "git status"}
+	for _, note := range []*Note{inContent, inTitle, prefix, other} {
+		if err := manager.CreateNote(note); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, _ := manager.SearchNotes(SearchOptions{Query: "split"})
+	if len(results) != 3 {
+		t.Fatalf("SearchNotes() returned %d notes, want 3", len(results))
+	}
+	if results[0].ID != inTitle.ID {
+		t.Errorf("a title match should rank first, got %q", results[0].Title)
+	}
+
+	results, _ = manager.SearchNotes(SearchOptions{Query: "ctrl-w v"})
+	if len(results) != 1 || results[0].ID != inTitle.ID {
+		t.Errorf("all query words should match, got %v", results)
+	}
+
+	results, _ = manager.SearchNotes(SearchOptions{Query: "split", SortBy: "title"})
+	if len(results) != 3 || results[0].ID != inContent.ID {
+		t.Errorf("an explicit sort should replace the ranking, got %v", results)
+	}
+
+	results, _ = manager.SearchNotes(SearchOptions{Query: "%"})
+	if len(results) != 1 || results[0].ID != prefix.ID {
+		t.Errorf("queries without words should match literally, got %v", results)
+	}
+
+	// the index follows updates and deletes
+	manager.UpdateNote(other.ID, &Note{Title: "Git", Content: "split a hunk with s"})
+	results, _ = manager.SearchNotes(SearchOptions{Query: "hunk"})
+	if len(results) != 1 {
+		t.Errorf("updated content should be searchable, got %d notes", len(results))
+	}
+	results, _ = manager.SearchNotes(SearchOptions{Query: "status"})
+	if len(results) != 0 {
+		t.Errorf("replaced content should not be found, got %d notes", len(results))
+	}
+
+	manager.DeleteNote(inTitle.ID)
+	results, _ = manager.SearchNotes(SearchOptions{Query: "split"})
+	if len(results) != 3 {
+		t.Errorf("SearchNotes() after delete returned %d notes, want 3", len(results))
+	}
+	results, _ = manager.SearchNotes(SearchOptions{Query: "window"})
+	if len(results) != 0 {
+		t.Errorf("deleted notes should not be found, got %d notes", len(results))
+	}
+
+	// notes loaded from disk are indexed
+	reopened, err := NewStorageManager(manager.store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, _ = reopened.SearchNotes(SearchOptions{Query: "hunk"})
+	if len(results) != 1 {
+		t.Errorf("loaded notes should be searchable, got %d notes", len(results))
+	}
+}
+
+func BenchmarkFileManager_SearchNotes(b *testing.B) {
+	manager, err := NewFileManager(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < 10000; i++ {
+		note := &Note{
+			ID:      fmt.Sprintf("note-%d", i),
+			Title:   fmt.Sprintf("Note %d", i),
+			Content: fmt.Sprintf("keyword%d shared words about shortcuts and splits %d", i%100, i),
+		}
+		manager.notes[note.ID] = note
+		manager.index.add(note)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		manager.SearchNotes(SearchOptions{Query: "keyword42 splits"})
+	}
+}
@@ -1,7 +1,6 @@
 package notes
 
 import (
-	"cheat-go/pkg/apps"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +12,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+
 	"gopkg.in/yaml.v3"
 )
 
@@ -20,84 +23,119 @@ var (
 	ErrNoteNotFound  = errors.New("note not found")
 	ErrInvalidFormat = errors.New("invalid format")
 	ErrNoteExists    = errors.New("note already exists")
+	ErrReadOnly      = errors.New("notes are read-only")
 )
 
 type FileManager struct {
-	dataDir string
-	mu      sync.RWMutex
-	notes   map[string]*Note
+	store    storage.Storage
+	mu       sync.RWMutex
+	notes    map[string]*Note
+	trash    map[string]*TrashedNote
+	team     map[string]*Note
+	readOnly bool
+	journal  *journal.Journal
+	source   journal.Source
+	index    *index
+
+	// unloaded holds the notes whose content has not been read yet. Readers
+	// share fm.mu, so bodyMu guards loading content.
+	bodyMu   sync.Mutex
+	unloaded map[string]bool
 }
 
+// NewFileManager creates a manager keeping each note in dataDir/files and
+// the notes index in dataDir/index.json
 func NewFileManager(dataDir string) (*FileManager, error) {
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create notes directory: %w", err)
 	}
 
+	store := storage.NewFileStorage(dataDir).
+		Mount(storage.CollectionNotes, dataDir, ".json").
+		Mount(storage.CollectionNoteFiles, filepath.Join(dataDir, "files"), ".json")
+	return NewStorageManager(store)
+}
+
+// NewStorageManager creates a manager keeping notes in the notes collection
+// of store
+func NewStorageManager(store storage.Storage) (*FileManager, error) {
 	fm := &FileManager{
-		dataDir: dataDir,
-		notes:   make(map[string]*Note),
+		store:    store,
+		notes:    make(map[string]*Note),
+		trash:    make(map[string]*TrashedNote),
+		team:     make(map[string]*Note),
+		index:    newIndex(),
+		unloaded: make(map[string]bool),
 	}
 
 	if err := fm.loadNotes(); err != nil {
 		return nil, fmt.Errorf("failed to load notes: %w", err)
 	}
-
-	return fm, nil
-}
-
-func (fm *FileManager) loadNotes() error {
-	notesFile := filepath.Join(fm.dataDir, "notes.json")
-
-	if _, err := os.Stat(notesFile); os.IsNotExist(err) {
-		return nil
-	}
-
-	data, err := os.ReadFile(notesFile)
-	if err != nil {
-		return fmt.Errorf("failed to read notes file: %w", err)
-	}
-
-	var notes []*Note
-	if err := json.Unmarshal(data, &notes); err != nil {
-		return fmt.Errorf("failed to unmarshal notes: %w", err)
+	if err := fm.loadTrash(); err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
 	}
-
-	for _, note := range notes {
-		fm.notes[note.ID] = note
+	if err := fm.loadTeamNotes(); err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
 	}
 
-	return nil
+	return fm, nil
 }
 
-func (fm *FileManager) saveNotes() error {
-	notesFile := filepath.Join(fm.dataDir, "notes.json")
+// SetReadOnly disables all modifications, e.g. while another instance owns the notes
+func (fm *FileManager) SetReadOnly(readOnly bool) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.readOnly = readOnly
+}
 
-	notes := make([]*Note, 0, len(fm.notes))
-	for _, note := range fm.notes {
-		notes = append(notes, note)
-	}
+// SetJournal records every change made through the manager in j,
+// attributed to source
+func (fm *FileManager) SetJournal(j *journal.Journal, source journal.Source) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.journal = j
+	fm.source = source
+}
 
-	data, err := json.MarshalIndent(notes, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal notes: %w", err)
+// record appends a change to the journal, if one is configured. Journal
+// failures never fail the change itself.
+func (fm *FileManager) record(action journal.Action, source journal.Source, id string, before, after *Note) {
+	if fm.journal == nil {
+		return
 	}
-
-	if err := os.WriteFile(notesFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write notes file: %w", err)
+	name := ""
+	if after != nil {
+		name = after.Title
+	} else if before != nil {
+		name = before.Title
 	}
+	fm.journal.Record(action, journal.KindNote, id, name, source, before, after)
+}
 
-	return nil
+// IsReadOnly reports whether modifications are disabled
+func (fm *FileManager) IsReadOnly() bool {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.readOnly
 }
 
 func (fm *FileManager) CreateNote(note *Note) error {
 	fm.mu.Lock()
 	defer fm.mu.Unlock()
 
+	if fm.readOnly {
+		return ErrReadOnly
+	}
+
+	if note.Encrypted && !IsEncryptedContent(note.Content) {
+		return ErrEncryptionRequired
+	}
+
 	if note.ID == "" {
 		note.ID = generateID()
 	}
 
-	if _, exists := fm.notes[note.ID]; exists {
+	if _, exists := fm.lookup(note.ID); exists {
 		return ErrNoteExists
 	}
 
@@ -105,17 +143,35 @@ func (fm *FileManager) CreateNote(note *Note) error {
 	note.UpdatedAt = time.Now()
 
 	fm.notes[note.ID] = note
-	return fm.saveNotes()
+	fm.index.add(note)
+	if err := fm.saveNotes(note.ID); err != nil {
+		return err
+	}
+
+	// recreating a deleted note, e.g. when a delete is undone, takes it out
+	// of the trash
+	if _, trashed := fm.trash[note.ID]; trashed {
+		delete(fm.trash, note.ID)
+		if err := fm.saveTrash(); err != nil {
+			return err
+		}
+	}
+
+	fm.record(journal.ActionCreate, fm.source, note.ID, nil, note)
+	return nil
 }
 
 func (fm *FileManager) GetNote(id string) (*Note, error) {
 	fm.mu.RLock()
 	defer fm.mu.RUnlock()
 
-	note, exists := fm.notes[id]
+	note, exists := fm.lookup(id)
 	if !exists {
 		return nil, ErrNoteNotFound
 	}
+	if err := fm.loadBody(id); err != nil {
+		return nil, err
+	}
 
 	return note, nil
 }
@@ -124,9 +180,19 @@ func (fm *FileManager) UpdateNote(id string, updatedNote *Note) error {
 	fm.mu.Lock()
 	defer fm.mu.Unlock()
 
+	if fm.readOnly {
+		return ErrReadOnly
+	}
+
 	note, exists := fm.notes[id]
 	if !exists {
-		return ErrNoteNotFound
+		return fm.missing(id)
+	}
+	if err := fm.loadBody(id); err != nil {
+		return err
+	}
+	if updatedNote.Encrypted && !IsEncryptedContent(updatedNote.Content) {
+		return ErrEncryptionRequired
 	}
 
 	updatedNote.ID = id
@@ -134,35 +200,88 @@ func (fm *FileManager) UpdateNote(id string, updatedNote *Note) error {
 	updatedNote.UpdatedAt = time.Now()
 
 	fm.notes[id] = updatedNote
-	return fm.saveNotes()
+	fm.index.add(updatedNote)
+	if err := fm.saveNotes(id); err != nil {
+		return err
+	}
+
+	fm.record(journal.ActionUpdate, fm.source, id, note, updatedNote)
+	return nil
 }
 
+// DeleteNote moves a note to the trash, from where RestoreNote brings it back
 func (fm *FileManager) DeleteNote(id string) error {
 	fm.mu.Lock()
 	defer fm.mu.Unlock()
 
-	if _, exists := fm.notes[id]; !exists {
-		return ErrNoteNotFound
+	if fm.readOnly {
+		return ErrReadOnly
+	}
+
+	note, exists := fm.notes[id]
+	if !exists {
+		return fm.missing(id)
+	}
+	if err := fm.loadBody(id); err != nil {
+		return err
 	}
 
 	delete(fm.notes, id)
-	return fm.saveNotes()
+	fm.index.remove(id)
+	fm.trash[id] = &TrashedNote{Note: note, DeletedAt: time.Now()}
+	if err := fm.saveTrash(); err != nil {
+		return err
+	}
+	if err := fm.saveNotes(id); err != nil {
+		return err
+	}
+
+	fm.record(journal.ActionDelete, fm.source, id, note, nil)
+	return nil
 }
 
+// SearchNotes returns the notes matching opts. A query matches notes that
+// contain all of its words, or words starting with them, in their title or
+// content. Query results are ranked by relevance unless opts.SortBy is set.
 func (fm *FileManager) SearchNotes(opts SearchOptions) ([]*Note, error) {
 	fm.mu.RLock()
 	defer fm.mu.RUnlock()
 
+	if err := fm.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
 	results := []*Note{}
 
-	for _, note := range fm.notes {
-		if !matchesSearchOptions(note, opts) {
-			continue
+	var scores map[string]float64
+	if opts.Query != "" {
+		scores = fm.index.search(opts.Query)
+	}
+
+	if scores != nil {
+		filters := opts
+		filters.Query = ""
+		for id := range scores {
+			if note, _ := fm.lookup(id); note != nil && matchesSearchOptions(note, filters) {
+				results = append(results, note)
+			}
+		}
+	} else {
+		// queries without any words, such as punctuation, are matched literally
+		for _, notes := range []map[string]*Note{fm.notes, fm.team} {
+			for _, note := range notes {
+				if matchesSearchOptions(note, opts) {
+					results = append(results, note)
+				}
+			}
 		}
-		results = append(results, note)
 	}
 
-	sortNotes(results, opts.SortBy)
+	if scores != nil && (opts.SortBy == "" || opts.SortBy == SortByRelevance) {
+		sortByRelevance(results, scores)
+	} else {
+		sortNotes(results, opts.SortBy)
+	}
 
 	if opts.Limit > 0 && len(results) > opts.Limit {
 		results = results[opts.Offset:min(opts.Offset+opts.Limit, len(results))]
@@ -171,14 +290,22 @@ func (fm *FileManager) SearchNotes(opts SearchOptions) ([]*Note, error) {
 	return results, nil
 }
 
+// ListNotes returns the personal and team notes, most recently updated first
 func (fm *FileManager) ListNotes() ([]*Note, error) {
 	fm.mu.RLock()
 	defer fm.mu.RUnlock()
 
-	notes := make([]*Note, 0, len(fm.notes))
+	if err := fm.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	notes := make([]*Note, 0, len(fm.notes)+len(fm.team))
 	for _, note := range fm.notes {
 		notes = append(notes, note)
 	}
+	for _, note := range fm.team {
+		notes = append(notes, note)
+	}
 
 	sortNotes(notes, "updated_at")
 	return notes, nil
@@ -188,55 +315,98 @@ func (fm *FileManager) AddShortcutToNote(noteID string, shortcut apps.Shortcut)
 	fm.mu.Lock()
 	defer fm.mu.Unlock()
 
+	if fm.readOnly {
+		return ErrReadOnly
+	}
+
 	note, exists := fm.notes[noteID]
 	if !exists {
-		return ErrNoteNotFound
+		return fm.missing(noteID)
+	}
+	if err := fm.loadBody(noteID); err != nil {
+		return err
 	}
 
+	before := note.clone()
 	note.Shortcuts = append(note.Shortcuts, shortcut)
 	note.UpdatedAt = time.Now()
 
-	return fm.saveNotes()
+	if err := fm.saveNotes(noteID); err != nil {
+		return err
+	}
+
+	fm.record(journal.ActionUpdate, fm.source, noteID, before, note)
+	return nil
 }
 
 func (fm *FileManager) RemoveShortcutFromNote(noteID string, shortcutIndex int) error {
 	fm.mu.Lock()
 	defer fm.mu.Unlock()
 
+	if fm.readOnly {
+		return ErrReadOnly
+	}
+
 	note, exists := fm.notes[noteID]
 	if !exists {
-		return ErrNoteNotFound
+		return fm.missing(noteID)
+	}
+	if err := fm.loadBody(noteID); err != nil {
+		return err
 	}
 
 	if shortcutIndex < 0 || shortcutIndex >= len(note.Shortcuts) {
 		return fmt.Errorf("invalid shortcut index")
 	}
 
+	before := note.clone()
 	note.Shortcuts = append(note.Shortcuts[:shortcutIndex], note.Shortcuts[shortcutIndex+1:]...)
 	note.UpdatedAt = time.Now()
 
-	return fm.saveNotes()
+	if err := fm.saveNotes(noteID); err != nil {
+		return err
+	}
+
+	fm.record(journal.ActionUpdate, fm.source, noteID, before, note)
+	return nil
 }
 
 func (fm *FileManager) ToggleFavorite(id string) error {
 	fm.mu.Lock()
 	defer fm.mu.Unlock()
 
+	if fm.readOnly {
+		return ErrReadOnly
+	}
+
 	note, exists := fm.notes[id]
 	if !exists {
-		return ErrNoteNotFound
+		return fm.missing(id)
+	}
+	if err := fm.loadBody(id); err != nil {
+		return err
 	}
 
+	before := note.clone()
 	note.IsFavorite = !note.IsFavorite
 	note.UpdatedAt = time.Now()
 
-	return fm.saveNotes()
+	if err := fm.saveNotes(id); err != nil {
+		return err
+	}
+
+	fm.record(journal.ActionUpdate, fm.source, id, before, note)
+	return nil
 }
 
 func (fm *FileManager) ExportNotes(format string) ([]byte, error) {
 	fm.mu.RLock()
 	defer fm.mu.RUnlock()
 
+	if err := fm.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
 	notes := make([]*Note, 0, len(fm.notes))
 	for _, note := range fm.notes {
 		notes = append(notes, note)
@@ -249,6 +419,10 @@ func (fm *FileManager) ExportNotes(format string) ([]byte, error) {
 		return yaml.Marshal(notes)
 	case "markdown", "md":
 		return exportToMarkdown(notes), nil
+	case "html":
+		return exportToHTML(notes)
+	case "pdf":
+		return exportToPDF(notes)
 	default:
 		return nil, ErrInvalidFormat
 	}
@@ -273,23 +447,40 @@ func (fm *FileManager) ImportNotes(data []byte, format string) error {
 	fm.mu.Lock()
 	defer fm.mu.Unlock()
 
+	if fm.readOnly {
+		return ErrReadOnly
+	}
+
+	imported := []*Note{}
+	ids := []string{}
 	for _, note := range notes {
 		if note.ID == "" {
 			note.ID = generateID()
 		}
 		if _, exists := fm.notes[note.ID]; !exists {
 			fm.notes[note.ID] = note
+			fm.index.add(note)
+			imported = append(imported, note)
+			ids = append(ids, note.ID)
 		}
 	}
 
-	return fm.saveNotes()
+	if err := fm.saveNotes(ids...); err != nil {
+		return err
+	}
+
+	for _, note := range imported {
+		fm.record(journal.ActionCreate, journal.SourceImport, note.ID, nil, note)
+	}
+	return nil
 }
 
 func matchesSearchOptions(note *Note, opts SearchOptions) bool {
 	if opts.Query != "" {
 		query := strings.ToLower(opts.Query)
+		// the content of encrypted notes cannot be searched
 		if !strings.Contains(strings.ToLower(note.Title), query) &&
-			!strings.Contains(strings.ToLower(note.Content), query) {
+			(note.Encrypted || !strings.Contains(strings.ToLower(note.Content), query)) {
 			return false
 		}
 	}
@@ -302,6 +493,10 @@ func matchesSearchOptions(note *Note, opts SearchOptions) bool {
 		return false
 	}
 
+	if opts.ShortcutKeys != "" && note.ShortcutKeys != opts.ShortcutKeys {
+		return false
+	}
+
 	if opts.OnlyFavorites && !note.IsFavorite {
 		return false
 	}
@@ -327,6 +522,17 @@ func matchesSearchOptions(note *Note, opts SearchOptions) bool {
 	return true
 }
 
+// sortByRelevance orders notes by descending score, most recently updated
+// first among equal scores
+func sortByRelevance(notes []*Note, scores map[string]float64) {
+	sort.Slice(notes, func(i, j int) bool {
+		if scores[notes[i].ID] != scores[notes[j].ID] {
+			return scores[notes[i].ID] > scores[notes[j].ID]
+		}
+		return notes[i].UpdatedAt.After(notes[j].UpdatedAt)
+	})
+}
+
 func sortNotes(notes []*Note, sortBy string) {
 	switch sortBy {
 	case "title":
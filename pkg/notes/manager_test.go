@@ -2,12 +2,16 @@ package notes
 
 import (
 	"bytes"
-	"cheat-go/pkg/apps"
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
 )
 
 func TestFileManager_CreateNote(t *testing.T) {
@@ -179,11 +183,12 @@ func TestFileManager_SearchNotes(t *testing.T) {
 			Tags:     []string{"git", "version-control"},
 		},
 		{
-			Title:    "Vim Advanced",
-			Content:  "Advanced vim techniques",
-			AppName:  "vim",
-			Category: "editor",
-			Tags:     []string{"vim", "advanced"},
+			Title:        "Vim Advanced",
+			Content:      "Advanced vim techniques",
+			AppName:      "vim",
+			Category:     "editor",
+			Tags:         []string{"vim", "advanced"},
+			ShortcutKeys: "gg",
 		},
 	}
 
@@ -226,6 +231,11 @@ func TestFileManager_SearchNotes(t *testing.T) {
 			opts:     SearchOptions{AppName: "vim", Tags: []string{"editor"}},
 			expected: 1,
 		},
+		{
+			name:     "Search by attached shortcut",
+			opts:     SearchOptions{AppName: "vim", ShortcutKeys: "gg"},
+			expected: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -784,3 +794,125 @@ func TestFileManager_LoadNotesErrors(t *testing.T) {
 		t.Error("Manager should be nil on error")
 	}
 }
+
+func TestFileManager_ReadOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewFileManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	note := &Note{Title: "Existing", Content: "content"}
+	if err := manager.CreateNote(note); err != nil {
+		t.Fatalf("CreateNote() error = %v", err)
+	}
+
+	manager.SetReadOnly(true)
+	if !manager.IsReadOnly() {
+		t.Fatal("manager should be read-only")
+	}
+
+	if err := manager.CreateNote(&Note{Title: "New"}); err != ErrReadOnly {
+		t.Errorf("CreateNote() error = %v, want ErrReadOnly", err)
+	}
+	if err := manager.UpdateNote(note.ID, &Note{Title: "Changed"}); err != ErrReadOnly {
+		t.Errorf("UpdateNote() error = %v, want ErrReadOnly", err)
+	}
+	if err := manager.DeleteNote(note.ID); err != ErrReadOnly {
+		t.Errorf("DeleteNote() error = %v, want ErrReadOnly", err)
+	}
+	if err := manager.ToggleFavorite(note.ID); err != ErrReadOnly {
+		t.Errorf("ToggleFavorite() error = %v, want ErrReadOnly", err)
+	}
+
+	// Reads keep working
+	got, err := manager.GetNote(note.ID)
+	if err != nil || got.Title != "Existing" {
+		t.Errorf("GetNote() = %v, %v", got, err)
+	}
+
+	manager.SetReadOnly(false)
+	if err := manager.DeleteNote(note.ID); err != nil {
+		t.Errorf("DeleteNote() after SetReadOnly(false) error = %v", err)
+	}
+}
+
+func TestFileManager_Journal(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewFileManager(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	j, err := journal.Open(filepath.Join(tempDir, "journal.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.SetJournal(j, journal.SourceUI)
+
+	note := &Note{Title: "Original", Content: "content"}
+	manager.CreateNote(note)
+	manager.UpdateNote(note.ID, &Note{Title: "Renamed", Content: "content"})
+	manager.ToggleFavorite(note.ID)
+	manager.DeleteNote(note.ID)
+	manager.ImportNotes([]byte(`[{"id":"imported","title":"Imported"}]`), "json")
+
+	entries, err := j.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	want := []journal.Action{journal.ActionCreate, journal.ActionUpdate, journal.ActionUpdate, journal.ActionDelete, journal.ActionCreate}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(entries))
+	}
+	for i, action := range want {
+		if entries[i].Action != action || entries[i].Kind != journal.KindNote {
+			t.Errorf("entry %d = %s %s, want %s note", i, entries[i].Action, entries[i].Kind, action)
+		}
+	}
+
+	var before Note
+	json.Unmarshal(entries[1].Before, &before)
+	if before.Title != "Original" || entries[1].Name != "Renamed" {
+		t.Errorf("update entry before=%q name=%q", before.Title, entries[1].Name)
+	}
+
+	var favBefore, favAfter Note
+	json.Unmarshal(entries[2].Before, &favBefore)
+	json.Unmarshal(entries[2].After, &favAfter)
+	if favBefore.IsFavorite || !favAfter.IsFavorite {
+		t.Error("favorite toggle should record distinct before and after states")
+	}
+
+	if entries[3].After != nil {
+		t.Error("delete entry should have no after state")
+	}
+	if entries[4].Source != journal.SourceImport || entries[4].ID != "imported" {
+		t.Errorf("import entry = %+v", entries[4])
+	}
+}
+
+func TestStorageManager_SQLite(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "cheat-go.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage() error = %v", err)
+	}
+	defer store.Close()
+
+	manager, err := NewStorageManager(store)
+	if err != nil {
+		t.Fatalf("NewStorageManager() error = %v", err)
+	}
+	note := &Note{Title: "Stored", Content: "in sqlite"}
+	if err := manager.CreateNote(note); err != nil {
+		t.Fatalf("CreateNote() error = %v", err)
+	}
+
+	reloaded, err := NewStorageManager(store)
+	if err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+	got, err := reloaded.GetNote(note.ID)
+	if err != nil || got.Content != "in sqlite" {
+		t.Errorf("GetNote() = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,116 @@
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+// teamKey is the document holding the notes shared by the team in the notes
+// collection. Team notes come from sync and are never pushed back.
+const teamKey = "team"
+
+// ErrTeamNote is returned when changing a note shared by the team
+var ErrTeamNote = errors.New("team notes are read-only")
+
+func (fm *FileManager) loadTeamNotes() error {
+	var team []*Note
+	if err := storage.GetJSON(fm.store, storage.CollectionNotes, teamKey, &team); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to read team notes: %w", err)
+	}
+
+	for _, note := range team {
+		// personal notes win should an ID be taken twice
+		if _, exists := fm.notes[note.ID]; exists {
+			continue
+		}
+		fm.team[note.ID] = note
+		fm.index.add(note)
+	}
+	return nil
+}
+
+// lookup returns a personal or team note
+func (fm *FileManager) lookup(id string) (*Note, bool) {
+	if note, exists := fm.notes[id]; exists {
+		return note, true
+	}
+	note, exists := fm.team[id]
+	return note, exists
+}
+
+// missing is the error for changing a note that is not a personal note
+func (fm *FileManager) missing(id string) error {
+	if _, exists := fm.team[id]; exists {
+		return ErrTeamNote
+	}
+	return ErrNoteNotFound
+}
+
+// TeamNotes returns the notes shared by the team, most recently updated first
+func (fm *FileManager) TeamNotes() []*Note {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+
+	notes := make([]*Note, 0, len(fm.team))
+	for _, note := range fm.team {
+		notes = append(notes, note)
+	}
+	sortNotes(notes, "updated_at")
+	return notes
+}
+
+// ReplaceTeamNotes replaces the notes shared by team with notes, without
+// journaling the changes. Sync uses it to store the notes pulled for the
+// team; they are listed and searched with the personal notes but cannot be
+// changed.
+func (fm *FileManager) ReplaceTeamNotes(team string, notes []*Note) error {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.readOnly {
+		return ErrReadOnly
+	}
+
+	for id := range fm.team {
+		fm.index.remove(id)
+	}
+	fm.team = make(map[string]*Note, len(notes))
+	for _, note := range notes {
+		if _, exists := fm.notes[note.ID]; exists {
+			continue
+		}
+		note.Team = team
+		fm.team[note.ID] = note
+		fm.index.add(note)
+	}
+	return fm.saveTeamNotes()
+}
+
+// saveTeamNotes writes the team notes, sorted by ID so they diff cleanly
+func (fm *FileManager) saveTeamNotes() error {
+	if len(fm.team) == 0 {
+		if err := fm.store.Delete(storage.CollectionNotes, teamKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("failed to write team notes: %w", err)
+		}
+		return nil
+	}
+
+	team := make([]*Note, 0, len(fm.team))
+	for _, note := range fm.team {
+		team = append(team, note)
+	}
+	sort.Slice(team, func(i, j int) bool {
+		return team[i].ID < team[j].ID
+	})
+
+	if err := storage.PutJSON(fm.store, storage.CollectionNotes, teamKey, team); err != nil {
+		return fmt.Errorf("failed to write team notes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package notes
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFileManager_TeamNotes(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewFileManager(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	personal := &Note{Title: "Mine", Content: "deploy checklist"}
+	manager.CreateNote(personal)
+
+	shared := []*Note{{ID: "team-1", Title: "Runbook", Content: "deploy steps for the team"}}
+	if err := manager.ReplaceTeamNotes("ops", shared); err != nil {
+		t.Fatalf("ReplaceTeamNotes() error = %v", err)
+	}
+
+	list, _ := manager.ListNotes()
+	if len(list) != 2 {
+		t.Fatalf("ListNotes() returned %d notes, want the personal and team note", len(list))
+	}
+	if results, _ := manager.SearchNotes(SearchOptions{Query: "deploy"}); len(results) != 2 {
+		t.Errorf("team notes should be searched, got %d results", len(results))
+	}
+	if note, err := manager.GetNote("team-1"); err != nil || note.Team != "ops" {
+		t.Errorf("GetNote() = %+v, %v", note, err)
+	}
+
+	if err := manager.UpdateNote("team-1", &Note{Title: "Changed"}); !errors.Is(err, ErrTeamNote) {
+		t.Errorf("UpdateNote() of a team note error = %v, want %v", err, ErrTeamNote)
+	}
+	if err := manager.DeleteNote("team-1"); !errors.Is(err, ErrTeamNote) {
+		t.Errorf("DeleteNote() of a team note error = %v, want %v", err, ErrTeamNote)
+	}
+	if err := manager.ToggleFavorite("team-1"); !errors.Is(err, ErrTeamNote) {
+		t.Errorf("ToggleFavorite() of a team note error = %v, want %v", err, ErrTeamNote)
+	}
+	if err := manager.CreateNote(&Note{ID: "team-1"}); !errors.Is(err, ErrNoteExists) {
+		t.Errorf("CreateNote() with the ID of a team note error = %v", err)
+	}
+
+	// replacing the personal notes, as sync does, keeps the team notes
+	if err := manager.ReplaceNotes([]*Note{personal}); err != nil {
+		t.Fatal(err)
+	}
+
+	// team notes survive a restart
+	manager, err = NewFileManager(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if team := manager.TeamNotes(); len(team) != 1 || team[0].Team != "ops" {
+		t.Errorf("TeamNotes() after a restart = %+v", team)
+	}
+
+	if err := manager.ReplaceTeamNotes("ops", nil); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ := manager.SearchNotes(SearchOptions{Query: "deploy"}); len(results) != 1 {
+		t.Errorf("removed team notes should not be searched, got %d results", len(results))
+	}
+	if _, err := manager.GetNote("team-1"); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("removed team note error = %v", err)
+	}
+}
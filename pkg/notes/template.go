@@ -0,0 +1,166 @@
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+	ErrInvalidTemplate  = errors.New("invalid template")
+)
+
+// Template is a starting point for new notes. Title and Content may contain
+// the variables {{app}}, {{date}} and {{time}}.
+type Template struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	Title       string   `json:"title" yaml:"title"`
+	Category    string   `json:"category" yaml:"category"`
+	Tags        []string `json:"tags" yaml:"tags"`
+	Content     string   `json:"content" yaml:"content"`
+}
+
+// TemplateVars are the values substituted into a template
+type TemplateVars struct {
+	App  string
+	Time time.Time
+}
+
+// BuiltinTemplates returns the templates available without any user files
+func BuiltinTemplates() []Template {
+	return []Template{
+		{
+			Name:        "blank",
+			Description: "Empty note",
+			Title:       "New Note {{date}} {{time}}",
+			Category:    "general",
+			Content:     "",
+		},
+		{
+			Name:        "app-cheatsheet",
+			Description: "Personal cheat sheet for an app",
+			Title:       "{{app}} cheat sheet",
+			Category:    "cheatsheet",
+			Tags:        []string{"cheatsheet"},
+			Content: "# {{app}}\n\n" +
+				"## Most used\n\n- \n\n" +
+				"## Hard to remember\n\n- \n\n" +
+				"## Config\n\n",
+		},
+		{
+			Name:        "troubleshooting",
+			Description: "Log of a problem and its fix",
+			Title:       "{{app}} issue {{date}}",
+			Category:    "troubleshooting",
+			Tags:        []string{"troubleshooting"},
+			Content: "# Problem\n\n" +
+				"Seen on {{date}} at {{time}} in {{app}}.\n\n" +
+				"# Steps tried\n\n1. \n\n" +
+				"# Solution\n\n",
+		},
+	}
+}
+
+// LoadTemplates returns the built-in templates followed by the user
+// templates in dir, one YAML file per template. A user template replaces the
+// built-in template of the same name. A missing dir only yields the built-in
+// templates.
+func LoadTemplates(dir string) ([]Template, error) {
+	templates := BuiltinTemplates()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return templates, nil
+		}
+		return templates, fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	var user []Template
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		tmpl, err := loadTemplateFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return templates, err
+		}
+		user = append(user, *tmpl)
+	}
+	sort.Slice(user, func(i, j int) bool { return user[i].Name < user[j].Name })
+
+	for _, tmpl := range user {
+		replaced := false
+		for i := range templates {
+			if templates[i].Name == tmpl.Name {
+				templates[i] = tmpl
+				replaced = true
+			}
+		}
+		if !replaced {
+			templates = append(templates, tmpl)
+		}
+	}
+	return templates, nil
+}
+
+// loadTemplateFile reads a template, naming it after the file when the name
+// is not set
+func loadTemplateFile(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	var tmpl Template
+	if err := yaml.Unmarshal(data, &tmpl); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, filepath.Base(path), err)
+	}
+	if tmpl.Name == "" {
+		tmpl.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return &tmpl, nil
+}
+
+// FindTemplate returns the template called name
+func FindTemplate(templates []Template, name string) (*Template, error) {
+	for i := range templates {
+		if templates[i].Name == name {
+			return &templates[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+}
+
+// NewNote creates an unsaved note from the template, substituting vars
+func (t Template) NewNote(vars TemplateVars) *Note {
+	if vars.Time.IsZero() {
+		vars.Time = time.Now()
+	}
+	app := vars.App
+	if app == "" {
+		app = "general"
+	}
+	replacer := strings.NewReplacer(
+		"{{app}}", app,
+		"{{date}}", vars.Time.Format("2006-01-02"),
+		"{{time}}", vars.Time.Format("15:04"),
+	)
+
+	return &Note{
+		Title:    strings.TrimSpace(replacer.Replace(t.Title)),
+		Content:  replacer.Replace(t.Content),
+		AppName:  vars.App,
+		Category: t.Category,
+		Tags:     append([]string(nil), t.Tags...),
+	}
+}
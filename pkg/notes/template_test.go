@@ -0,0 +1,73 @@
+package notes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplate_NewNote(t *testing.T) {
+	tmpl, err := FindTemplate(BuiltinTemplates(), "troubleshooting")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2024, 3, 9, 14, 5, 0, 0, time.UTC)
+	note := tmpl.NewNote(TemplateVars{App: "vim", Time: at})
+	if note.Title != "vim issue 2024-03-09" {
+		t.Errorf("title = %q", note.Title)
+	}
+	if note.AppName != "vim" || note.Category != "troubleshooting" {
+		t.Errorf("note = %+v", note)
+	}
+	if want := "Seen on 2024-03-09 at 14:05 in vim."; !strings.Contains(note.Content, want) {
+		t.Errorf("content should contain %q:\n%s", want, note.Content)
+	}
+
+	note.Tags[0] = "changed"
+	if tmpl.Tags[0] != "troubleshooting" {
+		t.Error("notes should not share tags with their template")
+	}
+
+	if note := tmpl.NewNote(TemplateVars{Time: at}); note.Title != "general issue 2024-03-09" || note.AppName != "" {
+		t.Errorf("note without app = %+v", note)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+
+	templates, err := LoadTemplates(filepath.Join(dir, "missing"))
+	if err != nil || len(templates) != len(BuiltinTemplates()) {
+		t.Fatalf("missing dir should give the built-in templates, got %d (%v)", len(templates), err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "blank.yaml"), []byte("title: Scratch\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "meeting.yml"), []byte("name: meeting\ntitle: Meeting {{date}}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644)
+
+	templates, err = LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+	if len(templates) != len(BuiltinTemplates())+1 {
+		t.Fatalf("expected one added template, got %d", len(templates))
+	}
+	if blank, _ := FindTemplate(templates, "blank"); blank.Title != "Scratch" {
+		t.Errorf("user template should replace the built-in one, got %+v", blank)
+	}
+	if _, err := FindTemplate(templates, "meeting"); err != nil {
+		t.Errorf("user template missing: %v", err)
+	}
+	if _, err := FindTemplate(templates, "nope"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound, got %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("title: [unclosed\n"), 0644)
+	if _, err := LoadTemplates(dir); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("expected ErrInvalidTemplate, got %v", err)
+	}
+}
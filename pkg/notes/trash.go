@@ -0,0 +1,155 @@
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+// trashKey is the document holding deleted notes in the notes collection
+const trashKey = "trash"
+
+// DefaultTrashRetention is how long deleted notes are kept in the trash
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashedNote is a deleted note kept in the trash
+type TrashedNote struct {
+	Note      *Note     `json:"note" yaml:"note"`
+	DeletedAt time.Time `json:"deleted_at" yaml:"deleted_at"`
+}
+
+// TrashManager is implemented by managers that move deleted notes to a trash
+type TrashManager interface {
+	ListTrash() ([]*TrashedNote, error)
+	RestoreNote(id string) error
+	EmptyTrash() (int, error)
+	PurgeTrash(retention time.Duration) (int, error)
+}
+
+func (fm *FileManager) loadTrash() error {
+	var trash []*TrashedNote
+	if err := storage.GetJSON(fm.store, storage.CollectionNotes, trashKey, &trash); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	for _, trashed := range trash {
+		if trashed.Note != nil {
+			fm.trash[trashed.Note.ID] = trashed
+		}
+	}
+	return nil
+}
+
+func (fm *FileManager) saveTrash() error {
+	trash := make([]*TrashedNote, 0, len(fm.trash))
+	for _, trashed := range fm.trash {
+		trash = append(trash, trashed)
+	}
+
+	if err := storage.PutJSON(fm.store, storage.CollectionNotes, trashKey, trash); err != nil {
+		return fmt.Errorf("failed to write trash: %w", err)
+	}
+	return nil
+}
+
+// ListTrash returns the deleted notes, most recently deleted first
+func (fm *FileManager) ListTrash() ([]*TrashedNote, error) {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+
+	trash := make([]*TrashedNote, 0, len(fm.trash))
+	for _, trashed := range fm.trash {
+		trash = append(trash, trashed)
+	}
+	sort.Slice(trash, func(i, j int) bool {
+		return trash[i].DeletedAt.After(trash[j].DeletedAt)
+	})
+	return trash, nil
+}
+
+// RestoreNote moves a deleted note from the trash back to the notes
+func (fm *FileManager) RestoreNote(id string) error {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.readOnly {
+		return ErrReadOnly
+	}
+
+	trashed, exists := fm.trash[id]
+	if !exists {
+		return ErrNoteNotFound
+	}
+	if _, exists := fm.notes[id]; exists {
+		return ErrNoteExists
+	}
+
+	note := trashed.Note
+	fm.notes[id] = note
+	fm.index.add(note)
+	delete(fm.trash, id)
+	if err := fm.saveNotes(id); err != nil {
+		return err
+	}
+	if err := fm.saveTrash(); err != nil {
+		return err
+	}
+
+	fm.record(journal.ActionCreate, fm.source, id, nil, note)
+	return nil
+}
+
+// EmptyTrash permanently removes all deleted notes and returns how many
+// were removed
+func (fm *FileManager) EmptyTrash() (int, error) {
+	return fm.purgeTrash(func(*TrashedNote) bool { return true })
+}
+
+// PurgeTrash permanently removes notes deleted longer than retention ago
+// and returns how many were removed. A retention of zero means
+// DefaultTrashRetention.
+func (fm *FileManager) PurgeTrash(retention time.Duration) (int, error) {
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+	cutoff := time.Now().Add(-retention)
+	return fm.purgeTrash(func(trashed *TrashedNote) bool {
+		return trashed.DeletedAt.Before(cutoff)
+	})
+}
+
+// purgeTrash permanently removes the deleted notes matching expired
+func (fm *FileManager) purgeTrash(expired func(*TrashedNote) bool) (int, error) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.readOnly {
+		return 0, ErrReadOnly
+	}
+
+	var purged []*TrashedNote
+	for id, trashed := range fm.trash {
+		if expired(trashed) {
+			purged = append(purged, trashed)
+			delete(fm.trash, id)
+		}
+	}
+	if len(purged) == 0 {
+		return 0, nil
+	}
+
+	if err := fm.saveTrash(); err != nil {
+		for _, trashed := range purged {
+			fm.trash[trashed.Note.ID] = trashed
+		}
+		return 0, err
+	}
+	return len(purged), nil
+}
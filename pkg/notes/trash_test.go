@@ -0,0 +1,82 @@
+package notes
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFileManager_Trash(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewFileManager(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	note := &Note{Title: "Deleted", Content: "gone for now"}
+	old := &Note{Title: "Old", Content: "long gone"}
+	manager.CreateNote(note)
+	manager.CreateNote(old)
+
+	if err := manager.DeleteNote(note.ID); err != nil {
+		t.Fatalf("DeleteNote() error = %v", err)
+	}
+	manager.DeleteNote(old.ID)
+
+	if _, err := manager.GetNote(note.ID); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("deleted note should not be found, error = %v", err)
+	}
+	if results, _ := manager.SearchNotes(SearchOptions{Query: "gone"}); len(results) != 0 {
+		t.Errorf("deleted notes should not be searched, got %d", len(results))
+	}
+
+	trash, _ := manager.ListTrash()
+	if len(trash) != 2 {
+		t.Fatalf("ListTrash() returned %d notes, want 2", len(trash))
+	}
+
+	// the trash survives a restart
+	manager, err = NewFileManager(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.RestoreNote(note.ID); err != nil {
+		t.Fatalf("RestoreNote() error = %v", err)
+	}
+	if restored, err := manager.GetNote(note.ID); err != nil || restored.Content != "gone for now" {
+		t.Errorf("restored note = %+v, error = %v", restored, err)
+	}
+	if results, _ := manager.SearchNotes(SearchOptions{Query: "gone"}); len(results) != 1 {
+		t.Errorf("restored note should be searchable, got %d", len(results))
+	}
+	if err := manager.RestoreNote(note.ID); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("second RestoreNote() error = %v, want %v", err, ErrNoteNotFound)
+	}
+
+	// only notes older than the retention are purged
+	manager.trash[old.ID].DeletedAt = time.Now().Add(-48 * time.Hour)
+	manager.DeleteNote(note.ID)
+	if purged, err := manager.PurgeTrash(24 * time.Hour); err != nil || purged != 1 {
+		t.Errorf("PurgeTrash() = %d, %v, want 1", purged, err)
+	}
+	trash, _ = manager.ListTrash()
+	if len(trash) != 1 || trash[0].Note.ID != note.ID {
+		t.Errorf("trash after purge = %v", trash)
+	}
+
+	// recreating a deleted note, as undo does, takes it out of the trash
+	if err := manager.CreateNote(trash[0].Note); err != nil {
+		t.Fatalf("CreateNote() error = %v", err)
+	}
+	if trash, _ = manager.ListTrash(); len(trash) != 0 {
+		t.Errorf("recreated note should leave the trash, got %d", len(trash))
+	}
+
+	manager.DeleteNote(note.ID)
+	if removed, err := manager.EmptyTrash(); err != nil || removed != 1 {
+		t.Errorf("EmptyTrash() = %d, %v, want 1", removed, err)
+	}
+	if err := manager.RestoreNote(note.ID); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("RestoreNote() after EmptyTrash() error = %v", err)
+	}
+}
@@ -1,29 +1,69 @@
 package notes
 
 import (
-	"cheat-go/pkg/apps"
 	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
 )
 
+// Note is a personal note. A note with ShortcutKeys is attached to the
+// shortcut with those keys in AppName. The Content of an Encrypted note is
+// only readable through Encrypter.DecryptNote. A note with a Team is shared
+// by that team through sync and is read-only.
 type Note struct {
-	ID         string          `json:"id" yaml:"id"`
-	Title      string          `json:"title" yaml:"title"`
-	Content    string          `json:"content" yaml:"content"`
-	AppName    string          `json:"app_name" yaml:"app_name"`
-	Category   string          `json:"category" yaml:"category"`
-	Tags       []string        `json:"tags" yaml:"tags"`
-	CreatedAt  time.Time       `json:"created_at" yaml:"created_at"`
-	UpdatedAt  time.Time       `json:"updated_at" yaml:"updated_at"`
-	IsFavorite bool            `json:"is_favorite" yaml:"is_favorite"`
-	Shortcuts  []apps.Shortcut `json:"shortcuts,omitempty" yaml:"shortcuts,omitempty"`
+	ID           string          `json:"id" yaml:"id"`
+	Title        string          `json:"title" yaml:"title"`
+	Content      string          `json:"content" yaml:"content"`
+	AppName      string          `json:"app_name" yaml:"app_name"`
+	Category     string          `json:"category" yaml:"category"`
+	Tags         []string        `json:"tags" yaml:"tags"`
+	CreatedAt    time.Time       `json:"created_at" yaml:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at" yaml:"updated_at"`
+	IsFavorite   bool            `json:"is_favorite" yaml:"is_favorite"`
+	Shortcuts    []apps.Shortcut `json:"shortcuts,omitempty" yaml:"shortcuts,omitempty"`
+	ShortcutKeys string          `json:"shortcut_keys,omitempty" yaml:"shortcut_keys,omitempty"`
+	Encrypted    bool            `json:"encrypted,omitempty" yaml:"encrypted,omitempty"`
+	Team         string          `json:"team,omitempty" yaml:"team,omitempty"`
+}
+
+// clone returns a copy of the note that shares no slices with the original
+func (n *Note) clone() *Note {
+	c := *n
+	c.Tags = append([]string(nil), n.Tags...)
+	c.Shortcuts = append([]apps.Shortcut(nil), n.Shortcuts...)
+	return &c
+}
+
+// AddTags adds the tags the note does not have yet
+func (n *Note) AddTags(tags ...string) {
+	for _, tag := range tags {
+		if !containsTag(n.Tags, tag) {
+			n.Tags = append(n.Tags, tag)
+		}
+	}
 }
 
+// containsTag reports whether tags holds tag
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// SortByRelevance ranks query results by how well they match, which is the
+// default order of searches with a query
+const SortByRelevance = "relevance"
+
 type SearchOptions struct {
 	Query         string   `json:"query" yaml:"query"`
 	AppName       string   `json:"app_name" yaml:"app_name"`
 	Category      string   `json:"category" yaml:"category"`
 	Tags          []string `json:"tags" yaml:"tags"`
 	OnlyFavorites bool     `json:"only_favorites" yaml:"only_favorites"`
+	ShortcutKeys  string   `json:"shortcut_keys" yaml:"shortcut_keys"`
 	SortBy        string   `json:"sort_by" yaml:"sort_by"`
 	Limit         int      `json:"limit" yaml:"limit"`
 	Offset        int      `json:"offset" yaml:"offset"`
@@ -43,6 +83,14 @@ type Manager interface {
 	ImportNotes(data []byte, format string) error
 }
 
+// Encrypter is implemented by managers that can encrypt notes at rest
+type Encrypter interface {
+	EncryptNote(id, passphrase string) error
+	RemoveEncryption(id, passphrase string) error
+	DecryptNote(id, passphrase string) (*Note, error)
+	UpdateEncryptedNote(id string, note *Note, passphrase string) error
+}
+
 type SyncStatus struct {
 	LastSync     time.Time  `json:"last_sync" yaml:"last_sync"`
 	TotalNotes   int        `json:"total_notes" yaml:"total_notes"`
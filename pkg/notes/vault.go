@@ -0,0 +1,225 @@
+package notes
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+)
+
+// ImportDirectoryOptions control ImportDirectory
+type ImportDirectoryOptions struct {
+	// DryRun reports what would be imported without saving anything
+	DryRun bool
+	// App is the app of notes whose front matter does not name one
+	App string
+}
+
+// ImportedFile is the outcome of importing one Markdown file
+type ImportedFile struct {
+	Path string `json:"path"`
+	Note *Note  `json:"note,omitempty"`
+	// Reason explains why a file was skipped
+	Reason string `json:"reason,omitempty"`
+}
+
+// ImportReport lists the notes created from a directory and the files that
+// were skipped
+type ImportReport struct {
+	Created []ImportedFile `json:"created"`
+	Skipped []ImportedFile `json:"skipped"`
+}
+
+// frontMatter holds the note fields read from the YAML front matter of a
+// Markdown file. Tags may be a list or a comma or space separated string.
+type frontMatter struct {
+	Title    string      `yaml:"title"`
+	Tags     interface{} `yaml:"tags"`
+	App      string      `yaml:"app"`
+	AppName  string      `yaml:"app_name"`
+	Category string      `yaml:"category"`
+	Favorite bool        `yaml:"favorite"`
+}
+
+// ImportDirectory imports every .md file below dir, such as an Obsidian
+// vault, as a note. Front matter sets the title, tags, app, category and
+// favorite flag; without a title the first heading or the file name is used,
+// and without a category the folder of the file. Hidden folders are skipped,
+// and so are files whose title and content match an existing note.
+func (fm *FileManager) ImportDirectory(dir string, opts ImportDirectoryOptions) (*ImportReport, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), ".md") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	sort.Strings(paths)
+
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.readOnly && !opts.DryRun {
+		return nil, ErrReadOnly
+	}
+
+	if err := fm.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Created: []ImportedFile{}, Skipped: []ImportedFile{}}
+	existing := make(map[string]bool)
+	for _, note := range fm.notes {
+		existing[note.Title+"\x00"+note.Content] = true
+	}
+
+	for _, path := range paths {
+		rel, _ := filepath.Rel(dir, path)
+		note, err := readMarkdownNote(path, rel)
+		if err != nil {
+			report.Skipped = append(report.Skipped, ImportedFile{Path: rel, Reason: err.Error()})
+			continue
+		}
+		if note.AppName == "" {
+			note.AppName = opts.App
+		}
+
+		key := note.Title + "\x00" + note.Content
+		if existing[key] {
+			report.Skipped = append(report.Skipped, ImportedFile{Path: rel, Note: note, Reason: "already imported"})
+			continue
+		}
+		existing[key] = true
+		report.Created = append(report.Created, ImportedFile{Path: rel, Note: note})
+	}
+
+	if opts.DryRun || len(report.Created) == 0 {
+		return report, nil
+	}
+
+	ids := make([]string, 0, len(report.Created))
+	for _, file := range report.Created {
+		note := file.Note
+		note.ID = generateID()
+		for fm.notes[note.ID] != nil {
+			note.ID = generateID()
+		}
+		fm.notes[note.ID] = note
+		fm.index.add(note)
+		ids = append(ids, note.ID)
+	}
+	if err := fm.saveNotes(ids...); err != nil {
+		return nil, err
+	}
+
+	for _, file := range report.Created {
+		fm.record(journal.ActionCreate, journal.SourceImport, file.Note.ID, nil, file.Note)
+	}
+	return report, nil
+}
+
+// readMarkdownNote converts a Markdown file at rel below the imported
+// directory into an unsaved note dated with the file's modification time
+func readMarkdownNote(path, rel string) (*Note, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	var meta frontMatter
+	content := string(data)
+	if lines := strings.Split(content, "\n"); lines[0] == "---" {
+		end := 1
+		for end < len(lines) && strings.TrimRight(lines[end], " ") != "---" {
+			end++
+		}
+		if end == len(lines) {
+			return nil, fmt.Errorf("%w: unterminated front matter", ErrInvalidFormat)
+		}
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "\n")), &meta); err != nil {
+			return nil, fmt.Errorf("%w: front matter: %v", ErrInvalidFormat, err)
+		}
+		content = strings.Join(lines[end+1:], "\n")
+	}
+	content = strings.TrimSpace(content)
+
+	note := &Note{
+		Title:      meta.Title,
+		Content:    content,
+		AppName:    meta.App,
+		Category:   meta.Category,
+		Tags:       frontMatterTags(meta.Tags),
+		IsFavorite: meta.Favorite,
+		CreatedAt:  info.ModTime(),
+		UpdatedAt:  info.ModTime(),
+	}
+	if note.AppName == "" {
+		note.AppName = meta.AppName
+	}
+	if note.Title == "" {
+		note.Title = firstHeading(content)
+	}
+	if note.Title == "" {
+		note.Title = strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+	}
+	if folder := filepath.Dir(rel); note.Category == "" && folder != "." {
+		note.Category = filepath.ToSlash(folder)
+	}
+	return note, nil
+}
+
+// frontMatterTags accepts tags as a YAML list or a comma or space separated
+// string, dropping Obsidian's leading #
+func frontMatterTags(value interface{}) []string {
+	var raw []string
+	switch v := value.(type) {
+	case string:
+		raw = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	case []interface{}:
+		for _, item := range v {
+			raw = append(raw, fmt.Sprint(item))
+		}
+	}
+
+	tags := []string{}
+	for _, tag := range raw {
+		if tag = strings.TrimPrefix(strings.TrimSpace(tag), "#"); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// firstHeading returns the text of the first level one heading
+func firstHeading(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if title, ok := strings.CutPrefix(line, "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
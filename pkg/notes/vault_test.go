@@ -0,0 +1,87 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeVault(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFileManager_ImportDirectory(t *testing.T) {
+	vault := writeVault(t, map[string]string{
+		"vim/motions.md":      "---\ntitle: Motions\ntags: [vim, \"#motion\"]\napp: vim\nfavorite: true\n---\nw jumps a word\n",
+		"tmux.md":             "---\ntags: tmux, panes\n---\n# Tmux panes\n\nprefix % splits\n",
+		"Inbox.md":            "just a thought",
+		"broken.md":           "---\ntitle: [unclosed\n---\nbody",
+		".obsidian/config.md": "ignored",
+		"image.png":           "not a note",
+	})
+
+	manager, err := NewFileManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := manager.ImportDirectory(vault, ImportDirectoryOptions{DryRun: true, App: "general"})
+	if err != nil {
+		t.Fatalf("ImportDirectory() error = %v", err)
+	}
+	if len(report.Created) != 3 || len(report.Skipped) != 1 {
+		t.Fatalf("dry run report = %+v", report)
+	}
+	if notes, _ := manager.ListNotes(); len(notes) != 0 {
+		t.Fatalf("a dry run should not create notes, got %d", len(notes))
+	}
+	if !strings.Contains(report.Skipped[0].Reason, "front matter") {
+		t.Errorf("broken front matter reason = %q", report.Skipped[0].Reason)
+	}
+
+	if _, err := manager.ImportDirectory(vault, ImportDirectoryOptions{App: "general"}); err != nil {
+		t.Fatalf("ImportDirectory() error = %v", err)
+	}
+	byTitle := make(map[string]*Note)
+	notes, _ := manager.ListNotes()
+	for _, note := range notes {
+		byTitle[note.Title] = note
+	}
+
+	motions := byTitle["Motions"]
+	if motions == nil || motions.AppName != "vim" || motions.Category != "vim" || !motions.IsFavorite ||
+		strings.Join(motions.Tags, ",") != "vim,motion" || motions.Content != "w jumps a word" {
+		t.Errorf("note with front matter = %+v", motions)
+	}
+	panes := byTitle["Tmux panes"]
+	if panes == nil || panes.AppName != "general" || strings.Join(panes.Tags, ",") != "tmux,panes" {
+		t.Errorf("note titled by its heading = %+v", panes)
+	}
+	if inbox := byTitle["Inbox"]; inbox == nil || inbox.Content != "just a thought" || inbox.Category != "" {
+		t.Errorf("note titled by its file name = %+v", inbox)
+	}
+	if results, _ := manager.SearchNotes(SearchOptions{Query: "splits"}); len(results) != 1 {
+		t.Errorf("imported notes should be searchable, got %d", len(results))
+	}
+
+	// importing again skips notes that already exist
+	report, err = manager.ImportDirectory(vault, ImportDirectoryOptions{})
+	if err != nil {
+		t.Fatalf("second ImportDirectory() error = %v", err)
+	}
+	if len(report.Created) != 0 || len(report.Skipped) != 4 {
+		t.Errorf("second import report = %+v", report)
+	}
+}
@@ -0,0 +1,318 @@
+package online
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+)
+
+var (
+	ErrNotAuthenticated = errors.New("not authenticated")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrAuthDenied       = errors.New("authorization denied")
+	ErrAuthExpired      = errors.New("device code expired")
+)
+
+// deviceGrantType is the OAuth2 device authorization grant (RFC 8628)
+const deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// tokenRefreshMargin refreshes tokens slightly before they expire
+const tokenRefreshMargin = 30 * time.Second
+
+// Token is an OAuth2 access token with its optional refresh token
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// Valid reports whether the token can be used without refreshing
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(tokenRefreshMargin).Before(t.Expiry)
+}
+
+// tokenResponse is the token endpoint reply, including RFC 8628 errors
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Error        string `json:"error"`
+	Description  string `json:"error_description"`
+}
+
+func (r *tokenResponse) token() *Token {
+	t := &Token{
+		AccessToken:  r.AccessToken,
+		RefreshToken: r.RefreshToken,
+		TokenType:    r.TokenType,
+	}
+	if r.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(r.ExpiresIn) * time.Second)
+	}
+	return t
+}
+
+// TokenStore persists a token as a private JSON file
+type TokenStore struct {
+	path string
+}
+
+// NewTokenStore creates a store saving the token at path
+func NewTokenStore(path string) *TokenStore {
+	return &TokenStore{path: path}
+}
+
+// Path returns the token file
+func (s *TokenStore) Path() string {
+	return s.path
+}
+
+// Load returns the saved token or ErrNotAuthenticated when there is none
+func (s *TokenStore) Load() (*Token, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotAuthenticated
+		}
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode token: %w", err)
+	}
+	return &token, nil
+}
+
+// Save writes token readable only by the current user
+func (s *TokenStore) Save(token *Token) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+	return lock.WriteFileAtomic(s.path, data, 0600)
+}
+
+// Clear removes the saved token
+func (s *TokenStore) Clear() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	os.Remove(s.path + ".lock")
+	return nil
+}
+
+// OAuthConfig describes an OAuth2 provider supporting the device flow
+type OAuthConfig struct {
+	ClientID      string
+	DeviceAuthURL string
+	TokenURL      string
+	Scopes        []string
+}
+
+// DeviceCode is the device authorization response shown to the user
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// OAuth obtains, refreshes and persists OAuth2 tokens
+type OAuth struct {
+	config     OAuthConfig
+	store      *TokenStore
+	httpClient *http.Client
+	token      *Token
+	mu         sync.Mutex
+}
+
+// NewOAuth creates an OAuth helper persisting tokens in store
+func NewOAuth(config OAuthConfig, store *TokenStore) *OAuth {
+	return &OAuth{
+		config:     config,
+		store:      store,
+		httpClient: NewTransportClient(DefaultTransportOptions()),
+	}
+}
+
+// StartDeviceFlow requests a device and user code from the provider
+func (o *OAuth) StartDeviceFlow() (*DeviceCode, error) {
+	form := url.Values{"client_id": {o.config.ClientID}}
+	if len(o.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.config.Scopes, " "))
+	}
+
+	resp, err := o.httpClient.PostForm(o.config.DeviceAuthURL, form)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start device flow: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to start device flow: unexpected status code: %d", resp.StatusCode)
+	}
+
+	var code DeviceCode
+	if err := json.NewDecoder(resp.Body).Decode(&code); err != nil {
+		return nil, fmt.Errorf("failed to decode device code: %w", err)
+	}
+	if code.Interval <= 0 {
+		code.Interval = 5
+	}
+	return &code, nil
+}
+
+// PollDeviceToken waits until the user approves the device code, then saves
+// and returns the issued token
+func (o *OAuth) PollDeviceToken(ctx context.Context, code *DeviceCode) (*Token, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	var deadline <-chan time.Time
+	if code.ExpiresIn > 0 {
+		deadline = time.After(time.Duration(code.ExpiresIn) * time.Second)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, ErrAuthExpired
+		case <-time.After(interval):
+		}
+
+		resp, err := o.requestToken(url.Values{
+			"grant_type":  {deviceGrantType},
+			"device_code": {code.DeviceCode},
+			"client_id":   {o.config.ClientID},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		switch resp.Error {
+		case "":
+			token := resp.token()
+			if err := o.setToken(token); err != nil {
+				return nil, err
+			}
+			return token, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return nil, ErrAuthDenied
+		case "expired_token":
+			return nil, ErrAuthExpired
+		default:
+			return nil, fmt.Errorf("device flow failed: %s %s", resp.Error, resp.Description)
+		}
+	}
+}
+
+// Token returns a valid access token, refreshing an expired one
+func (o *OAuth) Token() (*Token, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token == nil && o.store != nil {
+		token, err := o.store.Load()
+		if err != nil {
+			return nil, err
+		}
+		o.token = token
+	}
+	if o.token == nil {
+		return nil, ErrNotAuthenticated
+	}
+	if o.token.Valid() {
+		return o.token, nil
+	}
+	if o.token.RefreshToken == "" {
+		return nil, fmt.Errorf("%w: token expired", ErrNotAuthenticated)
+	}
+
+	resp, err := o.requestToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {o.token.RefreshToken},
+		"client_id":     {o.config.ClientID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%w: refresh failed: %s", ErrNotAuthenticated, resp.Error)
+	}
+
+	token := resp.token()
+	if token.RefreshToken == "" {
+		token.RefreshToken = o.token.RefreshToken
+	}
+	o.token = token
+	if o.store != nil {
+		if err := o.store.Save(token); err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// Logout forgets the current token
+func (o *OAuth) Logout() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.token = nil
+	if o.store != nil {
+		return o.store.Clear()
+	}
+	return nil
+}
+
+func (o *OAuth) setToken(token *Token) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.token = token
+	if o.store != nil {
+		return o.store.Save(token)
+	}
+	return nil
+}
+
+func (o *OAuth) requestToken(form url.Values) (*tokenResponse, error) {
+	resp, err := o.httpClient.PostForm(o.config.TokenURL, form)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if token.Error == "" && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to request token: unexpected status code: %d", resp.StatusCode)
+	}
+	return &token, nil
+}
@@ -0,0 +1,183 @@
+package online
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenStore(t *testing.T) {
+	store := NewTokenStore(filepath.Join(t.TempDir(), "auth", "token.json"))
+
+	if _, err := store.Load(); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("Load() without token error = %v, want ErrNotAuthenticated", err)
+	}
+
+	if err := store.Save(&Token{AccessToken: "abc", RefreshToken: "r"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	info, err := os.Stat(store.Path())
+	if err != nil {
+		t.Fatalf("token file missing: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("token file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	token, err := store.Load()
+	if err != nil || token.AccessToken != "abc" || token.RefreshToken != "r" {
+		t.Errorf("Load() = %+v, %v", token, err)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, err := store.Load(); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("Load() after Clear error = %v", err)
+	}
+}
+
+func TestOAuth_DeviceFlow(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/device":
+			if r.Form.Get("client_id") != "cli" || r.Form.Get("scope") != "sheets.write" {
+				t.Errorf("unexpected device request %v", r.Form)
+			}
+			w.Write([]byte(`{"device_code":"dev","user_code":"ABCD-1234","verification_uri":"https://example.com/device","expires_in":60,"interval":1}`))
+		case "/token":
+			if r.Form.Get("grant_type") != deviceGrantType || r.Form.Get("device_code") != "dev" {
+				t.Errorf("unexpected token request %v", r.Form)
+			}
+			if atomic.AddInt32(&polls, 1) == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"at","refresh_token":"rt","token_type":"bearer","expires_in":3600}`))
+		}
+	}))
+	defer server.Close()
+
+	store := NewTokenStore(filepath.Join(t.TempDir(), "token.json"))
+	oauth := NewOAuth(OAuthConfig{
+		ClientID:      "cli",
+		DeviceAuthURL: server.URL + "/device",
+		TokenURL:      server.URL + "/token",
+		Scopes:        []string{"sheets.write"},
+	}, store)
+
+	code, err := oauth.StartDeviceFlow()
+	if err != nil {
+		t.Fatalf("StartDeviceFlow() error = %v", err)
+	}
+	if code.UserCode != "ABCD-1234" {
+		t.Errorf("UserCode = %s", code.UserCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	token, err := oauth.PollDeviceToken(ctx, code)
+	if err != nil {
+		t.Fatalf("PollDeviceToken() error = %v", err)
+	}
+	if token.AccessToken != "at" || polls != 2 {
+		t.Errorf("token = %+v after %d polls", token, polls)
+	}
+
+	saved, err := store.Load()
+	if err != nil || saved.AccessToken != "at" {
+		t.Errorf("token not persisted: %+v, %v", saved, err)
+	}
+}
+
+func TestOAuth_DeviceFlowDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"access_denied"}`))
+	}))
+	defer server.Close()
+
+	oauth := NewOAuth(OAuthConfig{ClientID: "cli", TokenURL: server.URL}, nil)
+	_, err := oauth.PollDeviceToken(context.Background(), &DeviceCode{DeviceCode: "dev", Interval: 0})
+	if !errors.Is(err, ErrAuthDenied) {
+		t.Errorf("PollDeviceToken() error = %v, want ErrAuthDenied", err)
+	}
+}
+
+func TestOAuth_Refresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "old-refresh" {
+			t.Errorf("unexpected refresh request %v", r.Form)
+		}
+		w.Write([]byte(`{"access_token":"fresh","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	store := NewTokenStore(filepath.Join(t.TempDir(), "token.json"))
+	store.Save(&Token{AccessToken: "stale", RefreshToken: "old-refresh", Expiry: time.Now().Add(-time.Minute)})
+
+	oauth := NewOAuth(OAuthConfig{ClientID: "cli", TokenURL: server.URL}, store)
+	token, err := oauth.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "fresh" || token.RefreshToken != "old-refresh" {
+		t.Errorf("refreshed token = %+v", token)
+	}
+
+	saved, _ := store.Load()
+	if saved.AccessToken != "fresh" {
+		t.Errorf("refreshed token not saved: %+v", saved)
+	}
+}
+
+func TestHTTPClient_Authorization(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Authorization")
+		if header == "ApiKey revoked" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+	if err := client.RateCheatSheet("sheet", 4); err != nil || header != "" {
+		t.Errorf("anonymous rate: err = %v, header = %q", err, header)
+	}
+
+	client.SetAPIKey("secret")
+	if err := client.SubmitCheatSheet(CheatSheet{Name: "s"}); err != nil || header != "ApiKey secret" {
+		t.Errorf("api key submit: err = %v, header = %q", err, header)
+	}
+
+	client.SetAPIKey("revoked")
+	if err := client.RateCheatSheet("sheet", 4); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("rejected key error = %v, want ErrUnauthorized", err)
+	}
+
+	store := NewTokenStore(filepath.Join(t.TempDir(), "token.json"))
+	client.SetOAuth(NewOAuth(OAuthConfig{}, store))
+	if err := client.RateCheatSheet("sheet", 4); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("oauth without token error = %v, want ErrNotAuthenticated", err)
+	}
+
+	store.Save(&Token{AccessToken: "tok", TokenType: "bearer"})
+	client.SetOAuth(NewOAuth(OAuthConfig{}, store))
+	if err := client.RateCheatSheet("sheet", 4); err != nil || header != "Bearer tok" {
+		t.Errorf("oauth rate: err = %v, header = %q", err, header)
+	}
+}
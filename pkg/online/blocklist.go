@@ -0,0 +1,168 @@
+package online
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+var (
+	ErrBlocked       = errors.New("blocked by the blocklist")
+	ErrInvalidReason = errors.New("invalid report reason")
+)
+
+// ReportReasons are the reasons a sheet can be reported for
+var ReportReasons = []string{"spam", "offensive", "incorrect", "malicious"}
+
+// IsReportReason reports whether reason is one of ReportReasons
+func IsReportReason(reason string) bool {
+	return slices.Contains(ReportReasons, reason)
+}
+
+// Report is a sheet flagged to the moderators of the community
+type Report struct {
+	SheetID string `json:"sheet_id"`
+	Reason  string `json:"reason"`
+}
+
+// Blocklist is online content the user does not want to see. Entries
+// match regardless of case: authors by name, repositories by URL or name
+// and tags by name.
+type Blocklist struct {
+	Authors      []string `json:"authors,omitempty" yaml:"authors,omitempty"`
+	Repositories []string `json:"repositories,omitempty" yaml:"repositories,omitempty"`
+	Tags         []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// listed reports whether value is one of entries
+func listed(entries []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	return slices.ContainsFunc(entries, func(entry string) bool {
+		return strings.EqualFold(strings.TrimSpace(entry), value)
+	})
+}
+
+// BlocksRepository reports whether the repository or its author is blocked
+func (b Blocklist) BlocksRepository(repo Repository) bool {
+	return listed(b.Repositories, repo.URL) || listed(b.Repositories, repo.Name) || listed(b.Authors, repo.Author)
+}
+
+// BlocksSheet reports whether the author of the sheet, its repository,
+// matched by URL, or one of its tags is blocked
+func (b Blocklist) BlocksSheet(sheet CheatSheet) bool {
+	if listed(b.Repositories, sheet.Repository) || listed(b.Authors, sheet.Author) {
+		return true
+	}
+	return slices.ContainsFunc(sheet.Tags, func(tag string) bool {
+		return listed(b.Tags, tag)
+	})
+}
+
+// BlockingClient leaves the content of a blocklist out of the results of
+// another client, and refuses to fetch it
+type BlockingClient struct {
+	client    Client
+	blocklist Blocklist
+	mu        sync.RWMutex
+}
+
+// NewBlockingClient filters the results of client with blocklist
+func NewBlockingClient(client Client, blocklist Blocklist) *BlockingClient {
+	return &BlockingClient{client: client, blocklist: blocklist}
+}
+
+// SetBlocklist replaces the blocklist applied from now on
+func (c *BlockingClient) SetBlocklist(blocklist Blocklist) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blocklist = blocklist
+}
+
+// Blocklist returns the blocklist applied
+func (c *BlockingClient) Blocklist() Blocklist {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.blocklist
+}
+
+// WithContext returns a copy of the client filtering the client it wraps
+// bound to ctx
+func (c *BlockingClient) WithContext(ctx context.Context) Client {
+	return NewBlockingClient(WithContext(ctx, c.client), c.Blocklist())
+}
+
+func (c *BlockingClient) GetRepositories() ([]Repository, error) {
+	repos, err := c.client.GetRepositories()
+	if err != nil {
+		return nil, err
+	}
+	blocklist := c.Blocklist()
+	// the slice may be the client's own
+	return slices.DeleteFunc(slices.Clone(repos), blocklist.BlocksRepository), nil
+}
+
+func (c *BlockingClient) SearchCheatSheets(opts SearchOptions) ([]CheatSheet, error) {
+	blocklist := c.Blocklist()
+	if opts.Repository != "" && listed(blocklist.Repositories, opts.Repository) {
+		return []CheatSheet{}, nil
+	}
+	sheets, err := c.client.SearchCheatSheets(opts)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(slices.Clone(sheets), blocklist.BlocksSheet), nil
+}
+
+func (c *BlockingClient) GetCheatSheet(id string) (*CheatSheet, error) {
+	sheet, err := c.client.GetCheatSheet(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Blocklist().BlocksSheet(*sheet) {
+		return nil, fmt.Errorf("cheat sheet %s: %w", id, ErrBlocked)
+	}
+	return sheet, nil
+}
+
+func (c *BlockingClient) DownloadCheatSheet(id string) (*apps.App, error) {
+	if _, err := c.GetCheatSheet(id); err != nil {
+		return nil, err
+	}
+	return c.client.DownloadCheatSheet(id)
+}
+
+// DownloadSigned downloads the sheet with its signature when the client
+// filtered serves signatures; otherwise the sheet comes unsigned
+func (c *BlockingClient) DownloadSigned(id string) (*SignedSheet, error) {
+	downloader, ok := c.client.(SignedDownloader)
+	if !ok {
+		app, err := c.DownloadCheatSheet(id)
+		if err != nil {
+			return nil, err
+		}
+		return &SignedSheet{App: app}, nil
+	}
+	if _, err := c.GetCheatSheet(id); err != nil {
+		return nil, err
+	}
+	return downloader.DownloadSigned(id)
+}
+
+func (c *BlockingClient) SubmitCheatSheet(sheet CheatSheet) error {
+	return c.client.SubmitCheatSheet(sheet)
+}
+
+func (c *BlockingClient) RateCheatSheet(id string, rating float64) error {
+	return c.client.RateCheatSheet(id, rating)
+}
+
+func (c *BlockingClient) ReportCheatSheet(id, reason string) error {
+	return c.client.ReportCheatSheet(id, reason)
+}
@@ -0,0 +1,109 @@
+package online
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+)
+
+func TestBlocklist(t *testing.T) {
+	blocklist := Blocklist{
+		Authors:      []string{"Spammer"},
+		Repositories: []string{"https://github.com/bad/sheets", "Awesome Cheat Sheets"},
+		Tags:         []string{"NSFW"},
+	}
+
+	repos := []struct {
+		repo Repository
+		want bool
+	}{
+		{Repository{URL: "https://github.com/bad/sheets"}, true},
+		{Repository{URL: "https://github.com/awesome/cheatsheets", Name: "awesome cheat sheets"}, true},
+		{Repository{URL: "https://github.com/spammer/x", Author: "spammer"}, true},
+		{Repository{URL: "https://github.com/cheat-go/community", Name: "Official", Author: "cheat-go"}, false},
+	}
+	for _, tc := range repos {
+		if got := blocklist.BlocksRepository(tc.repo); got != tc.want {
+			t.Errorf("BlocksRepository(%+v) = %v, want %v", tc.repo, got, tc.want)
+		}
+	}
+
+	sheets := []struct {
+		sheet CheatSheet
+		want  bool
+	}{
+		{CheatSheet{ID: "a", Repository: "https://github.com/bad/sheets"}, true},
+		{CheatSheet{ID: "b", Author: "SPAMMER"}, true},
+		{CheatSheet{ID: "c", Tags: []string{"vim", "nsfw"}}, true},
+		{CheatSheet{ID: "d", Tags: []string{"vim"}, Author: "cheat-go"}, false},
+	}
+	for _, tc := range sheets {
+		if got := blocklist.BlocksSheet(tc.sheet); got != tc.want {
+			t.Errorf("BlocksSheet(%+v) = %v, want %v", tc.sheet, got, tc.want)
+		}
+	}
+
+	if (Blocklist{}).BlocksSheet(CheatSheet{}) || (Blocklist{}).BlocksRepository(Repository{}) {
+		t.Error("an empty blocklist should block nothing")
+	}
+}
+
+func TestBlockingClient(t *testing.T) {
+	mock := NewMockClient()
+	client := NewBlockingClient(mock, Blocklist{
+		Repositories: []string{"https://github.com/awesome/cheatsheets"},
+		Tags:         []string{"git"},
+	})
+
+	repos, err := client.GetRepositories()
+	if err != nil || len(repos) != 1 || repos[0].URL != "https://github.com/cheat-go/community" {
+		t.Errorf("GetRepositories() = %+v, %v; want the community repository only", repos, err)
+	}
+	if all, _ := mock.GetRepositories(); len(all) != 2 {
+		t.Errorf("filtering should leave the client's repositories alone, got %+v", all)
+	}
+
+	sheets, err := client.SearchCheatSheets(SearchOptions{})
+	if err != nil || len(sheets) != 1 || sheets[0].ID != "vim-advanced" {
+		t.Errorf("SearchCheatSheets() = %+v, %v; want vim-advanced only", sheets, err)
+	}
+	if sheets, _ := client.SearchCheatSheets(SearchOptions{Repository: "https://github.com/awesome/cheatsheets"}); len(sheets) != 0 {
+		t.Errorf("a blocked repository should have no sheets, got %+v", sheets)
+	}
+
+	if _, err := client.GetCheatSheet("git-workflow"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("GetCheatSheet() of a blocked sheet error = %v, want %v", err, ErrBlocked)
+	}
+	if _, err := client.DownloadCheatSheet("git-workflow"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("DownloadCheatSheet() of a blocked sheet error = %v, want %v", err, ErrBlocked)
+	}
+	if app, err := client.DownloadCheatSheet("vim-advanced"); err != nil || app.Name != "vim-advanced" {
+		t.Errorf("DownloadCheatSheet() = %+v, %v", app, err)
+	}
+
+	// sheets of clients without signatures stay unsigned
+	public, _, _ := signature.GenerateKey()
+	verifier, _ := signature.NewVerifier([]string{public.String()})
+	if _, err := DownloadVerified(client, verifier, "vim-advanced"); !errors.Is(err, signature.ErrUnsigned) {
+		t.Errorf("DownloadVerified() error = %v, want %v", err, signature.ErrUnsigned)
+	}
+	if _, err := DownloadVerified(client, verifier, "git-workflow"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("DownloadVerified() of a blocked sheet error = %v, want %v", err, ErrBlocked)
+	}
+
+	client.SetBlocklist(Blocklist{})
+	if sheets, _ := client.SearchCheatSheets(SearchOptions{}); len(sheets) != 2 {
+		t.Errorf("an emptied blocklist should show every sheet, got %+v", sheets)
+	}
+
+	if err := client.ReportCheatSheet("git-workflow", "spam"); err != nil {
+		t.Fatalf("ReportCheatSheet() error = %v", err)
+	}
+	if reports := mock.Reports(); len(reports) != 1 || reports[0] != (Report{SheetID: "git-workflow", Reason: "spam"}) {
+		t.Errorf("Reports() = %+v", reports)
+	}
+	if err := client.ReportCheatSheet("git-workflow", "boring"); !errors.Is(err, ErrInvalidReason) {
+		t.Errorf("ReportCheatSheet() with an unknown reason error = %v, want %v", err, ErrInvalidReason)
+	}
+}
@@ -0,0 +1,265 @@
+package online
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+)
+
+var (
+	ErrInvalidBundle = errors.New("invalid bundle")
+)
+
+const (
+	// BundleExt ends the file name of bundles
+	BundleExt = ".tar.gz"
+
+	bundleManifest = "bundle.json"
+	bundleVersion  = 1
+	// maxBundleFile bounds the files read from a bundle
+	maxBundleFile = 10 << 20
+)
+
+// Bundle is an offline archive of online sheets, carried to machines that
+// cannot reach their repositories
+type Bundle struct {
+	CreatedAt time.Time
+	Sheets    []BundledSheet
+}
+
+// BundledSheet is a sheet of a bundle: what is known of it online, the
+// file it was published as and its signature, if it has one
+type BundledSheet struct {
+	Sheet     CheatSheet
+	Data      []byte
+	Signature []byte
+}
+
+// bundleManifestFile is the first entry of a bundle archive
+type bundleManifestFile struct {
+	Version   int           `json:"version"`
+	CreatedAt time.Time     `json:"created_at"`
+	Sheets    []bundleEntry `json:"sheets"`
+}
+
+// bundleEntry describes a sheet of the manifest and names its files
+type bundleEntry struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Repository  string    `json:"repository"`
+	Author      string    `json:"author,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Version     string    `json:"version,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	File        string    `json:"file"`
+	Signature   string    `json:"signature,omitempty"`
+}
+
+// BundleSheet downloads sheet for a bundle, with its signature when the
+// client serves signatures. Sheets of other clients are bundled as the
+// YAML of their app.
+func BundleSheet(client Client, sheet CheatSheet) (*BundledSheet, error) {
+	signed := &SignedSheet{}
+	if downloader, ok := client.(SignedDownloader); ok {
+		var err error
+		if signed, err = downloader.DownloadSigned(sheet.ID); err != nil {
+			return nil, err
+		}
+	} else {
+		app, err := client.DownloadCheatSheet(sheet.ID)
+		if err != nil {
+			return nil, err
+		}
+		signed.App = app
+	}
+
+	data := signed.Data
+	if data == nil {
+		var err error
+		if data, err = yaml.Marshal(signed.App); err != nil {
+			return nil, fmt.Errorf("failed to encode cheat sheet %s: %w", sheet.ID, err)
+		}
+	}
+	// the file holds the app
+	sheet.App = apps.App{}
+	return &BundledSheet{Sheet: sheet, Data: data, Signature: signed.Signature}, nil
+}
+
+// Open parses the app of the sheet, checking its signature against the
+// trusted keys of verifier unless it has none. As with DownloadVerified,
+// a sheet that is unsigned or signed by an unknown key comes with an error
+// that signature.Overridable reports.
+func (b BundledSheet) Open(verifier *signature.Verifier) (*apps.App, error) {
+	var verifyErr error
+	if verifier.Enabled() {
+		if _, err := verifier.Verify(b.Data, b.Signature); err != nil {
+			if !signature.Overridable(err) {
+				return nil, fmt.Errorf("cheat sheet %s: %w", b.Sheet.ID, err)
+			}
+			verifyErr = err
+		}
+	}
+
+	var app apps.App
+	if err := yaml.Unmarshal(b.Data, &app); err != nil {
+		return nil, fmt.Errorf("failed to parse cheat sheet %s: %w", b.Sheet.ID, err)
+	}
+	if app.Name == "" {
+		app.Name = b.Sheet.Name
+	}
+	return &app, verifyErr
+}
+
+// WriteBundle writes bundle to w as a tar.gz archive: a manifest followed
+// by the file of every sheet and its signature
+func WriteBundle(w io.Writer, bundle *Bundle) error {
+	manifest := bundleManifestFile{Version: bundleVersion, CreatedAt: bundle.CreatedAt}
+	type file struct {
+		name string
+		data []byte
+	}
+	var files []file
+	for i, bundled := range bundle.Sheets {
+		sheet := bundled.Sheet
+		entry := bundleEntry{
+			ID:          sheet.ID,
+			Name:        sheet.Name,
+			Description: sheet.Description,
+			Repository:  sheet.Repository,
+			Author:      sheet.Author,
+			Tags:        sheet.Tags,
+			Version:     sheet.Version,
+			UpdatedAt:   sheet.UpdatedAt,
+			File:        fmt.Sprintf("sheets/%03d-%s.yaml", i+1, bundleFileName(sheet.Name)),
+		}
+		files = append(files, file{entry.File, bundled.Data})
+		if bundled.Signature != nil {
+			entry.Signature = entry.File + signature.Suffix
+			files = append(files, file{entry.Signature, bundled.Signature})
+		}
+		manifest.Sheets = append(manifest.Sheets, entry)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	files = append([]file{{bundleManifest, data}}, files...)
+	for _, f := range files {
+		header := &tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.data)), ModTime: bundle.CreatedAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// bundleFileName keeps the letters, digits, dashes and dots of name for the
+// name of its file in a bundle
+func bundleFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r == ' ', r == '_', r == '/':
+			return '-'
+		}
+		return -1
+	}, name)
+	if safe = strings.Trim(safe, "-."); safe == "" {
+		return "sheet"
+	}
+	return safe
+}
+
+// ReadBundle reads a bundle written by WriteBundle
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != bundleManifest {
+		return nil, fmt.Errorf("%w: missing manifest", ErrInvalidBundle)
+	}
+	var manifest bundleManifestFile
+	if err := json.NewDecoder(io.LimitReader(tr, maxBundleFile)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: manifest: %v", ErrInvalidBundle, err)
+	}
+	if manifest.Version > bundleVersion {
+		return nil, fmt.Errorf("%w: version %d is newer than this cheat-go supports", ErrInvalidBundle, manifest.Version)
+	}
+
+	files := map[string][]byte{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > maxBundleFile {
+			return nil, fmt.Errorf("%w: %s is too large", ErrInvalidBundle, header.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+		}
+		files[header.Name] = data
+	}
+
+	bundle := &Bundle{CreatedAt: manifest.CreatedAt}
+	for _, entry := range manifest.Sheets {
+		data, ok := files[entry.File]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing %s of %s", ErrInvalidBundle, entry.File, entry.ID)
+		}
+		bundled := BundledSheet{
+			Sheet: CheatSheet{
+				ID:          entry.ID,
+				Name:        entry.Name,
+				Description: entry.Description,
+				Repository:  entry.Repository,
+				Author:      entry.Author,
+				Tags:        entry.Tags,
+				Version:     entry.Version,
+				UpdatedAt:   entry.UpdatedAt,
+			},
+			Data: data,
+		}
+		if entry.Signature != "" {
+			if bundled.Signature, ok = files[entry.Signature]; !ok {
+				return nil, fmt.Errorf("%w: missing %s of %s", ErrInvalidBundle, entry.Signature, entry.ID)
+			}
+		}
+		bundle.Sheets = append(bundle.Sheets, bundled)
+	}
+	return bundle, nil
+}
@@ -0,0 +1,90 @@
+package online
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+)
+
+func TestBundle(t *testing.T) {
+	public, secret, err := signature.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	mock := NewMockClient()
+	sheets, _ := mock.SearchCheatSheets(SearchOptions{})
+	unsigned, err := BundleSheet(mock, sheets[0])
+	if err != nil {
+		t.Fatalf("BundleSheet() error = %v", err)
+	}
+	if unsigned.Signature != nil || len(unsigned.Data) == 0 || unsigned.Sheet.App.Name != "" {
+		t.Errorf("BundleSheet() from the mock client = %+v, want the YAML of the app without a signature", unsigned)
+	}
+
+	signed := BundledSheet{
+		Sheet:     CheatSheet{ID: "acme/sheets/apps/vim.yaml", Name: "vim", Repository: "https://github.com/acme/sheets", Version: "abc123"},
+		Data:      []byte(githubVimYAML),
+		Signature: secret.Sign([]byte(githubVimYAML), "file:vim.yaml"),
+	}
+	created := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, &Bundle{CreatedAt: created, Sheets: []BundledSheet{*unsigned, signed}}); err != nil {
+		t.Fatalf("WriteBundle() error = %v", err)
+	}
+	bundle, err := ReadBundle(&buf)
+	if err != nil {
+		t.Fatalf("ReadBundle() error = %v", err)
+	}
+	if !bundle.CreatedAt.Equal(created) || len(bundle.Sheets) != 2 {
+		t.Fatalf("ReadBundle() = %+v", bundle)
+	}
+	got := bundle.Sheets[1]
+	if got.Sheet.ID != signed.Sheet.ID || got.Sheet.Version != "abc123" || !bytes.Equal(got.Data, signed.Data) || !bytes.Equal(got.Signature, signed.Signature) {
+		t.Errorf("the signed sheet read back as %+v", got)
+	}
+
+	verifier, _ := signature.NewVerifier([]string{public.String()})
+	if app, err := got.Open(verifier); err != nil || app.Name != "vim" || len(app.Shortcuts) != 1 {
+		t.Errorf("Open() of the signed sheet = %+v, %v", app, err)
+	}
+	if app, err := bundle.Sheets[0].Open(verifier); !errors.Is(err, signature.ErrUnsigned) || app == nil || app.Name != sheets[0].App.Name {
+		t.Errorf("Open() of the unsigned sheet = %+v, %v; want the app with %v", app, err, signature.ErrUnsigned)
+	}
+	if app, err := bundle.Sheets[0].Open(nil); err != nil || app == nil {
+		t.Errorf("Open() without trusted keys = %+v, %v", app, err)
+	}
+	got.Data = append(got.Data, "  - keys: x\n    description: delete\n"...)
+	if app, err := got.Open(verifier); !errors.Is(err, signature.ErrInvalidSignature) || app != nil {
+		t.Errorf("Open() of an altered sheet = %+v, %v; want %v", app, err, signature.ErrInvalidSignature)
+	}
+}
+
+func TestReadBundle_Invalid(t *testing.T) {
+	if _, err := ReadBundle(bytes.NewReader([]byte("not an archive"))); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("ReadBundle() of garbage error = %v, want %v", err, ErrInvalidBundle)
+	}
+
+	var buf bytes.Buffer
+	WriteBundle(&buf, &Bundle{})
+	if bundle, err := ReadBundle(&buf); err != nil || len(bundle.Sheets) != 0 {
+		t.Errorf("ReadBundle() of an empty bundle = %+v, %v", bundle, err)
+	}
+}
+
+func TestBundleFileName(t *testing.T) {
+	for name, want := range map[string]string{
+		"Vim Advanced": "Vim-Advanced",
+		"../../etc":    "etc",
+		"git_workflow": "git-workflow",
+		"日本":           "sheet",
+	} {
+		if got := bundleFileName(name); got != want {
+			t.Errorf("bundleFileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
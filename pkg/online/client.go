@@ -2,7 +2,7 @@ package online
 
 import (
 	"bytes"
-	"cheat-go/pkg/apps"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,64 +11,203 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/cache"
 )
 
 type HTTPClient struct {
 	baseURL    string
 	httpClient *http.Client
-	cache      *cache
-	mu         sync.RWMutex
+	cache      cache.Cache
+	ttl        time.Duration
+	apiKey     string
+	oauth      *OAuth
+	// ctx bounds the requests of the copies WithContext makes
+	ctx context.Context
 }
 
-type cache struct {
-	repositories []Repository
-	cheatSheets  map[string]*CheatSheet
-	lastUpdated  time.Time
-	ttl          time.Duration
+const (
+	// defaultResponseTTL is how long a cached response is used without
+	// asking the server whether it changed
+	defaultResponseTTL = 15 * time.Minute
+	// responseRetention is how long responses are kept for revalidation
+	responseRetention = 7 * 24 * time.Hour
+)
+
+// cachedResponse is a response body with the ETag and Last-Modified headers
+// it was served with
+type cachedResponse struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
 }
 
 func NewHTTPClient(baseURL string) *HTTPClient {
 	return &HTTPClient{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		cache: &cache{
-			cheatSheets: make(map[string]*CheatSheet),
-			ttl:         15 * time.Minute,
-		},
+		baseURL:    baseURL,
+		httpClient: NewTransportClient(DefaultTransportOptions()),
+		cache:      cache.NewLRUCache(4*1024*1024, 500),
+		ttl:        defaultResponseTTL,
 	}
 }
 
-func (c *HTTPClient) GetRepositories() ([]Repository, error) {
-	c.mu.RLock()
-	if time.Since(c.cache.lastUpdated) < c.cache.ttl && len(c.cache.repositories) > 0 {
-		repos := c.cache.repositories
-		c.mu.RUnlock()
-		return repos, nil
+// SetCache stores responses in c, such as a MultiLevelCache so they
+// survive restarts
+func (c *HTTPClient) SetCache(responses cache.Cache) {
+	c.cache = responses
+}
+
+// SetTransportOptions replaces the retry, rate limit and timeout settings
+func (c *HTTPClient) SetTransportOptions(opts TransportOptions) {
+	c.httpClient = NewTransportClient(opts)
+}
+
+// SetAPIKey authenticates submit and rate requests with a static API key
+func (c *HTTPClient) SetAPIKey(key string) {
+	c.apiKey = key
+}
+
+// SetOAuth authenticates submit and rate requests with OAuth2 tokens,
+// taking precedence over an API key
+func (c *HTTPClient) SetOAuth(oauth *OAuth) {
+	c.oauth = oauth
+}
+
+// WithContext returns a copy of the client sending its requests with ctx
+func (c *HTTPClient) WithContext(ctx context.Context) Client {
+	bound := *c
+	bound.ctx = ctx
+	return &bound
+}
+
+// authorize adds the Authorization header for the configured credentials.
+// Requests are sent anonymously when no credentials are configured.
+func (c *HTTPClient) authorize(req *http.Request) error {
+	if c.oauth != nil {
+		token, err := c.oauth.Token()
+		if err != nil {
+			return err
+		}
+		tokenType := token.TokenType
+		if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+			tokenType = "Bearer"
+		}
+		req.Header.Set("Authorization", tokenType+" "+token.AccessToken)
+		return nil
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+c.apiKey)
+	}
+	return nil
+}
+
+// fetch returns the body of url. Cached copies younger than maxAge are used
+// as is; older ones are revalidated with If-None-Match and
+// If-Modified-Since so an unchanged resource costs a 304 without a body.
+// The returned status is that of the response the body belongs to.
+func (c *HTTPClient) fetch(url string, maxAge time.Duration) ([]byte, int, error) {
+	cached := c.lookup(url)
+	if cached != nil && maxAge > 0 && time.Since(cached.FetchedAt) < maxAge {
+		return cached.Body, http.StatusOK, nil
 	}
-	c.mu.RUnlock()
 
-	resp, err := c.httpClient.Get(c.baseURL + "/api/repositories")
+	req, err := http.NewRequestWithContext(orBackground(c.ctx), "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repositories: %w", err)
+		return nil, 0, err
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		refreshed := *cached
+		refreshed.FetchedAt = time.Now()
+		c.store(url, &refreshed)
+		return cached.Body, http.StatusOK, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, resp.StatusCode, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entry := &cachedResponse{
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now(),
+	}
+	// responses without validators are only worth keeping while fresh
+	if maxAge > 0 || entry.ETag != "" || entry.LastModified != "" {
+		c.store(url, entry)
+	}
+	return body, http.StatusOK, nil
+}
+
+// lookup returns the cached response for url. Values read back from a file
+// cache are generic JSON and are decoded again.
+func (c *HTTPClient) lookup(url string) *cachedResponse {
+	value, err := c.cache.Get(cache.Key(cache.NamespaceOnline, url))
+	if err != nil {
+		return nil
+	}
+	if cached, ok := value.(*cachedResponse); ok {
+		return cached
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil
+	}
+	return &cached
+}
+
+func (c *HTTPClient) store(url string, entry *cachedResponse) {
+	c.cache.Set(cache.Key(cache.NamespaceOnline, url), entry, responseRetention)
+}
+
+func (c *HTTPClient) invalidate(url string) {
+	c.cache.Delete(cache.Key(cache.NamespaceOnline, url))
+}
+
+func (c *HTTPClient) sheetURL(id string) string {
+	return fmt.Sprintf("%s/api/cheatsheets/%s", c.baseURL, id)
+}
+
+func (c *HTTPClient) GetRepositories() ([]Repository, error) {
+	body, status, err := c.fetch(c.baseURL+"/api/repositories", c.ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", status)
 	}
 
 	var repos []Repository
-	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
+	if err := json.Unmarshal(body, &repos); err != nil {
 		return nil, fmt.Errorf("failed to decode repositories: %w", err)
 	}
 
-	c.mu.Lock()
-	c.cache.repositories = repos
-	c.cache.lastUpdated = time.Now()
-	c.mu.Unlock()
-
 	return repos, nil
 }
 
@@ -97,18 +236,16 @@ func (c *HTTPClient) SearchCheatSheets(opts SearchOptions) ([]CheatSheet, error)
 	}
 
 	url := fmt.Sprintf("%s/api/cheatsheets?%s", c.baseURL, params.Encode())
-	resp, err := c.httpClient.Get(url)
+	body, status, err := c.fetch(url, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search cheat sheets: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", status)
 	}
 
 	var sheets []CheatSheet
-	if err := json.NewDecoder(resp.Body).Decode(&sheets); err != nil {
+	if err := json.Unmarshal(body, &sheets); err != nil {
 		return nil, fmt.Errorf("failed to decode cheat sheets: %w", err)
 	}
 
@@ -116,35 +253,22 @@ func (c *HTTPClient) SearchCheatSheets(opts SearchOptions) ([]CheatSheet, error)
 }
 
 func (c *HTTPClient) GetCheatSheet(id string) (*CheatSheet, error) {
-	c.mu.RLock()
-	if cached, exists := c.cache.cheatSheets[id]; exists {
-		c.mu.RUnlock()
-		return cached, nil
-	}
-	c.mu.RUnlock()
-
-	resp, err := c.httpClient.Get(fmt.Sprintf("%s/api/cheatsheets/%s", c.baseURL, id))
+	body, status, err := c.fetch(c.sheetURL(id), c.ttl)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch cheat sheet: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
+	if status == http.StatusNotFound {
 		return nil, fmt.Errorf("cheat sheet not found")
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", status)
 	}
 
 	var sheet CheatSheet
-	if err := json.NewDecoder(resp.Body).Decode(&sheet); err != nil {
+	if err := json.Unmarshal(body, &sheet); err != nil {
 		return nil, fmt.Errorf("failed to decode cheat sheet: %w", err)
 	}
 
-	c.mu.Lock()
-	c.cache.cheatSheets[id] = &sheet
-	c.mu.Unlock()
-
 	return &sheet, nil
 }
 
@@ -163,16 +287,25 @@ func (c *HTTPClient) SubmitCheatSheet(sheet CheatSheet) error {
 		return fmt.Errorf("failed to marshal cheat sheet: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
-		c.baseURL+"/api/cheatsheets",
-		"application/json",
-		bytes.NewReader(data),
-	)
+	req, err := http.NewRequestWithContext(orBackground(c.ctx), "POST", c.baseURL+"/api/cheatsheets", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.authorize(req); err != nil {
+		return fmt.Errorf("failed to submit cheat sheet: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to submit cheat sheet: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("failed to submit cheat sheet: %w", ErrUnauthorized)
+	}
+
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("failed to submit cheat sheet: %s", body)
@@ -188,7 +321,8 @@ func (c *HTTPClient) RateCheatSheet(id string, rating float64) error {
 
 	data, _ := json.Marshal(map[string]float64{"rating": rating})
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		orBackground(c.ctx),
 		"POST",
 		fmt.Sprintf("%s/api/cheatsheets/%s/rate", c.baseURL, id),
 		bytes.NewReader(data),
@@ -197,6 +331,9 @@ func (c *HTTPClient) RateCheatSheet(id string, rating float64) error {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if err := c.authorize(req); err != nil {
+		return fmt.Errorf("failed to rate cheat sheet: %w", err)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -204,12 +341,55 @@ func (c *HTTPClient) RateCheatSheet(id string, rating float64) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("failed to rate cheat sheet: %w", ErrUnauthorized)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("failed to rate cheat sheet: %s", body)
 	}
 
-	delete(c.cache.cheatSheets, id)
+	c.invalidate(c.sheetURL(id))
+
+	return nil
+}
+
+func (c *HTTPClient) ReportCheatSheet(id, reason string) error {
+	if !IsReportReason(reason) {
+		return fmt.Errorf("%w: %q", ErrInvalidReason, reason)
+	}
+
+	data, _ := json.Marshal(map[string]string{"reason": reason})
+
+	req, err := http.NewRequestWithContext(
+		orBackground(c.ctx),
+		"POST",
+		fmt.Sprintf("%s/api/cheatsheets/%s/report", c.baseURL, id),
+		bytes.NewReader(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.authorize(req); err != nil {
+		return fmt.Errorf("failed to report cheat sheet: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to report cheat sheet: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("failed to report cheat sheet: %w", ErrUnauthorized)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to report cheat sheet: %s", body)
+	}
 
 	return nil
 }
@@ -217,6 +397,7 @@ func (c *HTTPClient) RateCheatSheet(id string, rating float64) error {
 type MockClient struct {
 	repositories []Repository
 	cheatSheets  []CheatSheet
+	reports      []Report
 	mu           sync.RWMutex
 }
 
@@ -249,6 +430,9 @@ func defaultRepositories() []Repository {
 }
 
 func defaultCheatSheets() []CheatSheet {
+	// days rather than instants, so the revisions of the sheets hold for a
+	// day
+	today := time.Now().Truncate(24 * time.Hour)
 	return []CheatSheet{
 		{
 			ID:          "vim-advanced",
@@ -257,9 +441,19 @@ func defaultCheatSheets() []CheatSheet {
 			Repository:  "https://github.com/cheat-go/community",
 			Downloads:   5420,
 			Rating:      4.8,
-			CreatedAt:   time.Now().Add(-30 * 24 * time.Hour),
-			UpdatedAt:   time.Now().Add(-2 * 24 * time.Hour),
+			CreatedAt:   today.Add(-30 * 24 * time.Hour),
+			UpdatedAt:   today.Add(-2 * 24 * time.Hour),
 			Tags:        []string{"vim", "editor", "advanced"},
+			Author:      "cheat-go",
+			App: apps.App{
+				Name:        "vim-advanced",
+				Description: "Advanced Vim shortcuts and commands",
+				Shortcuts: []apps.Shortcut{
+					{Keys: "ci\"", Description: "Change inside quotes", Category: "editing"},
+					{Keys: "qa", Description: "Record macro into register a", Category: "macros"},
+					{Keys: "@a", Description: "Replay macro a", Category: "macros"},
+				},
+			},
 		},
 		{
 			ID:          "git-workflow",
@@ -268,9 +462,18 @@ func defaultCheatSheets() []CheatSheet {
 			Repository:  "https://github.com/cheat-go/community",
 			Downloads:   3210,
 			Rating:      4.6,
-			CreatedAt:   time.Now().Add(-45 * 24 * time.Hour),
-			UpdatedAt:   time.Now().Add(-5 * 24 * time.Hour),
+			CreatedAt:   today.Add(-45 * 24 * time.Hour),
+			UpdatedAt:   today.Add(-5 * 24 * time.Hour),
 			Tags:        []string{"git", "vcs", "workflow"},
+			Author:      "cheat-go",
+			App: apps.App{
+				Name:        "git-workflow",
+				Description: "Complete Git workflow commands",
+				Shortcuts: []apps.Shortcut{
+					{Keys: "git switch -c", Description: "Create and switch branch", Category: "branches"},
+					{Keys: "git rebase -i", Description: "Interactive rebase", Category: "history"},
+				},
+			},
 		},
 	}
 }
@@ -349,3 +552,27 @@ func (m *MockClient) RateCheatSheet(id string, rating float64) error {
 	}
 	return fmt.Errorf("cheat sheet not found")
 }
+
+func (m *MockClient) ReportCheatSheet(id, reason string) error {
+	if !IsReportReason(reason) {
+		return fmt.Errorf("%w: %q", ErrInvalidReason, reason)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, sheet := range m.cheatSheets {
+		if sheet.ID == id {
+			m.reports = append(m.reports, Report{SheetID: id, Reason: reason})
+			return nil
+		}
+	}
+	return fmt.Errorf("cheat sheet not found")
+}
+
+// Reports returns the reports the mock received, oldest first
+func (m *MockClient) Reports() []Report {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Report(nil), m.reports...)
+}
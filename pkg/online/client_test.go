@@ -1,12 +1,16 @@
 package online
 
 import (
-	"cheat-go/pkg/apps"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/cache"
 )
 
 func TestHTTPClient_GetRepositories(t *testing.T) {
@@ -45,6 +49,27 @@ func TestHTTPClient_GetRepositories(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_WithContext(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode([]Repository{})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := NewHTTPClient(server.URL)
+	for _, bound := range []Client{WithContext(ctx, client), WithContext(ctx, NewBlockingClient(client, Blocklist{}))} {
+		if _, err := bound.GetRepositories(); !errors.Is(err, context.Canceled) {
+			t.Errorf("a request of a cancelled context should fail, got %v", err)
+		}
+	}
+	if _, err := client.GetRepositories(); err != nil || requests != 1 {
+		t.Errorf("the client itself should stay unbound, got %v after %d requests", err, requests)
+	}
+}
+
 func TestHTTPClient_SearchCheatSheets(t *testing.T) {
 	sheets := []CheatSheet{
 		{
@@ -197,6 +222,195 @@ func TestHTTPClient_RateCheatSheet(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_ReportCheatSheet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/cheatsheets/sheet1/report" || r.Method != "POST" {
+			t.Errorf("Expected POST /api/cheatsheets/sheet1/report, got %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "ApiKey secret" {
+			t.Errorf("Expected the API key, got %q", r.Header.Get("Authorization"))
+		}
+
+		var data map[string]string
+		json.NewDecoder(r.Body).Decode(&data)
+		if data["reason"] != "spam" {
+			t.Errorf("Expected reason spam, got %q", data["reason"])
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+	client.SetAPIKey("secret")
+
+	if err := client.ReportCheatSheet("sheet1", "spam"); err != nil {
+		t.Fatalf("ReportCheatSheet() error = %v", err)
+	}
+	if err := client.ReportCheatSheet("sheet1", "boring"); !errors.Is(err, ErrInvalidReason) {
+		t.Errorf("Expected ErrInvalidReason, got %v", err)
+	}
+}
+
+func TestHTTPClient_ConditionalRequests(t *testing.T) {
+	sheet := CheatSheet{ID: "sheet1", Name: "Test Sheet"}
+	var requests, notModified int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if r.URL.Path == "/api/cheatsheets" {
+			json.NewEncoder(w).Encode([]CheatSheet{sheet})
+			return
+		}
+		json.NewEncoder(w).Encode(sheet)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+
+	if _, err := client.GetCheatSheet("sheet1"); err != nil {
+		t.Fatalf("GetCheatSheet() error = %v", err)
+	}
+	// fresh entries are served without a request
+	if _, err := client.GetCheatSheet("sheet1"); err != nil {
+		t.Fatalf("GetCheatSheet() error = %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("Expected 1 request while fresh, got %d", requests)
+	}
+
+	// stale entries are revalidated and reused on 304
+	client.ttl = 0
+	got, err := client.GetCheatSheet("sheet1")
+	if err != nil {
+		t.Fatalf("GetCheatSheet() after 304 error = %v", err)
+	}
+	if got.Name != sheet.Name {
+		t.Errorf("Expected cached name %q, got %q", sheet.Name, got.Name)
+	}
+	if requests != 2 || notModified != 1 {
+		t.Errorf("Expected a conditional request answered with 304, got %d requests, %d not modified", requests, notModified)
+	}
+
+	// searches are always revalidated
+	for i := 0; i < 2; i++ {
+		sheets, err := client.SearchCheatSheets(SearchOptions{Query: "git"})
+		if err != nil || len(sheets) != 1 {
+			t.Fatalf("SearchCheatSheets() = %v, %v", sheets, err)
+		}
+	}
+	if notModified != 2 {
+		t.Errorf("Expected repeated search to be answered with 304, got %d", notModified)
+	}
+}
+
+func TestHTTPClient_LastModified(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Format(http.TimeFormat)
+	var conditional int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == modified {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", modified)
+		json.NewEncoder(w).Encode([]Repository{{Name: "Repo"}})
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+	client.ttl = 0
+
+	for i := 0; i < 2; i++ {
+		repos, err := client.GetRepositories()
+		if err != nil {
+			t.Fatalf("GetRepositories() error = %v", err)
+		}
+		if len(repos) != 1 || repos[0].Name != "Repo" {
+			t.Errorf("GetRepositories() = %+v", repos)
+		}
+	}
+	if conditional != 1 {
+		t.Errorf("Expected 1 conditional request, got %d", conditional)
+	}
+}
+
+func TestHTTPClient_PersistentCache(t *testing.T) {
+	var full, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		json.NewEncoder(w).Encode([]Repository{{Name: "Repo"}})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	newClient := func() *HTTPClient {
+		responses, err := cache.NewMultiLevelCache(1024*1024, 100, dir, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := NewHTTPClient(server.URL)
+		client.SetCache(responses)
+		return client
+	}
+
+	if _, err := newClient().GetRepositories(); err != nil {
+		t.Fatalf("GetRepositories() error = %v", err)
+	}
+
+	// a new client, as after a restart, reads the response from disk
+	restarted := newClient()
+	if repos, err := restarted.GetRepositories(); err != nil || len(repos) != 1 || repos[0].Name != "Repo" {
+		t.Fatalf("GetRepositories() after restart = %v, %v", repos, err)
+	}
+	if full != 1 || notModified != 0 {
+		t.Errorf("Expected the fresh response to be served from disk, got %d full, %d conditional", full, notModified)
+	}
+
+	restarted.ttl = 0
+	if repos, err := restarted.GetRepositories(); err != nil || len(repos) != 1 {
+		t.Fatalf("GetRepositories() after revalidation = %v, %v", repos, err)
+	}
+	if full != 1 || notModified != 1 {
+		t.Errorf("Expected the persisted ETag to be revalidated, got %d full, %d conditional", full, notModified)
+	}
+}
+
+func TestHTTPClient_RateInvalidatesCache(t *testing.T) {
+	var gets int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets++
+			json.NewEncoder(w).Encode(CheatSheet{ID: "sheet1"})
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+	client.GetCheatSheet("sheet1")
+	if err := client.RateCheatSheet("sheet1", 4); err != nil {
+		t.Fatalf("RateCheatSheet() error = %v", err)
+	}
+	client.GetCheatSheet("sheet1")
+
+	if gets != 2 {
+		t.Errorf("Expected rating to invalidate the cached sheet, got %d fetches", gets)
+	}
+}
+
 func TestMockClient_Operations(t *testing.T) {
 	client := NewMockClient()
 
@@ -0,0 +1,200 @@
+package online
+
+import (
+	"context"
+	"sync"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// DefaultDownloadWorkers is how many sheets download at once unless the
+// config says otherwise
+const DefaultDownloadWorkers = 4
+
+// DownloadState is where a download of a DownloadManager stands
+type DownloadState int
+
+const (
+	DownloadQueued DownloadState = iota
+	DownloadRunning
+	DownloadDone
+	DownloadFailed
+	DownloadCancelled
+)
+
+func (s DownloadState) String() string {
+	switch s {
+	case DownloadQueued:
+		return "queued"
+	case DownloadRunning:
+		return "downloading"
+	case DownloadDone:
+		return "done"
+	case DownloadFailed:
+		return "failed"
+	case DownloadCancelled:
+		return "cancelled"
+	}
+	return "unknown"
+}
+
+// Finished reports whether the download has ended, one way or another
+func (s DownloadState) Finished() bool {
+	return s >= DownloadDone
+}
+
+// DownloadFunc downloads a sheet, stopping when ctx is done. It may return
+// the app along with an error, as DownloadVerified does for sheets that
+// could not be verified.
+type DownloadFunc func(ctx context.Context, sheet CheatSheet) (*apps.App, error)
+
+// DownloadEvent is a change in the state of a download: it started, or it
+// ended with App and Err as the DownloadFunc returned them
+type DownloadEvent struct {
+	ID    int
+	Sheet CheatSheet
+	State DownloadState
+	App   *apps.App
+	Err   error
+}
+
+// DownloadManager downloads batches of sheets in the background, running
+// at most a fixed number of downloads at a time across every batch
+type DownloadManager struct {
+	slots   chan struct{}
+	mu      sync.Mutex
+	seq     int
+	cancels map[int]context.CancelFunc
+}
+
+// NewDownloadManager creates a manager running at most workers downloads
+// at once, or DefaultDownloadWorkers when workers is not positive
+func NewDownloadManager(workers int) *DownloadManager {
+	if workers <= 0 {
+		workers = DefaultDownloadWorkers
+	}
+	return &DownloadManager{
+		slots:   make(chan struct{}, workers),
+		cancels: make(map[int]context.CancelFunc),
+	}
+}
+
+// Workers returns how many downloads run at once
+func (d *DownloadManager) Workers() int {
+	return cap(d.slots)
+}
+
+// Start queues the sheets for fetch and returns the IDs of their
+// downloads, in the order of sheets, with the channel their events come
+// on. Downloads start in the order of sheets. Each sends a DownloadRunning
+// event when it starts, unless it is cancelled first, then one that it
+// ended; the channel is closed once every download of the batch has ended.
+func (d *DownloadManager) Start(sheets []CheatSheet, fetch DownloadFunc) ([]int, <-chan DownloadEvent) {
+	ids := make([]int, len(sheets))
+	// room for every event, so downloads never wait for the reader
+	events := make(chan DownloadEvent, 2*len(sheets))
+	var wg sync.WaitGroup
+
+	// each download waits for the one before it to take a slot, or to be
+	// cancelled, before it takes one
+	turn := make(chan struct{})
+	close(turn)
+
+	d.mu.Lock()
+	for i, sheet := range sheets {
+		d.seq++
+		ids[i] = d.seq
+		ctx, cancel := context.WithCancel(context.Background())
+		d.cancels[d.seq] = cancel
+
+		next := make(chan struct{})
+		wg.Add(1)
+		go func(id int, sheet CheatSheet, turn <-chan struct{}, next chan<- struct{}) {
+			defer wg.Done()
+			defer d.forget(id)
+			events <- d.run(ctx, id, sheet, fetch, events, turn, next)
+		}(d.seq, sheet, turn, next)
+		turn = next
+	}
+	d.mu.Unlock()
+
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+	return ids, events
+}
+
+// run waits for its turn and a free slot, closing next once it no longer
+// holds up the downloads after it, then downloads sheet, returning the
+// event ending the download
+func (d *DownloadManager) run(ctx context.Context, id int, sheet CheatSheet, fetch DownloadFunc,
+	events chan<- DownloadEvent, turn <-chan struct{}, next chan<- struct{}) DownloadEvent {
+	cancelled := DownloadEvent{ID: id, Sheet: sheet, State: DownloadCancelled, Err: context.Canceled}
+	select {
+	case <-turn:
+	case <-ctx.Done():
+		go func() {
+			<-turn
+			close(next)
+		}()
+		return cancelled
+	}
+
+	select {
+	case d.slots <- struct{}{}:
+		close(next)
+	case <-ctx.Done():
+		close(next)
+		return cancelled
+	}
+	if ctx.Err() != nil {
+		<-d.slots
+		return cancelled
+	}
+	events <- DownloadEvent{ID: id, Sheet: sheet, State: DownloadRunning}
+
+	// the slot stays taken until fetch returns, even when the download is
+	// cancelled meanwhile, so no more than the workers ever run
+	done := make(chan DownloadEvent, 1)
+	go func() {
+		defer func() { <-d.slots }()
+		app, err := fetch(ctx, sheet)
+		state := DownloadDone
+		if err != nil {
+			state = DownloadFailed
+		}
+		done <- DownloadEvent{ID: id, Sheet: sheet, State: state, App: app, Err: err}
+	}()
+
+	select {
+	case event := <-done:
+		return event
+	case <-ctx.Done():
+		return cancelled
+	}
+}
+
+// Cancel cancels the download with the ID: a queued download never starts
+// and a running one has the context of its fetch cancelled, with what it
+// returns dropped. It reports whether the
+// download had not ended yet.
+func (d *DownloadManager) Cancel(id int) bool {
+	d.mu.Lock()
+	cancel, ok := d.cancels[id]
+	d.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// forget drops the cancel func of an ended download
+func (d *DownloadManager) forget(id int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if cancel, ok := d.cancels[id]; ok {
+		cancel()
+		delete(d.cancels, id)
+	}
+}
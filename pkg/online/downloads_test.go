@@ -0,0 +1,137 @@
+package online
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// collect reads the events of a batch until its channel is closed
+func collect(t *testing.T, events <-chan DownloadEvent) map[int][]DownloadEvent {
+	t.Helper()
+	byID := map[int][]DownloadEvent{}
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return byID
+			}
+			byID[event.ID] = append(byID[event.ID], event)
+		case <-timeout:
+			t.Fatal("the batch did not end")
+		}
+	}
+}
+
+func TestDownloadManager(t *testing.T) {
+	manager := NewDownloadManager(2)
+	if manager.Workers() != 2 {
+		t.Errorf("Workers() = %d, want 2", manager.Workers())
+	}
+	if NewDownloadManager(0).Workers() != DefaultDownloadWorkers {
+		t.Errorf("a manager without workers should run %d", DefaultDownloadWorkers)
+	}
+
+	var running, most atomic.Int32
+	fetch := func(_ context.Context, sheet CheatSheet) (*apps.App, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := most.Load()
+			if n <= m || most.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if sheet.ID == "broken" {
+			return nil, errors.New("not found")
+		}
+		return &apps.App{Name: sheet.ID}, nil
+	}
+
+	sheets := []CheatSheet{{ID: "a"}, {ID: "b"}, {ID: "broken"}, {ID: "c"}, {ID: "d"}, {ID: "e"}}
+	ids, events := manager.Start(sheets, fetch)
+	byID := collect(t, events)
+
+	if most.Load() > 2 {
+		t.Errorf("%d downloads ran at once, want at most 2", most.Load())
+	}
+	for i, id := range ids {
+		got := byID[id]
+		if len(got) != 2 || got[0].State != DownloadRunning {
+			t.Fatalf("events of %s = %+v, want running then an end", sheets[i].ID, got)
+		}
+		end := got[1]
+		if sheets[i].ID == "broken" {
+			if end.State != DownloadFailed || end.Err == nil {
+				t.Errorf("the broken download ended %v, %v; want it failed", end.State, end.Err)
+			}
+			continue
+		}
+		if end.State != DownloadDone || end.App == nil || end.App.Name != sheets[i].ID || end.Sheet.ID != sheets[i].ID {
+			t.Errorf("download of %s ended %+v", sheets[i].ID, end)
+		}
+	}
+	if manager.Cancel(ids[0]) {
+		t.Error("Cancel() of an ended download should report false")
+	}
+}
+
+func TestDownloadManager_Cancel(t *testing.T) {
+	manager := NewDownloadManager(1)
+	release := make(chan struct{})
+	started := make(chan string, 3)
+	fetch := func(ctx context.Context, sheet CheatSheet) (*apps.App, error) {
+		started <- sheet.ID
+		if sheet.ID == "running" {
+			// holds its slot until it is told to stop
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		<-release
+		return &apps.App{Name: sheet.ID}, nil
+	}
+
+	ids, events := manager.Start([]CheatSheet{{ID: "running"}, {ID: "queued"}, {ID: "last"}}, fetch)
+	if id := <-started; id != "running" {
+		t.Fatalf("the first download to start was %s", id)
+	}
+	if !manager.Cancel(ids[1]) || !manager.Cancel(ids[0]) {
+		t.Fatal("Cancel() of a queued or running download should report true")
+	}
+	close(release)
+	byID := collect(t, events)
+
+	if got := byID[ids[1]]; len(got) != 1 || got[0].State != DownloadCancelled {
+		t.Errorf("events of the queued download = %+v, want it cancelled without starting", got)
+	}
+	if got := byID[ids[0]]; len(got) != 2 || got[1].State != DownloadCancelled || got[1].App != nil {
+		t.Errorf("events of the running download = %+v, want its result dropped", got)
+	}
+	if got := byID[ids[2]]; len(got) != 2 || got[1].State != DownloadDone {
+		t.Errorf("events of the last download = %+v, want it done", got)
+	}
+}
+
+func TestDownloadState_String(t *testing.T) {
+	for state, want := range map[DownloadState]string{
+		DownloadQueued:    "queued",
+		DownloadRunning:   "downloading",
+		DownloadDone:      "done",
+		DownloadFailed:    "failed",
+		DownloadCancelled: "cancelled",
+	} {
+		if got := fmt.Sprint(state); got != want {
+			t.Errorf("%d.String() = %q, want %q", state, got, want)
+		}
+		if state.Finished() != (state >= DownloadDone) {
+			t.Errorf("%s.Finished() = %v", state, state.Finished())
+		}
+	}
+}
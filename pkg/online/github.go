@@ -0,0 +1,456 @@
+package online
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultGitHubAPIURL = "https://api.github.com"
+	githubPageSize      = 100
+)
+
+var (
+	ErrNotSupported      = errors.New("operation not supported by this client")
+	ErrInvalidRepository = errors.New("invalid repository reference")
+
+	errNotFound = errors.New("not found")
+)
+
+var nextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// GitHubClient browses YAML cheat sheets stored in GitHub repositories using
+// the repository contents API. Repositories are referenced as "owner/repo" or
+// "owner/repo/path/to/dir".
+type GitHubClient struct {
+	apiURL       string
+	repositories []repoRef
+	httpClient   *http.Client
+	sheets       *sheetCache
+	// ctx bounds the requests of the copies WithContext makes
+	ctx context.Context
+}
+
+// sheetCache holds the sheets a GitHubClient fetched, shared with the
+// copies WithContext makes
+type sheetCache struct {
+	mu   sync.RWMutex
+	byID map[string]*CheatSheet
+}
+
+type repoRef struct {
+	Owner string
+	Repo  string
+	Path  string
+}
+
+func (r repoRef) fullName() string {
+	return r.Owner + "/" + r.Repo
+}
+
+// URL returns the browsable GitHub URL identifying the repository reference
+func (r repoRef) URL() string {
+	if r.Path == "" {
+		return "https://github.com/" + r.fullName()
+	}
+	return "https://github.com/" + r.fullName() + "/tree/HEAD/" + r.Path
+}
+
+type githubRepo struct {
+	Name        string    `json:"name"`
+	FullName    string    `json:"full_name"`
+	Description string    `json:"description"`
+	Stars       int       `json:"stargazers_count"`
+	PushedAt    time.Time `json:"pushed_at"`
+	Owner       struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+type githubContent struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	SHA         string `json:"sha"`
+	Size        int    `json:"size"`
+	Type        string `json:"type"`
+	Content     string `json:"content"`
+	Encoding    string `json:"encoding"`
+	DownloadURL string `json:"download_url"`
+}
+
+// NewGitHubClient creates a client for the given repository references.
+// An empty apiURL uses the public GitHub API.
+func NewGitHubClient(repositories []string, apiURL string) *GitHubClient {
+	if apiURL == "" {
+		apiURL = DefaultGitHubAPIURL
+	}
+
+	client := &GitHubClient{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		httpClient: NewTransportClient(DefaultTransportOptions()),
+		sheets:     &sheetCache{byID: make(map[string]*CheatSheet)},
+	}
+
+	for _, spec := range repositories {
+		if ref, err := parseRepoRef(spec); err == nil {
+			client.repositories = append(client.repositories, ref)
+		}
+	}
+
+	return client
+}
+
+// SetTransportOptions replaces the retry, rate limit and timeout settings
+func (c *GitHubClient) SetTransportOptions(opts TransportOptions) {
+	c.httpClient = NewTransportClient(opts)
+}
+
+// GitHubMirrors turns the mirrors of the config, keyed by repositories as
+// listed for NewGitHubClient, into the URL prefixes of TransportOptions.
+// Mirrors serve the GitHub API of the repository under their base URL.
+// Keys that are URLs already, such as the API URL, are kept as they are.
+func GitHubMirrors(apiURL string, mirrors map[string][]string) map[string][]string {
+	if apiURL == "" {
+		apiURL = DefaultGitHubAPIURL
+	}
+	apiURL = strings.TrimSuffix(apiURL, "/")
+
+	prefixes := make(map[string][]string, len(mirrors))
+	for key, bases := range mirrors {
+		ref, err := parseRepoRef(key)
+		if strings.Contains(key, "://") && !strings.HasPrefix(key, "https://github.com/") || err != nil {
+			prefixes[key] = bases
+			continue
+		}
+		repoPath := "/repos/" + ref.fullName()
+		origin := apiURL + repoPath
+		for _, base := range bases {
+			prefixes[origin] = append(prefixes[origin], strings.TrimSuffix(base, "/")+repoPath)
+		}
+	}
+	return prefixes
+}
+
+// parseRepoRef parses "owner/repo[/path]" into its components
+func parseRepoRef(spec string) (repoRef, error) {
+	spec = strings.TrimPrefix(strings.Trim(spec, "/"), "https://github.com/")
+	parts := strings.SplitN(spec, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return repoRef{}, fmt.Errorf("%w: %s", ErrInvalidRepository, spec)
+	}
+
+	ref := repoRef{Owner: parts[0], Repo: parts[1]}
+	if len(parts) == 3 {
+		ref.Path = strings.Trim(parts[2], "/")
+	}
+	return ref, nil
+}
+
+func (c *GitHubClient) GetRepositories() ([]Repository, error) {
+	repos := make([]Repository, 0, len(c.repositories))
+
+	for _, ref := range c.repositories {
+		var info githubRepo
+		if _, err := c.getJSON(fmt.Sprintf("%s/repos/%s", c.apiURL, ref.fullName()), &info); err != nil {
+			return nil, fmt.Errorf("failed to fetch repository %s: %w", ref.fullName(), err)
+		}
+
+		name := info.FullName
+		if ref.Path != "" {
+			name += "/" + ref.Path
+		}
+		repos = append(repos, Repository{
+			URL:         ref.URL(),
+			Name:        name,
+			Description: info.Description,
+			LastUpdated: info.PushedAt,
+			Stars:       info.Stars,
+			Author:      info.Owner.Login,
+		})
+	}
+
+	return repos, nil
+}
+
+func (c *GitHubClient) SearchCheatSheets(opts SearchOptions) ([]CheatSheet, error) {
+	results := []CheatSheet{}
+
+	for _, ref := range c.repositories {
+		if opts.Repository != "" && opts.Repository != ref.URL() {
+			continue
+		}
+
+		files, err := c.listContents(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", ref.URL(), err)
+		}
+
+		for _, file := range files {
+			if file.Type != "file" || !isYAMLFile(file.Name) {
+				continue
+			}
+			sheet := CheatSheet{
+				ID:         ref.fullName() + "/" + file.Path,
+				Name:       strings.TrimSuffix(file.Name, path.Ext(file.Name)),
+				Repository: ref.URL(),
+				Author:     ref.Owner,
+				Version:    file.SHA,
+			}
+			if opts.Query != "" && !strings.Contains(strings.ToLower(sheet.Name), strings.ToLower(opts.Query)) {
+				continue
+			}
+			results = append(results, sheet)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	return paginate(results, opts.Offset, opts.Limit), nil
+}
+
+// WithContext returns a copy of the client sending its requests with ctx
+func (c *GitHubClient) WithContext(ctx context.Context) Client {
+	bound := *c
+	bound.ctx = ctx
+	return &bound
+}
+
+func (c *GitHubClient) GetCheatSheet(id string) (*CheatSheet, error) {
+	c.sheets.mu.RLock()
+	if cached, exists := c.sheets.byID[id]; exists {
+		c.sheets.mu.RUnlock()
+		return cached, nil
+	}
+	c.sheets.mu.RUnlock()
+
+	sheet, _, err := c.fetchSheet(id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.sheets.mu.Lock()
+	c.sheets.byID[id] = sheet
+	c.sheets.mu.Unlock()
+
+	return sheet, nil
+}
+
+func (c *GitHubClient) DownloadCheatSheet(id string) (*apps.App, error) {
+	sheet, err := c.GetCheatSheet(id)
+	if err != nil {
+		return nil, err
+	}
+	return &sheet.App, nil
+}
+
+// DownloadSigned downloads a sheet with the signature stored beside it in
+// the repository, as the file name followed by ".minisig"
+func (c *GitHubClient) DownloadSigned(id string) (*SignedSheet, error) {
+	sheet, data, err := c.fetchSheet(id)
+	if err != nil {
+		return nil, err
+	}
+	signed := &SignedSheet{App: &sheet.App, Data: data}
+
+	ref, filePath, _ := splitSheetID(id)
+	var content githubContent
+	_, err = c.getJSON(fmt.Sprintf("%s/repos/%s/contents/%s", c.apiURL, ref.fullName(), filePath+signature.Suffix), &content)
+	if errors.Is(err, errNotFound) {
+		return signed, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signature: %w", err)
+	}
+	if signed.Signature, err = c.fileData(content); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// fetchSheet downloads and parses the sheet with the ID, returning the
+// content of its file as well
+func (c *GitHubClient) fetchSheet(id string) (*CheatSheet, []byte, error) {
+	ref, filePath, err := splitSheetID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var content githubContent
+	if _, err := c.getJSON(fmt.Sprintf("%s/repos/%s/contents/%s", c.apiURL, ref.fullName(), filePath), &content); err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch cheat sheet: %w", err)
+	}
+
+	data, err := c.fileData(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var app apps.App
+	if err := yaml.Unmarshal(data, &app); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse cheat sheet %s: %w", id, err)
+	}
+
+	name := strings.TrimSuffix(content.Name, path.Ext(content.Name))
+	if app.Name == "" {
+		app.Name = name
+	}
+
+	return &CheatSheet{
+		ID:          id,
+		Name:        name,
+		Description: app.Description,
+		App:         app,
+		Repository:  ref.URL(),
+		Tags:        app.Categories,
+		Author:      ref.Owner,
+		Version:     content.SHA,
+	}, data, nil
+}
+
+func (c *GitHubClient) SubmitCheatSheet(sheet CheatSheet) error {
+	return fmt.Errorf("submit: %w", ErrNotSupported)
+}
+
+func (c *GitHubClient) RateCheatSheet(id string, rating float64) error {
+	return fmt.Errorf("rate: %w", ErrNotSupported)
+}
+
+func (c *GitHubClient) ReportCheatSheet(id, reason string) error {
+	return fmt.Errorf("report: %w", ErrNotSupported)
+}
+
+// listContents lists a repository directory, following pagination links
+func (c *GitHubClient) listContents(ref repoRef) ([]githubContent, error) {
+	url := fmt.Sprintf("%s/repos/%s/contents/%s?per_page=%d", c.apiURL, ref.fullName(), ref.Path, githubPageSize)
+
+	var all []githubContent
+	for url != "" {
+		var page []githubContent
+		next, err := c.getJSON(url, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		url = next
+	}
+
+	return all, nil
+}
+
+// fileData returns the decoded content of a file, downloading it when the
+// API did not inline it
+func (c *GitHubClient) fileData(content githubContent) ([]byte, error) {
+	if content.Encoding == "base64" && content.Content != "" {
+		data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content.Content, "\n", ""))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode file content: %w", err)
+		}
+		return data, nil
+	}
+
+	if content.DownloadURL == "" {
+		return nil, fmt.Errorf("file %s has no downloadable content", content.Path)
+	}
+
+	req, err := http.NewRequestWithContext(orBackground(c.ctx), "GET", content.DownloadURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", content.Path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", content.Path, err)
+	}
+	return data, nil
+}
+
+// getJSON decodes a GitHub API response into v and returns the next page URL
+func (c *GitHubClient) getJSON(url string, v interface{}) (string, error) {
+	req, err := http.NewRequestWithContext(orBackground(c.ctx), "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nextPageURL(resp.Header.Get("Link")), nil
+}
+
+// nextPageURL extracts the rel="next" target from a Link header
+func nextPageURL(link string) string {
+	if m := nextLinkPattern.FindStringSubmatch(link); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// splitSheetID splits "owner/repo/path/file.yaml" into a repository and file path
+func splitSheetID(id string) (repoRef, string, error) {
+	parts := strings.SplitN(id, "/", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return repoRef{}, "", fmt.Errorf("%w: %s", ErrInvalidRepository, id)
+	}
+	return repoRef{Owner: parts[0], Repo: parts[1]}, parts[2], nil
+}
+
+func isYAMLFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// paginate applies offset and limit to a result slice
+func paginate(sheets []CheatSheet, offset, limit int) []CheatSheet {
+	if offset >= len(sheets) {
+		return []CheatSheet{}
+	}
+	if offset > 0 {
+		sheets = sheets[offset:]
+	}
+	if limit > 0 && len(sheets) > limit {
+		sheets = sheets[:limit]
+	}
+	return sheets
+}
@@ -0,0 +1,176 @@
+package online
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const githubVimYAML = `name: vim
+description: Vi IMproved
+categories: [editor]
+shortcuts:
+  - keys: dd
+    description: delete line
+`
+
+func newGitHubTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/acme/sheets", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"full_name":        "acme/sheets",
+			"description":      "Acme cheat sheets",
+			"stargazers_count": 42,
+			"owner":            map[string]string{"login": "acme"},
+		})
+	})
+	mux.HandleFunc("/repos/acme/sheets/contents/apps", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode([]githubContent{
+				{Name: "zsh.yml", Path: "apps/zsh.yml", Type: "file"},
+			})
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/repos/acme/sheets/contents/apps?page=2>; rel="next"`, server.URL))
+		json.NewEncoder(w).Encode([]githubContent{
+			{Name: "vim.yaml", Path: "apps/vim.yaml", Type: "file"},
+			{Name: "README.md", Path: "apps/README.md", Type: "file"},
+			{Name: "extra", Path: "apps/extra", Type: "dir"},
+		})
+	})
+	mux.HandleFunc("/repos/acme/sheets/contents/apps/vim.yaml", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(githubContent{
+			Name:     "vim.yaml",
+			Path:     "apps/vim.yaml",
+			Type:     "file",
+			Encoding: "base64",
+			Content:  base64.StdEncoding.EncodeToString([]byte(githubVimYAML)),
+		})
+	})
+	mux.HandleFunc("/repos/acme/sheets/contents/apps/zsh.yml", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(githubContent{
+			Name:        "zsh.yml",
+			Path:        "apps/zsh.yml",
+			Type:        "file",
+			DownloadURL: server.URL + "/raw/zsh.yml",
+		})
+	})
+	mux.HandleFunc("/raw/zsh.yml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("description: Z shell\nshortcuts:\n  - keys: ctrl+r\n    description: history\n"))
+	})
+
+	server = httptest.NewServer(mux)
+	return server
+}
+
+func TestGitHubClient_GetRepositories(t *testing.T) {
+	server := newGitHubTestServer(t)
+	defer server.Close()
+
+	client := NewGitHubClient([]string{"acme/sheets/apps", "not-a-repo"}, server.URL)
+	repos, err := client.GetRepositories()
+	if err != nil {
+		t.Fatalf("GetRepositories() error = %v", err)
+	}
+
+	if len(repos) != 1 {
+		t.Fatalf("expected 1 repository, got %d", len(repos))
+	}
+	if repos[0].Name != "acme/sheets/apps" || repos[0].Stars != 42 || repos[0].Author != "acme" {
+		t.Errorf("unexpected repository %+v", repos[0])
+	}
+	if repos[0].URL != "https://github.com/acme/sheets/tree/HEAD/apps" {
+		t.Errorf("unexpected repository URL %s", repos[0].URL)
+	}
+}
+
+func TestGitHubClient_SearchCheatSheets(t *testing.T) {
+	server := newGitHubTestServer(t)
+	defer server.Close()
+
+	client := NewGitHubClient([]string{"acme/sheets/apps"}, server.URL)
+	sheets, err := client.SearchCheatSheets(SearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchCheatSheets() error = %v", err)
+	}
+
+	if len(sheets) != 2 {
+		t.Fatalf("expected 2 sheets across pages, got %d: %+v", len(sheets), sheets)
+	}
+	if sheets[0].ID != "acme/sheets/apps/vim.yaml" || sheets[1].Name != "zsh" {
+		t.Errorf("unexpected sheets %+v", sheets)
+	}
+
+	sheets, _ = client.SearchCheatSheets(SearchOptions{Offset: 1, Limit: 1})
+	if len(sheets) != 1 || sheets[0].Name != "zsh" {
+		t.Errorf("offset/limit not applied: %+v", sheets)
+	}
+
+	sheets, _ = client.SearchCheatSheets(SearchOptions{Query: "VI"})
+	if len(sheets) != 1 || sheets[0].Name != "vim" {
+		t.Errorf("query not applied: %+v", sheets)
+	}
+
+	sheets, _ = client.SearchCheatSheets(SearchOptions{Repository: "https://github.com/other/repo"})
+	if len(sheets) != 0 {
+		t.Errorf("repository filter not applied: %+v", sheets)
+	}
+}
+
+func TestGitHubClient_DownloadCheatSheet(t *testing.T) {
+	server := newGitHubTestServer(t)
+	defer server.Close()
+
+	client := NewGitHubClient([]string{"acme/sheets/apps"}, server.URL)
+
+	app, err := client.DownloadCheatSheet("acme/sheets/apps/vim.yaml")
+	if err != nil {
+		t.Fatalf("DownloadCheatSheet() error = %v", err)
+	}
+	if app.Name != "vim" || len(app.Shortcuts) != 1 {
+		t.Errorf("unexpected app %+v", app)
+	}
+
+	app, err = client.DownloadCheatSheet("acme/sheets/apps/zsh.yml")
+	if err != nil {
+		t.Fatalf("DownloadCheatSheet() via download_url error = %v", err)
+	}
+	if app.Name != "zsh" {
+		t.Errorf("app name should default to file name, got %q", app.Name)
+	}
+
+	if _, err := client.DownloadCheatSheet("bad-id"); !errors.Is(err, ErrInvalidRepository) {
+		t.Errorf("expected ErrInvalidRepository, got %v", err)
+	}
+	if _, err := client.DownloadCheatSheet("acme/sheets/apps/missing.yaml"); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestGitHubClient_Unsupported(t *testing.T) {
+	client := NewGitHubClient(nil, "")
+
+	if err := client.SubmitCheatSheet(CheatSheet{}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+	if err := client.RateCheatSheet("x", 5); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	link := `<https://api.github.com/x?page=2>; rel="next", <https://api.github.com/x?page=5>; rel="last"`
+	if got := nextPageURL(link); got != "https://api.github.com/x?page=2" {
+		t.Errorf("nextPageURL() = %q", got)
+	}
+	if got := nextPageURL(""); got != "" {
+		t.Errorf("nextPageURL(\"\") = %q", got)
+	}
+}
@@ -0,0 +1,132 @@
+package online
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// MirrorTransport is an http.RoundTripper failing over to mirrors. Requests
+// to a URL under an origin prefix go to the origin or to the mirror prefixes
+// serving the same content, starting with the one that answered last, and
+// move on to the next when one cannot be reached or fails on its side.
+//
+// Credentials are not sent to mirrors on another host than the origin.
+type MirrorTransport struct {
+	base    http.RoundTripper
+	mirrors map[string][]string
+	// preferred is the index of the prefix that answered last, per origin,
+	// with 0 for the origin itself
+	preferred map[string]int
+	mu        sync.Mutex
+}
+
+// NewMirrorTransport wraps base, or http.DefaultTransport when base is nil,
+// with the mirrors of each origin prefix
+func NewMirrorTransport(base http.RoundTripper, mirrors map[string][]string) *MirrorTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &MirrorTransport{base: base, mirrors: map[string][]string{}, preferred: map[string]int{}}
+	for origin, prefixes := range mirrors {
+		origin = strings.TrimSuffix(origin, "/")
+		for _, prefix := range prefixes {
+			t.mirrors[origin] = append(t.mirrors[origin], strings.TrimSuffix(prefix, "/"))
+		}
+	}
+	return t
+}
+
+// origin returns the longest origin prefix of rawURL, if any
+func (t *MirrorTransport) origin(rawURL string) (string, bool) {
+	best, found := "", false
+	for origin := range t.mirrors {
+		if !strings.HasPrefix(rawURL, origin) || len(origin) <= len(best) && found {
+			continue
+		}
+		// the prefix must end at a path boundary
+		if rest := rawURL[len(origin):]; rest == "" || strings.ContainsAny(rest[:1], "/?#") {
+			best, found = origin, true
+		}
+	}
+	return best, found
+}
+
+func (t *MirrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rawURL := req.URL.String()
+	origin, ok := t.origin(rawURL)
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	prefixes := append([]string{origin}, t.mirrors[origin]...)
+	rest := rawURL[len(origin):]
+
+	t.mu.Lock()
+	start := t.preferred[origin]
+	t.mu.Unlock()
+	// a body that cannot be replayed goes to a single prefix
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for i := 0; ; i++ {
+		index := (start + i) % len(prefixes)
+		attemptReq, err := mirrorRequest(req, prefixes[index]+rest, i > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if !mirrorFailed(req, resp, err) {
+			if err == nil {
+				t.mu.Lock()
+				t.preferred[origin] = index
+				t.mu.Unlock()
+			}
+			return resp, err
+		}
+		// the server may have processed a request it did not answer
+		if i == len(prefixes)-1 || !replayable || err != nil && !isIdempotent(req.Method) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+	}
+}
+
+// mirrorRequest returns req sent to rawURL, with a fresh copy of its body
+// when it was sent before, which the caller checked it can be, and without
+// credentials when rawURL is on another host
+func mirrorRequest(req *http.Request, rawURL string, resend bool) (*http.Request, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	out := req.Clone(req.Context())
+	out.URL = target
+	out.Host = ""
+	if target.Host != req.URL.Host {
+		out.Header.Del("Authorization")
+		out.Header.Del("Cookie")
+	}
+	if resend && req.Body != nil && req.Body != http.NoBody {
+		if out.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// mirrorFailed reports whether an attempt failed in a way another mirror
+// may not: the server could not be reached or failed on its side
+func mirrorFailed(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,133 @@
+package online
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMirrorTransport_FailsOver(t *testing.T) {
+	var originCalls int32
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&originCalls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer origin.Close()
+
+	github := newGitHubTestServer(t)
+	defer github.Close()
+	mirror := httptest.NewServer(http.StripPrefix("/mirror", github.Config.Handler))
+	defer mirror.Close()
+
+	client := NewGitHubClient([]string{"acme/sheets/apps"}, origin.URL)
+	opts := DefaultTransportOptions()
+	opts.MaxRetries = 0
+	opts.Mirrors = GitHubMirrors(origin.URL, map[string][]string{"acme/sheets": {mirror.URL + "/mirror/"}})
+	client.SetTransportOptions(opts)
+
+	repos, err := client.GetRepositories()
+	if err != nil || len(repos) != 1 || repos[0].Name != "acme/sheets/apps" {
+		t.Fatalf("GetRepositories() through the mirror = %+v, %v", repos, err)
+	}
+	app, err := client.DownloadCheatSheet("acme/sheets/apps/vim.yaml")
+	if err != nil || app.Name != "vim" {
+		t.Fatalf("DownloadCheatSheet() through the mirror = %+v, %v", app, err)
+	}
+	// the mirror that answered is asked first from then on
+	if calls := atomic.LoadInt32(&originCalls); calls != 1 {
+		t.Errorf("the failing origin was asked %d times, want once", calls)
+	}
+}
+
+func TestMirrorTransport_Requests(t *testing.T) {
+	var originCalls int32
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&originCalls, 1)
+		if r.Header.Get("Authorization") != "ApiKey secret" {
+			t.Errorf("the origin should get the credentials, got %q", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer origin.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("a mirror on another host got the credentials %q", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.URL.Path + " " + string(body)))
+	}))
+	defer mirror.Close()
+
+	client := &http.Client{Transport: NewMirrorTransport(nil, map[string][]string{origin.URL + "/api": {mirror.URL + "/v2"}})}
+	send := func(method, path, body string) (string, int) {
+		req, _ := http.NewRequest(method, origin.URL+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "ApiKey secret")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return string(data), resp.StatusCode
+	}
+
+	if got, _ := send("POST", "/api/cheatsheets/1/rate", `{"rating":5}`); got != `/v2/cheatsheets/1/rate {"rating":5}` {
+		t.Errorf("the refused POST should be replayed to the mirror, got %q", got)
+	}
+	if got, _ := send("GET", "/api/cheatsheets?q=vim", ""); got != "/v2/cheatsheets " {
+		t.Errorf("GET through the mirror = %q", got)
+	}
+	// only paths under the origin prefix have mirrors
+	if _, status := send("GET", "/apis", ""); status != http.StatusServiceUnavailable {
+		t.Errorf("a path beside the origin prefix got %d, want the origin's answer", status)
+	}
+	if calls := atomic.LoadInt32(&originCalls); calls != 2 {
+		t.Errorf("the origin was asked %d times, want 2", calls)
+	}
+}
+
+func TestMirrorTransport_NetworkErrorOfPost(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := origin.URL
+	origin.Close()
+	var mirrorCalls int32
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&mirrorCalls, 1)
+	}))
+	defer mirror.Close()
+
+	client := &http.Client{Transport: NewMirrorTransport(nil, map[string][]string{url: {mirror.URL}})}
+	if _, err := client.Post(url+"/report", "application/json", strings.NewReader("{}")); err == nil {
+		t.Error("a POST the origin may have processed should not go to a mirror")
+	}
+	if resp, err := client.Get(url + "/sheets"); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("a GET should fail over, got %v", err)
+	}
+	if calls := atomic.LoadInt32(&mirrorCalls); calls != 1 {
+		t.Errorf("the mirror was asked %d times, want once", calls)
+	}
+}
+
+func TestGitHubMirrors(t *testing.T) {
+	got := GitHubMirrors("", map[string][]string{
+		"acme/sheets/apps":             {"https://gitea.example.com/api/v1/"},
+		"https://github.com/cheat/all": {"https://a.example.com", "https://b.example.com"},
+		"https://api.github.com":       {"https://proxy.example.com"},
+	})
+	want := map[string][]string{
+		"https://api.github.com/repos/acme/sheets": {"https://gitea.example.com/api/v1/repos/acme/sheets"},
+		"https://api.github.com/repos/cheat/all":   {"https://a.example.com/repos/cheat/all", "https://b.example.com/repos/cheat/all"},
+		"https://api.github.com":                   {"https://proxy.example.com"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("GitHubMirrors() = %v, want %v", got, want)
+	}
+	for origin, mirrors := range want {
+		if strings.Join(got[origin], " ") != strings.Join(mirrors, " ") {
+			t.Errorf("mirrors of %s = %v, want %v", origin, got[origin], mirrors)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package online
+
+import (
+	"fmt"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+)
+
+// SignedSheet is a sheet as the file it was published as, with the
+// content of its .minisig file, or no signature when it has none
+type SignedSheet struct {
+	App       *apps.App
+	Data      []byte
+	Signature []byte
+}
+
+// SignedDownloader is implemented by clients that serve the signatures of
+// their sheets
+type SignedDownloader interface {
+	DownloadSigned(id string) (*SignedSheet, error)
+}
+
+// DownloadVerified downloads the sheet with the ID and checks its signature
+// against the trusted keys of verifier, unless it has none. Sheets of
+// clients without signatures count as unsigned.
+//
+// When the sheet could only not be verified, being unsigned or signed by
+// an unknown key, the app is returned along with an error that
+// signature.Overridable reports, for the user to choose whether to use it.
+func DownloadVerified(client Client, verifier *signature.Verifier, id string) (*apps.App, error) {
+	if !verifier.Enabled() {
+		return client.DownloadCheatSheet(id)
+	}
+
+	downloader, ok := client.(SignedDownloader)
+	if !ok {
+		app, err := client.DownloadCheatSheet(id)
+		if err != nil {
+			return nil, err
+		}
+		return app, signature.ErrUnsigned
+	}
+
+	signed, err := downloader.DownloadSigned(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := verifier.Verify(signed.Data, signed.Signature); err != nil {
+		if signature.Overridable(err) {
+			return signed.App, err
+		}
+		return nil, fmt.Errorf("cheat sheet %s: %w", id, err)
+	}
+	return signed.App, nil
+}
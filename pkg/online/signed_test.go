@@ -0,0 +1,79 @@
+package online
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+)
+
+func TestDownloadVerified(t *testing.T) {
+	public, secret, err := signature.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	_, stranger, _ := signature.GenerateKey()
+
+	files := map[string][]byte{
+		"apps/vim.yaml":              []byte(githubVimYAML),
+		"apps/vim.yaml.minisig":      secret.Sign([]byte(githubVimYAML), "file:vim.yaml"),
+		"apps/unsigned.yaml":         []byte(githubVimYAML),
+		"apps/stranger.yaml":         []byte(githubVimYAML),
+		"apps/stranger.yaml.minisig": stranger.Sign([]byte(githubVimYAML), ""),
+		"apps/altered.yaml":          []byte(githubVimYAML + "  - keys: x\n    description: delete\n"),
+		"apps/altered.yaml.minisig":  secret.Sign([]byte(githubVimYAML), ""),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/acme/sheets/contents/", func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path[len("/repos/acme/sheets/contents/"):]
+		data, ok := files[path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(githubContent{
+			Name:     path[len("apps/"):],
+			Path:     path,
+			Type:     "file",
+			Encoding: "base64",
+			Content:  base64.StdEncoding.EncodeToString(data),
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewGitHubClient([]string{"acme/sheets/apps"}, server.URL)
+	verifier, _ := signature.NewVerifier([]string{public.String()})
+
+	app, err := DownloadVerified(client, verifier, "acme/sheets/apps/vim.yaml")
+	if err != nil || app == nil || app.Name != "vim" {
+		t.Fatalf("DownloadVerified() of a signed sheet = %+v, %v", app, err)
+	}
+
+	for _, id := range []string{"acme/sheets/apps/unsigned.yaml", "acme/sheets/apps/stranger.yaml"} {
+		app, err := DownloadVerified(client, verifier, id)
+		if !signature.Overridable(err) || app == nil {
+			t.Errorf("DownloadVerified(%s) = %+v, %v; want the app with an overridable error", id, app, err)
+		}
+	}
+
+	app, err = DownloadVerified(client, verifier, "acme/sheets/apps/altered.yaml")
+	if !errors.Is(err, signature.ErrInvalidSignature) || app != nil {
+		t.Errorf("DownloadVerified() of an altered sheet = %+v, %v; want %v", app, err, signature.ErrInvalidSignature)
+	}
+
+	// without trusted keys nothing is verified
+	if _, err := DownloadVerified(client, nil, "acme/sheets/apps/altered.yaml"); err != nil {
+		t.Errorf("DownloadVerified() without keys error = %v", err)
+	}
+
+	// clients without signatures serve unsigned sheets
+	app, err = DownloadVerified(NewMockClient(), verifier, "vim-advanced")
+	if !errors.Is(err, signature.ErrUnsigned) || app == nil {
+		t.Errorf("DownloadVerified() from the mock client = %+v, %v; want %v", app, err, signature.ErrUnsigned)
+	}
+}
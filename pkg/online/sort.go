@@ -0,0 +1,35 @@
+package online
+
+import (
+	"sort"
+	"strings"
+)
+
+// Sort orders accepted by SortCheatSheets and SearchOptions.SortBy
+const (
+	SortByName      = "name"
+	SortByRating    = "rating"
+	SortByDownloads = "downloads"
+)
+
+// SheetSortOrders lists the sort orders in the order the UI cycles through them
+var SheetSortOrders = []string{SortByName, SortByRating, SortByDownloads}
+
+// SortCheatSheets sorts sheets in place. Names sort ascending, ratings and
+// download counts descending; ties fall back to the name.
+func SortCheatSheets(sheets []CheatSheet, by string) {
+	sort.SliceStable(sheets, func(i, j int) bool {
+		a, b := sheets[i], sheets[j]
+		switch by {
+		case SortByRating:
+			if a.Rating != b.Rating {
+				return a.Rating > b.Rating
+			}
+		case SortByDownloads:
+			if a.Downloads != b.Downloads {
+				return a.Downloads > b.Downloads
+			}
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+}
@@ -0,0 +1,30 @@
+package online
+
+import "testing"
+
+func TestSortCheatSheets(t *testing.T) {
+	sheets := []CheatSheet{
+		{Name: "tmux", Rating: 4.0, Downloads: 10},
+		{Name: "Bash", Rating: 4.5, Downloads: 10},
+		{Name: "vim", Rating: 4.5, Downloads: 300},
+	}
+
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{SortByName, []string{"Bash", "tmux", "vim"}},
+		{SortByRating, []string{"Bash", "vim", "tmux"}},
+		{SortByDownloads, []string{"vim", "Bash", "tmux"}},
+		{"unknown", []string{"Bash", "tmux", "vim"}},
+	}
+
+	for _, tt := range tests {
+		SortCheatSheets(sheets, tt.by)
+		for i, name := range tt.want {
+			if sheets[i].Name != name {
+				t.Errorf("sort by %s: position %d = %s, want %s", tt.by, i, sheets[i].Name, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package online
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// InstalledSheetsKey is the session document recording the sheets
+// installed from online repositories
+const InstalledSheetsKey = "installed_sheets"
+
+// InstalledSheet records a sheet downloaded as an app, with the revision
+// downloaded, to tell when its repository has a newer one
+type InstalledSheet struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	App        string `json:"app"`
+	Repository string `json:"repository"`
+	Revision   string `json:"revision"`
+}
+
+// SheetUpdate is an installed sheet whose repository has changed it
+type SheetUpdate struct {
+	Installed InstalledSheet
+	Sheet     CheatSheet
+}
+
+// Revision identifies the content of the sheet: its version where the
+// provider tracks one, else the time it was last updated. It is empty when
+// the provider tells neither.
+func (s CheatSheet) Revision() string {
+	if s.Version != "" {
+		return s.Version
+	}
+	if s.UpdatedAt.IsZero() {
+		return ""
+	}
+	return s.UpdatedAt.UTC().Format(time.RFC3339)
+}
+
+// Installed records the sheet as downloaded into the app called app
+func (s CheatSheet) Installed(app string) InstalledSheet {
+	return InstalledSheet{
+		ID:         s.ID,
+		Name:       s.Name,
+		App:        app,
+		Repository: s.Repository,
+		Revision:   s.Revision(),
+	}
+}
+
+// CheckUpdates lists the installed sheets of the subscribed repositories
+// whose revision differs from the one downloaded. A repository that fails
+// to list its sheets is reported in the error while the others are still
+// checked.
+func CheckUpdates(client Client, subscriptions []string, installed []InstalledSheet) ([]SheetUpdate, error) {
+	var updates []SheetUpdate
+	var errs []error
+
+	for _, repo := range subscriptions {
+		sheets, err := client.SearchCheatSheets(SearchOptions{Repository: repo})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", repo, err))
+			continue
+		}
+
+		byID := make(map[string]CheatSheet, len(sheets))
+		for _, sheet := range sheets {
+			byID[sheet.ID] = sheet
+		}
+		for _, sheet := range installed {
+			if sheet.Repository != repo {
+				continue
+			}
+			latest, ok := byID[sheet.ID]
+			if !ok || latest.Revision() == "" || latest.Revision() == sheet.Revision {
+				continue
+			}
+			updates = append(updates, SheetUpdate{Installed: sheet, Sheet: latest})
+		}
+	}
+
+	return updates, errors.Join(errs...)
+}
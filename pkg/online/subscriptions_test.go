@@ -0,0 +1,40 @@
+package online
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheatSheet_Revision(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if got := (CheatSheet{UpdatedAt: updated}).Revision(); got != "2024-05-01T12:00:00Z" {
+		t.Errorf("revision should be the update time, got %q", got)
+	}
+	if got := (CheatSheet{UpdatedAt: updated, Version: "abc"}).Revision(); got != "abc" {
+		t.Errorf("revision should prefer the version, got %q", got)
+	}
+	if got := (CheatSheet{}).Revision(); got != "" {
+		t.Errorf("revision should be empty, got %q", got)
+	}
+}
+
+func TestCheckUpdates(t *testing.T) {
+	client := NewMockClient()
+	sheets, _ := client.SearchCheatSheets(SearchOptions{})
+	vim, git := sheets[0], sheets[1]
+
+	installed := []InstalledSheet{vim.Installed("vim-advanced"), git.Installed("git-workflow")}
+	installed[1].Revision = "older"
+
+	updates, err := CheckUpdates(client, []string{git.Repository}, installed)
+	if err != nil {
+		t.Fatalf("CheckUpdates failed: %v", err)
+	}
+	if len(updates) != 1 || updates[0].Sheet.ID != git.ID || updates[0].Installed.App != "git-workflow" {
+		t.Errorf("only the changed sheet should be an update, got %+v", updates)
+	}
+
+	if updates, _ := CheckUpdates(client, []string{"https://example.com/other"}, installed); len(updates) != 0 {
+		t.Errorf("sheets of repositories not subscribed to should not be checked, got %+v", updates)
+	}
+}
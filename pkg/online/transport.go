@@ -0,0 +1,281 @@
+package online
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// TransportOptions control retries, rate limiting and timeouts of requests
+// made by the online and sync clients
+type TransportOptions struct {
+	// MaxRetries is the number of times a failed request is retried
+	MaxRetries int
+	// RetryDelay is the base delay, doubled after every attempt
+	RetryDelay time.Duration
+	// MaxRetryDelay caps the delay between attempts
+	MaxRetryDelay time.Duration
+	// RateLimit is the maximum number of requests per second; 0 disables it
+	RateLimit float64
+	// Burst is the number of requests allowed at once before RateLimit applies
+	Burst int
+	// Timeout bounds a whole request including its retries
+	Timeout time.Duration
+	// Mirrors maps URL prefixes of origins to the prefixes of mirrors tried
+	// in turn on every attempt; see MirrorTransport
+	Mirrors map[string][]string
+}
+
+// DefaultTransportOptions returns the options used when none are configured
+func DefaultTransportOptions() TransportOptions {
+	return TransportOptions{
+		MaxRetries:    3,
+		RetryDelay:    500 * time.Millisecond,
+		MaxRetryDelay: 10 * time.Second,
+		Burst:         1,
+		Timeout:       30 * time.Second,
+	}
+}
+
+// Transport is an http.RoundTripper that retries transient failures with
+// jittered exponential backoff and limits the request rate
+type Transport struct {
+	base    http.RoundTripper
+	opts    TransportOptions
+	limiter *rateLimiter
+	// sleep waits between attempts; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewTransport wraps base, or http.DefaultTransport when base is nil
+func NewTransport(base http.RoundTripper, opts TransportOptions) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &Transport{base: base, opts: opts, sleep: sleepContext}
+	if opts.RateLimit > 0 {
+		t.limiter = newRateLimiter(opts.RateLimit, opts.Burst)
+	}
+	return t
+}
+
+// NewTransportClient returns an http.Client sending requests through a
+// Transport configured with opts, failing over to the mirrors of opts
+func NewTransportClient(opts TransportOptions) *http.Client {
+	var base http.RoundTripper
+	if len(opts.Mirrors) > 0 {
+		base = NewMirrorTransport(nil, opts.Mirrors)
+	}
+	return &http.Client{
+		Transport: NewTransport(base, opts),
+		Timeout:   opts.Timeout,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			if err := t.limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		retry, delay := t.shouldRetry(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rewind returns req with a fresh copy of its body for retries
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	out := req.Clone(req.Context())
+	out.Body = body
+	return out, nil
+}
+
+// shouldRetry decides whether an attempt failed transiently and how long to
+// wait before the next one
+func (t *Transport) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) (bool, time.Duration) {
+	if attempt >= t.opts.MaxRetries || req.Context().Err() != nil {
+		return false, 0
+	}
+	// a request body that cannot be replayed cannot be retried
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false, 0
+	}
+
+	if err != nil {
+		// only idempotent requests are retried when the server may have
+		// processed them before the connection failed
+		if !isIdempotent(req.Method) || !isTransientError(err) {
+			return false, 0
+		}
+		return true, t.backoff(attempt)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// the server refused the request, so even non-idempotent ones are safe
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		if !isIdempotent(req.Method) {
+			return false, 0
+		}
+	default:
+		return false, 0
+	}
+
+	delay := t.backoff(attempt)
+	if after, ok := retryAfter(resp); ok {
+		delay = after
+		if t.opts.MaxRetryDelay > 0 && delay > t.opts.MaxRetryDelay {
+			delay = t.opts.MaxRetryDelay
+		}
+	}
+	return true, delay
+}
+
+// backoff returns the jittered delay before retry number attempt+1: a random
+// duration between half and all of the exponentially growing base delay
+func (t *Transport) backoff(attempt int) time.Duration {
+	delay := t.opts.RetryDelay << uint(attempt)
+	if t.opts.MaxRetryDelay > 0 && (delay > t.opts.MaxRetryDelay || delay <= 0) {
+		delay = t.opts.MaxRetryDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isTransientError reports network failures that may succeed when retried
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimiter is a token bucket refilled at rate tokens per second
+type rateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait blocks until a request may be sent
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
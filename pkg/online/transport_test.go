@@ -0,0 +1,158 @@
+package online
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testTransport returns a transport that records delays instead of sleeping
+func testTransport(opts TransportOptions) (*Transport, *[]time.Duration) {
+	delays := &[]time.Duration{}
+	t := NewTransport(nil, opts)
+	t.sleep = func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return nil
+	}
+	return t, delays
+}
+
+func TestTransport_RetriesTransientStatus(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt %d body = %q, want replayed payload", calls, body)
+		}
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	transport, delays := testTransport(TransportOptions{MaxRetries: 3, RetryDelay: 100 * time.Millisecond, MaxRetryDelay: time.Second})
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 3 {
+		t.Errorf("status = %d after %d calls, want 200 after 3", resp.StatusCode, calls)
+	}
+	if len(*delays) != 2 {
+		t.Fatalf("delays = %v, want 2", *delays)
+	}
+	// jittered delays stay between half and all of the doubled base delay
+	for i, d := range *delays {
+		base := 100 * time.Millisecond << uint(i)
+		if d < base/2 || d > base {
+			t.Errorf("delay %d = %v, want within [%v, %v]", i, d, base/2, base)
+		}
+	}
+}
+
+func TestTransport_GivesUp(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	transport, delays := testTransport(TransportOptions{MaxRetries: 2, RetryDelay: time.Millisecond, MaxRetryDelay: 5 * time.Second})
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests || calls != 3 {
+		t.Errorf("status = %d after %d calls, want 429 after 3", resp.StatusCode, calls)
+	}
+	for _, d := range *delays {
+		if d != 5*time.Second {
+			t.Errorf("Retry-After delay = %v, want capped at 5s", d)
+		}
+	}
+}
+
+func TestTransport_NoRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	transport, _ := testTransport(TransportOptions{MaxRetries: 3})
+	client := &http.Client{Transport: transport}
+
+	resp, _ := client.Get(server.URL)
+	resp.Body.Close()
+	resp, _ = client.Post(server.URL, "text/plain", strings.NewReader("x"))
+	resp.Body.Close()
+
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2: 500s and non-idempotent 502s are not retried", calls)
+	}
+}
+
+func TestTransport_NetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	transport, delays := testTransport(TransportOptions{MaxRetries: 2})
+	if _, err := (&http.Client{Transport: transport}).Get(url); err == nil {
+		t.Fatal("Get() to a closed server should fail")
+	}
+	if len(*delays) != 2 {
+		t.Errorf("refused connections should be retried, delays = %v", *delays)
+	}
+}
+
+func TestTransport_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := NewTransportClient(TransportOptions{RateLimit: 20, Burst: 1, Timeout: 5 * time.Second})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+	// the first request uses the burst, the next two wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests at 20/s took %v, want at least 100ms", elapsed)
+	}
+}
+
+func TestTransport_ContextCanceled(t *testing.T) {
+	limiter := newRateLimiter(0.001, 1)
+	limiter.tokens = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait() error = %v, want context.Canceled", err)
+	}
+}
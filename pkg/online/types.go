@@ -1,8 +1,10 @@
 package online
 
 import (
-	"cheat-go/pkg/apps"
+	"context"
 	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
 )
 
 type Repository struct {
@@ -25,6 +27,12 @@ type CheatSheet struct {
 	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" yaml:"updated_at"`
 	Tags        []string  `json:"tags" yaml:"tags"`
+	// Author published the sheet, such as the owner of its GitHub
+	// repository
+	Author string `json:"author,omitempty" yaml:"author,omitempty"`
+	// Version identifies the content of the sheet where the provider
+	// tracks one, as the blob SHA of a GitHub file
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
 }
 
 type SearchOptions struct {
@@ -51,4 +59,31 @@ type Client interface {
 	DownloadCheatSheet(id string) (*apps.App, error)
 	SubmitCheatSheet(sheet CheatSheet) error
 	RateCheatSheet(id string, rating float64) error
+	// ReportCheatSheet flags a sheet to the moderators of the community
+	// for one of ReportReasons
+	ReportCheatSheet(id, reason string) error
+}
+
+// ContextClient is implemented by clients whose requests can be bound to a
+// context, so they stop when what they are for is no longer wanted
+type ContextClient interface {
+	WithContext(ctx context.Context) Client
+}
+
+// WithContext returns client with its requests bound to ctx, or client
+// itself when it cannot bind them
+func WithContext(ctx context.Context, client Client) Client {
+	if bindable, ok := client.(ContextClient); ok {
+		return bindable.WithContext(ctx)
+	}
+	return client
+}
+
+// orBackground returns ctx, or the background context for clients never
+// bound to one
+func orBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
 }
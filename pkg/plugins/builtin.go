@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
 )
 
 type ScriptPlugin struct {
@@ -62,6 +66,31 @@ func (s *ScriptPlugin) Execute(ctx context.Context, args []string) error {
 	return nil
 }
 
+// Apps returns the apps listed in the plugin file, followed by those the
+// apps_command of its config prints as a YAML list
+func (s *ScriptPlugin) Apps() ([]apps.App, error) {
+	provided := append([]apps.App{}, s.metadata.Apps...)
+
+	command, _ := s.config["apps_command"].(string)
+	if command == "" {
+		return provided, nil
+	}
+	interpreter, _ := s.config["interpreter"].(string)
+	if interpreter == "" {
+		interpreter = "sh"
+	}
+
+	output, err := exec.Command(interpreter, "-c", command).Output()
+	if err != nil {
+		return nil, fmt.Errorf("apps_command failed: %w", err)
+	}
+	var generated []apps.App
+	if err := yaml.Unmarshal(output, &generated); err != nil {
+		return nil, fmt.Errorf("%w: apps_command output: %v", ErrInvalidPlugin, err)
+	}
+	return append(provided, generated...), nil
+}
+
 func (s *ScriptPlugin) Cleanup() error {
 	return nil
 }
@@ -0,0 +1,178 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Hook names a UI event plugins can react to
+type Hook string
+
+const (
+	HookStartup Hook = "startup"
+	HookSearch  Hook = "search"
+	HookSelect  Hook = "select"
+	HookQuit    Hook = "quit"
+)
+
+// Selection is the shortcut a select event is about
+type Selection struct {
+	App         string
+	Keys        string
+	Description string
+}
+
+// Event is a UI event passed to the hooks of plugins
+type Event struct {
+	Hook Hook
+	// Query is the confirmed search of a search event
+	Query string
+	// Selection is the selected shortcut of a select event
+	Selection Selection
+}
+
+// The hook interfaces are implemented by plugins that react to UI events.
+// A hook returns a message for the status line, or "" for none.
+type (
+	StartupHook interface {
+		OnStartup(ctx context.Context) (string, error)
+	}
+	SearchHook interface {
+		OnSearch(ctx context.Context, query string) (string, error)
+	}
+	SelectHook interface {
+		OnSelect(ctx context.Context, selection Selection) (string, error)
+	}
+	QuitHook interface {
+		OnQuit(ctx context.Context) (string, error)
+	}
+)
+
+// HookResult is what the hook of one plugin reported
+type HookResult struct {
+	Plugin  string
+	Message string
+	Err     error
+}
+
+// HookPlugins returns the enabled plugins with a hook for event, in name
+// order
+func (l *Loader) HookPlugins(hook Hook) []Plugin {
+	var hooked []Plugin
+	for _, loaded := range l.ListPlugins() {
+		if !loaded.Disabled && handles(loaded.Plugin, hook) {
+			hooked = append(hooked, loaded.Plugin)
+		}
+	}
+	return hooked
+}
+
+// handles reports whether plugin has a hook for event; script and Lua
+// plugins only have the hooks they declare
+func handles(plugin Plugin, hook Hook) bool {
+	switch p := plugin.(type) {
+	case *ScriptPlugin:
+		return p.metadata.Hooks[string(hook)] != ""
+	case *LuaPlugin:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.hooks[hook] != nil
+	}
+	switch hook {
+	case HookStartup:
+		_, ok := plugin.(StartupHook)
+		return ok
+	case HookSearch:
+		_, ok := plugin.(SearchHook)
+		return ok
+	case HookSelect:
+		_, ok := plugin.(SelectHook)
+		return ok
+	case HookQuit:
+		_, ok := plugin.(QuitHook)
+		return ok
+	}
+	return false
+}
+
+// RunHooks calls the hook for event of each plugin implementing it, in
+// order, and returns the results that have a message or error. It may run
+// outside the UI goroutine as it only touches the given plugins.
+func RunHooks(ctx context.Context, plugins []Plugin, event Event) []HookResult {
+	var results []HookResult
+	for _, plugin := range plugins {
+		result := HookResult{Plugin: plugin.Name()}
+		switch event.Hook {
+		case HookStartup:
+			if hook, ok := plugin.(StartupHook); ok {
+				result.Message, result.Err = hook.OnStartup(ctx)
+			}
+		case HookSearch:
+			if hook, ok := plugin.(SearchHook); ok {
+				result.Message, result.Err = hook.OnSearch(ctx, event.Query)
+			}
+		case HookSelect:
+			if hook, ok := plugin.(SelectHook); ok {
+				result.Message, result.Err = hook.OnSelect(ctx, event.Selection)
+			}
+		case HookQuit:
+			if hook, ok := plugin.(QuitHook); ok {
+				result.Message, result.Err = hook.OnQuit(ctx)
+			}
+		}
+		if result.Message != "" || result.Err != nil {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// The hooks of a script plugin run the commands under hooks: in its file.
+// Event details are passed in CHEATGO_* environment variables and the first
+// line printed becomes the status message.
+
+func (s *ScriptPlugin) OnStartup(ctx context.Context) (string, error) {
+	return s.runHook(ctx, Event{Hook: HookStartup})
+}
+
+func (s *ScriptPlugin) OnSearch(ctx context.Context, query string) (string, error) {
+	return s.runHook(ctx, Event{Hook: HookSearch, Query: query})
+}
+
+func (s *ScriptPlugin) OnSelect(ctx context.Context, selection Selection) (string, error) {
+	return s.runHook(ctx, Event{Hook: HookSelect, Selection: selection})
+}
+
+func (s *ScriptPlugin) OnQuit(ctx context.Context) (string, error) {
+	return s.runHook(ctx, Event{Hook: HookQuit})
+}
+
+// runHook runs the command of the plugin for event, if it has one
+func (s *ScriptPlugin) runHook(ctx context.Context, event Event) (string, error) {
+	command := s.metadata.Hooks[string(event.Hook)]
+	if command == "" {
+		return "", nil
+	}
+	interpreter, _ := s.config["interpreter"].(string)
+	if interpreter == "" {
+		interpreter = "sh"
+	}
+
+	cmd := exec.CommandContext(ctx, interpreter, "-c", command)
+	cmd.Env = append(os.Environ(),
+		"CHEATGO_HOOK="+string(event.Hook),
+		"CHEATGO_QUERY="+event.Query,
+		"CHEATGO_APP="+event.Selection.App,
+		"CHEATGO_KEYS="+event.Selection.Keys,
+		"CHEATGO_DESCRIPTION="+event.Selection.Description,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s hook failed: %w", event.Hook, err)
+	}
+	message, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return message, nil
+}
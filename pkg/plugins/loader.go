@@ -1,15 +1,21 @@
 package plugins
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
 )
 
 var (
@@ -17,18 +23,34 @@ var (
 	ErrPluginAlreadyRegistered = errors.New("plugin already registered")
 	ErrInvalidPlugin           = errors.New("invalid plugin")
 	ErrPluginLoadFailed        = errors.New("plugin load failed")
+	ErrPluginUntrusted         = errors.New("plugin could not be verified")
 )
 
 type Loader struct {
 	registry      *Registry
 	pluginDirs    []string
 	loadedPlugins map[string]*LoadedPlugin
+	disabled      map[string]bool
+	host          *Host
+	verifier      *signature.Verifier
+	approved      map[string]bool
 }
 
 type LoadedPlugin struct {
 	Plugin   Plugin
 	Metadata *Metadata
 	Path     string
+	// Disabled plugins are listed but not registered
+	Disabled bool
+	// Apps are the names of the apps the plugin added to the app registry
+	Apps []string
+	// LastError is the last error the plugin's apps or hooks returned
+	LastError error
+	// Untrusted is why a plugin failed signature verification. Such
+	// plugins are listed, disabled, without being run.
+	Untrusted error
+	// Sum is the SHA-256 sum of the plugin file
+	Sum string
 }
 
 func NewLoader(dirs ...string) *Loader {
@@ -40,7 +62,65 @@ func NewLoader(dirs ...string) *Loader {
 		registry:      NewRegistry(),
 		pluginDirs:    dirs,
 		loadedPlugins: make(map[string]*LoadedPlugin),
+		disabled:      make(map[string]bool),
+		host:          &Host{},
+		approved:      make(map[string]bool),
+	}
+}
+
+// SetHost gives Lua plugins loaded afterwards access to host. Fields set
+// on host later, such as a notes manager opened after loading, are seen
+// by the plugins too.
+func (l *Loader) SetHost(host *Host) {
+	l.host = host
+}
+
+// SetDisabled marks plugins that are listed but not registered when loaded
+func (l *Loader) SetDisabled(names []string) {
+	l.disabled = make(map[string]bool, len(names))
+	for _, name := range names {
+		l.disabled[name] = true
+	}
+}
+
+// SetVerifier makes plugins loaded afterwards need a signature file beside
+// them, named after the plugin file with ".minisig" appended, made with a
+// key trusted by verifier. Plugins whose SHA-256 sum is in approved were
+// chosen by the user to run without one. A verifier without keys checks
+// nothing.
+func (l *Loader) SetVerifier(verifier *signature.Verifier, approved []string) {
+	l.verifier = verifier
+	l.approved = make(map[string]bool, len(approved))
+	for _, sum := range approved {
+		l.approved[sum] = true
+	}
+}
+
+// Approve lets plugins with the SHA-256 sum run without a valid signature
+// once they are loaded again
+func (l *Loader) Approve(sum string) {
+	l.approved[sum] = true
+}
+
+// Fresh returns a loader without plugins searching the same directories,
+// with the same disabled plugins, host and verification. Plugins can be
+// loaded into it while the loader in use keeps serving, then swapped in.
+func (l *Loader) Fresh() *Loader {
+	fresh := NewLoader(l.pluginDirs...)
+	fresh.host = l.host
+	for name := range l.disabled {
+		fresh.disabled[name] = true
+	}
+	fresh.verifier = l.verifier
+	for sum := range l.approved {
+		fresh.approved[sum] = true
 	}
+	return fresh
+}
+
+// Dirs returns the directories searched for plugins, in priority order
+func (l *Loader) Dirs() []string {
+	return l.pluginDirs
 }
 
 func getDefaultPluginDirs() []string {
@@ -91,6 +171,10 @@ func (l *Loader) LoadFromDirectory(dir string) error {
 			if err := l.LoadScriptPlugin(path); err != nil {
 				continue
 			}
+		} else if strings.HasSuffix(entry.Name(), ".lua") {
+			if err := l.LoadLuaPlugin(path); err != nil {
+				continue
+			}
 		}
 	}
 
@@ -115,20 +199,123 @@ func (l *Loader) LoadScriptPlugin(path string) error {
 		return fmt.Errorf("failed to read plugin file %s: %w", path, err)
 	}
 
-	var metadata Metadata
-	if err := yaml.Unmarshal(data, &metadata); err != nil {
+	metadata, err := ParseMetadata(data)
+	if err != nil {
 		return fmt.Errorf("failed to parse plugin metadata %s: %w", path, err)
 	}
+	// plugins found earlier in the search path take precedence
+	if _, exists := l.loadedPlugins[metadata.Name]; exists {
+		return ErrPluginAlreadyRegistered
+	}
+	if err := l.verify(path, data); err != nil {
+		return l.block(metadata, path, data, err)
+	}
+
+	return l.add(NewScriptPlugin(*metadata, path), metadata, path)
+}
+
+// LoadLuaPlugin runs a Lua plugin, giving it access to the loader's host
+func (l *Loader) LoadLuaPlugin(path string) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read plugin file %s: %w", path, err)
+	}
+	// checked before running the script so a shadowed plugin has no effect
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if _, exists := l.loadedPlugins[name]; exists {
+		return ErrPluginAlreadyRegistered
+	}
+	if err := l.verify(path, source); err != nil {
+		return l.block(&Metadata{Name: name, Type: TypeLua}, path, source, err)
+	}
+	// disabled scripts are listed without running them
+	if l.disabled[name] {
+		l.loadedPlugins[name] = &LoadedPlugin{
+			Metadata: &Metadata{Name: name, Type: TypeLua},
+			Path:     path,
+			Disabled: true,
+			Sum:      Sum(source),
+		}
+		return nil
+	}
+
+	luaPlugin, err := NewLuaPlugin(path, source, l.host)
+	if err != nil {
+		return err
+	}
+	return l.add(luaPlugin, luaPlugin.Metadata(), path)
+}
+
+// verify checks the signature of the plugin file at path, holding data,
+// unless no key is trusted or the user approved the plugin
+func (l *Loader) verify(path string, data []byte) error {
+	if !l.verifier.Enabled() || l.approved[Sum(data)] {
+		return nil
+	}
+	sig, err := os.ReadFile(path + signature.Suffix)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read signature of %s: %w", path, err)
+	}
+	_, err = l.verifier.Verify(data, sig)
+	return err
+}
 
-	scriptPlugin := NewScriptPlugin(metadata, path)
+// block lists a plugin that failed verification, disabled and without
+// running it
+func (l *Loader) block(metadata *Metadata, path string, data []byte, err error) error {
+	l.loadedPlugins[metadata.Name] = &LoadedPlugin{
+		Metadata:  metadata,
+		Path:      path,
+		Disabled:  true,
+		Untrusted: err,
+		Sum:       Sum(data),
+	}
+	return fmt.Errorf("%w: %s: %w", ErrPluginUntrusted, metadata.Name, err)
+}
 
+// Sum returns the SHA-256 sum of a plugin file, identifying the plugins
+// the user approved
+func Sum(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// add records a loaded plugin and registers it unless it is disabled
+func (l *Loader) add(plugin Plugin, metadata *Metadata, path string) error {
 	l.loadedPlugins[metadata.Name] = &LoadedPlugin{
-		Plugin:   scriptPlugin,
-		Metadata: &metadata,
+		Plugin:   plugin,
+		Metadata: metadata,
 		Path:     path,
+		Disabled: l.disabled[metadata.Name],
+	}
+	if l.disabled[metadata.Name] {
+		return nil
 	}
 
-	return l.registry.Register(metadata.Name, scriptPlugin)
+	return l.registry.Register(metadata.Name, plugin)
+}
+
+// ParseMetadata decodes and validates a script plugin definition
+func ParseMetadata(data []byte) (*Metadata, error) {
+	var metadata Metadata
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+	if err := checkName(metadata.Name); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// checkName rejects plugin names that cannot be used as file names
+func checkName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: missing name", ErrInvalidPlugin)
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%w: invalid name %q", ErrInvalidPlugin, name)
+	}
+	return nil
 }
 
 func (l *Loader) GetPlugin(name string) (Plugin, error) {
@@ -140,20 +327,139 @@ func (l *Loader) ListPlugins() []*LoadedPlugin {
 	for _, p := range l.loadedPlugins {
 		plugins = append(plugins, p)
 	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Metadata.Name < plugins[j].Metadata.Name
+	})
 	return plugins
 }
 
+// LoadedPlugin returns a loaded plugin, including disabled ones
+func (l *Loader) LoadedPlugin(name string) (*LoadedPlugin, error) {
+	loaded, exists := l.loadedPlugins[name]
+	if !exists {
+		return nil, ErrPluginNotFound
+	}
+	return loaded, nil
+}
+
+// SetEnabled enables or disables a loaded plugin for the rest of the
+// session; disabled plugins stay listed but are no longer registered
+func (l *Loader) SetEnabled(name string, enabled bool) error {
+	loaded, exists := l.loadedPlugins[name]
+	if !exists {
+		return ErrPluginNotFound
+	}
+	if loaded.Disabled == !enabled {
+		return nil
+	}
+	if loaded.Untrusted != nil {
+		return fmt.Errorf("%w: %s: %w", ErrPluginUntrusted, name, loaded.Untrusted)
+	}
+
+	if enabled {
+		if loaded.Plugin == nil {
+			if err := l.runDisabled(loaded); err != nil {
+				return err
+			}
+		}
+		if err := l.registry.Register(name, loaded.Plugin); err != nil {
+			return err
+		}
+		delete(l.disabled, name)
+	} else {
+		if err := loaded.Plugin.Cleanup(); err != nil {
+			return fmt.Errorf("failed to cleanup plugin %s: %w", name, err)
+		}
+		if err := l.registry.Unregister(name); err != nil {
+			return err
+		}
+		l.disabled[name] = true
+	}
+	loaded.Disabled = !enabled
+	return nil
+}
+
+// runDisabled runs the script of a Lua plugin that was disabled when it
+// was loaded, provided its file is still the one checked then
+func (l *Loader) runDisabled(loaded *LoadedPlugin) error {
+	source, err := os.ReadFile(loaded.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read plugin file %s: %w", loaded.Path, err)
+	}
+	if Sum(source) != loaded.Sum {
+		return fmt.Errorf("%w: %s changed since it was loaded", ErrInvalidPlugin, loaded.Path)
+	}
+	luaPlugin, err := NewLuaPlugin(loaded.Path, source, l.host)
+	if err != nil {
+		return err
+	}
+	loaded.Plugin = luaPlugin
+	loaded.Metadata = luaPlugin.Metadata()
+	return nil
+}
+
 func (l *Loader) UnloadPlugin(name string) error {
 	if loaded, exists := l.loadedPlugins[name]; exists {
+		if loaded.Plugin == nil {
+			delete(l.loadedPlugins, name)
+			return nil
+		}
 		if err := loaded.Plugin.Cleanup(); err != nil {
 			return fmt.Errorf("failed to cleanup plugin %s: %w", name, err)
 		}
 		delete(l.loadedPlugins, name)
+		if loaded.Disabled {
+			return nil
+		}
 	}
 
 	return l.registry.Unregister(name)
 }
 
+// RegisterApps adds the apps of enabled cheat provider plugins to registry.
+// Apps the registry already has or the user stored keep their definition.
+func (l *Loader) RegisterApps(registry *apps.Registry) error {
+	var errs []error
+	for _, loaded := range l.ListPlugins() {
+		provider, ok := loaded.Plugin.(CheatProvider)
+		if !ok || loaded.Disabled {
+			continue
+		}
+		name := loaded.Metadata.Name
+
+		provided, err := provider.Apps()
+		if err != nil {
+			loaded.LastError = err
+			errs = append(errs, fmt.Errorf("plugin %s: %w", name, err))
+			continue
+		}
+		loaded.Apps = nil
+		for i := range provided {
+			app := provided[i]
+			if err := registry.ValidateApp(&app); err != nil {
+				loaded.LastError = err
+				errs = append(errs, fmt.Errorf("plugin %s: %w", name, err))
+				continue
+			}
+			if _, exists := registry.Get(app.Name); exists || registry.HasStoredApp(app.Name) {
+				continue
+			}
+
+			metadata := map[string]string{}
+			for k, v := range app.Metadata {
+				metadata[k] = v
+			}
+			metadata["source"] = "plugin"
+			metadata["plugin"] = name
+			app.Metadata = metadata
+
+			registry.Register(&app)
+			loaded.Apps = append(loaded.Apps, app.Name)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (l *Loader) ExportPluginInfo(w io.Writer) error {
 	info := make(map[string]*Metadata)
 	for name, loaded := range l.loadedPlugins {
@@ -2,10 +2,17 @@ package plugins
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
 )
 
 func TestNewLoader(t *testing.T) {
@@ -131,6 +138,289 @@ description: Unload test
 	}
 }
 
+func TestLoader_DisabledAndPrecedence(t *testing.T) {
+	userDir := t.TempDir()
+	systemDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(userDir, "a.yaml"), []byte("name: shared\nversion: 2.0.0\n"), 0644)
+	os.WriteFile(filepath.Join(systemDir, "a.yaml"), []byte("name: shared\nversion: 1.0.0\n"), 0644)
+	os.WriteFile(filepath.Join(systemDir, "off.yaml"), []byte("name: off\n"), 0644)
+
+	loader := NewLoader(userDir, systemDir)
+	loader.SetDisabled([]string{"off"})
+	loader.LoadAll()
+
+	shared, err := loader.LoadedPlugin("shared")
+	if err != nil || shared.Metadata.Version != "2.0.0" {
+		t.Errorf("Expected the first directory to win, got %+v (%v)", shared, err)
+	}
+
+	off, err := loader.LoadedPlugin("off")
+	if err != nil || !off.Disabled {
+		t.Fatalf("Expected disabled plugin to be listed, got %+v (%v)", off, err)
+	}
+	if _, err := loader.GetPlugin("off"); err != ErrPluginNotFound {
+		t.Errorf("Expected disabled plugin not to be registered, got %v", err)
+	}
+	if err := loader.UnloadPlugin("off"); err != nil {
+		t.Errorf("UnloadPlugin() of disabled plugin error = %v", err)
+	}
+
+	if _, err := ParseMetadata([]byte("version: 1.0.0\n")); !errors.Is(err, ErrInvalidPlugin) {
+		t.Errorf("Expected ErrInvalidPlugin for missing name, got %v", err)
+	}
+}
+
+func TestLoader_Fresh(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "on.yaml"), []byte("name: on\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "off.yaml"), []byte("name: off\n"), 0644)
+
+	loader := NewLoader(dir)
+	loader.SetDisabled([]string{"off"})
+	loader.LoadAll()
+	loader.UnloadPlugin("on")
+
+	fresh := loader.Fresh()
+	if len(fresh.ListPlugins()) != 0 {
+		t.Fatal("Expected a fresh loader without plugins")
+	}
+	fresh.LoadAll()
+	if _, err := fresh.GetPlugin("on"); err != nil {
+		t.Errorf("Expected the unloaded plugin to load again, got %v", err)
+	}
+	if off, err := fresh.LoadedPlugin("off"); err != nil || !off.Disabled {
+		t.Errorf("Expected the plugin to stay disabled, got %+v (%v)", off, err)
+	}
+	if _, err := loader.GetPlugin("on"); err != ErrPluginNotFound {
+		t.Error("Expected the original loader to be left alone")
+	}
+}
+
+func TestLoader_SetVerifier(t *testing.T) {
+	public, secret, err := signature.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, stranger, _ := signature.GenerateKey()
+
+	dir := t.TempDir()
+	write := func(name, content string, key *signature.SecretKey, signed string) {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if key != nil {
+			os.WriteFile(filepath.Join(dir, name+signature.Suffix), key.Sign([]byte(signed), ""), 0644)
+		}
+	}
+	write("signed.yaml", "name: signed\n", &secret, "name: signed\n")
+	write("unsigned.yaml", "name: unsigned\n", nil, "")
+	write("stranger.lua", "return {}\n", &stranger, "return {}\n")
+	write("altered.yaml", "name: altered\nhooks:\n  search: rm -rf ~\n", &secret, "name: altered\n")
+
+	verifier, _ := signature.NewVerifier([]string{public.String()})
+	loader := NewLoader(dir)
+	loader.SetVerifier(verifier, nil)
+	loader.LoadAll()
+
+	if _, err := loader.GetPlugin("signed"); err != nil {
+		t.Errorf("Signed plugin should be registered, got %v", err)
+	}
+	for name, want := range map[string]error{
+		"unsigned": signature.ErrUnsigned,
+		"stranger": signature.ErrUnknownKey,
+		"altered":  signature.ErrInvalidSignature,
+	} {
+		loaded, err := loader.LoadedPlugin(name)
+		if err != nil || !loaded.Disabled || !errors.Is(loaded.Untrusted, want) {
+			t.Errorf("Expected %s to be listed disabled as %v, got %+v (%v)", name, want, loaded, err)
+			continue
+		}
+		if _, err := loader.GetPlugin(name); err != ErrPluginNotFound {
+			t.Errorf("Untrusted plugin %s should not be registered, got %v", name, err)
+		}
+		if err := loader.SetEnabled(name, true); !errors.Is(err, ErrPluginUntrusted) {
+			t.Errorf("Enabling untrusted plugin %s should fail, got %v", name, err)
+		}
+	}
+
+	unsigned, _ := loader.LoadedPlugin("unsigned")
+	loader.Approve(unsigned.Sum)
+	fresh := loader.Fresh()
+	fresh.LoadAll()
+	if _, err := fresh.GetPlugin("unsigned"); err != nil {
+		t.Errorf("Approved plugin should be registered, got %v", err)
+	}
+	if err := fresh.UnloadPlugin("altered"); err != nil {
+		t.Errorf("UnloadPlugin() of untrusted plugin error = %v", err)
+	}
+
+	// without trusted keys nothing is verified
+	loader = NewLoader(dir)
+	loader.SetVerifier(nil, nil)
+	loader.LoadAll()
+	if _, err := loader.GetPlugin("altered"); err != nil {
+		t.Errorf("Plugins should load without trusted keys, got %v", err)
+	}
+}
+
+func TestLoader_RegisterApps(t *testing.T) {
+	dir := t.TempDir()
+
+	provider := `
+name: k8s-sheets
+apps:
+  - name: kubectl
+    description: Kubernetes CLI
+    shortcuts:
+      - keys: kubectl get pods
+        description: List pods
+  - name: vim
+    description: Replaces nothing
+    shortcuts:
+      - keys: x
+        description: ignored
+config:
+  apps_command: |
+    printf -- '- name: helm\n  description: Helm\n  shortcuts:\n    - keys: helm ls\n      description: List releases\n'
+`
+	os.WriteFile(filepath.Join(dir, "provider.yaml"), []byte(provider), 0644)
+	os.WriteFile(filepath.Join(dir, "off.yaml"), []byte("name: off\napps:\n  - name: offapp\n    description: Off\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("name: broken\napps:\n  - description: no name\n"), 0644)
+
+	loader := NewLoader(dir)
+	loader.SetDisabled([]string{"off"})
+	loader.LoadAll()
+
+	registry := apps.NewRegistry("")
+	builtinVim, _ := registry.Get("vim")
+	err := loader.RegisterApps(registry)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected an error for the invalid app of broken, got %v", err)
+	}
+
+	kubectl, ok := registry.Get("kubectl")
+	if !ok || kubectl.Metadata["source"] != "plugin" || kubectl.Metadata["plugin"] != "k8s-sheets" {
+		t.Fatalf("Expected kubectl from the plugin, got %+v", kubectl)
+	}
+	if _, ok := registry.Get("helm"); !ok {
+		t.Error("Expected the app printed by apps_command")
+	}
+	if vim, _ := registry.Get("vim"); vim != builtinVim {
+		t.Error("Plugin apps should not replace apps of the registry")
+	}
+	if _, ok := registry.Get("offapp"); ok {
+		t.Error("Disabled plugins should not add apps")
+	}
+
+	loaded, _ := loader.LoadedPlugin("k8s-sheets")
+	if strings.Join(loaded.Apps, ",") != "kubectl,helm" {
+		t.Errorf("Expected the added apps to be recorded, got %v", loaded.Apps)
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	dir := t.TempDir()
+	hooked := `
+name: notify
+hooks:
+  search: echo "searched $CHEATGO_QUERY"; echo ignored
+  select: |
+    echo "$CHEATGO_APP $CHEATGO_KEYS: $CHEATGO_DESCRIPTION"
+  quit: exit 3
+`
+	os.WriteFile(filepath.Join(dir, "notify.yaml"), []byte(hooked), 0644)
+	os.WriteFile(filepath.Join(dir, "quiet.yaml"), []byte("name: quiet\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "off.yaml"), []byte("name: off\nhooks:\n  search: echo off\n"), 0644)
+
+	loader := NewLoader(dir)
+	loader.SetDisabled([]string{"off"})
+	loader.LoadAll()
+	ctx := context.Background()
+
+	if hooked := loader.HookPlugins(HookStartup); len(hooked) != 0 {
+		t.Errorf("No plugin has a startup hook, got %d", len(hooked))
+	}
+	searchers := loader.HookPlugins(HookSearch)
+	if len(searchers) != 1 || searchers[0].Name() != "notify" {
+		t.Fatalf("Expected only notify to hook searches, got %v", searchers)
+	}
+
+	results := RunHooks(ctx, searchers, Event{Hook: HookSearch, Query: "split"})
+	if len(results) != 1 || results[0].Message != "searched split" || results[0].Plugin != "notify" {
+		t.Errorf("Expected the first output line as message, got %+v", results)
+	}
+
+	selection := Selection{App: "vim", Keys: "dd", Description: "Delete line"}
+	results = RunHooks(ctx, loader.HookPlugins(HookSelect), Event{Hook: HookSelect, Selection: selection})
+	if len(results) != 1 || results[0].Message != "vim dd: Delete line" {
+		t.Errorf("Expected the selection in the environment, got %+v", results)
+	}
+
+	results = RunHooks(ctx, loader.HookPlugins(HookQuit), Event{Hook: HookQuit})
+	if len(results) != 1 || results[0].Err == nil {
+		t.Errorf("Expected the failed quit hook to be reported, got %+v", results)
+	}
+}
+
+func TestLoader_SetEnabled(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("name: a\n"), 0644)
+
+	loader := NewLoader(dir)
+	loader.SetDisabled([]string{"a"})
+	loader.LoadAll()
+
+	if err := loader.SetEnabled("a", true); err != nil {
+		t.Fatalf("SetEnabled(true) error = %v", err)
+	}
+	if _, err := loader.GetPlugin("a"); err != nil {
+		t.Errorf("Enabled plugin should be registered, got %v", err)
+	}
+
+	if err := loader.SetEnabled("a", false); err != nil {
+		t.Fatalf("SetEnabled(false) error = %v", err)
+	}
+	loaded, _ := loader.LoadedPlugin("a")
+	if _, err := loader.GetPlugin("a"); err != ErrPluginNotFound || !loaded.Disabled {
+		t.Errorf("Disabled plugin should be listed but not registered, got %v", err)
+	}
+	if err := loader.SetEnabled("a", false); err != nil {
+		t.Errorf("Disabling twice should be a no-op, got %v", err)
+	}
+
+	if err := loader.SetEnabled("missing", true); err != ErrPluginNotFound {
+		t.Errorf("Expected ErrPluginNotFound, got %v", err)
+	}
+}
+
+func TestLoader_DisabledLuaPluginDoesNotRun(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "noter.lua"), []byte("plugin = { version = \"2.0.0\" }\ncheat.note{ title = \"ran\" }\n"), 0644)
+	manager, err := notes.NewFileManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader(dir)
+	loader.SetHost(&Host{Notes: manager})
+	loader.SetDisabled([]string{"noter"})
+	loader.LoadAll()
+
+	if list, _ := manager.ListNotes(); len(list) != 0 {
+		t.Fatalf("the script of a disabled plugin ran and made %d notes", len(list))
+	}
+	loaded, err := loader.LoadedPlugin("noter")
+	if err != nil || !loaded.Disabled || loaded.Plugin != nil {
+		t.Fatalf("the disabled plugin should be listed without running, got %+v, %v", loaded, err)
+	}
+
+	if err := loader.SetEnabled("noter", true); err != nil {
+		t.Fatalf("SetEnabled(true) error = %v", err)
+	}
+	if list, _ := manager.ListNotes(); len(list) != 1 || loaded.Metadata.Version != "2.0.0" {
+		t.Errorf("enabling should run the script, notes %d, metadata %+v", len(list), loaded.Metadata)
+	}
+}
+
 func TestLoader_ExportPluginInfo(t *testing.T) {
 	tempDir := t.TempDir()
 
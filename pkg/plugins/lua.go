@@ -0,0 +1,495 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+)
+
+// TypeLua is the metadata type of plugins written in Lua
+const TypeLua = "lua"
+
+var (
+	ErrUnknownCommand = errors.New("unknown plugin command")
+	ErrNoHost         = errors.New("not available to plugins here")
+)
+
+// LoadTimeout bounds how long the script of a Lua plugin may run when it is
+// loaded, so a script that never returns fails instead of hanging startup
+var LoadTimeout = 5 * time.Second
+
+// Host is what Lua plugins can reach of the application through the cheat
+// module. Functions needing a nil field fail with ErrNoHost.
+type Host struct {
+	Apps  *apps.Registry
+	Notes notes.Manager
+}
+
+// LuaPlugin is a plugin written in Lua. The script runs once when it is
+// loaded; it describes the plugin in the global plugin table and declares
+// apps, commands and hooks with the functions of the cheat module:
+//
+//	plugin = { version = "1.0.0", description = "Extra git shortcuts" }
+//	cheat.provide{ name = "tig", shortcuts = { { keys = "j", description = "Down" } } }
+//	cheat.command("count", function(app) return #cheat.app(app).shortcuts end)
+//	cheat.on("select", function(s) cheat.note{ title = s.keys, app = s.app } end)
+//
+// The plugin is named after its file, so tig.lua is the plugin tig.
+type LuaPlugin struct {
+	metadata Metadata
+	path     string
+	host     *Host
+
+	// mu serializes calls into the interpreter, which is not safe for
+	// concurrent use while hooks run outside the UI goroutine
+	mu       sync.Mutex
+	state    *lua.LState
+	apps     []apps.App
+	commands map[string]*lua.LFunction
+	hooks    map[Hook]*lua.LFunction
+}
+
+// NewLuaPlugin runs the Lua source of the plugin at path, for LoadTimeout at
+// most. host may be nil or filled in later, as bindings only use it when
+// called.
+func NewLuaPlugin(path string, source []byte, host *Host) (*LuaPlugin, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	if host == nil {
+		host = &Host{}
+	}
+
+	p := &LuaPlugin{
+		metadata: Metadata{Name: name, Type: TypeLua},
+		path:     path,
+		host:     host,
+		state:    newSandbox(),
+		commands: make(map[string]*lua.LFunction),
+		hooks:    make(map[Hook]*lua.LFunction),
+	}
+	p.state.SetGlobal("cheat", p.module())
+
+	ctx, cancel := context.WithTimeout(context.Background(), LoadTimeout)
+	defer cancel()
+	p.state.SetContext(ctx)
+	chunk, err := p.state.LoadString(string(source))
+	if err == nil {
+		p.state.Push(chunk)
+		err = p.state.PCall(0, lua.MultRet, nil)
+	}
+	p.state.RemoveContext()
+	if err != nil {
+		p.state.Close()
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPlugin, filepath.Base(path), err)
+	}
+
+	if info, ok := p.state.GetGlobal("plugin").(*lua.LTable); ok {
+		p.metadata.Version = lua.LVAsString(info.RawGetString("version"))
+		p.metadata.Author = lua.LVAsString(info.RawGetString("author"))
+		p.metadata.Description = lua.LVAsString(info.RawGetString("description"))
+		if config, ok := luaToGo(info.RawGetString("config")).(map[string]interface{}); ok {
+			p.metadata.Config = config
+		}
+	}
+	return p, nil
+}
+
+// sandboxLibs are the Lua libraries plugins get: nothing reaching files,
+// processes or the environment
+var sandboxLibs = []struct {
+	name string
+	open lua.LGFunction
+}{
+	{lua.BaseLibName, lua.OpenBase},
+	{lua.TabLibName, lua.OpenTable},
+	{lua.StringLibName, lua.OpenString},
+	{lua.MathLibName, lua.OpenMath},
+}
+
+// newSandbox creates an interpreter with the base, table, string and math
+// libraries only, and without the base functions loading files
+func newSandbox() *lua.LState {
+	state := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range sandboxLibs {
+		state.Push(state.NewFunction(lib.open))
+		state.Push(lua.LString(lib.name))
+		state.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile"} {
+		state.SetGlobal(name, lua.LNil)
+	}
+	return state
+}
+
+// LuaFileMetadata describes the Lua plugin saved as name.lua from its file
+// name alone, without running the script, as for plugins not trusted yet
+func LuaFileMetadata(name string) (*Metadata, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	return &Metadata{Name: name, Type: TypeLua}, nil
+}
+
+// ParseLuaMetadata runs a Lua plugin saved as name.lua without a host and
+// returns its metadata
+func ParseLuaMetadata(name string, source []byte) (*Metadata, error) {
+	p, err := NewLuaPlugin(name+".lua", source, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer p.Cleanup()
+	return p.Metadata(), nil
+}
+
+func (p *LuaPlugin) Name() string        { return p.metadata.Name }
+func (p *LuaPlugin) Version() string     { return p.metadata.Version }
+func (p *LuaPlugin) Author() string      { return p.metadata.Author }
+func (p *LuaPlugin) Description() string { return p.metadata.Description }
+
+// Metadata returns a copy of what the script declared about itself
+func (p *LuaPlugin) Metadata() *Metadata {
+	metadata := p.metadata
+	return &metadata
+}
+
+func (p *LuaPlugin) Init(config map[string]interface{}) error {
+	if p.metadata.Config == nil {
+		p.metadata.Config = make(map[string]interface{})
+	}
+	for k, v := range config {
+		p.metadata.Config[k] = v
+	}
+	return nil
+}
+
+// Execute runs the command named by the first argument with the rest
+func (p *LuaPlugin) Execute(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: none given (available: %v)", ErrUnknownCommand, p.Commands())
+	}
+	_, err := p.RunCommand(ctx, args[0], args[1:])
+	return err
+}
+
+// Commands returns the names of the commands the script declared, sorted
+func (p *LuaPlugin) Commands() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	names := make([]string, 0, len(p.commands))
+	for name := range p.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunCommand calls a declared command with string arguments and returns
+// what it returned, converted to a string
+func (p *LuaPlugin) RunCommand(ctx context.Context, name string, args []string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fn, ok := p.commands[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownCommand, name)
+	}
+	values := make([]lua.LValue, len(args))
+	for i, arg := range args {
+		values[i] = lua.LString(arg)
+	}
+	return p.call(ctx, fn, values...)
+}
+
+// Apps returns the apps the script provided
+func (p *LuaPlugin) Apps() ([]apps.App, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]apps.App{}, p.apps...), nil
+}
+
+func (p *LuaPlugin) OnStartup(ctx context.Context) (string, error) {
+	return p.runHook(ctx, HookStartup, nil)
+}
+
+func (p *LuaPlugin) OnSearch(ctx context.Context, query string) (string, error) {
+	return p.runHook(ctx, HookSearch, func(L *lua.LState) lua.LValue {
+		return lua.LString(query)
+	})
+}
+
+func (p *LuaPlugin) OnSelect(ctx context.Context, selection Selection) (string, error) {
+	return p.runHook(ctx, HookSelect, func(L *lua.LState) lua.LValue {
+		table := L.NewTable()
+		table.RawSetString("app", lua.LString(selection.App))
+		table.RawSetString("keys", lua.LString(selection.Keys))
+		table.RawSetString("description", lua.LString(selection.Description))
+		return table
+	})
+}
+
+func (p *LuaPlugin) OnQuit(ctx context.Context) (string, error) {
+	return p.runHook(ctx, HookQuit, nil)
+}
+
+func (p *LuaPlugin) Cleanup() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state.Close()
+	return nil
+}
+
+// runHook calls the function registered for hook, if any, with the
+// argument arg builds
+func (p *LuaPlugin) runHook(ctx context.Context, hook Hook, arg func(L *lua.LState) lua.LValue) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fn := p.hooks[hook]
+	if fn == nil {
+		return "", nil
+	}
+	var args []lua.LValue
+	if arg != nil {
+		args = append(args, arg(p.state))
+	}
+	return p.call(ctx, fn, args...)
+}
+
+// call runs fn with mu held until it returns or ctx is done and converts
+// its result to a string; nil becomes ""
+func (p *LuaPlugin) call(ctx context.Context, fn *lua.LFunction, args ...lua.LValue) (string, error) {
+	p.state.SetContext(ctx)
+	defer p.state.RemoveContext()
+
+	if err := p.state.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, args...); err != nil {
+		return "", err
+	}
+	result := p.state.Get(-1)
+	p.state.Pop(1)
+	if result == lua.LNil {
+		return "", nil
+	}
+	return strings.TrimSpace(result.String()), nil
+}
+
+// module builds the cheat table of bindings the script calls. They run
+// inside the interpreter, so with mu held once the plugin is loaded.
+func (p *LuaPlugin) module() *lua.LTable {
+	return p.state.SetFuncs(p.state.NewTable(), map[string]lua.LGFunction{
+		"apps":    p.luaApps,
+		"app":     p.luaApp,
+		"search":  p.luaSearch,
+		"provide": p.luaProvide,
+		"note":    p.luaNote,
+		"command": p.luaCommand,
+		"on":      p.luaOn,
+	})
+}
+
+// cheat.apps() returns the names of the registered apps, sorted
+func (p *LuaPlugin) luaApps(L *lua.LState) int {
+	registry := p.registry(L)
+	names := registry.List()
+	sort.Strings(names)
+
+	list := L.NewTable()
+	for _, name := range names {
+		list.Append(lua.LString(name))
+	}
+	L.Push(list)
+	return 1
+}
+
+// cheat.app(name) returns an app with its shortcuts, or nil
+func (p *LuaPlugin) luaApp(L *lua.LState) int {
+	app, ok := p.registry(L).Get(L.CheckString(1))
+	if !ok {
+		L.Push(lua.LNil)
+		return 1
+	}
+
+	table := L.NewTable()
+	table.RawSetString("name", lua.LString(app.Name))
+	table.RawSetString("description", lua.LString(app.Description))
+	table.RawSetString("version", lua.LString(app.Version))
+	table.RawSetString("categories", stringList(L, app.Categories))
+	shortcuts := L.NewTable()
+	for _, shortcut := range app.Shortcuts {
+		shortcuts.Append(shortcutTable(L, app.Name, shortcut))
+	}
+	table.RawSetString("shortcuts", shortcuts)
+	L.Push(table)
+	return 1
+}
+
+// cheat.search(query) returns the matching shortcuts of all apps
+func (p *LuaPlugin) luaSearch(L *lua.LState) int {
+	results := p.registry(L).SearchShortcuts(L.CheckString(1))
+
+	list := L.NewTable()
+	for _, result := range results {
+		list.Append(shortcutTable(L, result.AppName, result.Shortcut))
+	}
+	L.Push(list)
+	return 1
+}
+
+// cheat.provide{name=..., shortcuts={...}} adds an app to those the plugin
+// provides; it is validated when registered
+func (p *LuaPlugin) luaProvide(L *lua.LState) int {
+	table := L.CheckTable(1)
+	app := apps.App{
+		Name:        lua.LVAsString(table.RawGetString("name")),
+		Description: lua.LVAsString(table.RawGetString("description")),
+		Version:     lua.LVAsString(table.RawGetString("version")),
+		Categories:  luaStrings(table.RawGetString("categories")),
+	}
+	if shortcuts, ok := table.RawGetString("shortcuts").(*lua.LTable); ok {
+		shortcuts.ForEach(func(_, value lua.LValue) {
+			fields, ok := value.(*lua.LTable)
+			if !ok {
+				return
+			}
+			app.Shortcuts = append(app.Shortcuts, apps.Shortcut{
+				Keys:        lua.LVAsString(fields.RawGetString("keys")),
+				Description: lua.LVAsString(fields.RawGetString("description")),
+				Category:    lua.LVAsString(fields.RawGetString("category")),
+				Tags:        luaStrings(fields.RawGetString("tags")),
+				Platform:    lua.LVAsString(fields.RawGetString("platform")),
+			})
+		})
+	}
+	p.apps = append(p.apps, app)
+	return 0
+}
+
+// cheat.note{title=..., content=..., app=..., category=..., tags={...}}
+// creates a note and returns its id
+func (p *LuaPlugin) luaNote(L *lua.LState) int {
+	if p.host.Notes == nil {
+		L.RaiseError("cheat.note: notes are %v", ErrNoHost)
+	}
+	table := L.CheckTable(1)
+	note := &notes.Note{
+		Title:    lua.LVAsString(table.RawGetString("title")),
+		Content:  lua.LVAsString(table.RawGetString("content")),
+		AppName:  lua.LVAsString(table.RawGetString("app")),
+		Category: lua.LVAsString(table.RawGetString("category")),
+		Tags:     luaStrings(table.RawGetString("tags")),
+	}
+	if note.Title == "" {
+		L.ArgError(1, "title is required")
+	}
+	if err := p.host.Notes.CreateNote(note); err != nil {
+		L.RaiseError("cheat.note: %v", err)
+	}
+	L.Push(lua.LString(note.ID))
+	return 1
+}
+
+// cheat.command(name, fn) declares a command run with its arguments
+func (p *LuaPlugin) luaCommand(L *lua.LState) int {
+	name := L.CheckString(1)
+	if strings.TrimSpace(name) == "" {
+		L.ArgError(1, "empty command name")
+	}
+	p.commands[name] = L.CheckFunction(2)
+	return 0
+}
+
+// cheat.on(hook, fn) calls fn on a UI event: startup, search (with the
+// query), select (with the shortcut) or quit. A string it returns is shown
+// in the status line.
+func (p *LuaPlugin) luaOn(L *lua.LState) int {
+	hook := Hook(L.CheckString(1))
+	switch hook {
+	case HookStartup, HookSearch, HookSelect, HookQuit:
+	default:
+		L.ArgError(1, fmt.Sprintf("unknown hook %q", hook))
+	}
+	p.hooks[hook] = L.CheckFunction(2)
+	return 0
+}
+
+// registry returns the app registry or raises an error in the script
+func (p *LuaPlugin) registry(L *lua.LState) *apps.Registry {
+	if p.host.Apps == nil {
+		L.RaiseError("apps are %v", ErrNoHost)
+	}
+	return p.host.Apps
+}
+
+func shortcutTable(L *lua.LState, app string, shortcut apps.Shortcut) *lua.LTable {
+	table := L.NewTable()
+	table.RawSetString("app", lua.LString(app))
+	table.RawSetString("keys", lua.LString(shortcut.Keys))
+	table.RawSetString("description", lua.LString(shortcut.Description))
+	table.RawSetString("category", lua.LString(shortcut.Category))
+	table.RawSetString("tags", stringList(L, shortcut.Tags))
+	return table
+}
+
+func stringList(L *lua.LState, items []string) *lua.LTable {
+	list := L.NewTable()
+	for _, item := range items {
+		list.Append(lua.LString(item))
+	}
+	return list
+}
+
+// luaStrings returns the string items of a Lua list, or nil for other values
+func luaStrings(value lua.LValue) []string {
+	list, ok := value.(*lua.LTable)
+	if !ok {
+		return nil
+	}
+	var items []string
+	list.ForEach(func(_, item lua.LValue) {
+		items = append(items, lua.LVAsString(item))
+	})
+	return items
+}
+
+// luaToGo converts a Lua value to the types YAML decoding produces: tables
+// with only list items become slices and other tables maps
+func luaToGo(value lua.LValue) interface{} {
+	switch v := value.(type) {
+	case lua.LBool:
+		return bool(v)
+	case lua.LString:
+		return string(v)
+	case lua.LNumber:
+		if f := float64(v); f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return int(f)
+		}
+		return float64(v)
+	case *lua.LTable:
+		if n := v.Len(); n > 0 {
+			items := make([]interface{}, 0, n)
+			for i := 1; i <= n; i++ {
+				items = append(items, luaToGo(v.RawGetInt(i)))
+			}
+			return items
+		}
+		fields := make(map[string]interface{})
+		v.ForEach(func(key, item lua.LValue) {
+			fields[lua.LVAsString(key)] = luaToGo(item)
+		})
+		return fields
+	}
+	return nil
+}
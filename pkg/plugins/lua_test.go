@@ -0,0 +1,192 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+)
+
+const luaPluginSource = `
+plugin = {
+  version = "1.0.0",
+  author = "test",
+  description = "Lua test plugin",
+  config = { width = 80, verbose = true },
+}
+
+cheat.provide{
+  name = "lua-app",
+  description = "Provided from Lua",
+  categories = { "Motion" },
+  shortcuts = {
+    { keys = "j", description = "Down", category = "Motion" },
+  },
+}
+
+cheat.command("count", function(name)
+  local app = cheat.app(name)
+  if app == nil then error("no app " .. name) end
+  return #app.shortcuts
+end)
+
+cheat.command("remember", function(keys)
+  return cheat.note{ title = "Remember " .. keys, app = "vim", tags = { "lua" } }
+end)
+
+cheat.command("spin", function()
+  while true do end
+end)
+
+cheat.on("search", function(query)
+  return "searched " .. query .. " in " .. #cheat.search(query) .. " shortcuts"
+end)
+
+cheat.on("select", function(s)
+  return s.app .. ":" .. s.keys
+end)
+`
+
+func TestLuaPlugin(t *testing.T) {
+	registry := apps.NewRegistry("")
+	manager, err := notes.NewFileManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := &Host{}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "extras.lua"), []byte(luaPluginSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loader := NewLoader(dir)
+	loader.SetHost(host)
+	loader.LoadAll()
+
+	loaded, err := loader.LoadedPlugin("extras")
+	if err != nil {
+		t.Fatalf("the plugin should be named after its file: %v", err)
+	}
+	metadata := loaded.Metadata
+	if metadata.Type != TypeLua || metadata.Version != "1.0.0" || metadata.Description != "Lua test plugin" {
+		t.Errorf("metadata = %+v", metadata)
+	}
+	if metadata.Config["width"] != 80 || metadata.Config["verbose"] != true {
+		t.Errorf("config = %v", metadata.Config)
+	}
+
+	// the host is filled in after loading, as in the TUI
+	host.Apps = registry
+	host.Notes = manager
+
+	if err := loader.RegisterApps(registry); err != nil {
+		t.Fatalf("RegisterApps() error = %v", err)
+	}
+	if app, ok := registry.Get("lua-app"); !ok || len(app.Shortcuts) != 1 || app.Metadata["plugin"] != "extras" {
+		t.Errorf("provided app = %+v", app)
+	}
+
+	plugin := loaded.Plugin.(*LuaPlugin)
+	if got := strings.Join(plugin.Commands(), ","); got != "count,remember,spin" {
+		t.Errorf("Commands() = %s", got)
+	}
+
+	ctx := context.Background()
+	if out, err := plugin.RunCommand(ctx, "count", []string{"lua-app"}); err != nil || out != "1" {
+		t.Errorf("count = %q, %v", out, err)
+	}
+	if _, err := plugin.RunCommand(ctx, "count", []string{"missing"}); err == nil || !strings.Contains(err.Error(), "no app missing") {
+		t.Errorf("a Lua error should be returned, got %v", err)
+	}
+	if _, err := plugin.RunCommand(ctx, "unknown", nil); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("unknown command error = %v", err)
+	}
+
+	id, err := plugin.RunCommand(ctx, "remember", []string{"dd"})
+	if err != nil {
+		t.Fatalf("remember error = %v", err)
+	}
+	note, err := manager.GetNote(id)
+	if err != nil || note.Title != "Remember dd" || note.AppName != "vim" || len(note.Tags) != 1 {
+		t.Errorf("created note = %+v, %v", note, err)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := plugin.RunCommand(timeout, "spin", nil); err == nil {
+		t.Error("a command should stop when its context is done")
+	}
+
+	if hooked := loader.HookPlugins(HookSearch); len(hooked) != 1 {
+		t.Errorf("the plugin should hook searches, got %d", len(hooked))
+	}
+	if hooked := loader.HookPlugins(HookQuit); len(hooked) != 0 {
+		t.Errorf("the plugin does not hook quit, got %d", len(hooked))
+	}
+	results := RunHooks(ctx, loader.HookPlugins(HookSelect), Event{Hook: HookSelect, Selection: Selection{App: "vim", Keys: "dd"}})
+	if len(results) != 1 || results[0].Message != "vim:dd" {
+		t.Errorf("select hook results = %+v", results)
+	}
+	if msg, err := plugin.OnSearch(ctx, "Down"); err != nil || !strings.HasPrefix(msg, "searched Down in ") {
+		t.Errorf("search hook = %q, %v", msg, err)
+	}
+}
+
+func TestLuaPlugin_Sandbox(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "escaped")
+	for name, source := range map[string]string{
+		"os":       `os.execute("touch ` + marker + `")`,
+		"io":       `io.open("` + marker + `", "w")`,
+		"loadfile": `loadfile("` + marker + `")`,
+		"dofile":   `dofile("` + marker + `")`,
+		"require":  `require("os")`,
+	} {
+		if _, err := NewLuaPlugin("sandbox.lua", []byte(source), nil); !errors.Is(err, ErrInvalidPlugin) {
+			t.Errorf("%s should not be available to plugins, got %v", name, err)
+		}
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("a plugin reached the file system")
+	}
+
+	p, err := NewLuaPlugin("libs.lua", []byte(`plugin = { version = string.upper("v") .. math.floor(1.5) .. table.concat({"a"}) }`), nil)
+	if err != nil || p.Version() != "V1a" {
+		t.Errorf("the string, math and table libraries should be available, got %v", err)
+	}
+}
+
+func TestLuaPlugin_Errors(t *testing.T) {
+	if _, err := NewLuaPlugin("broken.lua", []byte("plugin = {"), nil); !errors.Is(err, ErrInvalidPlugin) {
+		t.Errorf("a syntax error should be an invalid plugin, got %v", err)
+	}
+	if _, err := NewLuaPlugin("hooks.lua", []byte(`cheat.on("resize", function() end)`), nil); err == nil {
+		t.Error("an unknown hook should fail")
+	}
+
+	p, err := NewLuaPlugin("nohost.lua", []byte(`cheat.command("list", function() return #cheat.apps() end)`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Cleanup()
+	if _, err := p.RunCommand(context.Background(), "list", nil); err == nil || !strings.Contains(err.Error(), ErrNoHost.Error()) {
+		t.Errorf("bindings without a host should fail, got %v", err)
+	}
+
+	loadTimeout := LoadTimeout
+	LoadTimeout = 50 * time.Millisecond
+	defer func() { LoadTimeout = loadTimeout }()
+	if _, err := NewLuaPlugin("loop.lua", []byte("while true do end"), nil); !errors.Is(err, ErrInvalidPlugin) {
+		t.Errorf("a script that never returns should fail to load, got %v", err)
+	}
+
+	metadata, err := ParseLuaMetadata("tig", []byte(`plugin = { version = "2.0" }`))
+	if err != nil || metadata.Name != "tig" || metadata.Version != "2.0" {
+		t.Errorf("ParseLuaMetadata() = %+v, %v", metadata, err)
+	}
+}
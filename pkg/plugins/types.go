@@ -1,8 +1,9 @@
 package plugins
 
 import (
-	"cheat-go/pkg/apps"
 	"context"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
 )
 
 type Plugin interface {
@@ -21,6 +22,13 @@ type AppProviderPlugin interface {
 	LoadApp(name string) (*apps.App, error)
 }
 
+// CheatProvider is a plugin adding cheat sheets of its own, which become
+// apps of the registry at startup
+type CheatProvider interface {
+	Plugin
+	Apps() ([]apps.App, error)
+}
+
 type TransformPlugin interface {
 	Plugin
 	Transform(app *apps.App) (*apps.App, error)
@@ -45,6 +53,10 @@ type Metadata struct {
 	Description string                 `json:"description" yaml:"description"`
 	Type        string                 `json:"type" yaml:"type"`
 	Config      map[string]interface{} `json:"config" yaml:"config"`
+	// Apps are cheat sheets the plugin provides
+	Apps []apps.App `json:"apps,omitempty" yaml:"apps,omitempty"`
+	// Hooks maps UI events such as "search" to shell commands to run
+	Hooks map[string]string `json:"hooks,omitempty" yaml:"hooks,omitempty"`
 }
 
 type Registry struct {
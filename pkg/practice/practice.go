@@ -0,0 +1,275 @@
+// Package practice keeps spaced-repetition statistics of the shortcuts a
+// user is quizzed on.
+package practice
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+// cardsKey is the practice document holding every card
+const cardsKey = "cards"
+
+// Intervals are how long a card rests after a correct answer in each
+// Leitner box. A wrong answer sends the card back to box 0, which is due
+// again right away.
+var Intervals = []time.Duration{
+	0,
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	14 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+}
+
+// Card is the review state of one shortcut of an app
+type Card struct {
+	App        string    `json:"app"`
+	Keys       string    `json:"keys"`
+	Category   string    `json:"category,omitempty"`
+	Box        int       `json:"box"`
+	Due        time.Time `json:"due"`
+	Reviews    int       `json:"reviews"`
+	Correct    int       `json:"correct"`
+	Streak     int       `json:"streak"`
+	LastReview time.Time `json:"last_review"`
+}
+
+// Accuracy returns the share of correct answers, or 0 before any review
+func (c Card) Accuracy() float64 {
+	if c.Reviews == 0 {
+		return 0
+	}
+	return float64(c.Correct) / float64(c.Reviews)
+}
+
+// Question is a shortcut that can be asked
+type Question struct {
+	App      string
+	Shortcut apps.Shortcut
+}
+
+// key identifies the card of the question
+func (q Question) key() string {
+	return cardKey(q.App, q.Shortcut.Keys)
+}
+
+// Tracker schedules questions and records answers. Cards are kept in the
+// practice collection of a store; a nil store keeps them in memory only.
+type Tracker struct {
+	mu    sync.Mutex
+	store storage.Storage
+	cards map[string]*Card
+	days  map[string]*Day
+	last  string
+	now   func() time.Time
+}
+
+// NewTracker loads the cards and daily reviews kept in store
+func NewTracker(store storage.Storage) (*Tracker, error) {
+	t := &Tracker{
+		store: store,
+		cards: make(map[string]*Card),
+		days:  make(map[string]*Day),
+		now:   time.Now,
+	}
+	if store == nil {
+		return t, nil
+	}
+
+	var cards []*Card
+	if err := storage.GetJSON(store, storage.CollectionPractice, cardsKey, &cards); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("failed to load practice stats: %w", err)
+	}
+	for _, card := range cards {
+		t.cards[cardKey(card.App, card.Keys)] = card
+	}
+
+	var days []*Day
+	if err := storage.GetJSON(store, storage.CollectionPractice, daysKey, &days); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("failed to load practice stats: %w", err)
+	}
+	for _, day := range days {
+		t.days[day.Date] = day
+	}
+	return t, nil
+}
+
+// SetClock replaces the clock used to schedule reviews
+func (t *Tracker) SetClock(now func() time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.now = now
+}
+
+// Card returns the card of a shortcut, if it was ever reviewed
+func (t *Tracker) Card(app, keys string) (Card, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	card, ok := t.cards[cardKey(app, keys)]
+	if !ok {
+		return Card{}, false
+	}
+	return *card, true
+}
+
+// Cards returns every card sorted by app and keys
+func (t *Tracker) Cards() []Card {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cards := make([]Card, 0, len(t.cards))
+	for _, card := range t.cards {
+		cards = append(cards, *card)
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		if cards[i].App != cards[j].App {
+			return cards[i].App < cards[j].App
+		}
+		return cards[i].Keys < cards[j].Keys
+	})
+	return cards
+}
+
+// Next picks the question to ask among questions: a random due card, else
+// a random shortcut never asked, else the card due soonest. The question
+// asked last is only repeated when there is no other.
+func (t *Tracker) Next(questions []Question) (Question, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(questions) > 1 {
+		others := make([]Question, 0, len(questions))
+		for _, q := range questions {
+			if q.key() != t.last {
+				others = append(others, q)
+			}
+		}
+		questions = others
+	}
+	if len(questions) == 0 {
+		return Question{}, false
+	}
+
+	now := t.now()
+	var due, fresh []Question
+	var soonest *Question
+	for i, q := range questions {
+		card, ok := t.cards[q.key()]
+		switch {
+		case !ok:
+			fresh = append(fresh, q)
+		case !card.Due.After(now):
+			due = append(due, q)
+		case soonest == nil || card.Due.Before(t.cards[soonest.key()].Due):
+			soonest = &questions[i]
+		}
+	}
+
+	var next Question
+	switch {
+	case len(due) > 0:
+		next = due[rand.Intn(len(due))]
+	case len(fresh) > 0:
+		next = fresh[rand.Intn(len(fresh))]
+	default:
+		next = *soonest
+	}
+	t.last = next.key()
+	return next, true
+}
+
+// Record updates the card of q after an answer and saves every card
+func (t *Tracker) Record(q Question, correct bool) (Card, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	card, ok := t.cards[q.key()]
+	if !ok {
+		card = &Card{App: q.App, Keys: q.Shortcut.Keys}
+		t.cards[q.key()] = card
+	}
+	card.Category = q.Shortcut.Category
+
+	now := t.now()
+	card.Reviews++
+	card.LastReview = now
+	if correct {
+		card.Correct++
+		card.Streak++
+		if card.Box < len(Intervals)-1 {
+			card.Box++
+		}
+	} else {
+		card.Streak = 0
+		card.Box = 0
+	}
+	card.Due = now.Add(Intervals[card.Box])
+	t.recordDay(now, correct)
+
+	return *card, t.save()
+}
+
+// save writes every card and day to the store
+func (t *Tracker) save() error {
+	if t.store == nil {
+		return nil
+	}
+	cards := make([]*Card, 0, len(t.cards))
+	for _, card := range t.cards {
+		cards = append(cards, card)
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		return cardKey(cards[i].App, cards[i].Keys) < cardKey(cards[j].App, cards[j].Keys)
+	})
+	if err := storage.PutJSON(t.store, storage.CollectionPractice, cardsKey, cards); err != nil {
+		return fmt.Errorf("failed to save practice stats: %w", err)
+	}
+
+	days := make([]*Day, 0, len(t.days))
+	for _, day := range t.days {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	if err := storage.PutJSON(t.store, storage.CollectionPractice, daysKey, days); err != nil {
+		return fmt.Errorf("failed to save practice stats: %w", err)
+	}
+	return nil
+}
+
+// Questions returns a question for every shortcut of appNames
+func Questions(registry *apps.Registry, appNames []string) []Question {
+	var questions []Question
+	for _, name := range appNames {
+		app, ok := registry.Get(name)
+		if !ok {
+			continue
+		}
+		for _, shortcut := range app.Shortcuts {
+			questions = append(questions, Question{App: name, Shortcut: shortcut})
+		}
+	}
+	return questions
+}
+
+// CheckAnswer reports whether answer names keys. Chords written in another
+// notation count, so "C-x" answers "Ctrl+X"; otherwise keys are compared
+// case-sensitively, as G and g are different keys.
+func CheckAnswer(answer, keys string) bool {
+	normalize := func(s string) string {
+		return apps.FormatKeys(strings.Join(strings.Fields(s), " "), apps.NotationVerbose)
+	}
+	return answer != "" && normalize(answer) == normalize(keys)
+}
+
+// cardKey identifies the card of a shortcut
+func cardKey(app, keys string) string {
+	return app + "\x00" + keys
+}
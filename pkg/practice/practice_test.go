@@ -0,0 +1,144 @@
+package practice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+func TestTracker_RecordSchedulesReviews(t *testing.T) {
+	store := storage.NewFileStorage(t.TempDir())
+	tracker, err := NewTracker(store)
+	if err != nil {
+		t.Fatalf("NewTracker() error = %v", err)
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker.SetClock(func() time.Time { return now })
+
+	q := Question{App: "vim", Shortcut: apps.Shortcut{Keys: "gg", Description: "top", Category: "general"}}
+	card, err := tracker.Record(q, true)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if card.Box != 1 || !card.Due.Equal(now.Add(Intervals[1])) || card.Streak != 1 {
+		t.Errorf("a correct answer should move the card to box 1, got %+v", card)
+	}
+
+	card, _ = tracker.Record(q, false)
+	if card.Box != 0 || !card.Due.Equal(now) || card.Streak != 0 || card.Reviews != 2 || card.Accuracy() != 0.5 {
+		t.Errorf("a wrong answer should reset the card, got %+v", card)
+	}
+
+	reloaded, err := NewTracker(store)
+	if err != nil {
+		t.Fatalf("NewTracker() error = %v", err)
+	}
+	if card, ok := reloaded.Card("vim", "gg"); !ok || card.Reviews != 2 || card.Category != "general" {
+		t.Errorf("cards should be persisted, got %+v, %v", card, ok)
+	}
+}
+
+func TestTracker_Next(t *testing.T) {
+	tracker, _ := NewTracker(nil)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker.SetClock(func() time.Time { return now })
+
+	learned := Question{App: "vim", Shortcut: apps.Shortcut{Keys: "gg"}}
+	fresh := Question{App: "vim", Shortcut: apps.Shortcut{Keys: "G"}}
+	due := Question{App: "vim", Shortcut: apps.Shortcut{Keys: "q"}}
+	tracker.Record(learned, true)
+	tracker.Record(due, false)
+
+	questions := []Question{learned, fresh, due}
+	if next, _ := tracker.Next(questions); next.Shortcut.Keys != "q" {
+		t.Errorf("the due card should be asked first, got %q", next.Shortcut.Keys)
+	}
+	if next, _ := tracker.Next(questions); next.Shortcut.Keys != "G" {
+		t.Errorf("a new shortcut should be asked next, got %q", next.Shortcut.Keys)
+	}
+	if next, _ := tracker.Next([]Question{learned}); next.Shortcut.Keys != "gg" {
+		t.Errorf("the only question should be asked even if not due, got %q", next.Shortcut.Keys)
+	}
+	if _, ok := tracker.Next(nil); ok {
+		t.Error("no questions should give no question")
+	}
+}
+
+func TestQuestions(t *testing.T) {
+	registry := apps.NewRegistry("")
+	questions := Questions(registry, []string{"zathura", "nano"})
+	if len(questions) == 0 {
+		t.Fatal("expected questions for zathura")
+	}
+	for _, q := range questions {
+		if q.App != "zathura" {
+			t.Errorf("unexpected question from %s", q.App)
+		}
+	}
+}
+
+func TestCheckAnswer(t *testing.T) {
+	tests := []struct {
+		answer, keys string
+		want         bool
+	}{
+		{"gg", "gg", true},
+		{" gg ", "gg", true},
+		{"g", "G", false},
+		{"C-x  C-s", "Ctrl+X Ctrl+S", true},
+		{"ctrl+b c", "C-b c", true},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := CheckAnswer(tt.answer, tt.keys); got != tt.want {
+			t.Errorf("CheckAnswer(%q, %q) = %v, want %v", tt.answer, tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestTracker_Stats(t *testing.T) {
+	tracker, _ := NewTracker(nil)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	clock := now
+	tracker.SetClock(func() time.Time { return clock })
+
+	gg := Question{App: "vim", Shortcut: apps.Shortcut{Keys: "gg", Category: "navigation"}}
+	quit := Question{App: "vim", Shortcut: apps.Shortcut{Keys: ":q"}}
+	detach := Question{App: "tmux", Shortcut: apps.Shortcut{Keys: "C-b d", Category: "session"}}
+
+	// practice on three days in a row after a gap
+	for _, day := range []time.Time{now.AddDate(0, 0, -6), now.AddDate(0, 0, -2), now.AddDate(0, 0, -1), now} {
+		clock = day
+		tracker.Record(gg, true)
+	}
+	tracker.Record(quit, false)
+	tracker.Record(quit, true)
+	tracker.Record(detach, false)
+
+	stats := tracker.Stats(2)
+	if stats.Reviews != 7 || stats.Correct != 5 || stats.Cards != 3 {
+		t.Errorf("unexpected totals %+v", stats)
+	}
+	if stats.CurrentStreak != 3 || stats.LongestStreak != 3 {
+		t.Errorf("streaks = %d/%d, want 3/3", stats.CurrentStreak, stats.LongestStreak)
+	}
+	if len(stats.Apps) != 2 || stats.Apps[0].Name != "tmux" || stats.Apps[1].Reviews != 6 {
+		t.Errorf("unexpected app groups %+v", stats.Apps)
+	}
+	if len(stats.Categories) != 3 || stats.Categories[0].Name != "general" {
+		t.Errorf("unexpected category groups %+v", stats.Categories)
+	}
+	if len(stats.Weakest) != 2 || stats.Weakest[0].Keys != "C-b d" || stats.Weakest[1].Keys != ":q" {
+		t.Errorf("unexpected weakest cards %+v", stats.Weakest)
+	}
+	if stats.Due != 1 {
+		t.Errorf("only the missed card should be due, got %d", stats.Due)
+	}
+
+	clock = now.AddDate(0, 0, 2)
+	if stats := tracker.Stats(0); stats.CurrentStreak != 0 || stats.LongestStreak != 3 {
+		t.Errorf("a missed day should end the streak, got %d/%d", stats.CurrentStreak, stats.LongestStreak)
+	}
+}
@@ -0,0 +1,180 @@
+package practice
+
+import (
+	"sort"
+	"time"
+)
+
+// daysKey is the practice document holding the reviews of each day
+const daysKey = "days"
+
+// dateLayout formats the dates of Day
+const dateLayout = "2006-01-02"
+
+// Day counts the reviews of one local calendar day
+type Day struct {
+	Date    string `json:"date"`
+	Reviews int    `json:"reviews"`
+	Correct int    `json:"correct"`
+}
+
+// Group sums the cards of an app or category
+type Group struct {
+	Name     string  `json:"name"`
+	Cards    int     `json:"cards"`
+	Reviews  int     `json:"reviews"`
+	Correct  int     `json:"correct"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+// Summary is an overview of all practice, as shown by the stats view
+type Summary struct {
+	Cards    int     `json:"cards"`
+	Due      int     `json:"due"`
+	Reviews  int     `json:"reviews"`
+	Correct  int     `json:"correct"`
+	Accuracy float64 `json:"accuracy"`
+	// CurrentStreak counts the days in a row, up to today or yesterday,
+	// with reviews; LongestStreak is the best such run
+	CurrentStreak int     `json:"current_streak"`
+	LongestStreak int     `json:"longest_streak"`
+	Apps          []Group `json:"apps"`
+	Categories    []Group `json:"categories"`
+	// Weakest are the reviewed cards with the lowest accuracy
+	Weakest []Card `json:"weakest"`
+	Days    []Day  `json:"days"`
+}
+
+// Stats summarizes every card, listing up to weakest weak cards
+func (t *Tracker) Stats(weakest int) Summary {
+	cards := t.Cards()
+
+	t.mu.Lock()
+	now := t.now()
+	days := make([]Day, 0, len(t.days))
+	for _, day := range t.days {
+		days = append(days, *day)
+	}
+	t.mu.Unlock()
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+
+	summary := Summary{Cards: len(cards), Days: days}
+	appGroups := map[string]*Group{}
+	categoryGroups := map[string]*Group{}
+	add := func(groups map[string]*Group, name string, card Card) {
+		group, ok := groups[name]
+		if !ok {
+			group = &Group{Name: name}
+			groups[name] = group
+		}
+		group.Cards++
+		group.Reviews += card.Reviews
+		group.Correct += card.Correct
+	}
+
+	for _, card := range cards {
+		summary.Reviews += card.Reviews
+		summary.Correct += card.Correct
+		if !card.Due.After(now) {
+			summary.Due++
+		}
+		add(appGroups, card.App, card)
+		category := card.Category
+		if category == "" {
+			category = "general"
+		}
+		add(categoryGroups, category, card)
+	}
+	summary.Accuracy = accuracy(summary.Correct, summary.Reviews)
+	summary.Apps = sortedGroups(appGroups)
+	summary.Categories = sortedGroups(categoryGroups)
+	summary.CurrentStreak, summary.LongestStreak = streaks(days, now)
+
+	reviewed := make([]Card, 0, len(cards))
+	for _, card := range cards {
+		if card.Reviews > 0 {
+			reviewed = append(reviewed, card)
+		}
+	}
+	sort.SliceStable(reviewed, func(i, j int) bool {
+		a, b := reviewed[i], reviewed[j]
+		if a.Accuracy() != b.Accuracy() {
+			return a.Accuracy() < b.Accuracy()
+		}
+		return a.Reviews > b.Reviews
+	})
+	if len(reviewed) > weakest {
+		reviewed = reviewed[:weakest]
+	}
+	summary.Weakest = reviewed
+
+	return summary
+}
+
+// recordDay counts a review on the day of now
+func (t *Tracker) recordDay(now time.Time, correct bool) {
+	date := now.Format(dateLayout)
+	day, ok := t.days[date]
+	if !ok {
+		day = &Day{Date: date}
+		t.days[date] = day
+	}
+	day.Reviews++
+	if correct {
+		day.Correct++
+	}
+}
+
+// streaks returns the current and longest runs of consecutive days in days,
+// which are sorted by date. The current run may end yesterday, as today's
+// practice may still come.
+func streaks(days []Day, now time.Time) (current, longest int) {
+	practiced := map[string]bool{}
+	run := 0
+	var previous time.Time
+	for _, day := range days {
+		date, err := time.ParseInLocation(dateLayout, day.Date, now.Location())
+		if err != nil || day.Reviews == 0 {
+			continue
+		}
+		practiced[day.Date] = true
+		if !previous.IsZero() && date.Equal(previous.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		previous = date
+		if run > longest {
+			longest = run
+		}
+	}
+
+	day := now
+	if !practiced[day.Format(dateLayout)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for practiced[day.Format(dateLayout)] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return current, longest
+}
+
+// sortedGroups returns groups sorted by name with their accuracy set
+func sortedGroups(groups map[string]*Group) []Group {
+	sorted := make([]Group, 0, len(groups))
+	for _, group := range groups {
+		group.Accuracy = accuracy(group.Correct, group.Reviews)
+		sorted = append(sorted, *group)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// accuracy returns correct/reviews, or 0 without reviews
+func accuracy(correct, reviews int) float64 {
+	if reviews == 0 {
+		return 0
+	}
+	return float64(correct) / float64(reviews)
+}
@@ -0,0 +1,240 @@
+package sheet
+
+import (
+	"fmt"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// itemKind tells the rows of a sheet apart
+type itemKind int
+
+const (
+	appHeading itemKind = iota
+	categoryHeading
+	entry
+)
+
+// item is a row of a sheet: the heading of an app or category, or a
+// shortcut whose keys and description are wrapped into lines
+type item struct {
+	kind itemKind
+	text string
+	keys []string
+	desc []string
+	// keyWidth is the width of the keys of the shortcuts of an app
+	keyWidth float64
+	height   float64
+	// space is left above the item, except at the top of a column
+	space float64
+}
+
+// column is the range of items in a column of a page
+type column struct {
+	start, end int
+}
+
+// layout places the items of a sheet set at one font size into the
+// columns of pages
+type layout struct {
+	opts        Options
+	size        float64
+	columnWidth float64
+	titleHeight float64
+	items       []item
+	pages       [][]column
+}
+
+// fit lays out appList at the largest font size that fits one page, or at
+// the chosen size on as many pages as needed when none does
+func fit(appList []*apps.App, opts Options) *layout {
+	for size := opts.FontSize; size >= opts.MinFontSize; size -= 0.5 {
+		if l := newLayout(appList, opts, size); len(l.pages) == 1 {
+			return l
+		}
+	}
+	return newLayout(appList, opts, opts.FontSize)
+}
+
+// newLayout lays out appList at font size
+func newLayout(appList []*apps.App, opts Options, size float64) *layout {
+	l := &layout{
+		opts:        opts,
+		size:        size,
+		columnWidth: (opts.Paper.Width - 2*margin - columnGap*float64(opts.Columns-1)) / float64(opts.Columns),
+	}
+	if opts.Title != "" {
+		l.titleHeight = 2*size*leading + size
+	}
+	for _, app := range appList {
+		l.addApp(app)
+	}
+	l.paginate()
+	return l
+}
+
+// addApp appends the heading, categories and shortcuts of app
+func (l *layout) addApp(app *apps.App) {
+	if len(app.Shortcuts) == 0 {
+		return
+	}
+	size := l.size
+	line := size * leading
+
+	// keys get the width of the longest, up to two fifths of the column
+	keyWidth := 0.0
+	for _, shortcut := range app.Shortcuts {
+		keyWidth = max(keyWidth, textWidth(mono, size, shortcut.Keys))
+	}
+	keyWidth = min(keyWidth, 0.4*l.columnWidth) + size
+
+	l.items = append(l.items, item{kind: appHeading, text: app.Name, height: 1.5*line + 2, space: size})
+	categories, groups := categoryGroups(app)
+	for _, category := range categories {
+		if category != "" {
+			l.items = append(l.items, item{kind: categoryHeading, text: category, height: line + 2, space: size / 2})
+		}
+		for _, shortcut := range groups[category] {
+			keys := wrapText(mono, size, shortcut.Keys, keyWidth-size)
+			desc := wrapText(regular, size, shortcut.Description, l.columnWidth-keyWidth)
+			l.items = append(l.items, item{
+				kind:     entry,
+				keys:     keys,
+				desc:     desc,
+				keyWidth: keyWidth,
+				height:   float64(max(len(keys), len(desc))) * line,
+			})
+		}
+	}
+}
+
+// columnHeight returns the height left for the columns of page
+func (l *layout) columnHeight(page int) float64 {
+	height := l.opts.Paper.Height - 2*margin
+	if page == 0 {
+		height -= l.titleHeight
+	}
+	return height
+}
+
+// paginate fills the columns of pages in turn, then balances the columns
+// of the last page so they end at about the same height
+func (l *layout) paginate() {
+	perPage := l.opts.Columns
+	columns := pack(l.items, 0, func(c int) float64 { return l.columnHeight(c / perPage) })
+
+	l.pages = nil
+	for len(columns) > perPage {
+		l.pages = append(l.pages, columns[:perPage])
+		columns = columns[perPage:]
+	}
+
+	last := len(l.pages)
+	start := columns[0].start
+	fits := func(limit float64) []column {
+		balanced := pack(l.items[start:], start, func(int) float64 { return limit })
+		if len(balanced) > perPage {
+			return nil
+		}
+		return balanced
+	}
+	low, high := 0.0, l.columnHeight(last)
+	for i := 0; i < 24; i++ {
+		if mid := (low + high) / 2; fits(mid) != nil {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	if balanced := fits(high); balanced != nil {
+		columns = balanced
+	}
+	l.pages = append(l.pages, columns)
+}
+
+// pack fills columns with items in turn, up to the height limit of each.
+// Headings are kept in the column of the shortcut that follows them, and an
+// item taller than a column gets one of its own. Columns index the items
+// from offset.
+func pack(items []item, offset int, limit func(column int) float64) []column {
+	var columns []column
+	start, used := 0, 0.0
+	for i := 0; i < len(items); {
+		// a heading needs room for the rows up to its first shortcut
+		need, top := 0.0, used == 0
+		for j := i; j < len(items); j++ {
+			need += items[j].height
+			if !top || j > i {
+				need += items[j].space
+			}
+			if items[j].kind == entry {
+				break
+			}
+		}
+
+		if !top && used+need > limit(len(columns)) {
+			columns = append(columns, column{start + offset, i + offset})
+			start, used = i, 0
+			continue
+		}
+		if !top {
+			used += items[i].space
+		}
+		used += items[i].height
+		i++
+	}
+	return append(columns, column{start + offset, len(items) + offset})
+}
+
+// draw renders the page numbered index
+func (l *layout) draw(index int) *page {
+	p := &page{}
+	size, line := l.size, l.size*leading
+	width, height := l.opts.Paper.Width, l.opts.Paper.Height
+	top := height - margin
+
+	if index == 0 && l.opts.Title != "" {
+		p.text(bold, 2*size, margin, baseline(top, 2*size), 0, l.opts.Title)
+		top -= l.titleHeight
+		p.line(margin, top+size/2, width-margin, top+size/2, 0.8, 0)
+	}
+
+	for c, col := range l.pages[index] {
+		x := margin + float64(c)*(l.columnWidth+columnGap)
+		y := top
+		for i := col.start; i < col.end; i++ {
+			it := l.items[i]
+			if i > col.start {
+				y -= it.space
+			}
+			switch it.kind {
+			case appHeading:
+				p.text(bold, 1.5*size, x, baseline(y, 1.5*size), 0, it.text)
+				p.line(x, y-it.height+1, x+l.columnWidth, y-it.height+1, 0.6, 0)
+			case categoryHeading:
+				p.rect(x, y-line-1, l.columnWidth, line+1, 0.88)
+				p.text(bold, size, x+2, baseline(y-0.5, size), 0.15, it.text)
+			case entry:
+				for n, keys := range it.keys {
+					p.text(mono, size, x, baseline(y-float64(n)*line, size), 0, keys)
+				}
+				for n, desc := range it.desc {
+					p.text(regular, size, x+it.keyWidth, baseline(y-float64(n)*line, size), 0.1, desc)
+				}
+			}
+			y -= it.height
+		}
+	}
+
+	if len(l.pages) > 1 {
+		footer := fmt.Sprintf("page %d of %d", index+1, len(l.pages))
+		footerSize := max(size-1, 5)
+		p.text(regular, footerSize, (width-textWidth(regular, footerSize, footer))/2, margin/2, 0.4, footer)
+	}
+	return p
+}
+
+// baseline returns where text of size sits in a line whose top is top
+func baseline(top, size float64) float64 {
+	return top - size*(leading-1)/2 - 0.8*size
+}
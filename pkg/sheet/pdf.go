@@ -0,0 +1,222 @@
+package sheet
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// font is one of the standard PDF fonts, which viewers provide so nothing
+// needs to be embedded
+type font int
+
+const (
+	regular font = iota
+	bold
+	mono
+)
+
+var fontNames = [...]string{"Helvetica", "Helvetica-Bold", "Courier-Bold"}
+
+// helveticaWidths and helveticaBoldWidths are the advance widths of the
+// printable ASCII characters, from space, in thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// winAnsi maps the characters of WinAnsiEncoding outside ASCII and Latin-1
+// to their byte
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// asciiArrows spell the arrows WinAnsiEncoding lacks
+var asciiArrows = map[rune]string{'←': "<-", '→': "->", '↑': "^", '↓': "v", '↔': "<->"}
+
+// encode converts s to WinAnsiEncoding, the encoding of the text of the
+// standard fonts, writing characters it lacks as "?"
+func encode(s string) []byte {
+	encoded := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case asciiArrows[r] != "":
+			encoded = append(encoded, asciiArrows[r]...)
+		case r == '\t':
+			encoded = append(encoded, ' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			encoded = append(encoded, byte(r))
+		default:
+			if b, ok := winAnsi[r]; ok {
+				encoded = append(encoded, b)
+			} else {
+				encoded = append(encoded, '?')
+			}
+		}
+	}
+	return encoded
+}
+
+// textWidth returns the width of s in points when set in f at size
+func textWidth(f font, size float64, s string) float64 {
+	width := 0
+	for _, b := range encode(s) {
+		switch {
+		case f == mono:
+			width += 600
+		case b >= 0x20 && b < 0x7f && f == bold:
+			width += helveticaBoldWidths[b-0x20]
+		case b >= 0x20 && b < 0x7f:
+			width += helveticaWidths[b-0x20]
+		default:
+			width += 556
+		}
+	}
+	return float64(width) * size / 1000
+}
+
+// wrapText breaks s into lines at most width wide, between words when it
+// can and inside words longer than a line
+func wrapText(f font, size float64, s string, width float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if textWidth(f, size, candidate) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for textWidth(f, size, word) > width && utf8.RuneCountInString(word) > 1 {
+			cut := len(word)
+			for cut > 0 && textWidth(f, size, word[:cut]) > width {
+				_, n := utf8.DecodeLastRuneInString(word[:cut])
+				cut -= n
+			}
+			if cut == 0 {
+				_, cut = utf8.DecodeRuneInString(word)
+			}
+			lines = append(lines, word[:cut])
+			word = word[cut:]
+		}
+		line = word
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// page collects the drawing operators of a page, in points from its bottom
+// left corner
+type page struct {
+	content bytes.Buffer
+}
+
+// text draws s in f at size with its baseline starting at x, y, in a gray
+// level from 0 (black) to 1 (white)
+func (p *page) text(f font, size, x, y, gray float64, s string) {
+	fmt.Fprintf(&p.content, "BT %.2f g /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n",
+		gray, f+1, size, x, y, escapeString(encode(s)))
+}
+
+// rect fills a rectangle whose bottom left corner is x, y
+func (p *page) rect(x, y, w, h, gray float64) {
+	fmt.Fprintf(&p.content, "%.2f g %.2f %.2f %.2f %.2f re f\n", gray, x, y, w, h)
+}
+
+// line strokes a line from x1, y1 to x2, y2
+func (p *page) line(x1, y1, x2, y2, width, gray float64) {
+	fmt.Fprintf(&p.content, "%.2f G %.2f w %.2f %.2f m %.2f %.2f l S\n", gray, width, x1, y1, x2, y2)
+}
+
+// escapeString writes encoded text as the body of a PDF literal string
+func escapeString(encoded []byte) string {
+	var b strings.Builder
+	for _, c := range encoded {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// writePDF assembles pages of size paper into a PDF document titled title
+func writePDF(paper Paper, pages []*page, title string) ([]byte, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// the catalog, page tree, info and fonts come first, so page objects
+	// start after them
+	firstPage := 4 + len(fontNames)
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object(fmt.Sprintf("<< /Title (%s) /Producer (cheat-go) >>", escapeString(encode(title))))
+	fonts := make([]string, len(fontNames))
+	for i, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fonts[i] = fmt.Sprintf("/F%d %d 0 R", i+1, 4+i)
+	}
+
+	for i, p := range pages {
+		var stream bytes.Buffer
+		w := zlib.NewWriter(&stream)
+		if _, err := w.Write(p.content.Bytes()); err != nil {
+			return nil, fmt.Errorf("failed to compress page %d: %w", i+1, err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress page %d: %w", i+1, err)
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			paper.Width, paper.Height, strings.Join(fonts, " "), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes(), nil
+}
@@ -0,0 +1,146 @@
+// Package sheet lays out the shortcuts of apps as a printable PDF cheat
+// sheet: compact, balanced columns of shortcuts under app and category
+// headings, shrunk to fit one page when they can.
+package sheet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+var ErrNoShortcuts = errors.New("no shortcuts to print")
+
+// Paper is a page size, in points
+type Paper struct {
+	Name          string
+	Width, Height float64
+}
+
+var (
+	A4     = Paper{Name: "a4", Width: 595.28, Height: 841.89}
+	Letter = Paper{Name: "letter", Width: 612, Height: 792}
+)
+
+// Papers are the page sizes a sheet can be printed on
+var Papers = []Paper{A4, Letter}
+
+// PaperNamed returns the page size called name, such as "a4"
+func PaperNamed(name string) (Paper, bool) {
+	for _, paper := range Papers {
+		if strings.EqualFold(paper.Name, name) {
+			return paper, true
+		}
+	}
+	return Paper{}, false
+}
+
+// Options control the layout of a sheet. Zero values choose the defaults.
+type Options struct {
+	// Title is printed at the top of the first page
+	Title string
+	// Paper defaults to A4
+	Paper Paper
+	// Landscape turns the page sideways
+	Landscape bool
+	// Columns is the number of columns of a page, 3 by default
+	Columns int
+	// FontSize is the size of shortcut text, 8 points by default. When the
+	// shortcuts do not fit one page, the text shrinks down to MinFontSize,
+	// 5 points by default, before continuing on more pages.
+	FontSize    float64
+	MinFontSize float64
+}
+
+const (
+	margin    = 28.0
+	columnGap = 14.0
+	// leading is the line height as a multiple of the font size
+	leading = 1.25
+)
+
+// withDefaults fills in the zero fields of o
+func (o Options) withDefaults() Options {
+	if o.Paper.Width == 0 {
+		o.Paper = A4
+	}
+	if o.Landscape {
+		o.Paper.Width, o.Paper.Height = o.Paper.Height, o.Paper.Width
+	}
+	if o.Columns <= 0 {
+		o.Columns = 3
+	}
+	if o.FontSize <= 0 {
+		o.FontSize = 8
+	}
+	if o.MinFontSize <= 0 || o.MinFontSize > o.FontSize {
+		o.MinFontSize = min(5, o.FontSize)
+	}
+	return o
+}
+
+// Render lays out the shortcuts of appList, in order, as a PDF document.
+// Shortcuts are grouped by category in the order of the app's categories,
+// then of first appearance; keys are printed as written.
+func Render(appList []*apps.App, opts Options) ([]byte, error) {
+	opts = opts.withDefaults()
+	empty := true
+	for _, app := range appList {
+		empty = empty && len(app.Shortcuts) == 0
+	}
+	if empty {
+		return nil, ErrNoShortcuts
+	}
+
+	l := fit(appList, opts)
+	pages := make([]*page, len(l.pages))
+	for i := range l.pages {
+		pages[i] = l.draw(i)
+	}
+	title := opts.Title
+	if title == "" {
+		title = "cheat-go shortcuts"
+	}
+	data, err := writePDF(opts.Paper, pages, title)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return data, nil
+}
+
+// categoryGroups groups the shortcuts of app by category, in the order of
+// its categories and then of first appearance
+func categoryGroups(app *apps.App) ([]string, map[string][]apps.Shortcut) {
+	groups := make(map[string][]apps.Shortcut)
+	var order []string
+	for _, shortcut := range app.Shortcuts {
+		if _, seen := groups[shortcut.Category]; !seen {
+			order = append(order, shortcut.Category)
+		}
+		groups[shortcut.Category] = append(groups[shortcut.Category], shortcut)
+	}
+
+	sorted := make([]string, 0, len(order))
+	for _, category := range app.Categories {
+		if _, ok := groups[category]; ok && !contains(sorted, category) {
+			sorted = append(sorted, category)
+		}
+	}
+	for _, category := range order {
+		if !contains(sorted, category) {
+			sorted = append(sorted, category)
+		}
+	}
+	return sorted, groups
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
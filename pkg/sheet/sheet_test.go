@@ -0,0 +1,210 @@
+package sheet
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// testApp returns an app with count shortcuts spread over categories
+func testApp(name string, count int, categories ...string) *apps.App {
+	app := &apps.App{Name: name, Categories: categories}
+	for i := 0; i < count; i++ {
+		category := ""
+		if len(categories) > 0 {
+			category = categories[i%len(categories)]
+		}
+		app.Shortcuts = append(app.Shortcuts, apps.Shortcut{
+			Keys:        fmt.Sprintf("C-x %d", i),
+			Description: fmt.Sprintf("Shortcut number %d", i),
+			Category:    category,
+		})
+	}
+	return app
+}
+
+// pageContents returns the decompressed content streams of a PDF
+func pageContents(t *testing.T, data []byte) []string {
+	t.Helper()
+	var contents []string
+	for _, match := range regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindAllSubmatchIndex(data, -1) {
+		length, _ := strconv.Atoi(string(data[match[2]:match[3]]))
+		r, err := zlib.NewReader(bytes.NewReader(data[match[1] : match[1]+length]))
+		if err != nil {
+			t.Fatalf("bad content stream: %v", err)
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("bad content stream: %v", err)
+		}
+		contents = append(contents, string(content))
+	}
+	return contents
+}
+
+func TestRender(t *testing.T) {
+	data, err := Render([]*apps.App{testApp("vim", 6, "motion", "editing")}, Options{Title: "My (vim) sheet"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("output is not a PDF document")
+	}
+	for _, want := range []string{"/Count 1", "/MediaBox [0 0 595.28 841.89]", `/Title (My \(vim\) sheet)`, "/BaseFont /Courier-Bold"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("document should contain %q", want)
+		}
+	}
+
+	// every xref offset points at its object
+	xref := regexp.MustCompile(`(?m)^(\d{10}) 00000 n $`).FindAllSubmatch(data, -1)
+	if len(xref) == 0 {
+		t.Fatal("no xref entries")
+	}
+	for i, entry := range xref {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, data[offset:offset+10])
+		}
+	}
+
+	content := pageContents(t, data)
+	if len(content) != 1 {
+		t.Fatalf("expected one page, got %d", len(content))
+	}
+	for _, want := range []string{"(vim) Tj", "(motion) Tj", "(editing) Tj", "(C-x 0) Tj", "(Shortcut number 5) Tj"} {
+		if !strings.Contains(content[0], want) {
+			t.Errorf("page should draw %q", want)
+		}
+	}
+	// categories follow the order of the app
+	if strings.Index(content[0], "(motion)") > strings.Index(content[0], "(editing)") {
+		t.Error("motion should come before editing")
+	}
+
+	if _, err := Render([]*apps.App{{Name: "empty"}}, Options{}); !errors.Is(err, ErrNoShortcuts) {
+		t.Errorf("expected ErrNoShortcuts, got %v", err)
+	}
+}
+
+func TestRender_Letter(t *testing.T) {
+	data, err := Render([]*apps.App{testApp("tmux", 3)}, Options{Paper: Letter, Landscape: true})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/MediaBox [0 0 792.00 612.00]")) {
+		t.Error("a landscape letter page should be 792 by 612 points")
+	}
+}
+
+func TestPaperNamed(t *testing.T) {
+	if paper, ok := PaperNamed("Letter"); !ok || paper != Letter {
+		t.Errorf("PaperNamed(Letter) = %v, %v", paper, ok)
+	}
+	if _, ok := PaperNamed("a3"); ok {
+		t.Error("a3 is not supported")
+	}
+}
+
+func TestLayout_ShrinksToOnePage(t *testing.T) {
+	appList := []*apps.App{testApp("vim", 300, "motion", "editing", "windows")}
+	opts := Options{}.withDefaults()
+
+	if l := newLayout(appList, opts, opts.FontSize); len(l.pages) < 2 {
+		t.Fatalf("300 shortcuts should not fit one page at %vpt", opts.FontSize)
+	}
+	l := fit(appList, opts)
+	if len(l.pages) != 1 || l.size >= opts.FontSize || l.size < opts.MinFontSize {
+		t.Errorf("the sheet should shrink to one page, got %d pages at %vpt", len(l.pages), l.size)
+	}
+
+	l = fit([]*apps.App{testApp("vim", 2000, "motion")}, opts)
+	if len(l.pages) < 2 || l.size != opts.FontSize {
+		t.Errorf("a sheet too long for one page should keep its size, got %d pages at %vpt", len(l.pages), l.size)
+	}
+	if content := pageContents(t, mustRender(t, []*apps.App{testApp("vim", 2000, "motion")})); !strings.Contains(content[1], "(page 2 of ") {
+		t.Error("pages should be numbered")
+	}
+}
+
+func mustRender(t *testing.T, appList []*apps.App) []byte {
+	t.Helper()
+	data, err := Render(appList, Options{})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	return data
+}
+
+func TestLayout_BalancesColumns(t *testing.T) {
+	opts := Options{}.withDefaults()
+	l := newLayout([]*apps.App{testApp("vim", 30, "motion", "editing")}, opts, opts.FontSize)
+	if len(l.pages) != 1 || len(l.pages[0]) != 3 {
+		t.Fatalf("expected one page of 3 columns, got %v", l.pages)
+	}
+
+	var heights []float64
+	for _, col := range l.pages[0] {
+		height := 0.0
+		for i := col.start; i < col.end; i++ {
+			height += l.items[i].height
+		}
+		heights = append(heights, height)
+	}
+	line := opts.FontSize * leading
+	if spread := max(heights[0], heights[1], heights[2]) - min(heights[0], heights[1], heights[2]); spread > 3*line {
+		t.Errorf("columns should have about the same height, got %v", heights)
+	}
+}
+
+func TestLayout_KeepsHeadingsWithShortcuts(t *testing.T) {
+	opts := Options{Columns: 4}.withDefaults()
+	l := newLayout([]*apps.App{testApp("vim", 40, "a", "b", "c", "d", "e"), testApp("tmux", 25, "x", "y")}, opts, opts.FontSize)
+	for _, columns := range l.pages {
+		for _, col := range columns {
+			if col.end > col.start && l.items[col.end-1].kind != entry {
+				t.Errorf("column %v ends with the heading %q", col, l.items[col.end-1].text)
+			}
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText(regular, 10, "Split the window vertically", 60)
+	if len(lines) < 2 || strings.Join(lines, " ") != "Split the window vertically" {
+		t.Errorf("wrapText = %q", lines)
+	}
+	for _, line := range lines {
+		if textWidth(regular, 10, line) > 60 {
+			t.Errorf("line %q is too wide", line)
+		}
+	}
+
+	// Courier fits 5 characters in 30 points at 10 points
+	if lines := wrapText(mono, 10, "abcdefghij", 30); strings.Join(lines, "|") != "abcde|fghij" {
+		t.Errorf("long words should be broken, got %q", lines)
+	}
+	if lines := wrapText(regular, 10, "", 30); len(lines) != 1 || lines[0] != "" {
+		t.Errorf("empty text should be one empty line, got %q", lines)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	if got := escapeString(encode("café – (x)\\ ← ⌘")); got != `caf\351 \226 \(x\)\\ <- ?` {
+		t.Errorf("escapeString(encode) = %q", got)
+	}
+	if got := textWidth(mono, 10, "abc"); got != 18 {
+		t.Errorf("Courier is 600 units wide, got %v", got)
+	}
+	if regularWidth, boldWidth := textWidth(regular, 10, "m"), textWidth(bold, 10, "m"); regularWidth != 8.33 || boldWidth != 8.89 {
+		t.Errorf("widths of m = %v, %v", regularWidth, boldWidth)
+	}
+}
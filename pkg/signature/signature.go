@@ -0,0 +1,226 @@
+// Package signature verifies minisign signatures, made with ed25519 keys,
+// of downloaded cheat sheets and of plugins.
+package signature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+var (
+	ErrUnsigned         = errors.New("content is not signed")
+	ErrUnknownKey       = errors.New("signed by a key that is not trusted")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrMalformed        = errors.New("malformed minisign data")
+)
+
+// Suffix is appended to the name of a file to name its signature file
+const Suffix = ".minisig"
+
+const (
+	// algEd signs the content itself, algPrehashed its BLAKE2b-512 hash as
+	// minisign does by default
+	algEd        = "Ed"
+	algPrehashed = "ED"
+
+	keyIDLength     = 8
+	commentPrefix   = "untrusted comment: "
+	trustedPrefix   = "trusted comment: "
+	publicKeyLength = 2 + keyIDLength + ed25519.PublicKeySize
+	sigLength       = 2 + keyIDLength + ed25519.SignatureSize
+)
+
+// KeyID identifies the key pair that made a signature
+type KeyID [keyIDLength]byte
+
+// String spells the ID as minisign prints it
+func (id KeyID) String() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
+
+// PublicKey is a minisign public key
+type PublicKey struct {
+	ID  KeyID
+	key ed25519.PublicKey
+}
+
+// ParsePublicKey decodes a minisign public key, either the base64 line or
+// the content of a .pub file with its untrusted comment
+func ParsePublicKey(text string) (PublicKey, error) {
+	line := ""
+	for _, l := range strings.Split(strings.TrimSpace(text), "\n") {
+		l = strings.TrimSpace(l)
+		if l != "" && !strings.HasPrefix(l, commentPrefix) {
+			line = l
+			break
+		}
+	}
+
+	data, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || len(data) != publicKeyLength || string(data[:2]) != algEd {
+		return PublicKey{}, fmt.Errorf("%w: public key %q", ErrMalformed, line)
+	}
+
+	var key PublicKey
+	copy(key.ID[:], data[2:2+keyIDLength])
+	key.key = ed25519.PublicKey(bytes.Clone(data[2+keyIDLength:]))
+	return key, nil
+}
+
+// String encodes the key as the base64 line of a minisign .pub file
+func (k PublicKey) String() string {
+	data := append([]byte(algEd), k.ID[:]...)
+	return base64.StdEncoding.EncodeToString(append(data, k.key...))
+}
+
+// SecretKey signs content for the matching PublicKey
+type SecretKey struct {
+	ID  KeyID
+	key ed25519.PrivateKey
+}
+
+// GenerateKey creates a key pair with a random ID
+func GenerateKey() (PublicKey, SecretKey, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return PublicKey{}, SecretKey{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+	var id KeyID
+	if _, err := rand.Read(id[:]); err != nil {
+		return PublicKey{}, SecretKey{}, fmt.Errorf("failed to generate key ID: %w", err)
+	}
+	return PublicKey{ID: id, key: public}, SecretKey{ID: id, key: private}, nil
+}
+
+// Sign signs the BLAKE2b-512 hash of data and returns the content of the
+// .minisig file, with trustedComment covered by the signature
+func (k SecretKey) Sign(data []byte, trustedComment string) []byte {
+	hash := blake2b.Sum512(data)
+	sig := ed25519.Sign(k.key, hash[:])
+	global := ed25519.Sign(k.key, append(bytes.Clone(sig), trustedComment...))
+
+	encoded := append([]byte(algPrehashed), k.ID[:]...)
+	encoded = append(encoded, sig...)
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%ssignature from cheat-go secret key\n", commentPrefix)
+	fmt.Fprintf(&out, "%s\n", base64.StdEncoding.EncodeToString(encoded))
+	fmt.Fprintf(&out, "%s%s\n", trustedPrefix, trustedComment)
+	fmt.Fprintf(&out, "%s\n", base64.StdEncoding.EncodeToString(global))
+	return out.Bytes()
+}
+
+// Signature is a decoded .minisig file
+type Signature struct {
+	KeyID          KeyID
+	TrustedComment string
+
+	algorithm string
+	sig       []byte
+	global    []byte
+}
+
+// ParseSignature decodes the content of a .minisig file
+func ParseSignature(data []byte) (*Signature, error) {
+	lines := strings.Split(strings.ReplaceAll(strings.TrimSpace(string(data)), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], commentPrefix) || !strings.HasPrefix(lines[2], trustedPrefix) {
+		return nil, fmt.Errorf("%w: signature", ErrMalformed)
+	}
+
+	encoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(encoded) != sigLength {
+		return nil, fmt.Errorf("%w: signature", ErrMalformed)
+	}
+	algorithm := string(encoded[:2])
+	if algorithm != algEd && algorithm != algPrehashed {
+		return nil, fmt.Errorf("%w: unknown algorithm %q", ErrMalformed, algorithm)
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("%w: global signature", ErrMalformed)
+	}
+
+	sig := &Signature{
+		TrustedComment: strings.TrimPrefix(lines[2], trustedPrefix),
+		algorithm:      algorithm,
+		sig:            encoded[2+keyIDLength:],
+		global:         global,
+	}
+	copy(sig.KeyID[:], encoded[2:2+keyIDLength])
+	return sig, nil
+}
+
+// Verifier checks signatures against the keys the user trusts
+type Verifier struct {
+	keys map[KeyID]PublicKey
+}
+
+// NewVerifier parses the trusted keys. A verifier without keys is not
+// enabled: content is then used whether it is signed or not.
+func NewVerifier(keys []string) (*Verifier, error) {
+	v := &Verifier{keys: make(map[KeyID]PublicKey, len(keys))}
+	for _, text := range keys {
+		key, err := ParsePublicKey(text)
+		if err != nil {
+			return nil, err
+		}
+		v.keys[key.ID] = key
+	}
+	return v, nil
+}
+
+// Enabled reports whether there are trusted keys to verify content with
+func (v *Verifier) Enabled() bool {
+	return v != nil && len(v.keys) > 0
+}
+
+// Verify checks that sig, the content of a .minisig file, signs data with
+// a trusted key. It returns ErrUnsigned when sig is empty, ErrUnknownKey
+// when another key made it and ErrInvalidSignature when data or the trusted
+// comment were altered.
+func (v *Verifier) Verify(data, sig []byte) (*Signature, error) {
+	if len(bytes.TrimSpace(sig)) == 0 {
+		return nil, ErrUnsigned
+	}
+	signature, err := ParseSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	var key PublicKey
+	var trusted bool
+	if v != nil {
+		key, trusted = v.keys[signature.KeyID]
+	}
+	if !trusted {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, signature.KeyID)
+	}
+
+	message := data
+	if signature.algorithm == algPrehashed {
+		hash := blake2b.Sum512(data)
+		message = hash[:]
+	}
+	if !ed25519.Verify(key.key, message, signature.sig) {
+		return nil, ErrInvalidSignature
+	}
+	if !ed25519.Verify(key.key, append(bytes.Clone(signature.sig), signature.TrustedComment...), signature.global) {
+		return nil, fmt.Errorf("%w: trusted comment", ErrInvalidSignature)
+	}
+	return signature, nil
+}
+
+// Overridable reports whether err only means that content could not be
+// verified, being unsigned or signed by an unknown key, so the user may
+// choose to use it anyway. Altered content is never overridable.
+func Overridable(err error) bool {
+	return errors.Is(err, ErrUnsigned) || errors.Is(err, ErrUnknownKey)
+}
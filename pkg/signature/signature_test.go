@@ -0,0 +1,104 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	public, secret, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	data := []byte("name: vim\nshortcuts: []\n")
+	sig := secret.Sign(data, "timestamp:1700000000\tfile:vim.yaml")
+
+	verifier, err := NewVerifier([]string{"untrusted comment: minisign public key\n" + public.String() + "\n"})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	if !verifier.Enabled() {
+		t.Fatal("a verifier with keys should be enabled")
+	}
+
+	verified, err := verifier.Verify(data, sig)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if verified.KeyID != public.ID || verified.TrustedComment != "timestamp:1700000000\tfile:vim.yaml" {
+		t.Errorf("Verify() = %+v, want key %s and the trusted comment", verified, public.ID)
+	}
+
+	if _, err := verifier.Verify(append(bytes.Clone(data), '#'), sig); !errors.Is(err, ErrInvalidSignature) || Overridable(err) {
+		t.Errorf("altered content error = %v, want %v", err, ErrInvalidSignature)
+	}
+	forged := bytes.Replace(sig, []byte("file:vim.yaml"), []byte("file:git.yaml"), 1)
+	if _, err := verifier.Verify(data, forged); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("altered trusted comment error = %v, want %v", err, ErrInvalidSignature)
+	}
+	if _, err := verifier.Verify(data, nil); !errors.Is(err, ErrUnsigned) || !Overridable(err) {
+		t.Errorf("unsigned error = %v, want %v", err, ErrUnsigned)
+	}
+	if _, err := verifier.Verify(data, []byte("not a signature")); !errors.Is(err, ErrMalformed) {
+		t.Errorf("malformed error = %v, want %v", err, ErrMalformed)
+	}
+
+	_, other, _ := GenerateKey()
+	if _, err := verifier.Verify(data, other.Sign(data, "")); !errors.Is(err, ErrUnknownKey) || !Overridable(err) {
+		t.Errorf("unknown key error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestVerify_Legacy(t *testing.T) {
+	public, secret, _ := GenerateKey()
+	data := []byte("legacy")
+
+	// minisign -l signs the content itself rather than its hash
+	sig := ed25519.Sign(secret.key, data)
+	global := ed25519.Sign(secret.key, append(bytes.Clone(sig), "legacy comment"...))
+	encoded := append(append([]byte(algEd), secret.ID[:]...), sig...)
+	file := fmt.Sprintf("untrusted comment: legacy\n%s\ntrusted comment: legacy comment\n%s\n",
+		base64.StdEncoding.EncodeToString(encoded), base64.StdEncoding.EncodeToString(global))
+
+	verifier, _ := NewVerifier([]string{public.String()})
+	if _, err := verifier.Verify(data, []byte(file)); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestNewVerifier(t *testing.T) {
+	verifier, err := NewVerifier(nil)
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	if verifier.Enabled() {
+		t.Error("a verifier without keys should not be enabled")
+	}
+	var none *Verifier
+	if none.Enabled() {
+		t.Error("a nil verifier should not be enabled")
+	}
+
+	if _, err := NewVerifier([]string{"RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"}); err != nil {
+		t.Errorf("NewVerifier() with the key of a minisign release error = %v", err)
+	}
+	for _, key := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("Ed short"))} {
+		if _, err := NewVerifier([]string{key}); !errors.Is(err, ErrMalformed) {
+			t.Errorf("NewVerifier(%q) error = %v, want %v", key, err, ErrMalformed)
+		}
+	}
+}
+
+func TestKeyID_String(t *testing.T) {
+	public, _ := ParsePublicKey("RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3")
+	if got := public.ID.String(); got != "E7620F1842B4E81F" {
+		t.Errorf("KeyID.String() = %q, want the ID minisign prints", got)
+	}
+	if got := public.String(); got != "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3" {
+		t.Errorf("PublicKey.String() = %q, want the parsed key", got)
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+)
+
+// FileStorage keeps every document in its own file. By default a collection
+// lives in root/<collection> with a .json extension; Mount places a
+// collection elsewhere, which keeps the historical on-disk layout intact.
+// Writers from other processes are serialized by a single lock file in root,
+// so no lock files are left beside the documents.
+type FileStorage struct {
+	root   string
+	mounts map[string]mount
+	mu     sync.RWMutex
+}
+
+type mount struct {
+	dir string
+	ext string
+}
+
+// NewFileStorage creates a file backend rooted at root
+func NewFileStorage(root string) *FileStorage {
+	return &FileStorage{
+		root:   root,
+		mounts: make(map[string]mount),
+	}
+}
+
+// Mount stores collection in dir using files with the given extension
+func (f *FileStorage) Mount(collection, dir, ext string) *FileStorage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mounts[collection] = mount{dir: dir, ext: ext}
+	return f
+}
+
+func (f *FileStorage) location(collection string) mount {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if m, ok := f.mounts[collection]; ok {
+		return m
+	}
+	return mount{dir: filepath.Join(f.root, collection), ext: ".json"}
+}
+
+// lockPath returns the lock file serializing writes to the store
+func (f *FileStorage) lockPath() string {
+	return filepath.Join(f.root, "storage.lock")
+}
+
+// Path returns the file holding a document
+func (f *FileStorage) Path(collection, key string) string {
+	loc := f.location(collection)
+	return filepath.Join(loc.dir, key+loc.ext)
+}
+
+func (f *FileStorage) Get(collection, key string) ([]byte, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(f.Path(collection, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s/%s", ErrNotFound, collection, key)
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+func (f *FileStorage) Put(collection, key string, data []byte) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.location(collection).dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", collection, err)
+	}
+
+	l, err := lock.Acquire(f.lockPath())
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	path := f.Path(collection, key)
+	if err := lock.ReplaceFile(path, data, 0644); err != nil {
+		return err
+	}
+	// earlier versions locked every document with a file beside it
+	os.Remove(path + ".lock")
+	return nil
+}
+
+func (f *FileStorage) Delete(collection, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	path := f.Path(collection, key)
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s/%s", ErrNotFound, collection, key)
+		}
+		return err
+	}
+	os.Remove(path + ".lock")
+	return nil
+}
+
+func (f *FileStorage) List(collection string) ([]string, error) {
+	loc := f.location(collection)
+
+	entries, err := os.ReadDir(loc.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	keys := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, loc.ext) {
+			continue
+		}
+		keys = append(keys, strings.TrimSuffix(name, loc.ext))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (f *FileStorage) Close() error {
+	return nil
+}
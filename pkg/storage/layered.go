@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+)
+
+// Layered stacks read-only directories of documents beneath and above one
+// collection of a backend, such as apps shipped system wide below the
+// user's own and apps of a project above them. Reads find a document in
+// the uppermost layer holding it; writes and deletes only ever reach the
+// backend, so documents of the layers cannot be changed through it.
+type Layered struct {
+	Storage
+	collection string
+	below      []*FileStorage
+	above      []*FileStorage
+}
+
+// NewLayered layers the directories below and above the documents of
+// collection in store, each list in increasing precedence. Documents of
+// the directories are files with the extension ext.
+func NewLayered(store Storage, collection, ext string, below, above []string) *Layered {
+	layer := func(dirs []string) []*FileStorage {
+		layers := make([]*FileStorage, len(dirs))
+		for i, dir := range dirs {
+			layers[i] = NewFileStorage(dir).Mount(collection, dir, ext)
+		}
+		return layers
+	}
+	return &Layered{
+		Storage:    store,
+		collection: collection,
+		below:      layer(below),
+		above:      layer(above),
+	}
+}
+
+// Get returns the document of the uppermost layer holding it
+func (l *Layered) Get(collection, key string) ([]byte, error) {
+	if collection != l.collection {
+		return l.Storage.Get(collection, key)
+	}
+
+	for i := len(l.above) - 1; i >= 0; i-- {
+		if data, err := l.above[i].Get(collection, key); !errors.Is(err, ErrNotFound) {
+			return data, err
+		}
+	}
+	data, err := l.Storage.Get(collection, key)
+	if !errors.Is(err, ErrNotFound) {
+		return data, err
+	}
+	for i := len(l.below) - 1; i >= 0; i-- {
+		if data, err := l.below[i].Get(collection, key); !errors.Is(err, ErrNotFound) {
+			return data, err
+		}
+	}
+	return nil, err
+}
+
+// List returns the sorted keys of every layer
+func (l *Layered) List(collection string) ([]string, error) {
+	keys, err := l.Storage.List(collection)
+	if err != nil || collection != l.collection {
+		return keys, err
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		seen[key] = true
+	}
+	for _, layer := range append(append([]*FileStorage(nil), l.below...), l.above...) {
+		layerKeys, err := layer.List(collection)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range layerKeys {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
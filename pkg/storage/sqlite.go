@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS documents (
+	collection TEXT NOT NULL,
+	key        TEXT NOT NULL,
+	data       BLOB NOT NULL,
+	updated_at INTEGER NOT NULL,
+	PRIMARY KEY (collection, key)
+)`
+
+// SQLiteStorage keeps every document as a row of a single SQLite database
+type SQLiteStorage struct {
+	db   *sql.DB
+	path string
+}
+
+// NewSQLiteStorage opens or creates the database at path
+func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// a single connection serialises writers inside the process
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("PRAGMA busy_timeout = 5000"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to configure database: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &SQLiteStorage{db: db, path: path}, nil
+}
+
+// Path returns the database file
+func (s *SQLiteStorage) Path() string {
+	return s.path
+}
+
+func (s *SQLiteStorage) Get(collection, key string) ([]byte, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	err := s.db.QueryRow("SELECT data FROM documents WHERE collection = ? AND key = ?",
+		collection, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s/%s", ErrNotFound, collection, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *SQLiteStorage) Put(collection, key string, data []byte) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`INSERT INTO documents (collection, key, data, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (collection, key) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		collection, key, data, time.Now().Unix())
+	return err
+}
+
+func (s *SQLiteStorage) Delete(collection, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	res, err := s.db.Exec("DELETE FROM documents WHERE collection = ? AND key = ?", collection, key)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s/%s", ErrNotFound, collection, key)
+	}
+	return nil
+}
+
+func (s *SQLiteStorage) List(collection string) ([]string, error) {
+	rows, err := s.db.Query("SELECT key FROM documents WHERE collection = ? ORDER BY key", collection)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound       = errors.New("document not found")
+	ErrInvalidKey     = errors.New("invalid document key")
+	ErrUnknownBackend = errors.New("unknown storage backend")
+)
+
+// Collections used by cheat-go
+const (
+	CollectionApps      = "apps"
+	CollectionNotes     = "notes"
+	CollectionNoteFiles = "note_files"
+	CollectionSession   = "session"
+	CollectionBookmarks = "bookmarks"
+	CollectionPractice  = "practice"
+)
+
+// Storage persists user data as opaque documents grouped in collections.
+// Feature code encodes its own data, so backends never depend on it.
+type Storage interface {
+	// Get returns the document stored under key or ErrNotFound
+	Get(collection, key string) ([]byte, error)
+	// Put creates or replaces a document
+	Put(collection, key string, data []byte) error
+	// Delete removes a document or returns ErrNotFound
+	Delete(collection, key string) error
+	// List returns the sorted keys of a collection
+	List(collection string) ([]string, error)
+	// Close releases resources held by the backend
+	Close() error
+}
+
+// GetJSON decodes the JSON document stored under key into v
+func GetJSON(s Storage, collection, key string, v interface{}) error {
+	data, err := s.Get(collection, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s/%s: %w", collection, key, err)
+	}
+	return nil
+}
+
+// PutJSON stores v as an indented JSON document
+func PutJSON(s Storage, collection, key string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s/%s: %w", collection, key, err)
+	}
+	return s.Put(collection, key, data)
+}
+
+// validKey rejects keys that could escape a file backend's directory
+func validKey(key string) error {
+	if key == "" || key == "." || key == ".." {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, r := range key {
+		if r == '/' || r == '\\' || r == 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
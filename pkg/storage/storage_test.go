@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func backends(t *testing.T) map[string]Storage {
+	dir := t.TempDir()
+	db, err := NewSQLiteStorage(filepath.Join(dir, "db", "cheat-go.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return map[string]Storage{
+		"file":   NewFileStorage(filepath.Join(dir, "files")),
+		"sqlite": db,
+	}
+}
+
+func TestStorage_CRUD(t *testing.T) {
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Get(CollectionNotes, "missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+			}
+
+			keys, err := s.List(CollectionNotes)
+			if err != nil || len(keys) != 0 {
+				t.Errorf("List(empty) = %v, %v", keys, err)
+			}
+
+			for _, key := range []string{"b", "a"} {
+				if err := s.Put(CollectionNotes, key, []byte("v-"+key)); err != nil {
+					t.Fatalf("Put(%s) error = %v", key, err)
+				}
+			}
+			if err := s.Put(CollectionNotes, "a", []byte("v2")); err != nil {
+				t.Fatalf("Put(replace) error = %v", err)
+			}
+
+			data, err := s.Get(CollectionNotes, "a")
+			if err != nil || string(data) != "v2" {
+				t.Errorf("Get(a) = %q, %v; want v2", data, err)
+			}
+
+			keys, _ = s.List(CollectionNotes)
+			if !reflect.DeepEqual(keys, []string{"a", "b"}) {
+				t.Errorf("List() = %v, want [a b]", keys)
+			}
+			if other, _ := s.List(CollectionApps); len(other) != 0 {
+				t.Errorf("collections should be separate, apps = %v", other)
+			}
+
+			if err := s.Delete(CollectionNotes, "a"); err != nil {
+				t.Errorf("Delete(a) error = %v", err)
+			}
+			if err := s.Delete(CollectionNotes, "a"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("second Delete(a) error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStorage_InvalidKey(t *testing.T) {
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"", "..", "../escape", `a\b`} {
+				if err := s.Put(CollectionApps, key, []byte("x")); !errors.Is(err, ErrInvalidKey) {
+					t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStorage_JSON(t *testing.T) {
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			in := map[string]int{"one": 1}
+			if err := PutJSON(s, CollectionSession, "state", in); err != nil {
+				t.Fatalf("PutJSON() error = %v", err)
+			}
+			var out map[string]int
+			if err := GetJSON(s, CollectionSession, "state", &out); err != nil {
+				t.Fatalf("GetJSON() error = %v", err)
+			}
+			if !reflect.DeepEqual(in, out) {
+				t.Errorf("GetJSON() = %v, want %v", out, in)
+			}
+		})
+	}
+}
+
+func TestFileStorage_Mount(t *testing.T) {
+	root := t.TempDir()
+	appsDir := filepath.Join(root, "custom")
+	s := NewFileStorage(root).Mount(CollectionApps, appsDir, ".yaml")
+
+	if err := s.Put(CollectionApps, "vim", []byte("name: vim")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(appsDir, "vim.yaml")); err != nil {
+		t.Errorf("mounted document not at expected path: %v", err)
+	}
+	if got := s.Path(CollectionNotes, "notes"); got != filepath.Join(root, "notes", "notes.json") {
+		t.Errorf("default Path() = %s", got)
+	}
+
+	// files with other extensions are not documents of the collection
+	os.WriteFile(filepath.Join(appsDir, "README.md"), []byte("x"), 0644)
+	keys, _ := s.List(CollectionApps)
+	if !reflect.DeepEqual(keys, []string{"vim"}) {
+		t.Errorf("List() = %v, want [vim]", keys)
+	}
+}
+
+func TestFileStorage_PutLeavesNoLockFiles(t *testing.T) {
+	root := t.TempDir()
+	appsDir := filepath.Join(root, "custom")
+	s := NewFileStorage(root).Mount(CollectionApps, appsDir, ".yaml")
+
+	os.MkdirAll(appsDir, 0755)
+	os.WriteFile(filepath.Join(appsDir, "vim.yaml.lock"), nil, 0644)
+	for _, key := range []string{"vim", "zsh"} {
+		if err := s.Put(CollectionApps, key, []byte("name: "+key)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	if err := s.Put(CollectionNotes, "notes", []byte("[]")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	for _, dir := range []string{appsDir, filepath.Join(root, "notes")} {
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if filepath.Ext(entry.Name()) == ".lock" {
+				t.Errorf("lock file %s left in %s", entry.Name(), dir)
+			}
+		}
+	}
+}
+
+func TestSQLiteStorage_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cheat-go.db")
+	s, err := NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage() error = %v", err)
+	}
+	s.Put(CollectionBookmarks, "k", []byte("v"))
+	s.Close()
+
+	s, err = NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer s.Close()
+	if data, err := s.Get(CollectionBookmarks, "k"); err != nil || string(data) != "v" {
+		t.Errorf("Get() after reopen = %q, %v", data, err)
+	}
+}
+
+func TestLayered(t *testing.T) {
+	root := t.TempDir()
+	system, project := filepath.Join(root, "system"), filepath.Join(root, "project")
+	os.MkdirAll(system, 0755)
+	os.MkdirAll(project, 0755)
+	os.WriteFile(filepath.Join(system, "vim.yaml"), []byte("system vim"), 0644)
+	os.WriteFile(filepath.Join(system, "tmux.yaml"), []byte("system tmux"), 0644)
+	os.WriteFile(filepath.Join(project, "vim.yaml"), []byte("project vim"), 0644)
+	os.WriteFile(filepath.Join(project, "make.yaml"), []byte("project make"), 0644)
+
+	user := NewFileStorage(root).Mount(CollectionApps, filepath.Join(root, "user"), ".yaml")
+	user.Put(CollectionApps, "tmux", []byte("user tmux"))
+	user.Put(CollectionApps, "git", []byte("user git"))
+	s := NewLayered(user, CollectionApps, ".yaml", []string{system}, []string{project})
+
+	for key, want := range map[string]string{
+		"vim":  "project vim",
+		"tmux": "user tmux",
+		"git":  "user git",
+		"make": "project make",
+	} {
+		if data, err := s.Get(CollectionApps, key); err != nil || string(data) != want {
+			t.Errorf("Get(%s) = %q, %v, want %q", key, data, err, want)
+		}
+	}
+	if _, err := s.Get(CollectionApps, "emacs"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(emacs) error = %v, want ErrNotFound", err)
+	}
+
+	keys, err := s.List(CollectionApps)
+	if want := []string{"git", "make", "tmux", "vim"}; err != nil || !reflect.DeepEqual(keys, want) {
+		t.Errorf("List() = %v, %v, want %v", keys, err, want)
+	}
+
+	// writes reach the backend only, beneath the project layer
+	s.Put(CollectionApps, "make", []byte("user make"))
+	if data, _ := s.Get(CollectionApps, "make"); string(data) != "project make" {
+		t.Errorf("project layer should win over the backend, got %q", data)
+	}
+	if err := s.Delete(CollectionApps, "vim"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete of a layered document error = %v, want ErrNotFound", err)
+	}
+
+	// other collections are the backend's alone
+	s.Put(CollectionSession, "state", []byte("{}"))
+	if keys, _ := s.List(CollectionSession); !reflect.DeepEqual(keys, []string{"state"}) {
+		t.Errorf("List(session) = %v", keys)
+	}
+}
@@ -0,0 +1,380 @@
+package sync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+)
+
+// ErrDeltaUnsupported is returned by delta services whose server only
+// exchanges whole data; the manager then falls back to a full sync
+var ErrDeltaUnsupported = errors.New("server does not support delta sync")
+
+// DeltaSyncService is implemented by services that exchange only the notes
+// and apps changed since the last sync instead of all data
+type DeltaSyncService interface {
+	// PullChanges returns the changes stored since cursor, or every item
+	// for an empty cursor, along with the cursor to pull from next time
+	PullChanges(cursor string) (*Changeset, error)
+	// PushChanges stores the changes of this device
+	PushChanges(changes Changeset) error
+}
+
+// VersionVector counts the changes each device made to an item. Of two
+// versions, the one that saw every change of the other is newer; when each
+// saw a change the other did not, the item was changed concurrently.
+type VersionVector map[string]uint64
+
+// Ordering is how two versions of an item relate
+type Ordering int
+
+const (
+	Equal Ordering = iota
+	Before
+	After
+	Concurrent
+)
+
+// Compare tells whether v is Before, After or Equal to other, or
+// Concurrent with it
+func (v VersionVector) Compare(other VersionVector) Ordering {
+	older, newer := false, false
+	for device, count := range v {
+		if count > other[device] {
+			newer = true
+		}
+	}
+	for device, count := range other {
+		if count > v[device] {
+			older = true
+		}
+	}
+	switch {
+	case older && newer:
+		return Concurrent
+	case older:
+		return Before
+	case newer:
+		return After
+	default:
+		return Equal
+	}
+}
+
+// Merge returns a version that saw the changes of both v and other
+func (v VersionVector) Merge(other VersionVector) VersionVector {
+	merged := make(VersionVector, len(v)+len(other))
+	for device, count := range v {
+		merged[device] = count
+	}
+	for device, count := range other {
+		if count > merged[device] {
+			merged[device] = count
+		}
+	}
+	return merged
+}
+
+// bump returns a copy of v with one more change by device
+func (v VersionVector) bump(device string) VersionVector {
+	bumped := v.Merge(nil)
+	bumped[device]++
+	return bumped
+}
+
+// Change is a created, updated or deleted note or app. Apps are identified
+// by their name.
+type Change struct {
+	Type    string        `json:"type"`
+	ID      string        `json:"id"`
+	Version VersionVector `json:"version"`
+	Deleted bool          `json:"deleted,omitempty"`
+	Note    *notes.Note   `json:"note,omitempty"`
+	App     *apps.App     `json:"app,omitempty"`
+}
+
+func (c Change) key() string {
+	return c.Type + "/" + c.ID
+}
+
+// hash identifies the content of the item; it is empty for deletions
+func (c Change) hash() string {
+	if c.Deleted {
+		return ""
+	}
+	var data []byte
+	if c.Note != nil {
+		data, _ = json.Marshal(c.Note)
+	} else {
+		data, _ = json.Marshal(c.App)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// item returns the note or app of the change as a conflict shows it
+func (c Change) item() interface{} {
+	if c.Type == "app" {
+		return c.App
+	}
+	return c.Note
+}
+
+// Changeset is the changes exchanged by a delta sync. Cursor marks how far
+// the server's changes were pulled.
+type Changeset struct {
+	DeviceID   string   `json:"device_id"`
+	DeviceName string   `json:"device_name,omitempty"`
+	UserID     string   `json:"user_id,omitempty"`
+	Cursor     string   `json:"cursor,omitempty"`
+	Changes    []Change `json:"changes"`
+}
+
+// versionsFile keeps the version of every synced item and the cursor of
+// the last pull, next to the notes
+const versionsFile = ".sync_versions.json"
+
+type versionState struct {
+	Cursor string                 `json:"cursor,omitempty"`
+	Items  map[string]itemVersion `json:"items,omitempty"`
+}
+
+// itemVersion is the state of an item after the last sync. Hash tells
+// whether it changed locally since; it is empty for deleted items.
+type itemVersion struct {
+	Version VersionVector `json:"version"`
+	Hash    string        `json:"hash,omitempty"`
+}
+
+func (m *Manager) loadVersions() versionState {
+	state := versionState{Items: make(map[string]itemVersion)}
+	data, err := os.ReadFile(filepath.Join(m.localDataDir, versionsFile))
+	if err != nil {
+		return state
+	}
+	if json.Unmarshal(data, &state) != nil || state.Items == nil {
+		return versionState{Items: make(map[string]itemVersion)}
+	}
+	return state
+}
+
+func (m *Manager) saveVersions(state versionState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return lock.WriteFileAtomic(filepath.Join(m.localDataDir, versionsFile), data, 0644)
+}
+
+// localItems lists the notes and apps of data by key
+func localItems(data *SyncData) map[string]Change {
+	items := make(map[string]Change, len(data.Notes)+len(data.Apps))
+	for _, note := range data.Notes {
+		c := Change{Type: "note", ID: note.ID, Note: note}
+		items[c.key()] = c
+	}
+	for i := range data.Apps {
+		c := Change{Type: "app", ID: data.Apps[i].Name, App: &data.Apps[i]}
+		items[c.key()] = c
+	}
+	return items
+}
+
+// localChanges returns the items created, updated or deleted since the
+// last sync, each with a version bumped for this device
+func (m *Manager) localChanges(state versionState, items map[string]Change) map[string]Change {
+	changes := make(map[string]Change)
+	for key, c := range items {
+		known, synced := state.Items[key]
+		if synced && known.Hash == c.hash() {
+			continue
+		}
+		c.Version = known.Version.bump(m.deviceID)
+		changes[key] = c
+	}
+	for key, known := range state.Items {
+		if _, exists := items[key]; exists || known.Hash == "" {
+			continue
+		}
+		changes[key] = deletion(key, known.Version.bump(m.deviceID))
+	}
+	return changes
+}
+
+// deletion returns the change deleting the item with key
+func deletion(key string, version VersionVector) Change {
+	itemType, id, _ := strings.Cut(key, "/")
+	return Change{Type: itemType, ID: id, Deleted: true, Version: version}
+}
+
+// syncDelta pulls the changes stored since the last sync, resolves those
+// conflicting with local changes and pushes only what changed locally
+func (m *Manager) syncDelta(service DeltaSyncService) (Progress, error) {
+	m.report(Progress{Stage: StageGathering})
+	localData, err := m.gatherLocalData()
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to gather local data: %w", err)
+	}
+	state := m.loadVersions()
+	items := localItems(localData)
+	changes := m.localChanges(state, items)
+
+	m.report(Progress{Stage: StagePulling})
+	remote, err := service.PullChanges(state.Cursor)
+	if err != nil {
+		return Progress{}, fmt.Errorf("failed to pull remote changes: %w", err)
+	}
+
+	apply := func(c Change) {
+		if c.Deleted {
+			delete(items, c.key())
+		} else {
+			items[c.key()] = c
+		}
+		state.Items[c.key()] = itemVersion{Version: c.Version, Hash: c.hash()}
+	}
+
+	type conflict struct {
+		local, remote Change
+	}
+	conflicts := []conflict{}
+	for _, rc := range remote.Changes {
+		key := rc.key()
+		lc, changed := changes[key]
+		if !changed {
+			lc = items[key]
+			if lc.ID == "" {
+				lc = deletion(key, nil)
+			}
+			lc.Version = state.Items[key].Version
+		}
+
+		switch rc.Version.Compare(lc.Version) {
+		case Before, Equal:
+			// already seen
+		case After:
+			apply(rc)
+			delete(changes, key)
+		case Concurrent:
+			if rc.hash() == lc.hash() {
+				lc.Version = lc.Version.Merge(rc.Version)
+				state.Items[key] = itemVersion{Version: lc.Version, Hash: lc.hash()}
+				if changed {
+					changes[key] = lc
+				}
+				continue
+			}
+			conflicts = append(conflicts, conflict{local: lc, remote: rc})
+		}
+	}
+
+	if len(conflicts) > 0 {
+		m.report(Progress{Stage: StageResolving, Total: len(conflicts)})
+	}
+	for i, c := range conflicts {
+		item := SyncItem{Type: c.local.Type, ID: c.local.ID, Local: c.local.item(), Remote: c.remote.item(), Timestamp: time.Now()}
+
+		// an edit wins over a deletion so no change is lost
+		var resolution ConflictResolution
+		switch {
+		case c.local.Deleted:
+			resolution = KeepRemote
+		case c.remote.Deleted:
+			resolution = KeepLocal
+		default:
+			resolution = m.determineResolution(item)
+		}
+
+		version := c.local.Version.Merge(c.remote.Version)
+		if resolution == KeepRemote {
+			c.remote.Version = version
+			apply(c.remote)
+			delete(changes, c.local.key())
+		} else {
+			c.local.Version = version.bump(m.deviceID)
+			changes[c.local.key()] = c.local
+		}
+		m.report(Progress{Stage: StageResolving, Done: i + 1, Total: len(conflicts), Conflict: &item, Resolution: resolution})
+	}
+
+	merged := mergedItems(items, m.deviceID)
+	counts := Progress{Notes: len(merged.Notes), Apps: len(merged.Apps), Changes: len(changes)}
+
+	m.mu.RLock()
+	beforeMerge := m.beforeMerge
+	m.mu.RUnlock()
+	if beforeMerge != nil {
+		if err := beforeMerge(); err != nil {
+			return Progress{}, fmt.Errorf("failed to prepare merge: %w", err)
+		}
+	}
+
+	if len(changes) > 0 {
+		counts.Stage = StagePushing
+		m.report(counts)
+		pushed := Changeset{DeviceID: m.deviceID, DeviceName: m.deviceName, Changes: make([]Change, 0, len(changes))}
+		for _, c := range changes {
+			pushed.Changes = append(pushed.Changes, c)
+		}
+		sort.Slice(pushed.Changes, func(i, j int) bool {
+			return pushed.Changes[i].key() < pushed.Changes[j].key()
+		})
+		if err := service.PushChanges(pushed); err != nil {
+			return Progress{}, fmt.Errorf("failed to push changes: %w", err)
+		}
+		for _, c := range pushed.Changes {
+			state.Items[c.key()] = itemVersion{Version: c.Version, Hash: c.hash()}
+		}
+	}
+
+	counts.Stage = StageSaving
+	m.report(counts)
+	if err := m.saveLocalData(merged); err != nil {
+		return Progress{}, fmt.Errorf("failed to save local data: %w", err)
+	}
+	// the next pull starts where this one ended, so changes other devices
+	// pushed meanwhile are not skipped; our own come back as already seen
+	state.Cursor = remote.Cursor
+	if err := m.saveVersions(state); err != nil {
+		return Progress{}, fmt.Errorf("failed to save sync versions: %w", err)
+	}
+	return counts, nil
+}
+
+// mergedItems returns the notes and apps of items as the data to save.
+// Notes and Apps are never nil, so saving removes the last deleted note.
+func mergedItems(items map[string]Change, deviceID string) *SyncData {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	merged := &SyncData{
+		Version:   "1.0",
+		Timestamp: time.Now(),
+		DeviceID:  deviceID,
+		Notes:     []*notes.Note{},
+		Apps:      []apps.App{},
+	}
+	for _, key := range keys {
+		c := items[key]
+		if c.Note != nil {
+			merged.Notes = append(merged.Notes, c.Note)
+		} else if c.App != nil {
+			merged.Apps = append(merged.Apps, *c.App)
+		}
+	}
+	return merged
+}
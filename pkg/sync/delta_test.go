@@ -0,0 +1,189 @@
+package sync
+
+import (
+	"strconv"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+)
+
+// deltaServer keeps the changes pushed by every device in one log; cursors
+// are positions in it
+type deltaServer struct {
+	mu          gosync.Mutex
+	log         []Change
+	pushes      [][]Change
+	unsupported bool
+	fullPushes  int
+}
+
+func (s *deltaServer) PullChanges(cursor string) (*Changeset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.unsupported {
+		return nil, ErrDeltaUnsupported
+	}
+	since, _ := strconv.Atoi(cursor)
+	return &Changeset{Cursor: strconv.Itoa(len(s.log)), Changes: append([]Change(nil), s.log[since:]...)}, nil
+}
+
+func (s *deltaServer) PushChanges(changes Changeset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log = append(s.log, changes.Changes...)
+	s.pushes = append(s.pushes, changes.Changes)
+	return nil
+}
+
+func (s *deltaServer) lastPush() []Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pushes) == 0 {
+		return nil
+	}
+	return s.pushes[len(s.pushes)-1]
+}
+
+func (s *deltaServer) Push(data SyncData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fullPushes++
+	return nil
+}
+func (s *deltaServer) Pull() (*SyncData, error)        { return &SyncData{}, nil }
+func (s *deltaServer) GetLastSync() (time.Time, error) { return time.Time{}, nil }
+func (s *deltaServer) ResolveConflict(SyncItem, ConflictResolution) error {
+	return nil
+}
+
+// device is a data directory syncing with the server
+type device struct {
+	t       *testing.T
+	dir     string
+	manager *Manager
+}
+
+func newDevice(t *testing.T, server *deltaServer) *device {
+	dir := t.TempDir()
+	manager, err := NewManager(server, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &device{t: t, dir: dir, manager: manager}
+}
+
+func (d *device) notes() *notes.FileManager {
+	d.t.Helper()
+	fm, err := notes.NewFileManager(d.dir)
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	return fm
+}
+
+func (d *device) sync() {
+	d.t.Helper()
+	if err := d.manager.Sync(); err != nil {
+		d.t.Fatalf("Sync() error = %v", err)
+	}
+}
+
+func TestVersionVector_Compare(t *testing.T) {
+	tests := []struct {
+		a, b VersionVector
+		want Ordering
+	}{
+		{VersionVector{"a": 1}, VersionVector{"a": 1}, Equal},
+		{nil, VersionVector{"a": 1}, Before},
+		{VersionVector{"a": 2, "b": 1}, VersionVector{"a": 1, "b": 1}, After},
+		{VersionVector{"a": 1}, VersionVector{"b": 1}, Concurrent},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Compare(tt.b); got != tt.want {
+			t.Errorf("%v.Compare(%v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+	if merged := (VersionVector{"a": 2}).Merge(VersionVector{"a": 1, "b": 3}); merged["a"] != 2 || merged["b"] != 3 {
+		t.Errorf("Merge() = %v", merged)
+	}
+}
+
+func TestManager_DeltaSync(t *testing.T) {
+	server := &deltaServer{}
+	a, b := newDevice(t, server), newDevice(t, server)
+
+	a.notes().CreateNote(&notes.Note{ID: "first", Title: "First"})
+	a.notes().CreateNote(&notes.Note{ID: "second", Title: "Second"})
+	a.sync()
+	if pushed := server.lastPush(); len(pushed) != 2 {
+		t.Fatalf("the first sync should push both notes, pushed %d", len(pushed))
+	}
+
+	b.sync()
+	if list, _ := b.notes().ListNotes(); len(list) != 2 {
+		t.Fatalf("the second device has %d notes after syncing, want 2", len(list))
+	}
+
+	// only the edited note travels
+	b.notes().UpdateNote("first", &notes.Note{Title: "First, edited"})
+	pushes := len(server.pushes)
+	b.sync()
+	if pushed := server.lastPush(); len(server.pushes) != pushes+1 || len(pushed) != 1 || pushed[0].ID != "first" {
+		t.Fatalf("an edit should push one change, pushed %+v", pushed)
+	}
+	a.sync()
+	if note, _ := a.notes().GetNote("first"); note.Title != "First, edited" {
+		t.Errorf("the edit did not reach the first device: %q", note.Title)
+	}
+	pushes = len(server.pushes)
+	a.sync()
+	if len(server.pushes) != pushes {
+		t.Error("a sync without changes should not push")
+	}
+
+	// concurrent edits keep the latest
+	a.notes().UpdateNote("second", &notes.Note{Title: "Second by a"})
+	time.Sleep(10 * time.Millisecond)
+	b.notes().UpdateNote("second", &notes.Note{Title: "Second by b"})
+	a.sync()
+
+	var resolved []Progress
+	b.manager.SetProgress(func(p Progress) {
+		if p.Conflict != nil {
+			resolved = append(resolved, p)
+		}
+	})
+	b.sync()
+	if len(resolved) != 1 || resolved[0].Resolution != KeepLocal {
+		t.Fatalf("the newer local edit should win the conflict, got %+v", resolved)
+	}
+	a.sync()
+	if note, _ := a.notes().GetNote("second"); note.Title != "Second by b" {
+		t.Errorf("the resolved note did not reach the first device: %q", note.Title)
+	}
+
+	// deletions travel too, including that of the last note
+	a.notes().DeleteNote("first")
+	a.notes().DeleteNote("second")
+	a.sync()
+	b.sync()
+	if list, _ := b.notes().ListNotes(); len(list) != 0 {
+		t.Errorf("deleted notes remain on the second device: %d", len(list))
+	}
+	if server.fullPushes != 0 {
+		t.Errorf("a delta server should never get whole data, got %d pushes", server.fullPushes)
+	}
+}
+
+func TestManager_DeltaSyncFallback(t *testing.T) {
+	server := &deltaServer{unsupported: true}
+	d := newDevice(t, server)
+	d.notes().CreateNote(&notes.Note{Title: "Note"})
+	d.sync()
+	if server.fullPushes != 1 || len(server.pushes) != 0 {
+		t.Errorf("a server without delta sync should get whole data, got %d full and %d delta pushes",
+			server.fullPushes, len(server.pushes))
+	}
+}
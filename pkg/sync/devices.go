@@ -0,0 +1,117 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoDeviceService = errors.New("the sync server does not list devices")
+	ErrCurrentDevice   = errors.New("the current device cannot be revoked")
+)
+
+// Device is a device syncing the same data
+type Device struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name,omitempty"`
+	LastSeen time.Time `json:"last_seen"`
+	// Current marks the device the manager runs on
+	Current bool `json:"current,omitempty"`
+}
+
+// DeviceService is implemented by services whose server keeps track of the
+// devices syncing with it
+type DeviceService interface {
+	ListDevices(ctx context.Context) ([]Device, error)
+	// RevokeDevice makes the server forget a device and refuse its syncs
+	RevokeDevice(id string) error
+}
+
+// deviceNameFile keeps the name of this device next to its ID
+const deviceNameFile = ".device_name"
+
+// loadDeviceName returns the name given to the device, or its host name
+func loadDeviceName(dataDir string) string {
+	if data, err := os.ReadFile(filepath.Join(dataDir, deviceNameFile)); err == nil {
+		if name := strings.TrimSpace(string(data)); name != "" {
+			return name
+		}
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// DeviceName returns the name other devices know this one by
+func (m *Manager) DeviceName() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.deviceName
+}
+
+// SetDeviceName names this device; the server learns the name with the
+// next sync. An empty name goes back to the host name.
+func (m *Manager) SetDeviceName(name string) error {
+	name = strings.TrimSpace(name)
+	path := filepath.Join(m.localDataDir, deviceNameFile)
+	if name == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deviceName = loadDeviceName(m.localDataDir)
+	return nil
+}
+
+// Devices asks the server for the devices syncing the same data, most
+// recently seen first, and keeps them for GetSyncStatus. The request stops
+// when ctx is done.
+func (m *Manager) Devices(ctx context.Context) ([]Device, error) {
+	service, ok := m.service.(DeviceService)
+	if !ok {
+		return nil, ErrNoDeviceService
+	}
+	devices, err := service.ListDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range devices {
+		devices[i].Current = devices[i].ID == m.deviceID
+	}
+	sort.SliceStable(devices, func(i, j int) bool {
+		return devices[i].LastSeen.After(devices[j].LastSeen)
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.devices = devices
+	m.saveState()
+	return devices, nil
+}
+
+// RevokeDevice makes the server forget another device, such as a lost
+// laptop, and refreshes the device list
+func (m *Manager) RevokeDevice(id string) error {
+	if id == m.deviceID {
+		return ErrCurrentDevice
+	}
+	service, ok := m.service.(DeviceService)
+	if !ok {
+		return ErrNoDeviceService
+	}
+	if err := service.RevokeDevice(id); err != nil {
+		return err
+	}
+	_, err := m.Devices(context.Background())
+	return err
+}
@@ -0,0 +1,117 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+)
+
+func TestManager_Devices(t *testing.T) {
+	var (
+		mu      gosync.Mutex
+		devices = map[string]Device{
+			"laptop": {ID: "laptop", Name: "Old laptop", LastSeen: time.Now().Add(-48 * time.Hour)},
+		}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/push":
+			var data SyncData
+			json.NewDecoder(r.Body).Decode(&data)
+			devices[data.DeviceID] = Device{ID: data.DeviceID, Name: data.DeviceName, LastSeen: time.Now()}
+		case r.URL.Path == "/pull":
+			json.NewEncoder(w).Encode(SyncData{Timestamp: time.Now()})
+		case r.URL.Path == "/devices" && r.Method == "GET":
+			list := []Device{}
+			for _, device := range devices {
+				list = append(list, device)
+			}
+			json.NewEncoder(w).Encode(list)
+		case strings.HasPrefix(r.URL.Path, "/devices/") && r.Method == "DELETE":
+			delete(devices, strings.TrimPrefix(r.URL.Path, "/devices/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	manager, err := NewManager(NewCloudSyncService(server.URL, "key"), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SetDeviceName("  Work desktop "); err != nil {
+		t.Fatalf("SetDeviceName() error = %v", err)
+	}
+	if name := manager.DeviceName(); name != "Work desktop" {
+		t.Errorf("DeviceName() = %q", name)
+	}
+
+	if err := manager.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	status := manager.GetSyncStatus()
+	if len(status.Devices) != 2 || !status.Devices[0].Current || status.Devices[0].Name != "Work desktop" {
+		t.Fatalf("a sync should refresh the devices, this one first: %+v", status.Devices)
+	}
+
+	if err := manager.RevokeDevice(status.DeviceID); !errors.Is(err, ErrCurrentDevice) {
+		t.Errorf("revoking the current device = %v", err)
+	}
+	if err := manager.RevokeDevice("laptop"); err != nil {
+		t.Fatalf("RevokeDevice() error = %v", err)
+	}
+	if status := manager.GetSyncStatus(); len(status.Devices) != 1 {
+		t.Errorf("the revoked device is still listed: %+v", status.Devices)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := manager.Devices(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Devices() with a cancelled context = %v", err)
+	}
+
+	// the name and the list outlive the manager
+	reopened, err := NewManager(NewCloudSyncService(server.URL, "key"), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := reopened.GetSyncStatus(); status.DeviceName != "Work desktop" || len(status.Devices) != 1 {
+		t.Errorf("after reopening: name %q, devices %+v", status.DeviceName, status.Devices)
+	}
+	reopened.SetDeviceName("")
+	if hostname, _ := os.Hostname(); reopened.DeviceName() != hostname {
+		t.Errorf("an empty name should fall back to the host name, got %q", reopened.DeviceName())
+	}
+}
+
+func TestManager_DevicesUnsupported(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	manager, err := NewManager(NewCloudSyncService(server.URL, "key"), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Devices(context.Background()); !errors.Is(err, ErrNoDeviceService) {
+		t.Errorf("Devices() from a server without /devices = %v", err)
+	}
+
+	manager, err = NewManager(&deltaServer{}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.RevokeDevice("other"); !errors.Is(err, ErrNoDeviceService) {
+		t.Errorf("RevokeDevice() without a device service = %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+)
+
+// ErrChecksumMismatch is returned when pulled data does not match its
+// checksum, such as after corruption in transit or on the server
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// quarantineDir keeps pulled data that failed verification, next to the
+// notes, so it can be inspected instead of merged
+const quarantineDir = "quarantine"
+
+// Checksum returns the SHA-256 of data without its Checksum field
+func Checksum(data SyncData) string {
+	data.Checksum = ""
+	jsonData, _ := json.Marshal(data)
+	hash := sha256.Sum256(jsonData)
+	return hex.EncodeToString(hash[:])
+}
+
+// verifyChecksum checks pulled data against its checksum. Data without a
+// checksum, as from a server that has none stored yet, passes.
+func verifyChecksum(data *SyncData) error {
+	if data == nil || data.Checksum == "" {
+		return nil
+	}
+	if sum := Checksum(*data); sum != data.Checksum {
+		return fmt.Errorf("%w: got %.12s, want %.12s", ErrChecksumMismatch, sum, data.Checksum)
+	}
+	return nil
+}
+
+// verifyPulled verifies pulled data, moving it to the quarantine directory
+// when it is corrupted so the sync stops before merging anything
+func (m *Manager) verifyPulled(kind string, data *SyncData) error {
+	err := verifyChecksum(data)
+	if err == nil {
+		return nil
+	}
+
+	path, qerr := m.quarantine(kind, data)
+	if qerr != nil {
+		return fmt.Errorf("%w (not quarantined: %v)", err, qerr)
+	}
+	m.mu.Lock()
+	m.quarantined = path
+	m.mu.Unlock()
+	return fmt.Errorf("%w; quarantined in %s", err, path)
+}
+
+// quarantine writes data to the quarantine directory and returns its path
+func (m *Manager) quarantine(kind string, data *SyncData) (string, error) {
+	dir := filepath.Join(m.localDataDir, quarantineDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.json", kind, time.Now().Format("20060102-150405.000")))
+	if err := lock.WriteFileAtomic(path, jsonData, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,65 @@
+package sync
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+)
+
+func TestManager_ChecksumQuarantine(t *testing.T) {
+	tmpDir := t.TempDir()
+	remote := &SyncData{
+		Version:   "1.0",
+		Timestamp: time.Now().Add(time.Hour),
+		Notes:     []*notes.Note{{ID: "remote", Title: "Remote"}},
+	}
+	remote.Checksum = Checksum(*remote)
+	service := &mockSyncService{returnData: remote}
+	manager, err := NewManager(service, tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.Sync(); err != nil {
+		t.Fatalf("Sync() with a valid checksum error = %v", err)
+	}
+
+	// corrupted in transit
+	remote.Notes[0].Title = "Tampered"
+	service.pushCalled = false
+	err = manager.Sync()
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Sync() of corrupted data error = %v, want %v", err, ErrChecksumMismatch)
+	}
+	if service.pushCalled {
+		t.Error("corrupted data should not be merged and pushed")
+	}
+	local, _ := notes.NewFileManager(tmpDir)
+	if note, _ := local.GetNote("remote"); note == nil || note.Title != "Remote" {
+		t.Errorf("corrupted data reached the local notes: %+v", note)
+	}
+
+	status := manager.GetSyncStatus()
+	if status.Quarantined == "" {
+		t.Fatal("the status should point to the quarantined data")
+	}
+	if _, err := os.Stat(status.Quarantined); err != nil {
+		t.Errorf("quarantined data not written: %v", err)
+	}
+
+	// the status survives a restart and clears with the next good sync
+	manager, _ = NewManager(service, tmpDir)
+	if manager.GetSyncStatus().Quarantined != status.Quarantined {
+		t.Error("the quarantine should be reported after a restart")
+	}
+	remote.Checksum = Checksum(*remote)
+	if err := manager.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if manager.GetSyncStatus().Quarantined != "" {
+		t.Error("a good sync should clear the integrity error")
+	}
+}
@@ -0,0 +1,88 @@
+package sync
+
+// SyncStage is a step of a sync
+type SyncStage string
+
+const (
+	StageGathering SyncStage = "gathering"
+	StagePulling   SyncStage = "pulling"
+	StageResolving SyncStage = "resolving"
+	StagePushing   SyncStage = "pushing"
+	StageSaving    SyncStage = "saving"
+	StageDone      SyncStage = "done"
+	// StageFailed ends the progress of a failed sync started with Start
+	StageFailed SyncStage = "failed"
+)
+
+// stageOrder lists the stages of a sync in the order they run
+var stageOrder = []SyncStage{StageGathering, StagePulling, StageResolving, StagePushing, StageSaving, StageDone}
+
+// Progress reports a step of a running sync
+type Progress struct {
+	Stage SyncStage
+	// Done and Total count the conflicts resolved in StageResolving
+	Done  int
+	Total int
+	// Conflict is the conflict just resolved with Resolution; it is nil
+	// when a stage starts
+	Conflict   *SyncItem
+	Resolution ConflictResolution
+	// Notes and Apps count the merged data from StagePushing on
+	Notes int
+	Apps  int
+	// Changes counts the notes and apps a delta sync pushes; a full sync
+	// pushes all data and leaves it zero
+	Changes int
+	// Err is the error a sync ended with in StageFailed
+	Err error
+}
+
+// Fraction tells how far the sync got, from 0 to 1, counting the resolved
+// conflicts within StageResolving
+func (p Progress) Fraction() float64 {
+	for i, stage := range stageOrder {
+		if stage != p.Stage {
+			continue
+		}
+		done := float64(i)
+		if p.Stage == StageResolving && p.Total > 0 {
+			done += float64(p.Done) / float64(p.Total)
+		}
+		return done / float64(len(stageOrder)-1)
+	}
+	return 0
+}
+
+// SetProgress calls fn for every step of the following syncs. fn runs on
+// the syncing goroutine and must not call back into the manager.
+func (m *Manager) SetProgress(fn func(Progress)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.progress = fn
+}
+
+// Start syncs in the background and returns a channel receiving the
+// progress of the sync, closed once it has finished. A sync that fails, or
+// does not start because another one runs, ends with StageFailed.
+func (m *Manager) Start() <-chan Progress {
+	events := make(chan Progress, len(stageOrder))
+	go func() {
+		defer close(events)
+		if err := m.syncWith(func(p Progress) { events <- p }); err != nil {
+			events <- Progress{Stage: StageFailed, Err: err}
+		}
+	}()
+	return events
+}
+
+func (m *Manager) report(p Progress) {
+	m.mu.RLock()
+	fn, listener := m.progress, m.listener
+	m.mu.RUnlock()
+	if fn != nil {
+		fn(p)
+	}
+	if listener != nil {
+		listener(p)
+	}
+}
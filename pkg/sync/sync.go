@@ -2,22 +2,31 @@ package sync
 
 import (
 	"bytes"
-	"cheat-go/pkg/apps"
-	"cheat-go/pkg/notes"
-	"cheat-go/pkg/online"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+	"github.com/remuscazacu/cheat-go/pkg/online"
 )
 
+// LockFile is held in the data directory while a sync runs, so the
+// processes sharing the directory, such as the TUI and the daemon, sync one
+// at a time
+const LockFile = "sync.lock"
+
 var (
 	ErrSyncInProgress = errors.New("sync already in progress")
 	ErrSyncFailed     = errors.New("sync failed")
@@ -31,10 +40,22 @@ type SyncService interface {
 	ResolveConflict(item SyncItem, resolution ConflictResolution) error
 }
 
+// TeamSyncService is implemented by services of servers shared by several
+// users, which also serve the notes shared by the team of the user. Team
+// notes are pulled on every sync and never pushed.
+type TeamSyncService interface {
+	PullTeam() (*SyncData, error)
+}
+
+// SyncData is the data of one user. UserID and TeamID identify the user
+// and team on a shared server and are empty on a single-user server.
 type SyncData struct {
 	Version     string              `json:"version"`
 	Timestamp   time.Time           `json:"timestamp"`
 	DeviceID    string              `json:"device_id"`
+	DeviceName  string              `json:"device_name,omitempty"`
+	UserID      string              `json:"user_id,omitempty"`
+	TeamID      string              `json:"team_id,omitempty"`
 	Apps        []apps.App          `json:"apps,omitempty"`
 	Notes       []*notes.Note       `json:"notes,omitempty"`
 	CheatSheets []online.CheatSheet `json:"cheat_sheets,omitempty"`
@@ -58,16 +79,73 @@ const (
 	Skip
 )
 
+// resolutionNames are the names accepted by ParseResolution
+var resolutionNames = map[ConflictResolution]string{
+	KeepLocal:  "local",
+	KeepRemote: "remote",
+	Merge:      "merge",
+	Skip:       "skip",
+}
+
+func (r ConflictResolution) String() string {
+	if name, ok := resolutionNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("resolution(%d)", int(r))
+}
+
+// ParseResolution returns the resolution named local, remote, merge or skip
+func ParseResolution(name string) (ConflictResolution, error) {
+	for resolution, n := range resolutionNames {
+		if n == name {
+			return resolution, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown conflict resolution %q (valid: local, remote, merge, skip)", name)
+}
+
+// DefaultSyncInterval is how often auto-sync runs unless SetInterval
+// changes it
+const DefaultSyncInterval = 15 * time.Minute
+
+// stateFile keeps the outcome of the last sync so separate processes, such
+// as the sync command and the TUI, report the same status
+const stateFile = ".sync_state.json"
+
+type syncState struct {
+	LastSync    time.Time  `json:"last_sync"`
+	LastError   string     `json:"last_error,omitempty"`
+	Conflicts   []SyncItem `json:"conflicts,omitempty"`
+	Quarantined string     `json:"quarantined,omitempty"`
+	// Transfer is nil for services that do not count their bytes
+	Transfer *TransferStats `json:"transfer,omitempty"`
+	Devices  []Device       `json:"devices,omitempty"`
+}
+
 type Manager struct {
 	service      SyncService
 	localDataDir string
 	deviceID     string
-	syncInterval time.Duration
+	deviceName   string
 	mu           sync.RWMutex
 	isSyncing    bool
 	lastSync     time.Time
+	lastError    string
 	conflicts    []SyncItem
-	stopChan     chan struct{}
+	quarantined  string
+	transfer     *TransferStats
+	devices      []Device
+	// autoMu guards the auto-sync session and its interval; it is separate
+	// from mu so a session can be stopped while its sync holds mu
+	autoMu       sync.Mutex
+	syncInterval time.Duration
+	autoCancel   context.CancelFunc
+	autoSyncDone chan struct{}
+	journal      *journal.Journal
+	beforeMerge  func() error
+	progress     func(Progress)
+	// listener receives the progress of the running sync only
+	listener func(Progress)
 }
 
 func NewManager(service SyncService, localDataDir string) (*Manager, error) {
@@ -76,27 +154,68 @@ func NewManager(service SyncService, localDataDir string) (*Manager, error) {
 		return nil, fmt.Errorf("failed to get device ID: %w", err)
 	}
 
-	return &Manager{
+	m := &Manager{
 		service:      service,
 		localDataDir: localDataDir,
 		deviceID:     deviceID,
-		syncInterval: 15 * time.Minute,
-		stopChan:     make(chan struct{}),
-	}, nil
+		deviceName:   loadDeviceName(localDataDir),
+		syncInterval: DefaultSyncInterval,
+	}
+	m.loadState()
+	return m, nil
+}
+
+// SetInterval changes how often auto-sync runs; it must be called before
+// StartAutoSync
+func (m *Manager) SetInterval(interval time.Duration) {
+	m.autoMu.Lock()
+	defer m.autoMu.Unlock()
+	if interval > 0 {
+		m.syncInterval = interval
+	}
+}
+
+// SetJournal records every note and app changed by a sync in j
+func (m *Manager) SetJournal(j *journal.Journal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.journal = j
+}
+
+// SetBeforeMerge calls fn before a sync writes merged data, such as to back
+// up local data; the sync is aborted if fn fails
+func (m *Manager) SetBeforeMerge(fn func() error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.beforeMerge = fn
 }
 
+// StartAutoSync syncs every sync interval until StopAutoSync; starting it
+// while it runs does nothing
 func (m *Manager) StartAutoSync() error {
+	m.autoMu.Lock()
+	defer m.autoMu.Unlock()
+	if m.autoCancel != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	m.autoCancel = cancel
+	m.autoSyncDone = done
+	interval := m.syncInterval
+
 	go func() {
-		ticker := time.NewTicker(m.syncInterval)
+		defer close(done)
+		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
 		for {
 			select {
 			case <-ticker.C:
-				if err := m.Sync(); err != nil {
-					fmt.Printf("Auto-sync failed: %v\n", err)
-				}
-			case <-m.stopChan:
+				// failures are kept as the last error of the sync status
+				m.Sync()
+			case <-ctx.Done():
 				return
 			}
 		}
@@ -105,33 +224,151 @@ func (m *Manager) StartAutoSync() error {
 	return nil
 }
 
+// StopAutoSync stops auto-sync and waits for a sync in progress to finish
+// so it does not write state afterwards. Auto-sync reads as stopped, and
+// can be started again, while that sync finishes; stopping it when it does
+// not run does nothing.
 func (m *Manager) StopAutoSync() {
-	close(m.stopChan)
+	m.autoMu.Lock()
+	cancel, done := m.autoCancel, m.autoSyncDone
+	m.autoCancel, m.autoSyncDone = nil, nil
+	m.autoMu.Unlock()
+	if cancel == nil {
+		return
+	}
+	// waited for without autoMu, which the status needs meanwhile
+	cancel()
+	<-done
+}
+
+// AutoSyncEnabled reports whether auto-sync runs
+func (m *Manager) AutoSyncEnabled() bool {
+	m.autoMu.Lock()
+	defer m.autoMu.Unlock()
+	return m.autoCancel != nil
+}
+
+// Interval returns how often auto-sync runs
+func (m *Manager) Interval() time.Duration {
+	m.autoMu.Lock()
+	defer m.autoMu.Unlock()
+	return m.syncInterval
 }
 
 func (m *Manager) Sync() error {
+	return m.syncWith(nil)
+}
+
+// syncWith syncs, reporting the progress to listener as well
+func (m *Manager) syncWith(listener func(Progress)) error {
 	m.mu.Lock()
 	if m.isSyncing {
 		m.mu.Unlock()
 		return ErrSyncInProgress
 	}
 	m.isSyncing = true
+	m.quarantined = ""
+	m.listener = listener
 	m.mu.Unlock()
 
-	defer func() {
+	l, err := lock.TryAcquire(filepath.Join(m.localDataDir, LockFile))
+	if err != nil {
 		m.mu.Lock()
 		m.isSyncing = false
+		m.listener = nil
 		m.mu.Unlock()
-	}()
+		if errors.Is(err, lock.ErrLocked) {
+			return fmt.Errorf("%w in another process", ErrSyncInProgress)
+		}
+		return err
+	}
+	defer l.Release()
+
+	meter, counted := m.service.(TransferMeter)
+	var before TransferStats
+	if counted {
+		before = meter.TransferStats()
+	}
+
+	err = m.sync()
+
+	m.mu.Lock()
+	m.isSyncing = false
+	m.listener = nil
+	if counted {
+		transfer := meter.TransferStats().sub(before)
+		m.transfer = &transfer
+	}
+	m.lastError = ""
+	if err != nil {
+		m.lastError = err.Error()
+	}
+	m.saveState()
+	m.mu.Unlock()
 
+	return err
+}
+
+func (m *Manager) sync() error {
+	counts, err := m.syncData()
+	if err != nil {
+		return err
+	}
+
+	teamData, err := m.pullTeam()
+	if err == nil {
+		err = m.verifyPulled("team", teamData)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to pull team notes: %w", err)
+	}
+	if err := m.saveTeamNotes(teamData); err != nil {
+		return fmt.Errorf("failed to save team notes: %w", err)
+	}
+
+	m.mu.Lock()
+	m.lastSync = time.Now()
+	m.conflicts = nil
+	m.mu.Unlock()
+
+	// a server without a device list still syncs
+	if _, ok := m.service.(DeviceService); ok {
+		m.Devices(context.Background())
+	}
+
+	counts.Stage = StageDone
+	m.report(counts)
+	return nil
+}
+
+// syncData exchanges only the changes with services that support it and
+// all data with the others, or when the server turns out not to
+func (m *Manager) syncData() (Progress, error) {
+	if service, ok := m.service.(DeltaSyncService); ok {
+		counts, err := m.syncDelta(service)
+		if !errors.Is(err, ErrDeltaUnsupported) {
+			return counts, err
+		}
+	}
+	return m.syncFull()
+}
+
+// syncFull pulls all remote data, merges it with the local data and pushes
+// the result
+func (m *Manager) syncFull() (Progress, error) {
+	m.report(Progress{Stage: StageGathering})
 	localData, err := m.gatherLocalData()
 	if err != nil {
-		return fmt.Errorf("failed to gather local data: %w", err)
+		return Progress{}, fmt.Errorf("failed to gather local data: %w", err)
 	}
 
+	m.report(Progress{Stage: StagePulling})
 	remoteData, err := m.service.Pull()
+	if err == nil {
+		err = m.verifyPulled("pull", remoteData)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to pull remote data: %w", err)
+		return Progress{}, fmt.Errorf("failed to pull remote data: %w", err)
 	}
 
 	conflicts := m.detectConflicts(localData, remoteData)
@@ -140,40 +377,92 @@ func (m *Manager) Sync() error {
 		m.conflicts = conflicts
 		m.mu.Unlock()
 
+		m.report(Progress{Stage: StageResolving, Total: len(conflicts)})
 		if err := m.autoResolveConflicts(conflicts); err != nil {
-			return fmt.Errorf("failed to resolve conflicts: %w", err)
+			return Progress{}, fmt.Errorf("failed to resolve conflicts: %w", err)
 		}
 	}
 
 	mergedData := m.mergeData(localData, remoteData)
 
+	m.mu.RLock()
+	beforeMerge := m.beforeMerge
+	m.mu.RUnlock()
+	if beforeMerge != nil {
+		if err := beforeMerge(); err != nil {
+			return Progress{}, fmt.Errorf("failed to prepare merge: %w", err)
+		}
+	}
+
+	counts := Progress{Notes: len(mergedData.Notes), Apps: len(mergedData.Apps)}
+	counts.Stage = StagePushing
+	m.report(counts)
 	if err := m.service.Push(*mergedData); err != nil {
-		return fmt.Errorf("failed to push data: %w", err)
+		return Progress{}, fmt.Errorf("failed to push data: %w", err)
 	}
 
+	counts.Stage = StageSaving
+	m.report(counts)
 	if err := m.saveLocalData(mergedData); err != nil {
-		return fmt.Errorf("failed to save local data: %w", err)
+		return Progress{}, fmt.Errorf("failed to save local data: %w", err)
 	}
-
-	m.mu.Lock()
-	m.lastSync = time.Now()
-	m.conflicts = nil
-	m.mu.Unlock()
-
-	return nil
+	return counts, nil
 }
 
 func (m *Manager) GetSyncStatus() SyncStatus {
+	// taken before mu, which a stopping auto-sync may be waiting for
+	autoSync := m.AutoSyncEnabled()
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	return SyncStatus{
+		AutoSync:     autoSync,
 		LastSync:     m.lastSync,
+		LastError:    m.lastError,
 		IsSyncing:    m.isSyncing,
 		HasConflicts: len(m.conflicts) > 0,
 		Conflicts:    m.conflicts,
 		DeviceID:     m.deviceID,
+		DeviceName:   m.deviceName,
+		Devices:      m.devices,
+		Quarantined:  m.quarantined,
+		Transfer:     m.transfer,
+	}
+}
+
+// loadState restores the status saved by the last sync, if any
+func (m *Manager) loadState() {
+	data, err := os.ReadFile(filepath.Join(m.localDataDir, stateFile))
+	if err != nil {
+		return
+	}
+	var state syncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return
+	}
+	m.lastSync = state.LastSync
+	m.lastError = state.LastError
+	m.conflicts = state.Conflicts
+	m.quarantined = state.Quarantined
+	m.transfer = state.Transfer
+	m.devices = state.Devices
+}
+
+// saveState persists the sync status; callers hold m.mu
+func (m *Manager) saveState() {
+	data, err := json.MarshalIndent(syncState{
+		LastSync:    m.lastSync,
+		LastError:   m.lastError,
+		Conflicts:   m.conflicts,
+		Quarantined: m.quarantined,
+		Transfer:    m.transfer,
+		Devices:     m.devices,
+	}, "", "  ")
+	if err != nil {
+		return
 	}
+	lock.WriteFileAtomic(filepath.Join(m.localDataDir, stateFile), data, 0644)
 }
 
 func (m *Manager) ResolveConflict(itemID string, resolution ConflictResolution) error {
@@ -187,6 +476,7 @@ func (m *Manager) ResolveConflict(itemID string, resolution ConflictResolution)
 			}
 
 			m.conflicts = append(m.conflicts[:i], m.conflicts[i+1:]...)
+			m.saveState()
 			return nil
 		}
 	}
@@ -196,9 +486,10 @@ func (m *Manager) ResolveConflict(itemID string, resolution ConflictResolution)
 
 func (m *Manager) gatherLocalData() (*SyncData, error) {
 	data := &SyncData{
-		Version:   "1.0",
-		Timestamp: time.Now(),
-		DeviceID:  m.deviceID,
+		Version:    "1.0",
+		Timestamp:  time.Now(),
+		DeviceID:   m.deviceID,
+		DeviceName: m.deviceName,
 	}
 
 	appsFile := filepath.Join(m.localDataDir, "apps.json")
@@ -209,11 +500,14 @@ func (m *Manager) gatherLocalData() (*SyncData, error) {
 		}
 	}
 
-	notesFile := filepath.Join(m.localDataDir, "notes.json")
-	if _, err := os.Stat(notesFile); err == nil {
-		notesData, err := os.ReadFile(notesFile)
-		if err == nil {
-			json.Unmarshal(notesData, &data.Notes)
+	if local, err := notes.NewFileManager(m.localDataDir); err == nil {
+		if list, err := local.ListNotes(); err == nil {
+			for _, note := range list {
+				// team notes belong to the team and are pulled separately
+				if note.Team == "" {
+					data.Notes = append(data.Notes, note)
+				}
+			}
 		}
 	}
 
@@ -222,26 +516,113 @@ func (m *Manager) gatherLocalData() (*SyncData, error) {
 	return data, nil
 }
 
+// saveLocalData writes the apps and notes of data. Nil apps or notes are
+// left as they are, while empty ones remove all.
 func (m *Manager) saveLocalData(data *SyncData) error {
-	if len(data.Apps) > 0 {
+	var previous *SyncData
+	if m.journal != nil {
+		previous, _ = m.gatherLocalData()
+	}
+
+	if data.Apps != nil {
 		appsFile := filepath.Join(m.localDataDir, "apps.json")
 		appsData, _ := json.MarshalIndent(data.Apps, "", "  ")
-		if err := os.WriteFile(appsFile, appsData, 0644); err != nil {
+		if err := lock.WriteFileAtomic(appsFile, appsData, 0644); err != nil {
 			return err
 		}
 	}
 
-	if len(data.Notes) > 0 {
-		notesFile := filepath.Join(m.localDataDir, "notes.json")
-		notesData, _ := json.MarshalIndent(data.Notes, "", "  ")
-		if err := os.WriteFile(notesFile, notesData, 0644); err != nil {
+	if data.Notes != nil {
+		local, err := notes.NewFileManager(m.localDataDir)
+		if err != nil {
 			return err
 		}
+		if err := local.ReplaceNotes(data.Notes); err != nil {
+			return err
+		}
+	}
+
+	if previous != nil {
+		m.recordChanges(previous, data)
 	}
 
 	return nil
 }
 
+// pullTeam pulls the notes shared by the team, or returns nil when the
+// service has no teams
+func (m *Manager) pullTeam() (*SyncData, error) {
+	service, ok := m.service.(TeamSyncService)
+	if !ok {
+		return nil, nil
+	}
+	return service.PullTeam()
+}
+
+// saveTeamNotes replaces the local team notes with those pulled, so notes
+// the team removed disappear as well
+func (m *Manager) saveTeamNotes(data *SyncData) error {
+	if data == nil {
+		return nil
+	}
+	local, err := notes.NewFileManager(m.localDataDir)
+	if err != nil {
+		return err
+	}
+	return local.ReplaceTeamNotes(data.TeamID, data.Notes)
+}
+
+// recordChanges journals the difference between the local data before and
+// after a sync
+func (m *Manager) recordChanges(before, after *SyncData) {
+	if after.Apps != nil {
+		oldApps := make(map[string]apps.App, len(before.Apps))
+		for _, app := range before.Apps {
+			oldApps[app.Name] = app
+		}
+		for i := range after.Apps {
+			app := &after.Apps[i]
+			old, existed := oldApps[app.Name]
+			delete(oldApps, app.Name)
+			switch {
+			case !existed:
+				m.journal.Record(journal.ActionCreate, journal.KindApp, app.Name, app.Name, journal.SourceSync, nil, app)
+			case !sameJSON(old, *app):
+				m.journal.Record(journal.ActionUpdate, journal.KindApp, app.Name, app.Name, journal.SourceSync, old, app)
+			}
+		}
+		for name, old := range oldApps {
+			m.journal.Record(journal.ActionDelete, journal.KindApp, name, name, journal.SourceSync, old, nil)
+		}
+	}
+
+	if after.Notes != nil {
+		oldNotes := make(map[string]*notes.Note, len(before.Notes))
+		for _, note := range before.Notes {
+			oldNotes[note.ID] = note
+		}
+		for _, note := range after.Notes {
+			old, existed := oldNotes[note.ID]
+			delete(oldNotes, note.ID)
+			switch {
+			case !existed:
+				m.journal.Record(journal.ActionCreate, journal.KindNote, note.ID, note.Title, journal.SourceSync, nil, note)
+			case !sameJSON(old, note):
+				m.journal.Record(journal.ActionUpdate, journal.KindNote, note.ID, note.Title, journal.SourceSync, old, note)
+			}
+		}
+		for id, old := range oldNotes {
+			m.journal.Record(journal.ActionDelete, journal.KindNote, id, old.Title, journal.SourceSync, old, nil)
+		}
+	}
+}
+
+func sameJSON(a, b interface{}) bool {
+	aData, _ := json.Marshal(a)
+	bData, _ := json.Marshal(b)
+	return bytes.Equal(aData, bData)
+}
+
 func (m *Manager) detectConflicts(local, remote *SyncData) []SyncItem {
 	conflicts := []SyncItem{}
 
@@ -274,9 +655,10 @@ func (m *Manager) detectConflicts(local, remote *SyncData) []SyncItem {
 
 func (m *Manager) mergeData(local, remote *SyncData) *SyncData {
 	merged := &SyncData{
-		Version:   "1.0",
-		Timestamp: time.Now(),
-		DeviceID:  m.deviceID,
+		Version:    "1.0",
+		Timestamp:  time.Now(),
+		DeviceID:   m.deviceID,
+		DeviceName: m.deviceName,
 	}
 
 	if local != nil {
@@ -297,11 +679,12 @@ func (m *Manager) mergeData(local, remote *SyncData) *SyncData {
 }
 
 func (m *Manager) autoResolveConflicts(conflicts []SyncItem) error {
-	for _, conflict := range conflicts {
+	for i, conflict := range conflicts {
 		resolution := m.determineResolution(conflict)
 		if err := m.service.ResolveConflict(conflict, resolution); err != nil {
 			return err
 		}
+		m.report(Progress{Stage: StageResolving, Done: i + 1, Total: len(conflicts), Conflict: &conflicts[i], Resolution: resolution})
 	}
 	return nil
 }
@@ -323,9 +706,7 @@ func (m *Manager) determineResolution(conflict SyncItem) ConflictResolution {
 }
 
 func (m *Manager) calculateChecksum(data *SyncData) string {
-	jsonData, _ := json.Marshal(data)
-	hash := sha256.Sum256(jsonData)
-	return hex.EncodeToString(hash[:])
+	return Checksum(*data)
 }
 
 func getOrCreateDeviceID(dataDir string) (string, error) {
@@ -358,51 +739,87 @@ func generateDeviceID() string {
 
 type SyncStatus struct {
 	LastSync     time.Time  `json:"last_sync"`
+	LastError    string     `json:"last_error,omitempty"`
 	IsSyncing    bool       `json:"is_syncing"`
 	HasConflicts bool       `json:"has_conflicts"`
 	Conflicts    []SyncItem `json:"conflicts,omitempty"`
 	DeviceID     string     `json:"device_id"`
+	DeviceName   string     `json:"device_name,omitempty"`
+	// Devices are the devices syncing the same data as of the last sync
+	Devices []Device `json:"devices,omitempty"`
+	// Quarantined is where the last sync moved pulled data that failed its
+	// checksum
+	Quarantined string `json:"quarantined,omitempty"`
+	// Transfer counts the bytes the last sync sent and received
+	Transfer *TransferStats `json:"transfer,omitempty"`
+	// AutoSync reports whether this manager syncs on its interval
+	AutoSync bool `json:"auto_sync"`
 }
 
-// CloudSyncService implements sync with a cloud backend
+// CloudSyncService implements sync with a cloud backend. A server shared
+// by several users serves each user under /users/{user} and the notes of a
+// team under /teams/{team}, each with its own API key. Request bodies are
+// gzipped and gzipped responses accepted.
 type CloudSyncService struct {
 	endpoint string
 	apiKey   string
+	user     string
+	team     string
+	teamKey  string
 	client   *http.Client
+
+	mu sync.Mutex
+	// plainRequests is set once the server refused a compressed request
+	plainRequests bool
+	stats         TransferStats
 }
 
 func NewCloudSyncService(endpoint, apiKey string) *CloudSyncService {
 	return &CloudSyncService{
 		endpoint: endpoint,
 		apiKey:   apiKey,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:   online.NewTransportClient(online.DefaultTransportOptions()),
 	}
 }
 
-func (c *CloudSyncService) Push(data SyncData) error {
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return err
+// SetTransportOptions replaces the retry, rate limit and timeout settings
+func (c *CloudSyncService) SetTransportOptions(opts online.TransportOptions) {
+	c.client = online.NewTransportClient(opts)
+}
+
+// SetIdentity scopes the service to user on a shared server. Team notes are
+// pulled with teamKey, a key that may only read them, or with the API key
+// of the user when teamKey is empty. Without a user the service talks to a
+// single-user server.
+func (c *CloudSyncService) SetIdentity(user, team, teamKey string) {
+	c.user = user
+	c.team = team
+	c.teamKey = teamKey
+}
+
+// url returns the address of an endpoint of the user
+func (c *CloudSyncService) url(path string) string {
+	if c.user == "" {
+		return c.endpoint + path
 	}
+	return c.endpoint + "/users/" + url.PathEscape(c.user) + path
+}
 
-	req, err := http.NewRequest("POST", c.endpoint+"/push", bytes.NewReader(jsonData))
+func (c *CloudSyncService) Push(data SyncData) error {
+	data.UserID = c.user
+	data.TeamID = c.team
+	data.Checksum = Checksum(data)
+	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.client.Do(req)
+	resp, body, err := c.request(context.Background(), "POST", c.url("/push"), c.apiKey, jsonData)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("push failed: %s", body)
 	}
 
@@ -410,30 +827,21 @@ func (c *CloudSyncService) Push(data SyncData) error {
 }
 
 func (c *CloudSyncService) Pull() (*SyncData, error) {
-	req, err := http.NewRequest("GET", c.endpoint+"/pull", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.client.Do(req)
+	resp, body, err := c.request(context.Background(), "GET", c.url("/pull"), c.apiKey, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
 		return &SyncData{}, nil
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("pull failed: %s", body)
 	}
 
 	var data SyncData
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+	if err := json.Unmarshal(body, &data); err != nil {
 		return nil, err
 	}
 
@@ -441,24 +849,16 @@ func (c *CloudSyncService) Pull() (*SyncData, error) {
 }
 
 func (c *CloudSyncService) GetLastSync() (time.Time, error) {
-	req, err := http.NewRequest("GET", c.endpoint+"/last-sync", nil)
+	_, body, err := c.request(context.Background(), "GET", c.url("/last-sync"), c.apiKey, nil)
 	if err != nil {
 		return time.Time{}, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return time.Time{}, err
-	}
-	defer resp.Body.Close()
-
 	var result struct {
 		LastSync time.Time `json:"last_sync"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(body, &result); err != nil {
 		return time.Time{}, err
 	}
 
@@ -476,23 +876,127 @@ func (c *CloudSyncService) ResolveConflict(item SyncItem, resolution ConflictRes
 		return err
 	}
 
-	req, err := http.NewRequest("POST", c.endpoint+"/resolve", bytes.NewReader(jsonData))
+	resp, body, err := c.request(context.Background(), "POST", c.url("/resolve"), c.apiKey, jsonData)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("conflict resolution failed: %s", body)
+	}
+
+	return nil
+}
 
-	resp, err := c.client.Do(req)
+// PullTeam returns the notes shared by the team, or nil without a team
+func (c *CloudSyncService) PullTeam() (*SyncData, error) {
+	if c.team == "" {
+		return nil, nil
+	}
+
+	key := c.teamKey
+	if key == "" {
+		key = c.apiKey
+	}
+	resp, body, err := c.request(context.Background(), "GET", c.endpoint+"/teams/"+url.PathEscape(c.team)+"/pull", key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &SyncData{TeamID: c.team}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("team pull failed: %s", body)
+	}
+
+	var data SyncData
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	data.TeamID = c.team
+
+	return &data, nil
+}
+
+// PullChanges pulls the changes stored since cursor from /changes. Servers
+// answering 404 or 501 there only exchange whole data.
+func (c *CloudSyncService) PullChanges(cursor string) (*Changeset, error) {
+	resp, body, err := c.request(context.Background(), "GET", c.url("/changes")+"?since="+url.QueryEscape(cursor), c.apiKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		return nil, ErrDeltaUnsupported
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pull failed: %s", body)
+	}
+
+	var changes Changeset
+	if err := json.Unmarshal(body, &changes); err != nil {
+		return nil, err
+	}
+
+	return &changes, nil
+}
+
+func (c *CloudSyncService) PushChanges(changes Changeset) error {
+	changes.UserID = c.user
+	jsonData, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	resp, body, err := c.request(context.Background(), "POST", c.url("/changes"), c.apiKey, jsonData)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("conflict resolution failed: %s", body)
+		return fmt.Errorf("push failed: %s", body)
+	}
+
+	return nil
+}
+
+func (c *CloudSyncService) ListDevices(ctx context.Context) ([]Device, error) {
+	resp, body, err := c.request(ctx, "GET", c.url("/devices"), c.apiKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		return nil, ErrNoDeviceService
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing devices failed: %s", body)
+	}
+
+	var devices []Device
+	if err := json.Unmarshal(body, &devices); err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+func (c *CloudSyncService) RevokeDevice(id string) error {
+	resp, body, err := c.request(context.Background(), "DELETE", c.url("/devices/"+url.PathEscape(id)), c.apiKey, nil)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotImplemented {
+		return ErrNoDeviceService
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("revoking device failed: %s", body)
 	}
 
 	return nil
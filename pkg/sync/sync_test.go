@@ -1,7 +1,6 @@
 package sync
 
 import (
-	"cheat-go/pkg/notes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,9 +9,14 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/lock"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
 )
 
 // Mock sync service for testing
@@ -147,6 +151,69 @@ func TestManager_SyncWithError(t *testing.T) {
 	}
 }
 
+func TestManager_SetBeforeMerge(t *testing.T) {
+	service := &mockSyncService{}
+	manager, err := NewManager(service, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	calls := 0
+	manager.SetBeforeMerge(func() error {
+		calls++
+		return errors.New("disk full")
+	})
+	if err := manager.Sync(); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("a failing before merge hook should fail the sync, got %v", err)
+	}
+	if calls != 1 || service.pushCalled {
+		t.Errorf("the hook should run once before pushing, calls = %d, pushed = %v", calls, service.pushCalled)
+	}
+
+	manager.SetBeforeMerge(func() error { return nil })
+	if err := manager.Sync(); err != nil || !service.pushCalled {
+		t.Errorf("Sync() = %v, pushed = %v", err, service.pushCalled)
+	}
+}
+
+func TestManager_SetProgress(t *testing.T) {
+	dir := t.TempDir()
+	updated := time.Now().Add(-time.Hour).UTC()
+	data, _ := json.Marshal([]*notes.Note{{ID: "note-1", Title: "Local", UpdatedAt: updated}})
+	os.WriteFile(filepath.Join(dir, "notes.json"), data, 0644)
+
+	service := &mockSyncService{returnData: &SyncData{
+		Timestamp: time.Now().Add(-2 * time.Hour),
+		Notes:     []*notes.Note{{ID: "note-1", Title: "Remote", UpdatedAt: updated.Add(time.Minute)}},
+	}}
+	manager, err := NewManager(service, dir)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	var stages []string
+	var last Progress
+	manager.SetProgress(func(p Progress) {
+		stage := string(p.Stage)
+		if p.Conflict != nil {
+			stage += ":" + p.Conflict.ID + "=" + p.Resolution.String()
+		}
+		stages = append(stages, stage)
+		last = p
+	})
+	if err := manager.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	want := "gathering,pulling,resolving,resolving:note-1=remote,pushing,saving,done"
+	if got := strings.Join(stages, ","); got != want {
+		t.Errorf("stages = %s, want %s", got, want)
+	}
+	if last.Notes != 1 {
+		t.Errorf("the last step should count the merged notes, got %+v", last)
+	}
+}
+
 func TestManager_ConcurrentSync(t *testing.T) {
 	tmpDir := t.TempDir()
 	service := &mockSyncService{}
@@ -208,6 +275,92 @@ func TestManager_AutoSync(t *testing.T) {
 	manager.StopAutoSync()
 }
 
+func TestManager_AutoSyncRestart(t *testing.T) {
+	manager, err := NewManager(&mockSyncService{}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.SetInterval(time.Hour)
+
+	// stopping before starting, and twice, does nothing
+	manager.StopAutoSync()
+	for i := 0; i < 3; i++ {
+		manager.StartAutoSync()
+		manager.StartAutoSync()
+		if !manager.AutoSyncEnabled() || !manager.GetSyncStatus().AutoSync {
+			t.Fatalf("cycle %d: auto-sync should run after StartAutoSync", i)
+		}
+		manager.StopAutoSync()
+		manager.StopAutoSync()
+		if manager.AutoSyncEnabled() {
+			t.Fatalf("cycle %d: auto-sync should stop", i)
+		}
+	}
+}
+
+// blockingSyncService pulls only once release is closed
+type blockingSyncService struct {
+	mockSyncService
+	pulling chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (s *blockingSyncService) Pull() (*SyncData, error) {
+	s.once.Do(func() { close(s.pulling) })
+	<-s.release
+	return s.mockSyncService.Pull()
+}
+
+func TestManager_StopAutoSyncDuringSync(t *testing.T) {
+	service := &blockingSyncService{pulling: make(chan struct{}), release: make(chan struct{})}
+	manager, err := NewManager(service, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.SetInterval(10 * time.Millisecond)
+	manager.StartAutoSync()
+	<-service.pulling
+
+	stopped := make(chan struct{})
+	go func() {
+		manager.StopAutoSync()
+		close(stopped)
+	}()
+
+	// the status must not wait for the sync that stopping waits for
+	status := make(chan SyncStatus, 1)
+	go func() {
+		for manager.AutoSyncEnabled() {
+			time.Sleep(time.Millisecond)
+		}
+		status <- manager.GetSyncStatus()
+	}()
+	select {
+	case s := <-status:
+		if s.AutoSync {
+			t.Error("auto-sync should read as stopped while its sync finishes")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("GetSyncStatus blocked while auto-sync was stopping")
+	}
+	if manager.Interval() != 10*time.Millisecond {
+		t.Errorf("Interval() = %s", manager.Interval())
+	}
+
+	select {
+	case <-stopped:
+		t.Fatal("StopAutoSync should wait for the sync in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(service.release)
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("StopAutoSync did not return once the sync finished")
+	}
+}
+
 func TestManager_ConflictDetection(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -404,9 +557,63 @@ func TestManager_SaveLocalData(t *testing.T) {
 	}
 
 	// Check files were created
-	notesFile := filepath.Join(tmpDir, "notes.json")
+	notesFile := filepath.Join(tmpDir, "files", "note1.json")
 	if _, err := os.Stat(notesFile); os.IsNotExist(err) {
-		t.Error("Notes file should be created")
+		t.Error("Note file should be created")
+	}
+}
+
+func TestManager_SaveLocalDataJournal(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager, err := NewManager(&mockSyncService{}, tmpDir)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	j, err := journal.Open(filepath.Join(tmpDir, "journal.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.SetJournal(j)
+
+	first := &SyncData{Notes: []*notes.Note{
+		{ID: "keep", Title: "Keep"},
+		{ID: "change", Title: "Before"},
+		{ID: "drop", Title: "Drop"},
+	}}
+	if err := manager.saveLocalData(first); err != nil {
+		t.Fatalf("saveLocalData failed: %v", err)
+	}
+
+	second := &SyncData{Notes: []*notes.Note{
+		{ID: "keep", Title: "Keep"},
+		{ID: "change", Title: "After"},
+		{ID: "new", Title: "New"},
+	}}
+	if err := manager.saveLocalData(second); err != nil {
+		t.Fatalf("saveLocalData failed: %v", err)
+	}
+
+	entries, _ := j.Entries()
+	got := map[string]journal.Action{}
+	for _, e := range entries[3:] {
+		if e.Source != journal.SourceSync {
+			t.Errorf("entry %s source = %s, want sync", e.ID, e.Source)
+		}
+		got[e.ID] = e.Action
+	}
+
+	want := map[string]journal.Action{
+		"change": journal.ActionUpdate,
+		"drop":   journal.ActionDelete,
+		"new":    journal.ActionCreate,
+	}
+	if len(got) != len(want) {
+		t.Errorf("second sync recorded %v, want %v", got, want)
+	}
+	for id, action := range want {
+		if got[id] != action {
+			t.Errorf("note %s recorded as %q, want %q", id, got[id], action)
+		}
 	}
 }
 
@@ -974,6 +1181,29 @@ func TestConflictResolution_Values(t *testing.T) {
 	}
 }
 
+func TestManager_SyncLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	service := &mockSyncService{}
+	manager, err := NewManager(service, tmpDir)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	// another process syncing the same data
+	held, err := lock.Acquire(filepath.Join(tmpDir, LockFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Sync(); !errors.Is(err, ErrSyncInProgress) || service.pullCalled {
+		t.Errorf("Sync() while another sync runs = %v", err)
+	}
+	held.Release()
+
+	if err := manager.Sync(); err != nil || !service.pullCalled {
+		t.Errorf("Sync() once the other sync ended = %v", err)
+	}
+}
+
 func TestManager_ErrorHandling(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -1084,3 +1314,181 @@ func BenchmarkCloudSyncService_Push(b *testing.B) {
 		service.Push(data)
 	}
 }
+
+func TestManager_PersistsState(t *testing.T) {
+	tmpDir := t.TempDir()
+	service := &mockSyncService{returnError: true}
+
+	manager, err := NewManager(service, tmpDir)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	if err := manager.Sync(); err == nil {
+		t.Fatal("Sync should fail when service returns errors")
+	}
+
+	reopened, err := NewManager(service, tmpDir)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	status := reopened.GetSyncStatus()
+	if status.LastError == "" {
+		t.Error("Last error should survive a restart")
+	}
+	if status.DeviceID != manager.GetSyncStatus().DeviceID {
+		t.Error("Device ID should survive a restart")
+	}
+
+	service.returnError = false
+	if err := reopened.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	reopened, _ = NewManager(service, tmpDir)
+	if status := reopened.GetSyncStatus(); status.LastError != "" || status.LastSync.IsZero() {
+		t.Errorf("Successful sync should be persisted, got %+v", status)
+	}
+}
+
+func TestParseResolution(t *testing.T) {
+	for _, resolution := range []ConflictResolution{KeepLocal, KeepRemote, Merge, Skip} {
+		parsed, err := ParseResolution(resolution.String())
+		if err != nil || parsed != resolution {
+			t.Errorf("ParseResolution(%q) = %v, %v", resolution.String(), parsed, err)
+		}
+	}
+	if _, err := ParseResolution("newest"); err == nil {
+		t.Error("ParseResolution should reject unknown names")
+	}
+}
+
+func TestCloudSyncService_Team(t *testing.T) {
+	var pushed SyncData
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/alice/pull":
+			if r.Header.Get("Authorization") != "Bearer user-key" {
+				t.Errorf("user data pulled with %q", r.Header.Get("Authorization"))
+			}
+			json.NewEncoder(w).Encode(SyncData{Version: "1.0", UserID: "alice"})
+		case "/users/alice/push":
+			json.NewDecoder(r.Body).Decode(&pushed)
+		case "/teams/ops/pull":
+			if r.Header.Get("Authorization") != "Bearer team-key" {
+				t.Errorf("team notes pulled with %q", r.Header.Get("Authorization"))
+			}
+			json.NewEncoder(w).Encode(SyncData{Notes: []*notes.Note{{ID: "team-1", Title: "Runbook"}}})
+		case "/users/alice/changes", "/users/alice/devices":
+			// a server without delta sync or a device list
+			http.NotFound(w, r)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	local, err := notes.NewFileManager(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local.CreateNote(&notes.Note{ID: "mine", Title: "Mine"})
+
+	service := NewCloudSyncService(server.URL, "user-key")
+	service.SetIdentity("alice", "ops", "team-key")
+	manager, err := NewManager(service, tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the second sync pushes what the first one saved, team notes included
+	for i := 0; i < 2; i++ {
+		if err := manager.Sync(); err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+	}
+
+	if pushed.UserID != "alice" || pushed.TeamID != "ops" {
+		t.Errorf("pushed data of user %q in team %q", pushed.UserID, pushed.TeamID)
+	}
+	for _, note := range pushed.Notes {
+		if note.ID == "team-1" {
+			t.Error("team notes should not be pushed")
+		}
+	}
+
+	local, err = notes.NewFileManager(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note, err := local.GetNote("team-1"); err != nil || note.Team != "ops" {
+		t.Errorf("team note after sync = %+v, %v", note, err)
+	}
+	if _, err := local.GetNote("mine"); err != nil {
+		t.Errorf("personal note after sync: %v", err)
+	}
+}
+
+func TestCloudSyncService_Changes(t *testing.T) {
+	var pushed Changeset
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/alice/changes" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		switch r.Method {
+		case "GET":
+			if r.URL.Query().Get("since") != "41" {
+				t.Errorf("changes pulled since %q", r.URL.Query().Get("since"))
+			}
+			json.NewEncoder(w).Encode(Changeset{Cursor: "42", Changes: []Change{{Type: "note", ID: "n1", Deleted: true}}})
+		case "POST":
+			json.NewDecoder(r.Body).Decode(&pushed)
+		}
+	}))
+	defer server.Close()
+
+	service := NewCloudSyncService(server.URL, "test-key")
+	service.SetIdentity("alice", "", "")
+	changes, err := service.PullChanges("41")
+	if err != nil || changes.Cursor != "42" || len(changes.Changes) != 1 || !changes.Changes[0].Deleted {
+		t.Fatalf("PullChanges() = %+v, %v", changes, err)
+	}
+	if err := service.PushChanges(Changeset{DeviceID: "d1", Changes: changes.Changes}); err != nil {
+		t.Fatal(err)
+	}
+	if pushed.UserID != "alice" || len(pushed.Changes) != 1 {
+		t.Errorf("pushed %+v", pushed)
+	}
+
+	server.Config.Handler = http.NotFoundHandler()
+	if _, err := service.PullChanges(""); !errors.Is(err, ErrDeltaUnsupported) {
+		t.Errorf("PullChanges() from a server without delta sync error = %v", err)
+	}
+}
+
+func TestManager_Start(t *testing.T) {
+	manager, err := NewManager(&mockSyncService{}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var last Progress
+	fraction := -1.0
+	for p := range manager.Start() {
+		if p.Fraction() < fraction {
+			t.Errorf("progress went back from %v to %v at %s", fraction, p.Fraction(), p.Stage)
+		}
+		fraction, last = p.Fraction(), p
+	}
+	if last.Stage != StageDone || last.Fraction() != 1 {
+		t.Errorf("the last progress = %+v, want done", last)
+	}
+
+	failing, _ := NewManager(&mockSyncService{returnError: true}, t.TempDir())
+	for p := range failing.Start() {
+		last = p
+	}
+	if last.Stage != StageFailed || !errors.Is(last.Err, ErrSyncFailed) {
+		t.Errorf("a failed sync should end with its error, got %+v", last)
+	}
+}
@@ -0,0 +1,151 @@
+package sync
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// minCompressSize is the smallest request body worth compressing; below it
+// the gzip header costs more than it saves
+const minCompressSize = 1024
+
+// TransferStats counts the bytes of sync payloads as they went over the
+// network and uncompressed, so the savings of compression show
+type TransferStats struct {
+	Sent        int64 `json:"sent"`
+	SentRaw     int64 `json:"sent_raw"`
+	Received    int64 `json:"received"`
+	ReceivedRaw int64 `json:"received_raw"`
+}
+
+// TransferMeter is implemented by services that count the bytes they
+// transfer
+type TransferMeter interface {
+	TransferStats() TransferStats
+}
+
+// Saved returns the fraction of bytes compression saved, from 0 to 1
+func (s TransferStats) Saved() float64 {
+	raw := s.SentRaw + s.ReceivedRaw
+	if raw == 0 {
+		return 0
+	}
+	return 1 - float64(s.Sent+s.Received)/float64(raw)
+}
+
+func (s TransferStats) String() string {
+	return fmt.Sprintf("%s sent, %s received (%.0f%% saved)",
+		formatBytes(s.Sent), formatBytes(s.Received), s.Saved()*100)
+}
+
+func (s TransferStats) sub(before TransferStats) TransferStats {
+	return TransferStats{
+		Sent:        s.Sent - before.Sent,
+		SentRaw:     s.SentRaw - before.SentRaw,
+		Received:    s.Received - before.Received,
+		ReceivedRaw: s.ReceivedRaw - before.ReceivedRaw,
+	}
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// TransferStats returns the bytes transferred since the service was created
+func (c *CloudSyncService) TransferStats() TransferStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// request sends body with the bearer key, stopping when ctx is done, and
+// returns the response along
+// with its body, decompressed. Bodies are gzipped unless the server
+// answered a compressed request with 415 Unsupported Media Type before, in
+// which case the request is sent again uncompressed.
+func (c *CloudSyncService) request(ctx context.Context, method, url, key string, body []byte) (*http.Response, []byte, error) {
+	c.mu.Lock()
+	compress := len(body) >= minCompressSize && !c.plainRequests
+	c.mu.Unlock()
+
+	resp, data, err := c.send(ctx, method, url, key, body, compress)
+	if err == nil && compress && resp.StatusCode == http.StatusUnsupportedMediaType {
+		c.mu.Lock()
+		c.plainRequests = true
+		c.mu.Unlock()
+		return c.send(ctx, method, url, key, body, false)
+	}
+	return resp, data, err
+}
+
+func (c *CloudSyncService) send(ctx context.Context, method, url, key string, body []byte, compress bool) (*http.Response, []byte, error) {
+	payload := body
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(body)
+		if err := zw.Close(); err != nil {
+			return nil, nil, err
+		}
+		payload = buf.Bytes()
+	}
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	// asking for gzip ourselves keeps the transport from decompressing
+	// transparently, so the compressed size can be counted
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	wire, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := wire
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(bytes.NewReader(wire))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decompress response: %w", err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, nil, fmt.Errorf("failed to decompress response: %w", err)
+		}
+	}
+
+	c.mu.Lock()
+	c.stats.Sent += int64(len(payload))
+	c.stats.SentRaw += int64(len(body))
+	c.stats.Received += int64(len(wire))
+	c.stats.ReceivedRaw += int64(len(data))
+	c.mu.Unlock()
+	return resp, data, nil
+}
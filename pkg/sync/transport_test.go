@@ -0,0 +1,117 @@
+package sync
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+)
+
+func TestCloudSyncService_Compression(t *testing.T) {
+	large := SyncData{Version: "1.0", Notes: []*notes.Note{{ID: "big", Content: strings.Repeat("compress me ", 500)}}}
+
+	var pushed SyncData
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/push":
+			if r.Header.Get("Content-Encoding") != "gzip" {
+				t.Error("large bodies should be gzipped")
+			}
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			json.NewDecoder(zr).Decode(&pushed)
+		case "/pull":
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				t.Error("gzipped responses should be accepted")
+			}
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			json.NewEncoder(zw).Encode(large)
+			zw.Close()
+		}
+	}))
+	defer server.Close()
+
+	service := NewCloudSyncService(server.URL, "test-key")
+	if err := service.Push(large); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if len(pushed.Notes) != 1 || pushed.Notes[0].Content != large.Notes[0].Content {
+		t.Error("the server did not get the pushed data")
+	}
+	data, err := service.Pull()
+	if err != nil || len(data.Notes) != 1 || data.Notes[0].Content != large.Notes[0].Content {
+		t.Fatalf("Pull() of a gzipped response = %+v, %v", data, err)
+	}
+
+	stats := service.TransferStats()
+	if stats.Sent >= stats.SentRaw || stats.Received >= stats.ReceivedRaw {
+		t.Errorf("compression should save bytes both ways: %+v", stats)
+	}
+	if saved := stats.Saved(); saved <= 0.5 {
+		t.Errorf("Saved() = %v for repetitive data", saved)
+	}
+}
+
+func TestCloudSyncService_CompressionRefused(t *testing.T) {
+	var plain int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !bytes.HasPrefix(body, []byte("{")) {
+			t.Errorf("expected a plain JSON body, got %.20q", body)
+		}
+		plain++
+	}))
+	defer server.Close()
+
+	service := NewCloudSyncService(server.URL, "test-key")
+	large := SyncData{Notes: []*notes.Note{{ID: "big", Content: strings.Repeat("x", 2048)}}}
+	for i := 0; i < 2; i++ {
+		if err := service.Push(large); err != nil {
+			t.Fatalf("Push() error = %v", err)
+		}
+	}
+	if plain != 2 {
+		t.Errorf("a server refusing gzip should get plain bodies, got %d", plain)
+	}
+}
+
+func TestManager_TransferStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/pull" {
+			json.NewEncoder(w).Encode(SyncData{Version: "1.0"})
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	manager, err := NewManager(NewCloudSyncService(server.URL, "test-key"), tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.Sync()
+	transfer := manager.GetSyncStatus().Transfer
+	if transfer == nil || transfer.Received == 0 {
+		t.Fatalf("the status should count the bytes of the sync, got %+v", transfer)
+	}
+
+	// the count is kept with the rest of the status
+	manager, _ = NewManager(&mockSyncService{}, tmpDir)
+	if restored := manager.GetSyncStatus().Transfer; restored == nil || *restored != *transfer {
+		t.Errorf("restored transfer = %+v, want %+v", restored, transfer)
+	}
+}
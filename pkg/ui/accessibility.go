@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+)
+
+// stillSpinner stands for the spinner when motion is reduced
+const stillSpinner = "…"
+
+// asciiBorders replaces box drawing characters, which screen readers
+// spell out and some fonts lack, with ASCII of the same width
+var asciiBorders = strings.NewReplacer(
+	"─", "-", "━", "-", "═", "=",
+	"│", "|", "┃", "|", "║", "|",
+	"┼", "+", "╋", "+", "╬", "+",
+	"╭", "+", "╮", "+", "╰", "+", "╯", "+",
+	"┌", "+", "┐", "+", "└", "+", "┘", "+",
+	"┏", "+", "┓", "+", "┗", "+", "┛", "+",
+	"╔", "+", "╗", "+", "╚", "+", "╝", "+",
+	"├", "+", "┤", "+", "┬", "+", "┴", "+",
+	"┣", "+", "┫", "+", "┳", "+", "┻", "+",
+	"╠", "+", "╣", "+", "╦", "+", "╩", "+",
+	"▸", ">",
+)
+
+// accessibility returns the accessibility settings of the config
+func (m Model) accessibility() config.AccessibilityConfig {
+	if m.Config == nil {
+		return config.AccessibilityConfig{}
+	}
+	return m.Config.Accessibility
+}
+
+// RenderLinear renders the table as a list read top to bottom: a line per
+// shortcut with what it does in each app, and a line per category. The row
+// under the cursor is marked with ">" as well as styled.
+func (r *TableRenderer) RenderLinear(rows [][]string, cursorY int) string {
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for y := 1; y < len(rows); y++ {
+		row := rows[y]
+		if apps.IsSectionRow(row) {
+			title := strings.TrimPrefix(sanitizeText(row[0]), apps.SectionPrefix)
+			b.WriteString("\n" + r.theme.CategoryStyle.Render("Category "+title) + "\n")
+			continue
+		}
+
+		var actions []string
+		for x, cell := range row[1:] {
+			if cell = r.displayCell(x+1, y, cell); !isEmptyCell(cell) {
+				actions = append(actions, r.displayCell(x+1, 0, rows[0][x+1])+": "+cell)
+			}
+		}
+		line := r.displayCell(0, y, row[0])
+		if len(actions) > 0 {
+			line += " — " + strings.Join(actions, "; ")
+		}
+
+		if y == cursorY {
+			b.WriteString(r.theme.SelectedRowStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	return b.String()
+}
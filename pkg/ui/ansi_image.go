@@ -0,0 +1,356 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// The cells of rendered ANSI text are cellWidth by cellHeight pixels, and
+// glyphs of the 5x7 font are drawn glyphScale times their size
+const (
+	cellWidth    = 12
+	cellHeight   = 22
+	glyphScale   = 2
+	imagePadding = 16
+)
+
+// glyphs is a 5x7 pixel font of printable ASCII. Each row is a byte whose
+// five low bits are the pixels, the highest of them leftmost.
+var glyphs = map[rune][7]byte{
+	' ': {}, '!': {0x04, 0x04, 0x04, 0x04, 0x04, 0x00, 0x04},
+	'"': {0x0A, 0x0A, 0x0A}, '#': {0x0A, 0x0A, 0x1F, 0x0A, 0x1F, 0x0A, 0x0A},
+	'$': {0x04, 0x0F, 0x14, 0x0E, 0x05, 0x1E, 0x04}, '%': {0x18, 0x19, 0x02, 0x04, 0x08, 0x13, 0x03},
+	'&': {0x0C, 0x12, 0x14, 0x08, 0x15, 0x12, 0x0D}, '\'': {0x0C, 0x04, 0x08},
+	'(': {0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02}, ')': {0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08},
+	'*': {0x00, 0x04, 0x15, 0x0E, 0x15, 0x04, 0x00}, '+': {0x00, 0x04, 0x04, 0x1F, 0x04, 0x04, 0x00},
+	',': {0x00, 0x00, 0x00, 0x00, 0x0C, 0x04, 0x08}, '-': {0x00, 0x00, 0x00, 0x1F},
+	'.': {0x00, 0x00, 0x00, 0x00, 0x00, 0x0C, 0x0C}, '/': {0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00},
+	'0': {0x0E, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0E}, '1': {0x04, 0x0C, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'2': {0x0E, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1F}, '3': {0x1F, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0E},
+	'4': {0x02, 0x06, 0x0A, 0x12, 0x1F, 0x02, 0x02}, '5': {0x1F, 0x10, 0x1E, 0x01, 0x01, 0x11, 0x0E},
+	'6': {0x06, 0x08, 0x10, 0x1E, 0x11, 0x11, 0x0E}, '7': {0x1F, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8': {0x0E, 0x11, 0x11, 0x0E, 0x11, 0x11, 0x0E}, '9': {0x0E, 0x11, 0x11, 0x0F, 0x01, 0x02, 0x0C},
+	':': {0x00, 0x0C, 0x0C, 0x00, 0x0C, 0x0C, 0x00}, ';': {0x00, 0x0C, 0x0C, 0x00, 0x0C, 0x04, 0x08},
+	'<': {0x02, 0x04, 0x08, 0x10, 0x08, 0x04, 0x02}, '=': {0x00, 0x00, 0x1F, 0x00, 0x1F},
+	'>': {0x08, 0x04, 0x02, 0x01, 0x02, 0x04, 0x08}, '?': {0x0E, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04},
+	'@': {0x0E, 0x11, 0x01, 0x0D, 0x15, 0x15, 0x0E}, 'A': {0x0E, 0x11, 0x11, 0x11, 0x1F, 0x11, 0x11},
+	'B': {0x1E, 0x11, 0x11, 0x1E, 0x11, 0x11, 0x1E}, 'C': {0x0E, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0E},
+	'D': {0x1C, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1C}, 'E': {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x1F},
+	'F': {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x10}, 'G': {0x0E, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0F},
+	'H': {0x11, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11}, 'I': {0x0E, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'J': {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0C}, 'K': {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L': {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1F}, 'M': {0x11, 0x1B, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N': {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11}, 'O': {0x0E, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'P': {0x1E, 0x11, 0x11, 0x1E, 0x10, 0x10, 0x10}, 'Q': {0x0E, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0D},
+	'R': {0x1E, 0x11, 0x11, 0x1E, 0x14, 0x12, 0x11}, 'S': {0x0F, 0x10, 0x10, 0x0E, 0x01, 0x01, 0x1E},
+	'T': {0x1F, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}, 'U': {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'V': {0x11, 0x11, 0x11, 0x11, 0x11, 0x0A, 0x04}, 'W': {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0A},
+	'X': {0x11, 0x11, 0x0A, 0x04, 0x0A, 0x11, 0x11}, 'Y': {0x11, 0x11, 0x11, 0x0A, 0x04, 0x04, 0x04},
+	'Z': {0x1F, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1F}, '[': {0x0E, 0x08, 0x08, 0x08, 0x08, 0x08, 0x0E},
+	'\\': {0x00, 0x10, 0x08, 0x04, 0x02, 0x01, 0x00}, ']': {0x0E, 0x02, 0x02, 0x02, 0x02, 0x02, 0x0E},
+	'^': {0x04, 0x0A, 0x11}, '_': {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F},
+	'`': {0x08, 0x04, 0x02}, 'a': {0x00, 0x00, 0x0E, 0x01, 0x0F, 0x11, 0x0F},
+	'b': {0x10, 0x10, 0x16, 0x19, 0x11, 0x11, 0x1E}, 'c': {0x00, 0x00, 0x0E, 0x10, 0x10, 0x11, 0x0E},
+	'd': {0x01, 0x01, 0x0D, 0x13, 0x11, 0x11, 0x0F}, 'e': {0x00, 0x00, 0x0E, 0x11, 0x1F, 0x10, 0x0E},
+	'f': {0x06, 0x09, 0x08, 0x1C, 0x08, 0x08, 0x08}, 'g': {0x00, 0x0F, 0x11, 0x11, 0x0F, 0x01, 0x0E},
+	'h': {0x10, 0x10, 0x16, 0x19, 0x11, 0x11, 0x11}, 'i': {0x04, 0x00, 0x0C, 0x04, 0x04, 0x04, 0x0E},
+	'j': {0x02, 0x00, 0x06, 0x02, 0x02, 0x12, 0x0C}, 'k': {0x10, 0x10, 0x12, 0x14, 0x18, 0x14, 0x12},
+	'l': {0x0C, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0E}, 'm': {0x00, 0x00, 0x1A, 0x15, 0x15, 0x11, 0x11},
+	'n': {0x00, 0x00, 0x16, 0x19, 0x11, 0x11, 0x11}, 'o': {0x00, 0x00, 0x0E, 0x11, 0x11, 0x11, 0x0E},
+	'p': {0x00, 0x00, 0x1E, 0x11, 0x1E, 0x10, 0x10}, 'q': {0x00, 0x00, 0x0D, 0x13, 0x0F, 0x01, 0x01},
+	'r': {0x00, 0x00, 0x16, 0x19, 0x10, 0x10, 0x10}, 's': {0x00, 0x00, 0x0E, 0x10, 0x0E, 0x01, 0x1E},
+	't': {0x08, 0x08, 0x1C, 0x08, 0x08, 0x09, 0x06}, 'u': {0x00, 0x00, 0x11, 0x11, 0x11, 0x13, 0x0D},
+	'v': {0x00, 0x00, 0x11, 0x11, 0x11, 0x0A, 0x04}, 'w': {0x00, 0x00, 0x11, 0x11, 0x15, 0x15, 0x0A},
+	'x': {0x00, 0x00, 0x11, 0x0A, 0x04, 0x0A, 0x11}, 'y': {0x00, 0x00, 0x11, 0x11, 0x0F, 0x01, 0x0E},
+	'z': {0x00, 0x00, 0x1F, 0x02, 0x04, 0x08, 0x1F}, '{': {0x02, 0x04, 0x04, 0x08, 0x04, 0x04, 0x02},
+	'|': {0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}, '}': {0x08, 0x04, 0x04, 0x02, 0x04, 0x04, 0x08},
+	'~': {0x00, 0x00, 0x08, 0x15, 0x02},
+}
+
+// boxArms tells which of the up, down, left and right arms the box drawing
+// characters of tables have, so they are drawn joined across cells
+var boxArms = map[rune][4]bool{
+	'─': {false, false, true, true}, '━': {false, false, true, true}, '═': {false, false, true, true},
+	'│': {true, true, false, false}, '┃': {true, true, false, false}, '║': {true, true, false, false},
+	'┼': {true, true, true, true}, '╋': {true, true, true, true}, '╬': {true, true, true, true},
+	'├': {true, true, false, true}, '┤': {true, true, true, false},
+	'┬': {false, true, true, true}, '┴': {true, false, true, true},
+	'┌': {false, true, false, true}, '╭': {false, true, false, true},
+	'┐': {false, true, true, false}, '╮': {false, true, true, false},
+	'└': {true, false, false, true}, '╰': {true, false, false, true},
+	'┘': {true, false, true, false}, '╯': {true, false, true, false},
+}
+
+// sgrState is the style text is drawn with, as set by SGR sequences
+type sgrState struct {
+	fg, bg                 color.Color
+	bold, faint, underline bool
+	reverse, hasFg, hasBg  bool
+}
+
+// renderANSI draws text holding ANSI SGR escape sequences, as rendered by
+// lipgloss, on an image with the given default colors
+func renderANSI(text string, fg, bg color.Color) *image.RGBA {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	columns := 0
+	for _, line := range lines {
+		columns = max(columns, runewidth.StringWidth(stripANSI(line)))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, columns*cellWidth+2*imagePadding, len(lines)*cellHeight+2*imagePadding))
+	fill(img, img.Bounds(), bg)
+
+	state := sgrState{}
+	for row, line := range lines {
+		col := 0
+		for i := 0; i < len(line); {
+			if line[i] == '\x1b' {
+				i = readEscape(line, i, &state)
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(line[i:])
+			i += size
+			width := runewidth.RuneWidth(r)
+			if width == 0 {
+				continue
+			}
+
+			cellFg, cellBg := fg, bg
+			if state.hasFg {
+				cellFg = state.fg
+			}
+			if state.hasBg {
+				cellBg = state.bg
+			}
+			if state.reverse {
+				cellFg, cellBg = cellBg, cellFg
+			}
+			if state.faint {
+				cellFg = blend(cellFg, cellBg)
+			}
+
+			x := imagePadding + col*cellWidth
+			y := imagePadding + row*cellHeight
+			cell := image.Rect(x, y, x+width*cellWidth, y+cellHeight)
+			fill(img, cell, cellBg)
+			drawRune(img, r, cell, cellFg, state.bold)
+			if state.underline {
+				fill(img, image.Rect(cell.Min.X, cell.Max.Y-3, cell.Max.X, cell.Max.Y-2), cellFg)
+			}
+			col += width
+		}
+	}
+	return img
+}
+
+// readEscape applies the escape sequence at s[i] to state and returns the
+// index after it. Sequences other than SGR are skipped.
+func readEscape(s string, i int, state *sgrState) int {
+	if i+1 < len(s) && s[i+1] == ']' {
+		// an operating system command, such as a hyperlink, ends with
+		// BEL or ESC \
+		end := i + 2
+		for end < len(s) && s[end] != '\a' && !(s[end] == '\x1b' && end+1 < len(s) && s[end+1] == '\\') {
+			end++
+		}
+		if end < len(s) && s[end] == '\x1b' {
+			end++
+		}
+		return end + 1
+	}
+	if i+1 >= len(s) || s[i+1] != '[' {
+		return i + 2
+	}
+	end := i + 2
+	for end < len(s) && (s[end] < 0x40 || s[end] > 0x7E) {
+		end++
+	}
+	if end < len(s) && s[end] == 'm' {
+		state.apply(s[i+2 : end])
+	}
+	return end + 1
+}
+
+// apply changes the state by the parameters of an SGR sequence
+func (s *sgrState) apply(params string) {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, _ := strconv.Atoi(codes[i])
+		switch {
+		case code == 0:
+			*s = sgrState{}
+		case code == 1:
+			s.bold = true
+		case code == 2:
+			s.faint = true
+		case code == 4:
+			s.underline = true
+		case code == 7:
+			s.reverse = true
+		case code == 22:
+			s.bold, s.faint = false, false
+		case code == 24:
+			s.underline = false
+		case code == 27:
+			s.reverse = false
+		case code >= 30 && code <= 37:
+			s.fg, s.hasFg = ansiColor(code-30), true
+		case code >= 90 && code <= 97:
+			s.fg, s.hasFg = ansiColor(code-90+8), true
+		case code >= 40 && code <= 47:
+			s.bg, s.hasBg = ansiColor(code-40), true
+		case code >= 100 && code <= 107:
+			s.bg, s.hasBg = ansiColor(code-100+8), true
+		case code == 39:
+			s.hasFg = false
+		case code == 49:
+			s.hasBg = false
+		case code == 38 || code == 48:
+			c, used := extendedColor(codes[i+1:])
+			i += used
+			if c == nil {
+				continue
+			}
+			if code == 38 {
+				s.fg, s.hasFg = c, true
+			} else {
+				s.bg, s.hasBg = c, true
+			}
+		}
+	}
+}
+
+// extendedColor reads a 256 color (5;n) or true color (2;r;g;b) from the
+// parameters following 38 or 48, returning how many it used
+func extendedColor(params []string) (color.Color, int) {
+	if len(params) >= 2 && params[0] == "5" {
+		n, _ := strconv.Atoi(params[1])
+		return ansiColor(n), 2
+	}
+	if len(params) >= 4 && params[0] == "2" {
+		r, _ := strconv.Atoi(params[1])
+		g, _ := strconv.Atoi(params[2])
+		b, _ := strconv.Atoi(params[3])
+		return color.RGBA{uint8(r), uint8(g), uint8(b), 255}, 4
+	}
+	return nil, len(params)
+}
+
+// systemColors are the first 16 colors of the xterm palette
+var systemColors = [16]color.RGBA{
+	{0, 0, 0, 255}, {205, 0, 0, 255}, {0, 205, 0, 255}, {205, 205, 0, 255},
+	{0, 0, 238, 255}, {205, 0, 205, 255}, {0, 205, 205, 255}, {229, 229, 229, 255},
+	{127, 127, 127, 255}, {255, 0, 0, 255}, {0, 255, 0, 255}, {255, 255, 0, 255},
+	{92, 92, 255, 255}, {255, 0, 255, 255}, {0, 255, 255, 255}, {255, 255, 255, 255},
+}
+
+// ansiColor returns color n of the xterm 256 color palette
+func ansiColor(n int) color.RGBA {
+	switch {
+	case n < 0 || n > 255:
+		return systemColors[7]
+	case n < 16:
+		return systemColors[n]
+	case n < 232:
+		levels := [6]uint8{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return color.RGBA{levels[n/36], levels[n/6%6], levels[n%6], 255}
+	default:
+		gray := uint8(8 + 10*(n-232))
+		return color.RGBA{gray, gray, gray, 255}
+	}
+}
+
+// blend mixes two colors half and half, for faint text
+func blend(a, b color.Color) color.Color {
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	return color.RGBA{uint8((ar + br) >> 9), uint8((ag + bg) >> 9), uint8((ab + bb) >> 9), 255}
+}
+
+// stripANSI removes the escape sequences of s
+func stripANSI(s string) string {
+	var b strings.Builder
+	var state sgrState
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' {
+			i = readEscape(s, i, &state)
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// fill paints rect of img with c
+func fill(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	rect = rect.Intersect(img.Bounds())
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+// drawRune draws r in cell: box drawing characters as lines joining the
+// neighbouring cells, characters of the font as glyphs, and anything else
+// as an outlined box
+func drawRune(img *image.RGBA, r rune, cell image.Rectangle, c color.Color, bold bool) {
+	if r == ' ' {
+		return
+	}
+	if arms, ok := boxArms[r]; ok {
+		cx, cy := (cell.Min.X+cell.Max.X)/2, (cell.Min.Y+cell.Max.Y)/2
+		if arms[0] {
+			fill(img, image.Rect(cx-1, cell.Min.Y, cx+1, cy+1), c)
+		}
+		if arms[1] {
+			fill(img, image.Rect(cx-1, cy-1, cx+1, cell.Max.Y), c)
+		}
+		if arms[2] {
+			fill(img, image.Rect(cell.Min.X, cy-1, cx+1, cy+1), c)
+		}
+		if arms[3] {
+			fill(img, image.Rect(cx-1, cy-1, cell.Max.X, cy+1), c)
+		}
+		return
+	}
+	if r == '…' {
+		for i := 0; i < 3; i++ {
+			x := cell.Min.X + 2 + i*3
+			fill(img, image.Rect(x, cell.Max.Y-8, x+2, cell.Max.Y-6), c)
+		}
+		return
+	}
+
+	glyph, ok := glyphs[r]
+	left := cell.Min.X + (cell.Dx()-5*glyphScale)/2
+	top := cell.Min.Y + (cell.Dy()-7*glyphScale)/2
+	if !ok {
+		box := image.Rect(left, top, left+5*glyphScale, top+7*glyphScale)
+		fill(img, image.Rect(box.Min.X, box.Min.Y, box.Max.X, box.Min.Y+1), c)
+		fill(img, image.Rect(box.Min.X, box.Max.Y-1, box.Max.X, box.Max.Y), c)
+		fill(img, image.Rect(box.Min.X, box.Min.Y, box.Min.X+1, box.Max.Y), c)
+		fill(img, image.Rect(box.Max.X-1, box.Min.Y, box.Max.X, box.Max.Y), c)
+		return
+	}
+	for row, bits := range glyph {
+		for col := 0; col < 5; col++ {
+			if bits&(0x10>>col) == 0 {
+				continue
+			}
+			x, y := left+col*glyphScale, top+row*glyphScale
+			width := glyphScale
+			if bold {
+				width++
+			}
+			fill(img, image.Rect(x, y, x+width, y+glyphScale), c)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// spinnerInterval is how often the spinner of running tasks turns
+const spinnerInterval = 100 * time.Millisecond
+
+// spinnerFrames are drawn in turn while tasks run
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// task is I/O running in the background on behalf of a view
+type task struct {
+	id     int
+	label  string
+	view   ViewMode
+	cancel context.CancelFunc
+}
+
+// taskDoneMsg carries the result of a task back to Update
+type taskDoneMsg struct {
+	id     int
+	result tea.Msg
+}
+
+// spinnerTickMsg turns the spinner while tasks run
+type spinnerTickMsg struct{}
+
+func spinnerTick() tea.Cmd {
+	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg {
+		return spinnerTickMsg{}
+	})
+}
+
+// startTask runs work in the background, showing label next to a spinner
+// in the status bar until its result comes back to Update. Esc cancels the
+// tasks of the view they were started from: the context given to work is
+// cancelled, for its requests to stop, and what it returns is dropped.
+func (m *Model) startTask(label string, work func(ctx context.Context) tea.Msg) tea.Cmd {
+	m.taskSeq++
+	id := m.taskSeq
+	ctx, cancel := context.WithCancel(context.Background())
+	// a copy, as the tasks of other copies of the model must stay apart
+	m.tasks = append(m.tasks[:len(m.tasks):len(m.tasks)], task{id: id, label: label, view: m.ViewMode, cancel: cancel})
+
+	run := func() tea.Msg {
+		return taskDoneMsg{id: id, result: work(ctx)}
+	}
+	if m.spinning || m.accessibility().ReducedMotion {
+		return run
+	}
+	m.spinning = true
+	return tea.Batch(run, spinnerTick())
+}
+
+// finishTask forgets the task with the ID, reporting whether it was still
+// running rather than cancelled
+func (m *Model) finishTask(id int) bool {
+	for i, t := range m.tasks {
+		if t.id == id {
+			t.cancel()
+			m.tasks = append(m.tasks[:i:i], m.tasks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// cancelTasks cancels the tasks started from view, returning how many
+// there were
+func (m *Model) cancelTasks(view ViewMode) int {
+	var kept []task
+	cancelled := 0
+	for _, t := range m.tasks {
+		if t.view == view {
+			t.cancel()
+			cancelled++
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	m.tasks = kept
+	return cancelled
+}
+
+// Pending returns the labels of the tasks running in the background,
+// followed by the downloads when some have not ended
+func (m Model) Pending() []string {
+	labels := make([]string, len(m.tasks), len(m.tasks)+1)
+	for i, t := range m.tasks {
+		labels[i] = t.label
+	}
+	if label := m.downloadsLabel(); label != "" {
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+// pendingLabel renders the spinner and what the running tasks do, or ""
+// when none runs
+func (m Model) pendingLabel() string {
+	pending := m.Pending()
+	if len(pending) == 0 {
+		return ""
+	}
+	frame := spinnerFrames[m.spinnerFrame%len(spinnerFrames)]
+	if m.accessibility().ReducedMotion {
+		frame = stillSpinner
+	}
+	return frame + " " + strings.Join(pending, ", ")
+}
+
+// handleTaskMsg handles the messages of background tasks and the spinner
+func (m Model) handleTaskMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case taskDoneMsg:
+		if !m.finishTask(msg.id) {
+			return m, nil
+		}
+		return m.Update(msg.result)
+	case spinnerTickMsg:
+		if len(m.Pending()) == 0 {
+			m.spinning = false
+			return m, nil
+		}
+		m.spinnerFrame++
+		return m, spinnerTick()
+	}
+	return m, nil
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"errors"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/remuscazacu/cheat-go/pkg/online"
+)
+
+// blocklist returns the online content the config hides
+func (m Model) blocklist() online.Blocklist {
+	if m.Config == nil {
+		return online.Blocklist{}
+	}
+	return online.Blocklist(m.Config.Online.Blocklist)
+}
+
+// HandleReportInput handles the prompt for the reason to report the sheet
+// under the cursor for
+func (m Model) HandleReportInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "esc", "q", "ctrl+c":
+		m.ReportMode = false
+		return m, nil
+	case "1", "2", "3", "4":
+		m.ReportMode = false
+		cmd := m.reportSelectedSheet(online.ReportReasons[key[0]-'1'])
+		return m, cmd
+	}
+	return m, nil
+}
+
+// setSheetReport tells whether the sheet was reported
+func (m *Model) setSheetReport(sheet online.CheatSheet, reason string, err error) tea.Cmd {
+	switch {
+	case errors.Is(err, online.ErrNotSupported):
+		return m.notify(ToastWarn, "This provider takes no reports; block %s with %s to hide it", sheet.Name, m.boundKey("online.block"))
+	case err != nil:
+		return m.notify(ToastError, "Error reporting %s: %v", sheet.Name, err)
+	}
+	return m.notify(ToastInfo, "Reported %s as %s", sheet.Name, reason)
+}
+
+// blockSelected adds the repository under the cursor to the blocklist of
+// the config, or the author of the sheet under the cursor when the sheets
+// are focused, and hides what it blocks
+func (m *Model) blockSelected() tea.Cmd {
+	if m.Config == nil {
+		return nil
+	}
+	blocklist := &m.Config.Online.Blocklist
+
+	var blocked string
+	switch {
+	case m.SheetFocus && m.SheetCursor < len(m.CheatSheets):
+		blocked = m.CheatSheets[m.SheetCursor].Author
+		if blocked == "" {
+			return m.notify(ToastInfo, "%s has no author to block", m.CheatSheets[m.SheetCursor].Name)
+		}
+		blocklist.Authors = append(slices.Clone(blocklist.Authors), blocked)
+	case !m.SheetFocus && m.RepoCursor < len(m.ReposList):
+		repo := m.ReposList[m.RepoCursor]
+		blocked = repo.Name
+		blocklist.Repositories = append(slices.Clone(blocklist.Repositories), repo.URL)
+	default:
+		return nil
+	}
+
+	if client, ok := m.OnlineClient.(interface{ SetBlocklist(online.Blocklist) }); ok {
+		client.SetBlocklist(m.blocklist())
+	}
+	m.hideBlocked()
+
+	if err := m.SaveConfig(); err != nil {
+		return m.notify(ToastError, "Blocked %s for this session, but saving config failed: %v", blocked, err)
+	}
+	return m.notify(ToastInfo, "Blocked %s", blocked)
+}
+
+// hideBlocked drops the repositories and sheets the blocklist matches from
+// the online view
+func (m *Model) hideBlocked() {
+	blocklist := m.blocklist()
+	m.ReposList = slices.DeleteFunc(slices.Clone(m.ReposList), blocklist.BlocksRepository)
+	m.CheatSheets = slices.DeleteFunc(slices.Clone(m.CheatSheets), blocklist.BlocksSheet)
+	m.RepoCursor = min(m.RepoCursor, max(len(m.ReposList)-1, 0))
+	m.SheetCursor = min(m.SheetCursor, max(len(m.CheatSheets)-1, 0))
+	if len(m.CheatSheets) == 0 {
+		m.SheetFocus = false
+	}
+	if m.SheetPreview != nil && blocklist.BlocksSheet(*m.SheetPreview) {
+		m.SheetPreview = nil
+	}
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// ClipboardWriter puts text on the system clipboard. It is a variable so
+// tests and other platforms can replace it.
+var ClipboardWriter = writeClipboard
+
+// clipboardCommands are the programs tried in turn to write the clipboard
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// writeClipboard pipes text to the first clipboard program installed, or
+// asks the terminal to set the clipboard with an OSC 52 sequence, which
+// also works over SSH, when there is none
+func writeClipboard(text string) error {
+	for _, command := range clipboardCommands {
+		if command[0] == "wl-copy" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			continue
+		}
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s failed: %v: %s", command[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+	termenv.Copy(text)
+	return nil
+}
+
+// isEmptyCell reports whether a table cell shows no shortcut
+func isEmptyCell(cell string) bool {
+	return cell == "" || cell == "-"
+}
+
+// copyRow copies the row under the cursor, the shortcut and what it does
+// in every app, to the clipboard as a markdown table
+func (m *Model) copyRow() tea.Cmd {
+	if m.CursorY < 1 || m.CursorY >= len(m.Rows) || m.isSection(m.CursorY) {
+		return m.notify(ToastWarn, "Move the cursor to a shortcut to copy its row")
+	}
+	row := m.Rows[m.CursorY]
+	text := string(m.Renderer.exportMarkdown([][]string{m.Rows[0], row}))
+	if err := ClipboardWriter(text); err != nil {
+		return m.notify(ToastError, "Error copying to the clipboard: %v", err)
+	}
+	return m.notify(ToastInfo, "Copied the row of %s to the clipboard", m.Renderer.displayCell(0, m.CursorY, row[0]))
+}
+
+// copyColumn copies the columns of the app under the cursor, with the
+// shortcuts it has, to the clipboard as a markdown table
+func (m *Model) copyColumn() tea.Cmd {
+	app := m.SelectedApp()
+	if app == "" {
+		return m.notify(ToastWarn, "Move the cursor to an app column to copy it")
+	}
+
+	columns := []int{0}
+	for x, header := range m.Rows[0] {
+		if name, _ := apps.ColumnApp(header); x > 0 && name == app {
+			columns = append(columns, x)
+		}
+	}
+
+	var rows [][]string
+	section := -1
+	count := 0
+	for y, row := range m.Rows {
+		if m.isSection(y) {
+			section = y
+			continue
+		}
+		cells := make([]string, len(columns))
+		empty := true
+		for i, x := range columns {
+			cells[i] = row[x]
+			empty = empty && (i == 0 || isEmptyCell(row[x]))
+		}
+		if y > 0 && empty {
+			continue
+		}
+		// a category is copied with its first shortcut of the app
+		if section > 0 {
+			rows = append(rows, make([]string, len(columns)))
+			rows[len(rows)-1][0] = m.Rows[section][0]
+			section = -1
+		}
+		if y > 0 {
+			count++
+		}
+		rows = append(rows, cells)
+	}
+
+	if err := ClipboardWriter(string(m.Renderer.exportMarkdown(rows))); err != nil {
+		return m.notify(ToastError, "Error copying to the clipboard: %v", err)
+	}
+	return m.notify(ToastInfo, "Copied the %s column to the clipboard (%d shortcuts)", app, count)
+}
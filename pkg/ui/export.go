@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+var ErrExportFormat = errors.New("unsupported export format")
+
+// ExportFormats are the formats a table can be exported to, with the
+// extension of their files
+var ExportFormats = map[string]string{
+	"markdown": ".md",
+	"html":     ".html",
+	"png":      ".png",
+}
+
+// ExportFormat returns the export format of a file name's extension
+func ExportFormat(path string) (string, bool) {
+	for format, ext := range ExportFormats {
+		if strings.HasSuffix(strings.ToLower(path), ext) {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// Export renders rows, a table as built by the registry, in format: a
+// markdown table, a standalone HTML page in the colors of the theme, or a
+// PNG image of the table as drawn in the terminal
+func (r *TableRenderer) Export(rows [][]string, format string) ([]byte, error) {
+	switch format {
+	case "markdown":
+		return r.exportMarkdown(rows), nil
+	case "html":
+		return r.exportHTML(rows)
+	case "png":
+		return r.exportPNG(rows)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrExportFormat, format)
+	}
+}
+
+// exportMarkdown renders rows as a markdown table, rows heading categories
+// as a bold cell
+func (r *TableRenderer) exportMarkdown(rows [][]string) []byte {
+	var b strings.Builder
+	for y, row := range rows {
+		cells := make([]string, len(row))
+		for x, cell := range row {
+			cell = strings.ReplaceAll(r.displayCell(x, y, cell), "|", `\|`)
+			if y > 0 && x == 0 && apps.IsSectionRow(row) {
+				cell = "**" + strings.TrimPrefix(cell, apps.SectionPrefix) + "**"
+			}
+			cells[x] = cell
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if y == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", len(row)) + "\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+// htmlTable is the page of an exported table
+var htmlTable = template.Must(template.New("table").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>cheat-go shortcuts</title>
+<style>
+body { background: {{.Background}}; color: {{.Foreground}}; font-family: ui-monospace, Menlo, Consolas, monospace; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.2em 0.8em; text-align: left; border-right: 1px solid {{.Border}}; }
+th:last-child, td:last-child { border-right: none; }
+th { {{.Header}} border-bottom: 1px solid {{.Border}}; }
+td { {{.Cell}} }
+tr.stripe td { {{.Stripe}} }
+tr.section td { {{.Header}} border-right: none; padding-top: 0.6em; }
+</style>
+</head>
+<body>
+<table>
+{{range .Rows}}{{if .Header}}<tr>{{range .Cells}}<th>{{.}}</th>{{end}}</tr>
+{{else if .Section}}<tr class="section"><td colspan="{{.Span}}">{{.Section}}</td></tr>
+{{else}}<tr{{if .Stripe}} class="stripe"{{end}}>{{range .Cells}}<td>{{.}}</td>{{end}}</tr>
+{{end}}{{end}}</table>
+</body>
+</html>
+`))
+
+// htmlRow is a row of an exported table
+type htmlRow struct {
+	Header, Stripe bool
+	Cells          []string
+	Section        string
+	Span           int
+}
+
+// exportHTML renders rows as a standalone HTML page in the colors of the
+// theme
+func (r *TableRenderer) exportHTML(rows [][]string) ([]byte, error) {
+	fg, bg := r.pageColors()
+	page := struct {
+		Background, Foreground, Border string
+		Header, Cell, Stripe           template.CSS
+		Rows                           []htmlRow
+	}{
+		Background: hexColor(bg),
+		Foreground: hexColor(fg),
+		Border:     hexColor(terminalColor(r.theme.BorderColor, fg)),
+		Header:     r.css(r.theme.HeaderStyle),
+		Cell:       r.css(r.theme.CellStyle),
+		Stripe:     r.css(r.theme.StripeStyle),
+	}
+
+	for y, row := range rows {
+		switch {
+		case y == 0:
+			page.Rows = append(page.Rows, htmlRow{Header: true, Cells: row})
+		case apps.IsSectionRow(row):
+			title := strings.TrimPrefix(row[0], apps.SectionPrefix)
+			page.Rows = append(page.Rows, htmlRow{Section: title, Span: len(row)})
+		default:
+			cells := make([]string, len(row))
+			for x, cell := range row {
+				cells[x] = r.displayCell(x, y, cell)
+			}
+			page.Rows = append(page.Rows, htmlRow{Cells: cells, Stripe: y%2 == 0 && r.stripes()})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := htmlTable.Execute(&buf, page); err != nil {
+		return nil, fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// css returns the CSS declarations of the colors and emphasis of style
+func (r *TableRenderer) css(style lipgloss.Style) template.CSS {
+	var b strings.Builder
+	if c, ok := colorOf(style.GetForeground()); ok {
+		b.WriteString("color: " + hexColor(c) + "; ")
+	}
+	if c, ok := colorOf(style.GetBackground()); ok {
+		b.WriteString("background: " + hexColor(c) + "; ")
+	}
+	if style.GetBold() {
+		b.WriteString("font-weight: bold; ")
+	}
+	if style.GetFaint() {
+		b.WriteString("opacity: 0.6; ")
+	}
+	if style.GetUnderline() {
+		b.WriteString("text-decoration: underline; ")
+	}
+	return template.CSS(b.String())
+}
+
+// exportPNG draws the table as rendered in the terminal, in true color
+// whatever the terminal supports, on a PNG image
+func (r *TableRenderer) exportPNG(rows [][]string) ([]byte, error) {
+	renderer := lipgloss.NewRenderer(io.Discard)
+	renderer.SetColorProfile(termenv.TrueColor)
+	table := r.WithTheme(r.theme.withRenderer(renderer)).Render(rows, -1, -1)
+
+	fg, bg := r.pageColors()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, renderANSI(table, fg, bg)); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// pageColors returns the default text and background colors of exports,
+// light or dark to suit the theme
+func (r *TableRenderer) pageColors() (color.Color, color.Color) {
+	if r.theme.Light {
+		return color.RGBA{28, 28, 28, 255}, color.RGBA{255, 255, 255, 255}
+	}
+	return color.RGBA{208, 208, 208, 255}, color.RGBA{28, 28, 28, 255}
+}
+
+// withRenderer returns a copy of the theme with its styles drawn by
+// renderer, such as one forcing a color profile
+func (t *Theme) withRenderer(renderer *lipgloss.Renderer) *Theme {
+	theme := *t
+	for _, style := range []*lipgloss.Style{
+		&theme.HeaderStyle, &theme.CellStyle, &theme.HighlightStyle, &theme.SelectedRowStyle,
+		&theme.CategoryStyle, &theme.SearchStyle, &theme.SearchInputStyle, &theme.StripeStyle,
+		&theme.StatusBarStyle,
+	} {
+		*style = style.Renderer(renderer)
+	}
+	return &theme
+}
+
+// colorOf converts a lipgloss color, an ANSI color number or a hex color,
+// reporting false for no color
+func colorOf(c lipgloss.TerminalColor) (color.Color, bool) {
+	var value string
+	switch c := c.(type) {
+	case lipgloss.Color:
+		value = string(c)
+	case lipgloss.AdaptiveColor:
+		value = c.Dark
+	default:
+		return nil, false
+	}
+
+	if n, err := strconv.Atoi(value); err == nil {
+		return ansiColor(n), true
+	}
+	if hex, ok := strings.CutPrefix(value, "#"); ok && len(hex) == 6 {
+		if rgb, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return color.RGBA{uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb), 255}, true
+		}
+	}
+	return nil, false
+}
+
+// terminalColor converts c, or returns fallback for no color
+func terminalColor(c lipgloss.TerminalColor, fallback color.Color) color.Color {
+	if converted, ok := colorOf(c); ok {
+		return converted
+	}
+	return fallback
+}
+
+// hexColor writes c as a CSS hex color
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+var exportRows = [][]string{
+	{"Shortcut", "vim"},
+	{apps.SectionPrefix + "navigation", ""},
+	{"gg", "top | start"},
+	{"<C-w>", "window"},
+}
+
+func TestExportMarkdown(t *testing.T) {
+	renderer := NewTableRenderer(DefaultTheme())
+	renderer.SetKeyNotation(apps.NotationEmacs)
+	data, err := renderer.Export(exportRows, "markdown")
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	want := "| Shortcut | vim |\n| --- | --- |\n| **navigation** |  |\n| gg | top \\| start |\n| C-w | window |\n"
+	if string(data) != want {
+		t.Errorf("markdown =\n%s\nwant\n%s", data, want)
+	}
+
+	if _, err := renderer.Export(exportRows, "pdf"); !errors.Is(err, ErrExportFormat) {
+		t.Errorf("expected ErrExportFormat, got %v", err)
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	data, err := NewTableRenderer(LightTheme()).Export(exportRows, "html")
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	page := string(data)
+	for _, want := range []string{
+		"background: #ffffff",              // light page
+		"th { color: #005faf; font-weight", // header color 25
+		`<tr class="section"><td colspan="2">navigation</td></tr>`,
+		"<td>top | start</td>",
+		`<tr class="stripe"><td>gg</td>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page should contain %q:\n%s", want, page)
+		}
+	}
+}
+
+func TestExportPNG(t *testing.T) {
+	data, err := NewTableRenderer(DefaultTheme()).Export(exportRows, "png")
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("not a PNG: %v", err)
+	}
+
+	// the columns fit "Shortcut" and "top | start" with padding, and a border
+	columns := 10 + 1 + 13
+	if bounds := img.Bounds(); bounds.Dx() != columns*cellWidth+2*imagePadding || bounds.Dy() != 5*cellHeight+2*imagePadding {
+		t.Errorf("image is %v", bounds)
+	}
+
+	// the header is drawn in the theme's color 205
+	header := ansiColor(205)
+	found := false
+	for x := imagePadding; x < imagePadding+10*cellWidth && !found; x++ {
+		for y := imagePadding; y < imagePadding+cellHeight; y++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == header {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		t.Error("the header should be drawn in the theme color")
+	}
+}
+
+func TestRenderANSI(t *testing.T) {
+	fg, bg := color.RGBA{255, 255, 255, 255}, color.RGBA{0, 0, 0, 255}
+	img := renderANSI("\x1b[48;2;10;20;30mA\x1b[0m\x1b[7mB\x1b[m\n│", fg, bg)
+	if img.Bounds().Dx() != 2*cellWidth+2*imagePadding || img.Bounds().Dy() != 2*cellHeight+2*imagePadding {
+		t.Fatalf("image is %v", img.Bounds())
+	}
+	corner := func(col, row int) color.Color {
+		return img.At(imagePadding+col*cellWidth, imagePadding+row*cellHeight)
+	}
+	if got := corner(0, 0); got != (color.RGBA{10, 20, 30, 255}) {
+		t.Errorf("true color background = %v", got)
+	}
+	if got := corner(1, 0); got != fg {
+		t.Errorf("reversed cell background = %v", got)
+	}
+	center := img.At(imagePadding+cellWidth/2, imagePadding+cellHeight+cellHeight/2)
+	if center != fg {
+		t.Errorf("box drawing line should cross the cell center, got %v", center)
+	}
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+)
+
+// pinnedHints are the actions whose hints stay in the hint bar when it is
+// cut short, so a narrow terminal still tells how to get out
+var pinnedHints = map[string]bool{"help": true, "quit": true, "close": true, "cancel": true}
+
+// hint is an entry of the hint bar: keys and what they do
+type hint struct {
+	keys   []string
+	text   string
+	pinned bool
+}
+
+func (h hint) String() string {
+	return strings.Join(h.keys, "/") + ": " + h.text
+}
+
+// hintKey spells the key of b for the hint bar: an arrow, Enter or Esc,
+// which work whatever the keybinds, else the key the config binds it to,
+// else all its fixed keys
+func (b binding) hintKey() string {
+	for _, key := range b.fixed {
+		switch key {
+		case "up", "down", "left", "right", "enter", "esc":
+			return displayKey(key)
+		}
+	}
+	if len(b.keys) > 0 {
+		return displayKey(b.keys[0])
+	}
+	names := make([]string, len(b.fixed))
+	for i, key := range b.fixed {
+		names[i] = displayKey(key)
+	}
+	return strings.Join(names, "/")
+}
+
+// hintApplies reports whether the action of group does something in the
+// state the view is in
+func (m Model) hintApplies(group, action string) bool {
+	switch group + "." + action {
+	case "table.prev_section", "table.next_section":
+		return m.Registry != nil && m.Registry.ShowCategories()
+	case "table.clear":
+		return m.LastSearch != ""
+	case "table.narrow_detail", "table.widen_detail":
+		return m.showDetail()
+	case "online.open":
+		return !m.SheetFocus
+	case "online.preview", "online.download", "online.rate", "online.report", "online.compare":
+		return m.SheetFocus
+	case "online.focus":
+		return len(m.CheatSheets) > 0
+	case "online.block":
+		if m.SheetFocus {
+			return m.SheetCursor < len(m.CheatSheets) && m.CheatSheets[m.SheetCursor].Author != ""
+		}
+		return m.RepoCursor < len(m.ReposList)
+	case "online.download_all":
+		return !m.SheetFocus && m.RepoCursor < len(m.ReposList)
+	case "online.downloads":
+		return len(m.Downloads) > 0
+	case "downloads.stop":
+		item, ok := m.selectedDownload()
+		return ok && !item.state.Finished()
+	case "downloads.retry":
+		item, ok := m.selectedDownload()
+		return ok && item.retryable()
+	case "downloads.retry_all":
+		return slices.ContainsFunc(m.Downloads, downloadItem.retryable)
+	case "downloads.clear":
+		return len(m.Downloads) > m.activeDownloads()
+	case "compare.merge":
+		return m.Comparison != nil && len(m.Comparison.Missing) > 0
+	case "online.subscribe":
+		return !m.SheetFocus
+	case "online.update":
+		return len(m.SheetUpdates) > 0
+	case "plugins.details", "plugins.unload", "plugins.reload":
+		return !m.PluginDetail
+	case "plugins.approve":
+		plugin, ok := m.selectedPlugin()
+		return !m.PluginDetail && ok && signature.Overridable(plugin.Untrusted)
+	case "sync.resolve":
+		return m.SyncStatus.HasConflicts
+	}
+	return true
+}
+
+// hints lists the hints of the keys of the view and mode shown, after the
+// keybinds of the config
+func (m Model) hints() []hint {
+	name := m.keyGroupName()
+	var hints []hint
+	for _, group := range m.keymap().groups {
+		if group.name != name {
+			continue
+		}
+		for _, b := range group.bindings {
+			if b.hint == "" || !m.hintApplies(name, b.action) {
+				continue
+			}
+			key := b.hintKey()
+			if last := len(hints) - 1; last >= 0 && hints[last].text == b.hint {
+				if !slices.Contains(hints[last].keys, key) {
+					hints[last].keys = append(hints[last].keys, key)
+				}
+				continue
+			}
+			hints = append(hints, hint{keys: []string{key}, text: b.hint, pinned: pinnedHints[b.action]})
+		}
+	}
+	return hints
+}
+
+// hintBar renders the hints of the view shown on a line as wide as the
+// terminal, dropping the hints that do not fit but the pinned ones
+func (m Model) hintBar() string {
+	return fitHints(m.hints(), m.Width)
+}
+
+// fitHints joins hints into a line of at most width columns, or of any
+// width when width is 0. Hints are dropped from the end, keeping the
+// pinned ones, and "…" stands for them.
+func fitHints(hints []hint, width int) string {
+	const sep = " • "
+	all := make([]string, len(hints))
+	for i, h := range hints {
+		all[i] = h.String()
+	}
+	line := strings.Join(all, sep)
+	if width <= 0 || displayWidth(line) <= width {
+		return line
+	}
+
+	var pinned []string
+	for _, h := range hints {
+		if h.pinned {
+			pinned = append(pinned, h.String())
+		}
+	}
+	tail := strings.Join(append([]string{"…"}, pinned...), sep)
+
+	var kept []string
+	for _, h := range hints {
+		if h.pinned {
+			continue
+		}
+		if displayWidth(strings.Join(slices.Concat(kept, []string{h.String(), tail}), sep)) > width {
+			break
+		}
+		kept = append(kept, h.String())
+	}
+	return truncateWidth(strings.Join(append(kept, tail), sep), max(width, 1), "…")
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/remuscazacu/cheat-go/pkg/plugins"
+)
+
+// hookTimeout bounds how long the plugin hooks of one event may run
+const hookTimeout = 5 * time.Second
+
+// hookResultMsg carries the results of plugin hooks back to Update
+type hookResultMsg struct {
+	results []plugins.HookResult
+}
+
+// runHooks returns a command calling the plugin hooks for event in the
+// background, or nil when no plugin has one
+func (m Model) runHooks(event plugins.Event) tea.Cmd {
+	if m.PluginLoader == nil {
+		return nil
+	}
+	hooked := m.PluginLoader.HookPlugins(event.Hook)
+	if len(hooked) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+		defer cancel()
+		return hookResultMsg{results: plugins.RunHooks(ctx, hooked, event)}
+	}
+}
+
+// showHookResults shows the messages and errors of plugin hooks as toasts
+// and keeps the errors as the last error of their plugin
+func (m *Model) showHookResults(results []plugins.HookResult) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, result := range results {
+		if result.Err != nil {
+			if loaded, err := m.PluginLoader.LoadedPlugin(result.Plugin); err == nil {
+				loaded.LastError = result.Err
+			}
+			cmds = append(cmds, m.notify(ToastError, "%s: %v", result.Plugin, result.Err))
+		} else if result.Message != "" {
+			cmds = append(cmds, m.notify(ToastInfo, "%s: %s", result.Plugin, result.Message))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// quit runs the quit hooks of plugins before quitting
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	if hooks := m.runHooks(plugins.Event{Hook: plugins.HookQuit}); hooks != nil {
+		return m, tea.Sequence(hooks, tea.Quit)
+	}
+	return m, tea.Quit
+}
+
+// selectShortcut runs the select hooks of plugins for the shortcut under
+// the cursor
+func (m Model) selectShortcut() (tea.Model, tea.Cmd) {
+	app, keys, ok := m.SelectedShortcut()
+	if !ok {
+		return m, nil
+	}
+	selection := plugins.Selection{App: app, Keys: keys, Description: m.Rows[m.CursorY][m.CursorX]}
+	return m, m.runHooks(plugins.Event{Hook: plugins.HookSelect, Selection: selection})
+}
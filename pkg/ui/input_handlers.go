@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/remuscazacu/cheat-go/pkg/plugins"
 )
 
 func (m Model) HandleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -13,32 +15,45 @@ func (m Model) HandleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc", "ctrl+[":
 		m.SearchMode = false
 		m.SearchQuery = ""
-		m.Rows = m.AllRows
-		m.LastSearch = ""
-		m.CursorY = 1
+		m.SearchHistoryPos = 0
+		m.LastSearch = m.searchBefore
+		m.applyTableFilters()
 		return m, nil
 	case "ctrl+u":
 		m.SearchQuery = ""
-		return m, nil
+		m.SearchHistoryPos = 0
+		cmd := m.debounceSearch()
+		return m, cmd
 	case "enter":
 		m.SearchMode = false
-		if m.SearchQuery == "" {
-			m.Rows = m.AllRows
-			m.LastSearch = ""
-		} else {
-			m.Rows = m.Registry.SearchTableData(m.Config.Apps, m.SearchQuery)
-			m.LastSearch = m.SearchQuery
-		}
-		m.CursorY = 1
-		return m, nil
+		m.SearchHistoryPos = 0
+		m.LastSearch = m.SearchQuery
+		m.recordSearch(m.SearchQuery)
+		m.applyTableFilters()
+		return m, m.runHooks(plugins.Event{Hook: plugins.HookSearch, Query: m.LastSearch})
+	case "up":
+		m.recallSearch(1)
+		cmd := m.debounceSearch()
+		return m, cmd
+	case "down":
+		m.recallSearch(-1)
+		cmd := m.debounceSearch()
+		return m, cmd
+	case "ctrl+h":
+		return m.openSearchHistory()
 	case "backspace":
 		if len(m.SearchQuery) > 0 {
 			m.SearchQuery = m.SearchQuery[:len(m.SearchQuery)-1]
 		}
-		return m, nil
+		m.SearchHistoryPos = 0
+		cmd := m.debounceSearch()
+		return m, cmd
 	default:
 		if len(msg.String()) == 1 {
 			m.SearchQuery += msg.String()
+			m.SearchHistoryPos = 0
+			cmd := m.debounceSearch()
+			return m, cmd
 		}
 		return m, nil
 	}
@@ -56,12 +71,7 @@ func (m Model) HandleFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case "enter":
 		m.FilterMode = false
-		if len(m.FilteredApps) == 0 {
-			m.Rows = m.Registry.GetTableData(m.AllApps)
-		} else {
-			m.Rows = m.Registry.GetTableData(m.FilteredApps)
-		}
-		m.CursorY = 1
+		m.applyTableFilters()
 		return m, nil
 	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
 		appIndex := int(msg.String()[0] - '1')
@@ -91,21 +101,28 @@ func (m Model) HandleFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m Model) HandleHelpInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "?", "esc", "q":
-		m.HelpMode = false
-		m.ViewMode = ViewMain
+func (m Model) HandlePluginsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.PluginDetail {
+		switch msg.String() {
+		case "esc", "q", "enter":
+			m.PluginDetail = false
+		case "e":
+			if m.PluginCursor < len(m.PluginsList) {
+				m.togglePlugin(m.PluginsList[m.PluginCursor])
+			}
+		}
 		return m, nil
 	}
-	return m, nil
-}
 
-func (m Model) HandlePluginsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc", "q":
 		m.ViewMode = ViewMain
 		return m, nil
+	case "enter":
+		if m.PluginCursor < len(m.PluginsList) {
+			m.PluginDetail = true
+		}
+		return m, nil
 	case "up", "k":
 		if m.PluginCursor > 0 {
 			m.PluginCursor--
@@ -130,11 +147,17 @@ func (m Model) HandlePluginsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.StatusMessage = fmt.Sprintf("Unloaded plugin: %s", plugin.Metadata.Name)
 		}
 		return m, nil
-	case "r":
-		m.PluginLoader.LoadAll()
-		m.LoadPlugins()
-		m.StatusMessage = "Plugins reloaded"
+	case "e":
+		if m.PluginCursor < len(m.PluginsList) {
+			m.togglePlugin(m.PluginsList[m.PluginCursor])
+		}
 		return m, nil
+	case "a":
+		cmd := m.askApprovePlugin()
+		return m, cmd
+	case "r":
+		cmd := m.reloadPlugins()
+		return m, cmd
 	}
 	return m, nil
 }
@@ -142,30 +165,92 @@ func (m Model) HandlePluginsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) HandleOnlineInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc", "q":
+		if m.SheetPreview != nil {
+			m.SheetPreview = nil
+			return m, nil
+		}
 		m.ViewMode = ViewMain
 		return m, nil
 	case "up", "k":
-		if m.RepoCursor > 0 {
+		if m.SheetFocus {
+			if m.SheetCursor > 0 {
+				m.SheetCursor--
+				if m.SheetPreview != nil {
+					cmd := m.loadSheetPreview()
+					return m, cmd
+				}
+			}
+		} else if m.RepoCursor > 0 {
 			m.RepoCursor--
 		}
 		return m, nil
 	case "down", "j":
-		if m.RepoCursor < len(m.ReposList)-1 {
+		if m.SheetFocus {
+			if m.SheetCursor < len(m.CheatSheets)-1 {
+				m.SheetCursor++
+				if m.SheetPreview != nil {
+					cmd := m.loadSheetPreview()
+					return m, cmd
+				}
+			}
+		} else if m.RepoCursor < len(m.ReposList)-1 {
 			m.RepoCursor++
 		}
 		return m, nil
+	case "v":
+		if m.SheetPreview != nil {
+			m.SheetPreview = nil
+		} else if m.SheetFocus {
+			cmd := m.loadSheetPreview()
+			return m, cmd
+		}
+		return m, nil
+	case "tab":
+		m.SheetFocus = !m.SheetFocus && len(m.CheatSheets) > 0
+		return m, nil
 	case "enter":
 		if m.RepoCursor < len(m.ReposList) {
-			repo := m.ReposList[m.RepoCursor]
-			m.LoadCheatSheets(repo.URL)
+			cmd := m.loadCheatSheets(m.ReposList[m.RepoCursor].URL)
+			return m, cmd
 		}
 		return m, nil
 	case "d":
-		if m.SheetCursor < len(m.CheatSheets) {
-			sheet := m.CheatSheets[m.SheetCursor]
-			m.StatusMessage = fmt.Sprintf("Downloading: %s", sheet.Name)
+		cmd := m.downloadSheet()
+		return m, cmd
+	case "r":
+		if m.SheetFocus && m.SheetCursor < len(m.CheatSheets) {
+			m.RatingMode = true
 		}
 		return m, nil
+	case "a":
+		cmd := m.downloadRepository()
+		return m, cmd
+	case "D":
+		m.ViewMode = ViewDownloads
+		return m, nil
+	case "C":
+		cmd := m.compareSheet()
+		return m, cmd
+	case "R":
+		if m.SheetFocus && m.SheetCursor < len(m.CheatSheets) {
+			m.ReportMode = true
+		}
+		return m, nil
+	case "b":
+		cmd := m.blockSelected()
+		return m, cmd
+	case "s":
+		m.CycleSheetSort()
+		return m, nil
+	case "S":
+		cmd := m.toggleSubscription()
+		return m, cmd
+	case "c":
+		cmd := m.checkUpdates(true)
+		return m, cmd
+	case "U":
+		cmd := m.updateSheets()
+		return m, cmd
 	case "/":
 		m.SearchMode = true
 		return m, nil
@@ -173,25 +258,49 @@ func (m Model) HandleOnlineInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// HandleRatingInput handles the 1-5 rating prompt of the online view
+func (m Model) HandleRatingInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "esc", "q", "ctrl+c":
+		m.RatingMode = false
+		return m, nil
+	case "1", "2", "3", "4", "5":
+		m.RatingMode = false
+		cmd := m.rateSelectedSheet(float64(key[0] - '0'))
+		return m, cmd
+	}
+	return m, nil
+}
+
 func (m Model) HandleSyncInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.DeviceMode {
+		return m.handleDeviceInput(msg)
+	}
+
 	switch msg.String() {
 	case "esc", "q":
 		m.ViewMode = ViewMain
 		return m, nil
 	case "s":
-		m.StatusMessage = "Syncing..."
-		if m.SyncManager != nil {
-			go m.SyncManager.Sync()
-		}
-		return m, nil
+		return m.startSync()
 	case "r":
 		m.StatusMessage = "Resolving conflicts..."
 		return m, nil
+	case "d":
+		if m.SyncManager == nil {
+			m.StatusMessage = "Sync is not configured"
+			return m, nil
+		}
+		m.DeviceMode = true
+		cmd := m.loadDevices()
+		return m, cmd
 	case "a":
-		if m.SyncManager != nil {
-			m.StatusMessage = "Auto-sync toggled"
+		if m.SyncManager == nil {
+			m.StatusMessage = "Sync is not configured"
+			return m, nil
 		}
-		return m, nil
+		cmd := m.toggleAutoSync()
+		return m, cmd
 	}
 	return m, nil
 }
@@ -0,0 +1,536 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// binding is an action and the keys its handler matches. The keybinds of
+// the config bind an action to another key by its name, prefixed with the
+// group for groups other than the table, as in "notes.new: a". Fixed keys,
+// such as the arrows, always work; they may also describe a range of keys,
+// as "1-9". Bindings with a hint are shown in the hint bar of their view,
+// those with the same hint side by side under it.
+type binding struct {
+	action string
+	keys   []string
+	fixed  []string
+	help   string
+	hint   string
+}
+
+// keyGroup are the bindings of a view or mode, which help lists under
+// title. Several groups may share a name to be listed in parts. The keys of
+// typing groups, which read text, cannot be rebound.
+type keyGroup struct {
+	name     string
+	title    string
+	typing   bool
+	bindings []binding
+}
+
+// defaultKeymap are the bindings of every view, in the order of the help
+var defaultKeymap = []keyGroup{
+	{name: "table", title: "Navigation", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous row", hint: "move"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next row", hint: "move"},
+		{action: "left", keys: []string{"h"}, fixed: []string{"left"}, help: "Previous column", hint: "move"},
+		{action: "right", keys: []string{"l"}, fixed: []string{"right"}, help: "Next column", hint: "move"},
+		{action: "next_app", keys: []string{"tab"}, help: "Next app"},
+		{action: "prev_app", keys: []string{"shift+tab"}, help: "Previous app"},
+		{action: "first_row", keys: []string{"ctrl+a"}, fixed: []string{"home"}, help: "First row"},
+		{action: "last_row", keys: []string{"ctrl+e"}, fixed: []string{"end"}, help: "Last row"},
+		{action: "prev_section", keys: []string{"{"}, help: "Previous category", hint: "categories"},
+		{action: "next_section", keys: []string{"}"}, help: "Next category", hint: "categories"},
+	}},
+	{name: "table", title: "Features", bindings: []binding{
+		{action: "search", keys: []string{"/"}, help: "Search the table", hint: "search"},
+		{action: "clear", keys: []string{"esc"}, fixed: []string{"ctrl+["}, help: "Clear the search", hint: "clear search"},
+		{action: "search_history", keys: []string{"ctrl+h"}, help: "Pick a recent search"},
+		{action: "find", keys: []string{"F"}, help: "Find in apps, notes and online", hint: "find everywhere"},
+		{action: "filter", keys: []string{"f"}, fixed: []string{"ctrl+f"}, help: "Filter apps", hint: "filter"},
+		{action: "tags", keys: []string{"t"}, help: "Filter by shortcut tags", hint: "tags"},
+		{action: "profiles", keys: []string{"P"}, help: "Switch profile", hint: "profiles"},
+		{action: "views", keys: []string{"V"}, help: "Open or save a view", hint: "views"},
+		{action: "themes", keys: []string{"T"}, help: "Preview and pick a theme", hint: "themes"},
+		{action: "export", keys: []string{"E"}, help: "Export the table", hint: "export"},
+		{action: "copy_row", keys: []string{"y"}, help: "Copy the row", hint: "copy row/column"},
+		{action: "copy_column", keys: []string{"Y"}, help: "Copy the app column", hint: "copy row/column"},
+		{action: "detail", keys: []string{"D"}, help: "Toggle the detail pane", hint: "details"},
+		{action: "narrow_detail", keys: []string{"<"}, help: "Narrow the detail pane", hint: "resize details"},
+		{action: "widen_detail", keys: []string{">"}, help: "Widen the detail pane", hint: "resize details"},
+		{action: "select", keys: []string{"enter"}, help: "Send the shortcut to plugins"},
+		{action: "notes", keys: []string{"n"}, help: "Notes manager", hint: "notes"},
+		{action: "shortcut_note", keys: []string{"N"}, help: "Note of the selected shortcut", hint: "shortcut note"},
+		{action: "plugins", keys: []string{"p"}, help: "Plugin manager", hint: "plugins"},
+		{action: "online", keys: []string{"o"}, help: "Browse online", hint: "online"},
+		{action: "sync", keys: []string{"s"}, help: "Sync status", hint: "sync"},
+		{action: "force_sync", keys: []string{"ctrl+s"}, help: "Force sync"},
+		{action: "history", keys: []string{"H"}, help: "Change history", hint: "history"},
+		{action: "cache", keys: []string{"C"}, help: "Cache statistics"},
+		{action: "diagnostics", keys: []string{"!"}, help: "Problems of app files", hint: "app diagnostics"},
+		{action: "quiz", keys: []string{"Q"}, help: "Quiz on the shown apps", hint: "quiz"},
+		{action: "stats", keys: []string{"S"}, help: "Practice statistics", hint: "stats"},
+		{action: "refresh", keys: []string{"ctrl+r"}, help: "Refresh the table"},
+		{action: "help", keys: []string{"?"}, help: "This help screen", hint: "help"},
+		{action: "quit", keys: []string{"q"}, fixed: []string{"ctrl+c"}, help: "Quit", hint: "quit"},
+	}},
+	{name: "search", title: "Search Mode", typing: true, bindings: []binding{
+		{action: "confirm", fixed: []string{"enter"}, help: "Confirm the search"},
+		{action: "cancel", fixed: []string{"esc"}, help: "Cancel the search"},
+		{action: "clear", fixed: []string{"ctrl+u"}, help: "Clear the search"},
+		{action: "recall", fixed: []string{"up", "down"}, help: "Recall recent searches"},
+		{action: "history", fixed: []string{"ctrl+h"}, help: "Pick a recent search"},
+		{action: "syntax", fixed: []string{"app:vim cat:nav"}, help: "Limit to an app or category"},
+		{action: "syntax_keys", fixed: []string{"key:gg tag:window"}, help: "Match keys or tags"},
+	}},
+	{name: "search_history", title: "Recent Searches", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous search", hint: "move"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next search", hint: "move"},
+		{action: "open", keys: []string{"enter"}, help: "Search again", hint: "search"},
+		{action: "edit", keys: []string{"e"}, help: "Edit before searching", hint: "edit"},
+		{action: "delete", keys: []string{"d"}, help: "Forget the search", hint: "forget"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc", "ctrl+h"}, help: "Back to the table", hint: "cancel"},
+	}},
+	{name: "filter", title: "App Filter", bindings: []binding{
+		{action: "toggle", fixed: []string{"1-9"}, help: "Toggle an app", hint: "toggle apps"},
+		{action: "all", keys: []string{"a"}, help: "Select all apps", hint: "all"},
+		{action: "clear", keys: []string{"c"}, fixed: []string{"ctrl+u"}, help: "Clear the selection", hint: "clear"},
+		{action: "apply", keys: []string{"enter"}, help: "Apply the filter", hint: "apply"},
+		{action: "cancel", keys: []string{"esc"}, fixed: []string{"ctrl+["}, help: "Cancel", hint: "cancel"},
+	}},
+	{name: "tags", title: "Tag Filter", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous tag", hint: "move"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next tag", hint: "move"},
+		{action: "toggle", keys: []string{"x"}, fixed: []string{" "}, help: "Toggle the tag", hint: "toggle"},
+		{action: "all", keys: []string{"a"}, help: "Select all tags", hint: "all"},
+		{action: "clear", keys: []string{"c"}, fixed: []string{"ctrl+u"}, help: "Clear the selection", hint: "clear"},
+		{action: "apply", keys: []string{"enter"}, help: "Apply the filter", hint: "apply"},
+		{action: "cancel", keys: []string{"t"}, fixed: []string{"esc"}, help: "Cancel", hint: "cancel"},
+	}},
+	{name: "profiles", title: "Profiles", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous profile", hint: "move"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next profile", hint: "move"},
+		{action: "switch", keys: []string{"enter"}, help: "Switch to the profile", hint: "switch"},
+		{action: "cancel", keys: []string{"P"}, fixed: []string{"esc"}, help: "Cancel", hint: "cancel"},
+	}},
+	{name: "views", title: "Saved Views", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous view", hint: "move"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next view", hint: "move"},
+		{action: "open", keys: []string{"enter"}, help: "Open the view", hint: "open"},
+		{action: "save", keys: []string{"a"}, help: "Save the table as a view", hint: "save current"},
+		{action: "delete", keys: []string{"d"}, help: "Delete the view", hint: "delete"},
+		{action: "startup", keys: []string{"*"}, help: "Open the view at startup", hint: "open at startup"},
+		{action: "cancel", keys: []string{"V"}, fixed: []string{"esc"}, help: "Cancel", hint: "cancel"},
+	}},
+	{name: "themes", title: "Themes", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Preview the previous theme", hint: "preview"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Preview the next theme", hint: "preview"},
+		{action: "pick", keys: []string{"enter"}, help: "Keep the theme", hint: "use and save"},
+		{action: "cancel", keys: []string{"T"}, fixed: []string{"esc"}, help: "Cancel", hint: "cancel"},
+	}},
+	{name: "export", title: "Export", bindings: []binding{
+		{action: "markdown", keys: []string{"m"}, help: "Export as markdown", hint: "markdown"},
+		{action: "html", keys: []string{"h"}, help: "Export as an HTML page", hint: "HTML page"},
+		{action: "png", keys: []string{"p"}, help: "Export as a PNG image", hint: "PNG image"},
+		{action: "cancel", keys: []string{"E"}, fixed: []string{"esc"}, help: "Cancel", hint: "cancel"},
+	}},
+	{name: "notes", title: "Notes Manager", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous note"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next note"},
+		{action: "open", keys: []string{"v"}, fixed: []string{"enter"}, help: "Read the note", hint: "view"},
+		{action: "new", keys: []string{"n"}, help: "New note from a template", hint: "new"},
+		{action: "edit", keys: []string{"e"}, help: "Edit the note", hint: "edit"},
+		{action: "delete", keys: []string{"d"}, help: "Move the note to the trash", hint: "delete"},
+		{action: "favorite", keys: []string{"f"}, help: "Toggle favorite", hint: "favorite"},
+		{action: "encrypt", keys: []string{"x"}, help: "Encrypt or decrypt the note", hint: "encrypt"},
+		{action: "trash", keys: []string{"t"}, help: "Show the trash", hint: "trash"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back to the table", hint: "back"},
+	}},
+	{name: "templates", title: "Note Templates", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous template", hint: "select"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next template", hint: "select"},
+		{action: "create", keys: []string{"enter"}, help: "Create the note", hint: "create"},
+		{action: "cancel", keys: []string{"q"}, fixed: []string{"esc"}, help: "Cancel", hint: "cancel"},
+	}},
+	{name: "trash", title: "Trash", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous note", hint: "move"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next note", hint: "move"},
+		{action: "restore", keys: []string{"r"}, fixed: []string{"enter"}, help: "Restore the note", hint: "restore"},
+		{action: "empty", keys: []string{"E"}, help: "Empty the trash", hint: "empty trash"},
+		{action: "close", keys: []string{"t"}, fixed: []string{"esc", "q"}, help: "Back to the notes", hint: "back"},
+	}},
+	{name: "note", title: "Note Preview", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Scroll up", hint: "scroll"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Scroll down", hint: "scroll"},
+		{action: "raw", keys: []string{"r"}, help: "Toggle rendered and raw", hint: "raw/rendered"},
+		{action: "edit", keys: []string{"e"}, help: "Edit the note", hint: "edit"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back", hint: "back"},
+	}},
+	{name: "plugins", title: "Plugin Manager", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous plugin"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next plugin"},
+		{action: "details", keys: []string{"enter"}, help: "Show or hide the details", hint: "details"},
+		{action: "toggle", keys: []string{"e"}, help: "Enable or disable the plugin", hint: "enable/disable"},
+		{action: "approve", keys: []string{"a"}, help: "Run the unverified plugin anyway", hint: "approve"},
+		{action: "unload", keys: []string{"u"}, help: "Unload the plugin", hint: "unload"},
+		{action: "reload", keys: []string{"r"}, help: "Reload the plugins", hint: "reload all"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back to the table", hint: "back"},
+	}},
+	{name: "online", title: "Online Browser", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous entry", hint: "move"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next entry", hint: "move"},
+		{action: "open", keys: []string{"enter"}, help: "List the sheets of the repository", hint: "browse"},
+		{action: "focus", keys: []string{"tab"}, help: "Switch between repositories and sheets", hint: "switch list"},
+		{action: "preview", keys: []string{"v"}, help: "Preview the sheet", hint: "preview"},
+		{action: "download", keys: []string{"d"}, help: "Download the sheet", hint: "download"},
+		{action: "download_all", keys: []string{"a"}, help: "Download every sheet of the repository", hint: "download all"},
+		{action: "downloads", keys: []string{"D"}, help: "Follow the downloads", hint: "downloads"},
+		{action: "compare", keys: []string{"C"}, help: "Compare the sheet with the local app", hint: "compare"},
+		{action: "rate", keys: []string{"r"}, help: "Rate the sheet", hint: "rate"},
+		{action: "report", keys: []string{"R"}, help: "Report the sheet to the moderators", hint: "report"},
+		{action: "block", keys: []string{"b"}, help: "Block the repository, or the author of the sheet", hint: "block"},
+		{action: "sort", keys: []string{"s"}, help: "Change the order of the sheets", hint: "sort"},
+		{action: "subscribe", keys: []string{"S"}, help: "Subscribe to the repository, or unsubscribe", hint: "subscribe"},
+		{action: "check", keys: []string{"c"}, help: "Check the subscriptions for updates", hint: "check updates"},
+		{action: "update", keys: []string{"U"}, help: "Download the changed sheets again", hint: "update all"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back to the table", hint: "back"},
+	}},
+	{name: "downloads", title: "Downloads", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous download", hint: "move"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next download", hint: "move"},
+		{action: "stop", keys: []string{"x"}, help: "Cancel the download", hint: "cancel"},
+		{action: "retry", keys: []string{"r"}, help: "Retry the failed or cancelled download", hint: "retry"},
+		{action: "retry_all", keys: []string{"R"}, help: "Retry every failed or cancelled download", hint: "retry all"},
+		{action: "clear", keys: []string{"c"}, help: "Clear the finished downloads", hint: "clear finished"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back to the online browser", hint: "back"},
+	}},
+	{name: "compare", title: "Coverage", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Scroll up", hint: "scroll"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Scroll down", hint: "scroll"},
+		{action: "merge", keys: []string{"m"}, help: "Add the missing shortcuts to the local app", hint: "merge missing"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back to the online browser", hint: "back"},
+	}},
+	{name: "rating", title: "Rating", bindings: []binding{
+		{action: "rate", fixed: []string{"1-5"}, help: "Rate the sheet", hint: "rate"},
+		{action: "cancel", keys: []string{"q"}, fixed: []string{"esc"}, help: "Cancel", hint: "cancel"},
+	}},
+	{name: "report", title: "Report", bindings: []binding{
+		{action: "reason", fixed: []string{"1-4"}, help: "Report the sheet as spam, offensive, incorrect or malicious", hint: "reason"},
+		{action: "cancel", keys: []string{"q"}, fixed: []string{"esc"}, help: "Cancel", hint: "cancel"},
+	}},
+	{name: "trust", title: "Unverified Content", bindings: []binding{
+		{action: "accept", keys: []string{"y"}, help: "Use the sheet or plugin anyway", hint: "use anyway"},
+		{action: "cancel", keys: []string{"n"}, fixed: []string{"esc"}, help: "Refuse it", hint: "refuse"},
+	}},
+	{name: "sync", title: "Sync Status", bindings: []binding{
+		{action: "sync", keys: []string{"s"}, help: "Sync now", hint: "sync now"},
+		{action: "auto", keys: []string{"a"}, help: "Toggle auto-sync", hint: "auto-sync"},
+		{action: "devices", keys: []string{"d"}, help: "Devices syncing the data", hint: "devices"},
+		{action: "resolve", keys: []string{"r"}, help: "Resolve conflicts", hint: "resolve conflicts"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back to the table", hint: "back"},
+	}},
+	{name: "devices", title: "Devices", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous device", hint: "move"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next device", hint: "move"},
+		{action: "rename", keys: []string{"n"}, help: "Rename this device", hint: "name this device"},
+		{action: "revoke", keys: []string{"x"}, help: "Revoke the device, pressed twice", hint: "revoke"},
+		{action: "reload", keys: []string{"r"}, help: "Reload the devices", hint: "refresh"},
+		{action: "close", keys: []string{"d"}, fixed: []string{"esc", "q"}, help: "Back to sync status", hint: "back"},
+	}},
+	{name: "history", title: "Change History", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous change", hint: "move"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next change", hint: "move"},
+		{action: "undo", keys: []string{"u"}, help: "Undo the change", hint: "undo change"},
+		{action: "snapshot", keys: []string{"t"}, help: "Data as of the change", hint: "view as of this change"},
+		{action: "reload", keys: []string{"r"}, help: "Reload the history", hint: "reload"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back to the table", hint: "back"},
+	}},
+	{name: "snapshot", title: "Snapshot", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Previous item", hint: "move"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Next item", hint: "move"},
+		{action: "restore", keys: []string{"r"}, help: "Restore the item", hint: "restore item"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back to the history", hint: "back to history"},
+	}},
+	{name: "cache", title: "Cache Statistics", bindings: []binding{
+		{action: "clear_memory", keys: []string{"m"}, help: "Clear the memory cache", hint: "clear memory"},
+		{action: "clear_disk", keys: []string{"d"}, help: "Clear the disk cache", hint: "clear file"},
+		{action: "clear", keys: []string{"c"}, help: "Clear both caches", hint: "clear both"},
+		{action: "ttl", fixed: []string{"[", "]", "-", "+"}, help: "Shorten or lengthen the TTLs", hint: "TTLs"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back to the table", hint: "back"},
+	}},
+	{name: "quiz", title: "Quiz", typing: true, bindings: []binding{
+		{action: "answer", fixed: []string{"enter"}, help: "Check the answer, or reveal it"},
+		{action: "reveal", fixed: []string{"tab"}, help: "Reveal the answer"},
+		{action: "grade", fixed: []string{"y", "n"}, help: "Grade a revealed answer"},
+		{action: "close", fixed: []string{"esc"}, help: "End the quiz"},
+	}},
+	{name: "stats", title: "Practice Statistics", bindings: []binding{
+		{action: "reload", keys: []string{"r"}, help: "Recompute the statistics", hint: "refresh"},
+		{action: "export", keys: []string{"e"}, help: "Export them as JSON", hint: "export JSON"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back to the table", hint: "back"},
+	}},
+	{name: "find", title: "Find Everywhere", typing: true, bindings: []binding{
+		{action: "move", fixed: []string{"up", "down"}, help: "Move through the results"},
+		{action: "open", fixed: []string{"enter"}, help: "Open the result"},
+		{action: "clear", fixed: []string{"ctrl+u"}, help: "Clear the query"},
+		{action: "close", fixed: []string{"esc"}, help: "Back to the table"},
+	}},
+	{name: "diagnostics", title: "App Diagnostics", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Scroll up", hint: "scroll"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Scroll down", hint: "scroll"},
+		{action: "check", keys: []string{"r"}, help: "Check again", hint: "check again"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "Back to the table", hint: "back"},
+	}},
+	{name: "setup", title: "Setup", typing: true, bindings: []binding{
+		{action: "next", fixed: []string{"enter"}, help: "Next step"},
+		{action: "back", fixed: []string{"shift+tab"}, help: "Previous step"},
+		{action: "toggle", fixed: []string{" ", "x"}, help: "Toggle the app"},
+		{action: "skip", fixed: []string{"esc"}, help: "Skip the setup"},
+	}},
+	{name: "tour", title: "Tour", bindings: []binding{
+		{action: "next", keys: []string{"l"}, fixed: []string{"right", "enter", " "}, help: "Next step", hint: "next"},
+		{action: "back", keys: []string{"h"}, fixed: []string{"left", "shift+tab"}, help: "Previous step", hint: "back"},
+		{action: "close", keys: []string{"q"}, fixed: []string{"esc"}, help: "End the tour", hint: "end tour"},
+	}},
+	{name: "help", title: "Help", bindings: []binding{
+		{action: "up", keys: []string{"k"}, fixed: []string{"up"}, help: "Scroll up", hint: "scroll"},
+		{action: "down", keys: []string{"j"}, fixed: []string{"down"}, help: "Scroll down", hint: "scroll"},
+		{action: "search", keys: []string{"/"}, help: "Search the keys", hint: "search keys"},
+		{action: "tour", keys: []string{"t"}, help: "Take the tour of the features", hint: "take the tour"},
+		{action: "close", keys: []string{"?"}, fixed: []string{"esc", "q"}, help: "Close the help", hint: "close"},
+	}},
+}
+
+// Keymap is the keymap of the TUI after the keybinds of the config
+type Keymap struct {
+	groups []keyGroup
+	// remap maps the keys the config binds, by group, to the key the
+	// handler of the action matches; unbound are the keys of rebound
+	// actions that no longer trigger them
+	remap   map[string]map[string]string
+	unbound map[string]map[string]bool
+}
+
+// NewKeymap applies keybinds, as in the config, to the default keymap
+func NewKeymap(keybinds map[string]string) *Keymap {
+	k := &Keymap{
+		remap:   make(map[string]map[string]string),
+		unbound: make(map[string]map[string]bool),
+	}
+	for _, group := range defaultKeymap {
+		if k.remap[group.name] == nil {
+			k.remap[group.name] = make(map[string]string)
+			k.unbound[group.name] = make(map[string]bool)
+		}
+		resolved := group
+		resolved.bindings = append([]binding(nil), group.bindings...)
+		for i, b := range resolved.bindings {
+			key := normalizeKey(keybinds[group.bindingName(b)])
+			if key == "" || group.typing || len(b.keys) == 0 || key == b.keys[0] {
+				continue
+			}
+			for _, old := range b.keys {
+				k.unbound[group.name][old] = true
+			}
+			k.remap[group.name][key] = b.keys[0]
+			resolved.bindings[i].keys = []string{key}
+		}
+		k.groups = append(k.groups, resolved)
+	}
+	return k
+}
+
+// bindingName is the name the config binds b by
+func (g keyGroup) bindingName(b binding) string {
+	if g.name == "table" {
+		return b.action
+	}
+	return g.name + "." + b.action
+}
+
+// normalizeKey spells a key of the config as tea.KeyMsg.String does
+func normalizeKey(key string) string {
+	switch key = strings.TrimSpace(key); strings.ToLower(key) {
+	case "space":
+		return " "
+	case "escape":
+		return "esc"
+	case "return":
+		return "enter"
+	}
+	return key
+}
+
+// translate returns the key the handlers of group match for msg, and
+// false when msg was bound away from its action
+func (k *Keymap) translate(group string, msg tea.KeyMsg) (tea.KeyMsg, bool) {
+	if msg.Paste {
+		return msg, true
+	}
+	key := msg.String()
+	if to, ok := k.remap[group][key]; ok {
+		return keyMsg(to), true
+	}
+	return msg, !k.unbound[group][key]
+}
+
+// keyTypes are the key types by the names tea.KeyMsg.String gives them
+var keyTypes = func() map[string]tea.KeyType {
+	types := make(map[string]tea.KeyType)
+	for t := tea.KeyType(-256); t < 256; t++ {
+		if name := (tea.Key{Type: t}).String(); name != "" && t != tea.KeyRunes {
+			if _, ok := types[name]; !ok {
+				types[name] = t
+			}
+		}
+	}
+	return types
+}()
+
+// keyMsg returns the message of pressing the key called name
+func keyMsg(name string) tea.KeyMsg {
+	alt := false
+	if rest, ok := strings.CutPrefix(name, "alt+"); ok && rest != "" {
+		alt, name = true, rest
+	}
+	if t, ok := keyTypes[name]; ok {
+		return tea.KeyMsg{Type: t, Alt: alt}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name), Alt: alt}
+}
+
+// keymap returns the keymap of the config of the model
+func (m Model) keymap() *Keymap {
+	if m.Config == nil {
+		return NewKeymap(nil)
+	}
+	return NewKeymap(m.Config.Keybinds)
+}
+
+// keyGroupName names the group of the keys of the view and mode shown
+func (m Model) keyGroupName() string {
+	switch m.ViewMode {
+	case ViewMain:
+		switch {
+		case m.TourMode:
+			return "tour"
+		case m.SearchHistoryMode:
+			return "search_history"
+		case m.SearchMode:
+			return "search"
+		case m.FilterMode:
+			return "filter"
+		case m.TagMode:
+			return "tags"
+		case m.ProfileMode:
+			return "profiles"
+		case m.SavedViewMode && m.SavedViewNaming:
+			return ""
+		case m.SavedViewMode:
+			return "views"
+		case m.ThemeMode:
+			return "themes"
+		case m.ExportMode:
+			return "export"
+		}
+		return "table"
+	case ViewNotes:
+		switch {
+		case m.PassphraseMode:
+			return ""
+		case m.TemplateMode:
+			return "templates"
+		case m.TrashMode:
+			return "trash"
+		}
+		return "notes"
+	case ViewNotePreview:
+		return "note"
+	case ViewPlugins:
+		if m.PluginApproveMode {
+			return "trust"
+		}
+		return "plugins"
+	case ViewOnline:
+		if len(m.UnverifiedSheets) > 0 {
+			return "trust"
+		}
+		if m.RatingMode {
+			return "rating"
+		}
+		if m.ReportMode {
+			return "report"
+		}
+		return "online"
+	case ViewSync:
+		switch {
+		case m.DeviceMode && m.DeviceNameMode:
+			return ""
+		case m.DeviceMode:
+			return "devices"
+		}
+		return "sync"
+	case ViewHistory:
+		return "history"
+	case ViewSnapshot:
+		return "snapshot"
+	case ViewCache:
+		return "cache"
+	case ViewQuiz:
+		return "quiz"
+	case ViewStats:
+		return "stats"
+	case ViewFind:
+		return "find"
+	case ViewDiagnostics:
+		return "diagnostics"
+	case ViewDownloads:
+		return "downloads"
+	case ViewCompare:
+		return "compare"
+	case ViewSetup:
+		return "setup"
+	case ViewHelp:
+		if m.HelpSearching {
+			return ""
+		}
+		return "help"
+	}
+	return ""
+}
+
+// keyNames spell keys for people
+var keyNames = map[string]string{
+	"up": "↑", "down": "↓", "left": "←", "right": "→",
+	" ": "Space", "space": "Space", "enter": "Enter", "esc": "Esc", "tab": "Tab",
+	"shift+tab": "Shift+Tab", "home": "Home", "end": "End", "backspace": "Backspace",
+}
+
+// displayKey spells key for the help
+func displayKey(key string) string {
+	if name, ok := keyNames[key]; ok {
+		return name
+	}
+	if rest, ok := strings.CutPrefix(key, "ctrl+"); ok {
+		return "Ctrl+" + strings.ToUpper(rest)
+	}
+	if rest, ok := strings.CutPrefix(key, "alt+"); ok {
+		return "Alt+" + rest
+	}
+	return key
+}
+
+// displayKeys spells the keys of b for the help
+func (b binding) displayKeys() string {
+	var names []string
+	for _, key := range append(append([]string(nil), b.keys...), b.fixed...) {
+		if key == "ctrl+[" {
+			// the same key as esc
+			continue
+		}
+		names = append(names, displayKey(key))
+	}
+	return strings.Join(names, " / ")
+}
@@ -0,0 +1,283 @@
+package ui
+
+import (
+	"context"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/remuscazacu/cheat-go/pkg/daemon"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+	"github.com/remuscazacu/cheat-go/pkg/plugins"
+	"github.com/remuscazacu/cheat-go/pkg/sync"
+)
+
+// The results of the loads the views start in the background
+type (
+	notesLoadedMsg struct {
+		notes []*notes.Note
+	}
+	notesOpenedMsg struct {
+		manager *notes.FileManager
+		host    *plugins.Host
+		err     error
+	}
+	pluginsLoadedMsg struct {
+		loader *plugins.Loader
+		// startup is set for the first load, started by LoadInBackground
+		startup bool
+	}
+	reposLoadedMsg struct {
+		repos []online.Repository
+		err   error
+	}
+	sheetsLoadedMsg struct {
+		sheets []online.CheatSheet
+		err    error
+	}
+	previewLoadedMsg struct {
+		id    string
+		sheet *online.CheatSheet
+		err   error
+	}
+	sheetRatedMsg struct {
+		sheet   online.CheatSheet
+		rating  float64
+		updated *online.CheatSheet
+		err     error
+	}
+	sheetReportedMsg struct {
+		sheet  online.CheatSheet
+		reason string
+		err    error
+	}
+	devicesLoadedMsg struct {
+		devices []sync.Device
+		err     error
+	}
+	daemonStatusMsg struct {
+		// status is nil when no daemon answered
+		status *daemon.Status
+	}
+)
+
+// loadNotes lists the notes for the notes view in the background
+func (m *Model) loadNotes() tea.Cmd {
+	if m.NotesManager == nil {
+		return nil
+	}
+	manager := m.NotesManager
+	return m.startTask("loading notes", func(context.Context) tea.Msg {
+		list, _ := manager.ListNotes()
+		return notesLoadedMsg{notes: list}
+	})
+}
+
+// LoadInBackground opens the notes and loads the plugins once the program
+// runs, so the first frame does not wait for them. The plugins are loaded
+// into a fresh copy of PluginLoader, which replaces it once they are; the
+// startup hooks run when both are done.
+func (m *Model) LoadInBackground() {
+	host := &plugins.Host{Apps: m.Registry}
+	m.PluginLoader.SetHost(host)
+	loader := m.PluginLoader.Fresh()
+
+	store, changes, readOnly, retention := m.Store, m.Journal, m.ReadOnly, m.Config.Notes.TrashRetention
+	openNotes := m.startTask("opening notes", func(context.Context) tea.Msg {
+		fm, err := notes.NewStorageManager(store)
+		if err != nil {
+			return notesOpenedMsg{err: err}
+		}
+		if changes != nil {
+			fm.SetJournal(changes, journal.SourceUI)
+		}
+		// read-only instances leave the trash to the owner of the data
+		// directory
+		if readOnly {
+			fm.SetReadOnly(true)
+		} else {
+			fm.PurgeTrash(retention)
+		}
+		return notesOpenedMsg{manager: fm, host: host}
+	})
+	loadPlugins := m.startTask("loading plugins", func(context.Context) tea.Msg {
+		loader.LoadAll()
+		return pluginsLoadedMsg{loader: loader, startup: true}
+	})
+
+	m.startup = tea.Batch(openNotes, loadPlugins)
+	m.startupLoads = 2
+}
+
+// setNotesManager starts using the notes manager opened at startup
+func (m *Model) setNotesManager(msg notesOpenedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.notify(ToastError, "Error opening notes: %v", msg.err)
+	}
+	m.NotesManager = msg.manager
+	m.LoadNoteLinks()
+	msg.host.Notes = msg.manager
+	return nil
+}
+
+// registerPluginApps adds the apps of the plugins loaded at startup to the
+// table
+func (m *Model) registerPluginApps() tea.Cmd {
+	err := m.PluginLoader.RegisterApps(m.Registry)
+	if slices.ContainsFunc(m.PluginLoader.ListPlugins(), func(loaded *plugins.LoadedPlugin) bool { return len(loaded.Apps) > 0 }) {
+		m.Registry.LoadApps(m.Config.Apps)
+		m.RefreshTable()
+		m.applyTableFilters()
+	}
+	if err != nil {
+		return m.notify(ToastError, "Error loading some plugin apps: %v", err)
+	}
+	return nil
+}
+
+// startupLoaded counts a startup load as done, running the startup hooks
+// once all are
+func (m *Model) startupLoaded() tea.Cmd {
+	m.startupLoads--
+	if m.startupLoads > 0 {
+		return nil
+	}
+	m.startup = nil
+	return m.runHooks(plugins.Event{Hook: plugins.HookStartup})
+}
+
+// reloadPlugins loads the plugins again into a new loader, which replaces
+// the one in use once it is done
+func (m *Model) reloadPlugins() tea.Cmd {
+	loader := m.PluginLoader.Fresh()
+	return m.startTask("reloading plugins", func(context.Context) tea.Msg {
+		loader.LoadAll()
+		return pluginsLoadedMsg{loader: loader}
+	})
+}
+
+// loadRepositories fetches the repositories of the online view
+func (m *Model) loadRepositories() tea.Cmd {
+	client := m.OnlineClient
+	return m.startTask("loading repositories", func(ctx context.Context) tea.Msg {
+		repos, err := online.WithContext(ctx, client).GetRepositories()
+		return reposLoadedMsg{repos: repos, err: err}
+	})
+}
+
+// loadCheatSheets fetches the sheets of a repository
+func (m *Model) loadCheatSheets(repoURL string) tea.Cmd {
+	client := m.OnlineClient
+	return m.startTask("loading cheat sheets", func(ctx context.Context) tea.Msg {
+		sheets, err := online.WithContext(ctx, client).SearchCheatSheets(sheetSearch(repoURL))
+		return sheetsLoadedMsg{sheets: sheets, err: err}
+	})
+}
+
+// loadSheetPreview fetches the sheet under the cursor for the preview pane
+func (m *Model) loadSheetPreview() tea.Cmd {
+	if m.SheetCursor >= len(m.CheatSheets) {
+		return nil
+	}
+	client, id := m.OnlineClient, m.CheatSheets[m.SheetCursor].ID
+	return m.startTask("loading preview", func(ctx context.Context) tea.Msg {
+		sheet, err := online.WithContext(ctx, client).GetCheatSheet(id)
+		return previewLoadedMsg{id: id, sheet: sheet, err: err}
+	})
+}
+
+// downloadSheet downloads the sheet under the cursor to install it
+func (m *Model) downloadSheet() tea.Cmd {
+	if m.SheetCursor >= len(m.CheatSheets) {
+		return nil
+	}
+	return m.download(m.CheatSheets[m.SheetCursor])
+}
+
+// download downloads sheet to install it, or to update the app it was
+// installed as
+func (m *Model) download(sheet online.CheatSheet) tea.Cmd {
+	return m.queueDownloads([]online.CheatSheet{sheet})
+}
+
+// rateSelectedSheet rates the sheet under the cursor
+func (m *Model) rateSelectedSheet(rating float64) tea.Cmd {
+	if m.SheetCursor >= len(m.CheatSheets) {
+		return nil
+	}
+	client, sheet := m.OnlineClient, m.CheatSheets[m.SheetCursor]
+	return m.startTask("rating "+sheet.Name, func(ctx context.Context) tea.Msg {
+		updated, err := rateSheet(online.WithContext(ctx, client), sheet.ID, rating)
+		return sheetRatedMsg{sheet: sheet, rating: rating, updated: updated, err: err}
+	})
+}
+
+// reportSelectedSheet reports the sheet under the cursor to the moderators
+// of the community
+func (m *Model) reportSelectedSheet(reason string) tea.Cmd {
+	if m.SheetCursor >= len(m.CheatSheets) {
+		return nil
+	}
+	client, sheet := m.OnlineClient, m.CheatSheets[m.SheetCursor]
+	return m.startTask("reporting "+sheet.Name, func(ctx context.Context) tea.Msg {
+		err := online.WithContext(ctx, client).ReportCheatSheet(sheet.ID, reason)
+		return sheetReportedMsg{sheet: sheet, reason: reason, err: err}
+	})
+}
+
+// loadDevices asks the sync server for the devices of the device screen
+func (m *Model) loadDevices() tea.Cmd {
+	if m.SyncManager == nil {
+		return nil
+	}
+	manager := m.SyncManager
+	return m.startTask("loading devices", func(ctx context.Context) tea.Msg {
+		devices, err := manager.Devices(ctx)
+		return devicesLoadedMsg{devices: devices, err: err}
+	})
+}
+
+// handleLoaded applies the result of a background load
+func (m Model) handleLoaded(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case notesLoadedMsg:
+		m.setNotes(msg.notes)
+	case notesOpenedMsg:
+		cmd := m.setNotesManager(msg)
+		return m, tea.Batch(cmd, m.startupLoaded())
+	case pluginsLoadedMsg:
+		m.PluginLoader = msg.loader
+		m.LoadPlugins()
+		if msg.startup {
+			cmd := m.registerPluginApps()
+			return m, tea.Batch(cmd, m.startupLoaded())
+		}
+		m.StatusMessage = "Plugins reloaded"
+	case reposLoadedMsg:
+		m.setRepositories(msg.repos, msg.err)
+	case sheetsLoadedMsg:
+		m.setCheatSheets(msg.sheets, msg.err)
+	case previewLoadedMsg:
+		// the cursor may have moved on while the sheet loaded
+		if m.SheetCursor < len(m.CheatSheets) && m.CheatSheets[m.SheetCursor].ID == msg.id {
+			m.setSheetPreview(msg.sheet, msg.err)
+		}
+	case repoSheetsMsg:
+		return m, m.downloadRepoSheets(msg)
+	case sheetRatedMsg:
+		m.setSheetRating(msg.sheet, msg.rating, msg.updated, msg.err)
+	case sheetReportedMsg:
+		return m, m.setSheetReport(msg.sheet, msg.reason, msg.err)
+	case devicesLoadedMsg:
+		m.setDevices(msg.devices, msg.err)
+	case daemonStatusMsg:
+		m.DaemonStatus = msg.status
+	case updatesCheckedMsg:
+		return m, m.setSheetUpdates(msg)
+	case sheetComparedMsg:
+		return m, m.setSheetComparison(msg)
+	}
+	return m, nil
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rivo/uniseg"
+
+	"github.com/remuscazacu/cheat-go/pkg/markdown"
+)
+
+// markdownStyle returns the glamour style of note content for theme: the
+// light or dark style by its background, with headings in the color of the
+// table header
+func markdownStyle(theme *Theme) ansi.StyleConfig {
+	if theme == nil {
+		theme = DefaultTheme()
+	}
+
+	style := styles.DarkStyleConfig
+	if theme.Light {
+		style = styles.LightStyleConfig
+	}
+	if color, ok := theme.HeaderStyle.GetForeground().(lipgloss.Color); ok {
+		heading := string(color)
+		style.Heading.Color = &heading
+	}
+
+	// the preview lays out its own margins
+	var margin uint
+	style.Document.Margin = &margin
+	style.Document.BlockPrefix = ""
+	style.Document.BlockSuffix = ""
+	return style
+}
+
+// renderMarkdown renders content with glamour as styled lines wrapped to
+// width columns, falling back to the wrapped raw text
+func renderMarkdown(content string, theme *Theme, width int) []string {
+	rendered, err := markdown.Terminal(content, markdownStyle(theme), width)
+	if err != nil {
+		return wrapText(content, width)
+	}
+	return strings.Split(strings.Trim(rendered, "\n"), "\n")
+}
+
+// wrapText breaks text into lines of at most width columns at spaces,
+// cutting words that are longer than a line
+func wrapText(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	for _, word := range strings.Fields(text) {
+		wordWidth := displayWidth(word)
+		if lineWidth > 0 && lineWidth+1+wordWidth > width {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+		for wordWidth > width {
+			head := truncateWidth(word, width, "")
+			if head == "" {
+				// a character wider than the line gets one of its own
+				head, _, _, _ = uniseg.FirstGraphemeClusterInString(word, -1)
+			}
+			lines = append(lines, head)
+			word = strings.TrimPrefix(word, head)
+			wordWidth = displayWidth(word)
+		}
+		if lineWidth > 0 {
+			line.WriteByte(' ')
+			lineWidth++
+		}
+		line.WriteString(word)
+		lineWidth += wordWidth
+	}
+	if lineWidth > 0 || len(lines) == 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/mattn/go-runewidth"
+)
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// plainMarkdown renders content without styling escape codes and the
+// spaces padding lines to the width
+func plainMarkdown(content string, width int) []string {
+	var lines []string
+	for _, line := range renderMarkdown(content, MinimalTheme(), width) {
+		lines = append(lines, strings.TrimRight(ansiEscape.ReplaceAllString(line, ""), " "))
+	}
+	return lines
+}
+
+func TestRenderMarkdown_Blocks(t *testing.T) {
+	content := strings.Join([]string{
+		"## Section",
+		"- item",
+		"  * nested",
+		"- [ ] todo",
+		"- [x] done",
+		"",
+		"> quoted",
+		"",
+		"```sh",
+		"ls -la",
+		"```",
+	}, "\n")
+
+	lines := plainMarkdown(content, 20)
+	want := []string{
+		"## Section",
+		"",
+		"• item",
+		"  • nested",
+		"[ ] todo",
+		"[✓] done",
+		"",
+		"│ quoted",
+		"",
+		"  ls -la",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %d: %q", len(want), len(lines), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestRenderMarkdown_Inline(t *testing.T) {
+	lines := plainMarkdown("Use **bold**, *em* and _under_", 80)
+	if len(lines) != 1 || lines[0] != "Use bold, em and under" {
+		t.Errorf("inline markers should be removed, got %q", lines)
+	}
+}
+
+func TestRenderMarkdown_Wrap(t *testing.T) {
+	lines := plainMarkdown("one two three four five six", 12)
+	want := []string{"one two", "three four", "five six"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("wrapped text = %q, want %q", lines, want)
+	}
+
+	for _, line := range renderMarkdown("# Title\n\n"+strings.Repeat("x ", 25), DefaultTheme(), 10) {
+		if width := runewidth.StringWidth(ansiEscape.ReplaceAllString(line, "")); width > 10 {
+			t.Errorf("line %q is %d columns wide, more than 10", line, width)
+		}
+	}
+}
+
+func TestMarkdownStyle(t *testing.T) {
+	style := markdownStyle(LightTheme())
+	if style.Document.Color == nil || *style.Document.Color != *styles.LightStyleConfig.Document.Color {
+		t.Error("a light theme should render notes in the light style")
+	}
+	if style.Heading.Color == nil || *style.Heading.Color != "25" {
+		t.Errorf("headings should take the header color, got %v", style.Heading.Color)
+	}
+	if *style.Document.Margin != 0 || *styles.LightStyleConfig.Document.Margin == 0 {
+		t.Error("the margin should be dropped from a copy of the style")
+	}
+}
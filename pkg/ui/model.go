@@ -1,15 +1,21 @@
 package ui
 
 import (
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 
-	"cheat-go/pkg/apps"
-	"cheat-go/pkg/cache"
-	"cheat-go/pkg/config"
-	"cheat-go/pkg/notes"
-	"cheat-go/pkg/online"
-	"cheat-go/pkg/plugins"
-	"cheat-go/pkg/sync"
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/cache"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/daemon"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+	"github.com/remuscazacu/cheat-go/pkg/plugins"
+	"github.com/remuscazacu/cheat-go/pkg/practice"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+	"github.com/remuscazacu/cheat-go/pkg/sync"
 )
 
 // View modes for Phase 4 features
@@ -22,6 +28,17 @@ const (
 	ViewOnline
 	ViewSync
 	ViewHelp
+	ViewHistory
+	ViewSnapshot
+	ViewCache
+	ViewNotePreview
+	ViewQuiz
+	ViewStats
+	ViewSetup
+	ViewFind
+	ViewDiagnostics
+	ViewDownloads
+	ViewCompare
 )
 
 // Aliases for backward compatibility with lowercase names used in main.go
@@ -38,6 +55,7 @@ type Model struct {
 	// Original fields
 	Registry     *apps.Registry
 	Config       *config.Config
+	ConfigLoader *config.Loader
 	Renderer     *TableRenderer
 	Rows         [][]string
 	FilteredRows [][]string
@@ -52,28 +70,212 @@ type Model struct {
 	AllApps      []string
 	HelpMode     bool
 
+	// HelpQuery narrows the help to the keys matching it, typed while
+	// HelpSearching; HelpScroll is the first line of the help shown
+	HelpQuery     string
+	HelpSearching bool
+	HelpScroll    int
+
+	// TagMode shows the tag selector; the table only lists shortcuts with
+	// one of SelectedTags, when any
+	TagMode      bool
+	AllTags      []string
+	SelectedTags []string
+	TagCursor    int
+
+	// ProfileMode shows the selector of the configured profiles
+	ProfileMode   bool
+	ProfileCursor int
+
+	// SavedViewMode shows the picker of the saved views; SavedViewNaming
+	// asks for the name to save the table under
+	SavedViewMode   bool
+	SavedViewCursor int
+	SavedViewNaming bool
+	SavedViewName   string
+	// TableSort orders the shortcuts of the table, one of
+	// config.ValidSorts
+	TableSort string
+
+	// TourMode walks through the features at TourStep of tourSteps;
+	// TourPending starts the tour once the first-run wizard closes
+	TourMode    bool
+	TourStep    int
+	TourPending bool
+
+	// ThemeMode shows the theme picker, previewing the theme under
+	// ThemeCursor; themeRenderer is the renderer to go back to on cancel
+	ThemeMode     bool
+	ThemeCursor   int
+	themeRenderer *TableRenderer
+
+	// ExportMode asks for the format to export the table in
+	ExportMode bool
+
+	// SearchHistory holds recent searches, newest first. SearchHistoryPos
+	// is the recalled entry while searching, counting from 1, and
+	// SearchDraft what was typed before recalling.
+	SearchHistory       []string
+	SearchHistoryPos    int
+	SearchDraft         string
+	SearchHistoryMode   bool
+	SearchHistoryCursor int
+	// searchBefore is the search in effect when typing started, restored
+	// on esc; searchSeq numbers the edits, so only the last is debounced
+	searchBefore string
+	searchSeq    int
+
+	// FindQuery searches the shortcuts of the configured apps, the notes
+	// and the online sheets at once; FindCursor moves through the results
+	// in that order
+	FindQuery     string
+	FindShortcuts []apps.ShortcutResult
+	FindNotes     []*notes.Note
+	FindSheets    []online.CheatSheet
+	FindCursor    int
+	findSeq       int
+	findOnlineErr error
+
+	// Diagnoses are the stored app files with problems, once diagnosed
+	Diagnoses         []apps.Diagnosis
+	DiagnosticsScroll int
+	diagnosed         bool
+	diagnoseErr       error
+
+	// Practice schedules quiz questions; QuizQuestions are the shortcuts
+	// of the apps shown when the quiz started
+	Practice        *practice.Tracker
+	QuizQuestions   []practice.Question
+	QuizQuestion    practice.Question
+	QuizAnswer      string
+	QuizRevealed    bool
+	QuizGraded      bool
+	QuizLastCorrect bool
+	QuizAsked       int
+	QuizCorrect     int
+	// Stats is the practice summary shown by the stats view
+	Stats practice.Summary
+
+	// Setup* hold the choices of the first-run wizard until they are saved;
+	// SetupApps lists every app that can be picked
+	SetupStep       int
+	SetupCursor     int
+	SetupApps       []string
+	SetupSelected   []string
+	SetupTheme      string
+	SetupTableStyle string
+	SetupDataDir    string
+	SetupSync       bool
+	SetupEndpoint   string
+	SetupAPIKey     string
+
+	// Width is the width of the terminal, 0 until it is known
+	Width int
+
 	// Phase 4 fields
 	ViewMode     ViewMode
 	Cache        cache.Cache
+	Store        storage.Storage
 	NotesManager notes.Manager
 	PluginLoader *plugins.Loader
 	OnlineClient online.Client
 	SyncManager  *sync.Manager
+	Journal      *journal.Journal
+	// ReadOnly is set when another instance owns the data directory, so
+	// notes are opened read-only
+	ReadOnly bool
 
 	// View-specific state
-	NotesList   []*notes.Note
-	PluginsList []*plugins.LoadedPlugin
-	ReposList   []online.Repository
-	CheatSheets []online.CheatSheet
-	SyncStatus  sync.SyncStatus
+	NotesList    []*notes.Note
+	Templates    []notes.Template
+	NoteLinks    map[string]string
+	PluginsList  []*plugins.LoadedPlugin
+	ReposList    []online.Repository
+	CheatSheets  []online.CheatSheet
+	SheetPreview *online.CheatSheet
+	// SheetUpdates are the downloaded sheets changed in the subscribed
+	// repositories since; checkScheduled is set once the subscriptions
+	// are checked periodically
+	SheetUpdates   []online.SheetUpdate
+	checkScheduled bool
+	// UnverifiedSheets are downloads that could not be verified, waiting
+	// for the user to install them anyway; PluginApproveMode asks whether
+	// to run the unverified plugin under the cursor
+	UnverifiedSheets  []unverifiedSheet
+	PluginApproveMode bool
+	// Downloads are the sheets downloaded in the background, in the order
+	// they were queued; downloader runs them
+	Downloads      []downloadItem
+	DownloadCursor int
+	downloader     *online.DownloadManager
+	// Comparison compares a sheet with its local app in the compare view;
+	// CompareCursor is the first line of it shown
+	Comparison    *sheetComparison
+	CompareCursor int
+
+	SyncStatus sync.SyncStatus
+	// SyncProgress is the last step of the sync started from the TUI; nil
+	// while none runs
+	SyncProgress *sync.Progress
+	syncEvents   <-chan sync.Progress
+	// DeviceMode lists the devices syncing the same data in the sync view
+	DeviceMode   bool
+	DevicesList  []sync.Device
+	DeviceCursor int
+	// DeviceRevoke is the device revoked once x is pressed again
+	DeviceRevoke string
+	// DeviceNameMode prompts for a new name of this device
+	DeviceNameMode  bool
+	DeviceNameInput string
+	DaemonStatus    *daemon.Status
+	HistoryList     []journal.Entry
+	Snapshot        *journal.Snapshot
+	SnapshotTime    time.Time
 
 	// UI state for Phase 4 views
-	NoteCursor    int
-	PluginCursor  int
-	RepoCursor    int
-	SheetCursor   int
-	StatusMessage string
-	Loading       bool
+	NoteCursor     int
+	TemplateMode   bool
+	TemplateCursor int
+	TrashMode      bool
+	TrashList      []*notes.TrashedNote
+	TrashCursor    int
+	PreviewRaw     bool
+	PreviewScroll  int
+	PreviewFrom    ViewMode
+	// DecryptedNote is the encrypted note being previewed, in plain text
+	DecryptedNote *notes.Note
+	// PassphraseMode prompts for the passphrase PassphraseAction needs
+	PassphraseMode   bool
+	PassphraseInput  string
+	PassphraseAction string
+	// PassphraseFirst holds the first entry while a new passphrase is confirmed
+	PassphraseFirst string
+	PluginCursor    int
+	// PluginDetail shows everything known about the plugin under the cursor
+	PluginDetail   bool
+	RepoCursor     int
+	SheetCursor    int
+	SheetFocus     bool
+	SheetSort      string
+	RatingMode     bool
+	ReportMode     bool
+	HistoryCursor  int
+	SnapshotCursor int
+	StatusMessage  string
+	// Toasts are the notifications waiting for their timeout, oldest first
+	Toasts   []Toast
+	toastSeq int
+	// tasks is the I/O running in the background, with a spinner turning
+	// in the status bar while any runs
+	tasks        []task
+	taskSeq      int
+	spinning     bool
+	spinnerFrame int
+	Loading      bool
+	// startup runs the loads started by LoadInBackground; startupLoads
+	// counts those not back yet
+	startup      tea.Cmd
+	startupLoads int
 }
 
 func NewModel() Model {
@@ -83,20 +285,101 @@ func NewModel() Model {
 }
 
 func (m Model) Init() tea.Cmd {
-	return nil
+	cmd := m.runHooks(plugins.Event{Hook: plugins.HookStartup})
+	if len(m.subscriptions()) > 0 {
+		cmd = tea.Batch(cmd, checkUpdatesNow)
+	}
+	if m.startup != nil {
+		cmd = tea.Batch(cmd, m.startup)
+	}
+	return cmd
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case hookResultMsg:
+		cmd := m.showHookResults(msg.results)
+		return m, cmd
+	case searchTickMsg:
+		return m.handleSearchTick(msg)
+	case findTickMsg:
+		return m.runFind(msg)
+	case onlineFoundMsg:
+		m.setOnlineFound(msg)
+		return m, nil
+	case diagnosedMsg:
+		m.setDiagnoses(msg)
+		return m, nil
+	case toastExpiredMsg:
+		m.dismissToast(msg.id)
+		return m, nil
+	case taskDoneMsg, spinnerTickMsg:
+		return m.handleTaskMsg(msg)
+	case subscriptionTickMsg:
+		m.checkScheduled = true
+		cmd := m.checkUpdates(false)
+		return m, tea.Batch(cmd, m.scheduleUpdateCheck())
+	case notesLoadedMsg, notesOpenedMsg, pluginsLoadedMsg, reposLoadedMsg, sheetsLoadedMsg, previewLoadedMsg,
+		repoSheetsMsg, sheetRatedMsg, sheetReportedMsg, devicesLoadedMsg, updatesCheckedMsg, sheetComparedMsg,
+		daemonStatusMsg:
+		return m.handleLoaded(msg)
+	case downloadEventMsg:
+		return m.handleDownloadEvent(msg)
+	case syncProgressMsg:
+		return m.handleSyncProgress(msg)
+	case autoSyncStoppedMsg:
+		return m.handleAutoSyncStopped()
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		if m.Renderer != nil {
+			m.Renderer.SetTerminalWidth(msg.Width)
+		}
+		if m.themeRenderer != nil {
+			m.themeRenderer.SetTerminalWidth(msg.Width)
+		}
+		return m, nil
 	case tea.KeyMsg:
+		// keys the config binds to an action become the key its handler
+		// matches, and keys bound away from an action do nothing
+		msg, bound := m.keymap().translate(m.keyGroupName(), msg)
+		if !bound {
+			return m, nil
+		}
+		// esc first cancels what the view is loading; the table's own loads
+		// are left to finish, as esc clears the search there
+		if msg.Type == tea.KeyEsc && m.ViewMode != ViewMain && m.cancelTasks(m.ViewMode) > 0 {
+			m.StatusMessage = "Cancelled"
+			return m, nil
+		}
 		switch m.ViewMode {
 		case ViewMain:
+			if m.TourMode {
+				return m.HandleTourInput(msg)
+			}
+			if m.SearchHistoryMode {
+				return m.HandleSearchHistoryInput(msg)
+			}
 			if m.SearchMode {
 				return m.HandleSearchInput(msg)
 			}
 			if m.FilterMode {
 				return m.HandleFilterInput(msg)
 			}
+			if m.TagMode {
+				return m.HandleTagInput(msg)
+			}
+			if m.ProfileMode {
+				return m.HandleProfileInput(msg)
+			}
+			if m.SavedViewMode {
+				return m.HandleSavedViewInput(msg)
+			}
+			if m.ThemeMode {
+				return m.HandleThemeInput(msg)
+			}
+			if m.ExportMode {
+				return m.HandleExportInput(msg)
+			}
 			if m.HelpMode {
 				return m.HandleHelpInput(msg)
 			}
@@ -104,19 +387,62 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case ViewNotes:
 			return m.HandleNotesInput(msg)
 		case ViewPlugins:
+			if m.PluginApproveMode {
+				return m.HandlePluginApproveInput(msg)
+			}
 			return m.HandlePluginsInput(msg)
 		case ViewOnline:
+			if len(m.UnverifiedSheets) > 0 {
+				return m.HandleUnverifiedSheetInput(msg)
+			}
+			if m.RatingMode {
+				return m.HandleRatingInput(msg)
+			}
+			if m.ReportMode {
+				return m.HandleReportInput(msg)
+			}
 			return m.HandleOnlineInput(msg)
 		case ViewSync:
 			return m.HandleSyncInput(msg)
 		case ViewHelp:
 			return m.HandleHelpInput(msg)
+		case ViewHistory:
+			return m.HandleHistoryInput(msg)
+		case ViewSnapshot:
+			return m.HandleSnapshotInput(msg)
+		case ViewCache:
+			return m.HandleCacheInput(msg)
+		case ViewNotePreview:
+			return m.HandleNotePreviewInput(msg)
+		case ViewQuiz:
+			return m.HandleQuizInput(msg)
+		case ViewStats:
+			return m.HandleStatsInput(msg)
+		case ViewSetup:
+			return m.HandleSetupInput(msg)
+		case ViewFind:
+			return m.HandleFindInput(msg)
+		case ViewDiagnostics:
+			return m.HandleDiagnosticsInput(msg)
+		case ViewDownloads:
+			return m.HandleDownloadsInput(msg)
+		case ViewCompare:
+			return m.HandleCompareInput(msg)
 		}
 	}
 	return m, nil
 }
 
 func (m Model) View() string {
+	view := m.viewContent() + m.viewToasts() + m.statusBar()
+	if m.accessibility().ASCIIBorders {
+		view = asciiBorders.Replace(view)
+	}
+	return view
+}
+
+// viewContent renders the current view above the status bar
+func (m Model) viewContent() string {
 	switch m.ViewMode {
 	case ViewNotes:
 		return m.ViewNotes()
@@ -128,6 +454,28 @@ func (m Model) View() string {
 		return m.ViewSync()
 	case ViewHelp:
 		return m.ViewHelp()
+	case ViewHistory:
+		return m.ViewHistory()
+	case ViewSnapshot:
+		return m.ViewSnapshot()
+	case ViewCache:
+		return m.ViewCache()
+	case ViewNotePreview:
+		return m.ViewNotePreview()
+	case ViewQuiz:
+		return m.ViewQuiz()
+	case ViewStats:
+		return m.ViewStats()
+	case ViewSetup:
+		return m.ViewSetup()
+	case ViewFind:
+		return m.ViewFind()
+	case ViewDiagnostics:
+		return m.ViewDiagnostics()
+	case ViewDownloads:
+		return m.ViewDownloads()
+	case ViewCompare:
+		return m.ViewCompare()
 	default:
 		return m.ViewMain()
 	}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchDebounce is how long typing must pause before the table is
+// filtered by what was typed so far
+const searchDebounce = 150 * time.Millisecond
+
+// searchTickMsg filters the table by the query being typed, unless it
+// changed again since the tick with seq was scheduled
+type searchTickMsg struct {
+	seq int
+}
+
+// openSearch starts typing a search, keeping the search in effect to go
+// back to on esc
+func (m Model) openSearch() Model {
+	m.SearchMode = true
+	m.searchBefore = m.LastSearch
+	return m
+}
+
+// debounceSearch schedules filtering the table by the query being typed
+func (m *Model) debounceSearch() tea.Cmd {
+	m.searchSeq++
+	seq := m.searchSeq
+	return tea.Tick(searchDebounce, func(time.Time) tea.Msg {
+		return searchTickMsg{seq: seq}
+	})
+}
+
+// handleSearchTick filters the table live once typing has paused
+func (m Model) handleSearchTick(msg searchTickMsg) (tea.Model, tea.Cmd) {
+	if !m.SearchMode || msg.seq != m.searchSeq || m.LastSearch == m.SearchQuery {
+		return m, nil
+	}
+	m.LastSearch = m.SearchQuery
+	m.applyTableFilters()
+	return m, nil
+}
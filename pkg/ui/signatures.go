@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+	"github.com/remuscazacu/cheat-go/pkg/plugins"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+)
+
+// unverifiedSheet is a download that is unsigned or signed by an unknown
+// key, waiting for the user to install it anyway or refuse it
+type unverifiedSheet struct {
+	sheet online.CheatSheet
+	app   *apps.App
+	err   error
+}
+
+// verifier checks signatures with the trusted keys of the config
+func (m Model) verifier() (*signature.Verifier, error) {
+	if m.Config == nil {
+		return nil, nil
+	}
+	return signature.NewVerifier(m.Config.Signatures.TrustedKeys)
+}
+
+// holdUnverified keeps a sheet that could not be verified for the prompt
+// of the online view
+func (m *Model) holdUnverified(sheet online.CheatSheet, app *apps.App, err error) tea.Cmd {
+	m.UnverifiedSheets = append(slices.Clone(m.UnverifiedSheets), unverifiedSheet{sheet: sheet, app: app, err: err})
+	return m.notify(ToastWarn, "%s could not be verified: %v", sheet.Name, err)
+}
+
+// HandleUnverifiedSheetInput handles the prompt to install the first of
+// the sheets that could not be verified
+func (m Model) HandleUnverifiedSheetInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pending := m.UnverifiedSheets[0]
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "y":
+		m.UnverifiedSheets = m.UnverifiedSheets[1:]
+		cmd := m.installSheet(pending.sheet, pending.app, nil)
+		return m, cmd
+	case "n", "esc":
+		m.UnverifiedSheets = m.UnverifiedSheets[1:]
+		cmd := m.notify(ToastInfo, "Did not install %s", pending.sheet.Name)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// selectedPlugin returns the plugin under the cursor of the plugin manager
+func (m Model) selectedPlugin() (*plugins.LoadedPlugin, bool) {
+	if m.PluginCursor >= len(m.PluginsList) {
+		return nil, false
+	}
+	return m.PluginsList[m.PluginCursor], true
+}
+
+// askApprovePlugin asks whether to run the plugin under the cursor although
+// it could not be verified
+func (m *Model) askApprovePlugin() tea.Cmd {
+	plugin, ok := m.selectedPlugin()
+	if !ok {
+		return nil
+	}
+	switch {
+	case plugin.Untrusted == nil:
+		return m.notify(ToastInfo, "%s needs no approval", plugin.Metadata.Name)
+	case !signature.Overridable(plugin.Untrusted):
+		return m.notify(ToastError, "%s cannot be approved: %v", plugin.Metadata.Name, plugin.Untrusted)
+	}
+	m.PluginApproveMode = true
+	return nil
+}
+
+// HandlePluginApproveInput handles the prompt to run an unverified plugin
+func (m Model) HandlePluginApproveInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "y":
+		m.PluginApproveMode = false
+		cmd := m.approvePlugin()
+		return m, cmd
+	case "n", "esc":
+		m.PluginApproveMode = false
+	}
+	return m, nil
+}
+
+// approvePlugin records the sum of the plugin under the cursor in the
+// plugins.approved list of the config and loads the plugins again
+func (m *Model) approvePlugin() tea.Cmd {
+	plugin, ok := m.selectedPlugin()
+	if !ok || plugin.Untrusted == nil {
+		return nil
+	}
+
+	m.PluginLoader.Approve(plugin.Sum)
+	if !slices.Contains(m.Config.Plugins.Approved, plugin.Sum) {
+		m.Config.Plugins.Approved = append(slices.Clone(m.Config.Plugins.Approved), plugin.Sum)
+	}
+	cmd := m.reloadPlugins()
+	if err := m.SaveConfig(); err != nil {
+		return tea.Batch(cmd, m.notify(ToastWarn, "Approved %s for this session, but saving config failed: %v", plugin.Metadata.Name, err))
+	}
+	return tea.Batch(cmd, m.notify(ToastInfo, "Approved %s", plugin.Metadata.Name))
+}
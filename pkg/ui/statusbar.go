@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// viewModeNames name the views in the status bar
+var viewModeNames = map[ViewMode]string{
+	ViewMain:        "TABLE",
+	ViewNotes:       "NOTES",
+	ViewPlugins:     "PLUGINS",
+	ViewOnline:      "ONLINE",
+	ViewSync:        "SYNC",
+	ViewHelp:        "HELP",
+	ViewHistory:     "HISTORY",
+	ViewSnapshot:    "SNAPSHOT",
+	ViewCache:       "CACHE",
+	ViewNotePreview: "NOTE",
+	ViewQuiz:        "QUIZ",
+	ViewStats:       "STATS",
+	ViewSetup:       "SETUP",
+	ViewFind:        "FIND",
+	ViewDiagnostics: "DIAGNOSTICS",
+	ViewDownloads:   "DOWNLOADS",
+	ViewCompare:     "COMPARE",
+}
+
+// modeName names the view and the subscreen the keys act on
+func (m Model) modeName() string {
+	switch {
+	case m.ViewMode == ViewMain && m.TourMode:
+		return "TOUR"
+	case m.ViewMode == ViewMain && (m.SearchMode || m.SearchHistoryMode):
+		return "SEARCH"
+	case m.ViewMode == ViewMain && m.FilterMode:
+		return "FILTER"
+	case m.ViewMode == ViewMain && m.TagMode:
+		return "TAGS"
+	case m.ViewMode == ViewMain && m.ProfileMode:
+		return "PROFILES"
+	case m.ViewMode == ViewMain && m.SavedViewMode:
+		return "VIEWS"
+	case m.ViewMode == ViewMain && m.ThemeMode:
+		return "THEMES"
+	case m.ViewMode == ViewMain && m.ExportMode:
+		return "EXPORT"
+	case m.ViewMode == ViewNotes && m.TrashMode:
+		return "TRASH"
+	case m.ViewMode == ViewNotes && m.TemplateMode:
+		return "TEMPLATES"
+	case m.ViewMode == ViewSync && m.DeviceMode:
+		return "DEVICES"
+	}
+	return viewModeNames[m.ViewMode]
+}
+
+// statusSegments returns what the status bar shows besides the message:
+// the mode, the filters of the table, the sync state and the cache hit
+// ratio
+func (m Model) statusSegments() []string {
+	segments := []string{m.modeName()}
+	if pending := m.pendingLabel(); pending != "" {
+		segments = append(segments, pending)
+	}
+
+	if m.Config != nil && m.Config.ActiveProfile() != "" {
+		segments = append(segments, "profile: "+m.Config.ActiveProfile())
+	}
+	if m.Config != nil && m.Config.Project() != nil {
+		segments = append(segments, "project: "+filepath.Base(filepath.Dir(m.Config.Project().Path)))
+	}
+	if m.Config != nil {
+		if name, ok := m.matchingView(); ok && name != "" {
+			segments = append(segments, "view: "+name)
+		}
+	}
+	if len(m.FilteredApps) > 0 {
+		segments = append(segments, "apps: "+strings.Join(m.FilteredApps, ","))
+	}
+	if len(m.SelectedTags) > 0 {
+		segments = append(segments, "tags: "+strings.Join(m.SelectedTags, ","))
+	}
+	if m.LastSearch != "" {
+		segments = append(segments, fmt.Sprintf("search: %q", m.LastSearch))
+	}
+	if m.TableSort != "" {
+		segments = append(segments, "sort: "+m.TableSort)
+	}
+
+	if m.SyncManager != nil {
+		sync := "idle"
+		switch {
+		case m.SyncProgress != nil:
+			sync = fmt.Sprintf("%.0f%%", m.SyncProgress.Fraction()*100)
+		case m.SyncStatus.LastError != "":
+			sync = "failed"
+		case !m.SyncStatus.LastSync.IsZero():
+			sync = m.SyncStatus.LastSync.Format("15:04")
+		}
+		segments = append(segments, "sync: "+sync)
+	}
+
+	if m.Cache != nil {
+		stats := m.Cache.Stats()
+		if lookups := stats.Hits + stats.Misses; lookups > 0 {
+			segments = append(segments, fmt.Sprintf("cache: %.0f%%", float64(stats.Hits)/float64(lookups)*100))
+		}
+	}
+
+	if m.StatusMessage != "" {
+		segments = append(segments, m.StatusMessage)
+	}
+	return segments
+}
+
+// statusBar renders the status bar shown below every view, as wide as the
+// terminal once its width is known
+func (m Model) statusBar() string {
+	line := " " + strings.Join(m.statusSegments(), " │ ") + " "
+	if m.Width > 0 {
+		line = runewidth.FillRight(runewidth.Truncate(line, m.Width, "…"), m.Width)
+	}
+
+	theme := DefaultTheme()
+	if m.Renderer != nil {
+		theme = m.Renderer.GetTheme()
+	}
+	return "\n" + theme.StatusBarStyle.Render(line) + "\n"
+}
@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/remuscazacu/cheat-go/pkg/online"
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+// defaultCheckInterval is how often subscribed repositories are checked
+// for updates when the config leaves it unset
+const defaultCheckInterval = 6 * time.Hour
+
+// subscriptionTickMsg asks Update to check the subscribed repositories and
+// to schedule the next check
+type subscriptionTickMsg struct{}
+
+// checkUpdatesNow starts the periodic checks of the subscriptions
+func checkUpdatesNow() tea.Msg {
+	return subscriptionTickMsg{}
+}
+
+// updatesCheckedMsg is the result of checking the subscribed repositories;
+// manual checks report that nothing changed
+type updatesCheckedMsg struct {
+	updates []online.SheetUpdate
+	err     error
+	manual  bool
+}
+
+// checkInterval is how long to wait between checks of the subscriptions
+func (m Model) checkInterval() time.Duration {
+	if m.Config == nil || m.Config.Online.CheckInterval <= 0 {
+		return defaultCheckInterval
+	}
+	return m.Config.Online.CheckInterval
+}
+
+// subscriptions returns the URLs of the subscribed repositories
+func (m Model) subscriptions() []string {
+	if m.Config == nil {
+		return nil
+	}
+	return m.Config.Online.Subscriptions
+}
+
+// isSubscribed reports whether the repository at url is subscribed to
+func (m Model) isSubscribed(url string) bool {
+	return slices.Contains(m.subscriptions(), url)
+}
+
+// installedSheets reads the record of the downloaded sheets from m.Store
+func (m Model) installedSheets() ([]online.InstalledSheet, error) {
+	if m.Store == nil {
+		return nil, nil
+	}
+	var sheets []online.InstalledSheet
+	if err := storage.GetJSON(m.Store, storage.CollectionSession, online.InstalledSheetsKey, &sheets); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	return sheets, nil
+}
+
+// recordInstalled records sheet as downloaded into the app called app,
+// replacing the revision downloaded before, and forgets its update
+func (m *Model) recordInstalled(sheet online.CheatSheet, app string) error {
+	m.SheetUpdates = slices.DeleteFunc(slices.Clone(m.SheetUpdates), func(update online.SheetUpdate) bool {
+		return update.Sheet.ID == sheet.ID
+	})
+	if m.Store == nil {
+		return nil
+	}
+
+	sheets, err := m.installedSheets()
+	if err != nil {
+		return err
+	}
+	sheets = slices.DeleteFunc(sheets, func(installed online.InstalledSheet) bool {
+		return installed.ID == sheet.ID
+	})
+	sheets = append(sheets, sheet.Installed(app))
+	return storage.PutJSON(m.Store, storage.CollectionSession, online.InstalledSheetsKey, sheets)
+}
+
+// scheduleUpdateCheck returns the command asking for the next check of
+// the subscriptions after the interval of the config
+func (m Model) scheduleUpdateCheck() tea.Cmd {
+	return tea.Tick(m.checkInterval(), func(time.Time) tea.Msg {
+		return subscriptionTickMsg{}
+	})
+}
+
+// checkUpdates checks the subscribed repositories for new revisions of the
+// downloaded sheets in the background
+func (m *Model) checkUpdates(manual bool) tea.Cmd {
+	if m.OnlineClient == nil || len(m.subscriptions()) == 0 {
+		if manual {
+			return m.notify(ToastInfo, "Subscribe to a repository with %s to check it for updates", m.boundKey("online.subscribe"))
+		}
+		return nil
+	}
+	installed, err := m.installedSheets()
+	if err != nil {
+		return m.notify(ToastError, "Error reading the downloaded sheets: %v", err)
+	}
+
+	client, subscriptions := m.OnlineClient, slices.Clone(m.subscriptions())
+	return m.startTask("checking for sheet updates", func(ctx context.Context) tea.Msg {
+		updates, err := online.CheckUpdates(online.WithContext(ctx, client), subscriptions, installed)
+		return updatesCheckedMsg{updates: updates, err: err, manual: manual}
+	})
+}
+
+// setSheetUpdates keeps the updates found, telling of those that are new
+func (m *Model) setSheetUpdates(msg updatesCheckedMsg) tea.Cmd {
+	fresh := 0
+	for _, update := range msg.updates {
+		if !slices.ContainsFunc(m.SheetUpdates, func(known online.SheetUpdate) bool {
+			return known.Sheet.ID == update.Sheet.ID && known.Sheet.Revision() == update.Sheet.Revision()
+		}) {
+			fresh++
+		}
+	}
+	m.SheetUpdates = msg.updates
+
+	switch {
+	case msg.err != nil:
+		return m.notify(ToastWarn, "Some subscriptions could not be checked: %v", msg.err)
+	case fresh > 0:
+		return m.notify(ToastInfo, "%d downloaded cheat sheets changed online; press %s, then %s, to update them",
+			len(msg.updates), m.boundKey("online"), m.boundKey("online.update"))
+	case msg.manual && len(msg.updates) == 0:
+		return m.notify(ToastInfo, "The downloaded cheat sheets are up to date")
+	}
+	return nil
+}
+
+// sheetUpdate returns the update of the sheet with the ID, if there is one
+func (m Model) sheetUpdate(id string) (online.SheetUpdate, bool) {
+	for _, update := range m.SheetUpdates {
+		if update.Sheet.ID == id {
+			return update, true
+		}
+	}
+	return online.SheetUpdate{}, false
+}
+
+// repoUpdates counts the updates of the sheets of the repository at url
+func (m Model) repoUpdates(url string) int {
+	count := 0
+	for _, update := range m.SheetUpdates {
+		if update.Sheet.Repository == url {
+			count++
+		}
+	}
+	return count
+}
+
+// toggleSubscription subscribes to the repository under the cursor, or
+// unsubscribes from it, and saves the config
+func (m *Model) toggleSubscription() tea.Cmd {
+	if m.RepoCursor >= len(m.ReposList) || m.Config == nil {
+		return nil
+	}
+	repo := m.ReposList[m.RepoCursor]
+
+	subscribed := !m.isSubscribed(repo.URL)
+	if subscribed {
+		m.Config.Online.Subscriptions = append(slices.Clone(m.Config.Online.Subscriptions), repo.URL)
+	} else {
+		m.Config.Online.Subscriptions = slices.DeleteFunc(slices.Clone(m.Config.Online.Subscriptions), func(url string) bool {
+			return url == repo.URL
+		})
+		m.SheetUpdates = slices.DeleteFunc(slices.Clone(m.SheetUpdates), func(update online.SheetUpdate) bool {
+			return update.Sheet.Repository == repo.URL
+		})
+	}
+
+	verb := "Subscribed to"
+	if !subscribed {
+		verb = "Unsubscribed from"
+	}
+	if err := m.SaveConfig(); err != nil {
+		return m.notify(ToastError, "%s %s for this session, but saving config failed: %v", verb, repo.Name, err)
+	}
+	cmd := m.notify(ToastInfo, "%s %s", verb, repo.Name)
+	if subscribed && !m.checkScheduled {
+		return tea.Batch(cmd, checkUpdatesNow)
+	}
+	return cmd
+}
+
+// updateSheets downloads again every sheet changed online
+func (m *Model) updateSheets() tea.Cmd {
+	if len(m.SheetUpdates) == 0 {
+		return m.notify(ToastInfo, "No downloaded cheat sheet changed online")
+	}
+	sheets := make([]online.CheatSheet, len(m.SheetUpdates))
+	for i, update := range m.SheetUpdates {
+		sheets[i] = update.Sheet
+	}
+	return m.queueDownloads(sheets)
+}
+
+// updateBadge marks repositories and sheets with changes to download
+func updateBadge(count int) string {
+	if count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("⬆%d", count)
+}
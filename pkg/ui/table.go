@@ -3,14 +3,31 @@ package ui
 import (
 	"strings"
 
-	"github.com/mattn/go-runewidth"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rivo/uniseg"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
 )
 
+// minColumnWidth is the narrowest a column is made to fit the table in its
+// width budget
+const minColumnWidth = 6
+
 // TableRenderer handles the rendering of tabular data
 type TableRenderer struct {
 	theme      *Theme
 	tableStyle string
 	maxWidth   int
+	// termWidth is the width of the terminal, 0 while unknown
+	termWidth int
+	// wrap breaks cells too wide for their column into several lines
+	// instead of truncating them
+	wrap bool
+	// keyNotation is the apps notation the Shortcut column is shown in
+	keyNotation string
+	// zebra and rowHighlight override the choices of the theme when set
+	zebra        *bool
+	rowHighlight *bool
 }
 
 // NewTableRenderer creates a new table renderer with the given theme
@@ -30,37 +47,31 @@ func (r *TableRenderer) Render(rows [][]string, cursorX, cursorY int) string {
 
 	var b strings.Builder
 
-	// Determine column widths using runewidth
-	colWidths := make([]int, len(rows[0]))
-	for _, row := range rows {
-		for i, cell := range row {
-			if w := runewidth.StringWidth(cell); w > colWidths[i] {
-				colWidths[i] = w
-			}
-		}
-	}
+	colWidths := r.columnWidths(rows)
 
 	// Render rows
 	for y, row := range rows {
-		for x, cell := range row {
-			cellWidth := runewidth.StringWidth(cell)
-			pad := colWidths[x] - cellWidth
-			content := " " + cell + strings.Repeat(" ", pad) + " "
-
-			style := r.theme.CellStyle
-			if y == 0 {
-				style = r.theme.HeaderStyle
-			}
-			if x == cursorX && y == cursorY {
-				style = style.Reverse(true)
-			}
+		if y > 0 && apps.IsSectionRow(row) {
+			b.WriteString(r.sectionLine(row[0], colWidths))
+			continue
+		}
+		lines := r.rowLines(row, y, colWidths)
+		for line := range lines {
+			for x, cell := range lines[line] {
+				content := " " + fillWidth(cell, colWidths[x]) + " "
+
+				style := r.rowStyle(y, cursorY)
+				if x == cursorX && y == cursorY {
+					style = style.Reverse(true)
+				}
 
-			b.WriteString(style.Render(content))
-			if x < len(row)-1 {
-				b.WriteString("│")
+				b.WriteString(style.Render(content))
+				if x < len(row)-1 {
+					b.WriteString(r.rowStyle(y, cursorY).Render("│"))
+				}
 			}
+			b.WriteString("\n")
 		}
-		b.WriteString("\n")
 
 		// Add separator after header
 		if y == 0 {
@@ -82,6 +93,22 @@ func (r *TableRenderer) GetTheme() *Theme {
 	return r.theme
 }
 
+// WithTheme returns a copy of the renderer drawing with theme, keeping its
+// table style and other settings
+func (r *TableRenderer) WithTheme(theme *Theme) *TableRenderer {
+	renderer := *r
+	renderer.theme = theme
+	return &renderer
+}
+
+// WithTerminalWidth returns a copy of the renderer fitting tables in width
+// columns, such as what is left of the terminal beside a pane
+func (r *TableRenderer) WithTerminalWidth(width int) *TableRenderer {
+	renderer := *r
+	renderer.termWidth = width
+	return &renderer
+}
+
 // SetTableStyle sets the table style
 func (r *TableRenderer) SetTableStyle(style string) {
 	r.tableStyle = style
@@ -92,86 +119,302 @@ func (r *TableRenderer) SetMaxWidth(width int) {
 	r.maxWidth = width
 }
 
-// highlightSearchTerm highlights search terms in the given text
-func (r *TableRenderer) highlightSearchTerm(text, searchTerm string) string {
-	if searchTerm == "" {
-		return text
+// SetTerminalWidth sets the width of the terminal, which bounds the table
+// like the maximum width does
+func (r *TableRenderer) SetTerminalWidth(width int) {
+	r.termWidth = width
+}
+
+// SetWrap makes cells too wide for their column wrap onto more lines
+// instead of being cut short with "…"
+func (r *TableRenderer) SetWrap(wrap bool) {
+	r.wrap = wrap
+}
+
+// width returns the most columns the table may take: the smaller of the
+// maximum and terminal widths, or 0 when neither is set
+func (r *TableRenderer) width() int {
+	switch {
+	case r.maxWidth <= 0:
+		return r.termWidth
+	case r.termWidth <= 0:
+		return r.maxWidth
+	default:
+		return min(r.maxWidth, r.termWidth)
+	}
+}
+
+// columnWidths returns the width of the widest cell of each column,
+// narrowing the widest columns until the table fits in its width. Each
+// column takes two columns of padding and one of separator besides.
+func (r *TableRenderer) columnWidths(rows [][]string) []int {
+	widths := make([]int, len(rows[0]))
+	for y, row := range rows {
+		if y > 0 && apps.IsSectionRow(row) {
+			continue
+		}
+		for i, cell := range row {
+			if w := displayWidth(r.displayCell(i, y, cell)); w > widths[i] {
+				widths[i] = w
+			}
+		}
 	}
 
-	// Case-insensitive highlighting
-	lowerText := strings.ToLower(text)
-	lowerTerm := strings.ToLower(searchTerm)
+	limit := r.width()
+	if limit <= 0 {
+		return widths
+	}
+	total := len(widths) - 1
+	for _, w := range widths {
+		total += w + 2
+	}
+	for total > limit {
+		widest := -1
+		for i, w := range widths {
+			if w > minColumnWidth && (widest == -1 || w > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest == -1 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
 
-	if !strings.Contains(lowerText, lowerTerm) {
-		return text
+// sectionLine renders the row heading a category across the whole table
+func (r *TableRenderer) sectionLine(title string, widths []int) string {
+	width := len(widths) - 1
+	for _, w := range widths {
+		width += w + 2
 	}
+	title = truncateWidth(sanitizeText(title), max(0, width-1), "…")
+	return r.theme.HeaderStyle.Render(" "+fillWidth(title, width-1)) + "\n"
+}
 
-	// Find the position of the match
-	index := strings.Index(lowerText, lowerTerm)
-	if index == -1 {
-		return text
+// rowLines returns the lines row y takes with the given column widths:
+// one, unless wrapping breaks a cell onto more
+func (r *TableRenderer) rowLines(row []string, y int, widths []int) [][]string {
+	cells := make([][]string, len(row))
+	height := 1
+	for x, cell := range row {
+		cells[x] = r.fitCell(r.displayCell(x, y, cell), widths[x])
+		height = max(height, len(cells[x]))
 	}
 
-	// Extract the actual case-preserved match
-	beforeMatch := text[:index]
-	actualMatch := text[index : index+len(searchTerm)]
-	afterMatch := text[index+len(searchTerm):]
+	lines := make([][]string, height)
+	for i := range lines {
+		lines[i] = make([]string, len(row))
+		for x := range row {
+			if i < len(cells[x]) {
+				lines[i][x] = cells[x][i]
+			}
+		}
+	}
+	return lines
+}
 
-	// Apply highlighting style
-	highlightedMatch := r.theme.HighlightStyle.Render(actualMatch)
+// fitCell fits cell in width, truncating it with "…" or wrapping it
+func (r *TableRenderer) fitCell(cell string, width int) []string {
+	if displayWidth(cell) <= width {
+		return []string{cell}
+	}
+	if !r.wrap {
+		return []string{truncateWidth(cell, width, "…")}
+	}
+	return wrapText(cell, width)
+}
+
+// SetZebra turns the striping of every other row on or off, whatever the
+// theme chooses
+func (r *TableRenderer) SetZebra(on bool) {
+	r.zebra = &on
+}
 
-	// Recursively highlight remaining occurrences
-	highlightedAfter := r.highlightSearchTerm(afterMatch, searchTerm)
+// SetRowHighlight turns highlighting the whole row under the cursor on or
+// off, whatever the theme chooses
+func (r *TableRenderer) SetRowHighlight(on bool) {
+	r.rowHighlight = &on
+}
 
-	return beforeMatch + highlightedMatch + highlightedAfter
+func (r *TableRenderer) stripes() bool {
+	if r.zebra != nil {
+		return *r.zebra
+	}
+	return r.theme.Zebra
+}
+
+func (r *TableRenderer) highlightsRow() bool {
+	if r.rowHighlight != nil {
+		return *r.rowHighlight
+	}
+	return r.theme.RowHighlight
+}
+
+// cellStyle returns the style of the cells of row y before row styling
+func (r *TableRenderer) cellStyle(y int) lipgloss.Style {
+	if y == 0 {
+		return r.theme.HeaderStyle.Copy()
+	}
+	return r.theme.CellStyle.Copy()
+}
+
+// rowStyle returns the style of the cells of row y other than the one
+// under the cursor: the row under the cursor when whole rows are
+// highlighted, then the stripe of every other row
+func (r *TableRenderer) rowStyle(y, cursorY int) lipgloss.Style {
+	style := r.cellStyle(y)
+	if y == 0 {
+		return style
+	}
+	if y == cursorY && r.highlightsRow() {
+		style = style.Inherit(r.theme.SelectedRowStyle)
+	}
+	if y%2 == 0 && r.stripes() {
+		style = style.Inherit(r.theme.StripeStyle)
+	}
+	return style
+}
+
+// SetKeyNotation sets the notation of the keys in the Shortcut column;
+// an empty notation shows keys as written
+func (r *TableRenderer) SetKeyNotation(notation string) {
+	r.keyNotation = notation
+}
+
+// displayCell returns the text shown for the cell at column x of row y,
+// on one line and without control characters
+func (r *TableRenderer) displayCell(x, y int, cell string) string {
+	cell = sanitizeText(cell)
+	if x == 0 && y > 0 && r.keyNotation != "" {
+		return apps.FormatKeys(cell, r.keyNotation)
+	}
+	return cell
+}
+
+// highlightSearchTerm highlights search terms in the given text
+func (r *TableRenderer) highlightSearchTerm(text, searchTerm string) string {
+	return r.highlightTerms(text, []string{searchTerm})
+}
+
+// highlightTerms highlights every case-insensitive occurrence of each of
+// terms in text, merging overlapping matches. Text is matched by grapheme
+// cluster, so a match never splits a multi-byte character and folding case
+// may change the length of a character.
+func (r *TableRenderer) highlightTerms(text string, terms []string) string {
+	// fold each cluster on its own, remembering where it starts in the
+	// folded text
+	var clusters []string
+	var starts []int
+	var folded strings.Builder
+	state := -1
+	for rest := text; rest != ""; {
+		var cluster string
+		cluster, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		clusters = append(clusters, cluster)
+		starts = append(starts, folded.Len())
+		folded.WriteString(strings.ToLower(cluster))
+	}
+	lowerText := folded.String()
+	starts = append(starts, len(lowerText))
+
+	marked := make([]bool, len(clusters))
+	found := false
+	for _, term := range terms {
+		lowerTerm := strings.ToLower(term)
+		if lowerTerm == "" {
+			continue
+		}
+		for offset := 0; ; {
+			index := strings.Index(lowerText[offset:], lowerTerm)
+			if index == -1 {
+				break
+			}
+			start, end := offset+index, offset+index+len(lowerTerm)
+			for c := range clusters {
+				if starts[c] < end && starts[c+1] > start {
+					marked[c] = true
+				}
+			}
+			found = true
+			offset = end
+		}
+	}
+	if !found {
+		return text
+	}
+
+	// Apply highlighting style to each run of matched clusters
+	var b strings.Builder
+	for start := 0; start < len(clusters); {
+		end := start
+		for end < len(clusters) && marked[end] == marked[start] {
+			end++
+		}
+		run := strings.Join(clusters[start:end], "")
+		if marked[start] {
+			b.WriteString(r.theme.HighlightStyle.Render(run))
+		} else {
+			b.WriteString(run)
+		}
+		start = end
+	}
+	return b.String()
 }
 
 // RenderWithHighlighting renders the table with search term highlighting
 func (r *TableRenderer) RenderWithHighlighting(rows [][]string, cursorX, cursorY int, searchTerm string) string {
+	return r.RenderWithHighlightedTerms(rows, cursorX, cursorY, []string{searchTerm})
+}
+
+// RenderWithHighlightedTerms renders the table, highlighting every
+// occurrence of each of terms
+func (r *TableRenderer) RenderWithHighlightedTerms(rows [][]string, cursorX, cursorY int, terms []string) string {
 	if len(rows) == 0 {
 		return ""
 	}
 
 	var b strings.Builder
 
-	// Determine column widths using runewidth (without highlight markup)
-	colWidths := make([]int, len(rows[0]))
-	for _, row := range rows {
-		for i, cell := range row {
-			if w := runewidth.StringWidth(cell); w > colWidths[i] {
-				colWidths[i] = w
-			}
-		}
-	}
+	// Determine column widths without highlight markup
+	colWidths := r.columnWidths(rows)
 
 	// Render rows with highlighting
 	for y, row := range rows {
-		for x, cell := range row {
-			cellWidth := runewidth.StringWidth(cell)
-			pad := colWidths[x] - cellWidth
-
-			// Apply highlighting if not header row and search term exists
-			content := cell
-			if y > 0 && searchTerm != "" {
-				content = r.highlightSearchTerm(cell, searchTerm)
-			}
+		if y > 0 && apps.IsSectionRow(row) {
+			b.WriteString(r.sectionLine(row[0], colWidths))
+			continue
+		}
+		lines := r.rowLines(row, y, colWidths)
+		for line := range lines {
+			for x, cell := range lines[line] {
+				// Apply highlighting if not header row and search term exists
+				content := cell
+				if y > 0 && len(terms) > 0 {
+					content = r.highlightTerms(cell, terms)
+				}
 
-			contentWithPadding := " " + content + strings.Repeat(" ", pad) + " "
+				contentWithPadding := " " + fillWidth(content, colWidths[x]) + " "
 
-			style := r.theme.CellStyle
-			if y == 0 {
-				style = r.theme.HeaderStyle
-			}
-			if x == cursorX && y == cursorY {
-				style = style.Copy().Inherit(r.theme.SelectedRowStyle)
-			}
+				style := r.rowStyle(y, cursorY)
+				if x == cursorX && y == cursorY {
+					if r.highlightsRow() {
+						// the row already has the selected style
+						style = style.Reverse(true)
+					} else {
+						style = r.cellStyle(y).Inherit(r.theme.SelectedRowStyle).Inherit(style)
+					}
+				}
 
-			b.WriteString(style.Render(contentWithPadding))
-			if x < len(row)-1 {
-				b.WriteString("│")
+				b.WriteString(style.Render(contentWithPadding))
+				if x < len(row)-1 {
+					b.WriteString(r.rowStyle(y, cursorY).Render("│"))
+				}
 			}
+			b.WriteString("\n")
 		}
-		b.WriteString("\n")
 
 		// Add separator after header
 		if y == 0 {
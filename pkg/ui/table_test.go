@@ -3,6 +3,11 @@ package ui
 import (
 	"strings"
 	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
 )
 
 func TestNewTableRenderer(t *testing.T) {
@@ -326,6 +331,154 @@ func TestTableRenderer_HighlightSearchTerm(t *testing.T) {
 	}
 }
 
+func TestTableRenderer_HighlightTerms(t *testing.T) {
+	renderer := NewTableRenderer(DefaultTheme())
+	style := renderer.theme.HighlightStyle
+
+	result := renderer.highlightTerms("next window", []string{"window", "next"})
+	if result != style.Render("next")+" "+style.Render("window") {
+		t.Errorf("every term should be highlighted, got %q", result)
+	}
+
+	result = renderer.highlightTerms("window", []string{"win", "dow"})
+	if result != style.Render("window") {
+		t.Errorf("overlapping matches should be merged, got %q", result)
+	}
+}
+
+func TestTableRenderer_HighlightTermsUnicode(t *testing.T) {
+	renderer := NewTableRenderer(DefaultTheme())
+	style := renderer.theme.HighlightStyle
+
+	for _, tc := range []struct {
+		text, term, want string
+	}{
+		{"← move left", "move", "← " + style.Render("move") + " left"},
+		{"切换窗口 window", "窗口", "切换" + style.Render("窗口") + " window"},
+		// the lowercase of İ is longer than İ
+		{"İstanbul İSTANBUL", "stanbul", "İ" + style.Render("stanbul") + " İ" + style.Render("STANBUL")},
+		// a match inside a cluster highlights the whole cluster
+		{"cafe\u0301 noir", "cafe", style.Render("cafe\u0301") + " noir"},
+	} {
+		if got := renderer.highlightTerms(tc.text, []string{tc.term}); got != tc.want {
+			t.Errorf("highlightTerms(%q, %q) = %q, want %q", tc.text, tc.term, got, tc.want)
+		}
+	}
+}
+
+func TestTableRenderer_HighlightedColumnsAlign(t *testing.T) {
+	renderer := NewTableRenderer(DefaultTheme())
+	data := [][]string{
+		{"Shortcut", "app", "other"},
+		{"C-w", "切换窗口 → 👩‍💻 here", "x"},
+		{"e\u0301", "move ← left", "y"},
+	}
+	result := renderer.RenderWithHighlightedTerms(data, 0, 0, []string{"窗口", "left", "here"})
+	lines := strings.Split(strings.TrimRight(result, "\n"), "\n")
+	want := lipgloss.Width(lines[0])
+	for _, line := range lines {
+		if got := lipgloss.Width(line); got != want {
+			t.Errorf("line %q is %d columns wide, want %d", line, got, want)
+		}
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	if got := truncateWidth("👩‍💻👩‍💻👩‍💻", 5, "…"); got != "👩‍💻👩‍💻…" {
+		t.Errorf("emoji sequences should not be split, got %q", got)
+	}
+	if got := truncateWidth("窗口窗口", 5, "…"); got != "窗口…" || displayWidth(got) != 5 {
+		t.Errorf("truncateWidth = %q", got)
+	}
+	if got := truncateWidth("short", 10, "…"); got != "short" {
+		t.Errorf("text that fits should be kept, got %q", got)
+	}
+	if got := wrapText("窗口", 1); len(got) != 2 {
+		t.Errorf("characters wider than the line should get a line each, got %q", got)
+	}
+}
+
+func TestTableRenderer_ControlCharacters(t *testing.T) {
+	renderer := NewTableRenderer(DefaultTheme())
+	data := [][]string{
+		{"Shortcut", "app"},
+		{"C-x\tC-s", "save\nthe file\r\nnow"},
+		{"q", "\x1b[31mquit\x1b[0m\x07 \x1b]8;;https://example.com\x07link\x1b]8;;\x07"},
+	}
+	for _, terms := range [][]string{nil, {"the"}} {
+		result := renderer.RenderWithHighlightedTerms(data, 0, 0, terms)
+		lines := strings.Split(strings.TrimRight(result, "\n"), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("every row should take one line, got %q", lines)
+		}
+		plain := stripANSI(result)
+		for _, want := range []string{"C-x    C-s", "save the file now", "quit link"} {
+			if !strings.Contains(plain, want) {
+				t.Errorf("table should show %q:\n%s", want, plain)
+			}
+		}
+		if strings.ContainsAny(plain, "\a\t\r") || strings.Contains(plain, "example.com") {
+			t.Errorf("control characters should be dropped: %q", plain)
+		}
+		width := lipgloss.Width(lines[0])
+		for _, line := range lines {
+			if lipgloss.Width(line) != width {
+				t.Errorf("line %q is %d columns wide, want %d", line, lipgloss.Width(line), width)
+			}
+		}
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	style := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	for text, want := range map[string]int{
+		"plain":                         5,
+		"\x1b[1;38;5;205mstyled\x1b[0m": 6,
+		"\x1b]8;;https://x.org\x1b\\link\x1b]8;;\x1b\\": 4,
+		style.Render("窗口"):                              4,
+	} {
+		if got := displayWidth(text); got != want {
+			t.Errorf("displayWidth(%q) = %d, want %d", text, got, want)
+		}
+	}
+	if got := sanitizeText("a\tb\nc\x00d"); got != "a    b cd" {
+		t.Errorf("sanitizeText = %q", got)
+	}
+}
+
+func TestTableRenderer_RenderLinear(t *testing.T) {
+	renderer := NewTableRenderer(MinimalTheme())
+	renderer.SetKeyNotation("emacs")
+	rows := [][]string{
+		{"Shortcut", "vim", "tmux"},
+		{apps.SectionPrefix + "navigation", "", ""},
+		{"gg", "top", ""},
+		{"Ctrl+B d", "-", "detach"},
+	}
+	result := stripANSI(renderer.RenderLinear(rows, 3))
+	want := "\nCategory navigation\n  gg — vim: top\n> C-b d — tmux: detach\n"
+	if result != want {
+		t.Errorf("RenderLinear =\n%q\nwant\n%q", result, want)
+	}
+}
+
+func TestTableRenderer_KeyNotation(t *testing.T) {
+	renderer := NewTableRenderer(DefaultTheme())
+	data := [][]string{
+		{"Shortcut", "Ctrl+X"},
+		{"Ctrl+X", "cut"},
+	}
+
+	renderer.SetKeyNotation("emacs")
+	result := renderer.RenderWithHighlightedTerms(data, 0, 0, nil)
+	if !strings.Contains(result, "C-x") {
+		t.Errorf("Shortcut column should use emacs notation, got %q", result)
+	}
+	if !strings.Contains(result, "Ctrl+X") {
+		t.Error("header and other columns should be kept as written")
+	}
+}
+
 func TestTableRenderer_RenderWithHighlighting(t *testing.T) {
 	theme := DefaultTheme()
 	renderer := NewTableRenderer(theme)
@@ -357,3 +510,99 @@ func TestTableRenderer_RenderWithHighlighting(t *testing.T) {
 		t.Error("should return non-empty string even with empty search term")
 	}
 }
+
+func TestTableRenderer_MaxWidth(t *testing.T) {
+	data := [][]string{
+		{"Shortcut", "Vim"},
+		{"dd", "Delete the current line and keep it in the unnamed register"},
+		{"u", "Undo"},
+	}
+
+	renderer := NewTableRenderer(DefaultTheme())
+	renderer.SetMaxWidth(40)
+	lines := strings.Split(strings.TrimSuffix(renderer.Render(data, -1, -1), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("a truncated table should keep one line per row, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if w := runewidth.StringWidth(line); w > 40 {
+			t.Errorf("line is %d columns wide, want at most 40: %q", w, line)
+		}
+	}
+	if !strings.Contains(lines[2], "…") || !strings.Contains(lines[3], "Undo") {
+		t.Errorf("only the long description should be cut short:\n%s", strings.Join(lines, "\n"))
+	}
+
+	// the terminal narrows the table further
+	renderer.SetTerminalWidth(30)
+	for _, line := range strings.Split(renderer.Render(data, -1, -1), "\n") {
+		if w := runewidth.StringWidth(line); w > 30 {
+			t.Errorf("line is %d columns wide in a 30 column terminal: %q", w, line)
+		}
+	}
+
+	renderer.SetWrap(true)
+	wrapped := renderer.RenderWithHighlightedTerms(data, -1, -1, nil)
+	if strings.Contains(wrapped, "…") || !strings.Contains(wrapped, "register") {
+		t.Errorf("wrapping should keep the whole description:\n%s", wrapped)
+	}
+	if n := strings.Count(wrapped, "\n"); n <= 4 {
+		t.Errorf("the long description should take several lines, got %d lines", n)
+	}
+}
+
+func TestTableRenderer_ZebraAndRowHighlight(t *testing.T) {
+	theme := DarkTheme()
+	renderer := NewTableRenderer(theme)
+
+	stripe := theme.StripeStyle.GetBackground()
+	selected := theme.SelectedRowStyle.GetBackground()
+	if got := renderer.rowStyle(2, 1).GetBackground(); got != stripe {
+		t.Errorf("even rows should be striped, got background %v", got)
+	}
+	if got := renderer.rowStyle(3, 1).GetBackground(); got == stripe {
+		t.Error("odd rows should not be striped")
+	}
+	if got := renderer.rowStyle(2, 2).GetBackground(); got != selected {
+		t.Errorf("the row under the cursor should be highlighted over its stripe, got %v", got)
+	}
+	if got := renderer.rowStyle(0, 0).GetBackground(); got == selected || got == stripe {
+		t.Error("the header should keep its style")
+	}
+
+	// the configuration overrides the theme
+	renderer.SetZebra(false)
+	renderer.SetRowHighlight(false)
+	if got := renderer.rowStyle(2, 2).GetBackground(); got == stripe || got == selected {
+		t.Errorf("stripes and row highlight should be off, got %v", got)
+	}
+	if DefaultTheme().Zebra || !LightTheme().Zebra {
+		t.Error("only some themes stripe their rows by default")
+	}
+}
+
+func TestTableRenderer_Render_Sections(t *testing.T) {
+	renderer := NewTableRenderer(DefaultTheme())
+	data := [][]string{
+		{"Shortcut", "vim"},
+		{"▸ a rather long navigation heading", ""},
+		{"gg", "top"},
+	}
+
+	for _, result := range []string{renderer.Render(data, 1, 2), renderer.RenderWithHighlightedTerms(data, 1, 2, []string{"top"})} {
+		lines := strings.Split(strings.TrimRight(result, "\n"), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("expected header, separator, section and row, got:\n%s", result)
+		}
+		section := lines[2]
+		if !strings.Contains(section, "▸ a rather") || strings.Contains(section, "│") {
+			t.Errorf("the section should span the table without separators: %q", section)
+		}
+		if runewidth.StringWidth(section) != runewidth.StringWidth(lines[1]) {
+			t.Errorf("the section should be as wide as the table:\n%s", result)
+		}
+		if strings.Contains(lines[0], "navigation") || runewidth.StringWidth(lines[3]) > len("Shortcut")+len("vim")+5 {
+			t.Errorf("the section should not widen the columns:\n%s", result)
+		}
+	}
+}
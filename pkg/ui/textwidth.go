@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+)
+
+// displayWidth returns the terminal columns text takes, measured by
+// grapheme cluster so an emoji sequence or a letter with combining marks
+// counts once. Escape sequences, such as the styles of highlights, take
+// none.
+func displayWidth(text string) int {
+	if strings.IndexByte(text, '\x1b') != -1 {
+		text = stripANSI(text)
+	}
+	return uniseg.StringWidth(text)
+}
+
+// sanitizeText puts text on one line for a table cell: line breaks become
+// spaces, tabs four spaces, and escape sequences and other control
+// characters, which would move the cursor or restyle the terminal, are
+// dropped
+func sanitizeText(text string) string {
+	clean := true
+	for _, r := range text {
+		if unicode.IsControl(r) {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return text
+	}
+
+	text = strings.ReplaceAll(stripANSI(text), "\r\n", " ")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\r':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, strings.ReplaceAll(text, "\t", "    "))
+}
+
+// truncateWidth cuts text to at most width columns, ending it with tail
+// when it had to be cut, without splitting a grapheme cluster
+func truncateWidth(text string, width int, tail string) string {
+	if displayWidth(text) <= width {
+		return text
+	}
+	width -= displayWidth(tail)
+
+	var b strings.Builder
+	used := 0
+	state := -1
+	for rest := text; rest != ""; {
+		var cluster string
+		var clusterWidth int
+		cluster, rest, clusterWidth, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		if used+clusterWidth > width {
+			break
+		}
+		b.WriteString(cluster)
+		used += clusterWidth
+	}
+	return b.String() + tail
+}
+
+// fillWidth pads text with spaces on the right to width columns
+func fillWidth(text string, width int) string {
+	return text + strings.Repeat(" ", max(0, width-displayWidth(text)))
+}
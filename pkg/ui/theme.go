@@ -14,6 +14,16 @@ type Theme struct {
 	SearchStyle      lipgloss.Style
 	SearchInputStyle lipgloss.Style
 	TableStyle       string
+	// StripeStyle colors every other row of the table when Zebra is set
+	StripeStyle lipgloss.Style
+	Zebra       bool
+	// RowHighlight extends SelectedRowStyle from the cell under the
+	// cursor to its whole row
+	RowHighlight bool
+	// StatusBarStyle draws the status bar below every view
+	StatusBarStyle lipgloss.Style
+	// Light is set for themes made for terminals with a light background
+	Light bool
 }
 
 // DefaultTheme returns the default theme
@@ -29,6 +39,8 @@ func DefaultTheme() *Theme {
 		SearchStyle:      lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("220")),
 		SearchInputStyle: lipgloss.NewStyle().Background(lipgloss.Color("235")),
 		TableStyle:       "simple",
+		StripeStyle:      lipgloss.NewStyle().Background(lipgloss.Color("235")),
+		StatusBarStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Background(lipgloss.Color("237")),
 	}
 }
 
@@ -45,6 +57,10 @@ func DarkTheme() *Theme {
 		SearchStyle:      lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("226")),
 		SearchInputStyle: lipgloss.NewStyle().Background(lipgloss.Color("234")),
 		TableStyle:       "rounded",
+		StripeStyle:      lipgloss.NewStyle().Background(lipgloss.Color("234")),
+		StatusBarStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("250")).Background(lipgloss.Color("236")),
+		Zebra:            true,
+		RowHighlight:     true,
 	}
 }
 
@@ -61,6 +77,10 @@ func LightTheme() *Theme {
 		SearchStyle:      lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")),
 		SearchInputStyle: lipgloss.NewStyle().Background(lipgloss.Color("255")),
 		TableStyle:       "simple",
+		StripeStyle:      lipgloss.NewStyle().Background(lipgloss.Color("255")),
+		StatusBarStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("235")).Background(lipgloss.Color("252")),
+		Zebra:            true,
+		Light:            true,
 	}
 }
 
@@ -77,6 +97,29 @@ func MinimalTheme() *Theme {
 		SearchStyle:      lipgloss.NewStyle().Bold(true),
 		SearchInputStyle: lipgloss.NewStyle().Underline(true),
 		TableStyle:       "minimal",
+		StripeStyle:      lipgloss.NewStyle().Faint(true),
+		StatusBarStyle:   lipgloss.NewStyle().Reverse(true),
+	}
+}
+
+// HighContrastTheme returns a theme of bright colors of the 16 base
+// terminal colors on the terminal background, marking highlights and the
+// cursor by more than color alone
+func HighContrastTheme() *Theme {
+	return &Theme{
+		Name:             "high-contrast",
+		HeaderStyle:      lipgloss.NewStyle().Bold(true).Underline(true).Foreground(lipgloss.Color("15")),
+		CellStyle:        lipgloss.NewStyle().Foreground(lipgloss.Color("15")),
+		HighlightStyle:   lipgloss.NewStyle().Bold(true).Underline(true).Foreground(lipgloss.Color("11")),
+		BorderColor:      lipgloss.Color("15"),
+		SelectedRowStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("11")),
+		CategoryStyle:    lipgloss.NewStyle().Bold(true).Underline(true).Foreground(lipgloss.Color("14")),
+		SearchStyle:      lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		SearchInputStyle: lipgloss.NewStyle().Underline(true).Foreground(lipgloss.Color("15")),
+		TableStyle:       "simple",
+		StripeStyle:      lipgloss.NewStyle(),
+		StatusBarStyle:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("15")),
+		RowHighlight:     true,
 	}
 }
 
@@ -89,6 +132,8 @@ func GetTheme(name string) *Theme {
 		return LightTheme()
 	case "minimal":
 		return MinimalTheme()
+	case "high-contrast":
+		return HighContrastTheme()
 	default:
 		return DefaultTheme()
 	}
@@ -96,5 +141,5 @@ func GetTheme(name string) *Theme {
 
 // GetAvailableThemes returns a list of all available theme names
 func GetAvailableThemes() []string {
-	return []string{"default", "dark", "light", "minimal"}
+	return []string{"default", "dark", "light", "minimal", "high-contrast"}
 }
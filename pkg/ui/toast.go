@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// ToastLevel tells how much a toast matters, and so how long it stays
+type ToastLevel int
+
+const (
+	ToastInfo ToastLevel = iota
+	ToastWarn
+	ToastError
+)
+
+// maxToasts is how many toasts are shown at once; older ones wait their turn
+// to expire below the fold
+const maxToasts = 3
+
+// toastTimeouts is how long a toast of each level stays on screen
+var toastTimeouts = map[ToastLevel]time.Duration{
+	ToastInfo:  4 * time.Second,
+	ToastWarn:  6 * time.Second,
+	ToastError: 10 * time.Second,
+}
+
+// toastStyles draw the toasts of each level, whatever the theme
+var toastStyles = map[ToastLevel]lipgloss.Style{
+	ToastInfo:  lipgloss.NewStyle().Foreground(lipgloss.Color("39")),
+	ToastWarn:  lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	ToastError: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")),
+}
+
+// toastIcons mark the level of a toast for terminals without colors
+var toastIcons = map[ToastLevel]string{
+	ToastInfo:  "ℹ",
+	ToastWarn:  "⚠",
+	ToastError: "✖",
+}
+
+// Toast is a transient notification shown above the status bar
+type Toast struct {
+	ID    int
+	Level ToastLevel
+	Text  string
+}
+
+// toastExpiredMsg tells Update the toast with the ID has timed out
+type toastExpiredMsg struct {
+	id int
+}
+
+// notify queues a toast and returns the command dismissing it once its
+// level's timeout has passed
+func (m *Model) notify(level ToastLevel, format string, args ...interface{}) tea.Cmd {
+	m.toastSeq++
+	id := m.toastSeq
+	m.Toasts = append(m.Toasts, Toast{ID: id, Level: level, Text: fmt.Sprintf(format, args...)})
+	return tea.Tick(toastTimeouts[level], func(time.Time) tea.Msg {
+		return toastExpiredMsg{id: id}
+	})
+}
+
+// dismissToast removes the toast with the ID from the queue
+func (m *Model) dismissToast(id int) {
+	for i, toast := range m.Toasts {
+		if toast.ID == id {
+			m.Toasts = append(m.Toasts[:i:i], m.Toasts[i+1:]...)
+			return
+		}
+	}
+}
+
+// viewToasts renders the newest toasts, one per line, above the status bar
+func (m Model) viewToasts() string {
+	if len(m.Toasts) == 0 {
+		return ""
+	}
+
+	var output strings.Builder
+	output.WriteString("\n")
+	shown := m.Toasts
+	if len(shown) > maxToasts {
+		output.WriteString(fmt.Sprintf("  … %d more\n", len(shown)-maxToasts))
+		shown = shown[len(shown)-maxToasts:]
+	}
+	for _, toast := range shown {
+		line := fmt.Sprintf(" %s %s", toastIcons[toast.Level], toast.Text)
+		if m.Width > 0 {
+			line = runewidth.Truncate(line, m.Width, "…")
+		}
+		output.WriteString(toastStyles[toast.Level].Render(line) + "\n")
+	}
+	return output.String()
+}
@@ -1,22 +1,140 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"time"
 
-	"cheat-go/pkg/notes"
-	"cheat-go/pkg/online"
-	"cheat-go/pkg/sync"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/daemon"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+	"github.com/remuscazacu/cheat-go/pkg/sync"
 )
 
 func (m *Model) LoadNotes() {
 	notes, _ := m.NotesManager.ListNotes()
-	m.NotesList = notes
+	m.setNotes(notes)
+}
+
+// setNotes lists notes in the notes view and marks the shortcuts they are
+// attached to. Notes of the project cheat-go runs in come first.
+func (m *Model) setNotes(list []*notes.Note) {
+	m.NotesList = list
+	if m.Config != nil && m.Config.Project() != nil {
+		// a copy, as the links need the notes newest first
+		tags := m.Config.Project().NoteTags
+		m.NotesList = append([]*notes.Note(nil), list...)
+		sort.SliceStable(m.NotesList, func(i, j int) bool {
+			return hasAnyTag(m.NotesList[i].Tags, tags) && !hasAnyTag(m.NotesList[j].Tags, tags)
+		})
+	}
 	m.NoteCursor = 0
+	m.setNoteLinks(list)
+}
+
+// noteTags are the tags of the notes created in the TUI
+func (m Model) noteTags() []string {
+	if m.Config == nil {
+		return nil
+	}
+	return m.Config.Notes.Tags
+}
+
+// hasAnyTag reports whether tags holds one of wanted
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LoadTrash refreshes the list of deleted notes
+func (m *Model) LoadTrash() {
+	m.TrashList = nil
+	m.TrashCursor = 0
+	if trash, ok := m.NotesManager.(notes.TrashManager); ok {
+		m.TrashList, _ = trash.ListTrash()
+	}
+}
+
+// LoadNoteLinks refreshes which table cells have an attached note
+func (m *Model) LoadNoteLinks() {
+	if m.NotesManager == nil {
+		return
+	}
+	notes, _ := m.NotesManager.ListNotes()
+	m.setNoteLinks(notes)
+}
+
+// setNoteLinks maps each shortcut cell to the newest note attached to it;
+// notes are listed newest first
+func (m *Model) setNoteLinks(list []*notes.Note) {
+	m.NoteLinks = make(map[string]string)
+	for _, note := range list {
+		if note.ShortcutKeys == "" || note.AppName == "" {
+			continue
+		}
+		key := noteLinkKey(note.AppName, note.ShortcutKeys)
+		if _, ok := m.NoteLinks[key]; !ok {
+			m.NoteLinks[key] = note.ID
+		}
+	}
+}
+
+// noteLinkKey identifies the table cell of a shortcut
+func noteLinkKey(app, keys string) string {
+	return app + "\x00" + keys
+}
+
+// SelectedShortcut returns the app and keys of the table cell under the
+// cursor, if it holds a shortcut
+func (m Model) SelectedShortcut() (string, string, bool) {
+	app := m.SelectedApp()
+	if app == "" || m.CursorY < 1 || m.CursorY >= len(m.Rows) || m.CursorX >= len(m.Rows[m.CursorY]) {
+		return "", "", false
+	}
+	row := m.Rows[m.CursorY]
+	if row[m.CursorX] == "-" || row[m.CursorX] == "" {
+		return "", "", false
+	}
+	return app, row[0], true
+}
+
+// LoadTemplates loads the built-in and user note templates
+func (m *Model) LoadTemplates() {
+	m.TemplateCursor = 0
+	if m.Config == nil {
+		m.Templates = notes.BuiltinTemplates()
+		return
+	}
+	templates, err := notes.LoadTemplates(m.Config.TemplatesDir())
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error loading templates: %v", err)
+	}
+	m.Templates = templates
+}
+
+// SelectedApp returns the app of the table column under the cursor, if any
+func (m Model) SelectedApp() string {
+	if len(m.Rows) == 0 || m.CursorX < 1 || m.CursorX >= len(m.Rows[0]) {
+		return ""
+	}
+	app, _ := apps.ColumnApp(m.Rows[0][m.CursorX])
+	return app
 }
 
 func (m *Model) LoadPlugins() {
@@ -25,21 +143,234 @@ func (m *Model) LoadPlugins() {
 }
 
 func (m *Model) LoadRepositories() {
-	repos, _ := m.OnlineClient.GetRepositories()
+	m.setRepositories(m.OnlineClient.GetRepositories())
+}
+
+// setRepositories lists the repositories of the online view
+func (m *Model) setRepositories(repos []online.Repository, err error) {
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error loading repositories: %v", err)
+	}
 	m.ReposList = repos
 	m.RepoCursor = 0
 }
 
 func (m *Model) LoadCheatSheets(repoURL string) {
-	sheets, _ := m.OnlineClient.SearchCheatSheets(online.SearchOptions{
+	m.setCheatSheets(m.OnlineClient.SearchCheatSheets(sheetSearch(repoURL)))
+}
+
+// sheetSearch asks for the sheets of a repository
+func sheetSearch(repoURL string) online.SearchOptions {
+	return online.SearchOptions{
 		Repository: repoURL,
 		Limit:      50,
-	})
+	}
+}
+
+// setCheatSheets lists the sheets of a repository, focusing the list
+func (m *Model) setCheatSheets(sheets []online.CheatSheet, err error) {
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error loading cheat sheets: %v", err)
+	}
+	if m.SheetSort != "" {
+		online.SortCheatSheets(sheets, m.SheetSort)
+	}
 	m.CheatSheets = sheets
 	m.SheetCursor = 0
+	m.SheetFocus = len(sheets) > 0
+	m.SheetPreview = nil
+}
+
+// CycleSheetSort switches the sheet list to the next sort order, keeping the
+// cursor on the selected sheet
+func (m *Model) CycleSheetSort() {
+	next := online.SheetSortOrders[0]
+	for i, order := range online.SheetSortOrders {
+		if order == m.SheetSort {
+			next = online.SheetSortOrders[(i+1)%len(online.SheetSortOrders)]
+		}
+	}
+	m.SheetSort = next
+
+	selected := ""
+	if m.SheetCursor < len(m.CheatSheets) {
+		selected = m.CheatSheets[m.SheetCursor].ID
+	}
+	online.SortCheatSheets(m.CheatSheets, next)
+	for i, sheet := range m.CheatSheets {
+		if sheet.ID == selected {
+			m.SheetCursor = i
+		}
+	}
+	m.StatusMessage = fmt.Sprintf("Sorted by %s", next)
+}
+
+// rateSheet submits a rating and fetches the sheet again for its new
+// average; the sheet is nil when only fetching it failed
+func rateSheet(client online.Client, id string, rating float64) (*online.CheatSheet, error) {
+	if err := client.RateCheatSheet(id, rating); err != nil {
+		return nil, err
+	}
+	updated, _ := client.GetCheatSheet(id)
+	return updated, nil
+}
+
+// setSheetRating reports a rating and shows the new average of the sheet
+func (m *Model) setSheetRating(sheet online.CheatSheet, rating float64, updated *online.CheatSheet, err error) {
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error rating %s: %v", sheet.Name, err)
+		return
+	}
+	for i := range m.CheatSheets {
+		if m.CheatSheets[i].ID == sheet.ID && updated != nil {
+			m.CheatSheets[i].Rating = updated.Rating
+			m.CheatSheets[i].Downloads = updated.Downloads
+		}
+	}
+	m.StatusMessage = fmt.Sprintf("Rated %s %.0f/5", sheet.Name, rating)
+}
+
+// setSheetPreview shows a sheet in the preview pane
+func (m *Model) setSheetPreview(sheet *online.CheatSheet, err error) {
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error loading preview: %v", err)
+		m.SheetPreview = nil
+		return
+	}
+	m.SheetPreview = sheet
+}
+
+// installSheet validates a downloaded app, saves it to the data directory,
+// adds it to the configured apps and refreshes the table, reporting the
+// outcome as a toast. Apps that could not be verified wait for the user
+// to confirm them.
+func (m *Model) installSheet(sheet online.CheatSheet, app *apps.App, err error) tea.Cmd {
+	if app != nil && signature.Overridable(err) {
+		return m.holdUnverified(sheet, app, err)
+	}
+	if err != nil {
+		return m.notify(ToastError, "Error downloading %s: %v", sheet.Name, err)
+	}
+
+	if err := m.Registry.ValidateApp(app); err != nil {
+		return m.notify(ToastError, "Invalid cheat sheet %s: %v", sheet.Name, err)
+	}
+
+	if err := m.Registry.SaveApp(app); err != nil {
+		return m.notify(ToastError, "Error saving %s: %v", sheet.Name, err)
+	}
+	if err := m.recordInstalled(sheet, app.Name); err != nil {
+		m.StatusMessage = fmt.Sprintf("Error recording the download of %s: %v", sheet.Name, err)
+	}
+
+	if m.IsAppConfigured(app.Name) {
+		m.RefreshTable()
+		return m.notify(ToastInfo, "Updated %s", app.Name)
+	}
+
+	m.Config.Apps = append(m.Config.Apps, app.Name)
+	m.AllApps = m.Config.Apps
+	m.RefreshTable()
+
+	if err := m.SaveConfig(); err != nil {
+		return m.notify(ToastWarn, "Installed %s, but saving config failed: %v", app.Name, err)
+	}
+
+	return m.notify(ToastInfo, "Installed %s", app.Name)
+}
+
+// IsAppConfigured reports whether an app is part of the configured apps
+func (m Model) IsAppConfigured(appName string) bool {
+	for _, name := range m.Config.Apps {
+		if name == appName {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshTable rebuilds the table rows from the configured apps
+func (m *Model) RefreshTable() {
+	m.Rows = m.Registry.GetTableData(m.Config.Apps)
+	m.AllRows = m.Rows
+	m.FilteredRows = m.Rows
+}
+
+// applyConfig switches the model to cfg, reloading the apps of the table
+// and the renderer. The error reports apps that could not be loaded.
+func (m *Model) applyConfig(cfg *config.Config) error {
+	m.Config = cfg
+	m.Registry.SetColumns(cfg.Layout.Columns)
+	m.Registry.SetShowCategories(cfg.Layout.ShowCategories)
+	m.Registry.SetAliases(cfg.Aliases)
+	m.Registry.SetMergedApps(cfg.Merged)
+	err := m.Registry.LoadApps(cfg.Apps)
+	m.AllApps = cfg.Apps
+	m.FilteredApps = []string{}
+	m.RefreshTable()
+	m.applyTableFilters()
+
+	renderer := NewConfiguredRenderer(cfg)
+	renderer.SetTerminalWidth(m.Width)
+	m.Renderer = renderer
+	return err
+}
+
+// NewConfiguredRenderer creates a table renderer with the theme and layout
+// of cfg
+func NewConfiguredRenderer(cfg *config.Config) *TableRenderer {
+	theme := GetTheme(cfg.Theme)
+	if cfg.Accessibility.HighContrast {
+		theme = HighContrastTheme()
+	}
+	renderer := NewTableRenderer(theme)
+	renderer.SetTableStyle(cfg.Layout.TableStyle)
+	renderer.SetMaxWidth(cfg.Layout.MaxWidth)
+	renderer.SetWrap(cfg.Layout.Wrap)
+	if cfg.Layout.Zebra != nil {
+		renderer.SetZebra(*cfg.Layout.Zebra)
+	}
+	if cfg.Layout.RowHighlight != nil {
+		renderer.SetRowHighlight(*cfg.Layout.RowHighlight)
+	}
+	renderer.SetKeyNotation(cfg.Layout.KeyNotation)
+	return renderer
+}
+
+// SaveConfig writes the current configuration back to its file
+func (m *Model) SaveConfig() error {
+	if m.ConfigLoader == nil {
+		return nil
+	}
+	return m.ConfigLoader.Save(m.Config, m.ConfigLoader.Path())
+}
+
+// LoadDevices asks the sync server for the devices syncing the same data
+func (m *Model) LoadDevices() {
+	m.DevicesList = nil
+	m.DeviceCursor = 0
+	m.DeviceRevoke = ""
+	if m.SyncManager == nil {
+		return
+	}
+	m.setDevices(m.SyncManager.Devices(context.Background()))
+}
+
+// setDevices lists the devices of the device screen
+func (m *Model) setDevices(devices []sync.Device, err error) {
+	m.DevicesList = nil
+	m.DeviceCursor = 0
+	m.DeviceRevoke = ""
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error listing devices: %v", err)
+		return
+	}
+	m.DevicesList = devices
 }
 
-func (m *Model) LoadSyncStatus() {
+// LoadSyncStatus reads the status of the sync manager, and asks a daemon of
+// the same data directory for its own in the background
+func (m *Model) LoadSyncStatus() tea.Cmd {
 	if m.SyncManager != nil {
 		m.SyncStatus = m.SyncManager.GetSyncStatus()
 	} else {
@@ -49,6 +380,61 @@ func (m *Model) LoadSyncStatus() {
 			DeviceID:  "not-configured",
 		}
 	}
+
+	// a daemon of the same data directory may be syncing in the background
+	if m.Config == nil {
+		m.DaemonStatus = nil
+		return nil
+	}
+	client := daemon.NewClient(m.Config.DaemonSocket())
+	return m.startTask("asking the daemon", func(context.Context) tea.Msg {
+		status, _ := client.Status()
+		return daemonStatusMsg{status: status}
+	})
+}
+
+// activeApps returns the apps shown in the table: the filtered apps, or all
+// apps when none are filtered
+func (m Model) activeApps() []string {
+	if len(m.FilteredApps) > 0 {
+		return m.FilteredApps
+	}
+	return m.AllApps
+}
+
+// applyTableFilters rebuilds the table from the app filter, the last search
+// and the selected tags, in the order of TableSort
+func (m *Model) applyTableFilters() {
+	if len(m.FilteredApps) == 0 && m.LastSearch == "" && len(m.SelectedTags) == 0 {
+		m.Rows = m.AllRows
+	} else {
+		m.Rows = m.Registry.FilterTableData(m.activeApps(), m.LastSearch, m.SelectedTags)
+	}
+	m.Rows = sortRows(m.Rows, m.TableSort)
+	m.firstRow()
+}
+
+// FilterTable shows the shortcuts of appNames matching query, as the app
+// filter and a search would. Apps that are not configured are left out and
+// reported in the error.
+func (m *Model) FilterTable(appNames []string, query string) error {
+	var unknown []string
+	m.FilteredApps = []string{}
+	for _, name := range appNames {
+		if m.IsAppConfigured(name) {
+			m.FilteredApps = append(m.FilteredApps, name)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	m.LastSearch = query
+	m.SearchQuery = ""
+	m.applyTableFilters()
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: %s is not configured", apps.ErrAppNotFound, strings.Join(unknown, ", "))
+	}
+	return nil
 }
 
 func (m Model) FilterRowsBySearch(query string) [][]string {
@@ -120,11 +506,15 @@ func (m Model) OpenEditorForNote(note *notes.Note) (*notes.Note, error) {
 
 	lines := strings.Split(string(editedContent), "\n")
 	updatedNote := &notes.Note{
-		ID:       note.ID,
-		Title:    note.Title,
-		Category: note.Category,
-		Tags:     note.Tags,
-		Content:  note.Content,
+		ID:           note.ID,
+		Title:        note.Title,
+		AppName:      note.AppName,
+		Category:     note.Category,
+		Tags:         note.Tags,
+		Content:      note.Content,
+		IsFavorite:   note.IsFavorite,
+		Shortcuts:    note.Shortcuts,
+		ShortcutKeys: note.ShortcutKeys,
 	}
 
 	var contentStart int
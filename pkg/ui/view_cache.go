@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/cache"
+	"github.com/remuscazacu/cheat-go/pkg/maintenance"
+)
+
+// minCacheTTL is the shortest TTL that can be set from the cache view
+const minCacheTTL = time.Minute
+
+// cacheLayer is a row of the cache view
+type cacheLayer struct {
+	name  string
+	stats cache.CacheStats
+	ttl   time.Duration
+}
+
+// cacheLayers returns the statistics of every layer of the model's cache
+func (m Model) cacheLayers() []cacheLayer {
+	switch c := m.Cache.(type) {
+	case nil:
+		return nil
+	case *cache.MultiLevelCache:
+		return []cacheLayer{
+			{name: "memory", stats: c.MemoryStats(), ttl: c.MemoryTTL()},
+			{name: "file", stats: c.FileStats(), ttl: c.FileTTL()},
+		}
+	case *cache.FileCache:
+		return []cacheLayer{{name: "file", stats: c.Stats(), ttl: c.TTL()}}
+	default:
+		return []cacheLayer{{name: "memory", stats: c.Stats()}}
+	}
+}
+
+func (m Model) ViewCache() string {
+	var output strings.Builder
+
+	output.WriteString("╭─ Cache ──────────────────────────────────────────────────╮\n")
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, ""), 58)))
+	}
+
+	layers := m.cacheLayers()
+	if len(layers) == 0 {
+		output.WriteString("│  Caching is not available.                               │\n")
+	} else {
+		writeLine(fmt.Sprintf("  %-7s %6s %6s %5s %5s %8s %8s", "Layer", "Hits", "Misses", "Evict", "Items", "Size", "TTL"))
+		for _, layer := range layers {
+			ttl := "-"
+			if layer.ttl > 0 {
+				ttl = layer.ttl.String()
+			}
+			writeLine(fmt.Sprintf("  %-7s %6d %6d %5d %5d %8s %8s", layer.name, layer.stats.Hits, layer.stats.Misses,
+				layer.stats.Evictions, layer.stats.Items, maintenance.FormatBytes(layer.stats.Size), ttl))
+		}
+		writeLine("")
+		for _, layer := range layers {
+			lastClean := "never"
+			if !layer.stats.LastClean.IsZero() {
+				lastClean = layer.stats.LastClean.Format("2006-01-02 15:04:05")
+			}
+			writeLine(fmt.Sprintf("  Last %s clean: %s", layer.name, lastClean))
+		}
+	}
+	if m.Registry != nil {
+		stats := m.Registry.LoadStats()
+		writeLine("")
+		writeLine(fmt.Sprintf("  Apps: %d registered, %d with shortcuts loaded", stats.Apps, stats.Loaded))
+		writeLine(fmt.Sprintf("  App index: %d from index, %d decoded in %s", stats.Indexed, stats.Decoded,
+			stats.Duration.Round(time.Microsecond)))
+	}
+
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\n" + m.hintBar() + "\n")
+
+	return output.String()
+}
+
+func (m Model) HandleCacheInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.ViewMode = ViewMain
+		return m, nil
+	case "m":
+		m.clearCache(true, false)
+		return m, nil
+	case "d":
+		m.clearCache(false, true)
+		return m, nil
+	case "c":
+		m.clearCache(true, true)
+		return m, nil
+	case "[", "]", "-", "+", "=":
+		m.tuneCacheTTL(msg.String())
+		return m, nil
+	}
+	return m, nil
+}
+
+// clearCache empties the selected layers of the model's cache
+func (m *Model) clearCache(memory, file bool) {
+	var err error
+	switch c := m.Cache.(type) {
+	case nil:
+		m.StatusMessage = "Caching is not available"
+		return
+	case *cache.MultiLevelCache:
+		if memory {
+			err = c.ClearMemory()
+		}
+		if file && err == nil {
+			err = c.ClearFile()
+		}
+	default:
+		err = c.Clear()
+	}
+
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error clearing cache: %v", err)
+		return
+	}
+	switch {
+	case memory && file:
+		m.StatusMessage = "Cleared memory and file cache"
+	case memory:
+		m.StatusMessage = "Cleared memory cache"
+	default:
+		m.StatusMessage = "Cleared file cache"
+	}
+}
+
+// tuneCacheTTL halves or doubles a layer's TTL: [ and ] change the memory
+// layer, - and + the file layer
+func (m *Model) tuneCacheTTL(key string) {
+	c, ok := m.Cache.(*cache.MultiLevelCache)
+	if !ok {
+		m.StatusMessage = "TTLs can only be tuned for the memory and file cache"
+		return
+	}
+
+	scale := func(ttl time.Duration) time.Duration {
+		if key == "[" || key == "-" {
+			ttl /= 2
+		} else {
+			ttl *= 2
+		}
+		if ttl < minCacheTTL {
+			ttl = minCacheTTL
+		}
+		return ttl
+	}
+
+	if key == "[" || key == "]" {
+		c.SetMemoryTTL(scale(c.MemoryTTL()))
+		m.StatusMessage = fmt.Sprintf("Memory TTL set to %s", c.MemoryTTL())
+		return
+	}
+	c.SetFileTTL(scale(c.FileTTL()))
+	m.StatusMessage = fmt.Sprintf("File TTL set to %s", c.FileTTL())
+}
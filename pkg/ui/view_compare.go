@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+)
+
+// compareLines is how many lines of the comparison the compare view shows
+const compareLines = 14
+
+// sheetComparison compares an online sheet with the local app it was
+// installed as
+type sheetComparison struct {
+	sheet     online.CheatSheet
+	app       string
+	community *apps.App
+	apps.Comparison
+}
+
+// sheetComparedMsg carries the community definition of a sheet to compare
+// with the local app
+type sheetComparedMsg struct {
+	sheet online.CheatSheet
+	app   *apps.App
+	err   error
+}
+
+// compareSheet downloads the sheet under the cursor in the background to
+// compare it with the local app
+func (m *Model) compareSheet() tea.Cmd {
+	if !m.SheetFocus || m.SheetCursor >= len(m.CheatSheets) {
+		return nil
+	}
+	verifier, err := m.verifier()
+	if err != nil {
+		return m.notify(ToastError, "Error reading the trusted keys: %v", err)
+	}
+	client, sheet := m.OnlineClient, m.CheatSheets[m.SheetCursor]
+	return m.startTask("comparing "+sheet.Name, func(ctx context.Context) tea.Msg {
+		// content that cannot be verified is not compared, let alone merged
+		app, err := online.DownloadVerified(online.WithContext(ctx, client), verifier, sheet.ID)
+		return sheetComparedMsg{sheet: sheet, app: app, err: err}
+	})
+}
+
+// localApp returns the name of the local app of sheet: the app it was
+// downloaded as, or else the app named like it
+func (m Model) localApp(sheet online.CheatSheet, community *apps.App) (string, bool) {
+	installed, _ := m.installedSheets()
+	if i := slices.IndexFunc(installed, func(s online.InstalledSheet) bool { return s.ID == sheet.ID }); i >= 0 {
+		if _, ok := m.Registry.Get(installed[i].App); ok {
+			return installed[i].App, true
+		}
+	}
+	for _, name := range []string{community.Name, sheet.Name} {
+		if _, ok := m.Registry.Get(name); ok && name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// setSheetComparison opens the compare view on the sheet compared with its
+// local app
+func (m *Model) setSheetComparison(msg sheetComparedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.notify(ToastError, "Error comparing %s: %v", msg.sheet.Name, msg.err)
+	}
+	name, ok := m.localApp(msg.sheet, msg.app)
+	if !ok {
+		return m.notify(ToastInfo, "%s is not installed; press %s to download it", msg.sheet.Name, m.boundKey("online.download"))
+	}
+	local, _ := m.Registry.Get(name)
+	m.Comparison = &sheetComparison{sheet: msg.sheet, app: name, community: msg.app, Comparison: apps.Compare(local, msg.app)}
+	m.CompareCursor = 0
+	m.ViewMode = ViewCompare
+	return nil
+}
+
+// mergeMissing adds the shortcuts of the community sheet missing from the
+// local app to the overlay of the app
+func (m *Model) mergeMissing() tea.Cmd {
+	comparison := m.Comparison
+	if comparison == nil || len(comparison.Missing) == 0 {
+		return m.notify(ToastInfo, "Nothing to merge")
+	}
+	if err := m.Registry.AddToOverlay(comparison.app, comparison.Missing); err != nil {
+		return m.notify(ToastError, "Error merging into %s: %v", comparison.app, err)
+	}
+
+	if local, ok := m.Registry.Get(comparison.app); ok {
+		merged := *comparison
+		merged.Comparison = apps.Compare(local, comparison.community)
+		m.Comparison = &merged
+	}
+	m.CompareCursor = 0
+	m.RefreshTable()
+	return m.notify(ToastInfo, "Merged the missing shortcuts of %s into %s%s", comparison.sheet.Name, comparison.app, apps.OverlaySuffix)
+}
+
+// compareReport lists the lines of the comparison below its summary
+func (c *sheetComparison) compareReport() []string {
+	var lines []string
+	section := func(title string, shortcuts []apps.Shortcut) {
+		lines = append(lines, fmt.Sprintf("  %s (%d)", title, len(shortcuts)))
+		for _, shortcut := range shortcuts {
+			keys := runewidth.FillRight(runewidth.Truncate(shortcut.Keys, 16, "…"), 16)
+			lines = append(lines, "    "+keys+" "+shortcut.Description)
+		}
+		if len(shortcuts) == 0 {
+			lines = append(lines, "    none")
+		}
+	}
+	section("Missing from "+c.app, c.Missing)
+	lines = append(lines, "")
+	section("Only in "+c.app, c.Extra)
+	return lines
+}
+
+func (m Model) HandleCompareInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.ViewMode = ViewOnline
+		return m, nil
+	case "up", "k":
+		if m.CompareCursor > 0 {
+			m.CompareCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.Comparison != nil && m.CompareCursor < len(m.Comparison.compareReport())-compareLines {
+			m.CompareCursor++
+		}
+		return m, nil
+	case "m":
+		cmd := m.mergeMissing()
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) ViewCompare() string {
+	var output strings.Builder
+
+	output.WriteString("╭─ Coverage ───────────────────────────────────────────────╮\n")
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+
+	if c := m.Comparison; c == nil {
+		writeLine(fmt.Sprintf("  Press %s on a sheet in the online browser to compare it.", m.boundKey("online.compare")))
+	} else {
+		writeLine(fmt.Sprintf("  %s compared with %s", c.sheet.Name, c.app))
+		writeLine(fmt.Sprintf("  Coverage: %d of %d shortcuts (%.0f%%)", c.Shared, c.Shared+len(c.Missing), 100*c.Coverage()))
+		writeLine("")
+
+		lines := c.compareReport()
+		end := min(m.CompareCursor+compareLines, len(lines))
+		for _, line := range lines[m.CompareCursor:end] {
+			writeLine(line)
+		}
+		if len(lines) > compareLines {
+			writeLine(fmt.Sprintf("  lines %d-%d of %d", m.CompareCursor+1, end, len(lines)))
+		}
+	}
+
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\n" + m.hintBar() + "\n")
+
+	return output.String()
+}
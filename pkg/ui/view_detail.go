@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+)
+
+// detailStep is how many columns < and > resize the detail pane by
+const detailStep = 4
+
+// maxDetailNotes is how many related notes the detail pane lists
+const maxDetailNotes = 5
+
+// showDetail reports whether the detail pane is shown next to the table,
+// which it never is beside the linear list read by screen readers
+func (m Model) showDetail() bool {
+	return m.Config != nil && m.Config.Layout.DetailPane && !m.Config.Accessibility.Linear
+}
+
+// detailWidth returns the width of the detail pane, borders included
+func (m Model) detailWidth() int {
+	if m.Config == nil || m.Config.Layout.DetailWidth == 0 {
+		return config.DefaultDetailWidth
+	}
+	return m.Config.Layout.DetailWidth
+}
+
+// toggleDetail shows or hides the detail pane, remembering the choice
+func (m Model) toggleDetail() (tea.Model, tea.Cmd) {
+	m.Config.Layout.DetailPane = !m.Config.Layout.DetailPane
+	state := "hidden"
+	if m.Config.Layout.DetailPane {
+		state = "shown"
+	}
+	if err := m.SaveConfig(); err != nil {
+		m.StatusMessage = fmt.Sprintf("Detail pane %s, but saving config failed: %v", state, err)
+		return m, nil
+	}
+	m.StatusMessage = "Detail pane " + state
+	return m, nil
+}
+
+// resizeDetail widens the detail pane by delta columns, within the bounds
+// the config accepts, remembering the width
+func (m Model) resizeDetail(delta int) (tea.Model, tea.Cmd) {
+	if !m.showDetail() {
+		return m, nil
+	}
+	width := max(config.MinDetailWidth, min(m.detailWidth()+delta, config.MaxDetailWidth))
+	if width == m.detailWidth() {
+		return m, nil
+	}
+	m.Config.Layout.DetailWidth = width
+	if err := m.SaveConfig(); err != nil {
+		m.StatusMessage = fmt.Sprintf("Detail pane %d columns wide, but saving config failed: %v", width, err)
+		return m, nil
+	}
+	m.StatusMessage = fmt.Sprintf("Detail pane %d columns wide", width)
+	return m, nil
+}
+
+// selectedShortcutDetail returns the app and the shortcut of the table cell
+// under the cursor
+func (m Model) selectedShortcutDetail() (string, apps.Shortcut, bool) {
+	app, keys, ok := m.SelectedShortcut()
+	if !ok || m.Registry == nil {
+		return "", apps.Shortcut{}, false
+	}
+	loaded, exists := m.Registry.Get(app)
+	if !exists {
+		return "", apps.Shortcut{}, false
+	}
+	for _, shortcut := range loaded.Shortcuts {
+		if shortcut.Keys == keys {
+			return app, shortcut, true
+		}
+	}
+	return "", apps.Shortcut{}, false
+}
+
+// relatedNotes returns the notes attached to the shortcut, then the other
+// notes of its app
+func (m Model) relatedNotes(app, keys string) []*notes.Note {
+	if m.NotesManager == nil {
+		return nil
+	}
+	list, err := m.NotesManager.ListNotes()
+	if err != nil {
+		return nil
+	}
+	var attached, others []*notes.Note
+	for _, note := range list {
+		switch {
+		case note.AppName == app && note.ShortcutKeys == keys:
+			attached = append(attached, note)
+		case note.AppName == app:
+			others = append(others, note)
+		}
+	}
+	related := append(attached, others...)
+	if len(related) > maxDetailNotes {
+		related = related[:maxDetailNotes]
+	}
+	return related
+}
+
+// viewDetail renders the detail pane: the full description, category, tags
+// and related notes of the shortcut under the cursor
+func (m Model) viewDetail() string {
+	var output strings.Builder
+
+	inner := m.detailWidth() - 2
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, inner, "…"), inner)))
+	}
+	writeWrapped := func(text string) {
+		for _, line := range wrapText(text, inner-2) {
+			writeLine("  " + line)
+		}
+	}
+
+	title := "─ Details "
+	output.WriteString("╭" + title + strings.Repeat("─", max(0, inner-runewidth.StringWidth(title))) + "╮\n")
+
+	app, shortcut, ok := m.selectedShortcutDetail()
+	if !ok {
+		writeLine("  Move the cursor to a")
+		writeLine("  shortcut to see its details.")
+	} else {
+		writeLine(fmt.Sprintf(" %s in %s", shortcut.Keys, app))
+		writeLine("")
+		writeWrapped(shortcut.Description)
+		writeLine("")
+		if shortcut.Category != "" {
+			writeLine(" Category: " + shortcut.Category)
+		}
+		if len(shortcut.Tags) > 0 {
+			writeLine(" Tags:")
+			writeWrapped(strings.Join(shortcut.Tags, ", "))
+		}
+		if shortcut.Platform != "" {
+			writeLine(" Platform: " + shortcut.Platform)
+		}
+		writeLine(" Notes:")
+		related := m.relatedNotes(app, shortcut.Keys)
+		if len(related) == 0 {
+			writeLine("  none; N attaches one")
+		}
+		for _, note := range related {
+			mark := "•"
+			if note.ShortcutKeys == shortcut.Keys {
+				mark = "✎"
+			}
+			writeLine(fmt.Sprintf("  %s %s", mark, note.Title))
+		}
+	}
+
+	output.WriteString("╰" + strings.Repeat("─", inner) + "╯")
+	return output.String()
+}
+
+// withDetail puts the detail pane to the right of the table, which is
+// narrowed to the rest of the terminal
+func (m Model) withDetail(table func(*TableRenderer) string) string {
+	renderer := m.Renderer
+	if m.Width > 0 {
+		renderer = renderer.WithTerminalWidth(max(1, m.Width-m.detailWidth()-1))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, table(renderer), " ", m.viewDetail())
+}
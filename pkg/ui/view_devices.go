@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// viewDevices lists the devices syncing the same data, or the prompt
+// naming this one
+func (m Model) viewDevices() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, ""), 58)))
+	}
+
+	output.WriteString("╭─ Devices ────────────────────────────────────────────────╮\n")
+	if m.DeviceNameMode {
+		writeLine("  Name this device; leave empty for the host name")
+		writeLine("")
+		writeLine("  > " + m.DeviceNameInput)
+		output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+		output.WriteString("\nKeys: enter: confirm • esc: cancel\n")
+	} else {
+		if len(m.DevicesList) == 0 {
+			writeLine("  No devices known; sync first.")
+		}
+		for i, device := range m.DevicesList {
+			cursor := "  "
+			if i == m.DeviceCursor {
+				cursor = "▶ "
+			}
+			name := device.Name
+			if name == "" {
+				name = "unnamed"
+			}
+			id := device.ID
+			if len(id) > 8 {
+				id = id[:8]
+			}
+			line := fmt.Sprintf("%s%s %-8s %s", cursor, runewidth.FillRight(runewidth.Truncate(name, 22, "…"), 22),
+				id, device.LastSeen.Format("2006-01-02 15:04"))
+			if device.Current {
+				line += " this"
+			}
+			writeLine(line)
+		}
+		output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+		output.WriteString("\n" + m.hintBar() + "\n")
+	}
+
+	return output.String()
+}
+
+func (m Model) handleDeviceInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.DeviceNameMode {
+		return m.handleDeviceNameInput(msg)
+	}
+
+	key := msg.String()
+	if key != "x" {
+		m.DeviceRevoke = ""
+	}
+
+	switch key {
+	case "esc", "q", "d":
+		m.DeviceMode = false
+		return m, nil
+	case "up", "k":
+		if m.DeviceCursor > 0 {
+			m.DeviceCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.DeviceCursor < len(m.DevicesList)-1 {
+			m.DeviceCursor++
+		}
+		return m, nil
+	case "r":
+		cmd := m.loadDevices()
+		return m, cmd
+	case "n":
+		m.DeviceNameMode = true
+		m.DeviceNameInput = m.SyncManager.DeviceName()
+		return m, nil
+	case "x":
+		if m.DeviceCursor >= len(m.DevicesList) {
+			return m, nil
+		}
+		device := m.DevicesList[m.DeviceCursor]
+		if device.Current {
+			m.StatusMessage = "This device cannot be revoked"
+			return m, nil
+		}
+		// revoking locks a device out, so it takes a second press
+		if m.DeviceRevoke != device.ID {
+			m.DeviceRevoke = device.ID
+			m.StatusMessage = fmt.Sprintf("Press x again to revoke '%s'", device.Name)
+			return m, nil
+		}
+		if err := m.SyncManager.RevokeDevice(device.ID); err != nil {
+			m.DeviceRevoke = ""
+			m.StatusMessage = fmt.Sprintf("Error revoking device: %v", err)
+			return m, nil
+		}
+		m.LoadDevices()
+		m.StatusMessage = fmt.Sprintf("Device '%s' revoked", device.Name)
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m Model) handleDeviceNameInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.DeviceNameMode = false
+		m.DeviceNameInput = ""
+		m.StatusMessage = "Cancelled"
+		return m, nil
+	case tea.KeyBackspace:
+		if runes := []rune(m.DeviceNameInput); len(runes) > 0 {
+			m.DeviceNameInput = string(runes[:len(runes)-1])
+		}
+		return m, nil
+	case tea.KeyCtrlU:
+		m.DeviceNameInput = ""
+		return m, nil
+	case tea.KeyRunes, tea.KeySpace:
+		m.DeviceNameInput += string(msg.Runes)
+		return m, nil
+	case tea.KeyEnter:
+		m.DeviceNameMode = false
+		if err := m.SyncManager.SetDeviceName(m.DeviceNameInput); err != nil {
+			m.StatusMessage = fmt.Sprintf("Error naming device: %v", err)
+			return m, nil
+		}
+		m.DeviceNameInput = ""
+		cmd := m.LoadSyncStatus()
+		m.StatusMessage = fmt.Sprintf("This device is now '%s'; others see it after the next sync", m.SyncStatus.DeviceName)
+		return m, cmd
+	}
+	return m, nil
+}
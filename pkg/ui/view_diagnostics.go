@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+)
+
+// diagnosticsLines is how many lines of problems are shown at once
+const diagnosticsLines = 16
+
+// diagnosedMsg carries the problems of the stored app files
+type diagnosedMsg struct {
+	diagnoses []apps.Diagnosis
+	err       error
+}
+
+// openDiagnostics checks the stored app files in the background
+func (m Model) openDiagnostics() (tea.Model, tea.Cmd) {
+	m.ViewMode = ViewDiagnostics
+	m.DiagnosticsScroll = 0
+	cmd := m.diagnose()
+	return m, cmd
+}
+
+// diagnose checks the stored app files against the app schema
+func (m *Model) diagnose() tea.Cmd {
+	registry := m.Registry
+	return m.startTask("checking app files", func(context.Context) tea.Msg {
+		diagnoses, err := registry.Diagnose()
+		return diagnosedMsg{diagnoses: diagnoses, err: err}
+	})
+}
+
+// setDiagnoses lists the problems found by diagnose
+func (m *Model) setDiagnoses(msg diagnosedMsg) {
+	m.Diagnoses, m.diagnoseErr = msg.diagnoses, msg.err
+	m.diagnosed = true
+	m.DiagnosticsScroll = 0
+}
+
+// diagnosticsBody returns the lines listing every file with problems
+func (m Model) diagnosticsBody() []string {
+	var lines []string
+	for _, diagnosis := range m.Diagnoses {
+		errors, warnings := 0, 0
+		for _, problem := range diagnosis.Problems {
+			if problem.Warning {
+				warnings++
+			} else {
+				errors++
+			}
+		}
+		mark := "⚠"
+		if diagnosis.Failing() {
+			mark = "✗"
+		}
+		lines = append(lines, fmt.Sprintf(" %s %s.yaml (%d errors, %d warnings)", mark, diagnosis.Key, errors, warnings))
+		for _, problem := range diagnosis.Problems {
+			kind := "error"
+			if problem.Warning {
+				kind = "warn "
+			}
+			lines = append(lines, "   "+kind+" "+problem.String())
+		}
+	}
+	return lines
+}
+
+func (m Model) ViewDiagnostics() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+
+	output.WriteString("╭─ App file diagnostics ───────────────────────────────────╮\n")
+	switch {
+	case !m.diagnosed:
+		writeLine("  Checking app files…")
+	case m.diagnoseErr != nil:
+		writeLine(fmt.Sprintf("  Error: %v", m.diagnoseErr))
+	case len(m.Diagnoses) == 0:
+		writeLine("  ✓ Every app file is valid.")
+	default:
+		failing := 0
+		for _, diagnosis := range m.Diagnoses {
+			if diagnosis.Failing() {
+				failing++
+			}
+		}
+		writeLine(fmt.Sprintf("  %d files with problems, %d not loading", len(m.Diagnoses), failing))
+		writeLine("")
+
+		body := m.diagnosticsBody()
+		start := min(m.DiagnosticsScroll, max(len(body)-diagnosticsLines, 0))
+		end := min(start+diagnosticsLines, len(body))
+		for _, line := range body[start:end] {
+			writeLine(line)
+		}
+		if len(body) > diagnosticsLines {
+			writeLine(fmt.Sprintf("  lines %d-%d of %d", start+1, end, len(body)))
+		}
+	}
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\n" + m.hintBar() + "\n")
+
+	return output.String()
+}
+
+// HandleDiagnosticsInput handles the keys of the diagnostics view
+func (m Model) HandleDiagnosticsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.ViewMode = ViewMain
+		return m, nil
+	case "up", "k":
+		if m.DiagnosticsScroll > 0 {
+			m.DiagnosticsScroll--
+		}
+		return m, nil
+	case "down", "j":
+		if m.DiagnosticsScroll < len(m.diagnosticsBody())-diagnosticsLines {
+			m.DiagnosticsScroll++
+		}
+		return m, nil
+	case "r":
+		m.diagnosed = false
+		cmd := m.diagnose()
+		return m, cmd
+	}
+	return m, nil
+}
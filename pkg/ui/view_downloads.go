@@ -0,0 +1,330 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+	"github.com/remuscazacu/cheat-go/pkg/signature"
+)
+
+// downloadItem is a row of the downloads view
+type downloadItem struct {
+	id    int
+	sheet online.CheatSheet
+	state online.DownloadState
+	err   error
+}
+
+// downloadEventMsg carries a change of a download back to Update; closed
+// is set once every download of the batch has ended
+type downloadEventMsg struct {
+	event  online.DownloadEvent
+	events <-chan online.DownloadEvent
+	closed bool
+}
+
+// repoSheetsMsg lists every sheet of a repository to download them all
+type repoSheetsMsg struct {
+	repo   online.Repository
+	sheets []online.CheatSheet
+	err    error
+}
+
+// waitForDownloads returns a command waiting for the next event of a batch
+// of downloads
+func waitForDownloads(events <-chan online.DownloadEvent) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		return downloadEventMsg{event: event, events: events, closed: !ok}
+	}
+}
+
+// downloadManager returns the manager running the downloads, created with
+// the workers of the config on first use
+func (m *Model) downloadManager() *online.DownloadManager {
+	if m.downloader == nil {
+		workers := 0
+		if m.Config != nil {
+			workers = m.Config.Online.DownloadWorkers
+		}
+		m.downloader = online.NewDownloadManager(workers)
+	}
+	return m.downloader
+}
+
+// activeDownloads counts the downloads that have not ended
+func (m Model) activeDownloads() int {
+	active := 0
+	for _, item := range m.Downloads {
+		if !item.state.Finished() {
+			active++
+		}
+	}
+	return active
+}
+
+// downloadsLabel describes the running downloads for the status bar, or ""
+// when none runs
+func (m Model) downloadsLabel() string {
+	switch active := m.activeDownloads(); active {
+	case 0:
+		return ""
+	case 1:
+		for _, item := range m.Downloads {
+			if !item.state.Finished() {
+				return "downloading " + item.sheet.Name
+			}
+		}
+	default:
+		return fmt.Sprintf("downloading %d sheets", active)
+	}
+	return ""
+}
+
+// startDownloads queues the sheets with the download manager, checking
+// their signatures when keys are trusted, and returns the IDs of their
+// downloads with the command following them
+func (m *Model) startDownloads(sheets []online.CheatSheet) ([]int, tea.Cmd) {
+	verifier, err := m.verifier()
+	if err != nil {
+		return nil, m.notify(ToastError, "Error reading the trusted keys: %v", err)
+	}
+	client := m.OnlineClient
+	ids, events := m.downloadManager().Start(sheets, func(ctx context.Context, sheet online.CheatSheet) (*apps.App, error) {
+		return online.DownloadVerified(online.WithContext(ctx, client), verifier, sheet.ID)
+	})
+
+	cmd := waitForDownloads(events)
+	if !m.spinning && !m.accessibility().ReducedMotion {
+		m.spinning = true
+		cmd = tea.Batch(cmd, spinnerTick())
+	}
+	return ids, cmd
+}
+
+// queueDownloads downloads the sheets in the background to install them,
+// listing them in the downloads view
+func (m *Model) queueDownloads(sheets []online.CheatSheet) tea.Cmd {
+	ids, cmd := m.startDownloads(sheets)
+	if ids == nil {
+		return cmd
+	}
+	m.Downloads = slices.Clone(m.Downloads)
+	for i, id := range ids {
+		m.Downloads = append(m.Downloads, downloadItem{id: id, sheet: sheets[i], state: online.DownloadQueued})
+	}
+	if len(sheets) > 1 {
+		return tea.Batch(cmd, m.notify(ToastInfo, "Downloading %d sheets; press %s to follow them", len(sheets), m.boundKey("online.downloads")))
+	}
+	return cmd
+}
+
+// handleDownloadEvent shows the change of a download and installs the
+// sheet once it is downloaded
+func (m Model) handleDownloadEvent(msg downloadEventMsg) (tea.Model, tea.Cmd) {
+	if msg.closed {
+		return m, nil
+	}
+	next := waitForDownloads(msg.events)
+	event := msg.event
+
+	i := slices.IndexFunc(m.Downloads, func(item downloadItem) bool { return item.id == event.ID })
+	if i < 0 {
+		return m, next
+	}
+	m.Downloads = slices.Clone(m.Downloads)
+	m.Downloads[i].state = event.State
+	m.Downloads[i].err = event.Err
+
+	switch event.State {
+	case online.DownloadDone, online.DownloadFailed:
+		cmd := m.installSheet(event.Sheet, event.App, event.Err)
+		return m, tea.Batch(cmd, next)
+	}
+	return m, next
+}
+
+// downloadRepository lists every sheet of the repository under the cursor
+// in the background to download them all
+func (m *Model) downloadRepository() tea.Cmd {
+	if m.RepoCursor >= len(m.ReposList) {
+		return nil
+	}
+	client, repo := m.OnlineClient, m.ReposList[m.RepoCursor]
+	return m.startTask("listing "+repo.Name, func(ctx context.Context) tea.Msg {
+		sheets, err := online.WithContext(ctx, client).SearchCheatSheets(online.SearchOptions{Repository: repo.URL})
+		return repoSheetsMsg{repo: repo, sheets: sheets, err: err}
+	})
+}
+
+// downloadRepoSheets downloads the sheets listed by downloadRepository
+func (m *Model) downloadRepoSheets(msg repoSheetsMsg) tea.Cmd {
+	switch {
+	case msg.err != nil:
+		return m.notify(ToastError, "Error listing the sheets of %s: %v", msg.repo.Name, msg.err)
+	case len(msg.sheets) == 0:
+		return m.notify(ToastInfo, "%s has no cheat sheets", msg.repo.Name)
+	}
+	return m.queueDownloads(msg.sheets)
+}
+
+// selectedDownload returns the download under the cursor of the downloads
+// view
+func (m Model) selectedDownload() (downloadItem, bool) {
+	if m.DownloadCursor >= len(m.Downloads) {
+		return downloadItem{}, false
+	}
+	return m.Downloads[m.DownloadCursor], true
+}
+
+// retryable reports whether a download can be started again
+func (item downloadItem) retryable() bool {
+	return item.state == online.DownloadCancelled || item.state == online.DownloadFailed && !signature.Overridable(item.err)
+}
+
+// retryDownloads starts the downloads at indexes again, in place
+func (m *Model) retryDownloads(indexes []int) tea.Cmd {
+	if len(indexes) == 0 {
+		return nil
+	}
+	sheets := make([]online.CheatSheet, len(indexes))
+	for i, index := range indexes {
+		sheets[i] = m.Downloads[index].sheet
+	}
+	ids, cmd := m.startDownloads(sheets)
+	if ids == nil {
+		return cmd
+	}
+	m.Downloads = slices.Clone(m.Downloads)
+	for i, index := range indexes {
+		m.Downloads[index] = downloadItem{id: ids[i], sheet: sheets[i], state: online.DownloadQueued}
+	}
+	return cmd
+}
+
+// clearFinishedDownloads drops the downloads that have ended from the list
+func (m *Model) clearFinishedDownloads() {
+	m.Downloads = slices.DeleteFunc(slices.Clone(m.Downloads), func(item downloadItem) bool {
+		return item.state.Finished()
+	})
+	m.DownloadCursor = min(m.DownloadCursor, max(len(m.Downloads)-1, 0))
+}
+
+func (m Model) HandleDownloadsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.ViewMode = ViewOnline
+		return m, nil
+	case "up", "k":
+		if m.DownloadCursor > 0 {
+			m.DownloadCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.DownloadCursor < len(m.Downloads)-1 {
+			m.DownloadCursor++
+		}
+		return m, nil
+	case "x":
+		if item, ok := m.selectedDownload(); ok && !item.state.Finished() {
+			m.downloadManager().Cancel(item.id)
+		}
+		return m, nil
+	case "r":
+		if item, ok := m.selectedDownload(); ok && item.retryable() {
+			cmd := m.retryDownloads([]int{m.DownloadCursor})
+			return m, cmd
+		}
+		return m, nil
+	case "R":
+		var failed []int
+		for i, item := range m.Downloads {
+			if item.retryable() {
+				failed = append(failed, i)
+			}
+		}
+		cmd := m.retryDownloads(failed)
+		return m, cmd
+	case "c":
+		m.clearFinishedDownloads()
+		return m, nil
+	}
+	return m, nil
+}
+
+// downloadStatus describes where a download stands in a few words
+func (m Model) downloadStatus(item downloadItem) string {
+	switch {
+	case item.state == online.DownloadRunning:
+		frame := spinnerFrames[m.spinnerFrame%len(spinnerFrames)]
+		if m.accessibility().ReducedMotion {
+			frame = stillSpinner
+		}
+		return frame + " downloading"
+	case item.state == online.DownloadDone:
+		return "✓ done"
+	case item.state == online.DownloadFailed && signature.Overridable(item.err):
+		return "⚠ unverified"
+	case item.state == online.DownloadFailed:
+		return "✗ " + item.err.Error()
+	case item.state == online.DownloadCancelled:
+		return "– cancelled"
+	}
+	return "· queued"
+}
+
+func (m Model) ViewDownloads() string {
+	var output strings.Builder
+
+	output.WriteString("╭─ Downloads ──────────────────────────────────────────────╮\n")
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+
+	if len(m.Downloads) == 0 {
+		writeLine("  No downloads. Press d in the online browser to start one.")
+	} else {
+		counts := map[online.DownloadState]int{}
+		for _, item := range m.Downloads {
+			counts[item.state]++
+		}
+		writeLine(fmt.Sprintf("  %d/%d done, %d failed, %d queued", counts[online.DownloadDone], len(m.Downloads),
+			counts[online.DownloadFailed], counts[online.DownloadQueued]))
+		writeLine("")
+
+		start := 0
+		if m.DownloadCursor >= 10 {
+			start = m.DownloadCursor - 9
+		}
+		end := min(start+10, len(m.Downloads))
+		for i := start; i < end; i++ {
+			item := m.Downloads[i]
+			cursor := "  "
+			if i == m.DownloadCursor {
+				cursor = "▶ "
+			}
+			name := runewidth.FillRight(runewidth.Truncate(item.sheet.Name, 24, "…"), 24)
+			writeLine(cursor + name + " " + m.downloadStatus(item))
+		}
+		if len(m.Downloads) > 10 {
+			writeLine(fmt.Sprintf("  %d/%d downloads", m.DownloadCursor+1, len(m.Downloads)))
+		}
+	}
+
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+
+	if item, ok := m.selectedDownload(); ok && item.state == online.DownloadFailed && item.err != nil {
+		output.WriteString(fmt.Sprintf("%s: %v\n", item.sheet.Name, item.err))
+	}
+	output.WriteString("\n" + m.hintBar() + "\n")
+
+	return output.String()
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tableExportFile names the file in the data directory the table is
+// exported to, with the extension of the format
+const tableExportFile = "table"
+
+// exportKeys maps the keys of the export picker to the format they choose
+var exportKeys = map[string]string{"m": "markdown", "h": "html", "p": "png"}
+
+// viewExport renders the export picker below the table
+func (m Model) viewExport() string {
+	return "\nExport the table as:\n" + m.hintBar() + "\n"
+}
+
+// HandleExportInput handles the keys of the export picker
+func (m Model) HandleExportInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+[", "E":
+		m.ExportMode = false
+		return m, nil
+	default:
+		format, ok := exportKeys[key]
+		if !ok {
+			return m, nil
+		}
+		m.ExportMode = false
+		cmd := m.exportTable(format)
+		return m, cmd
+	}
+}
+
+// exportTable writes the table as filtered on screen in format to the data
+// directory
+func (m *Model) exportTable(format string) tea.Cmd {
+	if m.Config == nil {
+		return m.notify(ToastError, "No data directory to export to")
+	}
+
+	data, err := m.Renderer.Export(m.Rows, format)
+	if err != nil {
+		return m.notify(ToastError, "Error exporting the table: %v", err)
+	}
+	path := filepath.Join(m.Config.BaseDir(), tableExportFile+ExportFormats[format])
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return m.notify(ToastError, "Error exporting the table: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return m.notify(ToastError, "Error exporting the table: %v", err)
+	}
+	return m.notify(ToastInfo, "Exported the table to %s", path)
+}
@@ -0,0 +1,287 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+)
+
+// findLimit is how many results of each kind the find view lists
+const findLimit = 8
+
+// findTickMsg runs the search of the find view, unless the query changed
+// again since the tick with seq was scheduled
+type findTickMsg struct {
+	seq int
+}
+
+// onlineFoundMsg carries the online sheets matching query
+type onlineFoundMsg struct {
+	query  string
+	sheets []online.CheatSheet
+	err    error
+}
+
+// openFind opens the search across shortcuts, notes and online sheets
+func (m Model) openFind() (tea.Model, tea.Cmd) {
+	m.ViewMode = ViewFind
+	m.FindQuery = ""
+	m.FindCursor = 0
+	m.FindShortcuts = nil
+	m.FindNotes = nil
+	m.FindSheets = nil
+	m.findOnlineErr = nil
+	return m, nil
+}
+
+// editFind changes the query and schedules the search once typing pauses
+func (m Model) editFind(query string) (tea.Model, tea.Cmd) {
+	m.FindQuery = query
+	m.findSeq++
+	seq := m.findSeq
+	return m, tea.Tick(searchDebounce, func(time.Time) tea.Msg {
+		return findTickMsg{seq: seq}
+	})
+}
+
+// runFind searches the shortcuts of the configured apps and the notes at
+// once, and the online sheets in the background
+func (m Model) runFind(msg findTickMsg) (tea.Model, tea.Cmd) {
+	if m.ViewMode != ViewFind || msg.seq != m.findSeq {
+		return m, nil
+	}
+	query := strings.TrimSpace(m.FindQuery)
+	m.FindCursor = 0
+	m.FindShortcuts, m.FindNotes, m.FindSheets, m.findOnlineErr = nil, nil, nil, nil
+	if query == "" {
+		return m, nil
+	}
+
+	m.FindShortcuts = m.findShortcuts(query)
+	if m.NotesManager != nil {
+		m.FindNotes, _ = m.NotesManager.SearchNotes(notes.SearchOptions{Query: query, Limit: findLimit})
+	}
+	if m.OnlineClient == nil {
+		return m, nil
+	}
+	client, typed := m.OnlineClient, m.FindQuery
+	cmd := m.startTask("searching online", func(ctx context.Context) tea.Msg {
+		sheets, err := online.WithContext(ctx, client).SearchCheatSheets(online.SearchOptions{Query: query, Limit: findLimit})
+		return onlineFoundMsg{query: typed, sheets: sheets, err: err}
+	})
+	return m, cmd
+}
+
+// findShortcuts returns the shortcuts of the configured apps matching
+// query, in the order of the apps
+func (m Model) findShortcuts(query string) []apps.ShortcutResult {
+	order := make(map[string]int, len(m.AllApps))
+	for i, app := range m.AllApps {
+		order[app] = i + 1
+	}
+	var found []apps.ShortcutResult
+	for _, result := range m.Registry.SearchShortcuts(query) {
+		if order[result.AppName] > 0 {
+			found = append(found, result)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].AppName != found[j].AppName {
+			return order[found[i].AppName] < order[found[j].AppName]
+		}
+		return found[i].Shortcut.Keys < found[j].Shortcut.Keys
+	})
+	if len(found) > findLimit {
+		found = found[:findLimit]
+	}
+	return found
+}
+
+// setOnlineFound lists the online sheets found for the current query
+func (m *Model) setOnlineFound(msg onlineFoundMsg) {
+	if msg.query != m.FindQuery {
+		return
+	}
+	m.FindSheets, m.findOnlineErr = msg.sheets, msg.err
+	if len(m.FindSheets) > findLimit {
+		m.FindSheets = m.FindSheets[:findLimit]
+	}
+}
+
+// findCount is how many results the find view lists
+func (m Model) findCount() int {
+	return len(m.FindShortcuts) + len(m.FindNotes) + len(m.FindSheets)
+}
+
+func (m Model) ViewFind() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+	i := 0
+	writeResult := func(line string) {
+		cursor := "  "
+		if i == m.FindCursor {
+			cursor = "▶ "
+		}
+		writeLine(cursor + line)
+		i++
+	}
+
+	output.WriteString("╭─ Find in apps, notes and online ─────────────────────────╮\n")
+	writeLine(" > " + m.FindQuery + "_")
+
+	if strings.TrimSpace(m.FindQuery) != "" {
+		writeLine("")
+		writeLine(fmt.Sprintf(" Shortcuts (%d)", len(m.FindShortcuts)))
+		for _, result := range m.FindShortcuts {
+			writeResult(fmt.Sprintf("%-10s %-12s %s", runewidth.Truncate(result.AppName, 10, "…"),
+				runewidth.Truncate(result.Shortcut.Keys, 12, "…"), result.Shortcut.Description))
+		}
+		writeLine(fmt.Sprintf(" Notes (%d)", len(m.FindNotes)))
+		for _, note := range m.FindNotes {
+			line := note.Title
+			if note.AppName != "" {
+				line += " (" + note.AppName + ")"
+			}
+			writeResult(line)
+		}
+		writeLine(fmt.Sprintf(" Online (%d)", len(m.FindSheets)))
+		if m.findOnlineErr != nil {
+			writeLine(fmt.Sprintf("  unavailable: %v", m.findOnlineErr))
+		}
+		for _, sheet := range m.FindSheets {
+			writeResult(fmt.Sprintf("%s ⭐%.1f", sheet.Name, sheet.Rating))
+		}
+	}
+
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\nKeys: type to search • ↑/↓: move • enter: open • esc: back\n")
+
+	return output.String()
+}
+
+// HandleFindInput handles the keys of the find view
+func (m Model) HandleFindInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.ViewMode = ViewMain
+		return m, nil
+	case tea.KeyUp:
+		if m.FindCursor > 0 {
+			m.FindCursor--
+		}
+		return m, nil
+	case tea.KeyDown:
+		if m.FindCursor < m.findCount()-1 {
+			m.FindCursor++
+		}
+		return m, nil
+	case tea.KeyEnter:
+		return m.openFound()
+	case tea.KeyBackspace:
+		if runes := []rune(m.FindQuery); len(runes) > 0 {
+			return m.editFind(string(runes[:len(runes)-1]))
+		}
+		return m, nil
+	case tea.KeyCtrlU:
+		return m.editFind("")
+	case tea.KeyRunes, tea.KeySpace:
+		return m.editFind(m.FindQuery + string(msg.Runes))
+	}
+	return m, nil
+}
+
+// openFound jumps to the result under the cursor: the shortcut in the
+// table, the note in its preview or the sheet in the online view
+func (m Model) openFound() (tea.Model, tea.Cmd) {
+	i := m.FindCursor
+	if i < len(m.FindShortcuts) {
+		result := m.FindShortcuts[i]
+		m.ViewMode = ViewMain
+		if !m.focusShortcut(result.AppName, result.Shortcut.Keys) {
+			m.StatusMessage = fmt.Sprintf("%s of %s is not in the table", result.Shortcut.Keys, result.AppName)
+		}
+		return m, nil
+	}
+
+	i -= len(m.FindShortcuts)
+	if i < len(m.FindNotes) {
+		id := m.FindNotes[i].ID
+		m.LoadNotes()
+		for j, note := range m.NotesList {
+			if note.ID == id {
+				m.NoteCursor = j
+			}
+		}
+		m.PreviewFrom = ViewFind
+		if m.NoteCursor < len(m.NotesList) && m.NotesList[m.NoteCursor].Encrypted {
+			return m.promptPassphrase("view"), nil
+		}
+		m.ViewMode = ViewNotePreview
+		m.PreviewScroll = 0
+		return m, nil
+	}
+
+	i -= len(m.FindNotes)
+	if i < len(m.FindSheets) {
+		m.ViewMode = ViewOnline
+		m.CheatSheets = append([]online.CheatSheet(nil), m.FindSheets...)
+		m.SheetCursor = i
+		m.SheetFocus = true
+		m.SheetPreview = nil
+		cmd := m.loadRepositories()
+		return m, cmd
+	}
+	return m, nil
+}
+
+// focusShortcut moves the table cursor onto the shortcut of app, clearing
+// the filters of the table when they hide it
+func (m *Model) focusShortcut(app, keys string) bool {
+	if m.moveCursorTo(app, keys) {
+		return true
+	}
+	m.LastSearch = ""
+	m.SelectedTags = nil
+	m.FilteredApps = make([]string, 0)
+	m.applyTableFilters()
+	return m.moveCursorTo(app, keys)
+}
+
+// moveCursorTo moves the table cursor onto the first column of app in the
+// row of keys, if the table shows it
+func (m *Model) moveCursorTo(app, keys string) bool {
+	if len(m.Rows) == 0 {
+		return false
+	}
+	column := -1
+	for x, header := range m.Rows[0] {
+		if name, _ := apps.ColumnApp(header); name == app && x > 0 {
+			column = x
+			break
+		}
+	}
+	if column < 0 {
+		return false
+	}
+	for y := 1; y < len(m.Rows); y++ {
+		if m.Rows[y][0] == keys {
+			m.CursorX, m.CursorY = column, y
+			return true
+		}
+	}
+	return false
+}
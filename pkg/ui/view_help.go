@@ -1,34 +1,152 @@
 package ui
 
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// helpLines is how many lines of keys the help shows at once
+const helpLines = 30
+
+// helpBody returns the lines of the help, generated from the keymap so it
+// lists the keys as bound: a heading per group and a line per action with
+// its keys and, when it can be rebound, its name in the config. With a
+// HelpQuery, only the actions or groups matching it are listed.
+func (m Model) helpBody() []string {
+	query := strings.ToLower(strings.TrimSpace(m.HelpQuery))
+
+	var body []string
+	for _, group := range m.keymap().groups {
+		titleMatches := strings.Contains(strings.ToLower(group.title), query)
+		var lines []string
+		for _, b := range group.bindings {
+			keys := b.displayKeys()
+			name := ""
+			if !group.typing && len(b.keys) > 0 {
+				name = group.bindingName(b)
+			}
+			if !titleMatches && !strings.Contains(strings.ToLower(keys+"\n"+b.help+"\n"+name), query) {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  %s %s %s", fillWidth(keys, 20), fillWidth(b.help, 32), name))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if len(body) > 0 {
+			body = append(body, "")
+		}
+		body = append(body, strings.ToUpper(group.title))
+		body = append(body, lines...)
+	}
+	return body
+}
+
 func (m Model) ViewHelp() string {
-	return `
-╭─ Help ────────────────────────────────────────────────╮
-│                                                       │
-│  NAVIGATION                                           │
-│    ↑/k, ↓/j, ←/h, →/l  Navigate                       │
-│    Home/Ctrl+A          Go to first row               │
-│    End/Ctrl+E           Go to last row                │
-│                                                       │
-│  FEATURES                                             │
-│    /                    Search mode                   │
-│    f                    Filter apps                   │
-│    n                    Notes manager                 │
-│    p                    Plugin manager                │
-│    o                    Browse online                 │
-│    s                    Sync status                   │
-│    Ctrl+S               Force sync                    │
-│    Ctrl+R               Refresh data                  │
-│    ?                    This help screen              │
-│    q/Ctrl+C             Quit                          │
-│                                                       │
-│  SEARCH MODE                                          │
-│    Type to search                                     │
-│    Enter                Confirm search                │
-│    Esc                  Cancel search                 │
-│    Ctrl+U               Clear search                  │
-│                                                       │
-╰───────────────────────────────────────────────────────╯
-
-Press ? or Esc to close help
-`
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 72, "…"), 72)))
+	}
+
+	output.WriteString("\n╭─ Help ─────────────────────────────────────────────────────────────────╮\n")
+	body := m.helpBody()
+	if len(body) == 0 {
+		writeLine(fmt.Sprintf("  No keys match %q", m.HelpQuery))
+	}
+	start := min(m.HelpScroll, max(len(body)-helpLines, 0))
+	end := min(start+helpLines, len(body))
+	for _, line := range body[start:end] {
+		writeLine(line)
+	}
+	if len(body) > helpLines {
+		writeLine("")
+		writeLine(fmt.Sprintf("  lines %d-%d of %d", start+1, end, len(body)))
+	}
+	output.WriteString("╰────────────────────────────────────────────────────────────────────────╯\n")
+
+	switch {
+	case m.HelpSearching:
+		output.WriteString(fmt.Sprintf("\nSearch keys: %s_\nType to search, Enter to confirm, Esc to clear\n", m.HelpQuery))
+	case m.HelpQuery != "":
+		output.WriteString(fmt.Sprintf("\nKeys matching %q • /: search • j/k: scroll • Esc: show all • ?: close\n", m.HelpQuery))
+	default:
+		output.WriteString("\n" + m.hintBar() + "\nRebind keys in the keybinds section of the config by the names on the right\n")
+	}
+
+	return output.String()
+}
+
+// HandleHelpInput handles the keys of the help screen
+func (m Model) HandleHelpInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.HelpSearching {
+		return m.handleHelpSearchInput(msg)
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+[":
+		if m.HelpQuery != "" {
+			m.HelpQuery = ""
+			m.HelpScroll = 0
+			return m, nil
+		}
+		return m.closeHelp(), nil
+	case "?", "q":
+		return m.closeHelp(), nil
+	case "/":
+		m.HelpSearching = true
+		return m, nil
+	case "t":
+		return m.closeHelp().startTour(), nil
+	case "up", "k":
+		if m.HelpScroll > 0 {
+			m.HelpScroll--
+		}
+		return m, nil
+	case "down", "j":
+		if m.HelpScroll < len(m.helpBody())-helpLines {
+			m.HelpScroll++
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// handleHelpSearchInput reads the query narrowing the help
+func (m Model) handleHelpSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.HelpSearching = false
+		m.HelpQuery = ""
+	case tea.KeyEnter:
+		m.HelpSearching = false
+	case tea.KeyBackspace:
+		if runes := []rune(m.HelpQuery); len(runes) > 0 {
+			m.HelpQuery = string(runes[:len(runes)-1])
+		}
+	case tea.KeyCtrlU:
+		m.HelpQuery = ""
+	case tea.KeySpace:
+		m.HelpQuery += " "
+	case tea.KeyRunes:
+		m.HelpQuery += string(msg.Runes)
+	}
+	m.HelpScroll = 0
+	return m, nil
+}
+
+// closeHelp goes back to the table, forgetting the help search
+func (m Model) closeHelp() Model {
+	m.HelpMode = false
+	m.HelpQuery = ""
+	m.HelpScroll = 0
+	m.ViewMode = ViewMain
+	return m
 }
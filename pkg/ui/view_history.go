@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+)
+
+// historyLimit is the number of journal entries shown in the history view
+const historyLimit = 100
+
+func (m Model) ViewHistory() string {
+	var output strings.Builder
+
+	output.WriteString("╭─ Change History ─────────────────────────────────────────╮\n")
+
+	if m.Journal == nil {
+		output.WriteString("│  Change history is not available.                        │\n")
+	} else if len(m.HistoryList) == 0 {
+		output.WriteString("│  No changes recorded yet.                                │\n")
+	} else {
+		start := 0
+		if m.HistoryCursor >= 12 {
+			start = m.HistoryCursor - 11
+		}
+		for i := start; i < len(m.HistoryList) && i < start+12; i++ {
+			entry := m.HistoryList[i]
+
+			cursor := "  "
+			if i == m.HistoryCursor {
+				cursor = "▶ "
+			}
+
+			line := fmt.Sprintf("%s%s %-6s %-6s %-4s %s", cursor, entry.Time.Format("01-02 15:04"),
+				entry.Action, entry.Source, entry.Kind, entry.Name)
+			output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, ""), 58)))
+		}
+		if len(m.HistoryList) > 12 {
+			output.WriteString(fmt.Sprintf("│  %-56s│\n", fmt.Sprintf("%d/%d changes", m.HistoryCursor+1, len(m.HistoryList))))
+		}
+	}
+
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\n" + m.hintBar() + "\n")
+
+	return output.String()
+}
+
+func (m Model) HandleHistoryInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.ViewMode = ViewMain
+		return m, nil
+	case "up", "k":
+		if m.HistoryCursor > 0 {
+			m.HistoryCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.HistoryCursor < len(m.HistoryList)-1 {
+			m.HistoryCursor++
+		}
+		return m, nil
+	case "r":
+		m.LoadHistory()
+		return m, nil
+	case "t":
+		if m.HistoryCursor < len(m.HistoryList) {
+			m.OpenSnapshot(m.HistoryList[m.HistoryCursor].Seq)
+		}
+		return m, nil
+	case "u":
+		if m.HistoryCursor < len(m.HistoryList) {
+			entry := m.HistoryList[m.HistoryCursor]
+			if err := m.UndoChange(entry); err != nil {
+				m.StatusMessage = fmt.Sprintf("Error undoing change: %v", err)
+			} else {
+				m.StatusMessage = fmt.Sprintf("Undid %s of %s %s", entry.Action, entry.Kind, entry.Name)
+				m.LoadHistory()
+			}
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// LoadHistory reads the most recent journal entries, newest first
+func (m *Model) LoadHistory() {
+	m.HistoryList = nil
+	m.HistoryCursor = 0
+	if m.Journal == nil {
+		return
+	}
+
+	entries, err := m.Journal.Recent(historyLimit)
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error loading history: %v", err)
+		return
+	}
+	m.HistoryList = entries
+}
+
+// UndoChange reverts a journaled change by restoring the state recorded
+// before it. The revert goes through the normal managers and is therefore
+// journaled itself.
+func (m *Model) UndoChange(entry journal.Entry) error {
+	switch entry.Kind {
+	case journal.KindNote:
+		return m.undoNoteChange(entry)
+	case journal.KindApp:
+		return m.undoAppChange(entry)
+	default:
+		return fmt.Errorf("unknown change kind %q", entry.Kind)
+	}
+}
+
+func (m *Model) undoNoteChange(entry journal.Entry) error {
+	if m.NotesManager == nil {
+		return fmt.Errorf("notes are not available")
+	}
+
+	if entry.Action == journal.ActionCreate {
+		return m.NotesManager.DeleteNote(entry.ID)
+	}
+
+	var before notes.Note
+	if err := json.Unmarshal(entry.Before, &before); err != nil {
+		return fmt.Errorf("no previous state recorded: %w", err)
+	}
+
+	if entry.Action == journal.ActionDelete {
+		return m.NotesManager.CreateNote(&before)
+	}
+	return m.NotesManager.UpdateNote(entry.ID, &before)
+}
+
+func (m *Model) undoAppChange(entry journal.Entry) error {
+	if entry.Action == journal.ActionCreate {
+		if err := m.Registry.RemoveApp(entry.ID); err != nil {
+			return err
+		}
+		if m.IsAppConfigured(entry.ID) {
+			remaining := make([]string, 0, len(m.Config.Apps))
+			for _, name := range m.Config.Apps {
+				if name != entry.ID {
+					remaining = append(remaining, name)
+				}
+			}
+			m.Config.Apps = remaining
+			m.AllApps = remaining
+			m.RefreshTable()
+			return m.SaveConfig()
+		}
+		return nil
+	}
+
+	var before apps.App
+	if err := json.Unmarshal(entry.Before, &before); err != nil {
+		return fmt.Errorf("no previous state recorded: %w", err)
+	}
+	if err := m.Registry.SaveApp(&before); err != nil {
+		return err
+	}
+	m.RefreshTable()
+	return nil
+}
@@ -5,22 +5,37 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
 )
 
 func (m Model) ViewMain() string {
 	var output strings.Builder
 
-	tableStr := m.Renderer.RenderWithHighlighting(
-		m.Rows,
-		m.CursorX,
-		m.CursorY,
-		m.LastSearch,
-	)
-	output.WriteString(tableStr)
+	renderTable := func(renderer *TableRenderer) string {
+		if m.accessibility().Linear {
+			return renderer.RenderLinear(m.markNotedCells(m.Rows), m.CursorY)
+		}
+		return renderer.RenderWithHighlightedTerms(
+			m.markNotedCells(m.Rows),
+			m.CursorX,
+			m.CursorY,
+			apps.ParseSearchQuery(m.LastSearch).Terms,
+		)
+	}
+	if m.showDetail() {
+		output.WriteString(m.withDetail(renderTable))
+	} else {
+		output.WriteString(renderTable(m.Renderer))
+	}
 	output.WriteString("\n")
 
-	if m.SearchMode {
-		output.WriteString(fmt.Sprintf("\nSearch: %s_\nType to search, Enter to confirm, Esc to cancel\n", m.SearchQuery))
+	if m.TourMode {
+		output.WriteString(m.viewTour())
+	} else if m.SearchHistoryMode {
+		output.WriteString(m.viewSearchHistory())
+	} else if m.SearchMode {
+		output.WriteString(fmt.Sprintf("\nSearch: %s_\nType to search, Enter to confirm, Esc to cancel • ↑/↓: recent searches, Ctrl+H: pick one\napp: cat: key: tag: narrow the search\n", m.SearchQuery))
 	} else if m.FilterMode {
 		output.WriteString("\nFilter Apps: ")
 		for i, app := range m.AllApps {
@@ -37,13 +52,22 @@ func (m Model) ViewMain() string {
 				output.WriteString(fmt.Sprintf(" [%d] %s", i+1, app))
 			}
 		}
-		output.WriteString("\n1-9: toggle apps, a: all, c: clear, Enter: apply, Esc: cancel\n")
+		output.WriteString("\n" + m.hintBar() + "\n")
+	} else if m.TagMode {
+		output.WriteString(m.viewTags())
+	} else if m.ProfileMode {
+		output.WriteString(m.viewProfiles())
+	} else if m.SavedViewMode {
+		output.WriteString(m.viewSavedViews())
+	} else if m.ThemeMode {
+		output.WriteString(m.viewThemes())
+	} else if m.ExportMode {
+		output.WriteString(m.viewExport())
 	} else {
-		output.WriteString("\nArrow keys/hjkl: move • /: search • f: filter • n: notes • p: plugins • o: online • s: sync • ?: help • q: quit\n")
-	}
-
-	if m.StatusMessage != "" {
-		output.WriteString(fmt.Sprintf("\nStatus: %s\n", m.StatusMessage))
+		if len(m.SelectedTags) > 0 {
+			output.WriteString(fmt.Sprintf("\nTags: %s\n", strings.Join(m.SelectedTags, ", ")))
+		}
+		output.WriteString("\n" + m.hintBar() + "\n")
 	}
 
 	return output.String()
@@ -52,45 +76,95 @@ func (m Model) ViewMain() string {
 func (m Model) HandleMainInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q":
-		return m, tea.Quit
+		return m.quit()
+	case "enter":
+		return m.selectShortcut()
 	case "?":
 		m.HelpMode = true
 		m.ViewMode = ViewHelp
 		return m, nil
 	case "/":
-		m.SearchMode = true
-		return m, nil
+		return m.openSearch(), nil
+	case "F":
+		return m.openFind()
 	case "f", "ctrl+f":
 		m.FilterMode = true
 		return m, nil
+	case "t":
+		return m.openTagSelector()
+	case "P":
+		return m.openProfileSelector()
+	case "V":
+		return m.openSavedViews()
+	case "T":
+		return m.openThemePicker()
+	case "E":
+		m.ExportMode = true
+		return m, nil
+	case "y":
+		cmd := m.copyRow()
+		return m, cmd
+	case "Y":
+		cmd := m.copyColumn()
+		return m, cmd
+	case "D":
+		return m.toggleDetail()
+	case "<":
+		return m.resizeDetail(-detailStep)
+	case ">":
+		return m.resizeDetail(detailStep)
+	case "ctrl+h":
+		return m.openSearchHistory()
+	case "Q":
+		return m.openQuiz()
+	case "S":
+		return m.openStats()
 	case "n":
 		m.ViewMode = ViewNotes
-		m.LoadNotes()
-		return m, nil
+		cmd := m.loadNotes()
+		return m, cmd
 	case "p":
 		m.ViewMode = ViewPlugins
 		m.LoadPlugins()
 		return m, nil
 	case "o":
 		m.ViewMode = ViewOnline
-		m.LoadRepositories()
-		return m, nil
+		cmd := m.loadRepositories()
+		return m, cmd
 	case "s":
 		m.ViewMode = ViewSync
-		m.LoadSyncStatus()
+		cmd := m.LoadSyncStatus()
+		return m, cmd
+	case "H":
+		m.ViewMode = ViewHistory
+		m.LoadHistory()
 		return m, nil
-	case "ctrl+s":
-		m.StatusMessage = "Syncing..."
+	case "C":
+		m.ViewMode = ViewCache
 		return m, nil
+	case "!":
+		return m.openDiagnostics()
+	case "N":
+		return m.openShortcutNote()
+	case "ctrl+s":
+		return m.startSync()
 	case "up", "k":
-		if m.CursorY > 1 {
-			m.CursorY--
-		}
+		m.moveRow(-1)
 		return m, nil
 	case "down", "j":
-		if m.CursorY < len(m.Rows)-1 {
-			m.CursorY++
-		}
+		m.moveRow(1)
+		return m, nil
+	case "{":
+		m.jumpSection(-1)
+		return m, nil
+	case "}":
+		m.jumpSection(1)
+		return m, nil
+	case "tab":
+		m.jumpApp(1)
+		return m, nil
+	case "shift+tab":
+		m.jumpApp(-1)
 		return m, nil
 	case "left", "h":
 		if m.CursorX > 0 {
@@ -103,7 +177,7 @@ func (m Model) HandleMainInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	case "ctrl+a", "home":
-		m.CursorY = 1
+		m.firstRow()
 		return m, nil
 	case "ctrl+e", "end":
 		m.CursorY = len(m.Rows) - 1
@@ -116,9 +190,80 @@ func (m Model) HandleMainInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.SearchMode = false
 		m.SearchQuery = ""
 		m.LastSearch = ""
-		m.Rows = m.AllRows
-		m.CursorY = 1
+		m.applyTableFilters()
 		return m, nil
 	}
 	return m, nil
 }
+
+// isSection reports whether row y of the table heads a category
+func (m Model) isSection(y int) bool {
+	return y > 0 && y < len(m.Rows) && apps.IsSectionRow(m.Rows[y])
+}
+
+// moveRow moves the cursor delta rows up or down, stepping over the rows
+// heading categories
+func (m *Model) moveRow(delta int) {
+	y := m.CursorY + delta
+	for m.isSection(y) {
+		y += delta
+	}
+	if y >= 1 && y < len(m.Rows) {
+		m.CursorY = y
+	}
+}
+
+// firstRow puts the cursor on the first shortcut of the table
+func (m *Model) firstRow() {
+	m.CursorY = 1
+	if m.isSection(1) {
+		m.CursorY = 2
+	}
+}
+
+// jumpApp moves the cursor to the first column of the next app when dir is
+// 1, or of the previous one when dir is -1, from the Shortcut column to the
+// first app and back
+func (m *Model) jumpApp(dir int) {
+	if len(m.Rows) == 0 {
+		return
+	}
+	app := m.columnApp(m.CursorX)
+	for x := m.CursorX + dir; x >= 0 && x < len(m.Rows[0]); x += dir {
+		if name := m.columnApp(x); x == 0 || name != app && m.columnApp(x-1) != name {
+			m.CursorX = x
+			return
+		}
+	}
+}
+
+// columnApp returns the app of column x of the table
+func (m Model) columnApp(x int) string {
+	if x < 1 {
+		return ""
+	}
+	app, _ := apps.ColumnApp(m.Rows[0][x])
+	return app
+}
+
+// jumpSection moves the cursor to the first shortcut of the next category
+// when dir is 1. When dir is -1 it moves to the first shortcut of the
+// current category, or of the previous one when already there.
+func (m *Model) jumpSection(dir int) {
+	if !m.Registry.ShowCategories() {
+		m.StatusMessage = "Set layout.show_categories to group the table by category"
+		return
+	}
+	y := m.CursorY
+	if dir < 0 && m.isSection(y-1) {
+		y--
+	}
+	for y += dir; y > 0 && y < len(m.Rows); y += dir {
+		if m.isSection(y) {
+			if dir < 0 || y+1 < len(m.Rows) {
+				m.CursorY = y + 1
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+)
+
+const (
+	// notePreviewWidth is the width note content is wrapped to
+	notePreviewWidth = 72
+	// notePreviewLines is how many content lines are shown at once
+	notePreviewLines = 20
+)
+
+// previewNote returns the note selected in the notes view, decrypted when
+// its passphrase was entered
+func (m Model) previewNote() *notes.Note {
+	if m.NoteCursor < 0 || m.NoteCursor >= len(m.NotesList) {
+		return nil
+	}
+	note := m.NotesList[m.NoteCursor]
+	if m.DecryptedNote != nil && m.DecryptedNote.ID == note.ID {
+		return m.DecryptedNote
+	}
+	return note
+}
+
+// notePreviewBody returns the note content as rendered or raw lines
+func (m Model) notePreviewBody(note *notes.Note) []string {
+	if note.Encrypted && notes.IsEncryptedContent(note.Content) {
+		return wrapText("🔒 This note is encrypted. Open it from the notes view to enter its passphrase.", notePreviewWidth)
+	}
+	if m.PreviewRaw {
+		var lines []string
+		for _, line := range strings.Split(note.Content, "\n") {
+			lines = append(lines, runewidth.Truncate(line, notePreviewWidth, "…"))
+		}
+		return lines
+	}
+
+	var theme *Theme
+	if m.Renderer != nil {
+		theme = m.Renderer.GetTheme()
+	}
+	return renderMarkdown(note.Content, theme, notePreviewWidth)
+}
+
+func (m Model) ViewNotePreview() string {
+	var output strings.Builder
+
+	note := m.previewNote()
+	if note == nil {
+		output.WriteString("No note selected.\n\nKeys: esc: back\n")
+		return output.String()
+	}
+
+	mode := "rendered"
+	if m.PreviewRaw {
+		mode = "raw"
+	}
+	output.WriteString(fmt.Sprintf("╭─ %s (%s)\n", note.Title, mode))
+
+	var meta []string
+	if note.AppName != "" {
+		meta = append(meta, "app: "+note.AppName)
+	}
+	if note.Category != "" {
+		meta = append(meta, "category: "+note.Category)
+	}
+	if len(note.Tags) > 0 {
+		meta = append(meta, "tags: "+strings.Join(note.Tags, ", "))
+	}
+	meta = append(meta, "updated: "+note.UpdatedAt.Format("2006-01-02 15:04"))
+	output.WriteString("│ " + strings.Join(meta, " • ") + "\n")
+	output.WriteString("├" + strings.Repeat("─", notePreviewWidth+1) + "\n")
+
+	body := m.notePreviewBody(note)
+	start := m.PreviewScroll
+	if start > len(body)-1 {
+		start = len(body) - 1
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + notePreviewLines
+	if end > len(body) {
+		end = len(body)
+	}
+	for _, line := range body[start:end] {
+		output.WriteString("│ " + line + "\n")
+	}
+
+	position := ""
+	if len(body) > notePreviewLines {
+		position = fmt.Sprintf(" lines %d-%d of %d ", start+1, end, len(body))
+	}
+	output.WriteString("╰" + position + strings.Repeat("─", notePreviewWidth+1-len(position)) + "\n")
+	output.WriteString("\n" + m.hintBar() + "\n")
+
+	return output.String()
+}
+
+func (m Model) HandleNotePreviewInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.ViewMode = m.PreviewFrom
+		m.DecryptedNote = nil
+		return m, nil
+	case "r":
+		m.PreviewRaw = !m.PreviewRaw
+		m.PreviewScroll = 0
+		return m, nil
+	case "up", "k":
+		if m.PreviewScroll > 0 {
+			m.PreviewScroll--
+		}
+		return m, nil
+	case "down", "j":
+		if note := m.previewNote(); note != nil && m.PreviewScroll < len(m.notePreviewBody(note))-notePreviewLines {
+			m.PreviewScroll++
+		}
+		return m, nil
+	case "e":
+		// edit from the notes view, which reloads the list afterwards
+		m.ViewMode = ViewNotes
+		m.DecryptedNote = nil
+		return m.HandleNotesInput(msg)
+	}
+	return m, nil
+}
+
+// noteMarker is appended to table cells that have an attached note
+const noteMarker = " ✎"
+
+// markNotedCells returns rows with noteMarker added to every shortcut cell
+// that has an attached note
+func (m Model) markNotedCells(rows [][]string) [][]string {
+	if len(m.NoteLinks) == 0 || len(rows) == 0 {
+		return rows
+	}
+
+	marked := make([][]string, len(rows))
+	marked[0] = rows[0]
+	for i, row := range rows[1:] {
+		marked[i+1] = row
+		copied := false
+		previous := ""
+		for col := 1; col < len(row) && col < len(rows[0]); col++ {
+			// an app with several columns has the marker on its first
+			app, _ := apps.ColumnApp(rows[0][col])
+			if app == previous {
+				continue
+			}
+			previous = app
+			if _, ok := m.NoteLinks[noteLinkKey(app, row[0])]; !ok {
+				continue
+			}
+			if !copied {
+				marked[i+1] = append([]string(nil), row...)
+				copied = true
+			}
+			marked[i+1][col] += noteMarker
+		}
+	}
+	return marked
+}
+
+// openShortcutNote shows the note attached to the shortcut under the cursor,
+// attaching a new note first when there is none
+func (m Model) openShortcutNote() (tea.Model, tea.Cmd) {
+	app, keys, ok := m.SelectedShortcut()
+	if !ok {
+		m.StatusMessage = "Move the cursor to a shortcut to attach a note"
+		return m, nil
+	}
+	if m.NotesManager == nil {
+		m.StatusMessage = "Notes are not available"
+		return m, nil
+	}
+
+	id, linked := m.NoteLinks[noteLinkKey(app, keys)]
+	if !linked {
+		note := &notes.Note{
+			Title:        fmt.Sprintf("%s: %s", app, keys),
+			Content:      m.Rows[m.CursorY][m.CursorX],
+			AppName:      app,
+			Category:     "shortcut",
+			ShortcutKeys: keys,
+		}
+		note.AddTags(m.noteTags()...)
+		if err := m.NotesManager.CreateNote(note); err != nil {
+			m.StatusMessage = fmt.Sprintf("Error attaching note: %v", err)
+			return m, nil
+		}
+		id = note.ID
+		m.StatusMessage = fmt.Sprintf("Attached a note to %s in %s; press e to edit", keys, app)
+	}
+
+	m.LoadNotes()
+	for i, note := range m.NotesList {
+		if note.ID == id {
+			m.NoteCursor = i
+		}
+	}
+	m.PreviewFrom = ViewMain
+	if m.NoteCursor < len(m.NotesList) && m.NotesList[m.NoteCursor].Encrypted {
+		return m.promptPassphrase("view"), nil
+	}
+	m.ViewMode = ViewNotePreview
+	m.PreviewScroll = 0
+	return m, nil
+}
@@ -3,13 +3,24 @@ package ui
 import (
 	"fmt"
 	"strings"
-	"time"
 
-	"cheat-go/pkg/notes"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/notes"
 )
 
 func (m Model) ViewNotes() string {
+	if m.TemplateMode {
+		return m.viewTemplates()
+	}
+	if m.PassphraseMode {
+		return m.viewPassphrase()
+	}
+	if m.TrashMode {
+		return m.viewTrash()
+	}
+
 	var output strings.Builder
 
 	output.WriteString("╭─ Personal Notes ─────────────────────────────────────────╮\n")
@@ -33,25 +44,36 @@ func (m Model) ViewNotes() string {
 				favorite = "⭐"
 			}
 
-			line := fmt.Sprintf("%s%s %-30s %s", cursor, favorite, note.Title, note.AppName)
-			if len(line) > 58 {
-				line = line[:58]
+			title := note.Title
+			if note.Encrypted {
+				title = "🔒 " + title
 			}
-			output.WriteString(fmt.Sprintf("│%-58s│\n", line))
+			if note.Team != "" {
+				title = "👥 " + title
+			}
+
+			line := fmt.Sprintf("%s%s %s %s", cursor, favorite, runewidth.FillRight(title, 30), note.AppName)
+			output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, ""), 58)))
 		}
 	}
 
 	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
-	output.WriteString("\nKeys: n: new • e: edit • d: delete • f: favorite • esc: back\n")
-
-	if m.StatusMessage != "" {
-		output.WriteString(fmt.Sprintf("\nStatus: %s\n", m.StatusMessage))
-	}
+	output.WriteString("\n" + m.hintBar() + "\n")
 
 	return output.String()
 }
 
 func (m Model) HandleNotesInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.TemplateMode {
+		return m.handleTemplateInput(msg)
+	}
+	if m.PassphraseMode {
+		return m.handlePassphraseInput(msg)
+	}
+	if m.TrashMode {
+		return m.handleTrashInput(msg)
+	}
+
 	switch msg.String() {
 	case "esc", "q":
 		m.ViewMode = ViewMain
@@ -66,24 +88,26 @@ func (m Model) HandleNotesInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.NoteCursor++
 		}
 		return m, nil
-	case "n":
-		newNote := &notes.Note{
-			Title:    fmt.Sprintf("New Note %d", time.Now().Unix()),
-			Content:  "Enter your note content here",
-			Category: "general",
-			Tags:     []string{"new"},
-		}
-		err := m.NotesManager.CreateNote(newNote)
-		if err != nil {
-			m.StatusMessage = fmt.Sprintf("Error creating note: %v", err)
-		} else {
-			m.LoadNotes()
-			m.StatusMessage = "Note created successfully"
+	case "enter", "v":
+		if m.NoteCursor < len(m.NotesList) {
+			m.PreviewFrom = ViewNotes
+			if m.NotesList[m.NoteCursor].Encrypted {
+				return m.promptPassphrase("view"), nil
+			}
+			m.ViewMode = ViewNotePreview
+			m.PreviewScroll = 0
 		}
 		return m, nil
+	case "n":
+		m.LoadTemplates()
+		m.TemplateMode = true
+		return m, nil
 	case "e":
 		if m.NoteCursor < len(m.NotesList) {
 			note := m.NotesList[m.NoteCursor]
+			if note.Encrypted {
+				return m.promptPassphrase("edit"), nil
+			}
 			updatedNote, err := m.OpenEditorForNote(note)
 			if err != nil {
 				m.StatusMessage = fmt.Sprintf("Error opening editor: %v", err)
@@ -101,18 +125,315 @@ func (m Model) HandleNotesInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "d":
 		if m.NoteCursor < len(m.NotesList) {
 			noteID := m.NotesList[m.NoteCursor].ID
-			m.NotesManager.DeleteNote(noteID)
+			if err := m.NotesManager.DeleteNote(noteID); err != nil {
+				m.StatusMessage = fmt.Sprintf("Error deleting note: %v", err)
+				return m, nil
+			}
 			m.LoadNotes()
-			m.StatusMessage = "Note deleted"
+			m.StatusMessage = "Note moved to trash; press t to view the trash"
 		}
 		return m, nil
 	case "f":
 		if m.NoteCursor < len(m.NotesList) {
 			noteID := m.NotesList[m.NoteCursor].ID
-			m.NotesManager.ToggleFavorite(noteID)
+			if err := m.NotesManager.ToggleFavorite(noteID); err != nil {
+				m.StatusMessage = fmt.Sprintf("Error updating note: %v", err)
+				return m, nil
+			}
 			m.LoadNotes()
 		}
 		return m, nil
+	case "t":
+		m.LoadTrash()
+		m.TrashMode = true
+		return m, nil
+	case "x":
+		if m.NoteCursor < len(m.NotesList) {
+			if m.NotesList[m.NoteCursor].Encrypted {
+				return m.promptPassphrase("decrypt"), nil
+			}
+			return m.promptPassphrase("encrypt"), nil
+		}
+		return m, nil
 	}
 	return m, nil
 }
+
+// viewTemplates shows the template picker for a new note
+func (m Model) viewTemplates() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, ""), 58)))
+	}
+
+	output.WriteString("╭─ New Note from Template ─────────────────────────────────╮\n")
+	for i, tmpl := range m.Templates {
+		cursor := "  "
+		if i == m.TemplateCursor {
+			cursor = "▶ "
+		}
+		writeLine(fmt.Sprintf("%s%-20s %s", cursor, tmpl.Name, tmpl.Description))
+	}
+	if app := m.SelectedApp(); app != "" {
+		writeLine("")
+		writeLine(fmt.Sprintf("  App: %s", app))
+	}
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\n" + m.hintBar() + "\n")
+
+	return output.String()
+}
+
+func (m Model) handleTemplateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.TemplateMode = false
+		return m, nil
+	case "up", "k":
+		if m.TemplateCursor > 0 {
+			m.TemplateCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.TemplateCursor < len(m.Templates)-1 {
+			m.TemplateCursor++
+		}
+		return m, nil
+	case "enter":
+		m.TemplateMode = false
+		if m.TemplateCursor >= len(m.Templates) {
+			return m, nil
+		}
+		tmpl := m.Templates[m.TemplateCursor]
+		newNote := tmpl.NewNote(notes.TemplateVars{App: m.SelectedApp()})
+		newNote.AddTags(m.noteTags()...)
+		if err := m.NotesManager.CreateNote(newNote); err != nil {
+			m.StatusMessage = fmt.Sprintf("Error creating note: %v", err)
+			return m, nil
+		}
+		m.LoadNotes()
+		for i, note := range m.NotesList {
+			if note.ID == newNote.ID {
+				m.NoteCursor = i
+			}
+		}
+		m.StatusMessage = fmt.Sprintf("Created '%s' from template %s; press e to edit", newNote.Title, tmpl.Name)
+		return m, nil
+	}
+	return m, nil
+}
+
+// viewTrash lists the deleted notes
+func (m Model) viewTrash() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, ""), 58)))
+	}
+
+	output.WriteString("╭─ Trash ──────────────────────────────────────────────────╮\n")
+	if len(m.TrashList) == 0 {
+		writeLine("  The trash is empty.")
+	}
+	for i, trashed := range m.TrashList {
+		cursor := "  "
+		if i == m.TrashCursor {
+			cursor = "▶ "
+		}
+		writeLine(fmt.Sprintf("%s%s %s", cursor, runewidth.FillRight(runewidth.Truncate(trashed.Note.Title, 38, "…"), 38),
+			trashed.DeletedAt.Format("2006-01-02 15:04")))
+	}
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\n" + m.hintBar() + "\n")
+
+	return output.String()
+}
+
+func (m Model) handleTrashInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	trash, ok := m.NotesManager.(notes.TrashManager)
+	if !ok {
+		m.TrashMode = false
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q", "t":
+		m.TrashMode = false
+		return m, nil
+	case "up", "k":
+		if m.TrashCursor > 0 {
+			m.TrashCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.TrashCursor < len(m.TrashList)-1 {
+			m.TrashCursor++
+		}
+		return m, nil
+	case "r", "enter":
+		if m.TrashCursor >= len(m.TrashList) {
+			return m, nil
+		}
+		note := m.TrashList[m.TrashCursor].Note
+		if err := trash.RestoreNote(note.ID); err != nil {
+			m.StatusMessage = fmt.Sprintf("Error restoring note: %v", err)
+			return m, nil
+		}
+		m.LoadTrash()
+		m.LoadNotes()
+		m.StatusMessage = fmt.Sprintf("Note '%s' restored", note.Title)
+		return m, nil
+	case "E":
+		removed, err := trash.EmptyTrash()
+		if err != nil {
+			m.StatusMessage = fmt.Sprintf("Error emptying trash: %v", err)
+			return m, nil
+		}
+		m.LoadTrash()
+		m.StatusMessage = fmt.Sprintf("Permanently removed %d notes", removed)
+		return m, nil
+	}
+	return m, nil
+}
+
+// passphrasePrompts describe what the passphrase is asked for
+var passphrasePrompts = map[string]string{
+	"view":    "Passphrase to open",
+	"edit":    "Passphrase to edit",
+	"encrypt": "New passphrase for",
+	"confirm": "Repeat the passphrase for",
+	"decrypt": "Passphrase to remove encryption from",
+}
+
+// promptPassphrase asks for the passphrase of the selected note before
+// running action
+func (m Model) promptPassphrase(action string) Model {
+	m.ViewMode = ViewNotes
+	m.PassphraseMode = true
+	m.PassphraseAction = action
+	m.PassphraseInput = ""
+	return m
+}
+
+// viewPassphrase shows the masked passphrase prompt
+func (m Model) viewPassphrase() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, ""), 58)))
+	}
+
+	title := ""
+	if m.NoteCursor < len(m.NotesList) {
+		title = m.NotesList[m.NoteCursor].Title
+	}
+
+	output.WriteString("╭─ Encrypted Note ─────────────────────────────────────────╮\n")
+	writeLine(fmt.Sprintf("  %s '%s'", passphrasePrompts[m.PassphraseAction], title))
+	writeLine("")
+	writeLine("  > " + strings.Repeat("•", runewidth.StringWidth(m.PassphraseInput)))
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\nKeys: enter: confirm • esc: cancel\n")
+
+	return output.String()
+}
+
+func (m Model) handlePassphraseInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.PassphraseMode = false
+		m.PassphraseInput = ""
+		m.PassphraseFirst = ""
+		m.StatusMessage = "Cancelled"
+		return m, nil
+	case tea.KeyBackspace:
+		if runes := []rune(m.PassphraseInput); len(runes) > 0 {
+			m.PassphraseInput = string(runes[:len(runes)-1])
+		}
+		return m, nil
+	case tea.KeyCtrlU:
+		m.PassphraseInput = ""
+		return m, nil
+	case tea.KeyRunes, tea.KeySpace:
+		m.PassphraseInput += string(msg.Runes)
+		return m, nil
+	case tea.KeyEnter:
+		passphrase := m.PassphraseInput
+		m.PassphraseMode = false
+		m.PassphraseInput = ""
+		return m.runPassphraseAction(passphrase), nil
+	}
+	return m, nil
+}
+
+// runPassphraseAction runs the pending passphrase action on the selected note
+func (m Model) runPassphraseAction(passphrase string) Model {
+	if m.NoteCursor >= len(m.NotesList) {
+		return m
+	}
+	note := m.NotesList[m.NoteCursor]
+
+	encrypter, ok := m.NotesManager.(notes.Encrypter)
+	if !ok {
+		m.StatusMessage = "Note encryption is not available"
+		return m
+	}
+
+	switch m.PassphraseAction {
+	case "view":
+		decrypted, err := encrypter.DecryptNote(note.ID, passphrase)
+		if err != nil {
+			m.StatusMessage = fmt.Sprintf("Error opening note: %v", err)
+			return m
+		}
+		m.DecryptedNote = decrypted
+		m.ViewMode = ViewNotePreview
+		m.PreviewScroll = 0
+	case "edit":
+		decrypted, err := encrypter.DecryptNote(note.ID, passphrase)
+		if err != nil {
+			m.StatusMessage = fmt.Sprintf("Error opening note: %v", err)
+			return m
+		}
+		updatedNote, err := m.OpenEditorForNote(decrypted)
+		if err != nil {
+			m.StatusMessage = fmt.Sprintf("Error opening editor: %v", err)
+			return m
+		}
+		if err := encrypter.UpdateEncryptedNote(note.ID, updatedNote, passphrase); err != nil {
+			m.StatusMessage = fmt.Sprintf("Error updating note: %v", err)
+			return m
+		}
+		m.LoadNotes()
+		m.StatusMessage = fmt.Sprintf("Note '%s' updated", updatedNote.Title)
+	case "encrypt":
+		if passphrase == "" {
+			m.StatusMessage = fmt.Sprintf("Error encrypting note: %v", notes.ErrEmptyPassphrase)
+			return m
+		}
+		m.PassphraseFirst = passphrase
+		return m.promptPassphrase("confirm")
+	case "confirm":
+		first := m.PassphraseFirst
+		m.PassphraseFirst = ""
+		if passphrase != first {
+			m.StatusMessage = "Passphrases do not match; note left unencrypted"
+			return m
+		}
+		if err := encrypter.EncryptNote(note.ID, passphrase); err != nil {
+			m.StatusMessage = fmt.Sprintf("Error encrypting note: %v", err)
+			return m
+		}
+		m.LoadNotes()
+		m.StatusMessage = fmt.Sprintf("Note '%s' encrypted", note.Title)
+	case "decrypt":
+		if err := encrypter.RemoveEncryption(note.ID, passphrase); err != nil {
+			m.StatusMessage = fmt.Sprintf("Error removing encryption: %v", err)
+			return m
+		}
+		m.LoadNotes()
+		m.StatusMessage = fmt.Sprintf("Note '%s' is no longer encrypted", note.Title)
+	}
+	return m
+}
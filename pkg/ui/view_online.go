@@ -3,51 +3,175 @@ package ui
 import (
 	"fmt"
 	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/online"
+)
+
+const (
+	previewWidth = 40
+	previewLines = 18
 )
 
 func (m Model) ViewOnline() string {
 	var output strings.Builder
+	var browser strings.Builder
 
-	output.WriteString("╭─ Online Repositories ────────────────────────────────────╮\n")
+	title := "─ Online Repositories "
+	if badge := updateBadge(len(m.SheetUpdates)); badge != "" {
+		title += badge + " updates "
+	}
+	browser.WriteString("╭" + title + strings.Repeat("─", max(0, 58-runewidth.StringWidth(title))) + "╮\n")
 
 	if len(m.ReposList) == 0 {
-		output.WriteString("│  Loading repositories...                                 │\n")
+		browser.WriteString("│  Loading repositories...                                 │\n")
 	} else {
 		for i, repo := range m.ReposList {
 			cursor := "  "
-			if i == m.RepoCursor {
+			if i == m.RepoCursor && !m.SheetFocus {
 				cursor = "▶ "
 			}
 
-			line := fmt.Sprintf("%s%-30s ⭐%d", cursor, repo.Name, repo.Stars)
-			if len(line) > 58 {
-				line = line[:58]
+			line := fmt.Sprintf("%s%-30s ⭐%-6d", cursor, repo.Name, repo.Stars)
+			if m.isSubscribed(repo.URL) {
+				line += " ● subscribed"
 			}
-			output.WriteString(fmt.Sprintf("│%-58s│\n", line))
+			if badge := updateBadge(m.repoUpdates(repo.URL)); badge != "" {
+				line += " " + badge
+			}
+			browser.WriteString("│" + runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58) + "│\n")
 		}
 	}
 
 	if len(m.CheatSheets) > 0 {
-		output.WriteString("│──────────────────────────────────────────────────────────│\n")
-		output.WriteString("│ Cheat Sheets:                                            │\n")
-		for i, sheet := range m.CheatSheets {
-			if i > 5 {
-				break
+		browser.WriteString("│──────────────────────────────────────────────────────────│\n")
+		browser.WriteString("│ Cheat Sheets:                                            │\n")
+		browser.WriteString("│" + sheetRow("  ", sortLabel("Name", online.SortByName, m.SheetSort),
+			sortLabel("Rating", online.SortByRating, m.SheetSort),
+			sortLabel("Downloads", online.SortByDownloads, m.SheetSort), "Tags") + "│\n")
+		start := 0
+		if m.SheetCursor > 5 {
+			start = m.SheetCursor - 5
+		}
+		for i := start; i < len(m.CheatSheets) && i <= start+5; i++ {
+			sheet := m.CheatSheets[i]
+			cursor := "  "
+			if i == m.SheetCursor && m.SheetFocus {
+				cursor = "▶ "
 			}
-			line := fmt.Sprintf("  %-25s ⬇%d ★%.1f", sheet.Name, sheet.Downloads, sheet.Rating)
-			if len(line) > 58 {
-				line = line[:58]
+			name := sheet.Name
+			if _, ok := m.sheetUpdate(sheet.ID); ok {
+				name = "⬆ " + name
 			}
-			output.WriteString(fmt.Sprintf("│%-58s│\n", line))
+			row := sheetRow(cursor, name, fmt.Sprintf("%.1f", sheet.Rating),
+				fmt.Sprintf("%d", sheet.Downloads), strings.Join(sheet.Tags, ","))
+			browser.WriteString("│" + row + "│\n")
+		}
+		if len(m.CheatSheets) > 6 {
+			line := fmt.Sprintf("  %d/%d sheets", m.SheetCursor+1, len(m.CheatSheets))
+			browser.WriteString(fmt.Sprintf("│%-58s│\n", line))
 		}
 	}
 
-	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
-	output.WriteString("\nKeys: enter: browse • d: download • /: search • esc: back\n")
+	browser.WriteString("╰──────────────────────────────────────────────────────────╯\n")
 
-	if m.StatusMessage != "" {
-		output.WriteString(fmt.Sprintf("\nStatus: %s\n", m.StatusMessage))
+	if len(m.UnverifiedSheets) > 0 {
+		pending := m.UnverifiedSheets[0]
+		browser.WriteString(fmt.Sprintf("⚠ %s could not be verified: %v\n", pending.sheet.Name, pending.err))
+		browser.WriteString("Install it anyway?\n")
+	} else if m.RatingMode && m.SheetCursor < len(m.CheatSheets) {
+		browser.WriteString(fmt.Sprintf("Rate %s: press 1-5 (esc to cancel)\n", m.CheatSheets[m.SheetCursor].Name))
+	} else if m.ReportMode && m.SheetCursor < len(m.CheatSheets) {
+		browser.WriteString(fmt.Sprintf("Report %s: 1 spam • 2 offensive • 3 incorrect • 4 malicious (esc to cancel)\n", m.CheatSheets[m.SheetCursor].Name))
 	}
 
+	if m.SheetPreview != nil {
+		output.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, strings.TrimSuffix(browser.String(), "\n"), " ", renderSheetPreview(m.SheetPreview)))
+		output.WriteString("\n")
+	} else {
+		output.WriteString(browser.String())
+	}
+	output.WriteString("\n" + m.hintBar() + "\n")
+
 	return output.String()
 }
+
+// renderSheetPreview renders a cheat sheet's shortcuts grouped by category
+func renderSheetPreview(sheet *online.CheatSheet) string {
+	return renderPreviewBox("Preview", appPreviewLines(sheet.Name, sheet.Description, sheet.App.Shortcuts))
+}
+
+// appPreviewLines lists an app's shortcuts grouped by category
+func appPreviewLines(name, description string, shortcuts []apps.Shortcut) []string {
+	inner := previewWidth - 4
+	lines := []string{name}
+	if description != "" {
+		lines = append(lines, description)
+	}
+	lines = append(lines, "")
+
+	if len(shortcuts) == 0 {
+		return append(lines, "No shortcuts in this sheet.")
+	}
+
+	keyWidth := 0
+	for _, sc := range shortcuts {
+		if w := runewidth.StringWidth(sc.Keys); w > keyWidth {
+			keyWidth = w
+		}
+	}
+	if keyWidth > inner/2 {
+		keyWidth = inner / 2
+	}
+
+	category := ""
+	for _, sc := range shortcuts {
+		if sc.Category != category {
+			category = sc.Category
+			if category != "" {
+				lines = append(lines, "["+category+"]")
+			}
+		}
+		keys := runewidth.FillRight(runewidth.Truncate(sc.Keys, keyWidth, "…"), keyWidth)
+		lines = append(lines, keys+"  "+sc.Description)
+	}
+	return lines
+}
+
+// renderPreviewBox draws lines in a titled box of previewWidth columns,
+// eliding lines beyond previewLines
+func renderPreviewBox(title string, lines []string) string {
+	inner := previewWidth - 4
+	if len(lines) > previewLines {
+		more := len(lines) - previewLines + 1
+		lines = append(lines[:previewLines-1:previewLines-1], fmt.Sprintf("… %d more lines", more))
+	}
+
+	var out strings.Builder
+	out.WriteString("╭─ " + title + " " + strings.Repeat("─", previewWidth-5-runewidth.StringWidth(title)) + "╮\n")
+	for _, line := range lines {
+		out.WriteString("│ " + runewidth.FillRight(runewidth.Truncate(line, inner, "…"), inner) + " │\n")
+	}
+	out.WriteString("╰" + strings.Repeat("─", previewWidth-2) + "╯")
+	return out.String()
+}
+
+// sheetRow lays out one 58 column row of the cheat sheet list
+func sheetRow(cursor, name, rating, downloads, tags string) string {
+	cell := func(text string, width int) string {
+		return runewidth.FillRight(runewidth.Truncate(text, width, "…"), width)
+	}
+	row := cursor + cell(name, 20) + " " + cell(rating, 7) + " " + cell(downloads, 10) + " " + tags
+	return cell(row, 58)
+}
+
+// sortLabel marks the column the sheet list is currently sorted by
+func sortLabel(label, order, current string) string {
+	if order == current {
+		return label + "▼"
+	}
+	return label
+}
@@ -2,10 +2,19 @@ package ui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/plugins"
 )
 
 func (m Model) ViewPlugins() string {
+	if m.PluginDetail && m.PluginCursor < len(m.PluginsList) {
+		return m.viewPluginDetail(m.PluginsList[m.PluginCursor])
+	}
+
 	var output strings.Builder
 
 	output.WriteString("╭─ Plugin Manager ─────────────────────────────────────────╮\n")
@@ -25,6 +34,11 @@ func (m Model) ViewPlugins() string {
 			}
 
 			line := fmt.Sprintf("%s%-20s v%-8s %s", cursor, plugin.Metadata.Name, plugin.Metadata.Version, plugin.Metadata.Author)
+			if plugin.Untrusted != nil {
+				line = fmt.Sprintf("%s%-20s v%-8s (unverified)", cursor, plugin.Metadata.Name, plugin.Metadata.Version)
+			} else if plugin.Disabled {
+				line = fmt.Sprintf("%s%-20s v%-8s (disabled)", cursor, plugin.Metadata.Name, plugin.Metadata.Version)
+			}
 			if len(line) > 58 {
 				line = line[:58]
 			}
@@ -33,11 +47,118 @@ func (m Model) ViewPlugins() string {
 	}
 
 	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
-	output.WriteString("\nKeys: l: load • u: unload • r: reload all • esc: back\n")
+	if plugin, ok := m.selectedPlugin(); ok && m.PluginApproveMode {
+		output.WriteString(fmt.Sprintf("⚠ %s could not be verified: %v\n", plugin.Metadata.Name, plugin.Untrusted))
+		output.WriteString("Run it anyway? Approve only plugins whose source you trust.\n")
+	}
+	output.WriteString("\n" + m.hintBar() + "\n")
+
+	return output.String()
+}
+
+// viewPluginDetail shows the metadata, config, load path, last error and
+// provided apps of a plugin
+func (m Model) viewPluginDetail(plugin *plugins.LoadedPlugin) string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+	writeField := func(name, value string) {
+		if value == "" {
+			value = "-"
+		}
+		for _, line := range wrapText(value, 43) {
+			writeLine(fmt.Sprintf("  %-12s %s", name, line))
+			name = ""
+		}
+	}
+
+	meta := plugin.Metadata
+	output.WriteString("╭─ Plugin Details ─────────────────────────────────────────╮\n")
+	writeField("Name", meta.Name)
+	writeField("Version", meta.Version)
+	writeField("Author", meta.Author)
+	writeField("Type", meta.Type)
+	state := "enabled"
+	switch {
+	case plugin.Untrusted != nil:
+		state = "not run: " + plugin.Untrusted.Error()
+	case plugin.Disabled:
+		state = "disabled"
+	}
+	writeField("State", state)
+	writeField("Path", plugin.Path)
+	if plugin.Sum != "" {
+		writeField("SHA-256", plugin.Sum)
+	}
+	writeField("Description", meta.Description)
 
-	if m.StatusMessage != "" {
-		output.WriteString(fmt.Sprintf("\nStatus: %s\n", m.StatusMessage))
+	writeLine("")
+	writeLine("  Config")
+	if len(meta.Config) == 0 {
+		writeLine("    (none)")
+	}
+	keys := make([]string, 0, len(meta.Config))
+	for key := range meta.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writeLine(fmt.Sprintf("    %s: %v", key, meta.Config[key]))
+	}
+
+	if len(meta.Hooks) > 0 {
+		writeLine("")
+		writeLine("  Hooks")
+		for _, hook := range []plugins.Hook{plugins.HookStartup, plugins.HookSearch, plugins.HookSelect, plugins.HookQuit} {
+			if command, ok := meta.Hooks[string(hook)]; ok {
+				writeLine(fmt.Sprintf("    %s: %s", hook, strings.TrimSpace(command)))
+			}
+		}
 	}
 
+	writeLine("")
+	writeField("Apps", strings.Join(plugin.Apps, ", "))
+	lastError := ""
+	if plugin.LastError != nil {
+		lastError = plugin.LastError.Error()
+	}
+	writeField("Last error", lastError)
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\n" + m.hintBar() + "\n")
+
 	return output.String()
 }
+
+// togglePlugin enables or disables a plugin and saves the choice to the
+// plugins.disabled list of the config
+func (m *Model) togglePlugin(plugin *plugins.LoadedPlugin) {
+	name := plugin.Metadata.Name
+	enable := plugin.Disabled
+	if err := m.PluginLoader.SetEnabled(name, enable); err != nil {
+		m.StatusMessage = fmt.Sprintf("Error: %v", err)
+		return
+	}
+
+	var disabled []string
+	for _, other := range m.Config.Plugins.Disabled {
+		if other != name {
+			disabled = append(disabled, other)
+		}
+	}
+	if !enable {
+		disabled = append(disabled, name)
+	}
+	m.Config.Plugins.Disabled = disabled
+
+	state := "Disabled"
+	if enable {
+		state = "Enabled"
+	}
+	if err := m.SaveConfig(); err != nil {
+		m.StatusMessage = fmt.Sprintf("%s %s, but saving config failed: %v", state, name, err)
+		return
+	}
+	m.StatusMessage = fmt.Sprintf("%s %s", state, name)
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// openProfileSelector lists the configured profiles to switch between
+func (m Model) openProfileSelector() (tea.Model, tea.Cmd) {
+	if m.Config == nil || len(m.Config.Profiles) == 0 {
+		m.StatusMessage = "No profiles configured; add some under profiles: in the config"
+		return m, nil
+	}
+	m.ProfileCursor = 0
+	for i, name := range m.profileChoices() {
+		if name == m.Config.ActiveProfile() {
+			m.ProfileCursor = i
+		}
+	}
+	m.ProfileMode = true
+	return m, nil
+}
+
+// profileChoices lists the profiles, after "" for the base configuration
+func (m Model) profileChoices() []string {
+	return append([]string{""}, m.Config.ProfileNames()...)
+}
+
+// viewProfiles renders the profile selector below the table
+func (m Model) viewProfiles() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+
+	output.WriteString("\n╭─ Profiles ───────────────────────────────────────────────╮\n")
+	for i, name := range m.profileChoices() {
+		cursor := "  "
+		if i == m.ProfileCursor {
+			cursor = "▶ "
+		}
+		active := "  "
+		if name == m.Config.ActiveProfile() {
+			active = "● "
+		}
+		if name == "" {
+			writeLine(cursor + active + "(no profile)")
+			continue
+		}
+		profile := m.Config.Profiles[name]
+		var details []string
+		if profile.Apps != nil {
+			details = append(details, strings.Join(profile.Apps, ", "))
+		}
+		if profile.Theme != "" {
+			details = append(details, profile.Theme+" theme")
+		}
+		writeLine(fmt.Sprintf("%s%s%-12s %s", cursor, active, name, strings.Join(details, " • ")))
+	}
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString(m.hintBar() + "\n")
+
+	return output.String()
+}
+
+// HandleProfileInput handles the keys of the profile selector
+func (m Model) HandleProfileInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	choices := m.profileChoices()
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+[", "P":
+		m.ProfileMode = false
+		return m, nil
+	case "up", "k":
+		if m.ProfileCursor > 0 {
+			m.ProfileCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.ProfileCursor < len(choices)-1 {
+			m.ProfileCursor++
+		}
+		return m, nil
+	case "enter":
+		m.ProfileMode = false
+		m.switchProfile(choices[m.ProfileCursor])
+		return m, nil
+	}
+	return m, nil
+}
+
+// switchProfile applies the named profile, or none for "", for this run
+func (m *Model) switchProfile(name string) {
+	cfg, err := m.Config.WithProfile(name)
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	if err := m.applyConfig(cfg); err != nil {
+		m.StatusMessage = fmt.Sprintf("Could not load some apps: %v", err)
+		return
+	}
+	if name == "" {
+		m.StatusMessage = "Switched to the base configuration"
+	} else {
+		m.StatusMessage = fmt.Sprintf("Switched to profile %s", name)
+	}
+}
@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/practice"
+)
+
+// openQuiz starts a quiz on the shortcuts of the shown apps
+func (m Model) openQuiz() (tea.Model, tea.Cmd) {
+	if !m.loadPractice() {
+		return m, nil
+	}
+
+	m.QuizQuestions = practice.Questions(m.Registry, m.activeApps())
+	if len(m.QuizQuestions) == 0 {
+		m.StatusMessage = "No shortcuts to practice"
+		return m, nil
+	}
+	m.QuizAsked = 0
+	m.QuizCorrect = 0
+	m.ViewMode = ViewQuiz
+	m.nextQuizQuestion()
+	return m, nil
+}
+
+// loadPractice opens the practice stats kept in m.Store, once
+func (m *Model) loadPractice() bool {
+	if m.Practice != nil {
+		return true
+	}
+	tracker, err := practice.NewTracker(m.Store)
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error loading practice stats: %v", err)
+		return false
+	}
+	m.Practice = tracker
+	return true
+}
+
+// nextQuizQuestion asks the next question and clears the previous answer
+func (m *Model) nextQuizQuestion() {
+	question, ok := m.Practice.Next(m.QuizQuestions)
+	if !ok {
+		m.ViewMode = ViewMain
+		return
+	}
+	m.QuizQuestion = question
+	m.QuizAnswer = ""
+	m.QuizRevealed = false
+	m.QuizGraded = false
+}
+
+// gradeQuiz records whether the current question was answered correctly
+func (m *Model) gradeQuiz(correct bool) {
+	m.QuizGraded = true
+	m.QuizLastCorrect = correct
+	m.QuizAsked++
+	if correct {
+		m.QuizCorrect++
+	}
+	if _, err := m.Practice.Record(m.QuizQuestion, correct); err != nil {
+		m.StatusMessage = fmt.Sprintf("Error saving practice stats: %v", err)
+	}
+}
+
+// displayKeys writes keys in the notation of the table
+func (m Model) displayKeys(keys string) string {
+	if m.Renderer == nil {
+		return keys
+	}
+	return apps.FormatKeys(keys, m.Renderer.keyNotation)
+}
+
+func (m Model) ViewQuiz() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+
+	q := m.QuizQuestion
+	output.WriteString("╭─ Quiz ───────────────────────────────────────────────────╮\n")
+	writeLine(fmt.Sprintf("  %s • %s", q.App, q.Shortcut.Category))
+	writeLine("")
+	writeLine("  " + q.Shortcut.Description)
+	writeLine("")
+
+	switch {
+	case m.QuizGraded && m.QuizLastCorrect:
+		writeLine(fmt.Sprintf("  ✓ Correct: %s", m.displayKeys(q.Shortcut.Keys)))
+	case m.QuizGraded:
+		writeLine(fmt.Sprintf("  ✗ The keys are: %s", m.displayKeys(q.Shortcut.Keys)))
+	case m.QuizRevealed:
+		writeLine(fmt.Sprintf("  Keys: %s", m.displayKeys(q.Shortcut.Keys)))
+	default:
+		writeLine(fmt.Sprintf("  Keys: %s_", m.QuizAnswer))
+	}
+
+	if card, ok := m.Practice.Card(q.App, q.Shortcut.Keys); ok {
+		writeLine("")
+		writeLine(fmt.Sprintf("  Box %d/%d • %d/%d correct • due %s", card.Box, len(practice.Intervals)-1,
+			card.Correct, card.Reviews, formatDue(card.Due)))
+	}
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString(fmt.Sprintf("Score: %d/%d\n", m.QuizCorrect, m.QuizAsked))
+
+	switch {
+	case m.QuizGraded:
+		output.WriteString("\nEnter: next question • Esc: end quiz\n")
+	case m.QuizRevealed:
+		output.WriteString("\ny: I knew it • n: I didn't • Esc: end quiz\n")
+	default:
+		output.WriteString("\nType the keys, Enter: check • Tab: reveal • Ctrl+U: clear • Esc: end quiz\n")
+	}
+
+	return output.String()
+}
+
+// formatDue describes when a card is due again
+func formatDue(due time.Time) string {
+	wait := time.Until(due)
+	switch {
+	case wait <= 0:
+		return "now"
+	case wait < 24*time.Hour:
+		return "today"
+	default:
+		return fmt.Sprintf("in %d days", int(wait.Round(24*time.Hour)/(24*time.Hour)))
+	}
+}
+
+func (m Model) HandleQuizInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+[":
+		m.ViewMode = ViewMain
+		if m.QuizAsked > 0 {
+			m.StatusMessage = fmt.Sprintf("Quiz score: %d/%d", m.QuizCorrect, m.QuizAsked)
+		}
+		return m, nil
+	}
+
+	switch {
+	case m.QuizGraded:
+		if msg.String() == "enter" || msg.String() == " " {
+			m.StatusMessage = ""
+			m.nextQuizQuestion()
+		}
+	case m.QuizRevealed:
+		switch msg.String() {
+		case "y":
+			m.gradeQuiz(true)
+		case "n":
+			m.gradeQuiz(false)
+		}
+	default:
+		switch msg.Type {
+		case tea.KeyEnter:
+			if strings.TrimSpace(m.QuizAnswer) == "" {
+				m.QuizRevealed = true
+			} else {
+				m.gradeQuiz(practice.CheckAnswer(m.QuizAnswer, m.QuizQuestion.Shortcut.Keys))
+			}
+		case tea.KeyTab:
+			m.QuizRevealed = true
+		case tea.KeyBackspace:
+			if runes := []rune(m.QuizAnswer); len(runes) > 0 {
+				m.QuizAnswer = string(runes[:len(runes)-1])
+			}
+		case tea.KeyCtrlU:
+			m.QuizAnswer = ""
+		case tea.KeySpace:
+			m.QuizAnswer += " "
+		case tea.KeyRunes:
+			m.QuizAnswer += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
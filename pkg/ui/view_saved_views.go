@@ -0,0 +1,321 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/config"
+)
+
+// openSavedViews lists the saved views to switch between
+func (m Model) openSavedViews() (tea.Model, tea.Cmd) {
+	if m.Config == nil {
+		return m, nil
+	}
+	m.SavedViewCursor = 0
+	current, _ := m.matchingView()
+	for i, name := range m.savedViewChoices() {
+		if name == current {
+			m.SavedViewCursor = i
+		}
+	}
+	m.SavedViewMode = true
+	return m, nil
+}
+
+// savedViewChoices lists the saved views, after "" for the whole table
+func (m Model) savedViewChoices() []string {
+	return append([]string{""}, m.Config.ViewNames()...)
+}
+
+// currentView returns the state of the table as a view to save
+func (m Model) currentView() config.View {
+	return config.View{
+		Apps:   slices.Clone(m.FilteredApps),
+		Tags:   slices.Clone(m.SelectedTags),
+		Search: m.LastSearch,
+		Sort:   m.TableSort,
+	}
+}
+
+// viewApps returns the apps of view that the table can show
+func (m Model) viewApps(view config.View) []string {
+	var shown []string
+	for _, name := range view.Apps {
+		if slices.Contains(m.AllApps, name) {
+			shown = append(shown, name)
+		}
+	}
+	return shown
+}
+
+// matchingView returns the choice of savedViewChoices the table is in: a
+// saved view with the same filters, or "" for the whole table. ok is false
+// when the filters match neither.
+func (m Model) matchingView() (name string, ok bool) {
+	current := m.currentView()
+	for _, name := range m.Config.ViewNames() {
+		view := m.Config.Views[name]
+		if sameItems(m.viewApps(view), current.Apps) && sameItems(view.Tags, current.Tags) &&
+			view.Search == current.Search && view.Sort == current.Sort {
+			return name, true
+		}
+	}
+	whole := len(current.Apps) == 0 && len(current.Tags) == 0 && current.Search == "" && current.Sort == config.SortNone
+	return "", whole
+}
+
+// sameItems reports whether a and b hold the same strings in any order
+func sameItems(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	return slices.Equal(a, b)
+}
+
+// ApplyView filters and sorts the table as the saved view called name
+// does; "" shows the whole table in its own order
+func (m *Model) ApplyView(name string) error {
+	var view config.View
+	if name != "" {
+		var err error
+		if view, err = m.Config.LookupView(name); err != nil {
+			return err
+		}
+	}
+	m.FilteredApps = m.viewApps(view)
+	if m.FilteredApps == nil {
+		m.FilteredApps = []string{}
+	}
+	m.SelectedTags = slices.Clone(view.Tags)
+	m.LastSearch = view.Search
+	m.SearchQuery = ""
+	m.TableSort = view.Sort
+	m.applyTableFilters()
+	return nil
+}
+
+// saveView saves the state of the table as the view called name, replacing
+// any view of that name
+func (m *Model) saveView(name string) tea.Cmd {
+	if m.Config.Views == nil {
+		m.Config.Views = make(map[string]config.View)
+	}
+	m.Config.Views[name] = m.currentView()
+	if err := m.SaveConfig(); err != nil {
+		return m.notify(ToastError, "Saved the view %s for this session, but saving config failed: %v", name, err)
+	}
+	return m.notify(ToastInfo, "Saved the view %s", name)
+}
+
+// deleteView removes the saved view called name
+func (m *Model) deleteView(name string) tea.Cmd {
+	delete(m.Config.Views, name)
+	if m.Config.View == name {
+		m.Config.View = ""
+	}
+	if err := m.SaveConfig(); err != nil {
+		return m.notify(ToastError, "Deleted the view %s for this session, but saving config failed: %v", name, err)
+	}
+	return m.notify(ToastInfo, "Deleted the view %s", name)
+}
+
+// sortRows returns rows with the shortcuts of each category in order, one
+// of config.ValidSorts. The header and the rows heading categories stay in
+// place.
+func sortRows(rows [][]string, order string) [][]string {
+	if order == config.SortNone || len(rows) < 2 {
+		return rows
+	}
+
+	column := func(row []string) string {
+		return row[0]
+	}
+	if order == config.SortDescription {
+		// what the shortcut does in the first app having it
+		column = func(row []string) string {
+			for x, cell := range row[1:] {
+				if _, field := apps.ColumnApp(rows[0][x+1]); field == "description" && !isEmptyCell(cell) {
+					return cell
+				}
+			}
+			return ""
+		}
+	}
+
+	sorted := slices.Clone(rows)
+	start := 1
+	for y := 1; y <= len(sorted); y++ {
+		if y < len(sorted) && !apps.IsSectionRow(sorted[y]) {
+			continue
+		}
+		sort.SliceStable(sorted[start:y], func(i, j int) bool {
+			a, b := column(sorted[start+i]), column(sorted[start+j])
+			if !strings.EqualFold(a, b) {
+				return strings.ToLower(a) < strings.ToLower(b)
+			}
+			return sorted[start+i][0] < sorted[start+j][0]
+		})
+		start = y + 1
+	}
+	return sorted
+}
+
+// describeView summarizes the filters of a saved view
+func describeView(view config.View) string {
+	var details []string
+	if len(view.Apps) > 0 {
+		details = append(details, strings.Join(view.Apps, ", "))
+	}
+	if len(view.Tags) > 0 {
+		details = append(details, "tags "+strings.Join(view.Tags, ", "))
+	}
+	if view.Search != "" {
+		details = append(details, fmt.Sprintf("%q", view.Search))
+	}
+	if view.Sort != config.SortNone {
+		details = append(details, "by "+view.Sort)
+	}
+	return strings.Join(details, " • ")
+}
+
+// viewSavedViews renders the saved view picker below the table
+func (m Model) viewSavedViews() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+
+	output.WriteString("\n╭─ Saved Views ────────────────────────────────────────────╮\n")
+	current, matched := m.matchingView()
+	for i, name := range m.savedViewChoices() {
+		cursor := "  "
+		if i == m.SavedViewCursor {
+			cursor = "▶ "
+		}
+		active := "  "
+		if matched && name == current {
+			active = "● "
+		}
+		startup := ""
+		if name == m.Config.View {
+			startup = " (startup)"
+		}
+		if name == "" {
+			writeLine(cursor + active + "(whole table)" + startup)
+			continue
+		}
+		writeLine(fmt.Sprintf("%s%s%-12s %s%s", cursor, active, name, describeView(m.Config.Views[name]), startup))
+	}
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	if m.SavedViewNaming {
+		output.WriteString(fmt.Sprintf("Save the table as: %s_\nEnter: save • Esc: cancel\n", m.SavedViewName))
+	} else {
+		output.WriteString(m.hintBar() + "\n")
+	}
+
+	return output.String()
+}
+
+// HandleSavedViewInput handles the keys of the saved view picker
+func (m Model) HandleSavedViewInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.SavedViewNaming {
+		return m.handleViewNameInput(msg)
+	}
+
+	choices := m.savedViewChoices()
+	selected := choices[m.SavedViewCursor]
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+[", "V":
+		m.SavedViewMode = false
+		return m, nil
+	case "up", "k":
+		if m.SavedViewCursor > 0 {
+			m.SavedViewCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.SavedViewCursor < len(choices)-1 {
+			m.SavedViewCursor++
+		}
+		return m, nil
+	case "enter":
+		m.SavedViewMode = false
+		if err := m.ApplyView(selected); err != nil {
+			cmd := m.notify(ToastError, "Error: %v", err)
+			return m, cmd
+		}
+		if selected == "" {
+			cmd := m.notify(ToastInfo, "Showing the whole table")
+			return m, cmd
+		}
+		cmd := m.notify(ToastInfo, "Showing the view %s", selected)
+		return m, cmd
+	case "a":
+		m.SavedViewNaming = true
+		m.SavedViewName = ""
+		return m, nil
+	case "d":
+		if selected == "" {
+			return m, nil
+		}
+		cmd := m.deleteView(selected)
+		m.SavedViewCursor = min(m.SavedViewCursor, len(m.savedViewChoices())-1)
+		return m, cmd
+	case "*":
+		m.Config.View = selected
+		if err := m.SaveConfig(); err != nil {
+			cmd := m.notify(ToastError, "Error saving config: %v", err)
+			return m, cmd
+		}
+		if selected == "" {
+			cmd := m.notify(ToastInfo, "The whole table opens at startup")
+			return m, cmd
+		}
+		cmd := m.notify(ToastInfo, "The view %s opens at startup", selected)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// handleViewNameInput reads the name to save the table under
+func (m Model) handleViewNameInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.SavedViewNaming = false
+		return m, nil
+	case tea.KeyEnter:
+		name := strings.TrimSpace(m.SavedViewName)
+		if name == "" {
+			return m, nil
+		}
+		m.SavedViewNaming = false
+		cmd := m.saveView(name)
+		m.SavedViewCursor = slices.Index(m.savedViewChoices(), name)
+		return m, cmd
+	case tea.KeyBackspace:
+		if m.SavedViewName != "" {
+			runes := []rune(m.SavedViewName)
+			m.SavedViewName = string(runes[:len(runes)-1])
+		}
+		return m, nil
+	case tea.KeySpace:
+		m.SavedViewName += " "
+		return m, nil
+	case tea.KeyRunes:
+		m.SavedViewName += string(msg.Runes)
+		return m, nil
+	}
+	return m, nil
+}
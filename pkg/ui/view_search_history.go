@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/storage"
+)
+
+// searchHistoryKey is the session document holding recent searches
+const searchHistoryKey = "search_history"
+
+// maxSearchHistory is how many recent searches are kept
+const maxSearchHistory = 50
+
+// LoadSearchHistory reads the recent searches, newest first, from m.Store
+func (m *Model) LoadSearchHistory() {
+	m.SearchHistory = nil
+	if m.Store == nil {
+		return
+	}
+	var history []string
+	if err := storage.GetJSON(m.Store, storage.CollectionSession, searchHistoryKey, &history); err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			m.StatusMessage = fmt.Sprintf("Error loading search history: %v", err)
+		}
+		return
+	}
+	m.SearchHistory = history
+}
+
+// recordSearch moves query to the front of the search history and saves it
+func (m *Model) recordSearch(query string) {
+	if strings.TrimSpace(query) == "" {
+		return
+	}
+
+	history := []string{query}
+	for _, previous := range m.SearchHistory {
+		if previous != query && len(history) < maxSearchHistory {
+			history = append(history, previous)
+		}
+	}
+	m.SearchHistory = history
+	m.saveSearchHistory()
+}
+
+// recallSearch replaces the search query with an older (step 1) or newer
+// (step -1) search from the history, keeping what was typed to come back to
+func (m *Model) recallSearch(step int) {
+	pos := m.SearchHistoryPos + step
+	if pos < 0 || pos > len(m.SearchHistory) {
+		return
+	}
+	if m.SearchHistoryPos == 0 {
+		m.SearchDraft = m.SearchQuery
+	}
+	m.SearchHistoryPos = pos
+	if pos == 0 {
+		m.SearchQuery = m.SearchDraft
+	} else {
+		m.SearchQuery = m.SearchHistory[pos-1]
+	}
+}
+
+// openSearchHistory shows the search history picker
+func (m Model) openSearchHistory() (tea.Model, tea.Cmd) {
+	if len(m.SearchHistory) == 0 {
+		m.StatusMessage = "No searches yet"
+		return m, nil
+	}
+	m.SearchHistoryMode = true
+	m.SearchHistoryCursor = 0
+	return m, nil
+}
+
+// viewSearchHistory renders the search history picker below the table
+func (m Model) viewSearchHistory() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+
+	output.WriteString("\n╭─ Recent Searches ────────────────────────────────────────╮\n")
+	start := 0
+	if m.SearchHistoryCursor >= tagListHeight {
+		start = m.SearchHistoryCursor - tagListHeight + 1
+	}
+	for i := start; i < len(m.SearchHistory) && i < start+tagListHeight; i++ {
+		cursor := "  "
+		if i == m.SearchHistoryCursor {
+			cursor = "▶ "
+		}
+		writeLine(cursor + m.SearchHistory[i])
+	}
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString(m.hintBar() + "\n")
+
+	return output.String()
+}
+
+// HandleSearchHistoryInput handles the keys of the search history picker
+func (m Model) HandleSearchHistoryInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+[", "ctrl+h", "q":
+		m.SearchHistoryMode = false
+		return m, nil
+	case "up", "k":
+		if m.SearchHistoryCursor > 0 {
+			m.SearchHistoryCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.SearchHistoryCursor < len(m.SearchHistory)-1 {
+			m.SearchHistoryCursor++
+		}
+		return m, nil
+	case "e":
+		if m.SearchHistoryCursor < len(m.SearchHistory) {
+			m.SearchHistoryMode = false
+			m = m.openSearch()
+			m.SearchQuery = m.SearchHistory[m.SearchHistoryCursor]
+			m.SearchHistoryPos = 0
+		}
+		return m, nil
+	case "d":
+		if m.SearchHistoryCursor < len(m.SearchHistory) {
+			forgotten := m.SearchHistory[m.SearchHistoryCursor]
+			m.SearchHistory = append(m.SearchHistory[:m.SearchHistoryCursor:m.SearchHistoryCursor],
+				m.SearchHistory[m.SearchHistoryCursor+1:]...)
+			m.saveSearchHistory()
+			if m.SearchHistoryCursor >= len(m.SearchHistory) && m.SearchHistoryCursor > 0 {
+				m.SearchHistoryCursor--
+			}
+			if len(m.SearchHistory) == 0 {
+				m.SearchHistoryMode = false
+			}
+			m.StatusMessage = fmt.Sprintf("Forgot search %q", forgotten)
+		}
+		return m, nil
+	case "enter":
+		if m.SearchHistoryCursor < len(m.SearchHistory) {
+			query := m.SearchHistory[m.SearchHistoryCursor]
+			m.SearchHistoryMode = false
+			m.SearchMode = false
+			m.SearchQuery = query
+			m.LastSearch = query
+			m.recordSearch(query)
+			m.applyTableFilters()
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// saveSearchHistory writes the search history to m.Store
+func (m *Model) saveSearchHistory() {
+	if m.Store == nil {
+		return
+	}
+	if err := storage.PutJSON(m.Store, storage.CollectionSession, searchHistoryKey, m.SearchHistory); err != nil {
+		m.StatusMessage = fmt.Sprintf("Error saving search history: %v", err)
+	}
+}
@@ -0,0 +1,405 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/config"
+)
+
+// Steps of the first-run setup wizard
+const (
+	setupStepApps = iota
+	setupStepTheme
+	setupStepTableStyle
+	setupStepDataDir
+	setupStepSync
+	setupStepEndpoint
+	setupStepAPIKey
+	setupStepReview
+)
+
+// setupListHeight is the number of apps shown at once by the wizard
+const setupListHeight = 10
+
+// setupSyncChoices are the options of the sync step
+var setupSyncChoices = []string{"Keep sync off", "Sync with a server"}
+
+// StartSetup opens the first-run wizard, starting from the settings of the
+// loaded configuration; the tour of the features follows it
+func (m Model) StartSetup() Model {
+	names := m.Registry.List()
+	if stored, err := m.Registry.StoredApps(); err == nil {
+		for _, name := range stored {
+			if _, ok := m.Registry.Get(name); !ok {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	m.SetupApps = names
+	m.SetupSelected = append([]string{}, m.Config.Apps...)
+	m.SetupTheme = m.Config.Theme
+	m.SetupTableStyle = m.Config.Layout.TableStyle
+	m.SetupDataDir = m.Config.DataDir
+	m.SetupSync = m.Config.Sync.Enabled
+	m.SetupEndpoint = m.Config.Sync.Endpoint
+	m.SetupAPIKey = m.Config.Sync.APIKey
+	m.setupGoto(setupStepApps)
+	m.ViewMode = ViewSetup
+	m.TourPending = true
+	return m
+}
+
+// setupGoto moves the wizard to step, placing the cursor on its current choice
+func (m *Model) setupGoto(step int) {
+	m.SetupStep = step
+	m.SetupCursor = 0
+	current := ""
+	switch step {
+	case setupStepTheme:
+		current = m.SetupTheme
+	case setupStepTableStyle:
+		current = m.SetupTableStyle
+	case setupStepSync:
+		if m.SetupSync {
+			current = setupSyncChoices[1]
+		}
+	}
+	for i, choice := range m.setupChoices() {
+		if choice == current {
+			m.SetupCursor = i
+		}
+	}
+}
+
+// setupChoices returns the options of a list step
+func (m Model) setupChoices() []string {
+	switch m.SetupStep {
+	case setupStepApps:
+		return m.SetupApps
+	case setupStepTheme:
+		return config.ValidThemes
+	case setupStepTableStyle:
+		return config.ValidTableStyles
+	case setupStepSync:
+		return setupSyncChoices
+	}
+	return nil
+}
+
+// setupInput returns the text field edited by a text step
+func (m *Model) setupInput() *string {
+	switch m.SetupStep {
+	case setupStepDataDir:
+		return &m.SetupDataDir
+	case setupStepEndpoint:
+		return &m.SetupEndpoint
+	case setupStepAPIKey:
+		return &m.SetupAPIKey
+	}
+	return nil
+}
+
+// setupStepNumber numbers the steps as shown to the user; the sync server
+// fields are part of the sync step
+func setupStepNumber(step int) int {
+	switch {
+	case step <= setupStepSync:
+		return step + 1
+	case step < setupStepReview:
+		return setupStepSync + 1
+	}
+	return setupStepSync + 2
+}
+
+func (m Model) ViewSetup() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+
+	output.WriteString("╭─ Welcome to cheat-go ────────────────────────────────────╮\n")
+	writeLine(fmt.Sprintf("  Step %d of %d", setupStepNumber(m.SetupStep), setupStepNumber(setupStepReview)))
+	writeLine("")
+
+	hint := "↑/↓: move • Enter: next • Shift+Tab: back • Esc: skip setup"
+	switch m.SetupStep {
+	case setupStepApps:
+		writeLine("  Which apps should the table show?")
+		writeLine("")
+		start := 0
+		if m.SetupCursor >= setupListHeight {
+			start = m.SetupCursor - setupListHeight + 1
+		}
+		for i := start; i < len(m.SetupApps) && i < start+setupListHeight; i++ {
+			cursor := "  "
+			if i == m.SetupCursor {
+				cursor = "▶ "
+			}
+			check := "[ ]"
+			if m.isSetupAppSelected(m.SetupApps[i]) {
+				check = "[✓]"
+			}
+			writeLine(fmt.Sprintf("  %s%s %s", cursor, check, m.SetupApps[i]))
+		}
+		if len(m.SetupApps) > setupListHeight {
+			writeLine(fmt.Sprintf("    %d of %d apps", m.SetupCursor+1, len(m.SetupApps)))
+		}
+		hint = "↑/↓: move • space: toggle • Enter: next • Esc: skip setup"
+	case setupStepTheme, setupStepTableStyle, setupStepSync:
+		switch m.SetupStep {
+		case setupStepTheme:
+			writeLine("  Pick a color theme")
+		case setupStepTableStyle:
+			writeLine("  Pick a table style")
+		default:
+			writeLine("  Sync notes and apps between devices?")
+		}
+		writeLine("")
+		for i, choice := range m.setupChoices() {
+			cursor := "    "
+			if i == m.SetupCursor {
+				cursor = "  ▶ "
+			}
+			writeLine(cursor + choice)
+		}
+	case setupStepDataDir, setupStepEndpoint, setupStepAPIKey:
+		switch m.SetupStep {
+		case setupStepDataDir:
+			writeLine("  Where should your apps and notes be kept?")
+		case setupStepEndpoint:
+			writeLine("  Sync server URL")
+		default:
+			writeLine("  Sync API key (optional)")
+		}
+		writeLine("")
+		value := *m.setupInput()
+		if m.SetupStep == setupStepAPIKey {
+			value = strings.Repeat("•", len([]rune(value)))
+		}
+		writeLine(fmt.Sprintf("  > %s█", value))
+		hint = "Enter: next • Ctrl+U: clear • Shift+Tab: back • Esc: skip setup"
+	case setupStepReview:
+		writeLine("  Save these settings?")
+		writeLine("")
+		writeLine(fmt.Sprintf("  Apps:        %s", strings.Join(m.setupSelectedApps(), ", ")))
+		writeLine(fmt.Sprintf("  Theme:       %s", m.SetupTheme))
+		writeLine(fmt.Sprintf("  Table style: %s", m.SetupTableStyle))
+		writeLine(fmt.Sprintf("  Data dir:    %s", m.SetupDataDir))
+		if m.SetupSync {
+			writeLine(fmt.Sprintf("  Sync:        %s", m.SetupEndpoint))
+		} else {
+			writeLine("  Sync:        off")
+		}
+		if m.ConfigLoader != nil {
+			writeLine("")
+			writeLine(fmt.Sprintf("  Config file: %s", m.ConfigLoader.Path()))
+		}
+		hint = "Enter: save • Shift+Tab: back • Esc: skip setup"
+	}
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\n" + hint + "\n")
+
+	return output.String()
+}
+
+func (m Model) HandleSetupInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+[":
+		m.closeSetup()
+		m.StatusMessage = "Setup skipped, using the default settings"
+		return m, nil
+	case "shift+tab":
+		m.StatusMessage = ""
+		m.setupBack()
+		return m, nil
+	case "enter":
+		m.StatusMessage = ""
+		m.setupNext()
+		return m, nil
+	}
+
+	if input := m.setupInput(); input != nil {
+		switch msg.Type {
+		case tea.KeyBackspace:
+			if runes := []rune(*input); len(runes) > 0 {
+				*input = string(runes[:len(runes)-1])
+			}
+		case tea.KeyCtrlU:
+			*input = ""
+		case tea.KeySpace:
+			*input += " "
+		case tea.KeyRunes:
+			*input += string(msg.Runes)
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.SetupCursor > 0 {
+			m.SetupCursor--
+		}
+	case "down", "j":
+		if m.SetupCursor < len(m.setupChoices())-1 {
+			m.SetupCursor++
+		}
+	case " ", "x":
+		if m.SetupStep == setupStepApps && m.SetupCursor < len(m.SetupApps) {
+			m.toggleSetupApp(m.SetupApps[m.SetupCursor])
+		}
+	}
+	return m, nil
+}
+
+// setupNext takes the choice of the current step and moves on, saving the
+// configuration after the review
+func (m *Model) setupNext() {
+	switch m.SetupStep {
+	case setupStepApps:
+		if len(m.SetupSelected) == 0 {
+			m.StatusMessage = "Select at least one app"
+			return
+		}
+		m.setupGoto(setupStepTheme)
+	case setupStepTheme:
+		m.SetupTheme = config.ValidThemes[m.SetupCursor]
+		m.setupGoto(setupStepTableStyle)
+	case setupStepTableStyle:
+		m.SetupTableStyle = config.ValidTableStyles[m.SetupCursor]
+		m.setupGoto(setupStepDataDir)
+	case setupStepDataDir:
+		m.SetupDataDir = strings.TrimSpace(m.SetupDataDir)
+		if m.SetupDataDir == "" {
+			m.StatusMessage = "Enter a data directory"
+			return
+		}
+		m.setupGoto(setupStepSync)
+	case setupStepSync:
+		m.SetupSync = m.SetupCursor == 1
+		if m.SetupSync {
+			m.setupGoto(setupStepEndpoint)
+		} else {
+			m.setupGoto(setupStepReview)
+		}
+	case setupStepEndpoint:
+		m.SetupEndpoint = strings.TrimSpace(m.SetupEndpoint)
+		if m.SetupEndpoint == "" {
+			m.StatusMessage = "Enter the URL of the sync server"
+			return
+		}
+		m.setupGoto(setupStepAPIKey)
+	case setupStepAPIKey:
+		m.SetupAPIKey = strings.TrimSpace(m.SetupAPIKey)
+		m.setupGoto(setupStepReview)
+	case setupStepReview:
+		m.finishSetup()
+	}
+}
+
+// closeSetup goes back to the table, taking the tour pending on the first
+// run
+func (m *Model) closeSetup() {
+	m.ViewMode = ViewMain
+	if m.TourPending {
+		m.TourPending = false
+		*m = m.startTour()
+	}
+}
+
+// setupBack returns to the previous step, skipping the sync server fields
+// when sync stays off
+func (m *Model) setupBack() {
+	switch {
+	case m.SetupStep == setupStepApps:
+		return
+	case m.SetupStep == setupStepReview && !m.SetupSync:
+		m.setupGoto(setupStepSync)
+	default:
+		m.setupGoto(m.SetupStep - 1)
+	}
+}
+
+// finishSetup saves the settings picked in the wizard and applies them
+func (m *Model) finishSetup() {
+	cfg := *m.Config
+	cfg.Apps = m.setupSelectedApps()
+	cfg.Theme = m.SetupTheme
+	cfg.Layout.TableStyle = m.SetupTableStyle
+	cfg.DataDir = m.SetupDataDir
+	cfg.Sync.Enabled = m.SetupSync
+	if m.SetupSync {
+		cfg.Sync.Endpoint = m.SetupEndpoint
+		cfg.Sync.APIKey = m.SetupAPIKey
+	}
+
+	if result := cfg.Validate(); !result.Valid {
+		m.StatusMessage = fmt.Sprintf("Invalid settings: %v", result.Errors[0])
+		return
+	}
+	if m.ConfigLoader != nil {
+		if err := m.ConfigLoader.Save(&cfg, m.ConfigLoader.Path()); err != nil {
+			m.StatusMessage = fmt.Sprintf("Error saving config: %v", err)
+			return
+		}
+	}
+
+	restart := cfg.DataDir != m.Config.DataDir || cfg.Sync != m.Config.Sync
+	if err := m.applyConfig(&cfg); err != nil {
+		m.StatusMessage = fmt.Sprintf("Could not load some apps: %v", err)
+	}
+	m.CursorX = 0
+
+	m.closeSetup()
+	if m.StatusMessage != "" {
+		return
+	}
+	m.StatusMessage = "Settings saved"
+	if m.ConfigLoader != nil {
+		m.StatusMessage = fmt.Sprintf("Settings saved to %s", m.ConfigLoader.Path())
+	}
+	if restart {
+		m.StatusMessage += "; restart cheat-go to apply the data directory and sync settings"
+	}
+}
+
+// setupSelectedApps returns the selected apps in the order they are listed
+func (m Model) setupSelectedApps() []string {
+	var selected []string
+	for _, name := range m.SetupApps {
+		if m.isSetupAppSelected(name) {
+			selected = append(selected, name)
+		}
+	}
+	return selected
+}
+
+// isSetupAppSelected reports whether the wizard adds name to the config
+func (m Model) isSetupAppSelected(name string) bool {
+	for _, selected := range m.SetupSelected {
+		if selected == name {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleSetupApp selects an app in the wizard or deselects it
+func (m *Model) toggleSetupApp(name string) {
+	for i, selected := range m.SetupSelected {
+		if selected == name {
+			m.SetupSelected = append(m.SetupSelected[:i:i], m.SetupSelected[i+1:]...)
+			return
+		}
+	}
+	m.SetupSelected = append(m.SetupSelected, name)
+}
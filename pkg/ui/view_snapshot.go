@@ -0,0 +1,246 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/apps"
+	"github.com/remuscazacu/cheat-go/pkg/journal"
+	"github.com/remuscazacu/cheat-go/pkg/notes"
+)
+
+func (m Model) ViewSnapshot() string {
+	var output strings.Builder
+	var list strings.Builder
+
+	list.WriteString("╭─ Time Travel (read-only) ────────────────────────────────╮\n")
+
+	if m.Snapshot == nil || len(m.Snapshot.Items) == 0 {
+		list.WriteString("│  Nothing existed at this point in time.                  │\n")
+	} else {
+		header := fmt.Sprintf("  As of %s", m.SnapshotTime.Format("2006-01-02 15:04:05"))
+		list.WriteString("│" + runewidth.FillRight(header, 58) + "│\n")
+
+		start := 0
+		if m.SnapshotCursor >= 12 {
+			start = m.SnapshotCursor - 11
+		}
+		for i := start; i < len(m.Snapshot.Items) && i < start+12; i++ {
+			item := m.Snapshot.Items[i]
+
+			cursor := "  "
+			if i == m.SnapshotCursor {
+				cursor = "▶ "
+			}
+
+			marker := " "
+			switch {
+			case item.Deleted:
+				marker = "-"
+			case item.Changed:
+				marker = "~"
+			}
+
+			line := fmt.Sprintf("%s%s %-4s %s", cursor, marker, item.Kind, snapshotItemTitle(item))
+			list.WriteString("│" + runewidth.FillRight(runewidth.Truncate(line, 58, ""), 58) + "│\n")
+		}
+	}
+
+	list.WriteString("╰──────────────────────────────────────────────────────────╯")
+
+	if m.Snapshot != nil && m.SnapshotCursor < len(m.Snapshot.Items) {
+		output.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, list.String(), " ",
+			renderSnapshotItem(m.Snapshot.Items[m.SnapshotCursor])))
+	} else {
+		output.WriteString(list.String())
+	}
+	output.WriteString("\n")
+
+	output.WriteString("\n~: changed since • -: deleted since\n")
+	output.WriteString(m.hintBar() + "\n")
+
+	return output.String()
+}
+
+func (m Model) HandleSnapshotInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.Snapshot = nil
+		m.ViewMode = ViewHistory
+		m.LoadHistory()
+		return m, nil
+	case "up", "k":
+		if m.SnapshotCursor > 0 {
+			m.SnapshotCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.Snapshot != nil && m.SnapshotCursor < len(m.Snapshot.Items)-1 {
+			m.SnapshotCursor++
+		}
+		return m, nil
+	case "r":
+		if m.Snapshot != nil && m.SnapshotCursor < len(m.Snapshot.Items) {
+			item := m.Snapshot.Items[m.SnapshotCursor]
+			if err := m.RestoreSnapshotItem(item); err != nil {
+				m.StatusMessage = fmt.Sprintf("Error restoring %s: %v", snapshotItemTitle(item), err)
+				return m, nil
+			}
+			m.StatusMessage = fmt.Sprintf("Restored %s %s", item.Kind, snapshotItemTitle(item))
+
+			cursor := m.SnapshotCursor
+			m.OpenSnapshot(m.Snapshot.Seq)
+			if m.Snapshot != nil && cursor < len(m.Snapshot.Items) {
+				m.SnapshotCursor = cursor
+			}
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// OpenSnapshot switches to the read-only view of notes and apps as they
+// were right after the journal entry numbered seq
+func (m *Model) OpenSnapshot(seq int64) {
+	if m.Journal == nil {
+		return
+	}
+
+	entries, err := m.Journal.Entries()
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error loading history: %v", err)
+		return
+	}
+
+	m.Snapshot = journal.Rewind(entries, m.currentJournalItems(), seq)
+	m.SnapshotCursor = 0
+	for _, e := range entries {
+		if e.Seq == seq {
+			m.SnapshotTime = e.Time
+		}
+	}
+	m.ViewMode = ViewSnapshot
+}
+
+// currentJournalItems returns the present state of every journaled object:
+// all notes and the stored user apps among the configured apps
+func (m *Model) currentJournalItems() []journal.Item {
+	items := []journal.Item{}
+
+	if m.NotesManager != nil {
+		list, _ := m.NotesManager.ListNotes()
+		for _, note := range list {
+			data, err := json.Marshal(note)
+			if err != nil {
+				continue
+			}
+			items = append(items, journal.Item{Kind: journal.KindNote, ID: note.ID, Name: note.Title, State: data})
+		}
+	}
+
+	if m.Registry != nil && m.Config != nil {
+		for _, name := range m.Config.Apps {
+			if !m.Registry.HasStoredApp(name) {
+				continue
+			}
+			app, ok := m.Registry.Get(name)
+			if !ok {
+				continue
+			}
+			data, err := json.Marshal(app)
+			if err != nil {
+				continue
+			}
+			items = append(items, journal.Item{Kind: journal.KindApp, ID: name, Name: name, State: data})
+		}
+	}
+
+	return items
+}
+
+// RestoreSnapshotItem writes a single object back to its snapshot state.
+// The restore goes through the normal managers and is journaled itself.
+func (m *Model) RestoreSnapshotItem(item journal.SnapshotItem) error {
+	if !item.Changed {
+		return fmt.Errorf("unchanged since the snapshot")
+	}
+
+	switch item.Kind {
+	case journal.KindNote:
+		if m.NotesManager == nil {
+			return fmt.Errorf("notes are not available")
+		}
+		var note notes.Note
+		if err := json.Unmarshal(item.State, &note); err != nil {
+			return err
+		}
+		if _, err := m.NotesManager.GetNote(item.ID); err == nil {
+			return m.NotesManager.UpdateNote(item.ID, &note)
+		}
+		return m.NotesManager.CreateNote(&note)
+
+	case journal.KindApp:
+		var app apps.App
+		if err := json.Unmarshal(item.State, &app); err != nil {
+			return err
+		}
+		if err := m.Registry.SaveApp(&app); err != nil {
+			return err
+		}
+		if !m.IsAppConfigured(app.Name) {
+			m.Config.Apps = append(m.Config.Apps, app.Name)
+			m.AllApps = m.Config.Apps
+			if err := m.SaveConfig(); err != nil {
+				return err
+			}
+		}
+		m.RefreshTable()
+		return nil
+	}
+
+	return fmt.Errorf("unknown item kind %q", item.Kind)
+}
+
+// snapshotItemTitle returns the display name stored in an item's state
+func snapshotItemTitle(item journal.SnapshotItem) string {
+	var named struct {
+		Title string `json:"title"`
+		Name  string `json:"name"`
+	}
+	json.Unmarshal(item.State, &named)
+	switch {
+	case named.Title != "":
+		return named.Title
+	case named.Name != "":
+		return named.Name
+	case item.Name != "":
+		return item.Name
+	}
+	return item.ID
+}
+
+// renderSnapshotItem shows the snapshot state of a note or app
+func renderSnapshotItem(item journal.SnapshotItem) string {
+	switch item.Kind {
+	case journal.KindNote:
+		var note notes.Note
+		json.Unmarshal(item.State, &note)
+		lines := []string{note.Title}
+		if note.Category != "" || len(note.Tags) > 0 {
+			lines = append(lines, strings.TrimSpace(note.Category+" "+strings.Join(note.Tags, ", ")))
+		}
+		lines = append(lines, "")
+		lines = append(lines, strings.Split(note.Content, "\n")...)
+		return renderPreviewBox("Note", lines)
+	case journal.KindApp:
+		var app apps.App
+		json.Unmarshal(item.State, &app)
+		return renderPreviewBox("App", appPreviewLines(app.Name, app.Description, app.Shortcuts))
+	}
+	return renderPreviewBox("Item", []string{item.ID})
+}
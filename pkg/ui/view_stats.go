@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/practice"
+)
+
+// statsWeakest is how many of the weakest shortcuts the stats view lists
+const statsWeakest = 5
+
+// statsExportFile is the file in the data directory stats are exported to
+const statsExportFile = "practice-stats.json"
+
+// openStats shows the practice statistics dashboard
+func (m Model) openStats() (tea.Model, tea.Cmd) {
+	if !m.loadPractice() {
+		return m, nil
+	}
+	m.Stats = m.Practice.Stats(statsWeakest)
+	m.ViewMode = ViewStats
+	return m, nil
+}
+
+func (m Model) ViewStats() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+	writeGroups := func(title string, groups []practice.Group) {
+		writeLine("")
+		writeLine(fmt.Sprintf("  %-20s %6s %8s %8s", title, "Cards", "Reviews", "Accuracy"))
+		for _, group := range groups {
+			writeLine(fmt.Sprintf("  %-20s %6d %8d %7.0f%%", runewidth.Truncate(group.Name, 20, "…"),
+				group.Cards, group.Reviews, group.Accuracy*100))
+		}
+	}
+
+	s := m.Stats
+	output.WriteString("╭─ Practice Stats ─────────────────────────────────────────╮\n")
+	if s.Reviews == 0 {
+		writeLine("  No practice yet. Press Q in the main view for a quiz.")
+	} else {
+		writeLine(fmt.Sprintf("  %d reviews • %.0f%% correct • %d cards, %d due",
+			s.Reviews, s.Accuracy*100, s.Cards, s.Due))
+		writeLine(fmt.Sprintf("  Streak: %d days • longest %d days", s.CurrentStreak, s.LongestStreak))
+		writeGroups("App", s.Apps)
+		writeGroups("Category", s.Categories)
+
+		writeLine("")
+		writeLine("  Weakest shortcuts")
+		for _, card := range s.Weakest {
+			writeLine(fmt.Sprintf("  %-10s %-30s %3.0f%% of %d", runewidth.Truncate(card.App, 10, "…"),
+				runewidth.Truncate(m.displayKeys(card.Keys), 30, "…"), card.Accuracy()*100, card.Reviews))
+		}
+	}
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\n" + m.hintBar() + "\n")
+
+	return output.String()
+}
+
+func (m Model) HandleStatsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.ViewMode = ViewMain
+		return m, nil
+	case "r":
+		m.Stats = m.Practice.Stats(statsWeakest)
+		return m, nil
+	case "e":
+		m.exportStats()
+		return m, nil
+	}
+	return m, nil
+}
+
+// exportStats writes the practice statistics as JSON to the data directory
+func (m *Model) exportStats() {
+	if m.Config == nil {
+		m.StatusMessage = "No data directory to export to"
+		return
+	}
+
+	data, err := json.MarshalIndent(m.Practice.Stats(statsWeakest), "", "  ")
+	if err != nil {
+		m.StatusMessage = fmt.Sprintf("Error exporting stats: %v", err)
+		return
+	}
+	path := filepath.Join(m.Config.BaseDir(), statsExportFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		m.StatusMessage = fmt.Sprintf("Error exporting stats: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		m.StatusMessage = fmt.Sprintf("Error exporting stats: %v", err)
+		return
+	}
+	m.StatusMessage = fmt.Sprintf("Exported stats to %s", path)
+}
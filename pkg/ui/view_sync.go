@@ -1,11 +1,139 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/remuscazacu/cheat-go/pkg/sync"
 )
 
+// syncProgressMsg carries a step of the sync started from the TUI back to
+// Update; done is set once the sync has finished
+type syncProgressMsg struct {
+	progress sync.Progress
+	done     bool
+}
+
+// autoSyncStoppedMsg tells Update that auto-sync stopped, once the sync
+// it was running finished
+type autoSyncStoppedMsg struct{}
+
+// waitForSync returns a command waiting for the next step of a sync
+func waitForSync(events <-chan sync.Progress) tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-events
+		return syncProgressMsg{progress: p, done: !ok}
+	}
+}
+
+// startSync syncs in the background, following its progress
+func (m Model) startSync() (tea.Model, tea.Cmd) {
+	if m.SyncManager == nil {
+		m.StatusMessage = "Sync is not configured"
+		return m, nil
+	}
+	if m.SyncProgress != nil {
+		m.StatusMessage = "A sync is already running"
+		return m, nil
+	}
+	m.syncEvents = m.SyncManager.Start()
+	m.SyncProgress = &sync.Progress{Stage: sync.StageGathering}
+	m.StatusMessage = "Syncing..."
+	return m, waitForSync(m.syncEvents)
+}
+
+// handleSyncProgress shows a step of the running sync and waits for the
+// next, or reports the outcome once the sync has finished
+func (m Model) handleSyncProgress(msg syncProgressMsg) (tea.Model, tea.Cmd) {
+	if msg.done {
+		failed := m.SyncProgress != nil && m.SyncProgress.Stage == sync.StageFailed
+		m.SyncProgress = nil
+		m.syncEvents = nil
+		load := m.LoadSyncStatus()
+		if m.StatusMessage == "Syncing..." {
+			m.StatusMessage = ""
+		}
+		if failed {
+			return m, load
+		}
+		cmd := m.notify(ToastInfo, "Sync complete")
+		return m, tea.Batch(load, cmd)
+	}
+
+	p := msg.progress
+	m.SyncProgress = &p
+	if p.Stage == sync.StageFailed {
+		cmd := m.notify(ToastError, "Sync failed: %v", p.Err)
+		return m, tea.Batch(cmd, waitForSync(m.syncEvents))
+	}
+	return m, waitForSync(m.syncEvents)
+}
+
+// toggleAutoSync starts auto-sync, or stops it in the background as that
+// waits for the sync it may be running
+func (m *Model) toggleAutoSync() tea.Cmd {
+	manager := m.SyncManager
+	if !manager.AutoSyncEnabled() {
+		manager.StartAutoSync()
+		m.StatusMessage = fmt.Sprintf("Auto-sync enabled, every %s", manager.Interval())
+		return m.LoadSyncStatus()
+	}
+	return m.startTask("stopping auto-sync", func(context.Context) tea.Msg {
+		manager.StopAutoSync()
+		return autoSyncStoppedMsg{}
+	})
+}
+
+// handleAutoSyncStopped reports that auto-sync stopped
+func (m Model) handleAutoSyncStopped() (tea.Model, tea.Cmd) {
+	m.StatusMessage = "Auto-sync disabled"
+	cmd := m.LoadSyncStatus()
+	return m, cmd
+}
+
+// syncStageLabel describes a step of a sync in a few words
+func syncStageLabel(p sync.Progress) string {
+	switch p.Stage {
+	case sync.StageGathering:
+		return "gathering local data"
+	case sync.StagePulling:
+		return "pulling remote data"
+	case sync.StageResolving:
+		return fmt.Sprintf("resolving conflicts (%d/%d)", p.Done, p.Total)
+	case sync.StagePushing:
+		if p.Changes > 0 {
+			return fmt.Sprintf("pushing %d changes", p.Changes)
+		}
+		return fmt.Sprintf("pushing %d notes and %d apps", p.Notes, p.Apps)
+	case sync.StageSaving:
+		return "saving local data"
+	case sync.StageDone:
+		return "done"
+	default:
+		return string(p.Stage)
+	}
+}
+
+// progressBar draws a bar width columns wide, filled to fraction and
+// followed by the percentage
+func progressBar(fraction float64, width int) string {
+	percent := fmt.Sprintf(" %3.0f%%", fraction*100)
+	bar := width - len(percent)
+	filled := int(fraction*float64(bar) + 0.5)
+	filled = max(0, min(filled, bar))
+	return strings.Repeat("█", filled) + strings.Repeat("░", bar-filled) + percent
+}
+
 func (m Model) ViewSync() string {
+	if m.DeviceMode {
+		return m.viewDevices()
+	}
+
 	var output strings.Builder
 
 	output.WriteString("╭─ Sync Status ────────────────────────────────────────────╮\n")
@@ -18,27 +146,59 @@ func (m Model) ViewSync() string {
 		if m.SyncStatus.IsSyncing {
 			status = "Syncing..."
 		}
+		if p := m.SyncProgress; p != nil {
+			status = "Syncing: " + syncStageLabel(*p)
+		}
 
 		lastSync := "Never"
 		if !m.SyncStatus.LastSync.IsZero() {
 			lastSync = m.SyncStatus.LastSync.Format("2006-01-02 15:04:05")
 		}
 
-		output.WriteString(fmt.Sprintf("│  Status:     %-43s │\n", status))
+		output.WriteString(fmt.Sprintf("│  Status:     %s │\n", runewidth.FillRight(runewidth.Truncate(status, 43, "…"), 43)))
+		if p := m.SyncProgress; p != nil {
+			output.WriteString(fmt.Sprintf("│  Progress:   %s │\n", progressBar(p.Fraction(), 43)))
+		}
 		output.WriteString(fmt.Sprintf("│  Last Sync:  %-43s │\n", lastSync))
+		autoSync := "off"
+		if m.SyncStatus.AutoSync {
+			autoSync = fmt.Sprintf("every %s", m.SyncManager.Interval())
+		}
+		output.WriteString(fmt.Sprintf("│  Auto-sync:  %-43s │\n", autoSync))
 		output.WriteString(fmt.Sprintf("│  Device ID:  %-43s │\n", m.SyncStatus.DeviceID[:16]+"..."))
+		if name := m.SyncStatus.DeviceName; name != "" {
+			output.WriteString(fmt.Sprintf("│  Device:     %s │\n", runewidth.FillRight(runewidth.Truncate(name, 43, "…"), 43)))
+		}
+		if n := len(m.SyncStatus.Devices); n > 0 {
+			output.WriteString(fmt.Sprintf("│  Devices:    %-43d │\n", n))
+		}
+
+		if m.SyncStatus.LastError != "" {
+			output.WriteString(fmt.Sprintf("│  Error:      %s │\n", runewidth.FillRight(runewidth.Truncate(m.SyncStatus.LastError, 43, "…"), 43)))
+		}
+
+		if t := m.SyncStatus.Transfer; t != nil {
+			output.WriteString(fmt.Sprintf("│  Transfer:   %s │\n", runewidth.FillRight(runewidth.Truncate(t.String(), 43, "…"), 43)))
+		}
+
+		if m.SyncStatus.Quarantined != "" {
+			output.WriteString("│  ⚠ Integrity: pulled data failed its checksum and was    │\n")
+			output.WriteString("│    not merged; it is kept in the quarantine directory as │\n")
+			output.WriteString(fmt.Sprintf("│    %s │\n", runewidth.FillRight(runewidth.Truncate(filepath.Base(m.SyncStatus.Quarantined), 53, "…"), 53)))
+		}
 
 		if m.SyncStatus.HasConflicts {
 			output.WriteString(fmt.Sprintf("│  ⚠ Conflicts: %-42d │\n", len(m.SyncStatus.Conflicts)))
 		}
 	}
 
-	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
-	output.WriteString("\nKeys: s: sync now • r: resolve conflicts • a: auto-sync • esc: back\n")
-
-	if m.StatusMessage != "" {
-		output.WriteString(fmt.Sprintf("\nStatus: %s\n", m.StatusMessage))
+	if d := m.DaemonStatus; d != nil {
+		daemon := fmt.Sprintf("running (pid %d), idle while open", d.PID)
+		output.WriteString(fmt.Sprintf("│  Daemon:     %s │\n", runewidth.FillRight(runewidth.Truncate(daemon, 43, "…"), 43)))
 	}
 
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString("\n" + m.hintBar() + "\n")
+
 	return output.String()
 }
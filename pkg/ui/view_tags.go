@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// tagListHeight is the number of tags shown at once in the tag selector
+const tagListHeight = 10
+
+// openTagSelector lists the tags of the displayed apps for selection
+func (m Model) openTagSelector() (tea.Model, tea.Cmd) {
+	m.AllTags = m.Registry.Tags(m.activeApps())
+	m.TagCursor = 0
+	if len(m.AllTags) == 0 {
+		m.StatusMessage = "No shortcuts in the displayed apps have tags"
+		return m, nil
+	}
+	m.TagMode = true
+	return m, nil
+}
+
+// viewTags renders the tag selector below the table
+func (m Model) viewTags() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, ""), 58)))
+	}
+
+	output.WriteString("\n╭─ Filter by Tags ─────────────────────────────────────────╮\n")
+	start := 0
+	if m.TagCursor >= tagListHeight {
+		start = m.TagCursor - tagListHeight + 1
+	}
+	for i := start; i < len(m.AllTags) && i < start+tagListHeight; i++ {
+		cursor := "  "
+		if i == m.TagCursor {
+			cursor = "▶ "
+		}
+		check := "[ ]"
+		if m.isTagSelected(m.AllTags[i]) {
+			check = "[✓]"
+		}
+		writeLine(fmt.Sprintf("%s%s %s", cursor, check, m.AllTags[i]))
+	}
+	if len(m.AllTags) > tagListHeight {
+		writeLine(fmt.Sprintf("  %d of %d tags", m.TagCursor+1, len(m.AllTags)))
+	}
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString(m.hintBar() + "\n")
+
+	return output.String()
+}
+
+// HandleTagInput handles the keys of the tag selector
+func (m Model) HandleTagInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+[", "t":
+		m.TagMode = false
+		return m, nil
+	case "up", "k":
+		if m.TagCursor > 0 {
+			m.TagCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.TagCursor < len(m.AllTags)-1 {
+			m.TagCursor++
+		}
+		return m, nil
+	case " ", "x":
+		if m.TagCursor < len(m.AllTags) {
+			m.toggleTag(m.AllTags[m.TagCursor])
+		}
+		return m, nil
+	case "a":
+		m.SelectedTags = append([]string{}, m.AllTags...)
+		return m, nil
+	case "c", "ctrl+u":
+		m.SelectedTags = []string{}
+		return m, nil
+	case "enter":
+		m.TagMode = false
+		m.applyTableFilters()
+		if len(m.SelectedTags) > 0 {
+			m.StatusMessage = fmt.Sprintf("Showing shortcuts tagged %s", strings.Join(m.SelectedTags, ", "))
+		} else {
+			m.StatusMessage = "Tag filter cleared"
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// isTagSelected reports whether the table is limited to tag
+func (m Model) isTagSelected(tag string) bool {
+	for _, selected := range m.SelectedTags {
+		if selected == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleTag adds tag to the selected tags or removes it
+func (m *Model) toggleTag(tag string) {
+	for i, selected := range m.SelectedTags {
+		if selected == tag {
+			m.SelectedTags = append(m.SelectedTags[:i:i], m.SelectedTags[i+1:]...)
+			return
+		}
+	}
+	m.SelectedTags = append(m.SelectedTags, tag)
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// openThemePicker lists the themes, previewing the one under the cursor on
+// the table until one is picked
+func (m Model) openThemePicker() (tea.Model, tea.Cmd) {
+	m.ThemeCursor = 0
+	for i, name := range GetAvailableThemes() {
+		if name == m.Config.Theme {
+			m.ThemeCursor = i
+		}
+	}
+	m.themeRenderer = m.Renderer
+	m.ThemeMode = true
+	return m, nil
+}
+
+// previewTheme renders the table with the theme under the cursor
+func (m *Model) previewTheme() {
+	m.Renderer = m.themeRenderer.WithTheme(GetTheme(GetAvailableThemes()[m.ThemeCursor]))
+}
+
+// viewThemes renders the theme picker below the table
+func (m Model) viewThemes() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+
+	output.WriteString("\n╭─ Themes ─────────────────────────────────────────────────╮\n")
+	for i, name := range GetAvailableThemes() {
+		cursor := "  "
+		if i == m.ThemeCursor {
+			cursor = "▶ "
+		}
+		active := "  "
+		if name == m.Config.Theme {
+			active = "● "
+		}
+		writeLine(cursor + active + name)
+	}
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString(m.hintBar() + "\n")
+
+	return output.String()
+}
+
+// HandleThemeInput handles the keys of the theme picker
+func (m Model) HandleThemeInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	themes := GetAvailableThemes()
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+[", "T":
+		m.ThemeMode = false
+		m.Renderer = m.themeRenderer
+		m.themeRenderer = nil
+		return m, nil
+	case "up", "k":
+		if m.ThemeCursor > 0 {
+			m.ThemeCursor--
+			m.previewTheme()
+		}
+		return m, nil
+	case "down", "j":
+		if m.ThemeCursor < len(themes)-1 {
+			m.ThemeCursor++
+			m.previewTheme()
+		}
+		return m, nil
+	case "enter":
+		m.ThemeMode = false
+		m.previewTheme()
+		m.themeRenderer = nil
+		name := themes[m.ThemeCursor]
+		m.Config.Theme = name
+		if err := m.SaveConfig(); err != nil {
+			m.StatusMessage = fmt.Sprintf("Switched to the %s theme, but saving config failed: %v", name, err)
+			return m, nil
+		}
+		m.StatusMessage = fmt.Sprintf("Switched to the %s theme", name)
+		return m, nil
+	}
+	return m, nil
+}
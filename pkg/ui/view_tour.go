@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// tourStep is a callout of the tour: a feature and how to reach it. The
+// verbs of text are filled in turn with the keys in effect for actions,
+// named as in the keybinds.
+type tourStep struct {
+	title   string
+	actions []string
+	text    string
+}
+
+// tourSteps walk through the main features of the table
+var tourSteps = []tourStep{
+	{
+		title:   "Navigation",
+		actions: []string{"left", "down", "up", "right", "next_app", "prev_app"},
+		text: "The table above has a column per app and a row per shortcut. Move with the arrows or " +
+			"%s/%s/%s/%s, and jump between apps with %s and %s.",
+	},
+	{
+		title:   "Search",
+		actions: []string{"search", "clear"},
+		text: "Press %s to search the shortcuts of every app shown; app:, cat:, key: and tag: narrow " +
+			"the search. %s clears it.",
+	},
+	{
+		title:   "Filter",
+		actions: []string{"filter", "tags", "views"},
+		text: "Press %s to pick the apps shown and %s to keep the shortcuts with some tags. %s saves " +
+			"the table as a view to come back to.",
+	},
+	{
+		title:   "Notes",
+		actions: []string{"notes", "shortcut_note"},
+		text: "Press %s for your notes, kept beside the apps, and %s to attach one to the shortcut " +
+			"under the cursor.",
+	},
+	{
+		title:   "Online",
+		actions: []string{"online"},
+		text: "Press %s to browse repositories of cheat sheets, preview them and download them as " +
+			"new apps.",
+	},
+	{
+		title:   "Sync",
+		actions: []string{"sync"},
+		text: "Press %s for the sync status. Once a server is set up, sync keeps notes and apps the " +
+			"same on every device.",
+	},
+	{
+		title:   "Help",
+		actions: []string{"help", "help.tour"},
+		text: "Press %s at any time for every key of the view you are in; press %s there to take " +
+			"this tour again.",
+	},
+}
+
+// startTour opens the tour at its first step
+func (m Model) startTour() Model {
+	m.ViewMode = ViewMain
+	m.TourMode = true
+	m.TourStep = 0
+	return m
+}
+
+// boundKey spells the key in effect for the action the keybinds call name
+func (m Model) boundKey(name string) string {
+	for _, group := range m.keymap().groups {
+		for _, b := range group.bindings {
+			if group.bindingName(b) == name && len(b.keys) > 0 {
+				return displayKey(b.keys[0])
+			}
+		}
+	}
+	return ""
+}
+
+// viewTour renders the callout of the step of the tour below the table,
+// pointing up at it
+func (m Model) viewTour() string {
+	var output strings.Builder
+
+	writeLine := func(line string) {
+		output.WriteString(fmt.Sprintf("│%s│\n", runewidth.FillRight(runewidth.Truncate(line, 58, "…"), 58)))
+	}
+
+	step := tourSteps[m.TourStep]
+	keys := make([]any, len(step.actions))
+	for i, action := range step.actions {
+		keys[i] = m.boundKey(action)
+	}
+
+	output.WriteString("\n  ▲\n")
+	title := fmt.Sprintf("─ Tour %d/%d: %s ", m.TourStep+1, len(tourSteps), step.title)
+	output.WriteString("╭" + title + strings.Repeat("─", max(0, 58-runewidth.StringWidth(title))) + "╮\n")
+	writeLine("")
+	for _, line := range wrapText(fmt.Sprintf(step.text, keys...), 54) {
+		writeLine("  " + line)
+	}
+	writeLine("")
+	output.WriteString("╰──────────────────────────────────────────────────────────╯\n")
+	output.WriteString(m.hintBar() + "\n")
+
+	return output.String()
+}
+
+// HandleTourInput handles the keys of the tour
+func (m Model) HandleTourInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "ctrl+[", "q":
+		m.TourMode = false
+		cmd := m.notify(ToastInfo, "Press %s, then %s, to take the tour again", m.boundKey("help"), m.boundKey("help.tour"))
+		return m, cmd
+	case "l", "right", "enter", " ":
+		if m.TourStep == len(tourSteps)-1 {
+			m.TourMode = false
+			return m, nil
+		}
+		m.TourStep++
+		return m, nil
+	case "h", "left", "shift+tab":
+		if m.TourStep > 0 {
+			m.TourStep--
+		}
+		return m, nil
+	}
+	return m, nil
+}
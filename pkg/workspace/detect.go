@@ -0,0 +1,147 @@
+// Package workspace guesses which apps matter where cheat-go is started,
+// from the environment and the working directory.
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Env is what detection looks at
+type Env struct {
+	Getenv func(string) string
+	// Dir is the working directory, searched upwards for a repository
+	Dir string
+}
+
+// Current returns the environment of this process
+func Current() Env {
+	dir, _ := os.Getwd()
+	return Env{Getenv: os.Getenv, Dir: dir}
+}
+
+// programApps maps program names to the apps describing them
+var programApps = map[string]string{
+	"vi":          "vim",
+	"nvim":        "vim",
+	"gvim":        "vim",
+	"emacsclient": "emacs",
+	"hx":          "helix",
+	"kak":         "kakoune",
+	"code":        "vscode",
+}
+
+// envApp is an app detected by a variable it sets for its children
+type envApp struct {
+	variable string
+	app      string
+}
+
+var (
+	multiplexers = []envApp{{"TMUX", "tmux"}, {"STY", "screen"}, {"ZELLIJ", "zellij"}}
+	terminals    = []envApp{{"KITTY_WINDOW_ID", "kitty"}, {"ALACRITTY_WINDOW_ID", "alacritty"}, {"WEZTERM_PANE", "wezterm"}}
+	desktops     = []envApp{{"SWAYSOCK", "sway"}, {"I3SOCK", "i3"}}
+)
+
+// Detect returns the apps in use, most relevant first: the editor, then
+// terminal multiplexers and emulators, the shell, git inside a repository
+// and the desktop
+func Detect(env Env) []string {
+	getenv := env.Getenv
+	if getenv == nil {
+		getenv = func(string) string { return "" }
+	}
+
+	var detected []string
+	add := func(app string) {
+		if app == "" {
+			return
+		}
+		for _, seen := range detected {
+			if seen == app {
+				return
+			}
+		}
+		detected = append(detected, app)
+	}
+
+	addSet := func(set []envApp) {
+		for _, e := range set {
+			if getenv(e.variable) != "" {
+				add(e.app)
+			}
+		}
+	}
+
+	add(program(getenv("VISUAL")))
+	add(program(getenv("EDITOR")))
+	addSet(multiplexers)
+	if term := getenv("TERM"); term == "st" || strings.HasPrefix(term, "st-") {
+		add("st")
+	}
+	addSet(terminals)
+	add(program(getenv("SHELL")))
+	if inRepository(env.Dir) {
+		add("git")
+	}
+	addSet(desktops)
+	for _, desktop := range strings.Split(getenv("XDG_CURRENT_DESKTOP"), ":") {
+		add(strings.ToLower(strings.TrimSpace(desktop)))
+	}
+	return detected
+}
+
+// program returns the app of a command line such as "/usr/bin/nvim -p"
+func program(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	name := filepath.Base(fields[0])
+	if app, ok := programApps[name]; ok {
+		return app
+	}
+	return name
+}
+
+// inRepository reports whether dir or one of its parents holds .git
+func inRepository(dir string) bool {
+	_, ok := repositoryRoot(dir)
+	return ok
+}
+
+// repositoryRoot returns dir or the first of its parents holding .git
+func repositoryRoot(dir string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// ProjectAppsDirName is the directory at the root of a repository holding
+// the apps of the project
+const ProjectAppsDirName = "cheat-go"
+
+// ProjectAppsDir returns the directory of project apps of the repository
+// holding the working directory, if it has one
+func ProjectAppsDir(env Env) (string, bool) {
+	root, ok := repositoryRoot(env.Dir)
+	if !ok {
+		return "", false
+	}
+	dir := filepath.Join(root, ProjectAppsDirName)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
@@ -0,0 +1,75 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func envOf(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestDetect(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(repo, "src", "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	env := Env{
+		Getenv: envOf(map[string]string{
+			"EDITOR":              "/usr/bin/nvim -p",
+			"TMUX":                "/tmp/tmux-1000/default,123,0",
+			"TERM":                "st-256color",
+			"SHELL":               "/bin/zsh",
+			"XDG_CURRENT_DESKTOP": "dwm",
+		}),
+		Dir: sub,
+	}
+	want := []string{"vim", "tmux", "st", "zsh", "git", "dwm"}
+	if got := Detect(env); !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect() = %v, want %v", got, want)
+	}
+}
+
+func TestDetect_Empty(t *testing.T) {
+	if got := Detect(Env{Dir: t.TempDir()}); len(got) != 0 {
+		t.Errorf("nothing should be detected in an empty environment, got %v", got)
+	}
+}
+
+func TestDetect_NoDuplicates(t *testing.T) {
+	env := Env{Getenv: envOf(map[string]string{"VISUAL": "vim", "EDITOR": "vi"})}
+	if got := Detect(env); !reflect.DeepEqual(got, []string{"vim"}) {
+		t.Errorf("Detect() = %v, want [vim]", got)
+	}
+}
+
+func TestProjectAppsDir(t *testing.T) {
+	repo := t.TempDir()
+	sub := filepath.Join(repo, "src", "pkg")
+	os.MkdirAll(sub, 0755)
+	os.Mkdir(filepath.Join(repo, ".git"), 0755)
+
+	if dir, ok := ProjectAppsDir(Env{Dir: sub}); ok {
+		t.Errorf("repository without apps should have none, got %s", dir)
+	}
+
+	apps := filepath.Join(repo, ProjectAppsDirName)
+	os.Mkdir(apps, 0755)
+	if dir, ok := ProjectAppsDir(Env{Dir: sub}); !ok || dir != apps {
+		t.Errorf("ProjectAppsDir() = %s, %v, want %s", dir, ok, apps)
+	}
+
+	// outside a repository a cheat-go directory is not a project's
+	outside := t.TempDir()
+	os.Mkdir(filepath.Join(outside, ProjectAppsDirName), 0755)
+	if dir, ok := ProjectAppsDir(Env{Dir: outside}); ok {
+		t.Errorf("directory outside a repository should have no project apps, got %s", dir)
+	}
+}